@@ -2,16 +2,20 @@ package cmd
 
 import (
 	"context"
+	"errors"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/cmd"
 
 	log "github.com/sirupsen/logrus"
-	_ "github.com/yubing744/trading-gpt/pkg"
+
+	"github.com/yubing744/trading-gpt/pkg"
 )
 
 func init() {
 	bbgo.SetWrapperBinary()
+
+	cmd.RootCmd.PersistentFlags().BoolVar(&pkg.SelfTest, "selftest", false, "check the exchange, LLM, memory and notifications, report a checklist and exit instead of trading")
 }
 
 func Execute(ctx context.Context, args []string) {
@@ -19,6 +23,11 @@ func Execute(ctx context.Context, args []string) {
 	rootCmd.SetArgs(args)
 
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		// A passed self-test stops the strategy with ErrSelfTestDone, which is a successful run
+		if errors.Is(err, pkg.ErrSelfTestDone) {
+			return
+		}
+
 		log.WithError(err).Fatalf("cannot execute command")
 	}
 }
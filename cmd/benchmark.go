@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/cmd"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yubing744/trading-gpt/pkg"
+	"github.com/yubing744/trading-gpt/pkg/agents/trading"
+	"github.com/yubing744/trading-gpt/pkg/benchmark"
+	"github.com/yubing744/trading-gpt/pkg/llms"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// benchmarkCmd feeds labeled snapshots to the model of the jarvis strategy config and scores its decisions,
+// to compare models before trading with them
+var benchmarkCmd = &cobra.Command{
+	Use:          "benchmark",
+	Short:        "Score the configured model against labeled decision snapshots",
+	SilenceUsage: true,
+	RunE: func(c *cobra.Command, args []string) error {
+		configFile, _ := c.Flags().GetString("config")
+		snapshotsPath, _ := c.Flags().GetString("snapshots")
+		model, _ := c.Flags().GetString("model")
+		maxStopPercent, _ := c.Flags().GetFloat64("max-stop-percent")
+		output, _ := c.Flags().GetString("output")
+
+		strategy, err := loadJarvisStrategy(configFile)
+		if err != nil {
+			return err
+		}
+
+		snapshots, err := benchmark.LoadSnapshots(snapshotsPath)
+		if err != nil {
+			return err
+		}
+
+		llm := llms.NewLLMManager(&strategy.LLM)
+		if err := llm.Init(); err != nil {
+			return errors.Wrap(err, "init LLM error")
+		}
+
+		tradingCfg := strategy.Agent.Trading
+		if model != "" {
+			tradingCfg.Model = model
+		}
+
+		name := tradingCfg.Model
+		if name == "" {
+			name = strategy.LLM.Primary
+		}
+
+		agent := trading.NewTradingAgent(&tradingCfg, llm)
+		report := benchmark.NewReport(name)
+
+		for _, snapshot := range snapshots {
+			score := runSnapshot(c.Context(), agent, snapshot, maxStopPercent)
+			report.Add(score)
+
+			log.WithField("snapshot", score.ID).
+				WithField("action", score.Action).
+				WithField("agreed", score.Agreed).
+				WithField("error", score.Error).
+				Info("snapshot scored")
+		}
+
+		if output != "" {
+			if err := writeScores(output, report.Scores); err != nil {
+				return err
+			}
+		}
+
+		fmt.Println(report.String())
+		return nil
+	},
+}
+
+// loadJarvisStrategy returns the jarvis strategy of the bbgo config
+func loadJarvisStrategy(configFile string) (*pkg.Strategy, error) {
+	userConfig, err := bbgo.Load(configFile, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "load config error")
+	}
+
+	for _, mount := range userConfig.ExchangeStrategies {
+		if strategy, ok := mount.Strategy.(*pkg.Strategy); ok {
+			return strategy, nil
+		}
+	}
+
+	return nil, errors.Errorf("no %s strategy in %s", pkg.ID, configFile)
+}
+
+// runSnapshot asks the agent for a decision on the snapshot in a fresh session and scores it
+func runSnapshot(ctx context.Context, agent *trading.TradingAgent, snapshot *benchmark.Snapshot, maxStopPercent float64) *benchmark.Score {
+	msgs := make([]*ttypes.Message, 0, len(snapshot.Messages))
+	for _, text := range snapshot.Messages {
+		msgs = append(msgs, &ttypes.Message{Text: text})
+	}
+
+	result, err := agent.GenActions(ctx, ttypes.NewMockSession("benchmark-"+snapshot.ID), msgs)
+	if err != nil {
+		return benchmark.ScoreError(snapshot, errors.Wrap(err, "gen actions error"))
+	}
+
+	_, _, text := utils.ExtractThinkingFull(strings.TrimSpace(strings.Join(result.Texts, "")))
+	decision, err := utils.ParseResult(text)
+	if err != nil {
+		return benchmark.ScoreError(snapshot, errors.Wrap(err, "parse result error"))
+	}
+
+	return benchmark.ScoreResult(snapshot, decision, maxStopPercent)
+}
+
+// writeScores writes the score of every snapshot as JSONL
+func writeScores(path string, scores []*benchmark.Score) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "create output error")
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	for _, score := range scores {
+		if err := encoder.Encode(score); err != nil {
+			return errors.Wrap(err, "write score error")
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	benchmarkCmd.Flags().String("snapshots", "", "labeled snapshots JSONL file")
+	benchmarkCmd.Flags().String("model", "", "model to benchmark, defaults to the model of the trading agent")
+	benchmarkCmd.Flags().Float64("max-stop-percent", 10, "largest stop-loss distance from the price in percent counted as sane")
+	benchmarkCmd.Flags().StringP("output", "o", "", "write the score of every snapshot to this JSONL file")
+
+	benchmarkCmd.MarkFlagRequired("snapshots")
+
+	cmd.RootCmd.AddCommand(benchmarkCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/c9s/bbgo/pkg/cmd"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/yubing744/trading-gpt/pkg/journal"
+)
+
+// exportDatasetCmd converts the decision journal into a prompt/completion JSONL dataset for fine-tuning or evaluation.
+// Only decisions recorded with journal.record_prompts enabled carry a prompt and are exported.
+var exportDatasetCmd = &cobra.Command{
+	Use:          "export-dataset",
+	Short:        "Export the decision journal as a prompt/completion JSONL dataset",
+	SilenceUsage: true,
+	RunE: func(c *cobra.Command, args []string) error {
+		journalPath, _ := c.Flags().GetString("journal")
+		output, _ := c.Flags().GetString("output")
+		withMetadata, _ := c.Flags().GetBool("metadata")
+
+		filter := journal.DatasetFilter{}
+		filter.Symbol, _ = c.Flags().GetString("symbol")
+		filter.Outcome, _ = c.Flags().GetString("outcome")
+		filter.IncludeNoAction, _ = c.Flags().GetBool("include-no-action")
+
+		if filter.Outcome != journal.OutcomeAll && filter.Outcome != journal.OutcomeProfitable && filter.Outcome != journal.OutcomeLosing {
+			return errors.Errorf("invalid outcome %q, expected profitable or losing", filter.Outcome)
+		}
+
+		entries, err := journal.NewJournal(journalPath).LoadEntries()
+		if err != nil {
+			return errors.Wrap(err, "load journal error")
+		}
+
+		examples := journal.BuildDataset(entries, filter)
+
+		out := os.Stdout
+		if output != "" && output != "-" {
+			out, err = os.Create(output)
+			if err != nil {
+				return errors.Wrap(err, "create output error")
+			}
+			defer out.Close()
+		}
+
+		if err := journal.WriteDataset(out, examples, withMetadata); err != nil {
+			return err
+		}
+
+		log.WithField("examples", len(examples)).WithField("output", output).Info("dataset exported")
+		return nil
+	},
+}
+
+func init() {
+	exportDatasetCmd.Flags().String("journal", "memory-bank/journal.jsonl", "path of the decision journal")
+	exportDatasetCmd.Flags().StringP("output", "o", "-", "output JSONL file, - for stdout")
+	exportDatasetCmd.Flags().String("symbol", "", "only export decisions of the symbol")
+	exportDatasetCmd.Flags().String("outcome", "", "only export entries whose trade closed profitable or losing")
+	exportDatasetCmd.Flags().Bool("include-no-action", false, "also export no_action decisions")
+	exportDatasetCmd.Flags().Bool("metadata", false, "add the decision metadata (action, outcome, PnL) to each example")
+
+	cmd.RootCmd.AddCommand(exportDatasetCmd)
+}
@@ -7,8 +7,10 @@ import (
 )
 
 type GenResult struct {
-	Texts []string
-	Model string
+	Texts          []string
+	Model          string
+	ResponseFormat string // How the JSON response was produced, native, tool or repair
+	CycleID        string
 }
 
 type IAgent interface {
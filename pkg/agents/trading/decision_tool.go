@@ -0,0 +1,101 @@
+package trading
+
+import (
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// DecisionToolName is the tool the LLM calls with its decision in tool-calling mode
+const DecisionToolName = "submit_decision"
+
+// decisionTool describes the decision as a tool taking the fields of the JSON response format of the prompt
+func decisionTool() llms.Tool {
+	str := map[string]any{"type": "string"}
+
+	action := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Full command name from the available commands, e.g. exchange.no_action",
+			},
+			"args": map[string]any{
+				"type":                 "object",
+				"description":          "Command args by name, values as strings",
+				"additionalProperties": str,
+			},
+		},
+		"required": []string{"name"},
+	}
+
+	return llms.Tool{
+		Type: "function",
+		Function: &llms.FunctionDefinition{
+			Name:        DecisionToolName,
+			Description: "Submit the analysis and the decided command of this decision cycle",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"thoughts": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"plan":       str,
+							"analyze":    str,
+							"detail":     str,
+							"reflection": str,
+							"speak":      str,
+						},
+					},
+					"assessment": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"trend":      map[string]any{"type": "string", "enum": []string{"up", "down", "sideways"}},
+							"volatility": map[string]any{"type": "string", "enum": []string{"low", "normal", "high"}},
+							"key_levels": map[string]any{"type": "array", "items": str},
+							"bias":       map[string]any{"type": "string", "enum": []string{"long", "short", "neutral"}},
+						},
+					},
+					"action": action,
+					"actions": map[string]any{
+						"type":        "array",
+						"description": "Ordered commands executed as a batch, instead of action",
+						"items":       action,
+					},
+					"memory": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"content": str,
+						},
+					},
+				},
+				"required": []string{"thoughts", "action"},
+			},
+		},
+	}
+}
+
+// decisionToolChoice makes the LLM call the decision tool rather than answer in text
+var decisionToolChoice = llms.ToolChoice{
+	Type:     "function",
+	Function: &llms.FunctionReference{Name: DecisionToolName},
+}
+
+// decisionText returns the arguments of the decision tool call, after the thinking of the response if any, and
+// whether the LLM called the tool
+func decisionText(choice *llms.ContentChoice) (string, bool) {
+	for _, call := range choice.ToolCalls {
+		if call.FunctionCall == nil || call.FunctionCall.Name != DecisionToolName {
+			continue
+		}
+
+		text := call.FunctionCall.Arguments
+		if hasThinking, thinking, _ := utils.ExtractThinkingFull(choice.Content); hasThinking {
+			text = utils.ThinkingStartMarker + thinking + utils.ThinkingEndMarker + text
+		}
+
+		return text, true
+	}
+
+	return "", false
+}
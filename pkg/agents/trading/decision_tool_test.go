@@ -0,0 +1,45 @@
+package trading
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+func TestDecisionTool(t *testing.T) {
+	tool := decisionTool()
+	assert.Equal(t, DecisionToolName, tool.Function.Name)
+
+	schema, err := json.Marshal(tool.Function.Parameters)
+	assert.NoError(t, err)
+	assert.Contains(t, string(schema), `"required":["thoughts","action"]`)
+	assert.Contains(t, string(schema), `"enum":["up","down","sideways"]`)
+}
+
+func TestDecisionText(t *testing.T) {
+	args := `{"thoughts": {"speak": "wait"}, "action": {"name": "exchange.no_action", "args": {}}}`
+
+	text, ok := decisionText(&llms.ContentChoice{
+		Content: "<thinking>range bound</thinking>",
+		ToolCalls: []llms.ToolCall{
+			{Type: "function", FunctionCall: &llms.FunctionCall{Name: "other", Arguments: "{}"}},
+			{Type: "function", FunctionCall: &llms.FunctionCall{Name: DecisionToolName, Arguments: args}},
+		},
+	})
+	assert.True(t, ok)
+
+	hasThinking, thinking, rest := utils.ExtractThinkingFull(text)
+	assert.True(t, hasThinking)
+	assert.Equal(t, "range bound", thinking)
+
+	result, err := utils.ParseResultStrict(rest)
+	assert.NoError(t, err)
+	assert.Equal(t, "exchange.no_action", result.Action.Name)
+
+	_, ok = decisionText(&llms.ContentChoice{Content: args})
+	assert.False(t, ok)
+}
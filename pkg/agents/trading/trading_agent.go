@@ -11,6 +11,7 @@ import (
 
 	"github.com/yubing744/trading-gpt/pkg/agents"
 	"github.com/yubing744/trading-gpt/pkg/config"
+	tllms "github.com/yubing744/trading-gpt/pkg/llms"
 	"github.com/yubing744/trading-gpt/pkg/types"
 )
 
@@ -27,6 +28,7 @@ type TradingAgent struct {
 	temperature      float32
 	maxContextLength int
 	backgroup        string
+	toolCalling      bool
 	chats            []string
 	actions          map[string]*types.ActionDesc
 }
@@ -38,6 +40,7 @@ func NewTradingAgent(cfg *config.TradingAgentConfig, llm llms.Model) *TradingAge
 		model:            cfg.Model,
 		temperature:      cfg.Temperature,
 		backgroup:        cfg.Backgroup,
+		toolCalling:      cfg.ToolCalling,
 		chats:            make([]string, 0),
 		actions:          make(map[string]*types.ActionDesc, 0),
 		maxContextLength: cfg.MaxContextLength,
@@ -153,14 +156,35 @@ func (a *TradingAgent) GenActions(ctx context.Context, session types.ISession, m
 		return nil, err
 	}
 
+	cycleID := types.CycleIDFromContext(ctx)
+
 	log.
+		WithField("cycle_id", cycleID).
 		WithField("chatgpt msgs", gptMsgs).
 		Infof("gen chatgpt messages")
 
 	callOpts := make([]llms.CallOption, 0)
-	callOpts = append(callOpts, llms.WithTemperature(float64(a.temperature)))
+
+	// The sampling parameters chosen for the market state override the configured temperature
+	temperature := float64(a.temperature)
+	if sampling := types.SamplingFromContext(ctx); sampling != nil {
+		temperature = sampling.Temperature
+		if sampling.TopP > 0 {
+			callOpts = append(callOpts, llms.WithTopP(sampling.TopP))
+		}
+	}
+
+	callOpts = append(callOpts, llms.WithTemperature(temperature))
 	callOpts = append(callOpts, llms.WithMaxTokens(a.maxContextLength))
 
+	// Native JSON mode where the provider supports it, the manager turns it off otherwise
+	callOpts = append(callOpts, llms.WithJSONMode())
+
+	// The decision as a tool call where the provider supports tools, the manager drops the tools otherwise
+	if a.toolCalling {
+		callOpts = append(callOpts, llms.WithTools([]llms.Tool{decisionTool()}), llms.WithToolChoice(decisionToolChoice))
+	}
+
 	if a.model != "" {
 		callOpts = append(callOpts, llms.WithModel(a.model))
 	}
@@ -171,23 +195,38 @@ func (a *TradingAgent) GenActions(ctx context.Context, session types.ISession, m
 	}
 
 	result := &agents.GenResult{
-		Texts: make([]string, 0),
+		Texts:   make([]string, 0),
+		CycleID: cycleID,
 	}
 
 	if len(resp.Choices) > 0 {
-		text := resp.Choices[0].Content
-		log.WithField("text", text).Info("resp.Choices[0].Text")
-
-		result.Texts = append(result.Texts, text)
+		choice := resp.Choices[0]
+		text := choice.Content
 
 		// extract model
-		extInfo := resp.Choices[0].GenerationInfo
+		extInfo := choice.GenerationInfo
 		if extInfo != nil {
 			model, ok := extInfo["model"]
 			if ok {
 				result.Model = model.(string)
 			}
+
+			if format, ok := extInfo["response_format"].(string); ok {
+				result.ResponseFormat = format
+			}
+		}
+
+		if result.ResponseFormat == tllms.ResponseFormatTool {
+			if args, ok := decisionText(choice); ok {
+				text = args
+			} else {
+				// Answered in text after all, left to the repair pipeline
+				result.ResponseFormat = tllms.ResponseFormatRepair
+			}
 		}
+
+		log.WithField("cycle_id", cycleID).WithField("text", text).Info("resp.Choices[0].Text")
+		result.Texts = append(result.Texts, text)
 	}
 
 	if len(result.Texts) > 0 {
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yubing744/trading-gpt/pkg/api/jarvispb"
+)
+
+var log = logrus.WithField("api", "grpc")
+
+// decisionBufferSize is the number of decisions buffered per stream before slow clients miss decisions
+const decisionBufferSize = 16
+
+// Backend provides the strategy state and executes operator commands
+type Backend interface {
+	GetState(ctx context.Context) (*jarvispb.GetStateResponse, error)
+	SubmitCommand(ctx context.Context, operator string, command string, args map[string]string) error
+}
+
+// Server implements the gRPC JarvisService
+type Server struct {
+	jarvispb.UnimplementedJarvisServiceServer
+
+	backend Backend
+	token   string
+
+	mu          sync.Mutex
+	subscribers map[chan *jarvispb.Decision]struct{}
+}
+
+// NewServer creates a server, requests must carry "authorization: Bearer <token>" when token is not empty
+func NewServer(backend Backend, token string) *Server {
+	return &Server{
+		backend:     backend,
+		token:       token,
+		subscribers: make(map[chan *jarvispb.Decision]struct{}),
+	}
+}
+
+// Serve listens on addr until the context is done
+func (s *Server) Serve(ctx context.Context, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "listen %s error", addr)
+	}
+
+	grpcServer := s.NewGRPCServer()
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.GracefulStop()
+	}()
+
+	go func() {
+		log.WithField("addr", addr).Info("grpc server started")
+
+		if err := grpcServer.Serve(lis); err != nil {
+			log.WithError(err).Error("grpc server error")
+		}
+	}()
+
+	return nil
+}
+
+// NewGRPCServer returns a grpc server with the service and the auth interceptors registered
+func (s *Server) NewGRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := s.authorize(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authorize(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+
+	jarvispb.RegisterJarvisServiceServer(grpcServer, s)
+	return grpcServer
+}
+
+// PublishDecision sends the decision to every open stream, dropping it for streams that fall behind
+func (s *Server) PublishDecision(decision *jarvispb.Decision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- decision:
+		default:
+			log.WithField("decision", decision.Id).Warn("decision stream full, dropping decision")
+		}
+	}
+}
+
+func (s *Server) GetState(ctx context.Context, req *jarvispb.GetStateRequest) (*jarvispb.GetStateResponse, error) {
+	state, err := s.backend.GetState(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return state, nil
+}
+
+func (s *Server) StreamDecisions(req *jarvispb.StreamDecisionsRequest, stream jarvispb.JarvisService_StreamDecisionsServer) error {
+	ch := make(chan *jarvispb.Decision, decisionBufferSize)
+
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case decision := <-ch:
+			if err := stream.Send(decision); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) SubmitCommand(ctx context.Context, req *jarvispb.SubmitCommandRequest) (*jarvispb.SubmitCommandResponse, error) {
+	if req.Command == "" {
+		return nil, status.Error(codes.InvalidArgument, "command is empty")
+	}
+
+	log.WithField("operator", req.Operator).WithField("command", req.Command).Info("grpc submit command")
+
+	err := s.backend.SubmitCommand(ctx, req.Operator, req.Command, req.Args)
+	if err != nil {
+		return &jarvispb.SubmitCommandResponse{Success: false, Message: err.Error()}, nil
+	}
+
+	return &jarvispb.SubmitCommandResponse{Success: true, Message: "ok"}, nil
+}
+
+func (s *Server) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	for _, val := range md.Get("authorization") {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(val, "Bearer ")), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid token")
+}
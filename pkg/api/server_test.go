@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/yubing744/trading-gpt/pkg/api/jarvispb"
+)
+
+type fakeBackend struct {
+	commands []string
+}
+
+func (f *fakeBackend) GetState(ctx context.Context) (*jarvispb.GetStateResponse, error) {
+	return &jarvispb.GetStateResponse{Symbol: "BTCUSDT", Position: &jarvispb.Position{Side: "long"}}, nil
+}
+
+func (f *fakeBackend) SubmitCommand(ctx context.Context, operator string, command string, args map[string]string) error {
+	f.commands = append(f.commands, command)
+	return nil
+}
+
+func newTestClient(t *testing.T, server *Server) jarvispb.JarvisServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := server.NewGRPCServer()
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return jarvispb.NewJarvisServiceClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	backend := &fakeBackend{}
+	server := NewServer(backend, "secret")
+	client := newTestClient(t, server)
+
+	_, err := client.GetState(context.Background(), &jarvispb.GetStateRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+
+	state, err := client.GetState(ctx, &jarvispb.GetStateRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", state.Symbol)
+	assert.Equal(t, "long", state.Position.Side)
+
+	resp, err := client.SubmitCommand(ctx, &jarvispb.SubmitCommandRequest{Command: "exchange.close_position", Operator: "ops"})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, []string{"exchange.close_position"}, backend.commands)
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.StreamDecisions(streamCtx, &jarvispb.StreamDecisionsRequest{})
+	assert.NoError(t, err)
+
+	// wait for the stream to subscribe
+	assert.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.subscribers) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	server.PublishDecision(&jarvispb.Decision{Id: "d1", Action: "exchange.no_action"})
+
+	decision, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "d1", decision.Id)
+}
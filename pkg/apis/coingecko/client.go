@@ -0,0 +1,71 @@
+package coingecko
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("api", "coingecko")
+
+type CoinGeckoClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewCoinGeckoClient(opts ...Option) *CoinGeckoClient {
+	cfg := &Options{
+		baseURL: "https://api.coingecko.com/api/v3",
+		timeout: time.Second * 20,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &CoinGeckoClient{
+		baseURL: cfg.baseURL,
+		apiKey:  cfg.apiKey,
+		client: &http.Client{
+			Timeout:   cfg.timeout,
+			Transport: cfg.transport,
+		},
+	}
+}
+
+// getJSON sends a GET request to the path and decodes the JSON response into out
+func (c *CoinGeckoClient) getJSON(ctx context.Context, path string, out interface{}) error {
+	url := fmt.Sprintf("%s%s", c.baseURL, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("x-cg-demo-api-key", c.apiKey)
+	}
+
+	log.WithField("url", url).Debug("coingecko request")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("response error, status code: %d, detail: %s", resp.StatusCode, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,37 @@
+package coingecko
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGlobal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/global", r.URL.Path)
+		w.Write([]byte(`{"data":{"total_market_cap":{"usd":2500000000000},"market_cap_percentage":{"btc":54.2},"market_cap_change_percentage_24h_usd":-1.5}}`))
+	}))
+	defer server.Close()
+
+	client := NewCoinGeckoClient(WithBaseURL(server.URL))
+	global, err := client.GetGlobal(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 54.2, global.MarketCapPercentage["btc"])
+	assert.Equal(t, 2500000000000.0, global.TotalMarketCap["usd"])
+	assert.Equal(t, -1.5, global.MarketCapChangePercentage24hUSD)
+}
+
+func TestGetMarketChart_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewCoinGeckoClient(WithBaseURL(server.URL))
+	_, err := client.GetMarketChart(context.Background(), "bitcoin", "usd", 20)
+	assert.Error(t, err)
+}
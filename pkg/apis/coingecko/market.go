@@ -0,0 +1,71 @@
+package coingecko
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+type GlobalData struct {
+	TotalMarketCap                  map[string]float64 `json:"total_market_cap"`
+	MarketCapPercentage             map[string]float64 `json:"market_cap_percentage"`
+	MarketCapChangePercentage24hUSD float64            `json:"market_cap_change_percentage_24h_usd"`
+}
+
+type GetGlobalResp struct {
+	Data *GlobalData `json:"data"`
+}
+
+// GetGlobal returns global crypto market data, including BTC dominance
+// https://docs.coingecko.com/reference/crypto-global
+func (c *CoinGeckoClient) GetGlobal(ctx context.Context) (*GlobalData, error) {
+	resp := &GetGlobalResp{}
+	if err := c.getJSON(ctx, "/global", resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Data == nil {
+		return nil, errors.New("global data missing in response")
+	}
+
+	return resp.Data, nil
+}
+
+type CoinMarket struct {
+	ID           string  `json:"id"`
+	Symbol       string  `json:"symbol"`
+	CurrentPrice float64 `json:"current_price"`
+	MarketCap    float64 `json:"market_cap"`
+}
+
+// GetCoinMarkets returns the top coins by market cap
+// https://docs.coingecko.com/reference/coins-markets
+func (c *CoinGeckoClient) GetCoinMarkets(ctx context.Context, vsCurrency string, perPage int) ([]*CoinMarket, error) {
+	path := fmt.Sprintf("/coins/markets?vs_currency=%s&order=market_cap_desc&per_page=%d&page=1", url.QueryEscape(vsCurrency), perPage)
+
+	markets := make([]*CoinMarket, 0)
+	if err := c.getJSON(ctx, path, &markets); err != nil {
+		return nil, err
+	}
+
+	return markets, nil
+}
+
+type MarketChart struct {
+	Prices [][]float64 `json:"prices"` // [timestamp ms, price] pairs
+}
+
+// GetMarketChart returns the daily price history of a coin over the last days
+// https://docs.coingecko.com/reference/coins-id-market-chart
+func (c *CoinGeckoClient) GetMarketChart(ctx context.Context, id string, vsCurrency string, days int) (*MarketChart, error) {
+	path := fmt.Sprintf("/coins/%s/market_chart?vs_currency=%s&days=%d&interval=daily", url.PathEscape(id), url.QueryEscape(vsCurrency), days)
+
+	chart := &MarketChart{}
+	if err := c.getJSON(ctx, path, chart); err != nil {
+		return nil, err
+	}
+
+	return chart, nil
+}
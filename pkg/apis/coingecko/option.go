@@ -0,0 +1,40 @@
+package coingecko
+
+import (
+	"net/http"
+	"time"
+)
+
+type Options struct {
+	baseURL   string
+	apiKey    string
+	timeout   time.Duration
+	transport http.RoundTripper
+}
+
+type Option func(opts *Options)
+
+func WithBaseURL(baseURL string) Option {
+	return func(opts *Options) {
+		opts.baseURL = baseURL
+	}
+}
+
+// WithAPIKey sets the demo API key sent in the x-cg-demo-api-key header
+func WithAPIKey(apiKey string) Option {
+	return func(opts *Options) {
+		opts.apiKey = apiKey
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.timeout = timeout
+	}
+}
+
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *Options) {
+		opts.transport = transport
+	}
+}
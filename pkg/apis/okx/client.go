@@ -0,0 +1,82 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("api", "okx")
+
+// OKXClient is a client of the OKX public market data API
+type OKXClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewOKXClient(opts ...Option) *OKXClient {
+	cfg := &Options{
+		baseURL: "https://www.okx.com",
+		timeout: time.Second * 20,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &OKXClient{
+		baseURL: cfg.baseURL,
+		client: &http.Client{
+			Timeout:   cfg.timeout,
+			Transport: cfg.transport,
+		},
+	}
+}
+
+type apiResp struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// getJSON sends a GET request to the path and decodes the data field of the response into out
+func (c *OKXClient) getJSON(ctx context.Context, path string, out interface{}) error {
+	url := fmt.Sprintf("%s%s", c.baseURL, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	log.WithField("url", url).Debug("okx request")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return errors.Errorf("response error, status code: %d, detail: %s", resp.StatusCode, body)
+	}
+
+	result := &apiResp{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return err
+	}
+
+	if result.Code != "0" {
+		return errors.Errorf("api error, code: %s, msg: %s", result.Code, result.Msg)
+	}
+
+	return json.Unmarshal(result.Data, out)
+}
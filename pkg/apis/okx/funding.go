@@ -0,0 +1,66 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type fundingRateData struct {
+	InstID          string `json:"instId"`
+	FundingRate     string `json:"fundingRate"`
+	FundingTime     string `json:"fundingTime"`
+	NextFundingTime string `json:"nextFundingTime"`
+}
+
+// FundingRate is the current funding rate of a perpetual swap
+type FundingRate struct {
+	InstID      string
+	Rate        float64       // Rate paid by longs to shorts per funding period, negative when shorts pay
+	FundingTime time.Time     // Next settlement time
+	Interval    time.Duration // Funding period
+}
+
+// SwapInstID returns the instrument ID of the USDT-margined perpetual swap of a pair, e.g. SUI-USDT-SWAP
+func SwapInstID(baseCurrency, quoteCurrency string) string {
+	return fmt.Sprintf("%s-%s-SWAP", baseCurrency, quoteCurrency)
+}
+
+// GetFundingRate returns the current funding rate of the perpetual swap
+// https://www.okx.com/docs-v5/en/#public-data-rest-api-get-funding-rate
+func (c *OKXClient) GetFundingRate(ctx context.Context, instID string) (*FundingRate, error) {
+	data := make([]*fundingRateData, 0)
+	if err := c.getJSON(ctx, "/api/v5/public/funding-rate?instId="+url.QueryEscape(instID), &data); err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, errors.Errorf("funding rate of %s missing in response", instID)
+	}
+
+	rate, err := strconv.ParseFloat(data[0].FundingRate, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid funding rate: %s", data[0].FundingRate)
+	}
+
+	result := &FundingRate{
+		InstID:   data[0].InstID,
+		Rate:     rate,
+		Interval: time.Hour * 8,
+	}
+
+	fundingTime, err1 := strconv.ParseInt(data[0].FundingTime, 10, 64)
+	nextFundingTime, err2 := strconv.ParseInt(data[0].NextFundingTime, 10, 64)
+	if err1 == nil {
+		result.FundingTime = time.UnixMilli(fundingTime)
+	}
+	if err1 == nil && err2 == nil && nextFundingTime > fundingTime {
+		result.Interval = time.Duration(nextFundingTime-fundingTime) * time.Millisecond
+	}
+
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package okx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFundingRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v5/public/funding-rate", r.URL.Path)
+		assert.Equal(t, "SUI-USDT-SWAP", r.URL.Query().Get("instId"))
+		w.Write([]byte(`{"code":"0","msg":"","data":[{"instId":"SUI-USDT-SWAP","fundingRate":"0.0003","fundingTime":"1700000000000","nextFundingTime":"1700014400000"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOKXClient(WithBaseURL(server.URL))
+	rate, err := client.GetFundingRate(context.Background(), SwapInstID("SUI", "USDT"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0.0003, rate.Rate)
+	assert.Equal(t, time.Hour*4, rate.Interval)
+	assert.Equal(t, int64(1700000000000), rate.FundingTime.UnixMilli())
+}
+
+func TestGetFundingRate_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"51001","msg":"Instrument ID does not exist","data":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewOKXClient(WithBaseURL(server.URL))
+	_, err := client.GetFundingRate(context.Background(), "FOO-USDT-SWAP")
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package okx
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+type instrumentData struct {
+	InstID string `json:"instId"`
+	TickSz string `json:"tickSz"`
+	LotSz  string `json:"lotSz"`
+	MinSz  string `json:"minSz"`
+	CtVal  string `json:"ctVal"`
+	CtMult string `json:"ctMult"`
+}
+
+// Instrument is the trading metadata of an instrument
+type Instrument struct {
+	InstID        string
+	TickSize      float64
+	LotSize       float64
+	MinSize       float64
+	ContractValue float64 // Base currency per contract including the contract multiplier, 1 for spot
+}
+
+// GetInstrument returns the metadata of the instrument, instType is SPOT, MARGIN, SWAP, FUTURES or OPTION
+// https://www.okx.com/docs-v5/en/#public-data-rest-api-get-instruments
+func (c *OKXClient) GetInstrument(ctx context.Context, instType string, instID string) (*Instrument, error) {
+	data := make([]*instrumentData, 0)
+	path := "/api/v5/public/instruments?instType=" + url.QueryEscape(instType) + "&instId=" + url.QueryEscape(instID)
+	if err := c.getJSON(ctx, path, &data); err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, errors.Errorf("instrument %s missing in response", instID)
+	}
+
+	parse := func(value string, fallback float64) float64 {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			return fallback
+		}
+		return parsed
+	}
+
+	return &Instrument{
+		InstID:        data[0].InstID,
+		TickSize:      parse(data[0].TickSz, 0),
+		LotSize:       parse(data[0].LotSz, 0),
+		MinSize:       parse(data[0].MinSz, 0),
+		ContractValue: parse(data[0].CtVal, 1) * parse(data[0].CtMult, 1),
+	}, nil
+}
@@ -0,0 +1,40 @@
+package okx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetInstrument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v5/public/instruments", r.URL.Path)
+		assert.Equal(t, "SWAP", r.URL.Query().Get("instType"))
+		assert.Equal(t, "BTC-USDT-SWAP", r.URL.Query().Get("instId"))
+		w.Write([]byte(`{"code":"0","msg":"","data":[{"instId":"BTC-USDT-SWAP","tickSz":"0.1","lotSz":"0.01","minSz":"0.01","ctVal":"0.01","ctMult":"1"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOKXClient(WithBaseURL(server.URL))
+	instrument, err := client.GetInstrument(context.Background(), "SWAP", SwapInstID("BTC", "USDT"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0.1, instrument.TickSize)
+	assert.Equal(t, 0.01, instrument.LotSize)
+	assert.Equal(t, 0.01, instrument.ContractValue)
+}
+
+func TestGetInstrument_Spot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"0","msg":"","data":[{"instId":"BTC-USDT","tickSz":"0.1","lotSz":"0.00000001","minSz":"0.00001","ctVal":"","ctMult":""}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOKXClient(WithBaseURL(server.URL))
+	instrument, err := client.GetInstrument(context.Background(), "SPOT", "BTC-USDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, instrument.ContractValue)
+}
@@ -0,0 +1,32 @@
+package okx
+
+import (
+	"net/http"
+	"time"
+)
+
+type Options struct {
+	baseURL   string
+	timeout   time.Duration
+	transport http.RoundTripper
+}
+
+type Option func(opts *Options)
+
+func WithBaseURL(baseURL string) Option {
+	return func(opts *Options) {
+		opts.baseURL = baseURL
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.timeout = timeout
+	}
+}
+
+func WithTransport(transport http.RoundTripper) Option {
+	return func(opts *Options) {
+		opts.transport = transport
+	}
+}
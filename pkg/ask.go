@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/prompt"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils/xtemplate"
+)
+
+const (
+	askJournalEntries = 40 // journal entries given as evidence to /ask
+	askMemories       = 3  // memories retrieved for /ask
+	askMemoryWords    = 150
+)
+
+// handleAsk answers an operator's question outside the decision cycle, without touching the agent's chat history
+func (s *Strategy) handleAsk(ctx context.Context, chatSession ttypes.ISession, question string) {
+	if question == "" {
+		s.replyMsg(ctx, chatSession, "Usage: /ask <question>, e.g. /ask why did you short at 14:00?")
+		return
+	}
+
+	answer, err := s.answerQuestion(ctx, question)
+	if err != nil {
+		log.WithError(err).Warn("answer question error")
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("ask error: %s", err.Error()))
+		return
+	}
+
+	s.replyMsg(ctx, chatSession, answer)
+}
+
+// answerQuestion queries the LLM with the question, grounded in the current state, recent journal entries and
+// the memories relevant to the question
+func (s *Strategy) answerQuestion(ctx context.Context, question string) (string, error) {
+	entries := make([]string, 0)
+	if s.journal != nil {
+		loaded, err := s.journal.LoadEntries()
+		if err != nil {
+			return "", errors.Wrap(err, "load journal error")
+		}
+
+		for i := len(loaded) - 1; i >= 0 && len(entries) < askJournalEntries; i-- {
+			if loaded[i].Symbol == "" || loaded[i].Symbol == s.Symbol {
+				entries = append([]string{formatJournalEntry(loaded[i])}, entries...)
+			}
+		}
+	}
+
+	memories := make([]string, 0)
+	if s.memoryRetriever != nil {
+		memories = memorySummaries(s.retrieveRelevantMemories(ctx, question, "", askMemories), askMemoryWords)
+	}
+
+	promptText, err := xtemplate.Render(prompt.AskTpl, map[string]interface{}{
+		"Symbol":   s.Symbol,
+		"State":    s.askState(),
+		"Entries":  entries,
+		"Memories": memories,
+		"Question": question,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "render ask prompt error")
+	}
+
+	answer, err := s.llm.Call(ctx, promptText)
+	if err != nil {
+		return "", errors.Wrap(err, "call llm error")
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
+// askState describes the current state of the bot for /ask and /status
+func (s *Strategy) askState() string {
+	lines := []string{fmt.Sprintf("- Time: %s", time.Now().Format(time.RFC3339))}
+
+	if s.Position != nil && !s.Position.GetBase().IsZero() {
+		side := "long"
+		if s.Position.IsShort() {
+			side = "short"
+		}
+		lines = append(lines, fmt.Sprintf("- Position: %s %s at average cost %s", side,
+			s.Position.GetBase().Abs().String(), s.Position.AverageCost.String()))
+	} else {
+		lines = append(lines, "- Position: none")
+	}
+
+	if decision := s.lastDecision.Load(); decision != nil {
+		lines = append(lines, fmt.Sprintf("- Last decision at %s: %s %v, reasoning: %s",
+			time.UnixMilli(decision.Timestamp).Format(time.RFC3339), decision.Action, decision.Args, decision.Reasoning))
+	}
+
+	if trackRecord := s.currentTrackRecord(); trackRecord != "" {
+		lines = append(lines, fmt.Sprintf("- Track record: %s", trackRecord))
+	}
+
+	if s.currentMemory != "" {
+		lines = append(lines, fmt.Sprintf("- Working memory: %s", s.currentMemory))
+	}
+
+	if s.lossStreak != nil {
+		if streak := s.lossStreak.String(); streak != "" {
+			lines = append(lines, fmt.Sprintf("- Loss streak: %s", streak))
+		}
+	}
+
+	if s.riskBudget != nil {
+		if budget := s.riskBudget.String(); budget != "" {
+			lines = append(lines, fmt.Sprintf("- Risk budget: %s", budget))
+		}
+	}
+
+	if s.riskManager != nil {
+		lines = append(lines, fmt.Sprintf("- Risk manager: %s", s.riskManager.String()))
+	}
+
+	if s.blackout.Load() {
+		lines = append(lines, "- LLM-driven trading is paused after repeated invalid responses")
+	}
+
+	if s.observeOnly.Load() {
+		lines = append(lines, "- Observe-only: another instance holds the trading lock")
+	}
+
+	return strings.Join(lines, "\n")
+}
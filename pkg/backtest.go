@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/chat"
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// backtestChannel is the notify channel of the backtest session, its replies are only logged
+type backtestChannel struct{}
+
+func (c *backtestChannel) GetID() string {
+	return "backtest"
+}
+
+func (c *backtestChannel) Reply(ctx context.Context, msg *ttypes.Message) error {
+	log.WithField("text", msg.Text).Debug("backtest reply")
+	return nil
+}
+
+// setupBacktest runs the decision loop over the klines replayed by a bbgo backtest, with the fills simulated by
+// the backtest exchange, and reports the PnL and drawdown of the closed trades when it ends. Replaying the LLM
+// responses recorded by llm.cache keeps reruns reproducible and free.
+func (s *Strategy) setupBacktest(ctx context.Context) error {
+	if !bbgo.IsBackTesting {
+		return nil
+	}
+
+	cfg := &s.Backtest
+	if cfg.Report == "" {
+		cfg.Report = fmt.Sprintf("memory-bank/backtest-%s.txt", s.Symbol)
+	}
+
+	initialEquity := 0.0
+	if balance, ok := s.session.GetAccount().Balance(s.Market.QuoteCurrency); ok {
+		initialEquity = balance.Total().Float64()
+	}
+
+	var mu sync.Mutex
+	report := utils.NewBacktestReport(s.Symbol, initialEquity)
+
+	s.world.OnEvent(func(evt ttypes.IEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch evt.GetType() {
+		case "update_finish":
+			report.Decisions++
+		case exchange.EventPositionClosed:
+			posData, ok := evt.GetData().(exchange.PositionClosedEventData)
+			if !ok {
+				return
+			}
+
+			report.AddTrade(utils.BacktestTrade{
+				Time:       posData.Timestamp,
+				Side:       posData.Side,
+				EntryPrice: posData.EntryPrice,
+				ExitPrice:  posData.ExitPrice,
+				PnL:        posData.ProfitAndLoss,
+				PnLPercent: posData.ProfitAndLossPercent,
+				Reason:     posData.CloseReason,
+			})
+		}
+	})
+
+	// Without a notify channel or chat no session would feed the events to the agent
+	s.adminMu.Lock()
+	hasSession := len(s.adminSessions) > 0
+	s.adminMu.Unlock()
+	if !hasSession {
+		s.setupAdminSession(ctx, chat.NewChatSession(&backtestChannel{}))
+	}
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		mu.Lock()
+		text := report.String()
+		mu.Unlock()
+
+		fmt.Println(text)
+		if err := writeBacktestReport(cfg.Report, text); err != nil {
+			log.WithError(err).Error("write backtest report failed")
+			return
+		}
+
+		log.WithField("path", cfg.Report).Info("backtest report written")
+	})
+
+	log.WithField("initialEquity", initialEquity).WithField("report", cfg.Report).Info("Backtest of the decision loop enabled")
+	return nil
+}
+
+func writeBacktestReport(path string, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "create backtest report dir error")
+	}
+
+	return errors.Wrap(os.WriteFile(path, []byte(text), 0644), "write backtest report error")
+}
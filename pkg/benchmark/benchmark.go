@@ -0,0 +1,274 @@
+package benchmark
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// Directions of a decision
+const (
+	DirectionLong  = "long"
+	DirectionShort = "short"
+	DirectionFlat  = "flat" // No new exposure: no_action, close_position and other commands
+)
+
+// Snapshot is a labeled decision point: the prompt messages the agent saw and the actions known to be good
+type Snapshot struct {
+	ID          string   `json:"id"`
+	Messages    []string `json:"messages"`     // Prompt messages sent to the agent in order
+	Price       float64  `json:"price"`        // Last close, the reference of the stop-loss and take-profit checks
+	GoodActions []string `json:"good_actions"` // Commands counted as agreeing, e.g. open_long_position or no_action
+	Direction   string   `json:"direction"`    // Expected direction, long, short or flat; empty leaves it unscored
+}
+
+// Score is the outcome of a decision on a snapshot
+type Score struct {
+	ID        string   `json:"id"`
+	Action    string   `json:"action"` // First command of the decision, no_action without one
+	Direction string   `json:"direction"`
+	Agreed    bool     `json:"agreed"`
+	Correct   *bool    `json:"correct,omitempty"`  // Direction matched the label, nil when unlabeled
+	Sane      *bool    `json:"sane,omitempty"`     // Stop loss and take profit sane, nil for decisions without an entry
+	Problems  []string `json:"problems,omitempty"` // Why the stop loss or take profit is not sane
+	Error     string   `json:"error,omitempty"`    // Generation or parse error, the decision counts as disagreeing
+}
+
+// LoadSnapshots reads labeled snapshots from a JSONL file, one snapshot per line
+func LoadSnapshots(path string) ([]*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open snapshots error")
+	}
+	defer f.Close()
+
+	snapshots := make([]*Snapshot, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		snapshot := &Snapshot{}
+		if err := json.Unmarshal([]byte(text), snapshot); err != nil {
+			return nil, errors.Wrapf(err, "invalid snapshot at line %d", line)
+		}
+
+		if len(snapshot.Messages) == 0 {
+			return nil, errors.Errorf("snapshot %s at line %d has no messages", snapshot.ID, line)
+		}
+
+		if snapshot.ID == "" {
+			snapshot.ID = fmt.Sprintf("line-%d", line)
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read snapshots error")
+	}
+
+	return snapshots, nil
+}
+
+// actionName returns the command of the action without the entity prefix
+func actionName(action *types.Action) string {
+	return strings.TrimPrefix(action.Name, "exchange.")
+}
+
+// direction returns the direction a command takes
+func direction(action string) string {
+	switch action {
+	case "open_long_position":
+		return DirectionLong
+	case "open_short_position":
+		return DirectionShort
+	default:
+		return DirectionFlat
+	}
+}
+
+// ScoreResult scores a parsed decision against the snapshot. Entries are checked for a stop loss on the losing
+// side of the price and within maxStopPercent of it, and a take profit, when set, on the winning side.
+func ScoreResult(snapshot *Snapshot, result *types.Result, maxStopPercent float64) *Score {
+	score := &Score{ID: snapshot.ID, Action: "no_action"}
+
+	actions := result.AllActions()
+	if len(actions) > 0 {
+		score.Action = actionName(actions[0])
+	}
+	score.Direction = direction(score.Action)
+
+	for _, good := range snapshot.GoodActions {
+		if strings.TrimPrefix(good, "exchange.") == score.Action {
+			score.Agreed = true
+			break
+		}
+	}
+
+	if snapshot.Direction != "" {
+		correct := snapshot.Direction == score.Direction
+		score.Correct = &correct
+	}
+
+	if score.Direction != DirectionFlat {
+		score.Problems = checkTriggers(score.Direction, snapshot.Price, actions[0].Args, maxStopPercent)
+		sane := len(score.Problems) == 0
+		score.Sane = &sane
+	}
+
+	return score
+}
+
+// ScoreError scores a decision that could not be generated or parsed
+func ScoreError(snapshot *Snapshot, err error) *Score {
+	score := &Score{ID: snapshot.ID, Error: err.Error()}
+
+	if snapshot.Direction != "" {
+		correct := false
+		score.Correct = &correct
+	}
+
+	return score
+}
+
+// checkTriggers returns the problems of the stop loss and take profit of an entry at the price
+func checkTriggers(side string, price float64, args map[string]string, maxStopPercent float64) []string {
+	problems := make([]string, 0)
+	long := side == DirectionLong
+
+	stopText := args["stop_loss_trigger_price"]
+	if stopText == "" {
+		problems = append(problems, "no stop loss")
+	} else if stop, err := utils.ParseNumberArgFloat(stopText); err != nil {
+		problems = append(problems, fmt.Sprintf("stop loss %q is not a price", stopText))
+	} else if price > 0 {
+		distance := math.Abs(price-stop) / price * 100
+		switch {
+		case long && stop >= price, !long && stop <= price:
+			problems = append(problems, fmt.Sprintf("stop loss %g is on the wrong side of the price %g", stop, price))
+		case maxStopPercent > 0 && distance > maxStopPercent:
+			problems = append(problems, fmt.Sprintf("stop loss %g is %.2f%% from the price, above %g%%", stop, distance, maxStopPercent))
+		}
+	}
+
+	if takeText := args["take_profit_trigger_price"]; takeText != "" {
+		if take, err := utils.ParseNumberArgFloat(takeText); err != nil {
+			problems = append(problems, fmt.Sprintf("take profit %q is not a price", takeText))
+		} else if price > 0 && ((long && take <= price) || (!long && take >= price)) {
+			problems = append(problems, fmt.Sprintf("take profit %g is on the wrong side of the price %g", take, price))
+		}
+	}
+
+	return problems
+}
+
+// Report aggregates the scores of a benchmark run
+type Report struct {
+	Model  string
+	Scores []*Score
+}
+
+func NewReport(model string) *Report {
+	return &Report{
+		Model:  model,
+		Scores: make([]*Score, 0),
+	}
+}
+
+func (r *Report) Add(score *Score) {
+	r.Scores = append(r.Scores, score)
+}
+
+// Agreement returns the percent of decisions taking one of the good actions
+func (r *Report) Agreement() float64 {
+	agreed := 0
+	for _, score := range r.Scores {
+		if score.Agreed {
+			agreed++
+		}
+	}
+
+	return percent(agreed, len(r.Scores))
+}
+
+// DirectionAccuracy returns the percent of correct directions among the labeled snapshots, and their count
+func (r *Report) DirectionAccuracy() (float64, int) {
+	correct, labeled := 0, 0
+	for _, score := range r.Scores {
+		if score.Correct == nil {
+			continue
+		}
+
+		labeled++
+		if *score.Correct {
+			correct++
+		}
+	}
+
+	return percent(correct, labeled), labeled
+}
+
+// Sanity returns the percent of entries with a sane stop loss and take profit, and the number of entries
+func (r *Report) Sanity() (float64, int) {
+	sane, entries := 0, 0
+	for _, score := range r.Scores {
+		if score.Sane == nil {
+			continue
+		}
+
+		entries++
+		if *score.Sane {
+			sane++
+		}
+	}
+
+	return percent(sane, entries), entries
+}
+
+// Errors returns the number of decisions that could not be generated or parsed
+func (r *Report) Errors() int {
+	errs := 0
+	for _, score := range r.Scores {
+		if score.Error != "" {
+			errs++
+		}
+	}
+
+	return errs
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+
+	accuracy, labeled := r.DirectionAccuracy()
+	sanity, entries := r.Sanity()
+
+	sb.WriteString(fmt.Sprintf("Benchmark of %s on %d snapshots\n", r.Model, len(r.Scores)))
+	sb.WriteString(fmt.Sprintf("Agreement: %.1f%%\n", r.Agreement()))
+	sb.WriteString(fmt.Sprintf("Direction accuracy: %.1f%% of %d labeled\n", accuracy, labeled))
+	sb.WriteString(fmt.Sprintf("SL/TP sanity: %.1f%% of %d entries\n", sanity, entries))
+	sb.WriteString(fmt.Sprintf("Errors: %d", r.Errors()))
+
+	return sb.String()
+}
+
+func percent(n int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(n) / float64(total) * 100
+}
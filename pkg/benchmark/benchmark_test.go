@@ -0,0 +1,91 @@
+package benchmark
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+func parse(t *testing.T, text string) *types.Result {
+	result, err := utils.ParseResult(text)
+	assert.NoError(t, err)
+	return result
+}
+
+func TestLoadSnapshots(t *testing.T) {
+	snapshots, err := LoadSnapshots(filepath.Join("testdata", "snapshots.jsonl"))
+	assert.NoError(t, err)
+
+	if assert.Len(t, snapshots, 3) {
+		assert.Equal(t, "breakout-long", snapshots[0].ID)
+		assert.Len(t, snapshots[0].Messages, 2)
+		assert.Equal(t, 2.93, snapshots[0].Price)
+		assert.Equal(t, DirectionFlat, snapshots[2].Direction)
+	}
+
+	_, err = LoadSnapshots(filepath.Join("testdata", "missing.jsonl"))
+	assert.Error(t, err)
+}
+
+func TestScoreResult(t *testing.T) {
+	long := &Snapshot{ID: "long", Price: 100, GoodActions: []string{"open_long_position"}, Direction: DirectionLong}
+
+	// Agreeing entry with sane triggers
+	score := ScoreResult(long, parse(t, `{"action": {"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "97", "take_profit_trigger_price": "106"}}}`), 5)
+	assert.Equal(t, "open_long_position", score.Action)
+	assert.True(t, score.Agreed)
+	assert.True(t, *score.Correct)
+	assert.True(t, *score.Sane)
+
+	// Wrong direction, stop on the wrong side and take profit on the wrong side
+	score = ScoreResult(long, parse(t, `{"action": {"name": "open_short_position", "args": {"stop_loss_trigger_price": "97", "take_profit_trigger_price": "106"}}}`), 5)
+	assert.False(t, score.Agreed)
+	assert.False(t, *score.Correct)
+	assert.False(t, *score.Sane)
+	assert.Equal(t, []string{
+		"stop loss 97 is on the wrong side of the price 100",
+		"take profit 106 is on the wrong side of the price 100",
+	}, score.Problems)
+
+	// Entries without a stop loss or with a far one are not sane
+	score = ScoreResult(long, parse(t, `{"action": {"name": "open_long_position", "args": {}}}`), 5)
+	assert.Equal(t, []string{"no stop loss"}, score.Problems)
+
+	score = ScoreResult(long, parse(t, `{"action": {"name": "open_long_position", "args": {"stop_loss_trigger_price": "90"}}}`), 5)
+	assert.Equal(t, []string{"stop loss 90 is 10.00% from the price, above 5%"}, score.Problems)
+
+	// No action is flat without a sanity check
+	score = ScoreResult(&Snapshot{ID: "wait", Price: 100, GoodActions: []string{"no_action"}}, parse(t, `{"action": null}`), 5)
+	assert.Equal(t, "no_action", score.Action)
+	assert.True(t, score.Agreed)
+	assert.Nil(t, score.Correct)
+	assert.Nil(t, score.Sane)
+}
+
+func TestReport(t *testing.T) {
+	snapshot := &Snapshot{ID: "long", Price: 100, GoodActions: []string{"open_long_position"}, Direction: DirectionLong}
+
+	report := NewReport("gpt-test")
+	report.Add(ScoreResult(snapshot, parse(t, `{"action": {"name": "open_long_position", "args": {"stop_loss_trigger_price": "97"}}}`), 5))
+	report.Add(ScoreResult(snapshot, parse(t, `{"action": {"name": "open_long_position", "args": {}}}`), 5))
+	report.Add(ScoreResult(snapshot, parse(t, `{"action": {"name": "no_action"}}`), 5))
+	report.Add(ScoreError(snapshot, errors.New("timeout")))
+
+	assert.Equal(t, 50.0, report.Agreement())
+
+	accuracy, labeled := report.DirectionAccuracy()
+	assert.Equal(t, 50.0, accuracy)
+	assert.Equal(t, 4, labeled)
+
+	sanity, entries := report.Sanity()
+	assert.Equal(t, 50.0, sanity)
+	assert.Equal(t, 2, entries)
+
+	assert.Equal(t, 1, report.Errors())
+	assert.Equal(t, "Benchmark of gpt-test on 4 snapshots\nAgreement: 50.0%\nDirection accuracy: 50.0% of 4 labeled\nSL/TP sanity: 50.0% of 2 entries\nErrors: 1", report.String())
+}
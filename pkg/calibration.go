@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/yubing744/trading-gpt/pkg/journal"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// setupCalibration gates entries on their stated confidence, with the minimum adjusted from the journal's
+// calibration when auto_adjust is enabled
+func (s *Strategy) setupCalibration(ctx context.Context) error {
+	cfg := &s.Calibration
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.TargetWinRate == 0 {
+		cfg.TargetWinRate = 0.5
+	}
+	if cfg.MinTrades == 0 {
+		cfg.MinTrades = 20
+	}
+
+	s.confidenceGate = utils.NewConfidenceGate(cfg.MinConfidence)
+
+	if cfg.AutoAdjust {
+		if s.journal == nil {
+			log.Warn("Confidence auto adjust requires the decision journal, using the configured minimum")
+		} else {
+			s.adjustConfidenceGate()
+		}
+	}
+
+	log.WithField("min_confidence", s.confidenceGate.Min()).Info("Confidence gate enabled")
+	return nil
+}
+
+// adjustConfidenceGate moves the minimum confidence to the lowest one whose trades on the symbol reach the
+// target win rate, keeping it while there are too few trades
+func (s *Strategy) adjustConfidenceGate() {
+	if s.confidenceGate == nil || !s.Calibration.AutoAdjust || s.journal == nil {
+		return
+	}
+
+	entries, err := s.journal.LoadEntries()
+	if err != nil {
+		log.WithError(err).Warn("Failed to load journal for confidence calibration")
+		return
+	}
+
+	symbolEntries := make([]*journal.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Symbol == s.Symbol {
+			symbolEntries = append(symbolEntries, entry)
+		}
+	}
+
+	calibration := journal.ComputeCalibration(symbolEntries, 0)
+	min, ok := calibration.SuggestMinConfidence(s.Calibration.TargetWinRate, s.Calibration.MinTrades)
+	if !ok {
+		log.WithField("trades", calibration.Trades).Info("Confidence calibration inconclusive, keeping the minimum confidence")
+		return
+	}
+
+	if min != s.confidenceGate.Min() {
+		log.WithField("min_confidence", min).WithField("calibration", calibration.String()).Info("Minimum confidence adjusted")
+		s.confidenceGate.SetMin(min)
+	}
+}
+
+// checkConfidenceGate returns why an entry is rejected for its stated confidence, empty when it may execute
+func (s *Strategy) checkConfidenceGate(actionName string, args map[string]string) string {
+	if s.confidenceGate == nil || !isEntryAction(actionName) {
+		return ""
+	}
+
+	confidence, stated := journal.ParseConfidence(args["confidence"])
+	return s.confidenceGate.Check(confidence, stated)
+}
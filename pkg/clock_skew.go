@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// clockSkewTimeout bounds a server time query
+const clockSkewTimeout = 10 * time.Second
+
+// setupClockSkew checks the local clock against the exchange at startup and periodically, since skew breaks
+// the timestamps of conditional orders and the ordering of the journal
+func (s *Strategy) setupClockSkew(ctx context.Context) error {
+	cfg := &s.ClockSkew
+	if !cfg.Enabled || s.exchange == nil {
+		return nil
+	}
+
+	if cfg.Interval == 0 {
+		cfg.Interval = types.Duration(30 * time.Minute)
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = types.Duration(time.Second)
+	}
+
+	s.checkClockSkew(ctx)
+
+	go func() {
+		defer s.isolatePanic("clock_skew")
+
+		ticker := time.NewTicker(cfg.Interval.Duration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.checkClockSkew(ctx)
+			}
+		}
+	}()
+
+	log.WithField("config", cfg).Info("Clock skew check enabled")
+	return nil
+}
+
+// checkClockSkew measures the skew, correcting the decision timestamps and warning the admins when it exceeds
+// the threshold. Orders are still signed by bbgo with the local clock, only a synced host clock fixes those.
+func (s *Strategy) checkClockSkew(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, clockSkewTimeout)
+	defer cancel()
+
+	sent := time.Now()
+	serverTime, err := s.exchange.ServerTime(ctx)
+	if err != nil {
+		log.WithError(err).Warn("query exchange server time failed")
+		return
+	}
+
+	skew, rtt := utils.MeasureClockSkew(sent, time.Now(), serverTime)
+	log.WithField("skew", skew).WithField("rtt", rtt).Debug("clock skew measured")
+
+	if skew.Abs() < s.ClockSkew.Threshold.Duration() {
+		if s.clock.Offset() != 0 {
+			log.WithField("skew", skew).Info("clock skew back within the threshold, decision timestamps no longer corrected")
+			s.clock.SetOffset(0)
+		}
+		return
+	}
+
+	corrected := s.clock.Offset() != 0
+	s.clock.SetOffset(skew)
+	if corrected {
+		return
+	}
+
+	msg := fmt.Sprintf("⏱️ The local clock is %s %s the %s server (round trip %s). Decision timestamps are corrected, "+
+		"but orders are signed with the local clock and may be rejected: sync the host clock (NTP).",
+		skew.Abs().Round(time.Millisecond), aheadOrBehind(skew), s.session.ExchangeName, rtt.Round(time.Millisecond))
+	log.Warn(msg)
+	s.notifyAdmins(ctx, ttypes.SeverityWarning, msg)
+}
+
+// aheadOrBehind phrases a skew of the exchange clock from the side of the local clock
+func aheadOrBehind(skew time.Duration) string {
+	if skew > 0 {
+		return "behind"
+	}
+
+	return "ahead of"
+}
@@ -8,4 +8,6 @@ type TradingAgentConfig struct {
 	MaxContextLength int     `json:"max_context_length"`
 	LLM              string  `json:"llm"`
 	Backgroup        string  `json:"backgroup"`
+	StrictParsing    bool    `json:"strict_parsing"` // Reject malformed JSON responses instead of repairing them, the error goes back to the LLM
+	ToolCalling      bool    `json:"tool_calling"`   // Ask for the decision as a tool call where the provider supports tools, JSON responses otherwise
 }
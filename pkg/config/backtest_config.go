@@ -0,0 +1,6 @@
+package config
+
+// BacktestConfig defines the report of a bbgo backtest run of the full decision loop
+type BacktestConfig struct {
+	Report string `json:"report"` // Report file written when the backtest ends, default "memory-bank/backtest-<symbol>.txt"
+}
@@ -0,0 +1,8 @@
+package config
+
+// BlackoutConfig defines when LLM-driven trading is paused after repeated invalid responses
+type BlackoutConfig struct {
+	Enabled     bool `json:"enabled"`      // Whether to pause trading after repeated failures
+	MaxFailures int  `json:"max_failures"` // Failed decision cycles that trigger the blackout, defaults to 3
+	Window      int  `json:"window"`       // Number of latest decision cycles the failures are counted in, defaults to 10
+}
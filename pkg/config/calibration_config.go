@@ -0,0 +1,11 @@
+package config
+
+// CalibrationConfig gates entries on the confidence the agent states with them, optionally moving the minimum
+// to where the measured calibration shows entries start paying off
+type CalibrationConfig struct {
+	Enabled       bool    `json:"enabled"`
+	MinConfidence float64 `json:"min_confidence"`  // Entries stating a lower confidence (0-1) are rejected, 0 only reports the calibration
+	AutoAdjust    bool    `json:"auto_adjust"`     // Move min_confidence to the lowest confidence whose trades reach target_win_rate
+	TargetWinRate float64 `json:"target_win_rate"` // Win rate (0-1) the entries above min_confidence should reach, default: 0.5
+	MinTrades     int     `json:"min_trades"`      // Trades with a stated confidence needed before adjusting, default: 20
+}
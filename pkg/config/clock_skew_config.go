@@ -0,0 +1,10 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// ClockSkewConfig checks the local clock against the exchange server time at startup and periodically
+type ClockSkewConfig struct {
+	Enabled   bool           `json:"enabled"`
+	Interval  types.Duration `json:"interval"`  // Time between the checks, default 30m
+	Threshold types.Duration `json:"threshold"` // Skew from which the operator is warned and decision timestamps are corrected, default 1s
+}
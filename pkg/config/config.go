@@ -6,6 +6,10 @@ import (
 )
 
 type Config struct {
+	// Preset applies a named bundle of defaults (conservative_swing, aggressive_scalp, news_avoidant),
+	// the fields set in this config override it one by one
+	Preset string `json:"preset"`
+
 	Symbol             string           `json:"symbol"`
 	Interval           types.Interval   `json:"interval"`
 	SubscribeIntervals []types.Interval `json:"subscribe_intervals"`
@@ -21,6 +25,13 @@ type Config struct {
 	Strategy                string   `json:"strategy"`
 	StrategyAttentionPoints []string `json:"strategy_attention_points"`
 
+	// Formatters overrides how event data is phrased in prompts, keyed by event type
+	// ("kline", "position", "fng" or an indicator type such as "boll" or "rsi"), using Go templates
+	Formatters map[string]string `json:"formatters"`
+
+	// Precision controls the number formats of kline and position prompts, indicators use their own format
+	Precision PrecisionConfig `json:"precision"`
+
 	// ReflectionPath specifies the directory path where trade reflections will be stored
 	// If not specified, defaults to "memory-bank/reflections/"
 	ReflectionPath string `json:"reflection_path"`
@@ -29,12 +40,113 @@ type Config struct {
 	// If not specified, defaults to true
 	ReflectionEnabled *bool `json:"reflection_enabled,omitempty"`
 
+	// ReflectionMaxRetries is how many times a low-quality reflection is regenerated with feedback before it is discarded
+	// If not specified, defaults to 2
+	ReflectionMaxRetries *int `json:"reflection_max_retries,omitempty"`
+
+	// CounterfactualHorizon is the number of klines to wait after a trade closes before reflecting on it,
+	// so the reflection can compare against holding longer. If not specified, reflections are generated immediately
+	CounterfactualHorizon int `json:"counterfactual_horizon"`
+
+	// ReflectionTrigger filters which closed trades are reflected on
+	ReflectionTrigger ReflectionTriggerConfig `json:"reflection_trigger"`
+
 	// ReadMemoryEnabled controls whether the system reads from memory bank reflections
 	// If not specified, defaults to true
 	ReadMemoryEnabled *bool `json:"read_memory_enabled,omitempty"`
 
 	// Memory configuration for file-based memory function
 	Memory MemoryConfig `json:"memory"`
+
+	// Journal configuration for decision journaling
+	Journal JournalConfig `json:"journal"`
+
+	// Review configuration for the scheduled strategy review memo
+	Review ReviewConfig `json:"review"`
+
+	// GRPC configuration for the control and decision API
+	GRPC GRPCConfig `json:"grpc"`
+
+	// PreTrade configuration for simulating entries against risk limits before execution
+	PreTrade PreTradeConfig `json:"pre_trade"`
+
+	// Funding configuration for weighing perpetual funding when holding a position
+	Funding FundingConfig `json:"funding"`
+
+	// Blackout configuration for pausing trading after repeated unparseable or invalid responses
+	Blackout BlackoutConfig `json:"blackout"`
+
+	// KlinePrompt configuration for downsampling long kline windows in prompts
+	KlinePrompt KlinePromptConfig `json:"kline_prompt"`
+
+	// Snapshot configuration for saving the strategy state and restoring it after restarts
+	StateSnapshot SnapshotConfig `json:"snapshot"`
+
+	// InstanceLock configuration for keeping a second instance on the same account and symbol observe-only
+	InstanceLock InstanceLockConfig `json:"instance_lock"`
+
+	// FlipGuard configuration for holding back direction reversals shortly after an entry
+	FlipGuard FlipGuardConfig `json:"flip_guard"`
+
+	// DeadMan configuration for pausing entries and tightening stops without operator heartbeats
+	DeadMan DeadManConfig `json:"dead_man"`
+
+	// PromptDedup configuration for compressing prompt sections unchanged since the last cycle
+	PromptDedup PromptDedupConfig `json:"prompt_dedup"`
+
+	// Sampling configuration for choosing the sampling parameters of decisions by market state
+	Sampling SamplingScheduleConfig `json:"sampling"`
+
+	// StaleGuard configuration for re-validating entries decided on stale prompt data
+	StaleGuard StaleGuardConfig `json:"stale_guard"`
+
+	// Fade configuration for executing the inverse of the agent's entries in backtests and shadow mode
+	Fade FadeConfig `json:"fade"`
+
+	// CycleDeadline configuration for bounding the calls of a decision cycle by the kline interval
+	CycleDeadline CycleDeadlineConfig `json:"cycle_deadline"`
+
+	// MarketInfo configuration for loading the precisions and order size limits of the market at startup
+	MarketInfo MarketInfoConfig `json:"market_info"`
+
+	// EventLog configuration for recording the env events and replaying them into the agent
+	EventLog EventLogConfig `json:"event_log"`
+
+	// SampleBank configuration for the few-shot samples shown with the commands
+	SampleBank SampleBankConfig `json:"sample_bank"`
+
+	// Calibration configuration for gating entries on their stated confidence
+	Calibration CalibrationConfig `json:"calibration"`
+
+	// LossStreak configuration for throttling entries after consecutive losing trades
+	LossStreak LossStreakConfig `json:"loss_streak"`
+
+	// Exposure configuration for capping the margin of the instances sharing an account
+	Exposure ExposureConfig `json:"exposure"`
+
+	// RiskBudget configuration for shrinking the risk per trade in a drawdown
+	RiskBudget RiskBudgetConfig `json:"risk_budget"`
+
+	// CycleDiff configuration for the changes since the last cycle at the top of the prompt
+	CycleDiff CycleDiffConfig `json:"cycle_diff"`
+
+	// Testnet configuration for running against exchange sandbox accounts
+	Testnet TestnetConfig `json:"testnet"`
+
+	// Recovery configuration for dumping the open risk on critical failures
+	Recovery RecoveryConfig `json:"recovery"`
+
+	// Backtest configuration for the report of bbgo backtests
+	Backtest BacktestConfig `json:"backtest"`
+
+	// ClockSkew configuration for checking the local clock against the exchange server time
+	ClockSkew ClockSkewConfig `json:"clock_skew"`
+
+	// Debug configuration for diagnostics of the decision cycles
+	Debug DebugConfig `json:"debug"`
+
+	// Risk configuration for halting new entries on the daily loss and max drawdown limits
+	Risk RiskConfig `json:"risk"`
 }
 
 // MemoryConfig defines configuration for the file-based memory system
@@ -42,4 +154,28 @@ type MemoryConfig struct {
 	Enabled    bool   `json:"enabled"`     // Whether to enable memory function
 	MemoryPath string `json:"memory_path"` // Path to memory file
 	MaxWords   int    `json:"max_words"`   // Maximum word limit for memory
+
+	// Retrieval of trade reflections, active when read_memory_enabled is true
+	RetrievalTopK     int            `json:"retrieval_top_k"`     // Number of relevant reflections injected into prompts (default: 3)
+	RelevanceLLM      bool           `json:"relevance_llm"`       // Score reflection relevance with the LLM instead of keyword matching
+	RelevanceCacheTTL types.Interval `json:"relevance_cache_ttl"` // How long LLM relevance scores are reused for similar situations (default: 30m)
+
+	// Embedding similarity retrieval, used when relevance_llm is false
+	Embeddings         bool   `json:"embeddings"`           // Retrieve reflections by embedding similarity instead of keyword matching
+	EmbeddingCachePath string `json:"embedding_cache_path"` // Path of the on-disk embedding cache (default: memory-bank/embeddings.json)
+	RerankCandidates   int    `json:"rerank_candidates"`    // With relevance_llm, reflections shortlisted by embeddings for LLM scoring (default: 3 x retrieval_top_k)
+
+	// Episodic memories are specific trade reflections, semantic memories are general rules distilled from them
+	ConsolidateEvery      int `json:"consolidate_every"`       // Distill rules after every N new reflections, 0 disables consolidation
+	EpisodicRetentionDays int `json:"episodic_retention_days"` // Reflections older than this are no longer retrieved (default: 90)
+	SemanticMaxCount      int `json:"semantic_max_count"`      // Number of latest rule sets kept retrievable (default: 3)
+
+	// Knowledge base of user-authored markdown playbooks, retrieved alongside memories
+	KnowledgeBasePath string `json:"knowledge_base_path"` // Directory of playbooks, empty disables the knowledge base
+	KnowledgeTopK     int    `json:"knowledge_top_k"`     // Number of relevant playbooks injected into prompts (default: 2)
+
+	// Importance of memories evolving from the outcomes of the decisions they were injected into, requires the journal
+	Reinforcement bool `json:"reinforcement"` // Bump the injected memories when the trade wins, decrement them when it loses
+	PromoteAt     int  `json:"promote_at"`    // Reinforcement from which a memory takes the first retrieval slots (default: 2)
+	DemoteAt      int  `json:"demote_at"`     // Reinforcement from which a memory is no longer retrieved (default: -2)
 }
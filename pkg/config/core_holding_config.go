@@ -0,0 +1,7 @@
+package config
+
+// CoreHoldingConfig declares a core spot holding of the base currency the bot must never sell
+type CoreHoldingConfig struct {
+	Enabled  bool    `json:"enabled"`
+	Quantity float64 `json:"quantity"` // Base currency the account always keeps, e.g. 0.5 for 0.5 BTC
+}
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CycleDeadlineConfig bounds every LLM, exchange and memory call of a decision cycle by a deadline derived
+// from the kline interval, so one slow call can't cascade into skipped klines
+type CycleDeadlineConfig struct {
+	Enabled bool           `json:"enabled"`
+	Ratio   float64        `json:"ratio"` // Share of the kline interval a cycle may take, default 0.8
+	Min     types.Duration `json:"min"`   // Shortest deadline, default 30s
+}
@@ -0,0 +1,6 @@
+package config
+
+// CycleDiffConfig defines the summary of the changes since the last decision cycle shown at the top of the prompt
+type CycleDiffConfig struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,12 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// DeadManConfig defines the dead-man's switch that stops new entries when the operator stops answering heartbeats
+type DeadManConfig struct {
+	Enabled             bool           `json:"enabled"`
+	PingInterval        types.Duration `json:"ping_interval"`         // How often the operator is asked to acknowledge, default 4h
+	Window              types.Duration `json:"window"`                // Time to acknowledge a ping before the switch trips, default 30m
+	TightenRatio        float64        `json:"tighten_ratio"`         // Fraction of the stop distance kept when tripped, default 0.5
+	FallbackStopPercent float64        `json:"fallback_stop_percent"` // Stop distance in percent for a position without stop, default 2
+}
@@ -0,0 +1,6 @@
+package config
+
+// DebugConfig enables diagnostics of the decision cycles
+type DebugConfig struct {
+	ContextBudget bool `json:"context_budget"` // Log the estimated tokens of each prompt section per decision cycle
+}
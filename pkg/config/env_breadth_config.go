@@ -0,0 +1,16 @@
+package config
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MarketBreadthConfig configures the entity emitting macro crypto market context
+type MarketBreadthConfig struct {
+	Enabled      bool           `json:"enabled"`
+	BaseURL      string         `json:"base_url"`      // CoinGecko compatible API, default: https://api.coingecko.com/api/v3
+	APIKey       string         `json:"api_key"`       // Optional, read from COINGECKO_API_KEY when empty
+	Interval     types.Interval `json:"interval"`      // How often market breadth is refreshed, default: 4h
+	TopN         int            `json:"top_n"`         // Number of top coins by market cap used for breadth, default: 50
+	MADays       int            `json:"ma_days"`       // Moving average length in days, default: 20
+	RequestDelay types.Interval `json:"request_delay"` // Delay between price history requests to respect rate limits, default: 2s
+}
@@ -0,0 +1,13 @@
+package config
+
+// BridgeConfig configures the pub-sub bridge to external quant infrastructure
+type BridgeConfig struct {
+	Enabled       bool   `json:"enabled"`
+	Driver        string `json:"driver"`         // Pub-sub backend, only "redis" is supported
+	Addr          string `json:"addr"`           // Server address, default: localhost:6379
+	Password      string `json:"password"`       // Read from BRIDGE_PASSWORD when empty
+	DB            int    `json:"db"`             // Redis database
+	InputChannel  string `json:"input_channel"`  // Channel of external signals and commands, empty disables input
+	OutputChannel string `json:"output_channel"` // Channel the bot's events are published to, empty disables output
+	AllowCommands bool   `json:"allow_commands"` // Execute commands received on the input channel
+}
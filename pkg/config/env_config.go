@@ -5,5 +5,12 @@ type EnvConfig struct {
 	FNG            *FNGConfig              `json:"fng"`
 	Coze           *CozeEntityConfig       `json:"coze"`
 	TwitterAPI     *TwitterAPIEntityConfig `json:"twitterapi"`
+	Divergence     *PriceDivergenceConfig  `json:"divergence"`
+	Breadth        *MarketBreadthConfig    `json:"breadth"`
+	Funding        *FundingRateConfig      `json:"funding"`
+	Spread         *SpreadConfig           `json:"spread"`
+	REST           *RESTEntityConfig       `json:"rest"`
+	Bridge         *BridgeConfig           `json:"bridge"`
+	Plugins        []*EntityPluginConfig   `json:"plugins"`
 	IncludeEvents  []string                `json:"include_events"`
 }
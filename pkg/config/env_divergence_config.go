@@ -0,0 +1,15 @@
+package config
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PriceDivergenceConfig configures the monitor comparing the local price against a reference exchange
+type PriceDivergenceConfig struct {
+	Enabled          bool           `json:"enabled"`
+	ReferenceSession string         `json:"reference_session"` // bbgo session name of the reference exchange
+	ReferenceSymbol  string         `json:"reference_symbol"`  // Symbol on the reference exchange, defaults to the traded symbol
+	Interval         types.Interval `json:"interval"`          // How often prices are compared, defaults to 1m
+	Threshold        float64        `json:"threshold"`         // Divergence in percent considered abnormal, defaults to 1.0
+	BlockEntries     bool           `json:"block_entries"`     // Reject new positions while the divergence is abnormal
+}
@@ -27,12 +27,40 @@ const (
 	IndicatorTypeGHFilter     IndicatorType = "ghfilter"
 	IndicatorTypeKalmanFilter IndicatorType = "kalmanfilter"
 	IndicatorTypeVR           IndicatorType = "vr"
+
+	// Indicators computed in Go by the pkg/indicators package
+	IndicatorTypeSuperTrend IndicatorType = "supertrend"
+	IndicatorTypeDonchian   IndicatorType = "donchian"
+	IndicatorTypeKeltner    IndicatorType = "keltner"
 )
 
 type IndicatorConfig struct {
-	Type   IndicatorType     `json:"type"`
-	MaxNum *int              `json:"max_num"`
-	Params map[string]string `json:"params"`
+	Type     IndicatorType        `json:"type"`
+	MaxNum   *int                 `json:"max_num"`
+	Params   map[string]string    `json:"params"`
+	Format   NumberFormat         `json:"format"`    // Number format of the indicator values in prompts
+	KlineNum int                  `json:"kline_num"` // Klines the indicator needs, e.g. 200 for EMA200, defaults to window_size with adaptive_kline_num
+	Alerts   []IndicatorAlertRule `json:"alerts"`    // Threshold crossings reported as indicator_alert events
+}
+
+type IndicatorAlertCondition string
+
+const (
+	AlertCrossAbove          IndicatorAlertCondition = "cross_above"           // Indicator value crosses above Value
+	AlertCrossBelow          IndicatorAlertCondition = "cross_below"           // Indicator value crosses below Value
+	AlertPriceCrossUpBand    IndicatorAlertCondition = "price_cross_up_band"   // Close price crosses above the BOLL upper band
+	AlertPriceCrossDownBand  IndicatorAlertCondition = "price_cross_down_band" // Close price crosses below the BOLL lower band
+	AlertCrossAboveIndicator IndicatorAlertCondition = "cross_above_indicator" // Indicator crosses above another indicator, e.g. fast and slow EMA
+	AlertCrossBelowIndicator IndicatorAlertCondition = "cross_below_indicator" // Indicator crosses below another indicator
+	AlertTrendFlip           IndicatorAlertCondition = "trend_flip"            // SuperTrend flips or the close breaks out of the Donchian channel
+)
+
+// IndicatorAlertRule turns a threshold crossing of the indicator into an explicit event
+type IndicatorAlertRule struct {
+	Condition IndicatorAlertCondition `json:"condition"`
+	Value     float64                 `json:"value"`     // Threshold of cross_above and cross_below
+	Indicator string                  `json:"indicator"` // Name of the other indicator of the crossover conditions
+	Message   string                  `json:"message"`   // Description of the alert, generated when empty
 }
 
 func (cfg IndicatorConfig) GetString(key string, def string) string {
@@ -97,12 +125,89 @@ func (cfg IndicatorConfig) GetInterval(key string, def types.Interval) types.Int
 
 type EnvExchangeConfig struct {
 	KlineNum            int                         `json:"kline_num"`
+	AdaptiveKlineNum    bool                        `json:"adaptive_kline_num"` // Keep as many klines as the longest indicator needs
 	Indicators          map[string]*IndicatorConfig `json:"indicators"`
 	HandlePositionClose bool                        `json:"handle_position_close"`
 	CleanPosition       CleanPositionConfig         `json:"clean_position"`
+	Execution           ExecutionConfig             `json:"execution"`
+	LeverageScaling     LeverageScalingConfig       `json:"leverage_scaling"`
+	ProfitRatchet       ProfitRatchetConfig         `json:"profit_ratchet"`
+	TriggerPrice        TriggerPriceConfig          `json:"trigger_price"`
+	FlatBy              FlatByConfig                `json:"flat_by"`
+	RiskSizing          RiskSizingConfig            `json:"risk_sizing"`
+	PositionSizing      PositionSizingConfig        `json:"position_sizing"`
+	Grid                GridConfig                  `json:"grid"`
+	PriceAlert          PriceAlertConfig            `json:"price_alert"`
+	LimitOrder          LimitOrderConfig            `json:"limit_order"`
+	Intent              IntentConfig                `json:"intent"`
+	Retry               RetryConfig                 `json:"retry"`
+	Venue               VenueConfig                 `json:"venue"`
+	CoreHolding         CoreHoldingConfig           `json:"core_holding"`
+	PositionCheck       PositionCheckConfig         `json:"position_check"`
+	Direction           string                      `json:"direction"`       // Sides positions may be opened on: long_only, short_only or both (default)
+	DynamicActions      bool                        `json:"dynamic_actions"` // Only offer the actions valid in the current state, e.g. no close_position while flat
+}
+
+// Direction policies of the exchange entity
+const (
+	DirectionBoth      = "both"
+	DirectionLongOnly  = "long_only"
+	DirectionShortOnly = "short_only"
+)
+
+// RequiredKlineNum returns the number of klines to keep: the configured number, raised to the longest
+// indicator lookback when adaptive
+func (cfg *EnvExchangeConfig) RequiredKlineNum() int {
+	num := cfg.KlineNum
+
+	for _, indicator := range cfg.Indicators {
+		lookback := indicator.KlineNum
+		if lookback == 0 && cfg.AdaptiveKlineNum {
+			lookback = indicator.GetInt("window_size", 0)
+		}
+
+		if lookback > num {
+			num = lookback
+		}
+	}
+
+	return num
 }
 
 type CleanPositionConfig struct {
 	Enabled  bool           `json:"enabled"`
 	Interval types.Interval `json:"interval"`
 }
+
+// ExecutionConfig splits large market orders to reduce slippage
+type ExecutionConfig struct {
+	Algo        string         `json:"algo"`         // market (default), twap or peg
+	MinNotional float64        `json:"min_notional"` // Order size in quote currency from which the algo is used
+	Slices      int            `json:"slices"`       // Number of child orders, default 5
+	Duration    types.Duration `json:"duration"`     // Time to work the order over, default 1m
+}
+
+// LeverageScalingConfig scales the leverage of new entries inversely with the ATR percentile
+type LeverageScalingConfig struct {
+	Enabled     bool    `json:"enabled"`
+	MinLeverage float64 `json:"min_leverage"` // Leverage at the most volatile ATR of the kline window, default 1
+	MaxLeverage float64 `json:"max_leverage"` // Leverage at the calmest ATR of the kline window, defaults to the strategy leverage
+	ATRPeriod   int     `json:"atr_period"`   // Number of klines per ATR, default 14
+}
+
+// RiskSizingConfig defines the limits of entries sized by the risk per trade the agent asks for
+type RiskSizingConfig struct {
+	MaxRiskPercent float64 `json:"max_risk_percent"` // Largest percent of equity an entry may risk at its stop, default 2
+}
+
+// PositionSizingConfig defines the limits of entries sized by the share of the balance the agent asks for
+type PositionSizingConfig struct {
+	MaxPercent float64 `json:"max_percent"` // Largest percent of the leveraged balance an entry may use, default 100
+}
+
+// TriggerPriceConfig defines how far stop-loss and take-profit triggers are kept from the current price,
+// after snapping them to the tick size
+type TriggerPriceConfig struct {
+	MinDistanceTicks   int     `json:"min_distance_ticks"`   // Minimum distance in ticks, default 1
+	MinDistancePercent float64 `json:"min_distance_percent"` // Minimum distance in percent of the price, e.g. 0.1 for 0.1%
+}
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// FundingRateConfig configures the entity emitting the perpetual funding rate of the traded pair
+type FundingRateConfig struct {
+	Enabled  bool           `json:"enabled"`
+	BaseURL  string         `json:"base_url"` // OKX compatible API, default: https://www.okx.com
+	InstID   string         `json:"inst_id"`  // Perpetual swap instrument, defaults to <base>-<quote>-SWAP
+	Interval types.Interval `json:"interval"` // How often the funding rate is polled, default: 1h
+}
@@ -0,0 +1,11 @@
+package config
+
+import "encoding/json"
+
+// EntityPluginConfig enables an entity registered with env.RegisterEntityFactory, so entities compiled in
+// by a third party are turned on purely from the config
+type EntityPluginConfig struct {
+	Type    string          `json:"type"` // The registered entity type
+	Enabled bool            `json:"enabled"`
+	Config  json.RawMessage `json:"config"` // Passed to the entity factory as is
+}
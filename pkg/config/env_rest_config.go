@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// RESTSourceItem is a JSON HTTP endpoint polled on a schedule
+type RESTSourceItem struct {
+	Name        string            `json:"name"`        // Event type emitted for this source, must be unique
+	Description string            `json:"description"` // Title shown above the formatted data in prompts
+	URL         string            `json:"url"`         // Endpoint URL, ${ENV_VAR} references are expanded
+	Method      string            `json:"method"`      // HTTP method, default: GET
+	Headers     map[string]string `json:"headers"`     // Request headers, ${ENV_VAR} references are expanded
+	Body        string            `json:"body"`        // Optional request body
+	Interval    types.Interval    `json:"interval"`    // How often to poll the endpoint
+	Fields      map[string]string `json:"fields"`      // Template variable name to JSONPath, e.g. rate: "$.data[0].fundingRate"
+	Template    string            `json:"template"`    // Go template rendering the fields, lists them when empty
+}
+
+// RESTEntityConfig holds the configuration of the generic REST data source entity
+type RESTEntityConfig struct {
+	Enabled bool              `json:"enabled"`
+	Timeout types.Interval    `json:"timeout"`
+	Sources []*RESTSourceItem `json:"sources"`
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// SpreadConfig configures two-leg spread and hedge trades, e.g. long BTC / short ETH or spot long + perp short
+type SpreadConfig struct {
+	Enabled     bool                        `json:"enabled"`
+	Legs        map[string]*SpreadLegConfig `json:"legs"`         // Instruments a leg may trade, keyed by the name the agent refers to them by
+	MaxNotional float64                     `json:"max_notional"` // Largest notional of the long leg in quote currency, also the default
+	Interval    types.Interval              `json:"interval"`     // How often the combined PnL is reported, defaults to the strategy interval
+}
+
+// SpreadLegConfig is an instrument a spread leg can trade
+type SpreadLegConfig struct {
+	Session string `json:"session"` // bbgo session name, defaults to the strategy session; use a futures session for perp legs
+	Symbol  string `json:"symbol"`
+}
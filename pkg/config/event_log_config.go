@@ -0,0 +1,15 @@
+package config
+
+// EventLogConfig records the env events fed to the agent to an append-only log, and replays a recorded log
+type EventLogConfig struct {
+	Enabled bool              `json:"enabled"` // Record the env events
+	Path    string            `json:"path"`    // Log file, defaults to "memory-bank/events-<symbol>.jsonl"
+	Replay  EventReplayConfig `json:"replay"`
+}
+
+// EventReplayConfig feeds a recorded event log to the agent instead of the live env, in observe-only mode
+type EventReplayConfig struct {
+	Enabled bool    `json:"enabled"`
+	Path    string  `json:"path"`  // Log to replay, defaults to the record path
+	Speed   float64 `json:"speed"` // Multiple of the recorded pace, 0 replays without waiting between events
+}
@@ -0,0 +1,16 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// ExposureConfig defines the ledger that caps the margin used by all the instances trading a shared account
+type ExposureConfig struct {
+	Enabled        bool           `json:"enabled"`
+	MaxMarginUsage float64        `json:"max_margin_usage"` // Max margin of all instances together, in percent of the account equity, default 80
+	Driver         string         `json:"driver"`           // Ledger backend: "file" (default) or "redis"
+	Path           string         `json:"path"`             // Ledger file of the file driver, defaults to "memory-bank/exposure-<session>.json"
+	Addr           string         `json:"addr"`             // Redis address of the redis driver, default: localhost:6379
+	Password       string         `json:"password"`         // Read from EXPOSURE_PASSWORD when empty
+	DB             int            `json:"db"`               // Redis database
+	Key            string         `json:"key"`              // Redis hash of the redis driver, defaults to "trading-gpt:exposure:<session>"
+	TTL            types.Duration `json:"ttl"`              // How long a published margin counts without an update, refreshed every third of it, defaults to 1m
+}
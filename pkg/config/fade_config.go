@@ -0,0 +1,7 @@
+package config
+
+// FadeConfig configures the fade experiment, executing the inverse of the agent's entries to measure its edge
+type FadeConfig struct {
+	Enabled   bool `json:"enabled"`
+	AllowLive bool `json:"allow_live"` // Also fade outside backtests, e.g. in shadow mode on a paper account
+}
@@ -0,0 +1,12 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// FlatByConfig defines a daily or weekly deadline by which the position must be closed
+type FlatByConfig struct {
+	Enabled    bool           `json:"enabled"`
+	Time       string         `json:"time"`        // Deadline clock time "HH:MM", e.g. "21:00"
+	Weekday    string         `json:"weekday"`     // Weekday of a weekly deadline, e.g. "friday", daily when empty
+	Timezone   string         `json:"timezone"`    // IANA timezone of the deadline, default UTC
+	WarnBefore types.Duration `json:"warn_before"` // How long before the deadline the agent is warned, default 1h
+}
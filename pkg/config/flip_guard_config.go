@@ -0,0 +1,9 @@
+package config
+
+// FlipGuardConfig defines the hysteresis that holds back direction reversals shortly after an entry
+type FlipGuardConfig struct {
+	Enabled       bool    `json:"enabled"`
+	Window        int     `json:"window"`         // Klines after an entry during which a reversal needs confirmation, default 6
+	Confirmations int     `json:"confirmations"`  // Consecutive decision cycles that must ask for the reversal, default 2
+	MinConfidence float64 `json:"min_confidence"` // Confidence (0-1) that reverses at once, 0 disables the confidence override
+}
@@ -0,0 +1,12 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// FundingConfig defines how perpetual funding rates are weighed in the position prompt
+type FundingConfig struct {
+	Enabled   bool           `json:"enabled"`   // Whether to add the funding carry to the position prompt
+	InstID    string         `json:"inst_id"`   // Perpetual swap instrument, defaults to <base>-<quote>-SWAP
+	Threshold float64        `json:"threshold"` // Funding rate per period from which funding counts as strong, defaults to 0.0005 (0.05%)
+	HoldHint  bool           `json:"hold_hint"` // Suggest holding for funding when it strongly favors the position
+	Interval  types.Duration `json:"interval"`  // Refresh interval of the funding rate, defaults to 10m
+}
@@ -0,0 +1,10 @@
+package config
+
+// GridConfig limits the grids the agent may start on the traded symbol
+type GridConfig struct {
+	Enabled      bool    `json:"enabled"`
+	MaxLevels    int     `json:"max_levels"`     // Largest number of price levels, default 20
+	MaxNotional  float64 `json:"max_notional"`   // Largest notional of all resting orders in quote currency, 0 is unlimited
+	BreakPercent float64 `json:"break_percent"`  // Distance outside the range in percent that stops the grid, default 1
+	CloseOnBreak bool    `json:"close_on_break"` // Close the position built by the grid when the range breaks
+}
@@ -0,0 +1,8 @@
+package config
+
+// GRPCConfig defines configuration for the gRPC control and decision API
+type GRPCConfig struct {
+	Enabled bool   `json:"enabled"` // Whether to start the gRPC server
+	Addr    string `json:"addr"`    // Listen address, defaults to ":50051", or "127.0.0.1:50051" without a token
+	Token   string `json:"token"`   // Bearer token required from clients, read from GRPC_TOKEN when empty, required to listen beyond localhost
+}
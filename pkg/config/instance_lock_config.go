@@ -0,0 +1,14 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// InstanceLockConfig defines the lock that lets only one instance trade an account and symbol
+type InstanceLockConfig struct {
+	Enabled  bool           `json:"enabled"`  // Whether instances without the lock stay observe-only
+	Driver   string         `json:"driver"`   // Lock backend: "file" (default) or "redis"
+	Path     string         `json:"path"`     // Lock file of the file driver, defaults to "memory-bank/lock-<session>-<symbol>.json"
+	Addr     string         `json:"addr"`     // Redis address of the redis driver, default: localhost:6379
+	Password string         `json:"password"` // Read from LOCK_PASSWORD when empty
+	DB       int            `json:"db"`       // Redis database
+	TTL      types.Duration `json:"ttl"`      // Lease duration, renewed every third of it, defaults to 30s
+}
@@ -0,0 +1,8 @@
+package config
+
+// IntentConfig lets the agent schedule plans deferred until a kline closes beyond a price
+type IntentConfig struct {
+	Enabled    bool `json:"enabled"`
+	MaxIntents int  `json:"max_intents"` // Largest number of pending intents, default 5
+	MaxBars    int  `json:"max_bars"`    // Most klines an intent may wait for its condition, default 20
+}
@@ -0,0 +1,18 @@
+package config
+
+// JournalConfig defines configuration for the decision journal
+type JournalConfig struct {
+	Enabled bool   `json:"enabled"` // Whether to record decisions in the journal
+	Path    string `json:"path"`    // Path to journal file, defaults to "memory-bank/journal.jsonl"
+
+	// NoActionSummaryEvery sends a compact "why I'm staying flat" summary to the
+	// notification channel after every N consecutive no_action decisions, 0 disables it
+	NoActionSummaryEvery int `json:"no_action_summary_every"`
+
+	// StatsWindow is the number of latest closed trades used for the per-symbol track record
+	// injected into decision prompts (default: 50)
+	StatsWindow int `json:"stats_window"`
+
+	// RecordPrompts stores the prompt and raw completion of each decision, for exporting fine-tuning datasets
+	RecordPrompts bool `json:"record_prompts"`
+}
@@ -0,0 +1,10 @@
+package config
+
+// KlinePromptConfig defines how the kline window is shown in prompts
+type KlinePromptConfig struct {
+	Downsample     bool    `json:"downsample"`       // Merge klines older than the recent ones into wider candlesticks to fit max_num rows
+	Recent         int     `json:"recent"`           // Number of latest klines kept at full resolution, defaults to half of max_num
+	HeikinAshi     bool    `json:"heikin_ashi"`      // Add the klines transformed into Heikin-Ashi candlesticks
+	Renko          bool    `json:"renko"`            // Add renko bricks built from the closes
+	RenkoBrickSize float64 `json:"renko_brick_size"` // Price move of a renko brick, defaults to the 14-kline ATR
+}
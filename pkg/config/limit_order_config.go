@@ -0,0 +1,9 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// LimitOrderConfig defines what happens to limit entries the market did not fill
+type LimitOrderConfig struct {
+	Timeout          types.Duration `json:"timeout"`            // Cancel an unfilled limit entry after this long, 0 leaves it until the next decision cycle
+	FallbackToMarket bool           `json:"fallback_to_market"` // Enter the unfilled rest with a market order after the timeout
+}
@@ -1,5 +1,7 @@
 package config
 
+import "github.com/c9s/bbgo/pkg/types"
+
 type OpenAIConfig struct {
 	Token        string `json:"token"`
 	Model        string `json:"model"`
@@ -13,6 +15,16 @@ type OllamaConfig struct {
 	Format    string `json:"format"`
 }
 
+// LocalConfig is an open-weight model served locally by an OpenAI-compatible endpoint (Ollama, llama.cpp server)
+type LocalConfig struct {
+	BaseURL       string         `json:"base_url"`       // Default http://localhost:11434/v1 (Ollama)
+	Model         string         `json:"model"`          // Model name served by the endpoint
+	Timeout       types.Duration `json:"timeout"`        // Request timeout, default 5m
+	ContextLength int            `json:"context_length"` // Context window in tokens, default 8192
+	MaxTokens     int            `json:"max_tokens"`     // Completion tokens reserved in the context, default 1024
+	NoSystemRole  bool           `json:"no_system_role"` // Send system messages as user messages
+}
+
 type AnthropicConfig struct {
 	Token            string `json:"token"`
 	Model            string `json:"model"`
@@ -26,6 +38,13 @@ type GoogleAIConfig struct {
 	Model  string `json:"model"`
 }
 
+// LLMCacheConfig records the responses of the model by prompt, and replays them so backtests of the same klines
+// are reproducible and cost no LLM calls
+type LLMCacheConfig struct {
+	Mode string `json:"mode"` // record (call and save), replay (saved responses only) or read_through (call on misses)
+	Path string `json:"path"` // Cache file, default "memory-bank/llm-cache.jsonl"
+}
+
 type LLMConfig struct {
 	Primary   string           `json:"primary,omitempty"`
 	Secondly  string           `json:"secondly,omitempty"`
@@ -33,4 +52,15 @@ type LLMConfig struct {
 	Ollama    *OllamaConfig    `json:"ollama,omitempty"`
 	Anthropic *AnthropicConfig `json:"anthropic,omitempty"`
 	GoogleAI  *GoogleAIConfig  `json:"googleai,omitempty"`
+	Local     *LocalConfig     `json:"local,omitempty"`
+
+	// NativeJSON overrides by provider whether JSON responses use the provider's native JSON mode,
+	// native by default for openai, ollama, googleai and local
+	NativeJSON map[string]bool `json:"native_json,omitempty"`
+
+	// ToolCalling overrides by provider whether decisions asked for as a tool call use the provider's native
+	// tool calls, native by default for openai and anthropic
+	ToolCalling map[string]bool `json:"tool_calling,omitempty"`
+
+	Cache *LLMCacheConfig `json:"cache,omitempty"`
 }
@@ -0,0 +1,9 @@
+package config
+
+// LossStreakConfig throttles entries after consecutive losing trades, until a winning trade or an operator reset
+type LossStreakConfig struct {
+	Enabled       bool    `json:"enabled"`
+	Losses        int     `json:"losses"`         // Consecutive losing trades that engage the throttle, default 3
+	SizeScale     float64 `json:"size_scale"`     // Share of the normal position size entered while throttled, default 0.5
+	MinConfidence float64 `json:"min_confidence"` // Confidence (0-1) entries need while throttled, default 0.8
+}
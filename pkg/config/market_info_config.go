@@ -0,0 +1,10 @@
+package config
+
+// MarketInfoConfig loads the precisions, order size limits and contract multiplier of the symbol at startup,
+// for prompt formatting, validation and order construction
+type MarketInfoConfig struct {
+	Enabled   bool   `json:"enabled"`
+	InstID    string `json:"inst_id"`    // OKX instrument to read the contract multiplier from, e.g. BTC-USDT-SWAP, empty uses the market only
+	InstType  string `json:"inst_type"`  // OKX instrument type, default: SWAP
+	CachePath string `json:"cache_path"` // Where the info is cached for startups without network, default: memory-bank/market-<symbol>.json
+}
@@ -7,4 +7,6 @@ type NotifyFeishuConfig struct {
 	TenantKey     string `json:"tenant_key"`
 	ReceiveIdType string `json:"receive_id_type"`
 	ReceiveId     string `json:"receive_id"`
+
+	NotifyRouteConfig
 }
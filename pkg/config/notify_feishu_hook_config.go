@@ -3,4 +3,6 @@ package config
 type NotifyFeishuHookConfig struct {
 	Enabled bool   `json:"enabled"`
 	URL     string `json:"url"`
+
+	NotifyRouteConfig
 }
@@ -0,0 +1,7 @@
+package config
+
+// NotifyRouteConfig selects which severities a notify channel receives and how often
+type NotifyRouteConfig struct {
+	Levels    []string       `json:"levels"`     // Severities sent to the channel: info, action, warning, critical, all when empty
+	RateLimit map[string]int `json:"rate_limit"` // Max messages per minute for each severity, unlimited when missing or 0
+}
@@ -0,0 +1,23 @@
+package config
+
+// DefaultDecimals is the number of decimal places used when no precision is configured
+const DefaultDecimals = 3
+
+// NumberFormat controls how a numeric value is rendered in prompts
+type NumberFormat struct {
+	Decimals *int `json:"decimals,omitempty"` // Decimal places, defaults to 3
+	SigFigs  int  `json:"sig_figs,omitempty"` // Significant figures, takes precedence over decimals when set
+	Percent  bool `json:"percent,omitempty"`  // Render ratios as percentages, e.g. 0.0123 as 1.23%
+}
+
+// IsSet reports whether the format specifies a precision
+func (f NumberFormat) IsSet() bool {
+	return f.Decimals != nil || f.SigFigs > 0
+}
+
+// PrecisionConfig defines the number formats of kline and position prompts
+type PrecisionConfig struct {
+	Price   NumberFormat `json:"price"`   // Prices such as open/close, average cost and SL/TP
+	Volume  NumberFormat `json:"volume"`  // Trading volumes
+	Percent NumberFormat `json:"percent"` // Values already in percent such as change% and position profit, the percent flag is ignored
+}
@@ -0,0 +1,10 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// PositionCheckConfig periodically compares the local position with the one the exchange reports
+type PositionCheckConfig struct {
+	Enabled   bool           `json:"enabled"`
+	Interval  types.Duration `json:"interval"`  // Time between the checks, default 5m
+	Tolerance float64        `json:"tolerance"` // Difference in base currency ignored as dust, defaults to the market minimum quantity
+}
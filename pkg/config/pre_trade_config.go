@@ -0,0 +1,11 @@
+package config
+
+// PreTradeConfig defines the pre-trade simulation of proposed entries and its risk limits
+type PreTradeConfig struct {
+	Enabled               bool    `json:"enabled"`                 // Simulate open position actions before execution
+	Confirm               bool    `json:"confirm"`                 // Echo the simulation to the LLM and execute only the confirmed action
+	RequireStopLoss       bool    `json:"require_stop_loss"`       // Reject entries without a stop loss
+	MaxLossPercent        float64 `json:"max_loss_percent"`        // Max loss at the stop loss, in percent of equity, 0 disables the check
+	MaxMarginUsage        float64 `json:"max_margin_usage"`        // Max margin used by the position, in percent of equity, 0 disables the check
+	MaintenanceMarginRate float64 `json:"maintenance_margin_rate"` // Used to estimate the liquidation price, defaults to 0.005
+}
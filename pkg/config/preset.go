@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strategy presets selectable with the preset key
+const (
+	PresetConservativeSwing = "conservative_swing"
+	PresetAggressiveScalp   = "aggressive_scalp"
+	PresetNewsAvoidant      = "news_avoidant"
+)
+
+// presets bundle the leverage, risk limits, triggers, prompt persona and indicators of a trading style as
+// strategy config JSON. The strategy config is applied on top, so every field it sets overrides the preset.
+var presets = map[string]string{
+	PresetConservativeSwing: `{
+		"interval": "4h",
+		"leverage": 1,
+		"agent": {"trading": {
+			"temperature": 0.1,
+			"backgroup": "You are a patient swing trader. Trade only with the higher timeframe trend, enter on pullbacks to support or resistance, always place a stop loss and prefer no_action when the setup is unclear. Capital preservation comes before profit."
+		}},
+		"env": {"exchange": {
+			"leverage_scaling": {"enabled": true, "min_leverage": 1, "max_leverage": 2, "atr_period": 14},
+			"risk_sizing": {"max_risk_percent": 1},
+			"profit_ratchet": {"enabled": true, "steps": [{"trigger_r": 1.5, "lock_r": 0}, {"trigger_r": 3, "lock_r": 1.5}]},
+			"trigger_price": {"min_distance_percent": 0.5},
+			"indicators": {
+				"EMA50": {"type": "ewma", "params": {"interval": "4h", "window_size": "50"}, "kline_num": 50},
+				"RSI14": {"type": "rsi", "params": {"interval": "4h", "window_size": "14"},
+					"alerts": [{"condition": "cross_above", "value": 70}, {"condition": "cross_below", "value": 30}]},
+				"BOLL20": {"type": "boll", "params": {"interval": "4h", "window_size": "20"}}
+			}
+		}},
+		"pre_trade": {"enabled": true, "require_stop_loss": true, "max_loss_percent": 1, "max_margin_usage": 30},
+		"flip_guard": {"enabled": true, "window": 6, "confirmations": 2, "min_confidence": 0.85},
+		"calibration": {"enabled": true, "min_confidence": 0.6}
+	}`,
+	PresetAggressiveScalp: `{
+		"interval": "5m",
+		"leverage": 5,
+		"agent": {"trading": {
+			"temperature": 0.3,
+			"backgroup": "You are an active scalper. Take short-lived momentum trades on breakouts and fast EMA crossovers, use tight stops and take profits quickly, and reverse when momentum flips."
+		}},
+		"env": {"exchange": {
+			"leverage_scaling": {"enabled": true, "min_leverage": 2, "max_leverage": 5, "atr_period": 14},
+			"risk_sizing": {"max_risk_percent": 2},
+			"profit_ratchet": {"enabled": true, "steps": [{"trigger_r": 0.5, "lock_r": 0}, {"trigger_r": 1, "lock_r": 0.5}, {"trigger_r": 2, "lock_r": 1.5}]},
+			"trigger_price": {"min_distance_ticks": 2},
+			"indicators": {
+				"EMA9": {"type": "ewma", "params": {"interval": "5m", "window_size": "9"}},
+				"EMA21": {"type": "ewma", "params": {"interval": "5m", "window_size": "21"},
+					"alerts": [{"condition": "cross_above_indicator", "indicator": "EMA9"}, {"condition": "cross_below_indicator", "indicator": "EMA9"}]},
+				"RSI7": {"type": "rsi", "params": {"interval": "5m", "window_size": "7"}},
+				"VR3": {"type": "vr", "max_num": 5, "params": {"interval": "5m", "window_size": "3"}}
+			}
+		}},
+		"pre_trade": {"enabled": true, "require_stop_loss": true, "max_loss_percent": 2, "max_margin_usage": 80},
+		"flip_guard": {"enabled": false}
+	}`,
+	PresetNewsAvoidant: `{
+		"interval": "1h",
+		"leverage": 2,
+		"agent": {"trading": {
+			"temperature": 0.1,
+			"backgroup": "You are a cautious trader who avoids event risk. Do not open positions ahead of scheduled high-impact news (FOMC, CPI, NFP) or during sudden volatility spikes, keep positions small and prefer no_action when the market is driven by headlines."
+		}},
+		"env": {"exchange": {
+			"leverage_scaling": {"enabled": true, "min_leverage": 1, "max_leverage": 2, "atr_period": 14},
+			"risk_sizing": {"max_risk_percent": 1},
+			"flat_by": {"enabled": true, "time": "20:00", "weekday": "friday", "timezone": "UTC", "warn_before": "2h"},
+			"indicators": {
+				"ATRP14": {"type": "atrp", "params": {"interval": "1h", "window_size": "14"},
+					"alerts": [{"condition": "cross_above", "value": 0.03, "message": "Volatility spike, likely news driven"}]},
+				"BOLL20": {"type": "boll", "params": {"interval": "1h", "window_size": "20"}}
+			}
+		}},
+		"pre_trade": {"enabled": true, "require_stop_loss": true, "max_loss_percent": 1, "max_margin_usage": 40},
+		"blackout": {"enabled": true, "max_failures": 3, "window": 10}
+	}`,
+}
+
+// PresetNames returns the names of the strategy presets
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Preset returns the strategy config JSON of the named preset
+func Preset(name string) (json.RawMessage, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q, valid presets: %s", name, strings.Join(PresetNames(), ", "))
+	}
+
+	return json.RawMessage(preset), nil
+}
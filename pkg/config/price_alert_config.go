@@ -0,0 +1,10 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// PriceAlertConfig lets the agent set price levels that fire a decision cycle mid-candle when reached
+type PriceAlertConfig struct {
+	Enabled     bool           `json:"enabled"`
+	MaxAlerts   int            `json:"max_alerts"`   // Largest number of active alerts, default 5
+	MinInterval types.Duration `json:"min_interval"` // Shortest time between the decision cycles fired by alerts, default 1m
+}
@@ -0,0 +1,13 @@
+package config
+
+// ProfitRatchetStep locks a profit once the position has gained enough, both in multiples of the initial risk R
+type ProfitRatchetStep struct {
+	TriggerR float64 `json:"trigger_r"` // Unrealized profit that advances the ratchet to this step, e.g. 3 for +3R
+	LockR    float64 `json:"lock_r"`    // Profit the stop is tightened to, 0 is breakeven
+}
+
+// ProfitRatchetConfig defines how the stop-loss is tightened in steps as unrealized profit grows
+type ProfitRatchetConfig struct {
+	Enabled bool                `json:"enabled"`
+	Steps   []ProfitRatchetStep `json:"steps"` // Defaults to breakeven at +1R, +1R at +2R and +2R at +3R
+}
@@ -0,0 +1,9 @@
+package config
+
+// PromptDedupConfig defines how prompt sections unchanged since the last cycle are compressed
+type PromptDedupConfig struct {
+	Enabled          bool    `json:"enabled"`
+	Threshold        float64 `json:"threshold"`          // Similarity (0-1) from which a section counts as unchanged, default 0.98
+	MaxNumericChange float64 `json:"max_numeric_change"` // Relative change of any number that still counts as unchanged, default 0.001
+	MaxSkips         int     `json:"max_skips"`          // Cycles in a row a section may be compressed before it is sent in full again, default 3
+}
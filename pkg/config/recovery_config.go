@@ -0,0 +1,7 @@
+package config
+
+// RecoveryConfig defines the recovery file dumped on critical failures, so an operator can take over the open risk
+type RecoveryConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"` // Directory of the recovery files, default "memory-bank/recovery"
+}
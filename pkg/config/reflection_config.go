@@ -0,0 +1,11 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// ReflectionTriggerConfig filters which closed trades trigger a reflection
+type ReflectionTriggerConfig struct {
+	OnlyLosses bool           `json:"only_losses"` // Only reflect on losing trades
+	MinAbsPnL  float64        `json:"min_abs_pnl"` // Skip trades whose absolute PnL (quote currency) is below this threshold
+	MaxPerDay  int            `json:"max_per_day"` // Maximum number of reflections per rolling day, 0 means unlimited
+	Cooldown   types.Interval `json:"cooldown"`    // Minimum time between two reflections
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// RetryConfig defines how failed exchange calls are retried. Queries retry rate limits, timeouts and network
+// errors, order submissions only rate limits, since a failed submit may still have reached the exchange.
+type RetryConfig struct {
+	MaxAttempts     int            `json:"max_attempts"`      // Attempts per call including the first, default 3
+	BaseDelay       types.Duration `json:"base_delay"`        // Delay before the first retry, doubled on every retry, default 500ms
+	MaxDelay        types.Duration `json:"max_delay"`         // Longest delay between retries, default 10s
+	Jitter          float64        `json:"jitter"`            // Share of the delay randomized, default 0.5
+	AttemptTimeout  types.Duration `json:"attempt_timeout"`   // Deadline of each attempt, default 20s
+	BudgetPerMinute int            `json:"budget_per_minute"` // Retries allowed per minute across all calls, default 20
+	BreakerFailures int            `json:"breaker_failures"`  // Consecutive transient failures that pause exchange calls, default 5
+	BreakerCooldown types.Duration `json:"breaker_cooldown"`  // How long exchange calls are paused, default 1m
+}
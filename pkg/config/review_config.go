@@ -0,0 +1,9 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// ReviewConfig defines configuration for the scheduled strategy review memo
+type ReviewConfig struct {
+	Enabled  bool           `json:"enabled"`  // Whether to generate strategy review memos, requires the journal
+	Interval types.Interval `json:"interval"` // Review period, defaults to "1w"
+}
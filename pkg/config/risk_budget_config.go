@@ -0,0 +1,13 @@
+package config
+
+// RiskBudgetStepConfig scales the position size once the drawdown reaches drawdown_percent
+type RiskBudgetStepConfig struct {
+	DrawdownPercent float64 `json:"drawdown_percent"` // Drawdown from the equity peak, in percent
+	Scale           float64 `json:"scale"`            // Share of the normal risk per trade allowed from this drawdown
+}
+
+// RiskBudgetConfig shrinks the risk per trade as the account draws down and restores it as the equity recovers
+type RiskBudgetConfig struct {
+	Enabled bool                   `json:"enabled"`
+	Steps   []RiskBudgetStepConfig `json:"steps"` // Defaults to half the risk from a 5% drawdown and a quarter from 10%
+}
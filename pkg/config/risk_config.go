@@ -0,0 +1,8 @@
+package config
+
+// RiskConfig halts new entries when the account loses too much in a day or draws down too far from its peak
+type RiskConfig struct {
+	Enabled            bool    `json:"enabled"`
+	DailyLossPercent   float64 `json:"daily_loss_percent"`   // Loss of equity since the start of the UTC day, default 5
+	MaxDrawdownPercent float64 `json:"max_drawdown_percent"` // Fall of equity from its peak, default 15
+}
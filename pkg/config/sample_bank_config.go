@@ -0,0 +1,24 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// SampleBankConfig adds the few-shot samples most relevant to the current situation to the prompt,
+// one set per available command, rotating among equally relevant samples
+type SampleBankConfig struct {
+	Enabled     bool               `json:"enabled"`
+	Path        string             `json:"path"`         // JSON array of {action, situation, output} samples, empty uses the builtin samples only
+	FromJournal bool               `json:"from_journal"` // Also use the decisions of winning trades in the journal as samples
+	MaxJournal  int                `json:"max_journal"`  // Most recent winning decisions to load from the journal, default: 20
+	PerAction   int                `json:"per_action"`   // Samples shown per command, default: 1
+	Mining      SampleMiningConfig `json:"mining"`
+}
+
+// SampleMiningConfig periodically mines the journal for high-quality decisions and proposes them to the operator,
+// approved ones are added to the sample file
+type SampleMiningConfig struct {
+	Enabled           bool           `json:"enabled"`
+	Interval          types.Interval `json:"interval"`            // How often the journal is mined, default: 1d
+	MinPnLPercent     float64        `json:"min_pnl_percent"`     // Minimum profit percent of the trade, default: 0.5
+	MinRMultiple      float64        `json:"min_r_multiple"`      // Minimum R multiple of the trade, 0 doesn't check it
+	MinReasoningWords int            `json:"min_reasoning_words"` // Minimum words of the reasoning, so the setup is explained, default: 30
+}
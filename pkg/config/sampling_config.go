@@ -0,0 +1,19 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// SamplingParams are the sampling parameters of decisions in a market state
+type SamplingParams struct {
+	Temperature float64 `json:"temperature"`
+	TopP        float64 `json:"top_p"` // 0 keeps the provider default
+}
+
+// SamplingScheduleConfig ties the sampling parameters of decisions to the market state
+type SamplingScheduleConfig struct {
+	Enabled    bool           `json:"enabled"`
+	RiskEvents []string       `json:"risk_events"` // Event types that put the market in the risk state, default indicator_alert, flat_by_warning and price_divergence
+	RiskHold   types.Duration `json:"risk_hold"`   // How long the risk state lasts after a risk event, default 30m
+	Risk       SamplingParams `json:"risk"`        // After risk events or with the dead-man's switch tripped, default temperature 0.1
+	Position   SamplingParams `json:"position"`    // With an open position, default temperature 0.2
+	Flat       SamplingParams `json:"flat"`        // Flat and exploring, default temperature 0.5
+}
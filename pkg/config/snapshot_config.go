@@ -0,0 +1,11 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// SnapshotConfig defines how the full strategy state is saved to disk and restored at startup
+type SnapshotConfig struct {
+	Enabled  bool           `json:"enabled"`  // Whether to save snapshots and restore the latest one at startup
+	Path     string         `json:"path"`     // Snapshot file, defaults to "memory-bank/snapshot-<symbol>.json"
+	Interval types.Duration `json:"interval"` // Time between two periodic snapshots, defaults to 1m, a snapshot is also saved on shutdown
+	MaxAge   types.Duration `json:"max_age"`  // Snapshots older than this are not restored, 0 restores any age
+}
@@ -0,0 +1,11 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// StaleGuardConfig defines how entries decided on prompt data that went stale during a slow decision are re-validated
+type StaleGuardConfig struct {
+	Enabled         bool           `json:"enabled"`
+	MaxAge          types.Duration `json:"max_age"`           // Age of the prompt data from which the price drift is re-validated, default 2m
+	MaxDriftPercent float64        `json:"max_drift_percent"` // Price move since the prompt data tolerated for stale entries, default 0.3
+	OnDrift         string         `json:"on_drift"`          // abort, or confirm to ask the agent once to re-confirm against the current price (default)
+}
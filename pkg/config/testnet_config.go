@@ -0,0 +1,7 @@
+package config
+
+// TestnetConfig runs the live pipeline against sandbox accounts, labeled in notifications and the journal
+type TestnetConfig struct {
+	Enabled bool   `json:"enabled"`
+	Label   string `json:"label"` // Prefix of the notifications, default [TESTNET]
+}
@@ -0,0 +1,19 @@
+package config
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// Execution venue types
+const (
+	VenueTypeBbgo = "bbgo"
+	VenueTypeRest = "rest"
+)
+
+// VenueConfig selects where the orders of the exchange entity are executed, the market data still comes from the bbgo session
+type VenueConfig struct {
+	Type           string         `json:"type"`             // bbgo (default), or rest for a ccxt-style REST service
+	BaseURL        string         `json:"base_url"`         // Address of the REST service, e.g. http://localhost:3000
+	TestnetBaseURL string         `json:"testnet_base_url"` // Address of the REST service trading the sandbox accounts, used when testnet is enabled
+	Symbol         string         `json:"symbol"`           // Symbol on the REST service, e.g. BTC/USDT:USDT, defaults to the strategy symbol
+	APIKey         string         `json:"api_key"`          // Sent as a bearer token, defaults to VENUE_API_KEY
+	Timeout        types.Duration `json:"timeout"`          // Timeout of a REST request, default 10s
+}
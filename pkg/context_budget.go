@@ -0,0 +1,101 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// Prompt sections of the context budget
+const (
+	sectionSystem       = "system"
+	sectionKlines       = "klines"
+	sectionIndicators   = "indicators"
+	sectionPosition     = "position"
+	sectionRisk         = "risk"
+	sectionEvents       = "events"
+	sectionHistory      = "history"
+	sectionMemories     = "memories"
+	sectionExamples     = "examples"
+	sectionInstructions = "instructions"
+)
+
+// promptSectionKey carries the prompt section of the messages stashed by an event handler
+type promptSectionKey struct{}
+
+func withPromptSection(ctx context.Context, section string) context.Context {
+	return context.WithValue(ctx, promptSectionKey{}, section)
+}
+
+// promptSection returns the prompt section of the messages stashed with the context, events by default
+func promptSection(ctx context.Context) string {
+	if section, ok := ctx.Value(promptSectionKey{}).(string); ok {
+		return section
+	}
+
+	return sectionEvents
+}
+
+// eventSection returns the prompt section of the messages of an env event
+func eventSection(eventType string) string {
+	switch eventType {
+	case "kline_changed":
+		return sectionKlines
+	case "indicator_changed", "indicator_alert":
+		return sectionIndicators
+	case "position_changed":
+		return sectionPosition
+	default:
+		return sectionEvents
+	}
+}
+
+// logContextBudget logs the estimated tokens of each prompt section of the decision cycle. The rendered
+// instructions carry the memories and examples of the template data, they are counted apart.
+func (s *Strategy) logContextBudget(ctx context.Context, msgs []*ttypes.Message, templateData map[string]interface{}) {
+	if !s.Debug.ContextBudget {
+		return
+	}
+
+	budget := utils.NewContextBudget()
+	budget.Add(sectionSystem, s.Agent.Trading.Backgroup)
+
+	for _, msg := range msgs {
+		if msg.Section != sectionInstructions {
+			budget.Add(msg.Section, msg.Text)
+			continue
+		}
+
+		memories := templateTokens(templateData, "Memory", "RelevantRules", "RelevantPlaybooks", "RelevantMemories")
+		examples := templateTokens(templateData, "Examples")
+		budget.AddTokens(sectionMemories, memories)
+		budget.AddTokens(sectionExamples, examples)
+		budget.AddTokens(sectionInstructions, utils.EstimateTokens(msg.Text)-memories-examples)
+	}
+
+	log.
+		WithField("cycle_id", ttypes.CycleIDFromContext(ctx)).
+		Infof("context budget: %s", budget.String())
+}
+
+// templateTokens estimates the tokens of the template data rendered into the prompt
+func templateTokens(templateData map[string]interface{}, keys ...string) int {
+	tokens := 0
+	for _, key := range keys {
+		switch value := templateData[key].(type) {
+		case nil:
+		case string:
+			tokens += utils.EstimateTokens(value)
+		case []string:
+			for _, item := range value {
+				tokens += utils.EstimateTokens(item)
+			}
+		default:
+			tokens += utils.EstimateTokens(fmt.Sprint(value))
+		}
+	}
+
+	return tokens
+}
@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+func (s *Strategy) setupCycleDeadline(ctx context.Context) error {
+	cfg := &s.CycleDeadline
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.Ratio <= 0 || cfg.Ratio > 1 {
+		cfg.Ratio = 0.8
+	}
+	if cfg.Min == 0 {
+		cfg.Min = types.Duration(30 * time.Second)
+	}
+
+	log.WithField("config", cfg).WithField("timeout", s.cycleTimeout()).Info("Cycle deadline enabled")
+	return nil
+}
+
+// cycleTimeout returns how long a decision cycle may take, 0 when unbounded
+func (s *Strategy) cycleTimeout() time.Duration {
+	if !s.CycleDeadline.Enabled {
+		return 0
+	}
+
+	return utils.CycleTimeout(s.Interval.Duration(), s.CycleDeadline.Ratio, s.CycleDeadline.Min.Duration())
+}
+
+// withCycleDeadline bounds the decision cycle started by the kline by the cycle timeout
+func (s *Strategy) withCycleDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := s.cycleTimeout()
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// checkCycleDeadline reports a decision cycle whose remaining calls were cancelled by the deadline
+func (s *Strategy) checkCycleDeadline(ctx context.Context, chatSession ttypes.ISession) {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+
+	msg := fmt.Sprintf("⏱️ The decision cycle exceeded its deadline of %s, its remaining calls were cancelled.", s.cycleTimeout())
+	log.Warn(msg)
+
+	// The cycle context is done, so notify outside of it
+	s.notifyMsg(context.WithoutCancel(ctx), chatSession, ttypes.SeverityWarning, msg)
+}
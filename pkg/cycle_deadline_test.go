@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmergencyCloseAfterCycleDeadline(t *testing.T) {
+	p := newPipeline(t, scenarioConfig(), map[int]string{
+		0: `{"thoughts": {"plan": "buy"}, "action": {"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "97", "risk_percent": "1"}}}`,
+	})
+	p.llm.stalls = map[int]bool{1: true}
+
+	bars := trend(100, 1, 2)
+	p.run(bars[:1])
+	assert.False(t, p.strategy.Position.GetBase().IsZero())
+
+	// The LLM call of the next cycle outlives its deadline
+	p.close(bars[1])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	p.decide(ctx)
+	assert.True(t, errors.Is(ctx.Err(), context.DeadlineExceeded))
+
+	// The protective close still reaches the venue and the admins
+	assert.True(t, p.strategy.Position.GetBase().IsZero())
+	if assert.Len(t, p.venue.closes, 1) {
+		assert.Equal(t, 102.0, p.venue.closes[0].ExitPrice)
+	}
+
+	notified := false
+	for _, reply := range p.session.replies {
+		notified = notified || strings.Contains(reply, "emergency close position, for agent error")
+	}
+	assert.True(t, notified)
+}
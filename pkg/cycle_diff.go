@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// cycleEvents are the events every decision cycle has, they are not reported as new
+var cycleEvents = map[string]bool{
+	"kline_changed":     true,
+	"indicator_changed": true,
+	"position_changed":  true,
+	"update_finish":     true,
+}
+
+// observeCycle records what the event shows the agent into the state of the cycle being collected
+func (s *Strategy) observeCycle(session ttypes.ISession, evt ttypes.IEvent) {
+	if !s.CycleDiff.Enabled || evt.GetType() == "update_finish" {
+		return
+	}
+
+	ref, ok := session.GetAttribute("cycle_state_next")
+	state, _ := ref.(*utils.CycleState)
+	if !ok || state == nil {
+		state = utils.NewCycleState()
+		session.SetAttribute("cycle_state_next", state)
+	}
+
+	switch data := evt.GetData().(type) {
+	case *types.KLineWindow:
+		if data.Len() > 0 {
+			state.Close = data.GetClose().Float64()
+		}
+	case *exchange.ExchangeIndicator:
+		if value, ok := data.LastValue(); ok {
+			state.Indicators[data.Name] = value
+		}
+	case *exchange.PositionX:
+		kline, ok := s.getKline(session)
+		if ok && data.IsOpened(kline.GetClose()) {
+			state.Side = "short"
+			if data.IsLong() {
+				state.Side = "long"
+			}
+			state.ProfitPercent = data.AccumulatedProfit.Float64()
+		}
+	}
+
+	if !cycleEvents[evt.GetType()] {
+		state.AddEvent(evt.GetType())
+	}
+}
+
+// cycleDiffMsg describes the changes since the last decision cycle, and starts collecting the next one
+func (s *Strategy) cycleDiffMsg(session ttypes.ISession) string {
+	if !s.CycleDiff.Enabled {
+		return ""
+	}
+
+	ref, _ := session.GetAttribute("cycle_state_next")
+	curr, _ := ref.(*utils.CycleState)
+	if curr == nil {
+		return ""
+	}
+
+	ref, _ = session.GetAttribute("cycle_state")
+	prev, _ := ref.(*utils.CycleState)
+
+	session.SetAttribute("cycle_state", curr)
+	session.RemoveAttribute("cycle_state_next")
+
+	return utils.DiffCycles(prev, curr, s.Precision)
+}
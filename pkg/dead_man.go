@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/env/bridge"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// deadManCheckInterval is how often the dead-man's switch is checked for due pings and missed heartbeats
+const deadManCheckInterval = time.Minute
+
+func (s *Strategy) setupDeadMan(ctx context.Context) error {
+	cfg := &s.DeadMan
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.PingInterval == 0 {
+		cfg.PingInterval = types.Duration(time.Hour * 4)
+	}
+	if cfg.Window == 0 {
+		cfg.Window = types.Duration(time.Minute * 30)
+	}
+	if cfg.TightenRatio <= 0 || cfg.TightenRatio >= 1 {
+		cfg.TightenRatio = 0.5
+	}
+	if cfg.FallbackStopPercent <= 0 {
+		cfg.FallbackStopPercent = 2
+	}
+
+	s.deadMan = utils.NewDeadManSwitch(cfg.PingInterval.Duration(), cfg.Window.Duration(), time.Now())
+
+	go func() {
+		ticker := time.NewTicker(deadManCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.checkDeadMan(ctx)
+			}
+		}
+	}()
+
+	log.WithField("config", cfg).Info("Dead-man's switch enabled")
+	return nil
+}
+
+// checkDeadMan pings the operator when a heartbeat is due, and trips the switch when a ping went unanswered
+func (s *Strategy) checkDeadMan(ctx context.Context) {
+	ping, tripped := s.deadMan.Tick(time.Now())
+
+	if ping {
+		s.notifyAdmins(ctx, ttypes.SeverityWarning, fmt.Sprintf("💓 Heartbeat check for %s: reply \"/ack\" (or send the \"ack\" command via gRPC or the bridge) within %s to keep trading.",
+			s.Symbol, s.DeadMan.Window.Duration()))
+		s.publishBridge(ctx, &bridge.OutboundMessage{Type: "heartbeat", Text: "ack required"})
+	}
+
+	if !tripped {
+		return
+	}
+
+	msg := fmt.Sprintf("☠️ Dead-man's switch tripped for %s: no heartbeat acknowledged within %s. New positions are blocked until an operator sends \"/ack\".",
+		s.Symbol, s.DeadMan.Window.Duration())
+
+	// Sent as a command, so the tightening is serialized with the commands of the agent
+	err := s.world.SendCommand(ctx, "exchange.tighten_stop", map[string]string{
+		"ratio":            strconv.FormatFloat(s.DeadMan.TightenRatio, 'f', -1, 64),
+		"fallback_percent": strconv.FormatFloat(s.DeadMan.FallbackStopPercent, 'f', -1, 64),
+	})
+	if err != nil {
+		log.WithError(err).Error("dead-man's switch tighten stop failed")
+		msg = fmt.Sprintf("%s Tightening the stop failed: %s", msg, err.Error())
+	} else if s.Position != nil && !s.Position.GetBase().IsZero() {
+		msg = fmt.Sprintf("%s The stop of the open position was tightened.", msg)
+	}
+
+	log.Warn(msg)
+	s.notifyAdmins(ctx, ttypes.SeverityCritical, msg)
+	s.publishBridge(ctx, &bridge.OutboundMessage{Type: "dead_man", Text: msg})
+}
+
+// ackHeartbeat records an operator heartbeat, resuming entries if the switch had tripped
+func (s *Strategy) ackHeartbeat(ctx context.Context, operator string) string {
+	if s.deadMan == nil {
+		return "The dead-man's switch is not enabled."
+	}
+
+	if !s.deadMan.Ack(time.Now()) {
+		return fmt.Sprintf("💓 Heartbeat acknowledged by %s.", operator)
+	}
+
+	msg := fmt.Sprintf("▶️ Heartbeat acknowledged by %s, new positions are allowed again.", operator)
+	log.Info(msg)
+	s.publishBridge(ctx, &bridge.OutboundMessage{Type: "dead_man", Text: msg})
+
+	return msg
+}
+
+// deadManReason returns why an entry is blocked by the tripped switch, empty when it may execute
+func (s *Strategy) deadManReason(actionName string) string {
+	if s.deadMan == nil || !isEntryAction(actionName) || !s.deadMan.Tripped() {
+		return ""
+	}
+
+	return "new positions are blocked by the dead-man's switch until an operator acknowledges a heartbeat"
+}
+
+// isAckCommand returns whether an operator command acknowledges the heartbeat
+func isAckCommand(command string) bool {
+	return command == "ack" || command == "jarvis.ack"
+}
+
+// notifyAdmins sends the message to every admin session
+func (s *Strategy) notifyAdmins(ctx context.Context, severity ttypes.Severity, msg string) {
+	s.adminMu.Lock()
+	sessions := append([]ttypes.ISession{}, s.adminSessions...)
+	s.adminMu.Unlock()
+
+	for _, session := range sessions {
+		s.notifyMsg(ctx, session, severity, msg)
+	}
+}
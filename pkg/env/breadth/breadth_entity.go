@@ -0,0 +1,166 @@
+package breadth
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yubing744/trading-gpt/pkg/apis/coingecko"
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+var log = logrus.WithField("entity", "breadth")
+
+// MarketBreadthEntity periodically emits BTC dominance, total market cap trend and market breadth
+type MarketBreadthEntity struct {
+	config *config.MarketBreadthConfig
+	client *coingecko.CoinGeckoClient
+	delay  time.Duration
+
+	lastMarketCap float64
+}
+
+func NewMarketBreadthEntity(cfg *config.MarketBreadthConfig) *MarketBreadthEntity {
+	opts := []coingecko.Option{
+		coingecko.WithAPIKey(cfg.APIKey),
+		coingecko.WithTimeout(time.Second * 20),
+	}
+	if cfg.BaseURL != "" {
+		opts = append(opts, coingecko.WithBaseURL(cfg.BaseURL))
+	}
+
+	return &MarketBreadthEntity{
+		config: cfg,
+		client: coingecko.NewCoinGeckoClient(opts...),
+		delay:  time.Minute,
+	}
+}
+
+func (entity *MarketBreadthEntity) GetID() string {
+	return "breadth"
+}
+
+func (entity *MarketBreadthEntity) Actions() []*types.ActionDesc {
+	return nil
+}
+
+func (entity *MarketBreadthEntity) HandleCommand(ctx context.Context, cmd string, args map[string]string) error {
+	return nil
+}
+
+// Restartable reports that Run is a self-contained polling loop, restarted after a panic
+func (entity *MarketBreadthEntity) Restartable() bool {
+	return true
+}
+
+func (entity *MarketBreadthEntity) Run(ctx context.Context, ch chan types.IEvent) {
+	timer := time.NewTimer(entity.delay)
+	ticker := time.NewTicker(entity.config.Interval.Duration())
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("breadth entity done")
+			return
+		case <-timer.C:
+			entity.update(ctx, ch)
+		case <-ticker.C:
+			entity.update(ctx, ch)
+		}
+	}
+}
+
+func (entity *MarketBreadthEntity) update(ctx context.Context, ch chan types.IEvent) {
+	breadth, err := entity.fetchBreadth(ctx)
+	if err != nil {
+		log.WithError(err).Error("update market breadth error")
+		return
+	}
+
+	log.WithField("breadth", breadth).Debug("update market breadth")
+
+	ch <- NewMarketBreadthEvent(breadth)
+}
+
+func (entity *MarketBreadthEntity) fetchBreadth(ctx context.Context) (*MarketBreadth, error) {
+	global, err := entity.client.GetGlobal(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get global data error")
+	}
+
+	breadth := &MarketBreadth{
+		BTCDominance:       global.MarketCapPercentage["btc"],
+		TotalMarketCap:     global.TotalMarketCap["usd"],
+		MarketCapChange24h: global.MarketCapChangePercentage24hUSD,
+		MADays:             entity.config.MADays,
+	}
+
+	if entity.lastMarketCap > 0 {
+		change := (breadth.TotalMarketCap - entity.lastMarketCap) / entity.lastMarketCap * 100
+		breadth.MarketCapChangeSince = &change
+	}
+	entity.lastMarketCap = breadth.TotalMarketCap
+
+	// Breadth is best effort, dominance and market cap are still useful without it
+	markets, err := entity.client.GetCoinMarkets(ctx, "usd", entity.config.TopN)
+	if err != nil {
+		log.WithError(err).Warn("get coin markets error")
+		return breadth, nil
+	}
+
+	above := 0
+	for i, market := range markets {
+		if i > 0 && entity.config.RequestDelay != "" {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(entity.config.RequestDelay.Duration()):
+			}
+		}
+
+		chart, err := entity.client.GetMarketChart(ctx, market.ID, "usd", entity.config.MADays)
+		if err != nil {
+			log.WithError(err).WithField("coin", market.ID).Warn("get market chart error")
+			continue
+		}
+
+		isAbove, ok := AboveMovingAverage(chart.Prices, market.CurrentPrice)
+		if !ok {
+			continue
+		}
+
+		breadth.CoinsCounted++
+		if isAbove {
+			above++
+		}
+	}
+
+	if breadth.CoinsCounted > 0 {
+		breadth.PercentAboveMA = float64(above) / float64(breadth.CoinsCounted) * 100
+	}
+
+	return breadth, nil
+}
+
+// AboveMovingAverage reports whether the price is above the mean of the [timestamp, price] history
+func AboveMovingAverage(prices [][]float64, price float64) (bool, bool) {
+	sum := 0.0
+	count := 0
+	for _, p := range prices {
+		if len(p) < 2 {
+			continue
+		}
+
+		sum += p[1]
+		count++
+	}
+
+	if count == 0 || price <= 0 {
+		return false, false
+	}
+
+	return price > sum/float64(count), true
+}
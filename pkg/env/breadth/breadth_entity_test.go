@@ -0,0 +1,52 @@
+package breadth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestMarketBreadthEntity_FetchBreadth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/global":
+			w.Write([]byte(`{"data":{"total_market_cap":{"usd":2000000000000},"market_cap_percentage":{"btc":55},"market_cap_change_percentage_24h_usd":1.2}}`))
+		case "/coins/markets":
+			w.Write([]byte(`[{"id":"bitcoin","current_price":110},{"id":"ethereum","current_price":90}]`))
+		default:
+			w.Write([]byte(`{"prices":[[1,100],[2,100]]}`))
+		}
+	}))
+	defer server.Close()
+
+	entity := NewMarketBreadthEntity(&config.MarketBreadthConfig{BaseURL: server.URL, TopN: 2, MADays: 20})
+	breadth, err := entity.fetchBreadth(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 55.0, breadth.BTCDominance)
+	assert.Equal(t, 2, breadth.CoinsCounted)
+	assert.Equal(t, 50.0, breadth.PercentAboveMA)
+	assert.Nil(t, breadth.MarketCapChangeSince)
+
+	prompts := NewMarketBreadthEvent(breadth).ToPrompts()
+	assert.Contains(t, prompts[0], "BTC dominance: 55.00%")
+	assert.Contains(t, prompts[0], "50% of the top 2 coins are above their 20-day moving average")
+
+	breadth, err = entity.fetchBreadth(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, breadth.MarketCapChangeSince)
+}
+
+func TestAboveMovingAverage(t *testing.T) {
+	above, ok := AboveMovingAverage([][]float64{{1, 10}, {2, 20}}, 16)
+	assert.True(t, ok)
+	assert.True(t, above)
+
+	_, ok = AboveMovingAverage(nil, 16)
+	assert.False(t, ok)
+}
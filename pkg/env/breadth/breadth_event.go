@@ -0,0 +1,55 @@
+package breadth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+const EventMarketBreadthChanged = "market_breadth_changed"
+
+// MarketBreadth is a snapshot of the overall crypto market
+type MarketBreadth struct {
+	BTCDominance         float64
+	TotalMarketCap       float64
+	MarketCapChange24h   float64
+	MarketCapChangeSince *float64 // Change since the previous snapshot, nil for the first one
+	PercentAboveMA       float64
+	CoinsCounted         int
+	MADays               int
+}
+
+// MarketBreadthEvent carries the market breadth snapshot to the agent
+type MarketBreadthEvent struct {
+	types.Event
+	breadth *MarketBreadth
+}
+
+func NewMarketBreadthEvent(breadth *MarketBreadth) *MarketBreadthEvent {
+	return &MarketBreadthEvent{
+		Event:   *types.NewEvent(EventMarketBreadthChanged, breadth),
+		breadth: breadth,
+	}
+}
+
+func (e *MarketBreadthEvent) ToPrompts() []string {
+	b := e.breadth
+	sb := strings.Builder{}
+
+	sb.WriteString("Crypto market breadth (global, not asset-specific):\n")
+	sb.WriteString(fmt.Sprintf("- BTC dominance: %.2f%%\n", b.BTCDominance))
+	sb.WriteString(fmt.Sprintf("- Total market cap: $%.2fB, 24h change: %+.2f%%", b.TotalMarketCap/1e9, b.MarketCapChange24h))
+	if b.MarketCapChangeSince != nil {
+		sb.WriteString(fmt.Sprintf(", change since last update: %+.2f%%", *b.MarketCapChangeSince))
+	}
+	sb.WriteString("\n")
+
+	if b.CoinsCounted > 0 {
+		sb.WriteString(fmt.Sprintf("- %.0f%% of the top %d coins are above their %d-day moving average\n", b.PercentAboveMA, b.CoinsCounted, b.MADays))
+	}
+
+	sb.WriteString("Rising BTC dominance with weak breadth is a headwind for altcoins; broad strength above the moving average supports risk-on trades.")
+
+	return []string{sb.String()}
+}
@@ -0,0 +1,114 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+var log = logrus.WithField("entity", "bridge")
+
+// BridgeEntity turns messages on the input channel into signal and command events
+type BridgeEntity struct {
+	config *config.BridgeConfig
+	pubsub PubSub
+}
+
+func NewBridgeEntity(cfg *config.BridgeConfig, pubsub PubSub) *BridgeEntity {
+	return &BridgeEntity{
+		config: cfg,
+		pubsub: pubsub,
+	}
+}
+
+func (e *BridgeEntity) GetID() string {
+	return "bridge"
+}
+
+func (e *BridgeEntity) Actions() []*types.ActionDesc {
+	return []*types.ActionDesc{}
+}
+
+func (e *BridgeEntity) HandleCommand(ctx context.Context, cmd string, args map[string]string) error {
+	return nil
+}
+
+// Run subscribes to the input channel, resubscribing after connection errors
+func (e *BridgeEntity) Run(ctx context.Context, ch chan types.IEvent) {
+	if e.config.InputChannel == "" {
+		return
+	}
+
+	for {
+		msgs, err := e.pubsub.Subscribe(ctx, e.config.InputChannel)
+		if err != nil {
+			log.WithError(err).Error("bridge subscribe error")
+		} else {
+			log.WithField("channel", e.config.InputChannel).Info("bridge subscribed")
+
+			for payload := range msgs {
+				evt, err := e.toEvent(payload)
+				if err != nil {
+					log.WithError(err).WithField("payload", string(payload)).Warn("bridge invalid message")
+					continue
+				}
+
+				ch <- evt
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("bridge entity done")
+			return
+		case <-time.After(time.Second * 5):
+		}
+	}
+}
+
+// Publish sends a message to the output channel
+func (e *BridgeEntity) Publish(ctx context.Context, msg *OutboundMessage) error {
+	if e.config.OutputChannel == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return e.pubsub.Publish(ctx, e.config.OutputChannel, payload)
+}
+
+func (e *BridgeEntity) toEvent(payload []byte) (types.IEvent, error) {
+	msg := &InboundMessage{}
+	if err := json.Unmarshal(payload, msg); err != nil {
+		return nil, errors.Wrap(err, "decode message error")
+	}
+
+	switch msg.Type {
+	case MessageTypeSignal:
+		if msg.Text == "" {
+			return nil, errors.New("signal text is empty")
+		}
+
+		return NewExternalSignalEvent(msg), nil
+	case MessageTypeCommand:
+		if !e.config.AllowCommands {
+			return nil, errors.New("commands are not allowed")
+		}
+		if msg.Command == "" {
+			return nil, errors.New("command is empty")
+		}
+
+		return types.NewEvent(EventExternalCommand, msg), nil
+	default:
+		return nil, errors.Errorf("message type not supported: %s", msg.Type)
+	}
+}
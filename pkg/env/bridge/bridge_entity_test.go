@@ -0,0 +1,62 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+type memoryPubSub struct {
+	published map[string][][]byte
+}
+
+func (m *memoryPubSub) Publish(ctx context.Context, channel string, payload []byte) error {
+	m.published[channel] = append(m.published[channel], payload)
+	return nil
+}
+
+func (m *memoryPubSub) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	return make(chan []byte), nil
+}
+
+func (m *memoryPubSub) Close() error {
+	return nil
+}
+
+func TestBridgeEntity_ToEvent(t *testing.T) {
+	entity := NewBridgeEntity(&config.BridgeConfig{}, &memoryPubSub{})
+
+	evt, err := entity.toEvent([]byte(`{"type":"signal","source":"momentum-model","text":"BTC momentum flipped bullish"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, EventExternalSignal, evt.GetType())
+	assert.Equal(t, []string{"Signal from momentum-model: BTC momentum flipped bullish"}, evt.ToPrompts())
+
+	_, err = entity.toEvent([]byte(`{"type":"command","command":"exchange.close_position"}`))
+	assert.Error(t, err)
+
+	entity.config.AllowCommands = true
+	evt, err = entity.toEvent([]byte(`{"type":"command","command":"exchange.close_position"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, EventExternalCommand, evt.GetType())
+	assert.Equal(t, "exchange.close_position", evt.GetData().(*InboundMessage).Command)
+
+	_, err = entity.toEvent([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestBridgeEntity_Publish(t *testing.T) {
+	pubsub := &memoryPubSub{published: make(map[string][][]byte)}
+	entity := NewBridgeEntity(&config.BridgeConfig{OutputChannel: "bot-events"}, pubsub)
+
+	err := entity.Publish(context.Background(), &OutboundMessage{Type: "kline_changed", Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+	assert.Len(t, pubsub.published["bot-events"], 1)
+
+	msg := &OutboundMessage{}
+	assert.NoError(t, json.Unmarshal(pubsub.published["bot-events"][0], msg))
+	assert.Equal(t, "kline_changed", msg.Type)
+}
@@ -0,0 +1,57 @@
+package bridge
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+const (
+	EventExternalSignal  = "external_signal"
+	EventExternalCommand = "external_command"
+
+	MessageTypeSignal  = "signal"
+	MessageTypeCommand = "command"
+)
+
+// InboundMessage is a JSON message received on the input channel
+type InboundMessage struct {
+	Type    string            `json:"type"`    // "signal" or "command"
+	Source  string            `json:"source"`  // Name of the producing system
+	Text    string            `json:"text"`    // Signal content shown to the agent
+	Command string            `json:"command"` // Command to execute, e.g. exchange.close_position
+	Args    map[string]string `json:"args"`    // Command arguments
+}
+
+// OutboundMessage is a JSON message published on the output channel
+type OutboundMessage struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Symbol  string    `json:"symbol"`
+	Prompts []string  `json:"prompts,omitempty"`
+	Text    string    `json:"text,omitempty"`
+	CycleID string    `json:"cycle_id,omitempty"`
+}
+
+// ExternalSignalEvent carries a signal from external infrastructure to the agent
+type ExternalSignalEvent struct {
+	types.Event
+	msg *InboundMessage
+}
+
+func NewExternalSignalEvent(msg *InboundMessage) *ExternalSignalEvent {
+	return &ExternalSignalEvent{
+		Event: *types.NewEvent(EventExternalSignal, msg),
+		msg:   msg,
+	}
+}
+
+func (e *ExternalSignalEvent) ToPrompts() []string {
+	source := e.msg.Source
+	if source == "" {
+		source = "external system"
+	}
+
+	return []string{fmt.Sprintf("Signal from %s: %s", source, e.msg.Text)}
+}
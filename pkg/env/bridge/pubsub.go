@@ -0,0 +1,87 @@
+package bridge
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// PubSub is the message transport of the bridge
+type PubSub interface {
+	Publish(ctx context.Context, channel string, payload []byte) error
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+	Close() error
+}
+
+// NewPubSub creates the transport for the configured driver
+func NewPubSub(cfg *config.BridgeConfig) (PubSub, error) {
+	switch cfg.Driver {
+	case "", "redis":
+		return NewRedisPubSub(cfg.Addr, cfg.Password, cfg.DB), nil
+	default:
+		return nil, errors.Errorf("bridge driver not supported: %s", cfg.Driver)
+	}
+}
+
+// RedisPubSub is a PubSub backed by Redis channels
+type RedisPubSub struct {
+	client *redis.Client
+}
+
+func NewRedisPubSub(addr string, password string, db int) *RedisPubSub {
+	return &RedisPubSub{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+	}
+}
+
+func (r *RedisPubSub) Publish(ctx context.Context, channel string, payload []byte) error {
+	return r.client.Publish(ctx, channel, payload).Err()
+}
+
+// Subscribe returns the payloads received on the channel until the context is done
+func (r *RedisPubSub) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	sub := r.client.Subscribe(ctx, channel)
+
+	// Wait for the subscription to be confirmed so connection errors surface here
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, errors.Wrap(err, "redis subscribe error")
+	}
+
+	ch := make(chan []byte)
+	go func() {
+		defer close(ch)
+		defer sub.Close()
+
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+
+				select {
+				case ch <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (r *RedisPubSub) Close() error {
+	return r.client.Close()
+}
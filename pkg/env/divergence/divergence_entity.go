@@ -0,0 +1,153 @@
+package divergence
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+var log = logrus.WithField("entity", "divergence")
+
+// TickerQuerier queries the latest ticker of a symbol, implemented by bbgo exchanges
+type TickerQuerier interface {
+	QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error)
+}
+
+// PriceDivergenceEntity compares the traded symbol's price with a reference exchange
+type PriceDivergenceEntity struct {
+	symbol    string
+	config    *config.PriceDivergenceConfig
+	local     TickerQuerier
+	reference TickerQuerier
+
+	mu       sync.Mutex
+	abnormal bool
+	last     PriceDivergenceEventData
+}
+
+func NewPriceDivergenceEntity(symbol string, cfg *config.PriceDivergenceConfig, local TickerQuerier, reference TickerQuerier) *PriceDivergenceEntity {
+	return &PriceDivergenceEntity{
+		symbol:    symbol,
+		config:    cfg,
+		local:     local,
+		reference: reference,
+	}
+}
+
+func (entity *PriceDivergenceEntity) GetID() string {
+	return "divergence"
+}
+
+func (entity *PriceDivergenceEntity) Actions() []*ttypes.ActionDesc {
+	return nil
+}
+
+func (entity *PriceDivergenceEntity) HandleCommand(ctx context.Context, cmd string, args map[string]string) error {
+	return nil
+}
+
+// Restartable reports that Run is a self-contained polling loop, restarted after a panic
+func (entity *PriceDivergenceEntity) Restartable() bool {
+	return true
+}
+
+func (entity *PriceDivergenceEntity) Run(ctx context.Context, ch chan ttypes.IEvent) {
+	ticker := time.NewTicker(entity.config.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("divergence entity done")
+			return
+		case <-ticker.C:
+			evt, err := entity.check(ctx)
+			if err != nil {
+				log.WithError(err).Error("check price divergence error")
+				continue
+			}
+
+			if evt != nil {
+				ch <- evt
+			}
+		}
+	}
+}
+
+// IsEntryBlocked reports whether new positions should be rejected, with the reason
+func (entity *PriceDivergenceEntity) IsEntryBlocked() (bool, string) {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if !entity.config.BlockEntries || !entity.abnormal {
+		return false, ""
+	}
+
+	prompts := NewPriceDivergenceEvent(EventPriceDivergence, entity.last).ToPrompts()
+	return true, prompts[0]
+}
+
+// check compares the prices and returns an event only when the divergence becomes abnormal or normal again
+func (entity *PriceDivergenceEntity) check(ctx context.Context) (ttypes.IEvent, error) {
+	localTicker, err := entity.local.QueryTicker(ctx, entity.symbol)
+	if err != nil {
+		return nil, errors.Wrap(err, "query local ticker error")
+	}
+
+	referenceSymbol := entity.config.ReferenceSymbol
+	if referenceSymbol == "" {
+		referenceSymbol = entity.symbol
+	}
+
+	referenceTicker, err := entity.reference.QueryTicker(ctx, referenceSymbol)
+	if err != nil {
+		return nil, errors.Wrap(err, "query reference ticker error")
+	}
+
+	localPrice := localTicker.Last.Float64()
+	referencePrice := referenceTicker.Last.Float64()
+	if localPrice <= 0 || referencePrice <= 0 {
+		return nil, errors.Errorf("invalid prices, local: %f, reference: %f", localPrice, referencePrice)
+	}
+
+	divergence := (localPrice - referencePrice) / referencePrice * 100
+	abnormal := math.Abs(divergence) > entity.config.Threshold
+
+	log.WithField("local", localPrice).
+		WithField("reference", referencePrice).
+		WithField("divergence", divergence).
+		Debug("price divergence checked")
+
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	changed := abnormal != entity.abnormal
+	entity.abnormal = abnormal
+	entity.last = PriceDivergenceEventData{
+		Symbol:            entity.symbol,
+		LocalPrice:        localPrice,
+		ReferencePrice:    referencePrice,
+		DivergencePercent: divergence,
+		Threshold:         entity.config.Threshold,
+		EntriesBlocked:    abnormal && entity.config.BlockEntries,
+	}
+
+	if !changed {
+		return nil, nil
+	}
+
+	if abnormal {
+		log.WithField("divergence", divergence).Warn("abnormal price divergence")
+		return NewPriceDivergenceEvent(EventPriceDivergence, entity.last), nil
+	}
+
+	return NewPriceDivergenceEvent(EventPriceConverged, entity.last), nil
+}
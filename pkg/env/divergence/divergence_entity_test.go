@@ -0,0 +1,53 @@
+package divergence
+
+import (
+	"context"
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+type fakeTicker struct {
+	price float64
+}
+
+func (f *fakeTicker) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	return &types.Ticker{Last: fixedpoint.NewFromFloat(f.price)}, nil
+}
+
+func TestPriceDivergenceEntity_Check(t *testing.T) {
+	local := &fakeTicker{price: 100}
+	reference := &fakeTicker{price: 100}
+	entity := NewPriceDivergenceEntity("BTCUSDT", &config.PriceDivergenceConfig{Threshold: 1, BlockEntries: true}, local, reference)
+
+	evt, err := entity.check(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, evt)
+
+	local.price = 98
+	evt, err = entity.check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, EventPriceDivergence, evt.GetType())
+	assert.Contains(t, evt.ToPrompts()[0], "blocked")
+
+	blocked, reason := entity.IsEntryBlocked()
+	assert.True(t, blocked)
+	assert.Contains(t, reason, "-2.00%")
+
+	// no repeated warning while the divergence stays abnormal
+	evt, err = entity.check(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, evt)
+
+	local.price = 100.5
+	evt, err = entity.check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, EventPriceConverged, evt.GetType())
+
+	blocked, _ = entity.IsEntryBlocked()
+	assert.False(t, blocked)
+}
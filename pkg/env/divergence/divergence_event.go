@@ -0,0 +1,54 @@
+package divergence
+
+import (
+	"fmt"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+const (
+	EventPriceDivergence = "price_divergence"
+	EventPriceConverged  = "price_converged"
+)
+
+// PriceDivergenceEventData is the local and reference price at the time of the check
+type PriceDivergenceEventData struct {
+	Symbol            string
+	LocalPrice        float64
+	ReferencePrice    float64
+	DivergencePercent float64
+	Threshold         float64
+	EntriesBlocked    bool
+}
+
+// PriceDivergenceEvent warns the agent that the local price deviates from the reference exchange
+type PriceDivergenceEvent struct {
+	types.Event
+	data PriceDivergenceEventData
+}
+
+func NewPriceDivergenceEvent(ty string, data PriceDivergenceEventData) *PriceDivergenceEvent {
+	return &PriceDivergenceEvent{
+		Event: *types.NewEvent(ty, data),
+		data:  data,
+	}
+}
+
+// ToPrompts describes the divergence, or that prices converged again
+func (e *PriceDivergenceEvent) ToPrompts() []string {
+	d := e.data
+
+	if e.GetType() == EventPriceConverged {
+		return []string{fmt.Sprintf("The %s price on the trading exchange (%.4f) is back in line with the reference exchange (%.4f), divergence %.2f%%.",
+			d.Symbol, d.LocalPrice, d.ReferencePrice, d.DivergencePercent)}
+	}
+
+	msg := fmt.Sprintf("Warning: the %s price on the trading exchange (%.4f) deviates %.2f%% from the reference exchange (%.4f), above the %.2f%% threshold. "+
+		"The local price may be an exchange-specific wick or bad data, do not rely on it for entries.",
+		d.Symbol, d.LocalPrice, d.DivergencePercent, d.ReferencePrice, d.Threshold)
+	if d.EntriesBlocked {
+		msg += " Opening new positions is blocked until prices converge."
+	}
+
+	return []string{msg}
+}
@@ -6,9 +6,23 @@ import (
 	"github.com/yubing744/trading-gpt/pkg/types"
 )
 
+// IEntity is a part of the environment: it emits events for the agent and executes the actions it declares
 type IEntity interface {
+	// GetID is the prefix of the entity's actions, e.g. "exchange" for exchange.open_long_position
 	GetID() string
+	// Actions describes the commands the agent may send to the entity
 	Actions() []*types.ActionDesc
+	// HandleCommand executes one of the entity's actions
 	HandleCommand(ctx context.Context, cmd string, args map[string]string) error
+	// Run emits the entity's events to ch until ctx is done
 	Run(ctx context.Context, ch chan types.IEvent)
 }
+
+// IRestartableEntity is an entity whose Run is a self-contained loop, restarted with backoff after a panic. The Run
+// of other entities, e.g. one registering stream callbacks or starting goroutines, runs once, since running it
+// again would duplicate them
+type IRestartableEntity interface {
+	IEntity
+	// Restartable reports whether Run may run again after a panic
+	Restartable() bool
+}
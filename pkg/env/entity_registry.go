@@ -0,0 +1,72 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// EntityFactory creates an entity from its raw config, the "config" block of its env.plugins entry
+type EntityFactory func(ctx context.Context, cfg json.RawMessage) (IEntity, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]EntityFactory)
+)
+
+// RegisterEntityFactory makes an entity type available to env.plugins. It is meant to be called from the init
+// function of the package providing the entity, which is compiled in with a blank import; registering a type
+// twice panics.
+func RegisterEntityFactory(entityType string, factory EntityFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("env: RegisterEntityFactory factory is nil")
+	}
+
+	if _, dup := factories[entityType]; dup {
+		panic("env: RegisterEntityFactory called twice for entity type " + entityType)
+	}
+
+	factories[entityType] = factory
+}
+
+// EntityTypes returns the registered entity types, sorted
+func EntityTypes() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	types := make([]string, 0, len(factories))
+	for ty := range factories {
+		types = append(types, ty)
+	}
+
+	sort.Strings(types)
+	return types
+}
+
+// NewEntity creates an entity of a registered type
+func NewEntity(ctx context.Context, entityType string, cfg json.RawMessage) (IEntity, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[entityType]
+	factoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown entity type: %s, registered: %v", entityType, EntityTypes())
+	}
+
+	// An omitted config block is an empty one, so factories can always unmarshal it
+	if len(cfg) == 0 {
+		cfg = json.RawMessage("{}")
+	}
+
+	entity, err := factory(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create entity %s: %w", entityType, err)
+	}
+
+	return entity, nil
+}
@@ -0,0 +1,43 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEntity(t *testing.T) {
+	RegisterEntityFactory("test_stub", func(ctx context.Context, cfg json.RawMessage) (IEntity, error) {
+		var opts struct {
+			Fail bool `json:"fail"`
+		}
+		if err := json.Unmarshal(cfg, &opts); err != nil {
+			return nil, err
+		}
+		if opts.Fail {
+			return nil, errors.New("bad config")
+		}
+		return &stubEntity{}, nil
+	})
+
+	entity, err := NewEntity(context.Background(), "test_stub", json.RawMessage(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "stub", entity.GetID())
+	assert.Contains(t, EntityTypes(), "test_stub")
+
+	_, err = NewEntity(context.Background(), "test_stub", json.RawMessage(`{"fail":true}`))
+	assert.ErrorContains(t, err, "bad config")
+
+	_, err = NewEntity(context.Background(), "test_stub", nil)
+	assert.NoError(t, err)
+
+	_, err = NewEntity(context.Background(), "missing", nil)
+	assert.ErrorContains(t, err, "unknown entity type")
+
+	assert.Panics(t, func() {
+		RegisterEntityFactory("test_stub", func(ctx context.Context, cfg json.RawMessage) (IEntity, error) { return nil, nil })
+	})
+}
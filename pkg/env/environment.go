@@ -5,15 +5,24 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/metrics"
 	"github.com/yubing744/trading-gpt/pkg/types"
 	"github.com/yubing744/trading-gpt/pkg/utils"
 )
 
 var log = logrus.WithField("env", "environment")
 
+const (
+	// restartBaseDelay is the delay before restarting a panicked entity or the event loop, doubled per restart
+	restartBaseDelay = time.Second
+	// restartMaxDelay caps the restart delay
+	restartMaxDelay = time.Minute
+)
+
 type Environment struct {
 	entites       map[string]IEntity
 	callbacks     []types.EventCallback
@@ -32,6 +41,12 @@ func (env *Environment) RegisterEntity(entity IEntity) {
 	env.entites[entity.GetID()] = entity
 }
 
+// Entity returns the registered entity with the id
+func (env *Environment) Entity(id string) (IEntity, bool) {
+	entity, ok := env.entites[id]
+	return entity, ok
+}
+
 func (env *Environment) Actions() []*types.ActionDesc {
 	actions := make([]*types.ActionDesc, 0)
 
@@ -41,6 +56,7 @@ func (env *Environment) Actions() []*types.ActionDesc {
 				Name:        fmt.Sprintf("%s.%s", ent.GetID(), action.Name),
 				Description: action.Description,
 				Args:        action.Args,
+				Samples:     action.Samples,
 			})
 		}
 	}
@@ -49,20 +65,56 @@ func (env *Environment) Actions() []*types.ActionDesc {
 }
 
 func (env *Environment) SendCommand(ctx context.Context, fullCmd string, args map[string]string) error {
+	entity, cmd, err := env.resolveCommand(fullCmd, args)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = entity.HandleCommand(ctx, cmd, args)
+	category := metrics.RecordAction(fullCmd, time.Since(start), err)
+	if err != nil {
+		log.WithError(err).
+			WithField("cmd", fullCmd).
+			WithField("error_category", category).
+			WithField("latency", time.Since(start)).
+			Warn("command failed")
+	}
+
+	return err
+}
+
+// ValidateCommand checks that the command is supported by a registered entity, without executing it
+func (env *Environment) ValidateCommand(fullCmd string, args map[string]string) error {
+	entity, cmd, err := env.resolveCommand(fullCmd, args)
+	if err != nil {
+		return err
+	}
+
+	for _, action := range entity.Actions() {
+		if action.Name == cmd {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("command not supported: %s", fullCmd)
+}
+
+func (env *Environment) resolveCommand(fullCmd string, args map[string]string) (IEntity, string, error) {
 	dotIndex := strings.Index(fullCmd, ".")
 	if dotIndex == -1 || strings.Contains(fullCmd[dotIndex+1:], ".") {
-		return errors.New("cmd not correct, can not parse entity_id")
+		return nil, "", errors.New("cmd not correct, can not parse entity_id")
 	}
 
 	entityName := fullCmd[:dotIndex]
 	cmd := fullCmd[dotIndex+1:]
 
 	if entityName == "" || cmd == "" {
-		return errors.New("empty entityName or cmd")
+		return nil, "", errors.New("empty entityName or cmd")
 	}
 
 	if env.entites == nil {
-		return errors.New("entities map is nil")
+		return nil, "", errors.New("entities map is nil")
 	}
 
 	entity, ok := env.entites[entityName]
@@ -73,10 +125,10 @@ func (env *Environment) SendCommand(ctx context.Context, fullCmd string, args ma
 			WithField("args", args).
 			Debug("not found entity")
 
-		return errors.New("entity not found")
+		return nil, "", errors.New("entity not found")
 	}
 
-	return entity.HandleCommand(ctx, cmd, args)
+	return entity, cmd, nil
 }
 
 func (env *Environment) OnEvent(cb types.EventCallback) {
@@ -86,19 +138,48 @@ func (env *Environment) OnEvent(cb types.EventCallback) {
 func (env *Environment) Start(ctx context.Context) error {
 	ch := make(chan types.IEvent)
 
+	// A panic in one entity must not take down the others or the event loop
 	for _, entity := range env.entites {
 		go func(ent IEntity) {
-			ent.Run(ctx, ch)
+			if restartable, ok := ent.(IRestartableEntity); ok && restartable.Restartable() {
+				supervisor(ent.GetID()).Run(ctx, func(ctx context.Context) {
+					ent.Run(ctx, ch)
+				})
+				return
+			}
+
+			if err := utils.Recover(func() { ent.Run(ctx, ch) }); err != nil {
+				log.WithField("subsystem", ent.GetID()).
+					Errorf("%s, not restarted since its setup would run twice:\n%s", err.Error(), err.Stack)
+				metrics.RecordPanic(ent.GetID())
+			}
 		}(entity)
 	}
 
 	go func() {
-		env.run(ctx, ch)
+		supervisor("env").Run(ctx, func(ctx context.Context) {
+			env.run(ctx, ch)
+		})
 	}()
 
 	return nil
 }
 
+// supervisor restarts the subsystem with backoff after a panic, logging the stack and counting the restarts
+func supervisor(subsystem string) *utils.Supervisor {
+	return &utils.Supervisor{
+		BaseDelay: restartBaseDelay,
+		MaxDelay:  restartMaxDelay,
+		OnPanic: func(err *utils.PanicError, restarts int) {
+			log.WithField("subsystem", subsystem).
+				WithField("restarts", restarts).
+				Errorf("%s, restarting:\n%s", err.Error(), err.Stack)
+			metrics.RecordPanic(subsystem)
+			metrics.RecordRestart(subsystem)
+		},
+	}
+}
+
 func (env *Environment) Stop(ctx context.Context) {
 
 }
@@ -115,6 +196,11 @@ func (env *Environment) run(ctx context.Context, ch chan types.IEvent) error {
 	}
 }
 
+// Emit delivers an event as if an entity emitted it, e.g. to replay recorded events
+func (env *Environment) Emit(evt types.IEvent) {
+	env.emitEvent(evt)
+}
+
 func (env *Environment) emitEvent(evt types.IEvent) {
 	log.WithField("event", evt).Info("env emit event")
 
@@ -126,7 +212,11 @@ func (env *Environment) emitEvent(evt types.IEvent) {
 		return
 	}
 
+	// A panicking callback is skipped for this event, the others still get it
 	for _, cb := range env.callbacks {
-		cb(evt)
+		if err := utils.Recover(func() { cb(evt) }); err != nil {
+			log.WithField("eventType", evt.GetType()).Errorf("event callback %s:\n%s", err.Error(), err.Stack)
+			metrics.RecordPanic("env_callback")
+		}
 	}
 }
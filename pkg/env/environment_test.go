@@ -1,13 +1,112 @@
 package env
 
 import (
+	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/types"
 )
 
 func TestNewEnvironment(t *testing.T) {
 	env := NewEnvironment(&config.EnvConfig{})
 	assert.NotNil(t, env)
 }
+
+type stubEntity struct{}
+
+func (ent *stubEntity) GetID() string { return "stub" }
+
+func (ent *stubEntity) Actions() []*types.ActionDesc {
+	return []*types.ActionDesc{{Name: "close_position"}}
+}
+
+func (ent *stubEntity) HandleCommand(ctx context.Context, cmd string, args map[string]string) error {
+	return nil
+}
+
+func (ent *stubEntity) Run(ctx context.Context, ch chan types.IEvent) {}
+
+func TestValidateCommand(t *testing.T) {
+	env := NewEnvironment(&config.EnvConfig{})
+	env.RegisterEntity(&stubEntity{})
+
+	assert.NoError(t, env.ValidateCommand("stub.close_position", nil))
+	assert.Error(t, env.ValidateCommand("stub.open_position", nil))
+	assert.Error(t, env.ValidateCommand("other.close_position", nil))
+	assert.Error(t, env.ValidateCommand("close_position", nil))
+}
+
+func TestEmitEventIsolatesPanickingCallbacks(t *testing.T) {
+	env := NewEnvironment(&config.EnvConfig{IncludeEvents: []string{"news"}})
+
+	received := 0
+	env.OnEvent(func(evt types.IEvent) {
+		panic("boom")
+	})
+	env.OnEvent(func(evt types.IEvent) {
+		received++
+	})
+
+	assert.NotPanics(t, func() {
+		env.Emit(types.NewEvent("news", "headline"))
+	})
+	assert.Equal(t, 1, received)
+}
+
+// callbackEntity registers a callback each time it runs, then panics like a failing setup
+type callbackEntity struct {
+	stubEntity
+	runs      atomic.Int32
+	callbacks atomic.Int32
+}
+
+func (ent *callbackEntity) GetID() string { return "callbacks" }
+
+func (ent *callbackEntity) Run(ctx context.Context, ch chan types.IEvent) {
+	ent.runs.Add(1)
+	ent.callbacks.Add(1)
+	panic("setup failed")
+}
+
+// loopEntity panics in its first run and then polls until done
+type loopEntity struct {
+	stubEntity
+	runs atomic.Int32
+}
+
+func (ent *loopEntity) GetID() string { return "loop" }
+
+func (ent *loopEntity) Restartable() bool { return true }
+
+func (ent *loopEntity) Run(ctx context.Context, ch chan types.IEvent) {
+	if ent.runs.Add(1) == 1 {
+		panic("boom")
+	}
+
+	<-ctx.Done()
+}
+
+func TestStartRestartsOnlyRestartableEntities(t *testing.T) {
+	env := NewEnvironment(&config.EnvConfig{})
+	callbacks := &callbackEntity{}
+	loop := &loopEntity{}
+	env.RegisterEntity(callbacks)
+	env.RegisterEntity(loop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, env.Start(ctx))
+
+	assert.Eventually(t, func() bool {
+		return loop.runs.Load() == 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	// Running the setup again would register its callbacks twice
+	assert.Equal(t, int32(1), callbacks.runs.Load())
+	assert.Equal(t, int32(1), callbacks.callbacks.Load())
+}
@@ -0,0 +1,125 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+var log = logrus.WithField("env", "eventlog")
+
+// Record is an env event as it was emitted
+type Record struct {
+	Time    time.Time       `json:"time"`
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data,omitempty"`    // The event data, omitted when it can't be encoded
+	Prompts []string        `json:"prompts,omitempty"` // What the agent was told about the event
+}
+
+// Writer appends env events to a JSON lines log
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewWriter opens the log for appending, creating it and its directory if needed
+func NewWriter(path string) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Wrap(err, "create event log dir error")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "open event log error")
+	}
+
+	return &Writer{file: file}, nil
+}
+
+// Write appends the event with the prompts it produced
+func (w *Writer) Write(evt ttypes.IEvent, prompts []string) error {
+	record := &Record{
+		Time:    time.Now(),
+		ID:      evt.GetID(),
+		Type:    evt.GetType(),
+		Prompts: prompts,
+	}
+
+	if evt.GetData() != nil {
+		data, err := json.Marshal(evt.GetData())
+		if err != nil {
+			log.WithError(err).WithField("type", evt.GetType()).Debug("event data not encodable, recording prompts only")
+		} else {
+			record.Data = data
+		}
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "encode event record error")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err = w.file.Write(append(line, '\n'))
+	return errors.Wrap(err, "write event record error")
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Close()
+}
+
+// ReadEach calls fn with the records of the log in order, stopping at the first error
+func ReadEach(path string, fn func(record *Record) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "open event log error")
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	for {
+		record := &Record{}
+		err := decoder.Decode(record)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "decode event record error")
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// ReplayedEvent is a recorded event whose data could not be restored, the agent is fed its recorded prompts
+type ReplayedEvent struct {
+	ttypes.Event
+	Record *Record
+}
+
+func NewReplayedEvent(record *Record) *ReplayedEvent {
+	return &ReplayedEvent{
+		Event:  *ttypes.NewEvent(record.Type, record.Data),
+		Record: record,
+	}
+}
+
+func (e *ReplayedEvent) ToPrompts() []string {
+	return e.Record.Prompts
+}
@@ -0,0 +1,36 @@
+package eventlog
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+func TestWriteReadEach(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "events.jsonl")
+
+	writer, err := NewWriter(path)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Write(ttypes.NewEvent("fng_changed", "42"), []string{"fear and greed 42"}))
+	assert.NoError(t, writer.Write(ttypes.NewEvent("indicator_changed", func() {}), []string{"RSI 55"}))
+	assert.NoError(t, writer.Write(ttypes.NewEvent("update_finish", nil), nil))
+	assert.NoError(t, writer.Close())
+
+	records := make([]*Record, 0)
+	err = ReadEach(path, func(record *Record) error {
+		records = append(records, record)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	assert.Equal(t, "fng_changed", records[0].Type)
+	assert.JSONEq(t, `"42"`, string(records[0].Data))
+	assert.Nil(t, records[1].Data, "unencodable data is dropped")
+	assert.Equal(t, []string{"RSI 55"}, NewReplayedEvent(records[1]).ToPrompts())
+	assert.Equal(t, "update_finish", records[2].Type)
+	assert.False(t, records[2].Time.IsZero())
+}
@@ -0,0 +1,146 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EventActionResult is emitted after the exchange entity executed or rejected a command
+const EventActionResult = "action_result"
+
+// OrderReceipt is an order submitted while executing a command
+type OrderReceipt struct {
+	OrderID       uint64
+	ClientOrderID string
+	Side          string
+	Type          string
+	Quantity      float64
+	Price         float64 // Limit price, 0 for market orders
+	AveragePrice  float64 // Average fill price, 0 when not filled yet
+	Status        string
+}
+
+// ActionResult is the outcome of a command executed by the exchange entity
+type ActionResult struct {
+	Command       string
+	Args          map[string]string
+	Success       bool
+	Reason        string // Rejection or failure reason
+	MarketPrice   float64
+	Orders        []OrderReceipt
+	Timestamp     time.Time
+	CycleID       string              // Decision cycle the command was issued in
+	StopLoss      float64             // Stop-loss trigger price actually placed, 0 without one
+	TakeProfit    float64             // Take-profit trigger price actually placed, 0 without one
+	Adjustments   []string            // How the requested trigger prices were changed to valid ones
+	RiskSize      *utils.RiskSize     // Size computed from the requested risk per trade, nil with full sizing
+	PositionSize  *utils.PositionSize // Size computed from the requested share of the balance, nil with full sizing
+	Latency       time.Duration       // Time taken to execute the command
+	ErrorCategory string              // Category of the failure reason, e.g. min_notional
+}
+
+// commandReceipt collects what a command did, it travels in the context of the command so concurrent
+// commands each fill their own
+type commandReceipt struct {
+	Orders       []types.Order
+	StopLoss     float64 // Stop-loss placed, after snapping to a valid price
+	TakeProfit   float64 // Take-profit placed, after snapping to a valid price
+	Adjustments  []string
+	RiskSize     *utils.RiskSize
+	PositionSize *utils.PositionSize
+}
+
+type commandReceiptKey struct{}
+
+func withCommandReceipt(ctx context.Context, receipt *commandReceipt) context.Context {
+	return context.WithValue(ctx, commandReceiptKey{}, receipt)
+}
+
+// receiptFrom returns the receipt of the command being executed, a discarded one outside of commands
+func receiptFrom(ctx context.Context) *commandReceipt {
+	if receipt, ok := ctx.Value(commandReceiptKey{}).(*commandReceipt); ok {
+		return receipt
+	}
+
+	return &commandReceipt{}
+}
+
+// NewOrderReceipt converts a submitted order to a receipt
+func NewOrderReceipt(order types.Order) OrderReceipt {
+	return OrderReceipt{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Side:          string(order.Side),
+		Type:          string(order.Type),
+		Quantity:      order.Quantity.Float64(),
+		Price:         order.Price.Float64(),
+		AveragePrice:  order.AveragePrice.Float64(),
+		Status:        string(order.Status),
+	}
+}
+
+// ActionResultEvent tells the agent whether its last command actually happened
+type ActionResultEvent struct {
+	ttypes.Event
+	Result ActionResult
+}
+
+func NewActionResultEvent(result ActionResult) *ActionResultEvent {
+	return &ActionResultEvent{
+		Event:  *ttypes.NewEvent(EventActionResult, result),
+		Result: result,
+	}
+}
+
+func (e *ActionResultEvent) ToPrompts() []string {
+	r := e.Result
+	sb := strings.Builder{}
+
+	if !r.Success {
+		sb.WriteString(fmt.Sprintf("Your last command %s was rejected at %s: %s. No order was placed.",
+			r.Command, r.Timestamp.Format(time.RFC3339), r.Reason))
+		return []string{sb.String()}
+	}
+
+	sb.WriteString(fmt.Sprintf("Your last command %s was executed at %s (market price %.4f).",
+		r.Command, r.Timestamp.Format(time.RFC3339), r.MarketPrice))
+
+	if len(r.Orders) == 0 {
+		sb.WriteString(" No order was submitted.")
+	}
+
+	for _, order := range r.Orders {
+		sb.WriteString(fmt.Sprintf("\nOrder %d: %s %s %.6f, status %s", order.OrderID, order.Type, order.Side, order.Quantity, order.Status))
+		if order.AveragePrice > 0 {
+			sb.WriteString(fmt.Sprintf(", filled at %.4f", order.AveragePrice))
+		} else if order.Price > 0 {
+			sb.WriteString(fmt.Sprintf(", limit price %.4f, not filled yet", order.Price))
+		}
+		sb.WriteString(".")
+	}
+
+	if r.StopLoss > 0 {
+		sb.WriteString(fmt.Sprintf("\nStop-loss placed at %g.", r.StopLoss))
+	}
+	if r.TakeProfit > 0 {
+		sb.WriteString(fmt.Sprintf("\nTake-profit placed at %g.", r.TakeProfit))
+	}
+	for _, adjustment := range r.Adjustments {
+		sb.WriteString(fmt.Sprintf("\nAdjusted %s.", adjustment))
+	}
+	if r.RiskSize != nil {
+		sb.WriteString(fmt.Sprintf("\nPosition sized by %s.", r.RiskSize))
+	}
+	if r.PositionSize != nil {
+		sb.WriteString(fmt.Sprintf("\nPosition sized to %s.", r.PositionSize))
+	}
+
+	return []string{sb.String()}
+}
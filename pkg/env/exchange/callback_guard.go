@@ -0,0 +1,16 @@
+package exchange
+
+import (
+	"runtime/debug"
+
+	"github.com/yubing744/trading-gpt/pkg/metrics"
+)
+
+// recoverCallback isolates a panic of a stream or position callback, deferred at its top, so the market and
+// user data streams keep running and the next kline is handled as usual
+func (ent *ExchangeEntity) recoverCallback(name string) {
+	if r := recover(); r != nil {
+		log.WithField("callback", name).WithField("panic", r).Errorf("exchange callback panicked:\n%s", debug.Stack())
+		metrics.RecordPanic("exchange_" + name)
+	}
+}
@@ -0,0 +1,54 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/pkg/errors"
+)
+
+// CoreHoldingPrompt states the core holding constraint for the system prompt, empty without one
+func (s *ExchangeEntity) CoreHoldingPrompt() string {
+	cfg := s.cfg.CoreHolding
+	if !cfg.Enabled || cfg.Quantity <= 0 {
+		return ""
+	}
+
+	base := s.position.Market.BaseCurrency
+	return fmt.Sprintf("Core holding: the account keeps at least %g %s as a long-term holding that must never be sold. "+
+		"Sells, including closes and shorts, are capped so the %s balance stays at or above it; only the %s above it can be traded.",
+		cfg.Quantity, base, base, base)
+}
+
+// capCoreHolding caps a sell of the base currency so the account keeps its core holding
+func (s *ExchangeEntity) capCoreHolding(ctx context.Context, quantity fixedpoint.Value) (fixedpoint.Value, error) {
+	cfg := s.cfg.CoreHolding
+	if !cfg.Enabled || cfg.Quantity <= 0 {
+		return quantity, nil
+	}
+
+	base := s.position.Market.BaseCurrency
+	account, err := s.session.UpdateAccount(ctx)
+	if err != nil {
+		log.WithError(err).Warn("update account error, using the cached balances for the core holding")
+		account = s.session.GetAccount()
+	}
+
+	balance, ok := account.Balance(base)
+	if !ok {
+		return fixedpoint.Zero, errors.Errorf("sell blocked, the %s balance needed to protect the core holding is unknown", base)
+	}
+
+	sellable := balance.Total().Sub(fixedpoint.NewFromFloat(cfg.Quantity))
+	if sellable.Sign() <= 0 {
+		return fixedpoint.Zero, errors.Errorf("sell blocked, the account must keep its core holding of %g %s and holds %v", cfg.Quantity, base, balance.Total())
+	}
+
+	if quantity.Compare(sellable) > 0 {
+		log.WithField("quantity", quantity).WithField("sellable", sellable).Info("sell capped to protect the core holding")
+		return s.position.Market.TruncateQuantity(sellable), nil
+	}
+
+	return quantity, nil
+}
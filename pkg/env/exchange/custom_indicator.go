@@ -0,0 +1,144 @@
+package exchange
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/indicators"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// IndicatorFactory creates a custom Go indicator from its config
+type IndicatorFactory func(cfg *config.IndicatorConfig) indicators.Indicator
+
+var (
+	customIndicatorsMu sync.RWMutex
+	customIndicators   = map[config.IndicatorType]IndicatorFactory{}
+)
+
+// standardIndicatorTypes are the bbgo standard indicators NewExchangeIndicator creates
+var standardIndicatorTypes = []config.IndicatorType{
+	config.IndicatorTypeSMA,
+	config.IndicatorTypeVR,
+	config.IndicatorTypeEWMA,
+	config.IndicatorTypeVWMA,
+	config.IndicatorTypeEMV,
+	config.IndicatorTypeBOLL,
+	config.IndicatorTypeRSI,
+	config.IndicatorTypeATR,
+	config.IndicatorTypeATRP,
+}
+
+func init() {
+	RegisterIndicator(config.IndicatorTypeSuperTrend, func(cfg *config.IndicatorConfig) indicators.Indicator {
+		return indicators.NewSuperTrend(cfg.GetInt("window_size", 10), cfg.GetFloat("multiplier", 3.0), cfg.Format)
+	})
+	RegisterIndicator(config.IndicatorTypeDonchian, func(cfg *config.IndicatorConfig) indicators.Indicator {
+		return indicators.NewDonchian(cfg.GetInt("window_size", 20), cfg.Format)
+	})
+	RegisterIndicator(config.IndicatorTypeKeltner, func(cfg *config.IndicatorConfig) indicators.Indicator {
+		return indicators.NewKeltner(cfg.GetInt("window_size", 20), cfg.GetFloat("multiplier", 2.0), cfg.Format)
+	})
+}
+
+// RegisterIndicator makes a custom Go indicator usable as an indicator type in the exchange config, alongside
+// the bbgo standard indicators. Register before the strategy runs, a later registration of the type replaces it.
+func RegisterIndicator(indicatorType config.IndicatorType, factory IndicatorFactory) {
+	customIndicatorsMu.Lock()
+	defer customIndicatorsMu.Unlock()
+
+	customIndicators[indicatorType] = factory
+}
+
+// IndicatorTypes returns the indicator types usable in the exchange config, the standard and registered ones, sorted
+func IndicatorTypes() []string {
+	customIndicatorsMu.RLock()
+	defer customIndicatorsMu.RUnlock()
+
+	names := make([]string, 0, len(standardIndicatorTypes)+len(customIndicators))
+	for _, indicatorType := range standardIndicatorTypes {
+		names = append(names, string(indicatorType))
+	}
+	for indicatorType := range customIndicators {
+		names = append(names, string(indicatorType))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ValidateIndicators resolves the types of the configured indicators against the standard and registered ones
+func ValidateIndicators(cfgs map[string]*config.IndicatorConfig) error {
+	available := IndicatorTypes()
+
+	for name, cfg := range cfgs {
+		if cfg == nil {
+			return errors.Errorf("indicator %s has no config", name)
+		}
+
+		i := sort.SearchStrings(available, string(cfg.Type))
+		if i == len(available) || available[i] != string(cfg.Type) {
+			return errors.Errorf("unknown type %q of indicator %s, available types: %s", cfg.Type, name, strings.Join(available, ", "))
+		}
+	}
+
+	return nil
+}
+
+// newCustomIndicator creates the registered custom indicator of the config type
+func newCustomIndicator(cfg *config.IndicatorConfig) (indicators.Indicator, bool) {
+	customIndicatorsMu.RLock()
+	factory, ok := customIndicators[cfg.Type]
+	customIndicatorsMu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	return factory(cfg), true
+}
+
+// feedCustomIndicator warms up a custom indicator with the stored klines of its interval and updates it with
+// every closed kline after. With the source param heikin_ashi it is fed Heikin-Ashi candlesticks instead.
+func (ent *ExchangeEntity) feedCustomIndicator(ei *ExchangeIndicator) {
+	custom, ok := ei.Data.(indicators.Indicator)
+	if !ok {
+		return
+	}
+
+	interval := ei.Config.GetInterval("interval", "5m")
+
+	update := custom.Update
+	switch source := ei.Config.GetString("source", "kline"); source {
+	case "kline":
+	case "heikin_ashi":
+		var prev *types.KLine
+		update = func(kline types.KLine) {
+			ha := utils.HeikinAshiKLine(prev, kline)
+			prev = &ha
+			custom.Update(ha)
+		}
+	default:
+		log.WithField("indicator", ei.Name).WithField("source", source).Warn("unsupported indicator source, using the klines")
+	}
+
+	if dataStore, ok := ent.session.MarketDataStore(ent.symbol); ok {
+		if klines, ok := dataStore.KLinesOfInterval(interval); ok {
+			for _, k := range *klines {
+				update(k)
+			}
+		}
+	}
+
+	// Registered ahead of the kline handler of the entity, so the indicator is updated before it is reported
+	ent.session.MarketDataStream.OnKLineClosed(types.KLineWith(ent.symbol, interval, func(kline types.KLine) {
+		defer ent.recoverCallback("indicator")
+
+		update(kline)
+	}))
+}
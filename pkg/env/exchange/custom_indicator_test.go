@@ -0,0 +1,46 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/indicators"
+)
+
+type lastCloseIndicator struct {
+	close float64
+}
+
+func (i *lastCloseIndicator) Update(kline types.KLine) {
+	i.close = kline.Close.Float64()
+}
+
+func (i *lastCloseIndicator) PromptSummary() string {
+	return "last close"
+}
+
+func TestValidateIndicators(t *testing.T) {
+	cfgs := map[string]*config.IndicatorConfig{
+		"RSI":   {Type: config.IndicatorTypeRSI},
+		"TREND": {Type: config.IndicatorTypeSuperTrend},
+	}
+	assert.NoError(t, ValidateIndicators(cfgs))
+
+	cfgs["LAST"] = &config.IndicatorConfig{Type: "last_close"}
+	err := ValidateIndicators(cfgs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown type "last_close" of indicator LAST`)
+
+	RegisterIndicator("last_close", func(cfg *config.IndicatorConfig) indicators.Indicator {
+		return &lastCloseIndicator{}
+	})
+	assert.NoError(t, ValidateIndicators(cfgs))
+	assert.Contains(t, IndicatorTypes(), "last_close")
+
+	custom, ok := newCustomIndicator(cfgs["LAST"])
+	assert.True(t, ok)
+	assert.IsType(t, &lastCloseIndicator{}, custom)
+}
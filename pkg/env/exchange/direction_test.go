@@ -0,0 +1,33 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func actionNames(ent *ExchangeEntity) []string {
+	names := make([]string, 0)
+	for _, action := range ent.Actions() {
+		names = append(names, action.Name)
+	}
+	return names
+}
+
+func TestDirectionPolicy(t *testing.T) {
+	both := &ExchangeEntity{cfg: &config.EnvExchangeConfig{}}
+	assert.Contains(t, actionNames(both), "open_long_position")
+	assert.Contains(t, actionNames(both), "open_short_position")
+
+	longOnly := &ExchangeEntity{cfg: &config.EnvExchangeConfig{Direction: config.DirectionLongOnly}}
+	assert.Contains(t, actionNames(longOnly), "open_long_position")
+	assert.NotContains(t, actionNames(longOnly), "open_short_position")
+	assert.Contains(t, actionNames(longOnly), "close_position")
+	assert.False(t, longOnly.directionAllows("open_short_position"))
+
+	shortOnly := &ExchangeEntity{cfg: &config.EnvExchangeConfig{Direction: config.DirectionShortOnly}}
+	assert.NotContains(t, actionNames(shortOnly), "open_long_position")
+	assert.True(t, shortOnly.directionAllows("open_short_position"))
+}
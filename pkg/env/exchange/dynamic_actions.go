@@ -0,0 +1,33 @@
+package exchange
+
+// stateAllows returns whether the action makes sense in the current state, so the agent isn't offered
+// commands that would be rejected, e.g. close_position while flat. Everything is allowed until the state is known.
+func (ent *ExchangeEntity) stateAllows(name string) bool {
+	if ent.cfg == nil || !ent.cfg.DynamicActions {
+		return true
+	}
+
+	if ent.position == nil || ent.position.Position == nil || ent.KLineWindow == nil || ent.KLineWindow.Len() == 0 {
+		return true
+	}
+
+	gridRunning := ent.Grid() != nil
+	flat := ent.position.IsClosed() || ent.position.IsDust(ent.KLineWindow.GetClose())
+
+	switch name {
+	case "open_long_position":
+		return !gridRunning && (flat || !ent.position.IsLong())
+	case "open_short_position":
+		return !gridRunning && (flat || !ent.position.IsShort())
+	case "close_position", "update_position":
+		return !flat
+	case "start_grid":
+		return !gridRunning && flat
+	case "stop_grid":
+		return gridRunning
+	case "cancel_alert":
+		return ent.priceAlerts != nil && ent.priceAlerts.Len() > 0
+	default:
+		return true
+	}
+}
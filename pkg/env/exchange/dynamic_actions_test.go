@@ -0,0 +1,37 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestDynamicActions(t *testing.T) {
+	position := &types.Position{Symbol: "BTCUSDT", Market: types.Market{Symbol: "BTCUSDT"}}
+	ent := &ExchangeEntity{
+		cfg:         &config.EnvExchangeConfig{DynamicActions: true},
+		position:    NewPositionX(position),
+		KLineWindow: &types.KLineWindow{{Close: fixedpoint.NewFromFloat(100)}},
+	}
+
+	flat := actionNames(ent)
+	assert.Contains(t, flat, "open_long_position")
+	assert.Contains(t, flat, "open_short_position")
+	assert.NotContains(t, flat, "close_position")
+	assert.NotContains(t, flat, "update_position")
+
+	position.Base = fixedpoint.NewFromFloat(1)
+	long := actionNames(ent)
+	assert.NotContains(t, long, "open_long_position")
+	assert.Contains(t, long, "open_short_position")
+	assert.Contains(t, long, "close_position")
+	assert.Contains(t, long, "update_position")
+
+	// Without state every action is offered
+	ent.KLineWindow = nil
+	assert.Contains(t, actionNames(ent), "open_long_position")
+}
@@ -0,0 +1,157 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EntitySnapshot is the exchange entity state kept across restarts
+type EntitySnapshot struct {
+	KLines                 []types.KLine        `json:"klines"`
+	HistoryProfits         []fixedpoint.Value   `json:"history_profits"`
+	AccumulatedProfitValue fixedpoint.Value     `json:"accumulated_profit_value"`
+	LastSide               string               `json:"last_side"`
+	Dust                   bool                 `json:"dust"`
+	PendingOrders          []types.Order        `json:"pending_orders"`
+	OrderSeq               int                  `json:"order_seq"`
+	EntryStop              float64              `json:"entry_stop"`
+	ProfitRatchet          *utils.ProfitRatchet `json:"profit_ratchet,omitempty"`
+	FlatByCheckedAt        time.Time            `json:"flat_by_checked_at"`
+	Grid                   *utils.Grid          `json:"grid,omitempty"`
+}
+
+// Snapshot copies the kline window and position metrics of the entity, the open orders are queried by PendingOrders
+func (ent *ExchangeEntity) Snapshot() *EntitySnapshot {
+	snapshot := &EntitySnapshot{
+		OrderSeq:        ent.orderSeq,
+		EntryStop:       ent.entryStop,
+		FlatByCheckedAt: ent.flatByCheckedAt,
+		Grid:            ent.Grid(),
+	}
+
+	if ent.profitRatchet != nil {
+		ratchet := *ent.profitRatchet
+		snapshot.ProfitRatchet = &ratchet
+	}
+
+	if ent.KLineWindow != nil {
+		snapshot.KLines = append([]types.KLine{}, (*ent.KLineWindow)...)
+	}
+
+	if ent.position != nil {
+		snapshot.HistoryProfits = append([]fixedpoint.Value{}, ent.position.historyProfits...)
+		snapshot.AccumulatedProfitValue = ent.position.AccumulatedProfitValue
+		snapshot.LastSide = ent.position.lastSide
+		snapshot.Dust = ent.position.Dust
+	}
+
+	return snapshot
+}
+
+// PendingOrders queries the open orders of the symbol kept in the snapshot, so those filled or cancelled while down
+// are reported at restore
+func (ent *ExchangeEntity) PendingOrders(ctx context.Context) ([]types.Order, error) {
+	if ent.session == nil {
+		return nil, nil
+	}
+
+	return ent.venue.QueryOpenOrders(ctx, ent.symbol)
+}
+
+// Restore sets the state to restore, applied once the entity runs and has loaded the market data
+func (ent *ExchangeEntity) Restore(snapshot *EntitySnapshot) {
+	ent.restored = snapshot
+}
+
+func (ent *ExchangeEntity) applyRestored(ctx context.Context) {
+	snapshot := ent.restored
+	if snapshot == nil {
+		return
+	}
+	ent.restored = nil
+
+	window := restoreKLines(snapshot.KLines, *ent.KLineWindow, ent.cfg.RequiredKlineNum())
+	ent.KLineWindow = &window
+
+	if ent.position != nil {
+		ent.position.historyProfits = append(ent.position.historyProfits, snapshot.HistoryProfits...)
+		ent.position.AccumulatedProfitValue = snapshot.AccumulatedProfitValue
+		ent.position.lastSide = snapshot.LastSide
+		ent.position.Dust = snapshot.Dust
+	}
+
+	ent.orderSeq = snapshot.OrderSeq
+	ent.entryStop = snapshot.EntryStop
+	ent.profitRatchet = snapshot.ProfitRatchet
+
+	// Grid orders filled while down are reported by checkPendingOrders, their counter orders are not placed
+	if snapshot.Grid != nil && ent.cfg.Grid.Enabled {
+		ent.gridMu.Lock()
+		ent.grid = snapshot.Grid
+		ent.gridMu.Unlock()
+	}
+
+	// A flat-by deadline passed while down still closes the position
+	if ent.flatBy != nil && !snapshot.FlatByCheckedAt.IsZero() {
+		ent.flatByCheckedAt = snapshot.FlatByCheckedAt
+	}
+
+	if len(snapshot.PendingOrders) > 0 {
+		ent.checkPendingOrders(ctx, snapshot.PendingOrders)
+	}
+
+	log.
+		WithField("klines", window.Len()).
+		WithField("pending_orders", len(snapshot.PendingOrders)).
+		Info("exchange entity state restored")
+}
+
+// checkPendingOrders reports the orders pending at snapshot time that were filled or cancelled while down
+func (ent *ExchangeEntity) checkPendingOrders(ctx context.Context, pending []types.Order) {
+	orders, err := ent.venue.QueryOpenOrders(ctx, ent.symbol)
+	if err != nil {
+		log.WithError(err).Warn("query open orders for restore failed")
+		return
+	}
+
+	open := make(map[uint64]bool, len(orders))
+	for _, order := range orders {
+		open[order.OrderID] = true
+	}
+
+	for _, order := range pending {
+		if !open[order.OrderID] {
+			log.
+				WithField("order_id", order.OrderID).
+				WithField("type", order.Type).
+				WithField("side", order.Side).
+				Warn("order pending at snapshot is no longer open, filled or cancelled while down")
+		}
+	}
+}
+
+// restoreKLines prepends the snapshot klines older than the loaded ones, keeping at most maxNum klines
+func restoreKLines(snapshot []types.KLine, loaded types.KLineWindow, maxNum int) types.KLineWindow {
+	window := types.KLineWindow{}
+
+	for _, kline := range snapshot {
+		if len(loaded) == 0 || kline.StartTime.Time().Before(loaded[0].StartTime.Time()) {
+			window.Add(kline)
+		}
+	}
+
+	for _, kline := range loaded {
+		window.Add(kline)
+	}
+
+	if maxNum > 0 && window.Len() > maxNum {
+		window.Truncate(maxNum)
+	}
+
+	return window
+}
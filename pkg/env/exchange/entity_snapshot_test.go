@@ -0,0 +1,37 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testKLines(start time.Time, num int) []types.KLine {
+	klines := make([]types.KLine, 0, num)
+	for i := 0; i < num; i++ {
+		klines = append(klines, types.KLine{StartTime: types.Time(start.Add(time.Duration(i) * time.Minute))})
+	}
+	return klines
+}
+
+func TestRestoreKLines(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := testKLines(start, 10)
+	loaded := types.KLineWindow(testKLines(start.Add(5*time.Minute), 10))
+
+	// Overlapping klines are taken from the loaded window
+	window := restoreKLines(snapshot, loaded, 0)
+	assert.Len(t, window, 15)
+	assert.Equal(t, start, window[0].StartTime.Time())
+	assert.Equal(t, loaded[0], window[5])
+
+	window = restoreKLines(snapshot, loaded, 12)
+	assert.Len(t, window, 12)
+	assert.Equal(t, loaded[len(loaded)-1], window[11])
+
+	// Nothing loaded from the market data store
+	window = restoreKLines(snapshot, nil, 0)
+	assert.Len(t, window, 10)
+}
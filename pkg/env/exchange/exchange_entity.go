@@ -3,9 +3,12 @@ package exchange
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
@@ -29,15 +32,66 @@ type ExchangeEntity struct {
 
 	cfg *config.EnvExchangeConfig
 
-	session       *bbgo.ExchangeSession
-	orderExecutor *bbgo.GeneralOrderExecutor
-	position      *PositionX
+	session  *bbgo.ExchangeSession
+	venue    ExecutionVenue
+	position *PositionX
 
 	Status      types.StrategyStatus
 	Indicators  []*ExchangeIndicator
 	KLineWindow *types.KLineWindow
 
 	vm *goja.Runtime
+
+	// events reported back to the agent
+	ch       chan ttypes.IEvent
+	orderSeq int
+
+	// state restored from a snapshot, applied when the entity runs
+	restored *EntitySnapshot
+
+	// set while another instance trades the account and symbol
+	observeOnly atomic.Bool
+
+	// guards of the strategy run before entries made outside a command, nil when none
+	entryCheck func(ctx context.Context, side string) error
+
+	// share of the quote balance entries are sized from, stored as float64, unset means the full balance
+	sizeScale atomic.Value
+
+	// leverage permitted for new entries under the current volatility, nil without leverage scaling
+	volatilityLeverage *utils.VolatilityLeverage
+
+	// daily or weekly deadline to be flat by, and when it was last checked
+	flatBy          *utils.FlatSchedule
+	flatByCheckedAt time.Time
+
+	// stop of the latest entry and the ratchet tightening it as the profit grows
+	entryStop     float64
+	profitRatchet *utils.ProfitRatchet
+
+	// retries of exchange calls, with backoff, a retry budget and a circuit breaker
+	retry *utils.RetryPolicy
+
+	// deadline of the exchange calls made on a kline close, 0 when unbounded
+	cycleTimeout time.Duration
+
+	// precisions and order size limits of the market, nil when not loaded
+	marketInfo *utils.MarketInfo
+
+	// grid started by the agent, its fills arrive on the user data stream
+	gridMu sync.Mutex
+	grid   *utils.Grid
+
+	// price levels set by the agent, checked on every kline update, and when one last fired a cycle
+	priceAlerts  *utils.PriceAlerts
+	alertCycleAt time.Time
+
+	// plans deferred by the agent until a kline closes beyond a price
+	intents *utils.Intents
+
+	// mismatch with the exchange position found by the position check, entries are paused while set
+	divergenceMu sync.Mutex
+	divergence   *utils.PositionDivergence
 }
 
 func NewExchangeEntity(
@@ -46,18 +100,19 @@ func NewExchangeEntity(
 	leverage fixedpoint.Value,
 	cfg *config.EnvExchangeConfig,
 	session *bbgo.ExchangeSession,
-	orderExecutor *bbgo.GeneralOrderExecutor,
+	venue ExecutionVenue,
 	position *types.Position,
 ) *ExchangeEntity {
 	return &ExchangeEntity{
-		symbol:        symbol,
-		interval:      interval,
-		leverage:      leverage,
-		cfg:           cfg,
-		session:       session,
-		orderExecutor: orderExecutor,
-		position:      NewPositionX(position),
-		vm:            goja.New(),
+		symbol:   symbol,
+		interval: interval,
+		leverage: leverage,
+		cfg:      cfg,
+		session:  session,
+		venue:    venue,
+		position: NewPositionX(position),
+		vm:       goja.New(),
+		retry:    newRetryPolicy(cfg.Retry),
 	}
 }
 
@@ -66,7 +121,7 @@ func (ent *ExchangeEntity) GetID() string {
 }
 
 func (ent *ExchangeEntity) Actions() []*ttypes.ActionDesc {
-	return []*ttypes.ActionDesc{
+	actions := []*ttypes.ActionDesc{
 		{
 			Name:        "open_long_position",
 			Description: "Open long position (supports market and limit orders; unfilled limit orders auto-cancel at next cycle)",
@@ -95,17 +150,27 @@ func (ent *ExchangeEntity) Actions() []*ttypes.ActionDesc {
 					Name:        "post_only",
 					Description: "Post only: true|false (default: false, maker only)",
 				},
+				{
+					Name:        "confidence",
+					Description: "Confidence in the signal from 0 to 1, reversing a recent entry needs a high confidence or confirmation over several cycles",
+				},
+				{
+					Name:        "risk_percent",
+					Description: "Percent of equity to lose if the stop loss is hit, e.g. 1; sizes the position from the entry and stop-loss distance (requires stop_loss_trigger_price), the full leveraged balance is used when omitted",
+				},
+				{
+					Name:        "position_size_percent",
+					Description: "Percent of the leveraged balance to use by conviction, e.g. 50 for half size; capped by the configured maximum, not combinable with risk_percent, the full leveraged balance is used when omitted",
+				},
 			},
 			Samples: []ttypes.Sample{
 				{
 					Input: []string{
-						"BOLL data changed: UpBand:[2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.91 2.91 2.90 2.90 2.89 2.89 2.89 2.89 2.89 2.89 2.90 2.92], SMA:[2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.86 2.86 2.86 2.85 2.85 2.85 2.85 2.85 2.85 2.85 2.86], DownBand:[2.81 2.81 2.82 2.82 2.82 2.82 2.83 2.83 2.82 2.82 2.82 2.81 2.81 2.82 2.82 2.82 2.82 2.82 2.82 2.81 2.80]",
-						"RSI data changed: [73.454 41.980 25.516 17.727 32.413 18.679 8.576 42.228 29.611 36.948 57.658 46.181 61.506 77.894 76.378 44.059 35.556 50.472 56.603 60.012]",
+						"Close 2.93 broke above the BOLL upper band 2.92 with volume twice the average, RSI rose from 48 to 64",
 						"There are currently no open position",
-						"Analyze data, generate trading cmd",
 					},
 					Output: []string{
-						"Execute cmd: /open_long_position",
+						`{"stop_loss_trigger_price": "2.88", "take_profit_trigger_price": "3.03"}`,
 					},
 				},
 			},
@@ -138,17 +203,27 @@ func (ent *ExchangeEntity) Actions() []*ttypes.ActionDesc {
 					Name:        "post_only",
 					Description: "Post only: true|false (default: false, maker only)",
 				},
+				{
+					Name:        "confidence",
+					Description: "Confidence in the signal from 0 to 1, reversing a recent entry needs a high confidence or confirmation over several cycles",
+				},
+				{
+					Name:        "risk_percent",
+					Description: "Percent of equity to lose if the stop loss is hit, e.g. 1; sizes the position from the entry and stop-loss distance (requires stop_loss_trigger_price), the full leveraged balance is used when omitted",
+				},
+				{
+					Name:        "position_size_percent",
+					Description: "Percent of the leveraged balance to use by conviction, e.g. 50 for half size; capped by the configured maximum, not combinable with risk_percent, the full leveraged balance is used when omitted",
+				},
 			},
 			Samples: []ttypes.Sample{
 				{
 					Input: []string{
-						"BOLL data changed: UpBand:[2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.91 2.91 2.90 2.90 2.89 2.89 2.89 2.89 2.89 2.89 2.90 2.92], SMA:[2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.86 2.86 2.86 2.85 2.85 2.85 2.85 2.85 2.85 2.85 2.86], DownBand:[2.81 2.81 2.82 2.82 2.82 2.82 2.83 2.83 2.82 2.82 2.82 2.81 2.81 2.82 2.82 2.82 2.82 2.82 2.82 2.81 2.80]",
-						"RSI data changed: [2.66 2.65 2.65 2.64 2.64 2.63 2.63 2.63 2.63 2.63 2.63 2.64 2.65 2.66 2.67 2.67 2.68 2.68 2.68 2.68 2.69]",
-						"The current position is short, and average cost: 2.84",
-						"Analyze data, generate trading cmd",
+						"Close 2.79 broke below the BOLL lower band 2.80 with rising volume, RSI fell from 45 to 31",
+						"There are currently no open position",
 					},
 					Output: []string{
-						"Execute cmd: /open_short_position",
+						`{"stop_loss_trigger_price": "2.84", "take_profit_trigger_price": "2.69"}`,
 					},
 				},
 			},
@@ -185,12 +260,11 @@ func (ent *ExchangeEntity) Actions() []*ttypes.ActionDesc {
 			Samples: []ttypes.Sample{
 				{
 					Input: []string{
-						"RSI data changed: [2.66 2.65 2.65 2.64 2.64 2.63 2.63 2.63 2.63 2.63 2.63 2.64 2.65 2.66 2.67 2.67 2.68 2.68 2.68 2.68 2.69]",
-						"The current position is long, and average cost: 2.80",
-						"Analyze data, generate trading cmd",
+						"The current position is long, average cost: 2.80, close 2.91 above the BOLL middle band 2.87, RSI 62",
+						"Trail the stop loss to break even while the trend holds",
 					},
 					Output: []string{
-						"Execute cmd: /update_position",
+						`{"stop_loss_trigger_price": "2.80", "take_profit_trigger_price": "3.00"}`,
 					},
 				},
 			},
@@ -215,22 +289,20 @@ func (ent *ExchangeEntity) Actions() []*ttypes.ActionDesc {
 			Samples: []ttypes.Sample{
 				{
 					Input: []string{
-						"BOLL data changed: UpBand:[2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.92 2.91 2.91 2.90 2.90 2.89 2.89 2.89 2.89 2.89 2.89 2.90 2.92], SMA:[2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.87 2.86 2.86 2.86 2.85 2.85 2.85 2.85 2.85 2.85 2.85 2.86], DownBand:[2.81 2.81 2.82 2.82 2.82 2.82 2.83 2.83 2.82 2.82 2.82 2.81 2.81 2.82 2.82 2.82 2.82 2.82 2.82 2.81 2.80]",
-						"RSI data changed: [2.66 2.65 2.65 2.64 2.64 2.63 2.63 2.63 2.63 2.63 2.63 2.64 2.65 2.66 2.67 2.67 2.68 2.68 2.68 2.68 2.69]",
 						"The current position is long, average cost: 2.736, and accumulated profit: 15.324",
-						"Analyze data, generate trading cmd",
+						"Close 2.91 rejected at the BOLL upper band 2.92 with a bearish engulfing candle, RSI 78 turning down",
 					},
 					Output: []string{
-						"Execute cmd: /close_position",
+						`{}`,
 					},
 				},
 				{
 					Input: []string{
 						"The current position is long, average cost: 2.736, base quantity: 10, accumulated profit value: 120.5",
-						"Analyze data, generate trading cmd to lock part of the profit",
+						"Lock part of the profit ahead of the resistance at 2.92",
 					},
 					Output: []string{
-						"Execute cmd: /close_position percentage=50%",
+						`{"percentage": "50%"}`,
 					},
 				},
 			},
@@ -241,17 +313,54 @@ func (ent *ExchangeEntity) Actions() []*ttypes.ActionDesc {
 			Samples: []ttypes.Sample{
 				{
 					Input: []string{
-						"RSI data changed: [2.66 2.65 2.65 2.64 2.64 2.63 2.63 2.63 2.63 2.63 2.63 2.64 2.65 2.66 2.67 2.67 2.68 2.68 2.68 2.68 2.69]",
-						"The current position is long, and average cost: 2.80",
-						"Analyze data, generate trading cmd",
+						"The current position is long, average cost: 2.80, close 2.85 inside the BOLL bands with flat volume, RSI 52",
+						"The stop loss and take profit are already placed",
 					},
 					Output: []string{
-						"Execute cmd: /no_action",
+						`{}`,
 					},
 				},
 			},
 		},
 	}
+
+	if ent.cfg != nil && ent.cfg.Grid.Enabled {
+		actions = append(actions, ent.gridActions()...)
+	}
+
+	if ent.cfg != nil && ent.cfg.PriceAlert.Enabled {
+		actions = append(actions, ent.priceAlertActions()...)
+	}
+
+	if ent.cfg != nil && ent.cfg.Intent.Enabled {
+		actions = append(actions, ent.intentActions()...)
+	}
+
+	// Opens against the direction policy, and actions invalid in the current state, are not offered at all
+	allowed := make([]*ttypes.ActionDesc, 0, len(actions))
+	for _, action := range actions {
+		if ent.directionAllows(action.Name) && ent.stateAllows(action.Name) {
+			allowed = append(allowed, action)
+		}
+	}
+
+	return allowed
+}
+
+// directionAllows returns whether the direction policy permits the command
+func (ent *ExchangeEntity) directionAllows(cmd string) bool {
+	if ent.cfg == nil {
+		return true
+	}
+
+	switch ent.cfg.Direction {
+	case config.DirectionLongOnly:
+		return cmd != "open_short_position"
+	case config.DirectionShortOnly:
+		return cmd != "open_long_position"
+	default:
+		return true
+	}
 }
 
 func (ent *ExchangeEntity) cmdToSide(cmd string) types.SideType {
@@ -273,12 +382,113 @@ func (ent *ExchangeEntity) getPositionSide(pos *PositionX) types.SideType {
 	}
 }
 
+// HandleCommand executes the command and emits an action_result event with the outcome
 func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args map[string]string) error {
+	receipt := &commandReceipt{}
+
+	start := time.Now()
+	err := ent.executeCommand(withCommandReceipt(ctx, receipt), cmd, args)
+
+	if cmd != "no_action" {
+		ent.emitActionResult(ctx, cmd, args, receipt, err, time.Since(start))
+	}
+
+	return err
+}
+
+// emitActionResult reports the outcome of a command, including the submitted orders
+func (ent *ExchangeEntity) emitActionResult(ctx context.Context, cmd string, args map[string]string, receipt *commandReceipt, err error, latency time.Duration) {
+	if ent.ch == nil {
+		return
+	}
+
+	result := ActionResult{
+		Command:   cmd,
+		Args:      args,
+		Success:   err == nil,
+		Timestamp: time.Now(),
+		CycleID:   ttypes.CycleIDFromContext(ctx),
+		Latency:   latency,
+	}
+	if err != nil {
+		result.Reason = err.Error()
+		result.ErrorCategory = utils.CategorizeExecError(err)
+	}
+	if ent.KLineWindow != nil && ent.KLineWindow.Len() > 0 {
+		result.MarketPrice = ent.KLineWindow.GetClose().Float64()
+	}
+	for _, order := range receipt.Orders {
+		result.Orders = append(result.Orders, NewOrderReceipt(order))
+	}
+	if err == nil {
+		result.StopLoss = receipt.StopLoss
+		result.TakeProfit = receipt.TakeProfit
+		result.Adjustments = receipt.Adjustments
+		result.RiskSize = receipt.RiskSize
+		result.PositionSize = receipt.PositionSize
+	}
+
+	// Commands run inside env event callbacks, so send asynchronously to avoid blocking the event loop
+	go ent.emitEvent(ent.ch, NewActionResultEvent(result))
+}
+
+// SetObserveOnly keeps the entity from placing or cancelling orders while another instance trades
+func (ent *ExchangeEntity) SetObserveOnly(observeOnly bool) {
+	ent.observeOnly.Store(observeOnly)
+}
+
+// SetEntryCheck runs the guards of the strategy before entries made outside a command, such as the market
+// fallback of an expired limit entry
+func (ent *ExchangeEntity) SetEntryCheck(check func(ctx context.Context, side string) error) {
+	ent.entryCheck = check
+}
+
+// SetSizeScale sizes new entries from a share of the quote balance, 1 restores the full size
+func (ent *ExchangeEntity) SetSizeScale(scale float64) {
+	ent.sizeScale.Store(scale)
+}
+
+// SetCycleTimeout bounds the exchange calls made on a kline close, 0 leaves them unbounded
+func (ent *ExchangeEntity) SetCycleTimeout(timeout time.Duration) {
+	ent.cycleTimeout = timeout
+}
+
+// ServerTime returns the clock of the execution venue
+func (ent *ExchangeEntity) ServerTime(ctx context.Context) (time.Time, error) {
+	if ent.venue == nil {
+		return time.Time{}, errors.New("no execution venue")
+	}
+
+	return ent.venue.QueryServerTime(ctx)
+}
+
+// withCycleTimeout bounds the calls of a kline close by the cycle timeout
+func (ent *ExchangeEntity) withCycleTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ent.cycleTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, ent.cycleTimeout)
+}
+
+func (ent *ExchangeEntity) executeCommand(ctx context.Context, cmd string, args map[string]string) error {
 	log.
 		WithField("cmd", cmd).
 		WithField("args", args).
 		Infof("entity exchange handle command")
 
+	if ent.observeOnly.Load() && cmd != "no_action" {
+		return errors.New("observe-only, another instance is trading this account and symbol")
+	}
+
+	if !ent.directionAllows(cmd) {
+		return errors.Errorf("%s blocked by the %s direction policy", cmd, ent.cfg.Direction)
+	}
+
+	if !ent.positionCheckAllows(cmd) {
+		return errors.Errorf("%s blocked, the position diverges from the exchange until an operator reconciles it", cmd)
+	}
+
 	if ent.KLineWindow == nil {
 		log.Warn("skip for current kline nil")
 		return errors.New("current kline nil")
@@ -286,6 +496,21 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 
 	closePrice := ent.KLineWindow.GetClose()
 
+	switch cmd {
+	case "start_grid":
+		return ent.startGrid(ctx, args, closePrice)
+	case "stop_grid":
+		return ent.stopGrid(ctx, "stopped by the agent", false, closePrice)
+	case "set_alert":
+		return ent.setAlert(args, closePrice)
+	case "cancel_alert":
+		return ent.cancelAlert(args)
+	case "schedule_intent":
+		return ent.scheduleIntent(args)
+	case "tighten_stop":
+		return ent.tightenStop(ctx, args)
+	}
+
 	// close position if need
 	if cmd == "close_position" {
 		// TP/SL if there's non-dust position and meets the criteria
@@ -296,12 +521,13 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 				closePercentage := fixedpoint.One
 
 				if percentageArg, ok := args["percentage"]; ok && strings.TrimSpace(percentageArg) != "" {
-					val, err := fixedpoint.NewFromString(strings.TrimSuffix(strings.TrimSpace(percentageArg), "%"))
+					arg, err := utils.ParseNumberArg(percentageArg)
 					if err != nil {
 						return errors.Wrap(err, "invalid close percentage")
 					}
 
-					if strings.Contains(percentageArg, "%") || val.Compare(fixedpoint.One) > 0 {
+					val := arg.Fraction()
+					if !arg.Percent && val.Compare(fixedpoint.One) > 0 {
 						val = val.Div(fixedpoint.NewFromInt(100))
 					}
 
@@ -315,10 +541,11 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 						return errors.New("no position data available")
 					}
 
-					targetQty, err := fixedpoint.NewFromString(strings.TrimSpace(quantityArg))
+					qty, err := utils.ParseNumberArgFloat(quantityArg)
 					if err != nil {
 						return errors.Wrap(err, "invalid close quantity")
 					}
+					targetQty := fixedpoint.NewFromFloat(qty)
 
 					baseQty := ent.position.GetBase().Abs()
 					if baseQty.IsZero() {
@@ -339,10 +566,11 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 						return errors.New("no position data available")
 					}
 
-					targetProfit, err := fixedpoint.NewFromString(strings.TrimSpace(profitArg))
+					profit, err := utils.ParseNumberArgFloat(profitArg)
 					if err != nil {
 						return errors.Wrap(err, "invalid profit amount")
 					}
+					targetProfit := fixedpoint.NewFromFloat(profit)
 
 					if targetProfit.Compare(fixedpoint.Zero) <= 0 {
 						return errors.New("profit amount must be greater than zero")
@@ -384,6 +612,10 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 
 	// open position
 	if cmd == "open_long_position" || cmd == "open_short_position" || cmd == "update_position" {
+		if ent.Grid() != nil {
+			return errors.New("a grid is running, stop it before opening a directional position")
+		}
+
 		side := ent.cmdToSide(cmd)
 
 		// Close opposite position if any
@@ -401,8 +633,14 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 			}
 		}
 
+		receipt := receiptFrom(ctx)
 		opts := make([]interface{}, 0)
 
+		positionSide := side
+		if positionSide == types.SideTypeSelf {
+			positionSide = ent.getPositionSide(ent.position)
+		}
+
 		// config stop losss
 		if stopLoss, ok := args["stop_loss_trigger_price"]; ok && stopLoss != "" {
 			stopLoss, err := utils.ParseStopLoss(ent.vm, side, closePrice, stopLoss)
@@ -411,8 +649,10 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 			}
 
 			if stopLoss != nil {
+				value := ent.adjustTrigger(receipt, "stop_loss_trigger_price", *stopLoss, closePrice, positionSide == types.SideTypeBuy)
+				receipt.StopLoss = value.Float64()
 				opts = append(opts, &StopLossPrice{
-					Value: *stopLoss,
+					Value: value,
 				})
 			}
 		}
@@ -425,8 +665,10 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 			}
 
 			if takeProfix != nil {
+				value := ent.adjustTrigger(receipt, "take_profit_trigger_price", *takeProfix, closePrice, positionSide == types.SideTypeSell)
+				receipt.TakeProfit = value.Float64()
 				opts = append(opts, &TakeProfitPrice{
-					Value: *takeProfix,
+					Value: value,
 				})
 			}
 		}
@@ -466,6 +708,42 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 			})
 		}
 
+		// size by the risk per trade
+		if riskArg, ok := args["risk_percent"]; ok && riskArg != "" && (cmd == "open_long_position" || cmd == "open_short_position") {
+			entryPrice := closePrice
+			for _, opt := range opts {
+				if limitPrice, ok := opt.(*LimitPriceOpt); ok {
+					entryPrice = limitPrice.Value
+				}
+			}
+
+			size, err := ent.riskSize(ctx, riskArg, entryPrice, receipt.StopLoss)
+			if err != nil {
+				return errors.Wrap(err, "risk sizing error")
+			}
+
+			receipt.RiskSize = size
+			opts = append(opts, &RiskSizeOpt{
+				Quantity: fixedpoint.NewFromFloat(size.Quantity),
+				Notional: fixedpoint.NewFromFloat(size.Notional),
+			})
+		}
+
+		// size by the share of the balance
+		if sizeArg, ok := args["position_size_percent"]; ok && sizeArg != "" && (cmd == "open_long_position" || cmd == "open_short_position") {
+			if receipt.RiskSize != nil {
+				return errors.New("position_size_percent can't be combined with risk_percent")
+			}
+
+			size, err := ent.positionSize(ctx, sizeArg, closePrice)
+			if err != nil {
+				return errors.Wrap(err, "position sizing error")
+			}
+
+			receipt.PositionSize = size
+			opts = append(opts, positionSizeOpt(size, side))
+		}
+
 		// Validation: order_type=limit requires limit_price
 		if ot, ok := args["order_type"]; ok && strings.ToUpper(ot) == "LIMIT" {
 			if lp, ok := args["limit_price"]; !ok || lp == "" {
@@ -499,9 +777,15 @@ func (ent *ExchangeEntity) HandleCommand(ctx context.Context, cmd string, args m
 func (ent *ExchangeEntity) Run(ctx context.Context, ch chan ttypes.IEvent) {
 	session := ent.session
 
+	ent.ch = ch
 	ent.Status = types.StrategyStatusRunning
 
 	ent.setupIndicators()
+	ent.setupFlatBy()
+	ent.setupPriceAlerts()
+	ent.setupIntents()
+	ent.applyRestored(ctx)
+	ent.setupPositionCheck(ctx, ch)
 
 	// if you need to do something when the user data stream is ready
 	// note that you only receive order update, trade update, balance update when the user data stream is connect.
@@ -509,25 +793,48 @@ func (ent *ExchangeEntity) Run(ctx context.Context, ch chan ttypes.IEvent) {
 		log.Infof("connected")
 	})
 
+	session.UserDataStream.OnOrderUpdate(func(order types.Order) {
+		defer ent.recoverCallback("order_update")
+
+		if order.Symbol == ent.symbol {
+			ent.handleGridOrderUpdate(ctx, ch, order)
+		}
+	})
+
 	log.
 		WithField("symbol", ent.symbol).
 		WithField("interval", ent.interval).
 		Info("exchange entity run")
 
+	if ent.priceAlerts != nil {
+		session.MarketDataStream.OnKLine(types.KLineWith(ent.symbol, ent.interval, func(kline types.KLine) {
+			defer ent.recoverCallback("price_alert")
+
+			ent.checkPriceAlerts(ctx, ch, kline)
+		}))
+	}
+
 	session.MarketDataStream.OnKLineClosed(types.KLineWith(ent.symbol, ent.interval, func(kline types.KLine) {
+		defer ent.recoverCallback("kline_closed")
+
 		// StrategyController
 		if ent.Status != types.StrategyStatusRunning {
 			log.Info("strategy status not running")
 			return
 		}
 
+		ctx, cancel := ent.withCycleTimeout(ctx)
+		defer cancel()
+
 		// Update Kline
 		if ent.KLineWindow != nil {
 			ent.KLineWindow.Add(kline)
 
-			if ent.KLineWindow.Len() > ent.cfg.KlineNum {
-				ent.KLineWindow.Truncate(ent.cfg.KlineNum)
+			if ent.KLineWindow.Len() > ent.cfg.RequiredKlineNum() {
+				ent.KLineWindow.Truncate(ent.cfg.RequiredKlineNum())
 			}
+
+			ent.updateVolatilityLeverage()
 		}
 
 		// Update position accumulated profit metrics
@@ -553,9 +860,15 @@ func (ent *ExchangeEntity) Run(ctx context.Context, ch chan ttypes.IEvent) {
 			}
 
 			ent.position.UpdateProfit(accumulatedProfit, profitValue)
+			ent.position.UpdateExcursion(kline.GetHigh(), kline.GetLow())
 			ent.position.Dust = ent.position.IsDust(kline.GetClose())
+
+			ent.updateProfitRatchet(ctx, ch, kline.GetClose())
 		}
 
+		ent.checkFlatBy(ctx, ch, kline.GetClose())
+		ent.checkGridBreak(ctx, kline.GetClose())
+
 		log.WithField("kline", kline).Info("kline closed")
 
 		// Auto cleanup unfilled limit orders before new decision cycle
@@ -567,13 +880,27 @@ func (ent *ExchangeEntity) Run(ctx context.Context, ch chan ttypes.IEvent) {
 			ent.emitEvent(ch, ttypes.NewEvent("indicator_changed", indicator))
 		}
 
+		ent.emitActivePriceAlerts(ch)
+		ent.checkIntents(ch, kline.Close.Float64())
+
+		if ent.KLineWindow != nil && ent.KLineWindow.Len() >= 2 {
+			window := *ent.KLineWindow
+			prevClose := window[len(window)-2].Close.Float64()
+			for _, alert := range EvaluateAlerts(ent.Indicators, prevClose, kline.Close.Float64()) {
+				log.WithField("alert", alert.Description).Info("indicator alert")
+				ent.emitEvent(ch, NewIndicatorAlertEvent(alert))
+			}
+		}
+
 		ent.emitEvent(ch, ttypes.NewEvent("position_changed", ent.position))
 
 		ent.emitEvent(ch, ttypes.NewEvent("update_finish", nil))
 	}))
 
 	// Handle position update
-	ent.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+	ent.venue.OnPositionUpdate(func(position *types.Position) {
+		defer ent.recoverCallback("position_update")
+
 		log.WithField("position", position).Info("ExchangeEntity_OnPositionUpdate")
 
 		if position.IsClosed() {
@@ -592,9 +919,14 @@ func (ent *ExchangeEntity) Run(ctx context.Context, ch chan ttypes.IEvent) {
 			positionData := PositionClosedEventData{
 				StrategyID:           position.StrategyInstanceID,
 				Symbol:               ent.symbol,
+				Side:                 ent.position.GetLastSide(),
 				EntryPrice:           position.AverageCost.Float64(),
 				ExitPrice:            exitPrice,
 				Quantity:             position.Base.Float64(),
+				StopLossPrice:        ent.position.GetStopLossPrice(),
+				HoldingPeriod:        ent.position.GetHoldingPeriod(),
+				MAEPercent:           ent.position.Excursion.MAEPercent,
+				MFEPercent:           ent.position.Excursion.MFEPercent,
 				ProfitAndLoss:        ent.position.AccumulatedProfitValue.Float64(),
 				ProfitAndLossPercent: ent.position.AccumulatedProfit.Float64(),
 				CloseReason:          CloseReasonManual, // Default to Manual (will be overridden by the context in ClosePosition if available)
@@ -647,7 +979,13 @@ func (ent *ExchangeEntity) Run(ctx context.Context, ch chan ttypes.IEvent) {
 		log.WithField("config", cleanPostionCfg).Info("clean position enabled")
 
 		session.MarketDataStream.OnKLineClosed(types.KLineWith(ent.symbol, cleanPostionCfg.Interval, func(kline types.KLine) {
+			defer ent.recoverCallback("clean_position")
+
 			log.WithField("kline", kline).Info("clean position triggered")
+
+			ctx, cancel := ent.withCycleTimeout(ctx)
+			defer cancel()
+
 			ent.handleCleanPosition(ctx, kline)
 		}))
 	}
@@ -659,34 +997,25 @@ func (ent *ExchangeEntity) handleCleanPosition(ctx context.Context, kline types.
 	if implemented {
 		log.Info("handleCleanPosition_start")
 
+		if ent.observeOnly.Load() {
+			log.Info("handleCleanPosition_skip_for_observe_only")
+			return
+		}
+
 		if ent.position.IsClosed() {
 			log.Info("handleCleanPosition_skip_for_no_postion")
 			return
 		}
 
-		var err error
-		var posInfo *types.PositionInfo
-
-		for i := 0; i < 3; i++ {
-			duration := time.Duration(rand.Intn(10000)) * time.Millisecond
-			log.WithField("duration", duration).Info("handleCleanPosition_delay")
-			time.Sleep(duration)
-
-			newCtx, cancel := context.WithTimeout(ctx, time.Second*20)
-			defer cancel()
-
-			posInfo, err = service.QueryPositionInfo(newCtx, kline.Symbol)
-			if err == nil {
-				break
-			}
-
-			log.WithField("kline", kline).
-				WithField("postion", ent.position).
-				WithField("queryPositionInfo", posInfo).
-				WithError(err).
-				Infof("handleCleanPosition_QueryPositionInfo_fail_retrying attempt %d", i+1)
+		// Spread the query of instances triggered by the same kline
+		duration := time.Duration(rand.Intn(10000)) * time.Millisecond
+		log.WithField("duration", duration).Info("handleCleanPosition_delay")
+		if err := utils.SleepContext(ctx, duration); err != nil {
+			log.WithError(err).Warn("handleCleanPosition_cancelled")
+			return
 		}
 
+		posInfo, err := ent.queryPositionInfo(ctx, service, kline.Symbol)
 		if err != nil {
 			log.WithField("kline", kline).
 				WithField("postion", ent.position).
@@ -800,7 +1129,9 @@ func (ent *ExchangeEntity) setupIndicators() {
 
 	for name, cfg := range ent.cfg.Indicators {
 		log.WithField("name", name).WithField("cfg", cfg).Info("setupIndicators")
-		ent.Indicators = append(ent.Indicators, NewExchangeIndicator(name, cfg, indicators))
+		indicator := NewExchangeIndicator(name, cfg, indicators)
+		ent.feedCustomIndicator(indicator)
+		ent.Indicators = append(ent.Indicators, indicator)
 	}
 
 	sort.Slice(ent.Indicators, func(i int, j int) bool {
@@ -815,15 +1146,23 @@ func (ent *ExchangeEntity) emitEvent(ch chan ttypes.IEvent, evt ttypes.IEvent) {
 // cleanupLimitOrders clears all unfilled limit orders
 // Called automatically at the start of each decision cycle to ensure AI starts with a clean state
 func (ent *ExchangeEntity) cleanupLimitOrders(ctx context.Context) {
-	orders, err := ent.session.Exchange.QueryOpenOrders(ctx, ent.symbol)
+	if ent.observeOnly.Load() {
+		return
+	}
+
+	orders, err := ent.venue.QueryOpenOrders(ctx, ent.symbol)
 	if err != nil {
 		log.WithError(err).Warn("query open orders for cleanup failed")
 		return
 	}
 
-	// Only cancel limit orders, keep stop-loss/take-profit orders
+	// Only cancel limit orders, keep stop-loss/take-profit and grid orders
 	limitOrders := make([]types.Order, 0)
 	for _, order := range orders {
+		if ent.isGridOrder(order.OrderID) {
+			continue
+		}
+
 		if order.Type == types.OrderTypeLimit || order.Type == types.OrderTypeLimitMaker {
 			limitOrders = append(limitOrders, order)
 		}
@@ -833,7 +1172,7 @@ func (ent *ExchangeEntity) cleanupLimitOrders(ctx context.Context) {
 		return // No limit orders to clean up
 	}
 
-	err = ent.session.Exchange.CancelOrders(ctx, limitOrders...)
+	err = ent.venue.CancelOrders(ctx, limitOrders...)
 	if err != nil {
 		log.WithError(err).
 			WithField("order_count", len(limitOrders)).
@@ -870,24 +1209,58 @@ type PostOnlyOpt struct {
 
 func (s *ExchangeEntity) OpenPosition(ctx context.Context, side types.SideType, closePrice fixedpoint.Value, args ...interface{}) error {
 	quantity := s.calculateQuantity(ctx, closePrice, side)
+	stopLoss := 0.0
+	receipt := receiptFrom(ctx)
+
+	for _, arg := range args {
+		if size, ok := arg.(*RiskSizeOpt); ok {
+			// market buys are sized in quote, like calculateQuantity
+			quantity = size.Quantity
+			if side == types.SideTypeBuy {
+				quantity = size.Notional
+			}
+		}
+	}
 
 	for {
+		// Sells are sized in base, which the exchange only accepts in multiples of the step size
+		if side == types.SideTypeSell && s.marketInfo != nil {
+			quantity = s.position.Market.TruncateQuantity(quantity)
+		}
+
+		if side == types.SideTypeSell {
+			capped, err := s.capCoreHolding(ctx, quantity)
+			if err != nil {
+				return err
+			}
+			quantity = capped
+		}
+
 		if quantity.Compare(s.position.Market.MinQuantity) < 0 {
 			return fmt.Errorf("%s order quantity %v is too small, less than %v", s.symbol, quantity, s.position.Market.MinQuantity)
 		}
 
+		if err := s.checkMinNotional(side, quantity, closePrice); err != nil {
+			return err
+		}
+
 		orderForm := s.generateOrderForm(side, quantity, types.SideEffectTypeMarginBuy)
+		orderForm.ClientOrderID = s.clientOrderID(ctx)
 
 		for _, arg := range args {
 			switch val := arg.(type) {
 			case *StopLossPrice:
 				orderForm.StopPrice = val.Value
+				stopLoss = val.Value.Float64()
 			case *TakeProfitPrice:
 				orderForm.TakePrice = val.Value
 			case *OrderTypeOpt:
 				orderForm.Type = val.Type
 			case *LimitPriceOpt:
 				orderForm.Price = val.Value
+				if s.marketInfo != nil {
+					orderForm.Price = s.position.Market.TruncatePrice(val.Value)
+				}
 			case *TimeInForceOpt:
 				orderForm.TimeInForce = val.Value
 			case *PostOnlyOpt:
@@ -898,10 +1271,14 @@ func (s *ExchangeEntity) OpenPosition(ctx context.Context, side types.SideType,
 			}
 		}
 
+		orderForm = s.limitQuantity(orderForm)
+
 		log.Infof("submit open position order %v", orderForm)
-		_, err := s.orderExecutor.SubmitOrders(ctx, orderForm)
+		createdOrders, err := s.submitOrder(ctx, orderForm, closePrice)
+		receipt.Orders = append(receipt.Orders, createdOrders...)
 		if err != nil {
-			if strings.Contains(err.Error(), "Insufficient USDT") {
+			// Retrying a partially executed order with the full quantity would oversize the position
+			if len(createdOrders) == 0 && strings.Contains(err.Error(), "Insufficient USDT") {
 				log.WithField("quantity", quantity.Float64()).Error("Insufficient USDT, try reduce order quantity")
 				quantity = quantity.Mul(fixedpoint.NewFromFloat(0.99))
 				continue
@@ -911,9 +1288,14 @@ func (s *ExchangeEntity) OpenPosition(ctx context.Context, side types.SideType,
 			return err
 		}
 
+		s.expireLimitEntry(ctx, orderForm, createdOrders)
 		break
 	}
 
+	// The new position starts a new ratchet, measured against its initial stop
+	s.entryStop = stopLoss
+	s.profitRatchet = nil
+
 	return nil
 }
 
@@ -933,6 +1315,16 @@ func (s *ExchangeEntity) ClosePosition(ctx context.Context, percentage fixedpoin
 	if s.position.IsLong() {
 		side = types.SideTypeSell
 
+		capped, err := s.capCoreHolding(ctx, quantity)
+		if err != nil {
+			return err
+		}
+		if capped.Compare(quantity) < 0 {
+			// The rest of the position is left open rather than selling into the core holding
+			quantity = capped
+			isFullClose = false
+		}
+
 		if quantity.Compare(s.position.Market.MinQuantity) < 0 {
 			return fmt.Errorf("%s order quantity %v is too small, less than %v", s.symbol, quantity, s.position.Market.MinQuantity)
 		}
@@ -941,13 +1333,16 @@ func (s *ExchangeEntity) ClosePosition(ctx context.Context, percentage fixedpoin
 	}
 
 	orderForm := s.generateOrderForm(side, quantity, types.SideEffectTypeAutoRepay)
+	orderForm.ClientOrderID = s.clientOrderID(ctx)
 	if isFullClose {
 		orderForm.ClosePosition = true // Full close position
 	}
 
 	bbgo.Notify("submitting %s %s order to close position by %v, orderForm:%v", s.symbol, side.String(), percentage, orderForm)
 
-	_, err := s.orderExecutor.SubmitOrders(ctx, orderForm)
+	createdOrders, err := s.submitOrder(ctx, orderForm, closePrice)
+	receipt := receiptFrom(ctx)
+	receipt.Orders = append(receipt.Orders, createdOrders...)
 	if err != nil {
 		log.WithError(err).Errorf("can not place %s position close order", s.symbol)
 		bbgo.Notify("can not place %s position close order", s.symbol)
@@ -975,9 +1370,14 @@ func (s *ExchangeEntity) ClosePosition(ctx context.Context, percentage fixedpoin
 		positionData := PositionClosedEventData{
 			StrategyID:           strategyID,
 			Symbol:               s.symbol,
+			Side:                 posBeforeClose.GetLastSide(),
 			EntryPrice:           posBeforeClose.AverageCost.Float64(),
 			ExitPrice:            closePrice.Float64(),
 			Quantity:             posBeforeClose.GetBase().Float64(),
+			StopLossPrice:        posBeforeClose.GetStopLossPrice(),
+			HoldingPeriod:        posBeforeClose.GetHoldingPeriod(),
+			MAEPercent:           posBeforeClose.Excursion.MAEPercent,
+			MFEPercent:           posBeforeClose.Excursion.MFEPercent,
 			ProfitAndLoss:        posBeforeClose.AccumulatedProfitValue.Float64(),
 			ProfitAndLossPercent: posBeforeClose.AccumulatedProfit.Float64(),
 			CloseReason:          closeReason,
@@ -1096,9 +1496,128 @@ func (s *ExchangeEntity) generateOrderForm(side types.SideType, quantity fixedpo
 	return orderForm
 }
 
+// clientOrderID suffixes a sequence number with the decision cycle ID, so orders can be traced back to their decision.
+// Outside a decision cycle it is empty and the exchange generates one.
+func (s *ExchangeEntity) clientOrderID(ctx context.Context) string {
+	cycleID := strings.ReplaceAll(ttypes.CycleIDFromContext(ctx), "-", "")
+	if cycleID == "" {
+		return ""
+	}
+
+	// Exchanges like OKX accept up to 32 alphanumeric characters
+	if len(cycleID) > 24 {
+		cycleID = cycleID[:24]
+	}
+
+	s.orderSeq++
+	return fmt.Sprintf("tg%04d%s", s.orderSeq%10000, cycleID)
+}
+
+// MarketPrice returns the last traded price of the ticker
+func (s *ExchangeEntity) MarketPrice(ctx context.Context) (fixedpoint.Value, error) {
+	ticker, err := s.venue.QueryTicker(ctx, s.symbol)
+	if err != nil {
+		return fixedpoint.Zero, errors.Wrap(err, "query ticker error")
+	}
+
+	return ticker.Last, nil
+}
+
+// SimulateCommand computes the position an open position command would create, without placing orders
+func (s *ExchangeEntity) SimulateCommand(ctx context.Context, cmd string, args map[string]string, maintenanceMarginRate float64) (*utils.TradeSimulation, error) {
+	if s.KLineWindow == nil {
+		return nil, errors.New("current kline nil")
+	}
+
+	side := s.cmdToSide(cmd)
+	if side == types.SideTypeSelf {
+		return nil, errors.Errorf("not an open position command: %s", cmd)
+	}
+
+	closePrice := s.KLineWindow.GetClose()
+	entryPrice := closePrice
+	if limitPrice, ok := args["limit_price"]; ok && limitPrice != "" {
+		price, err := utils.ParsePrice(s.vm, s.KLineWindow, closePrice, limitPrice)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid limit_price: %s", limitPrice)
+		}
+
+		if price != nil {
+			entryPrice = *price
+		}
+	}
+
+	leverage := s.entryLeverage()
+	quoteQty, err := s.quoteQuantity(ctx, leverage)
+	if err != nil {
+		return nil, errors.Wrap(err, "calculate quote quantity error")
+	}
+
+	params := utils.TradeSimulationParams{
+		Side:                  PositionSideLong,
+		EntryPrice:            entryPrice.Float64(),
+		Quantity:              quoteQty.Div(entryPrice).Float64(),
+		Leverage:              leverage.Float64(),
+		Equity:                quoteQty.Div(leverage).Float64(),
+		MaintenanceMarginRate: maintenanceMarginRate,
+	}
+
+	if side == types.SideTypeSell {
+		// mirrors calculateQuantity, which keeps a 1% buffer on shorts
+		params.Side = PositionSideShort
+		params.Quantity = params.Quantity * 0.99
+	}
+
+	if stopLoss, ok := args["stop_loss_trigger_price"]; ok && stopLoss != "" {
+		price, err := utils.ParseStopLoss(s.vm, side, entryPrice, stopLoss)
+		if err != nil {
+			return nil, errors.Wrapf(err, "the stop loss invalid: %s", stopLoss)
+		}
+
+		if price != nil {
+			params.StopLoss = s.snapTrigger(*price, entryPrice, side == types.SideTypeBuy).Float64()
+		}
+	}
+
+	if takeProfit, ok := args["take_profit_trigger_price"]; ok && takeProfit != "" {
+		price, err := utils.ParseTakeProfit(s.vm, side, entryPrice, takeProfit)
+		if err != nil {
+			return nil, errors.Wrapf(err, "the take profit invalid: %s", takeProfit)
+		}
+
+		if price != nil {
+			params.TakeProfit = s.snapTrigger(*price, entryPrice, side == types.SideTypeSell).Float64()
+		}
+	}
+
+	if riskArg, ok := args["risk_percent"]; ok && riskArg != "" {
+		size, err := s.riskSize(ctx, riskArg, entryPrice, params.StopLoss)
+		if err != nil {
+			return nil, errors.Wrap(err, "risk sizing error")
+		}
+
+		params.Quantity = size.Quantity
+	}
+
+	if sizeArg, ok := args["position_size_percent"]; ok && sizeArg != "" {
+		if args["risk_percent"] != "" {
+			return nil, errors.New("position_size_percent can't be combined with risk_percent")
+		}
+
+		size, err := s.positionSize(ctx, sizeArg, entryPrice)
+		if err != nil {
+			return nil, errors.Wrap(err, "position sizing error")
+		}
+
+		params.Quantity = positionSizeOpt(size, side).Quantity.Float64()
+	}
+
+	return utils.SimulateTrade(params), nil
+}
+
 // calculateQuantity returns leveraged quantity
 func (s *ExchangeEntity) calculateQuantity(ctx context.Context, currentPrice fixedpoint.Value, side types.SideType) fixedpoint.Value {
-	quoteQty, err := bbgo.CalculateQuoteQuantity(ctx, s.session, s.position.Market.QuoteCurrency, s.leverage)
+	quoteQty, err := s.quoteQuantity(ctx, s.entryLeverage())
 	if err != nil {
 		log.WithError(err).Errorf("can not update %s quote balance from exchange", s.symbol)
 		return fixedpoint.Zero
@@ -1111,3 +1630,77 @@ func (s *ExchangeEntity) calculateQuantity(ctx context.Context, currentPrice fix
 		return quoteQty
 	}
 }
+
+// updateVolatilityLeverage recomputes the leverage permitted for new entries from the kline window
+func (s *ExchangeEntity) updateVolatilityLeverage() {
+	cfg := s.cfg.LeverageScaling
+	if !cfg.Enabled {
+		return
+	}
+
+	period := cfg.ATRPeriod
+	if period <= 0 {
+		period = 14
+	}
+
+	maxLeverage := cfg.MaxLeverage
+	if maxLeverage <= 0 {
+		maxLeverage = s.leverage.Float64()
+	}
+
+	s.volatilityLeverage = utils.ComputeVolatilityLeverage(*s.KLineWindow, period, cfg.MinLeverage, maxLeverage)
+}
+
+// VolatilityLeverage returns the leverage permitted for new entries, nil without leverage scaling
+func (s *ExchangeEntity) VolatilityLeverage() *utils.VolatilityLeverage {
+	return s.volatilityLeverage
+}
+
+// entryLeverage returns the leverage new entries are sized with
+func (s *ExchangeEntity) entryLeverage() fixedpoint.Value {
+	if s.volatilityLeverage != nil {
+		return fixedpoint.NewFromFloat(s.volatilityLeverage.Leverage)
+	}
+
+	return s.leverage
+}
+
+// snapTrigger returns the stop-loss or take-profit trigger price snapped to the tick size and kept the
+// minimum distance from the price, below or above it
+func (s *ExchangeEntity) snapTrigger(trigger fixedpoint.Value, price fixedpoint.Value, below bool) fixedpoint.Value {
+	value, _ := s.snapTriggerWithNote(trigger, price, below)
+	return value
+}
+
+func (s *ExchangeEntity) snapTriggerWithNote(trigger fixedpoint.Value, price fixedpoint.Value, below bool) (fixedpoint.Value, string) {
+	cfg := s.cfg.TriggerPrice
+
+	tickSize := 0.0
+	if s.position != nil && s.position.Position != nil {
+		tickSize = s.position.Market.TickSize.Float64()
+	}
+
+	ticks := cfg.MinDistanceTicks
+	if ticks <= 0 {
+		ticks = 1
+	}
+	minDistance := math.Max(float64(ticks)*tickSize, price.Float64()*cfg.MinDistancePercent/100)
+
+	adjusted, note := utils.AdjustTriggerPrice(trigger.Float64(), price.Float64(), tickSize, minDistance, below)
+	if note == "" {
+		return trigger, ""
+	}
+
+	return fixedpoint.NewFromFloat(adjusted), note
+}
+
+// adjustTrigger snaps a trigger price of the command being executed, recording the adjustment in its receipt
+func (s *ExchangeEntity) adjustTrigger(receipt *commandReceipt, name string, trigger fixedpoint.Value, price fixedpoint.Value, below bool) fixedpoint.Value {
+	value, note := s.snapTriggerWithNote(trigger, price, below)
+	if note != "" {
+		log.WithField("arg", name).WithField("adjustment", note).Info("trigger price adjusted")
+		receipt.Adjustments = append(receipt.Adjustments, fmt.Sprintf("%s %s", name, note))
+	}
+
+	return value
+}
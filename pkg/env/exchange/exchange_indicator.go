@@ -9,6 +9,7 @@ import (
 	"github.com/c9s/bbgo/pkg/types"
 
 	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/indicators"
 	"github.com/yubing744/trading-gpt/pkg/utils"
 )
 
@@ -25,7 +26,7 @@ type ExchangeIndicator struct {
 	Data   interface{}
 }
 
-func NewExchangeIndicator(name string, cfg *config.IndicatorConfig, indicators *bbgo.StandardIndicatorSet) *ExchangeIndicator {
+func NewExchangeIndicator(name string, cfg *config.IndicatorConfig, indicatorSet *bbgo.StandardIndicatorSet) *ExchangeIndicator {
 	indicator := &ExchangeIndicator{
 		Name:   name,
 		Type:   cfg.Type,
@@ -34,52 +35,56 @@ func NewExchangeIndicator(name string, cfg *config.IndicatorConfig, indicators *
 
 	switch cfg.Type {
 	case config.IndicatorTypeSMA:
-		indicator.Data = indicators.SMA(types.IntervalWindow{
+		indicator.Data = indicatorSet.SMA(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 5),
 		})
 	case config.IndicatorTypeVR:
-		indicator.Data = indicators.VR(types.IntervalWindow{
+		indicator.Data = indicatorSet.VR(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 5),
 		})
 	case config.IndicatorTypeEWMA:
-		indicator.Data = indicators.EWMA(types.IntervalWindow{
+		indicator.Data = indicatorSet.EWMA(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 5),
 		})
 	case config.IndicatorTypeVWMA:
-		indicator.Data = indicators.VWMA(types.IntervalWindow{
+		indicator.Data = indicatorSet.VWMA(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 5),
 		})
 	case config.IndicatorTypeEMV:
-		indicator.Data = indicators.EMV(types.IntervalWindow{
+		indicator.Data = indicatorSet.EMV(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 5),
 		})
 	case config.IndicatorTypeBOLL:
-		indicator.Data = indicators.BOLL(types.IntervalWindow{
+		indicator.Data = indicatorSet.BOLL(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 20),
 		}, cfg.GetFloat("band_width", 2.0))
 	case config.IndicatorTypeRSI:
-		indicator.Data = indicators.RSI(types.IntervalWindow{
+		indicator.Data = indicatorSet.RSI(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 20),
 		})
 	case config.IndicatorTypeATR:
-		indicator.Data = indicators.ATR(types.IntervalWindow{
+		indicator.Data = indicatorSet.ATR(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 20),
 		})
 	case config.IndicatorTypeATRP:
-		indicator.Data = indicators.ATRP(types.IntervalWindow{
+		indicator.Data = indicatorSet.ATRP(types.IntervalWindow{
 			Interval: cfg.GetInterval("interval", "5m"),
 			Window:   cfg.GetInt("window_size", 20),
 		})
 	default:
-		log.Panic("not support type" + cfg.Type)
+		custom, ok := newCustomIndicator(cfg)
+		if !ok {
+			log.Panic("not support type" + cfg.Type)
+		}
+		indicator.Data = custom
 	}
 
 	return indicator
@@ -90,6 +95,10 @@ func (ei *ExchangeIndicator) ToPrompts(maxNum int) []string {
 		maxNum = *ei.Config.MaxNum
 	}
 
+	if custom, ok := ei.Data.(indicators.Indicator); ok {
+		return ei.CustomToPrompts(ei.Name, custom)
+	}
+
 	switch ei.Type {
 	case config.IndicatorTypeBOLL:
 		return ei.BOLLToPrompts(ei.Name, ei.Type, ei.Data.(*indicator.BOLL), maxNum)
@@ -105,6 +114,54 @@ func (ei *ExchangeIndicator) ToPrompts(maxNum int) []string {
 	return []string{}
 }
 
+// TemplateData returns the indicator values exposed to user-defined prompt formatter templates
+func (ei *ExchangeIndicator) TemplateData(maxNum int) map[string]interface{} {
+	if ei.Config.MaxNum != nil {
+		maxNum = *ei.Config.MaxNum
+	}
+
+	data := map[string]interface{}{
+		"Name": ei.Name,
+		"Type": string(ei.Type),
+	}
+
+	if custom, ok := ei.Data.(indicators.Indicator); ok {
+		data["Summary"] = custom.PromptSummary()
+	}
+
+	tail := func(vals []float64) []float64 {
+		if len(vals) > maxNum {
+			return vals[len(vals)-maxNum:]
+		}
+		return vals
+	}
+
+	switch d := ei.Data.(type) {
+	case *indicator.BOLL:
+		data["Window"] = d.SMA.Window
+		data["UpBand"] = tail(d.UpBand)
+		data["SMA"] = tail(d.SMA.Values)
+		data["DownBand"] = tail(d.DownBand)
+		data["Up"] = d.UpBand.Last(0)
+		data["Mid"] = d.SMA.Last(0)
+		data["Down"] = d.DownBand.Last(0)
+	case IBasicIndicator:
+		// Index walks back from the latest value, so keep the latest maxNum values in chronological order
+		vals := basicIndicatorToValues(d)
+		if len(vals) > maxNum {
+			vals = vals[:maxNum]
+		}
+		for i, j := 0, len(vals)-1; i < j; i, j = i+1, j-1 {
+			vals[i], vals[j] = vals[j], vals[i]
+		}
+
+		data["Values"] = vals
+		data["Last"] = d.Last(0)
+	}
+
+	return data
+}
+
 func (indicator *ExchangeIndicator) BOLLToPrompts(name string, indicatorType config.IndicatorType, boll *indicator.BOLL, maxNum int) []string {
 	log.
 		WithField("name", name).
@@ -139,15 +196,18 @@ func (indicator *ExchangeIndicator) BOLLToPrompts(name string, indicatorType con
 
 	sb.WriteString("Time   UpBand   SMA   DownBand\n")
 	for i := 0; i < len(upVals); i++ {
-		sb.WriteString(fmt.Sprintf("%d      %.3f  %.3f    %.3f\n", i, upVals[i], midVals[i], downVals[i]))
+		sb.WriteString(fmt.Sprintf("%d      %s  %s    %s\n", i,
+			utils.FormatNumber(upVals[i], indicator.Config.Format),
+			utils.FormatNumber(midVals[i], indicator.Config.Format),
+			utils.FormatNumber(downVals[i], indicator.Config.Format)))
 	}
 
 	sb.WriteString("\n")
 
-	sb.WriteString(fmt.Sprintf("The current UpBand is %.3f, and the current SMA is %.3f, and the current DownBand is %.3f",
-		boll.UpBand.Last(0),
-		boll.SMA.Last(0),
-		boll.DownBand.Last(0),
+	sb.WriteString(fmt.Sprintf("The current UpBand is %s, and the current SMA is %s, and the current DownBand is %s",
+		utils.FormatNumber(boll.UpBand.Last(0), indicator.Config.Format),
+		utils.FormatNumber(boll.SMA.Last(0), indicator.Config.Format),
+		utils.FormatNumber(boll.DownBand.Last(0), indicator.Config.Format),
 	))
 
 	return []string{sb.String()}
@@ -173,11 +233,11 @@ func (indicator *ExchangeIndicator) BasicToPrompts(name string, indicatorType co
 	msgs := make([]string, 0)
 
 	if len(vals) > 0 {
-		msgs = append(msgs, fmt.Sprintf("%s data changed: [%s], and the most recent %s value is: %.3f at index %d",
+		msgs = append(msgs, fmt.Sprintf("%s data changed: [%s], and the most recent %s value is: %s at index %d",
 			name,
-			utils.JoinFloatSlice(vals, " "),
+			utils.JoinNumbers(vals, " ", indicator.Config.Format),
 			name,
-			basicIndicator.Last(0),
+			utils.FormatNumber(basicIndicator.Last(0), indicator.Config.Format),
 			len(vals)-1,
 		))
 	}
@@ -185,6 +245,16 @@ func (indicator *ExchangeIndicator) BasicToPrompts(name string, indicatorType co
 	return msgs
 }
 
+// CustomToPrompts describes a custom Go indicator by its prompt summary
+func (indicator *ExchangeIndicator) CustomToPrompts(name string, custom indicators.Indicator) []string {
+	summary := custom.PromptSummary()
+	if summary == "" {
+		return []string{}
+	}
+
+	return []string{fmt.Sprintf("%s data changed: %s", name, summary)}
+}
+
 func basicIndicatorToValues(basicIndicator IBasicIndicator) []float64 {
 	vals := make([]float64, 0)
 
@@ -0,0 +1,212 @@
+package exchange
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+const (
+	ExecutionAlgoMarket = "market"
+	ExecutionAlgoTWAP   = "twap"
+	ExecutionAlgoPeg    = "peg"
+)
+
+// submitOrder submits a market order at once, or works it with the configured execution algo when it is large.
+// Buy market order quantities are in quote currency, all others in base currency.
+func (s *ExchangeEntity) submitOrder(ctx context.Context, orderForm types.SubmitOrder, price fixedpoint.Value) (types.OrderSlice, error) {
+	cfg := s.cfg.Execution
+
+	notional := orderForm.Quantity
+	if orderForm.Side == types.SideTypeSell {
+		notional = orderForm.Quantity.Mul(price)
+	}
+
+	if orderForm.Type != types.OrderTypeMarket || cfg.Algo == "" || cfg.Algo == ExecutionAlgoMarket || notional.Float64() < cfg.MinNotional {
+		return s.submitOrders(ctx, orderForm)
+	}
+
+	slices := cfg.Slices
+	if slices <= 0 {
+		slices = 5
+	}
+
+	duration := cfg.Duration.Duration()
+	if duration <= 0 {
+		duration = time.Minute
+	}
+
+	minQuantity := s.position.Market.MinQuantity
+	if orderForm.Side == types.SideTypeBuy {
+		minQuantity = s.position.Market.MinNotional
+	}
+
+	quantities := splitQuantity(orderForm.Quantity, minQuantity, slices)
+	interval := duration / time.Duration(len(quantities))
+
+	log.WithField("algo", cfg.Algo).
+		WithField("slices", len(quantities)).
+		WithField("interval", interval).
+		Infof("work %s %s order of %v", s.symbol, orderForm.Side, orderForm.Quantity)
+
+	switch cfg.Algo {
+	case ExecutionAlgoTWAP:
+		return s.executeTWAP(ctx, orderForm, quantities, interval)
+	case ExecutionAlgoPeg:
+		return s.executePeg(ctx, orderForm, quantities, interval)
+	default:
+		return nil, errors.Errorf("unsupported execution algo: %s", cfg.Algo)
+	}
+}
+
+// executeTWAP submits the slices as market orders evenly spread over time, aborting the remainder on failure
+func (s *ExchangeEntity) executeTWAP(ctx context.Context, orderForm types.SubmitOrder, quantities []fixedpoint.Value, interval time.Duration) (types.OrderSlice, error) {
+	createdOrders := make(types.OrderSlice, 0, len(quantities))
+
+	for i, quantity := range quantities {
+		if i > 0 {
+			if err := utils.SleepContext(ctx, interval); err != nil {
+				return createdOrders, errors.Wrapf(err, "twap aborted after %d of %d slices", i, len(quantities))
+			}
+		}
+
+		child := childOrderForm(orderForm, quantity, i == len(quantities)-1)
+		child.ClientOrderID = s.clientOrderID(ctx)
+
+		orders, err := s.submitOrders(ctx, child)
+		if err != nil {
+			return createdOrders, errors.Wrapf(err, "twap slice %d of %d failed", i+1, len(quantities))
+		}
+
+		createdOrders = append(createdOrders, orders...)
+	}
+
+	return createdOrders, nil
+}
+
+// executePeg works the slices as limit orders at the top of the book, re-pegging the unfilled part
+// of each slice into the next one, and sweeps what is left with a market order at the end
+func (s *ExchangeEntity) executePeg(ctx context.Context, orderForm types.SubmitOrder, quantities []fixedpoint.Value, interval time.Duration) (types.OrderSlice, error) {
+	queryService, ok := s.orderQueryService()
+	if !ok {
+		log.Warn("venue can not query orders, fallback to twap execution")
+		return s.executeTWAP(ctx, orderForm, quantities, interval)
+	}
+
+	createdOrders := make(types.OrderSlice, 0, len(quantities)+1)
+	remaining := fixedpoint.Zero
+
+	for i, quantity := range quantities {
+		remaining = remaining.Add(quantity)
+
+		ticker, err := s.venue.QueryTicker(ctx, s.symbol)
+		if err != nil {
+			return createdOrders, errors.Wrap(err, "query ticker error")
+		}
+
+		// Join the best bid when buying and the best ask when selling
+		price := ticker.Buy
+		baseQuantity := remaining
+		if orderForm.Side == types.SideTypeBuy {
+			baseQuantity = remaining.Div(price)
+		} else {
+			price = ticker.Sell
+		}
+
+		child := childOrderForm(orderForm, baseQuantity, false)
+		child.Type = types.OrderTypeLimit
+		child.Price = price
+		child.TimeInForce = types.TimeInForceGTC
+		child.ClientOrderID = s.clientOrderID(ctx)
+
+		orders, err := s.submitOrders(ctx, child)
+		if err != nil {
+			return createdOrders, errors.Wrapf(err, "peg slice %d of %d failed", i+1, len(quantities))
+		}
+		createdOrders = append(createdOrders, orders...)
+
+		if err := utils.SleepContext(ctx, interval); err != nil {
+			return createdOrders, errors.Wrapf(err, "peg aborted after %d of %d slices", i+1, len(quantities))
+		}
+
+		if err := s.venue.CancelOrders(ctx, orders...); err != nil {
+			log.WithError(err).Warn("cancel pegged order error, it may be filled already")
+		}
+
+		for _, order := range orders {
+			filled, err := queryService.QueryOrder(ctx, types.OrderQuery{
+				Symbol:  s.symbol,
+				OrderID: strconv.FormatUint(order.OrderID, 10),
+			})
+			if err != nil {
+				return createdOrders, errors.Wrapf(err, "query pegged order %d error", order.OrderID)
+			}
+
+			executed := filled.ExecutedQuantity
+			if orderForm.Side == types.SideTypeBuy {
+				executed = executed.Mul(price)
+			}
+			remaining = remaining.Sub(executed)
+		}
+	}
+
+	if remaining.Sign() <= 0 {
+		return createdOrders, nil
+	}
+
+	log.WithField("remaining", remaining).Info("sweep the unfilled remainder with a market order")
+
+	child := childOrderForm(orderForm, remaining, true)
+	child.ClientOrderID = s.clientOrderID(ctx)
+
+	orders, err := s.submitOrders(ctx, child)
+	if err != nil {
+		return createdOrders, errors.Wrap(err, "sweep remainder failed")
+	}
+
+	return append(createdOrders, orders...), nil
+}
+
+// childOrderForm copies the parent order with a slice quantity, only the last slice may close the whole position
+func childOrderForm(orderForm types.SubmitOrder, quantity fixedpoint.Value, last bool) types.SubmitOrder {
+	child := orderForm
+	child.Quantity = quantity
+	child.ClosePosition = orderForm.ClosePosition && last
+	return child
+}
+
+// splitQuantity splits the quantity into equal slices, using fewer slices when a slice would fall below the minimum
+func splitQuantity(quantity, minQuantity fixedpoint.Value, slices int) []fixedpoint.Value {
+	if slices < 1 {
+		slices = 1
+	}
+
+	if minQuantity.Sign() > 0 {
+		maxSlices := int(quantity.Div(minQuantity).Float64())
+		if maxSlices < slices {
+			slices = maxSlices
+		}
+	}
+
+	if slices <= 1 {
+		return []fixedpoint.Value{quantity}
+	}
+
+	slice := quantity.Div(fixedpoint.NewFromInt(int64(slices)))
+	quantities := make([]fixedpoint.Value, slices)
+	rest := quantity
+
+	for i := 0; i < slices-1; i++ {
+		quantities[i] = slice
+		rest = rest.Sub(slice)
+	}
+	quantities[slices-1] = rest
+
+	return quantities
+}
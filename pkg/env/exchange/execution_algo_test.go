@@ -0,0 +1,24 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitQuantity(t *testing.T) {
+	quantities := splitQuantity(fixedpoint.NewFromFloat(10), fixedpoint.NewFromFloat(1), 3)
+	assert.Len(t, quantities, 3)
+
+	total := fixedpoint.Zero
+	for _, q := range quantities {
+		total = total.Add(q)
+	}
+	assert.Equal(t, 10.0, total.Float64())
+
+	// Slices below the minimum are merged into fewer slices
+	assert.Len(t, splitQuantity(fixedpoint.NewFromFloat(2.5), fixedpoint.NewFromFloat(1), 5), 2)
+	assert.Len(t, splitQuantity(fixedpoint.NewFromFloat(0.5), fixedpoint.NewFromFloat(1), 5), 1)
+	assert.Len(t, splitQuantity(fixedpoint.NewFromFloat(10), fixedpoint.Zero, 4), 4)
+}
@@ -0,0 +1,87 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EventFlatByWarning is emitted on every kline within the warning period before the flat-by deadline
+const EventFlatByWarning = "flat_by_warning"
+
+// FlatByEvent warns the agent that the position will be force-closed at the flat-by deadline
+type FlatByEvent struct {
+	ttypes.Event
+	Deadline time.Time
+	Schedule string
+}
+
+func NewFlatByEvent(deadline time.Time, schedule string) *FlatByEvent {
+	return &FlatByEvent{
+		Event:    *ttypes.NewEvent(EventFlatByWarning, deadline),
+		Deadline: deadline,
+		Schedule: schedule,
+	}
+}
+
+func (e *FlatByEvent) ToPrompts() []string {
+	remaining := time.Until(e.Deadline).Round(time.Minute)
+
+	return []string{fmt.Sprintf("Scheduled flat-by deadline (%s) at %s, in %s: any open position is force-closed at the deadline. Plan exits before it and avoid opening new positions.",
+		e.Schedule, e.Deadline.Format(time.RFC3339), remaining)}
+}
+
+// setupFlatBy parses the flat-by schedule, leaving it disabled when the config is invalid
+func (ent *ExchangeEntity) setupFlatBy() {
+	cfg := &ent.cfg.FlatBy
+	if !cfg.Enabled {
+		return
+	}
+
+	if cfg.WarnBefore == 0 {
+		cfg.WarnBefore = types.Duration(time.Hour)
+	}
+
+	schedule, err := utils.ParseFlatSchedule(cfg.Time, cfg.Weekday, cfg.Timezone)
+	if err != nil {
+		log.WithError(err).Error("flat-by schedule invalid, disabled")
+		return
+	}
+
+	ent.flatBy = schedule
+	ent.flatByCheckedAt = time.Now()
+
+	log.WithField("schedule", schedule.String()).Info("flat-by schedule enabled")
+}
+
+// checkFlatBy force-closes the position once the flat-by deadline passed, and warns the agent ahead of it
+func (ent *ExchangeEntity) checkFlatBy(ctx context.Context, ch chan ttypes.IEvent, closePrice fixedpoint.Value) {
+	if ent.flatBy == nil {
+		return
+	}
+
+	now := time.Now()
+	crossed := ent.flatBy.Crossed(ent.flatByCheckedAt, now)
+	ent.flatByCheckedAt = now
+
+	if crossed && ent.position != nil && !ent.position.IsClosed() && !ent.position.Dust && !ent.observeOnly.Load() {
+		log.WithField("schedule", ent.flatBy.String()).Warn("flat-by deadline passed, force closing position")
+
+		closeCtx := context.WithValue(ctx, "closeReason", CloseReasonSchedule)
+		if err := ent.ClosePosition(closeCtx, fixedpoint.One, closePrice); err != nil {
+			log.WithError(err).Error("flat-by close position failed")
+		}
+		return
+	}
+
+	deadline := ent.flatBy.Next(now)
+	if deadline.Sub(now) <= ent.cfg.FlatBy.WarnBefore.Duration() {
+		ent.emitEvent(ch, NewFlatByEvent(deadline, ent.flatBy.String()))
+	}
+}
@@ -0,0 +1,96 @@
+package exchange
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// Regenerate expected.json from the current parser with: go test ./pkg/env/exchange/ -run TestGolden -update
+var updateGolden = flag.Bool("update", false, "update golden expected.json files")
+
+type goldenExpected struct {
+	Actions []*ttypes.Action `json:"actions"`
+}
+
+// TestGoldenResponses parses recorded LLM responses (testdata/golden/<case>/response.txt, with the prompt that
+// produced them in prompt.txt) and checks the actions against expected.json and the exchange action schemas
+func TestGoldenResponses(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "golden", "*"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, dirs)
+
+	descs := (&ExchangeEntity{}).Actions()
+
+	for _, dir := range dirs {
+		dir := dir
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			response, err := os.ReadFile(filepath.Join(dir, "response.txt"))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			_, _, text := utils.ExtractThinkingFull(strings.TrimSpace(string(response)))
+			result, err := utils.ParseResult(text)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			actions := result.AllActions()
+			for _, action := range actions {
+				assert.NoError(t, validateGoldenAction(descs, action))
+			}
+
+			expectedPath := filepath.Join(dir, "expected.json")
+			if *updateGolden {
+				data, err := json.MarshalIndent(&goldenExpected{Actions: actions}, "", "  ")
+				if assert.NoError(t, err) {
+					assert.NoError(t, os.WriteFile(expectedPath, append(data, '\n'), 0644))
+				}
+				return
+			}
+
+			data, err := os.ReadFile(expectedPath)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			expected := &goldenExpected{}
+			if !assert.NoError(t, json.Unmarshal(data, expected)) {
+				return
+			}
+			assert.Equal(t, expected.Actions, actions)
+		})
+	}
+}
+
+// validateGoldenAction checks the action name and argument names against the action descriptions
+func validateGoldenAction(descs []*ttypes.ActionDesc, action *ttypes.Action) error {
+	name := strings.TrimPrefix(action.Name, "exchange.")
+
+	for _, desc := range descs {
+		if desc.Name != name {
+			continue
+		}
+
+		argNames := desc.ArgNames()
+		for arg := range action.Args {
+			if !utils.Contains(argNames, arg) {
+				return fmt.Errorf("unknown arg %s of %s", arg, action.Name)
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown action %s", action.Name)
+}
@@ -0,0 +1,330 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+const (
+	// EventGridFilled is emitted when a grid order fills and the opposite order is placed
+	EventGridFilled = "grid_filled"
+	// EventGridStopped is emitted when the grid is stopped by the agent or by a range break
+	EventGridStopped = "grid_stopped"
+)
+
+// GridEvent reports a fill or the stop of the grid
+type GridEvent struct {
+	ttypes.Event
+	Grid   utils.Grid
+	Detail string // The fill, or why the grid stopped
+}
+
+func NewGridEvent(ty string, grid utils.Grid, detail string) *GridEvent {
+	return &GridEvent{
+		Event:  *ttypes.NewEvent(ty, grid),
+		Grid:   grid,
+		Detail: detail,
+	}
+}
+
+func (e *GridEvent) ToPrompts() []string {
+	if e.GetType() == EventGridStopped {
+		return []string{fmt.Sprintf("Grid stopped: %s. Final grid: %s.", e.Detail, e.Grid.String())}
+	}
+
+	return []string{fmt.Sprintf("Grid order filled: %s. Grid: %s.", e.Detail, e.Grid.String())}
+}
+
+// gridConfig returns the grid limits with their defaults
+func (ent *ExchangeEntity) gridConfig() config.GridConfig {
+	cfg := ent.cfg.Grid
+	if cfg.MaxLevels <= 0 {
+		cfg.MaxLevels = 20
+	}
+	if cfg.BreakPercent <= 0 {
+		cfg.BreakPercent = 1
+	}
+
+	return cfg
+}
+
+func (ent *ExchangeEntity) gridActions() []*ttypes.ActionDesc {
+	return []*ttypes.ActionDesc{
+		{
+			Name:        "start_grid",
+			Description: "Start a grid in a ranging market: limit buys rest at the levels below the price and sells above, each fill is replaced by the opposite order one level away. The grid stops, and no directional position can be opened, until stop_grid or a break of the range",
+			Args: []ttypes.ArgmentDesc{
+				{
+					Name:        "lower_price",
+					Description: "Lowest grid level",
+				},
+				{
+					Name:        "upper_price",
+					Description: "Highest grid level",
+				},
+				{
+					Name:        "levels",
+					Description: fmt.Sprintf("Number of price levels, evenly spaced over the range, at most %d", ent.gridConfig().MaxLevels),
+				},
+				{
+					Name:        "quantity",
+					Description: "Order quantity per level in base currency",
+				},
+			},
+		},
+		{
+			Name:        "stop_grid",
+			Description: "Stop the grid and cancel its resting orders, the position it built is kept",
+		},
+	}
+}
+
+// Grid returns a copy of the active grid, nil without one
+func (ent *ExchangeEntity) Grid() *utils.Grid {
+	ent.gridMu.Lock()
+	defer ent.gridMu.Unlock()
+
+	if ent.grid == nil {
+		return nil
+	}
+
+	grid := *ent.grid
+	grid.Levels = append([]utils.GridLevel{}, ent.grid.Levels...)
+	return &grid
+}
+
+func (ent *ExchangeEntity) startGrid(ctx context.Context, args map[string]string, closePrice fixedpoint.Value) error {
+	cfg := ent.gridConfig()
+
+	ent.gridMu.Lock()
+	defer ent.gridMu.Unlock()
+
+	if ent.grid != nil {
+		return errors.New("a grid is already running, stop it first")
+	}
+
+	if !ent.position.IsDust(closePrice) && !ent.position.IsClosed() {
+		return errors.New("close the open position before starting a grid")
+	}
+
+	lower, err := utils.ParseNumberArgFloat(args["lower_price"])
+	if err != nil {
+		return errors.Wrapf(err, "invalid lower_price: %s", args["lower_price"])
+	}
+
+	upper, err := utils.ParseNumberArgFloat(args["upper_price"])
+	if err != nil {
+		return errors.Wrapf(err, "invalid upper_price: %s", args["upper_price"])
+	}
+
+	levels, err := strconv.Atoi(args["levels"])
+	if err != nil {
+		return errors.Wrapf(err, "invalid levels: %s", args["levels"])
+	}
+	if levels > cfg.MaxLevels {
+		return errors.Errorf("at most %d grid levels are allowed", cfg.MaxLevels)
+	}
+
+	qty, err := utils.ParseNumberArgFloat(args["quantity"])
+	if err != nil {
+		return errors.Wrapf(err, "invalid quantity: %s", args["quantity"])
+	}
+	quantity := fixedpoint.NewFromFloat(qty)
+
+	market := ent.position.Market
+	quantity = market.TruncateQuantity(quantity)
+	if quantity.Compare(market.MinQuantity) < 0 {
+		return errors.Errorf("grid quantity %v is too small, less than %v", quantity, market.MinQuantity)
+	}
+
+	grid, err := utils.NewGrid(lower, upper, levels, quantity.Float64(), closePrice.Float64())
+	if err != nil {
+		return err
+	}
+
+	if cfg.MaxNotional > 0 && grid.Notional() > cfg.MaxNotional {
+		return errors.Errorf("grid notional %.2f exceeds the maximum of %.2f", grid.Notional(), cfg.MaxNotional)
+	}
+
+	receipt := receiptFrom(ctx)
+	ent.grid = grid
+	for i, level := range grid.Levels {
+		if level.Side == "" {
+			continue
+		}
+
+		createdOrders, err := ent.placeGridOrder(ctx, i, types.SideEffectTypeMarginBuy)
+		receipt.Orders = append(receipt.Orders, createdOrders...)
+		if err != nil {
+			ent.cancelGridOrders(ctx)
+			ent.grid = nil
+			return errors.Wrap(err, "place grid orders error")
+		}
+	}
+
+	log.WithField("grid", grid.String()).Info("grid started")
+	return nil
+}
+
+// placeGridOrder submits the limit order of the level, the caller holds gridMu
+func (ent *ExchangeEntity) placeGridOrder(ctx context.Context, index int, sideEffect types.MarginOrderSideEffectType) (types.OrderSlice, error) {
+	level := &ent.grid.Levels[index]
+
+	side := types.SideTypeBuy
+	if level.Side == utils.GridSideSell {
+		side = types.SideTypeSell
+	}
+
+	quantity := fixedpoint.NewFromFloat(ent.grid.Quantity)
+	if side == types.SideTypeSell {
+		capped, err := ent.capCoreHolding(ctx, quantity)
+		if err != nil {
+			return nil, err
+		}
+		quantity = capped
+	}
+
+	orderForm := ent.generateOrderForm(side, quantity, sideEffect)
+	orderForm.Type = types.OrderTypeLimit
+	orderForm.Price = ent.position.Market.TruncatePrice(fixedpoint.NewFromFloat(level.Price))
+	orderForm.ClientOrderID = ent.clientOrderID(ctx)
+
+	createdOrders, err := ent.submitOrders(ctx, orderForm)
+	if err != nil {
+		return createdOrders, err
+	}
+
+	if len(createdOrders) > 0 {
+		level.OrderID = createdOrders[0].OrderID
+	}
+
+	return createdOrders, nil
+}
+
+// stopGrid cancels the resting grid orders, closing the position built by the grid when asked to
+func (ent *ExchangeEntity) stopGrid(ctx context.Context, reason string, closePosition bool, closePrice fixedpoint.Value) error {
+	ent.gridMu.Lock()
+	defer ent.gridMu.Unlock()
+
+	if ent.grid == nil {
+		return errors.New("no grid is running")
+	}
+
+	ent.cancelGridOrders(ctx)
+	grid := *ent.grid
+	ent.grid = nil
+
+	log.WithField("grid", grid.String()).WithField("reason", reason).Info("grid stopped")
+
+	var err error
+	if closePosition && !ent.position.IsDust(closePrice) && !ent.position.IsClosed() {
+		err = ent.ClosePosition(ctx, fixedpoint.One, closePrice)
+		if err != nil {
+			reason = fmt.Sprintf("%s, closing the position failed: %s", reason, err.Error())
+		} else {
+			reason = fmt.Sprintf("%s, the position was closed", reason)
+		}
+	}
+
+	if ent.ch != nil {
+		go ent.emitEvent(ent.ch, NewGridEvent(EventGridStopped, grid, reason))
+	}
+
+	return err
+}
+
+// cancelGridOrders cancels the open orders resting at the grid levels, the caller holds gridMu
+func (ent *ExchangeEntity) cancelGridOrders(ctx context.Context) {
+	orders, err := ent.venue.QueryOpenOrders(ctx, ent.symbol)
+	if err != nil {
+		log.WithError(err).Warn("query open orders for grid cancel failed")
+		return
+	}
+
+	gridOrders := make([]types.Order, 0)
+	for _, order := range orders {
+		if ent.grid.Level(order.OrderID) >= 0 {
+			gridOrders = append(gridOrders, order)
+		}
+	}
+
+	if len(gridOrders) == 0 {
+		return
+	}
+
+	if err := ent.venue.CancelOrders(ctx, gridOrders...); err != nil {
+		log.WithError(err).WithField("order_count", len(gridOrders)).Error("cancel grid orders failed")
+	}
+}
+
+// isGridOrder returns whether the order rests at a level of the active grid
+func (ent *ExchangeEntity) isGridOrder(orderID uint64) bool {
+	ent.gridMu.Lock()
+	defer ent.gridMu.Unlock()
+
+	return ent.grid != nil && ent.grid.Level(orderID) >= 0
+}
+
+// handleGridOrderUpdate replaces a filled grid order with the opposite order one level away and reports the fill
+func (ent *ExchangeEntity) handleGridOrderUpdate(ctx context.Context, ch chan ttypes.IEvent, order types.Order) {
+	if order.Status != types.OrderStatusFilled {
+		return
+	}
+
+	ent.gridMu.Lock()
+	defer ent.gridMu.Unlock()
+
+	if ent.grid == nil {
+		return
+	}
+
+	index := ent.grid.Level(order.OrderID)
+	if index < 0 {
+		return
+	}
+
+	filled := ent.grid.Levels[index]
+	next := ent.grid.Fill(index)
+	detail := fmt.Sprintf("%s %g at %g", filled.Side, ent.grid.Quantity, filled.Price)
+
+	if next >= 0 && !ent.observeOnly.Load() {
+		if _, err := ent.placeGridOrder(ctx, next, types.SideEffectTypeAutoRepay); err != nil {
+			log.WithError(err).WithField("level", next).Error("place grid counter order failed")
+			detail = fmt.Sprintf("%s, placing the %s at %g failed: %s", detail, ent.grid.Levels[next].Side, ent.grid.Levels[next].Price, err.Error())
+		} else {
+			detail = fmt.Sprintf("%s, %s placed at %g", detail, ent.grid.Levels[next].Side, ent.grid.Levels[next].Price)
+		}
+	}
+
+	log.WithField("fill", detail).Info("grid order filled")
+	go ent.emitEvent(ch, NewGridEvent(EventGridFilled, *ent.grid, detail))
+}
+
+// checkGridBreak stops the grid when the price left its range, so it does not keep accumulating a losing position
+func (ent *ExchangeEntity) checkGridBreak(ctx context.Context, closePrice fixedpoint.Value) {
+	grid := ent.Grid()
+	if grid == nil || ent.observeOnly.Load() {
+		return
+	}
+
+	cfg := ent.gridConfig()
+	if !grid.Breached(closePrice.Float64(), cfg.BreakPercent) {
+		return
+	}
+
+	reason := fmt.Sprintf("the price %g broke out of the range %g - %g", closePrice.Float64(), grid.Lower, grid.Upper)
+	log.WithField("price", closePrice.Float64()).Warn("grid range broken")
+
+	if err := ent.stopGrid(ctx, reason, cfg.CloseOnBreak, closePrice); err != nil {
+		log.WithError(err).Error("stop grid on range break failed")
+	}
+}
@@ -0,0 +1,165 @@
+package exchange
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/indicator"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/indicators"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EventIndicatorAlert is emitted when an indicator alert rule is triggered by the latest kline
+const EventIndicatorAlert = "indicator_alert"
+
+// IndicatorAlert is a triggered indicator alert rule
+type IndicatorAlert struct {
+	Indicator   string
+	Condition   config.IndicatorAlertCondition
+	Description string
+	Time        time.Time
+}
+
+// IndicatorAlertEvent states a threshold crossing in plain language
+type IndicatorAlertEvent struct {
+	ttypes.Event
+	Alert IndicatorAlert
+}
+
+func NewIndicatorAlertEvent(alert IndicatorAlert) *IndicatorAlertEvent {
+	return &IndicatorAlertEvent{
+		Event: *ttypes.NewEvent(EventIndicatorAlert, alert),
+		Alert: alert,
+	}
+}
+
+func (e *IndicatorAlertEvent) ToPrompts() []string {
+	return []string{fmt.Sprintf("Indicator alert: %s", e.Alert.Description)}
+}
+
+// lastTwo returns the previous and current value of the indicator, the middle band for BOLL
+func (ei *ExchangeIndicator) lastTwo() (float64, float64, bool) {
+	switch d := ei.Data.(type) {
+	case *indicator.BOLL:
+		if len(d.SMA.Values) < 2 {
+			return 0, 0, false
+		}
+		return d.SMA.Last(1), d.SMA.Last(0), true
+	case IBasicIndicator:
+		if d.Length() < 2 {
+			return 0, 0, false
+		}
+		return d.Last(1), d.Last(0), true
+	}
+
+	return 0, 0, false
+}
+
+// LastValue returns the latest value of the indicator, the middle band for BOLL
+func (ei *ExchangeIndicator) LastValue() (float64, bool) {
+	_, last, ok := ei.lastTwo()
+	return last, ok
+}
+
+// EvaluateAlerts returns the alerts of the indicator rules triggered between the previous and current close
+func EvaluateAlerts(indicators []*ExchangeIndicator, prevClose, currClose float64) []IndicatorAlert {
+	byName := make(map[string]*ExchangeIndicator, len(indicators))
+	for _, ei := range indicators {
+		byName[ei.Name] = ei
+	}
+
+	alerts := make([]IndicatorAlert, 0)
+
+	for _, ei := range indicators {
+		for _, rule := range ei.Config.Alerts {
+			description, ok := ei.evaluateAlert(rule, byName, prevClose, currClose)
+			if !ok {
+				continue
+			}
+
+			if rule.Message != "" {
+				description = fmt.Sprintf("%s: %s", rule.Message, description)
+			}
+
+			alerts = append(alerts, IndicatorAlert{
+				Indicator:   ei.Name,
+				Condition:   rule.Condition,
+				Description: description,
+				Time:        time.Now(),
+			})
+		}
+	}
+
+	return alerts
+}
+
+func (ei *ExchangeIndicator) evaluateAlert(rule config.IndicatorAlertRule, byName map[string]*ExchangeIndicator, prevClose, currClose float64) (string, bool) {
+	format := func(val float64) string {
+		return utils.FormatNumber(val, ei.Config.Format)
+	}
+
+	switch rule.Condition {
+	case config.AlertCrossAbove, config.AlertCrossBelow:
+		prev, curr, ok := ei.lastTwo()
+		if !ok {
+			return "", false
+		}
+
+		cross := utils.CrossOver(prev, curr, rule.Value, rule.Value)
+		if rule.Condition == config.AlertCrossAbove && cross > 0 {
+			return fmt.Sprintf("%s crossed above %s (%s -> %s)", ei.Name, format(rule.Value), format(prev), format(curr)), true
+		}
+		if rule.Condition == config.AlertCrossBelow && cross < 0 {
+			return fmt.Sprintf("%s crossed below %s (%s -> %s)", ei.Name, format(rule.Value), format(prev), format(curr)), true
+		}
+	case config.AlertPriceCrossUpBand, config.AlertPriceCrossDownBand:
+		boll, ok := ei.Data.(*indicator.BOLL)
+		if !ok || len(boll.UpBand) < 2 || len(boll.DownBand) < 2 {
+			return "", false
+		}
+
+		if rule.Condition == config.AlertPriceCrossUpBand && utils.CrossOver(prevClose, currClose, boll.UpBand.Last(1), boll.UpBand.Last(0)) > 0 {
+			return fmt.Sprintf("the close price crossed above the %s upper band (close %s, band %s)", ei.Name, format(currClose), format(boll.UpBand.Last(0))), true
+		}
+		if rule.Condition == config.AlertPriceCrossDownBand && utils.CrossOver(prevClose, currClose, boll.DownBand.Last(1), boll.DownBand.Last(0)) < 0 {
+			return fmt.Sprintf("the close price crossed below the %s lower band (close %s, band %s)", ei.Name, format(currClose), format(boll.DownBand.Last(0))), true
+		}
+	case config.AlertCrossAboveIndicator, config.AlertCrossBelowIndicator:
+		other, found := byName[rule.Indicator]
+		if !found {
+			log.WithField("indicator", ei.Name).WithField("other", rule.Indicator).Warn("alert indicator not found")
+			return "", false
+		}
+
+		prev, curr, ok := ei.lastTwo()
+		otherPrev, otherCurr, otherOk := other.lastTwo()
+		if !ok || !otherOk {
+			return "", false
+		}
+
+		cross := utils.CrossOver(prev, curr, otherPrev, otherCurr)
+		if rule.Condition == config.AlertCrossAboveIndicator && cross > 0 {
+			return fmt.Sprintf("%s crossed above %s (%s vs %s)", ei.Name, other.Name, format(curr), format(otherCurr)), true
+		}
+		if rule.Condition == config.AlertCrossBelowIndicator && cross < 0 {
+			return fmt.Sprintf("%s crossed below %s (%s vs %s)", ei.Name, other.Name, format(curr), format(otherCurr)), true
+		}
+	case config.AlertTrendFlip:
+		flipper, ok := ei.Data.(indicators.TrendFlipper)
+		if !ok {
+			log.WithField("indicator", ei.Name).Warn("trend_flip alert on an indicator without trend flips")
+			return "", false
+		}
+
+		if event := flipper.FlipEvent(); event != "" {
+			return fmt.Sprintf("%s: %s", ei.Name, event), true
+		}
+	default:
+		log.WithField("indicator", ei.Name).WithField("condition", rule.Condition).Warn("unsupported alert condition")
+	}
+
+	return "", false
+}
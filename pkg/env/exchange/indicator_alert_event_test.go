@@ -0,0 +1,79 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// fakeIndicator holds values in chronological order
+type fakeIndicator []float64
+
+func (f fakeIndicator) Length() int         { return len(f) }
+func (f fakeIndicator) Index(i int) float64 { return f[len(f)-1-i] }
+func (f fakeIndicator) Last(i int) float64  { return f[len(f)-1-i] }
+
+func TestEvaluateAlerts(t *testing.T) {
+	rsi := &ExchangeIndicator{
+		Name: "RSI14",
+		Type: config.IndicatorTypeRSI,
+		Config: &config.IndicatorConfig{Alerts: []config.IndicatorAlertRule{
+			{Condition: config.AlertCrossAbove, Value: 70, Message: "RSI entered overbought"},
+			{Condition: config.AlertCrossBelow, Value: 30},
+		}},
+		Data: fakeIndicator{65, 68.5, 71.25},
+	}
+	fast := &ExchangeIndicator{
+		Name: "EMA20",
+		Type: config.IndicatorTypeEWMA,
+		Config: &config.IndicatorConfig{Alerts: []config.IndicatorAlertRule{
+			{Condition: config.AlertCrossBelowIndicator, Indicator: "EMA50"},
+			{Condition: config.AlertCrossAboveIndicator, Indicator: "missing"},
+		}},
+		Data: fakeIndicator{1.9, 1.85, 1.8},
+	}
+	slow := &ExchangeIndicator{
+		Name:   "EMA50",
+		Type:   config.IndicatorTypeEWMA,
+		Config: &config.IndicatorConfig{},
+		Data:   fakeIndicator{1.8, 1.82, 1.83},
+	}
+
+	alerts := EvaluateAlerts([]*ExchangeIndicator{rsi, fast, slow}, 1.8, 1.81)
+	assert.Len(t, alerts, 2)
+
+	assert.Equal(t, "RSI14", alerts[0].Indicator)
+	assert.Equal(t, "RSI entered overbought: RSI14 crossed above 70.000 (68.500 -> 71.250)", alerts[0].Description)
+
+	assert.Equal(t, config.AlertCrossBelowIndicator, alerts[1].Condition)
+	assert.Equal(t, "EMA20 crossed below EMA50 (1.800 vs 1.830)", alerts[1].Description)
+
+	assert.Equal(t, []string{"Indicator alert: EMA20 crossed below EMA50 (1.800 vs 1.830)"}, NewIndicatorAlertEvent(alerts[1]).ToPrompts())
+}
+
+// fakeFlipper reports a fixed trend flip
+type fakeFlipper string
+
+func (f fakeFlipper) FlipEvent() string { return string(f) }
+
+func TestEvaluateTrendFlipAlerts(t *testing.T) {
+	rules := []config.IndicatorAlertRule{{Condition: config.AlertTrendFlip}}
+	flipped := &ExchangeIndicator{
+		Name:   "ST",
+		Type:   config.IndicatorTypeSuperTrend,
+		Config: &config.IndicatorConfig{Alerts: rules},
+		Data:   fakeFlipper("SuperTrend(10, 3) flipped to an uptrend"),
+	}
+	held := &ExchangeIndicator{
+		Name:   "DC",
+		Type:   config.IndicatorTypeDonchian,
+		Config: &config.IndicatorConfig{Alerts: rules},
+		Data:   fakeFlipper(""),
+	}
+
+	alerts := EvaluateAlerts([]*ExchangeIndicator{flipped, held}, 1, 2)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "ST: SuperTrend(10, 3) flipped to an uptrend", alerts[0].Description)
+}
@@ -0,0 +1,160 @@
+package exchange
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EventScheduledIntent replays the intents scheduled with schedule_intent once they are met or expired
+const EventScheduledIntent = "scheduled_intent"
+
+// ScheduledIntentEvent reports the intents the closed kline met, and those that ran out of klines
+type ScheduledIntentEvent struct {
+	ttypes.Event
+	Met     []utils.Intent
+	Expired []utils.Intent
+	Close   float64
+	Pending int // Intents still waiting for their condition
+}
+
+func NewScheduledIntentEvent(met, expired []utils.Intent, close float64, pending int) *ScheduledIntentEvent {
+	return &ScheduledIntentEvent{
+		Event:   *ttypes.NewEvent(EventScheduledIntent, met),
+		Met:     met,
+		Expired: expired,
+		Close:   close,
+		Pending: pending,
+	}
+}
+
+func (e *ScheduledIntentEvent) ToPrompts() []string {
+	lines := make([]string, 0, len(e.Met)+len(e.Expired))
+	for _, intent := range e.Met {
+		lines = append(lines, fmt.Sprintf("- Met after %d klines, the close is %g: %s", intent.Elapsed, e.Close, intent.String()))
+	}
+	for _, intent := range e.Expired {
+		lines = append(lines, fmt.Sprintf("- Expired, the condition was not met: %s", intent.String()))
+	}
+
+	msg := fmt.Sprintf("Scheduled intents, reconsider the plans you deferred against the current data:\n%s", strings.Join(lines, "\n"))
+	if e.Pending > 0 {
+		msg += fmt.Sprintf("\n%d more intents are still waiting.", e.Pending)
+	}
+
+	return []string{msg}
+}
+
+// setupIntents enables the schedule_intent action, leaving the intents nil when disabled
+func (ent *ExchangeEntity) setupIntents() {
+	cfg := &ent.cfg.Intent
+	if !cfg.Enabled {
+		return
+	}
+
+	if cfg.MaxIntents <= 0 {
+		cfg.MaxIntents = 5
+	}
+	if cfg.MaxBars <= 0 {
+		cfg.MaxBars = 20
+	}
+
+	ent.intents = utils.NewIntents(cfg.MaxIntents)
+
+	log.WithField("maxIntents", cfg.MaxIntents).Info("scheduled intents enabled")
+}
+
+func (ent *ExchangeEntity) intentActions() []*ttypes.ActionDesc {
+	return []*ttypes.ActionDesc{
+		{
+			Name:        "schedule_intent",
+			Description: "Defer a plan until a kline closes beyond a price: it is replayed to you when a close meets the condition, or when the klines given run out. Use it for setups that need a confirmation, e.g. a breakout close",
+			Args: []ttypes.ArgmentDesc{
+				{
+					Name:        "condition",
+					Description: "close_above or close_below",
+				},
+				{
+					Name:        "price",
+					Description: "Price the kline has to close beyond",
+				},
+				{
+					Name:        "bars",
+					Description: fmt.Sprintf("Klines the condition is given to be met, at most %d (default 4)", ent.cfg.Intent.MaxBars),
+				},
+				{
+					Name:        "plan",
+					Description: "What to consider once the condition is met, e.g. 'consider a long, stop below 51500'",
+				},
+			},
+			Samples: []ttypes.Sample{
+				{
+					Input: []string{
+						"Close 51650 is ranging below the resistance at 52000, there is no position",
+						"If we close above 52k in the next 4 bars, consider a long",
+					},
+					Output: []string{
+						`{"condition": "close_above", "price": "52000", "bars": "4", "plan": "breakout close above the range, consider a long with the stop below 51500"}`,
+					},
+				},
+			},
+		},
+	}
+}
+
+// scheduleIntent schedules a deferred intent from the action args
+func (ent *ExchangeEntity) scheduleIntent(args map[string]string) error {
+	if ent.intents == nil {
+		return errors.New("scheduled intents are not enabled")
+	}
+
+	price, err := utils.ParseNumberArgFloat(args["price"])
+	if err != nil {
+		return errors.Wrap(err, "invalid intent price")
+	}
+
+	bars := 4
+	if arg := strings.TrimSpace(args["bars"]); arg != "" {
+		bars, err = strconv.Atoi(arg)
+		if err != nil {
+			return errors.Errorf("invalid intent bars %q", arg)
+		}
+	}
+
+	if bars > ent.cfg.Intent.MaxBars {
+		return errors.Errorf("an intent may wait at most %d klines", ent.cfg.Intent.MaxBars)
+	}
+
+	condition := strings.ToLower(strings.TrimSpace(args["condition"]))
+	intent, err := ent.intents.Add(condition, price, bars, strings.TrimSpace(args["plan"]))
+	if err != nil {
+		return err
+	}
+
+	log.WithField("intent", intent.String()).Info("intent scheduled")
+	return nil
+}
+
+// checkIntents replays the intents met or expired by the closed kline, if any
+func (ent *ExchangeEntity) checkIntents(ch chan ttypes.IEvent, close float64) {
+	if ent.intents == nil {
+		return
+	}
+
+	met, expired := ent.intents.OnClose(close)
+	if len(met) == 0 && len(expired) == 0 {
+		return
+	}
+
+	log.WithField("met", met).
+		WithField("expired", expired).
+		WithField("close", close).
+		Info("scheduled intents replayed")
+
+	ent.emitEvent(ch, NewScheduledIntentEvent(met, expired, close, len(ent.intents.List())))
+}
@@ -0,0 +1,37 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+func TestScheduleIntent(t *testing.T) {
+	ent := &ExchangeEntity{
+		cfg: &config.EnvExchangeConfig{Intent: config.IntentConfig{Enabled: true, MaxBars: 10}},
+	}
+	ent.setupIntents()
+
+	assert.NoError(t, ent.scheduleIntent(map[string]string{"condition": "close_above", "price": "52,000", "plan": "consider a long"}))
+	assert.Error(t, ent.scheduleIntent(map[string]string{"condition": "close_above", "price": "52000", "bars": "20"}))
+	assert.Error(t, ent.scheduleIntent(map[string]string{"condition": "close_above", "price": "52000", "bars": "four"}))
+	assert.Error(t, ent.scheduleIntent(map[string]string{"condition": "touch", "price": "52000"}))
+
+	intents := ent.intents.List()
+	assert.Len(t, intents, 1)
+	assert.Equal(t, 4, intents[0].Bars)
+}
+
+func TestScheduledIntentEventPrompts(t *testing.T) {
+	met := []utils.Intent{{ID: 1, Condition: utils.IntentCloseAbove, Price: 52000, Bars: 4, Elapsed: 2, Plan: "consider a long"}}
+	expired := []utils.Intent{{ID: 2, Condition: utils.IntentCloseBelow, Price: 50000, Bars: 2, Elapsed: 2, Plan: "close the long"}}
+
+	prompts := NewScheduledIntentEvent(met, expired, 52100, 1).ToPrompts()
+	assert.Len(t, prompts, 1)
+	assert.Contains(t, prompts[0], "- Met after 2 klines, the close is 52100: #1 if a kline closes above 52000 within 4 klines: consider a long")
+	assert.Contains(t, prompts[0], "- Expired, the condition was not met: #2 if a kline closes below 50000 within 2 klines: close the long")
+	assert.Contains(t, prompts[0], "1 more intents are still waiting.")
+}
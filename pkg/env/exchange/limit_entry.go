@@ -0,0 +1,178 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EventLimitEntryExpired is emitted when a limit entry was not filled within the limit order timeout
+const EventLimitEntryExpired = "limit_entry_expired"
+
+// LimitEntryExpiredEvent reports a limit entry cancelled after the timeout, and the market order of the rest
+type LimitEntryExpiredEvent struct {
+	ttypes.Event
+	Order    types.Order
+	Filled   float64 // Base quantity of the limit order filled before the timeout
+	Fallback float64 // Base quantity entered at market instead, 0 without the fallback
+	Detail   string  // Why the rest was not entered at market, if so
+}
+
+func NewLimitEntryExpiredEvent(order types.Order, filled, fallback float64, detail string) *LimitEntryExpiredEvent {
+	return &LimitEntryExpiredEvent{
+		Event:    *ttypes.NewEvent(EventLimitEntryExpired, order),
+		Order:    order,
+		Filled:   filled,
+		Fallback: fallback,
+		Detail:   detail,
+	}
+}
+
+func (e *LimitEntryExpiredEvent) ToPrompts() []string {
+	msg := fmt.Sprintf("Limit %s entry at %s was not filled in time and was cancelled, %g of %g filled",
+		e.Order.Side, e.Order.Price.String(), e.Filled, e.Order.Quantity.Float64())
+
+	switch {
+	case e.Fallback > 0:
+		msg += fmt.Sprintf(", the remaining %g was entered with a market order.", e.Fallback)
+	case e.Detail != "":
+		msg += fmt.Sprintf(", the rest was not entered: %s.", e.Detail)
+	default:
+		msg += "."
+	}
+
+	return []string{msg}
+}
+
+// limitQuantity converts the size of a buy entry from quote to base at the limit price, as limit orders are sized
+// in base while market buys are sized in quote
+func (s *ExchangeEntity) limitQuantity(orderForm types.SubmitOrder) types.SubmitOrder {
+	if orderForm.Type != types.OrderTypeLimit || orderForm.Side != types.SideTypeBuy || orderForm.Price.Sign() <= 0 {
+		return orderForm
+	}
+
+	orderForm.Quantity = orderForm.Quantity.Div(orderForm.Price)
+	if s.marketInfo != nil {
+		orderForm.Quantity = s.position.Market.TruncateQuantity(orderForm.Quantity)
+	}
+
+	return orderForm
+}
+
+// expireLimitEntry cancels the limit entry orders still open after the limit order timeout, and enters the
+// unfilled rest with a market order when the fallback is enabled. It runs detached from the decision cycle.
+func (s *ExchangeEntity) expireLimitEntry(ctx context.Context, orderForm types.SubmitOrder, orders types.OrderSlice) {
+	cfg := s.cfg.LimitOrder
+	if cfg.Timeout <= 0 || orderForm.Type != types.OrderTypeLimit || len(orders) == 0 {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		if err := utils.SleepContext(ctx, cfg.Timeout.Duration()); err != nil {
+			return
+		}
+
+		queryService, canQuery := s.orderQueryService()
+
+		for _, order := range orders {
+			filled := fixedpoint.Zero
+			if canQuery {
+				current, err := queryService.QueryOrder(ctx, types.OrderQuery{
+					Symbol:  s.symbol,
+					OrderID: strconv.FormatUint(order.OrderID, 10),
+				})
+				if err != nil {
+					log.WithError(err).WithField("orderID", order.OrderID).Warn("query limit entry error")
+					continue
+				}
+
+				if current.Status == types.OrderStatusFilled {
+					continue
+				}
+				if current.Status == types.OrderStatusCanceled || current.Status == types.OrderStatusRejected {
+					// Cleaned up by the next decision cycle or by hand
+					continue
+				}
+
+				filled = current.ExecutedQuantity
+			}
+
+			if err := s.venue.CancelOrders(ctx, order); err != nil {
+				log.WithError(err).WithField("orderID", order.OrderID).Warn("cancel limit entry error, it may be filled already")
+				continue
+			}
+
+			log.WithField("orderID", order.OrderID).
+				WithField("filled", filled).
+				WithField("timeout", cfg.Timeout).
+				Info("limit entry expired")
+
+			fallback, detail := s.fallbackToMarket(ctx, orderForm, order.Quantity.Sub(filled))
+			if s.ch != nil {
+				s.emitEvent(s.ch, NewLimitEntryExpiredEvent(order, filled.Float64(), fallback, detail))
+			}
+		}
+	}()
+}
+
+// fallbackToMarket enters the unfilled base quantity of an expired limit entry at market, keeping its stop-loss
+// and take-profit, once the guards still allow entries. It returns the quantity entered, or why nothing was.
+func (s *ExchangeEntity) fallbackToMarket(ctx context.Context, orderForm types.SubmitOrder, remaining fixedpoint.Value) (float64, string) {
+	if !s.cfg.LimitOrder.FallbackToMarket {
+		return 0, "the fallback to market is disabled"
+	}
+
+	if s.observeOnly.Load() {
+		return 0, "another instance is trading this account and symbol"
+	}
+
+	if !s.positionCheckAllows("open_long_position") {
+		return 0, "entries are paused while the position diverges from the exchange"
+	}
+
+	// The guards passed when the limit entry was placed, the timeout gives them time to trip
+	if s.entryCheck != nil {
+		side := PositionSideLong
+		if orderForm.Side == types.SideTypeSell {
+			side = PositionSideShort
+		}
+
+		if err := s.entryCheck(ctx, side); err != nil {
+			return 0, err.Error()
+		}
+	}
+
+	if remaining.Compare(s.position.Market.MinQuantity) < 0 {
+		return 0, "the rest is below the minimum order quantity"
+	}
+
+	price, err := s.MarketPrice(ctx)
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	// Market buys are sized in quote
+	quantity := remaining
+	if orderForm.Side == types.SideTypeBuy {
+		quantity = remaining.Mul(price)
+	}
+
+	form := s.generateOrderForm(orderForm.Side, quantity, orderForm.MarginSideEffect)
+	form.StopPrice = orderForm.StopPrice
+	form.TakePrice = orderForm.TakePrice
+
+	if _, err := s.submitOrder(ctx, form, price); err != nil {
+		log.WithError(err).Error("market fallback of the limit entry error")
+		return 0, err.Error()
+	}
+
+	return remaining.Float64(), ""
+}
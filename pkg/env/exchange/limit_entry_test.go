@@ -0,0 +1,44 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitQuantity(t *testing.T) {
+	ent := &ExchangeEntity{}
+
+	buy := types.SubmitOrder{
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromFloat(1000),
+		Price:    fixedpoint.NewFromFloat(2.5),
+	}
+	assert.Equal(t, 400.0, ent.limitQuantity(buy).Quantity.Float64())
+
+	// Market buys stay in quote and sells in base
+	market := buy
+	market.Type = types.OrderTypeMarket
+	assert.Equal(t, 1000.0, ent.limitQuantity(market).Quantity.Float64())
+
+	sell := buy
+	sell.Side = types.SideTypeSell
+	assert.Equal(t, 1000.0, ent.limitQuantity(sell).Quantity.Float64())
+}
+
+func TestLimitEntryExpiredEventPrompts(t *testing.T) {
+	order := types.Order{SubmitOrder: types.SubmitOrder{
+		Side:     types.SideTypeBuy,
+		Quantity: fixedpoint.NewFromFloat(400),
+		Price:    fixedpoint.NewFromFloat(2.5),
+	}}
+
+	prompts := NewLimitEntryExpiredEvent(order, 100, 300, "").ToPrompts()
+	assert.Equal(t, []string{"Limit BUY entry at 2.5 was not filled in time and was cancelled, 100 of 400 filled, the remaining 300 was entered with a market order."}, prompts)
+
+	prompts = NewLimitEntryExpiredEvent(order, 0, 0, "the fallback to market is disabled").ToPrompts()
+	assert.Equal(t, []string{"Limit BUY entry at 2.5 was not filled in time and was cancelled, 0 of 400 filled, the rest was not entered: the fallback to market is disabled."}, prompts)
+}
@@ -0,0 +1,36 @@
+package exchange
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// SetMarketInfo sets the market metadata loaded at startup, used to validate orders before they are submitted
+func (s *ExchangeEntity) SetMarketInfo(info *utils.MarketInfo) {
+	s.marketInfo = info
+	if info != nil && s.position != nil && s.position.Position != nil {
+		info.Apply(&s.position.Market)
+	}
+}
+
+// checkMinNotional rejects an order below the min notional of the market, market buys are sized in quote
+func (s *ExchangeEntity) checkMinNotional(side types.SideType, quantity fixedpoint.Value, price fixedpoint.Value) error {
+	if s.marketInfo == nil || s.marketInfo.MinNotional <= 0 {
+		return nil
+	}
+
+	notional := quantity.Float64()
+	if side == types.SideTypeSell {
+		notional = s.marketInfo.Notional(quantity.Float64(), price.Float64())
+	}
+
+	if notional < s.marketInfo.MinNotional {
+		return fmt.Errorf("%s order notional %.2f is too small, less than the min notional %g", s.symbol, notional, s.marketInfo.MinNotional)
+	}
+
+	return nil
+}
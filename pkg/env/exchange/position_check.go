@@ -0,0 +1,157 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EventPositionDiverged is emitted when the local position stops matching the one the exchange reports
+const EventPositionDiverged = "position_diverged"
+
+// PositionDivergedEvent reports a mismatch between the local and the exchange position
+type PositionDivergedEvent struct {
+	ttypes.Event
+	Symbol     string
+	Divergence utils.PositionDivergence
+}
+
+func NewPositionDivergedEvent(symbol string, divergence utils.PositionDivergence) *PositionDivergedEvent {
+	return &PositionDivergedEvent{
+		Event:      *ttypes.NewEvent(EventPositionDiverged, divergence),
+		Symbol:     symbol,
+		Divergence: divergence,
+	}
+}
+
+func (e *PositionDivergedEvent) ToPrompts() []string {
+	return []string{fmt.Sprintf("Position mismatch on %s: %s. New entries are paused until an operator reconciles the position, closing it is still allowed.",
+		e.Symbol, e.Divergence.String())}
+}
+
+// setupPositionCheck starts comparing the local position with the exchange one at the configured interval
+func (ent *ExchangeEntity) setupPositionCheck(ctx context.Context, ch chan ttypes.IEvent) {
+	cfg := &ent.cfg.PositionCheck
+	if !cfg.Enabled || ent.venue == nil {
+		return
+	}
+
+	if cfg.Interval == 0 {
+		cfg.Interval = types.Duration(5 * time.Minute)
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = ent.position.Market.MinQuantity.Float64()
+	}
+
+	go func() {
+		defer ent.recoverCallback("position_check")
+
+		ticker := time.NewTicker(cfg.Interval.Duration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := ent.checkPosition(ctx, ch); err != nil {
+					log.WithError(err).Warn("position check failed")
+				}
+			}
+		}
+	}()
+
+	log.WithField("interval", cfg.Interval).WithField("tolerance", cfg.Tolerance).Info("position check enabled")
+}
+
+// exchangePosition queries the base quantity the exchange reports, less the core holding the strategy never trades
+func (ent *ExchangeEntity) exchangePosition(ctx context.Context) (fixedpoint.Value, error) {
+	base := fixedpoint.Zero
+
+	err := ent.retry.Do(ctx, utils.RetryTransient, func(ctx context.Context) error {
+		qty, err := ent.venue.QueryPosition(ctx, ent.symbol)
+		base = qty
+		return err
+	})
+	if err != nil {
+		return fixedpoint.Zero, errors.Wrap(err, "query position")
+	}
+
+	if core := ent.cfg.CoreHolding; core.Enabled && core.Quantity > 0 {
+		base = base.Sub(fixedpoint.NewFromFloat(core.Quantity))
+	}
+
+	return base, nil
+}
+
+// checkPosition compares the local position with the exchange one, reporting a divergence once when it starts
+func (ent *ExchangeEntity) checkPosition(ctx context.Context, ch chan ttypes.IEvent) error {
+	exchange, err := ent.exchangePosition(ctx)
+	if err != nil {
+		return err
+	}
+
+	divergence := utils.CheckPositionDivergence(ent.position.GetBase().Float64(), exchange.Float64(), ent.cfg.PositionCheck.Tolerance)
+
+	ent.divergenceMu.Lock()
+	started := divergence != nil && ent.divergence == nil
+	ent.divergence = divergence
+	ent.divergenceMu.Unlock()
+
+	if started {
+		log.WithField("divergence", divergence.String()).Error("position diverged from the exchange, entries paused")
+		ch <- NewPositionDivergedEvent(ent.symbol, *divergence)
+	}
+
+	return nil
+}
+
+// positionCheckAllows blocks the commands adding exposure while the position diverges from the exchange
+func (ent *ExchangeEntity) positionCheckAllows(cmd string) bool {
+	switch cmd {
+	case "open_long_position", "open_short_position", "update_position", "start_grid":
+	default:
+		return true
+	}
+
+	ent.divergenceMu.Lock()
+	defer ent.divergenceMu.Unlock()
+
+	return ent.divergence == nil
+}
+
+// ReconcilePosition adopts the position the exchange reports as the local one and resumes entries
+func (ent *ExchangeEntity) ReconcilePosition(ctx context.Context) (string, error) {
+	if ent.venue == nil {
+		return "", errors.New("no execution venue")
+	}
+
+	exchange, err := ent.exchangePosition(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	local := ent.position.GetBase()
+	ent.position.Base = exchange
+	if local.IsZero() && !exchange.IsZero() && ent.KLineWindow != nil {
+		// the local position has no cost to keep, the close price is the best estimate
+		ent.position.AverageCost = ent.KLineWindow.GetClose()
+	} else if exchange.IsZero() {
+		ent.position.AverageCost = fixedpoint.Zero
+	}
+
+	ent.divergenceMu.Lock()
+	ent.divergence = nil
+	ent.divergenceMu.Unlock()
+
+	log.WithField("local", local).WithField("exchange", exchange).Info("position reconciled with the exchange")
+
+	return fmt.Sprintf("%s position reconciled from %v to %v, entries resumed", ent.symbol, local, exchange), nil
+}
@@ -0,0 +1,60 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+func TestPositionCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"symbol": "BTC/USDT", "side": "long", "contracts": 0.3}]`))
+	}))
+	defer server.Close()
+
+	position := &types.Position{
+		Symbol:      "BTCUSDT",
+		Base:        fixedpoint.NewFromFloat(0.5),
+		AverageCost: fixedpoint.NewFromFloat(50000),
+	}
+
+	ent := &ExchangeEntity{
+		symbol:   "BTCUSDT",
+		cfg:      &config.EnvExchangeConfig{PositionCheck: config.PositionCheckConfig{Enabled: true, Tolerance: 0.001}},
+		position: NewPositionX(position),
+		venue:    NewRestVenue(config.VenueConfig{BaseURL: server.URL, Timeout: types.Duration(time.Second)}, "BTCUSDT", position),
+		retry:    newRetryPolicy(config.RetryConfig{}),
+	}
+
+	ctx := context.Background()
+	ch := make(chan ttypes.IEvent, 2)
+
+	assert.NoError(t, ent.checkPosition(ctx, ch))
+	assert.NoError(t, ent.checkPosition(ctx, ch))
+	assert.Len(t, ch, 1, "a divergence is reported once")
+
+	evt := (<-ch).(*PositionDivergedEvent)
+	assert.Equal(t, 0.5, evt.Divergence.Local)
+	assert.Equal(t, 0.3, evt.Divergence.Exchange)
+
+	assert.False(t, ent.positionCheckAllows("open_long_position"))
+	assert.True(t, ent.positionCheckAllows("close_position"))
+
+	_, err := ent.ReconcilePosition(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.3, ent.position.GetBase().Float64())
+	assert.Equal(t, 50000.0, ent.position.AverageCost.Float64())
+	assert.True(t, ent.positionCheckAllows("open_long_position"))
+
+	assert.NoError(t, ent.checkPosition(ctx, ch))
+	assert.Len(t, ch, 0)
+}
@@ -12,21 +12,33 @@ const (
 	EventPositionClosed = "position_closed"
 )
 
+// Position side constants
+const (
+	PositionSideLong  = "long"
+	PositionSideShort = "short"
+)
+
 // CloseReason constants
 const (
 	CloseReasonManual      = "Manual"
 	CloseReasonTakeProfit  = "TakeProfit"
 	CloseReasonStopLoss    = "StopLoss"
 	CloseReasonLiquidation = "Liquidation"
+	CloseReasonSchedule    = "Schedule"
 )
 
 // PositionClosedEventData contains all the information about a closed position
 type PositionClosedEventData struct {
 	StrategyID           string      // ID of the strategy that managed this position
 	Symbol               string      // Trading pair symbol
+	Side                 string      // Side of the closed position: "long" or "short"
 	EntryPrice           float64     // Price at which the position was opened
 	ExitPrice            float64     // Price at which the position was closed
 	Quantity             float64     // Position size
+	StopLossPrice        float64     // Stop-loss trigger price at close time, 0 if none was set
+	HoldingPeriod        int         // Number of klines the position was held
+	MAEPercent           float64     // Max adverse excursion over the trade, as a non-positive percent of the entry price
+	MFEPercent           float64     // Max favorable excursion over the trade, as a non-negative percent of the entry price
 	ProfitAndLoss        float64     // Profit or loss amount (quote currency)
 	ProfitAndLossPercent float64     // Profit or loss percentage
 	CloseReason          string      // Reason for closing: "TakeProfit", "StopLoss", "Manual", "Liquidation", etc.
@@ -53,6 +65,8 @@ func (data PositionClosedEventData) ToPrompts() []string {
 		"Exit Price: %.2f\n"+
 		"Quantity: %.6f\n"+
 		"%s: %.2f (%.2f%%)\n"+
+		"Max Adverse Excursion: %.2f%%\n"+
+		"Max Favorable Excursion: +%.2f%%\n"+
 		"Close Reason: %s\n"+
 		"Close Time: %s",
 		data.Symbol,
@@ -64,6 +78,8 @@ func (data PositionClosedEventData) ToPrompts() []string {
 		pnlStr,
 		data.ProfitAndLoss,
 		data.ProfitAndLossPercent,
+		data.MAEPercent,
+		data.MFEPercent,
 		data.CloseReason,
 		data.Timestamp.Format(time.RFC3339),
 	)
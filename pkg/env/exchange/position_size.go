@@ -0,0 +1,52 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// positionSize translates the position_size_percent arg of an entry into a position size from the leveraged
+// balance, clamped to the max percent
+func (s *ExchangeEntity) positionSize(ctx context.Context, sizeArg string, entryPrice fixedpoint.Value) (*utils.PositionSize, error) {
+	// The share is in percent with or without the sign
+	arg, err := utils.ParseNumberArg(sizeArg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid position_size_percent: %s", sizeArg)
+	}
+
+	quoteQty, err := s.quoteQuantity(ctx, s.entryLeverage())
+	if err != nil {
+		return nil, errors.Wrap(err, "calculate quote quantity error")
+	}
+
+	maxPercent := s.cfg.PositionSizing.MaxPercent
+	if maxPercent <= 0 {
+		maxPercent = 100
+	}
+
+	return utils.ComputePositionSize(utils.PositionSizeParams{
+		Percent:     arg.Float64(),
+		MaxPercent:  maxPercent,
+		Balance:     quoteQty.Float64(),
+		EntryPrice:  entryPrice.Float64(),
+		MinQuantity: s.position.Market.MinQuantity.Float64(),
+	})
+}
+
+// positionSizeOpt sizes the entry with the position size, keeping the 1% buffer calculateQuantity keeps on shorts
+func positionSizeOpt(size *utils.PositionSize, side types.SideType) *RiskSizeOpt {
+	quantity := size.Quantity
+	if side == types.SideTypeSell {
+		quantity = quantity * 0.99
+	}
+
+	return &RiskSizeOpt{
+		Quantity: fixedpoint.NewFromFloat(quantity),
+		Notional: fixedpoint.NewFromFloat(size.Notional),
+	}
+}
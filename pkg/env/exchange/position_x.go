@@ -4,6 +4,8 @@ import (
 	"github.com/c9s/bbgo/pkg/datatype/floats"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
 )
 
 type PositionX struct {
@@ -12,6 +14,10 @@ type PositionX struct {
 	Dust                   bool
 	historyProfits         []fixedpoint.Value
 	AccumulatedProfitValue fixedpoint.Value
+	lastSide               string
+
+	// max adverse and favorable excursion of the open trade, or of the last one once it is closed
+	Excursion utils.ExcursionTracker
 }
 
 func NewPositionX(pos *types.Position) *PositionX {
@@ -23,18 +29,55 @@ func NewPositionX(pos *types.Position) *PositionX {
 	pos.OnModify(func(baseQty fixedpoint.Value, quoteQty fixedpoint.Value, price fixedpoint.Value) {
 		if pos.IsClosed() {
 			x.historyProfits = make([]fixedpoint.Value, 0)
+			x.Excursion.Stop()
+			return
+		}
+
+		if !x.Excursion.Active {
+			x.Excursion.Start()
+		}
+
+		if pos.IsLong() {
+			x.lastSide = PositionSideLong
+		} else if pos.IsShort() {
+			x.lastSide = PositionSideShort
 		}
 	})
 
 	return x
 }
 
+// GetLastSide returns the side of the open position, or of the last position once it is closed
+func (pos *PositionX) GetLastSide() string {
+	if pos.IsLong() {
+		return PositionSideLong
+	} else if pos.IsShort() {
+		return PositionSideShort
+	}
+
+	return pos.lastSide
+}
+
+// GetStopLossPrice returns the stop-loss trigger price, or 0 if none is set
+func (pos *PositionX) GetStopLossPrice() float64 {
+	if pos.SlTriggerPx == nil {
+		return 0
+	}
+
+	return pos.SlTriggerPx.Float64()
+}
+
 func (pos *PositionX) UpdateProfit(percent fixedpoint.Value, profitValue fixedpoint.Value) {
 	pos.AccumulatedProfit = percent
 	pos.AccumulatedProfitValue = profitValue
 	pos.historyProfits = append(pos.historyProfits, percent)
 }
 
+// UpdateExcursion extends the excursions of the open trade with the range of a closed kline
+func (pos *PositionX) UpdateExcursion(high fixedpoint.Value, low fixedpoint.Value) {
+	pos.Excursion.Update(pos.GetLastSide(), pos.AverageCost.Float64(), high.Float64(), low.Float64())
+}
+
 func (pos *PositionX) GetProfitValues() floats.Slice {
 	values := make(floats.Slice, 0)
 
@@ -0,0 +1,258 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+const (
+	// EventPriceAlert is emitted when the price reaches a level set by the agent with set_alert
+	EventPriceAlert = "price_alert"
+	// EventActivePriceAlerts lists the alerts still waiting in every decision cycle
+	EventActivePriceAlerts = "active_price_alerts"
+)
+
+// PriceAlertEvent reports the price alerts reached by the kline being traded
+type PriceAlertEvent struct {
+	ttypes.Event
+	Alerts    []utils.PriceAlert
+	Price     float64 // Latest traded price
+	Intrabar  bool    // The alerts fired a decision cycle before the kline closed
+	Throttled bool    // The alerts were reached too soon after the last alert cycle to fire another
+}
+
+func NewPriceAlertEvent(alerts []utils.PriceAlert, price float64, intrabar, throttled bool) *PriceAlertEvent {
+	return &PriceAlertEvent{
+		Event:     *ttypes.NewEvent(EventPriceAlert, alerts),
+		Alerts:    alerts,
+		Price:     price,
+		Intrabar:  intrabar,
+		Throttled: throttled,
+	}
+}
+
+func (e *PriceAlertEvent) ToPrompts() []string {
+	reached := make([]string, 0, len(e.Alerts))
+	for _, alert := range e.Alerts {
+		reached = append(reached, alert.String())
+	}
+
+	msg := fmt.Sprintf("Price alert triggered (%s), the latest price is %g.", strings.Join(reached, ", "), e.Price)
+	if e.Intrabar {
+		msg += " This decision cycle was fired by the alert before the current kline closed, the kline data only covers the closed klines."
+	} else if e.Throttled {
+		msg += " It was reached mid-candle, shortly after another alert, so it is reported with the kline close."
+	}
+
+	return []string{msg}
+}
+
+// ActivePriceAlertsEvent lists the active price alerts, so the agent keeps track of the plans it set
+type ActivePriceAlertsEvent struct {
+	ttypes.Event
+	Alerts []utils.PriceAlert
+}
+
+func NewActivePriceAlertsEvent(alerts []utils.PriceAlert) *ActivePriceAlertsEvent {
+	return &ActivePriceAlertsEvent{
+		Event:  *ttypes.NewEvent(EventActivePriceAlerts, alerts),
+		Alerts: alerts,
+	}
+}
+
+func (e *ActivePriceAlertsEvent) ToPrompts() []string {
+	lines := make([]string, 0, len(e.Alerts))
+	for _, alert := range e.Alerts {
+		lines = append(lines, fmt.Sprintf("- %s, set %s ago", alert.String(), time.Since(alert.CreatedAt).Round(time.Minute)))
+	}
+
+	return []string{fmt.Sprintf("Active price alerts, cancel the ones no longer needed with cancel_alert:\n%s", strings.Join(lines, "\n"))}
+}
+
+// setupPriceAlerts enables the set_alert action, leaving the alerts nil when disabled
+func (ent *ExchangeEntity) setupPriceAlerts() {
+	cfg := &ent.cfg.PriceAlert
+	if !cfg.Enabled {
+		return
+	}
+
+	if cfg.MaxAlerts <= 0 {
+		cfg.MaxAlerts = 5
+	}
+	if cfg.MinInterval == 0 {
+		cfg.MinInterval = types.Duration(time.Minute)
+	}
+
+	ent.priceAlerts = utils.NewPriceAlerts(cfg.MaxAlerts)
+
+	log.WithField("maxAlerts", cfg.MaxAlerts).Info("price alerts enabled")
+}
+
+func (ent *ExchangeEntity) priceAlertActions() []*ttypes.ActionDesc {
+	return []*ttypes.ActionDesc{
+		{
+			Name:        "set_alert",
+			Description: "Wake me when the price reaches a level: a decision cycle fires as soon as it is traded, without waiting for the kline to close. Use it for levels that need a fast reaction, e.g. near the stop loss or a breakout level",
+			Args: []ttypes.ArgmentDesc{
+				{
+					Name:        "price",
+					Description: fmt.Sprintf("Price level to be woken at, at most %d alerts are active", ent.cfg.PriceAlert.MaxAlerts),
+				},
+				{
+					Name:        "direction",
+					Description: "above: alert when the price rises to the level, below: when it falls to it (default: the side of the current price the level is on)",
+				},
+				{
+					Name:        "note",
+					Description: "What to do at the level, shown with the alert, e.g. 'breakout above the range, consider a long'",
+				},
+			},
+			Samples: []ttypes.Sample{
+				{
+					Input: []string{
+						"Close 51650 is ranging below the resistance at 52000, there is no position",
+						"Wake me if we break 52k",
+					},
+					Output: []string{
+						`{"price": "52000", "direction": "above", "note": "breakout above the range resistance, consider a long"}`,
+					},
+				},
+			},
+		},
+		{
+			Name:        "cancel_alert",
+			Description: "Cancel an active price alert",
+			Args: []ttypes.ArgmentDesc{
+				{
+					Name:        "id",
+					Description: "Id of the alert, as in the active price alerts list, e.g. 3",
+				},
+			},
+		},
+	}
+}
+
+// setAlert sets a price alert, by default in the direction of the side of the current price the level is on
+func (ent *ExchangeEntity) setAlert(args map[string]string, closePrice fixedpoint.Value) error {
+	if ent.priceAlerts == nil {
+		return errors.New("price alerts are not enabled")
+	}
+
+	price, err := utils.ParseNumberArgFloat(args["price"])
+	if err != nil {
+		return errors.Wrap(err, "invalid alert price")
+	}
+
+	current := closePrice.Float64()
+
+	var above bool
+	switch direction := strings.ToLower(strings.TrimSpace(args["direction"])); direction {
+	case "":
+		if price == current {
+			return errors.Errorf("the alert price %g is the current price", price)
+		}
+		above = price > current
+	case "above", "up":
+		above = true
+	case "below", "down":
+		above = false
+	default:
+		return errors.Errorf("invalid alert direction %q, use above or below", direction)
+	}
+
+	// A level already passed would trigger on the next price update
+	if above && price <= current {
+		return errors.Errorf("the price %g is already above the alert price %g", current, price)
+	}
+	if !above && price >= current {
+		return errors.Errorf("the price %g is already below the alert price %g", current, price)
+	}
+
+	alert, err := ent.priceAlerts.Add(price, above, strings.TrimSpace(args["note"]))
+	if err != nil {
+		return err
+	}
+
+	log.WithField("alert", alert.String()).Info("price alert set")
+	return nil
+}
+
+// cancelAlert cancels the active price alert with the id arg
+func (ent *ExchangeEntity) cancelAlert(args map[string]string) error {
+	if ent.priceAlerts == nil {
+		return errors.New("price alerts are not enabled")
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(args["id"]), "#"))
+	if err != nil {
+		return errors.Errorf("invalid alert id %q", args["id"])
+	}
+
+	if !ent.priceAlerts.Remove(id) {
+		return errors.Errorf("no active price alert #%d", id)
+	}
+
+	log.WithField("id", id).Info("price alert cancelled")
+	return nil
+}
+
+// emitActivePriceAlerts lists the active price alerts for the decision cycle, if any
+func (ent *ExchangeEntity) emitActivePriceAlerts(ch chan ttypes.IEvent) {
+	if ent.priceAlerts == nil {
+		return
+	}
+
+	if alerts := ent.priceAlerts.List(); len(alerts) > 0 {
+		ent.emitEvent(ch, NewActivePriceAlertsEvent(alerts))
+	}
+}
+
+// checkPriceAlerts fires a decision cycle when an update of the kline being traded reaches an alert. Within the
+// min interval of the last alert cycle, or on the kline close which runs a cycle anyway, the alert is only reported.
+func (ent *ExchangeEntity) checkPriceAlerts(ctx context.Context, ch chan ttypes.IEvent, kline types.KLine) {
+	if ent.Status != types.StrategyStatusRunning {
+		return
+	}
+
+	triggered := ent.priceAlerts.Check(kline.High.Float64(), kline.Low.Float64())
+	if len(triggered) == 0 {
+		return
+	}
+
+	price := kline.Close.Float64()
+	throttled := time.Since(ent.alertCycleAt) < ent.cfg.PriceAlert.MinInterval.Duration()
+
+	log.WithField("alerts", triggered).
+		WithField("price", price).
+		WithField("closed", kline.Closed).
+		WithField("throttled", throttled).
+		Info("price alerts triggered")
+
+	if kline.Closed || throttled {
+		ent.emitEvent(ch, NewPriceAlertEvent(triggered, price, false, throttled))
+		return
+	}
+
+	ent.alertCycleAt = time.Now()
+
+	ent.emitEvent(ch, NewPriceAlertEvent(triggered, price, true, false))
+	ent.emitActivePriceAlerts(ch)
+	ent.emitEvent(ch, ttypes.NewEvent("kline_changed", ent.KLineWindow))
+
+	for _, indicator := range ent.Indicators {
+		ent.emitEvent(ch, ttypes.NewEvent("indicator_changed", indicator))
+	}
+
+	ent.emitEvent(ch, ttypes.NewEvent("position_changed", ent.position))
+	ent.emitEvent(ch, ttypes.NewEvent("update_finish", nil))
+}
@@ -0,0 +1,49 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+func TestSetAndCancelAlert(t *testing.T) {
+	ent := &ExchangeEntity{
+		cfg: &config.EnvExchangeConfig{PriceAlert: config.PriceAlertConfig{Enabled: true}},
+	}
+	ent.setupPriceAlerts()
+	closePrice := fixedpoint.NewFromFloat(51650)
+
+	assert.NoError(t, ent.setAlert(map[string]string{"price": "52,000", "note": "breakout"}, closePrice))
+	assert.NoError(t, ent.setAlert(map[string]string{"price": "51000", "direction": "below"}, closePrice))
+
+	// Levels already passed in the direction would trigger right away
+	assert.Error(t, ent.setAlert(map[string]string{"price": "51000", "direction": "above"}, closePrice))
+	assert.Error(t, ent.setAlert(map[string]string{"price": "52000", "direction": "sideways"}, closePrice))
+	assert.Error(t, ent.setAlert(map[string]string{"price": "51650"}, closePrice))
+
+	alerts := ent.priceAlerts.List()
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, "#1 price rises to 52000 (breakout)", alerts[0].String())
+	assert.Equal(t, "#2 price falls to 51000", alerts[1].String())
+
+	assert.NoError(t, ent.cancelAlert(map[string]string{"id": "#1"}))
+	assert.Error(t, ent.cancelAlert(map[string]string{"id": "1"}))
+	assert.Error(t, ent.cancelAlert(map[string]string{"id": "first"}))
+	assert.Equal(t, 1, ent.priceAlerts.Len())
+}
+
+func TestPriceAlertEventPrompts(t *testing.T) {
+	alerts := []utils.PriceAlert{{ID: 1, Price: 52000, Above: true, Note: "breakout"}}
+
+	prompts := NewPriceAlertEvent(alerts, 52010, true, false).ToPrompts()
+	assert.Len(t, prompts, 1)
+	assert.Contains(t, prompts[0], "Price alert triggered (#1 price rises to 52000 (breakout)), the latest price is 52010.")
+	assert.Contains(t, prompts[0], "fired by the alert before the current kline closed")
+
+	prompts = NewActivePriceAlertsEvent(alerts).ToPrompts()
+	assert.Contains(t, prompts[0], "Active price alerts, cancel the ones no longer needed with cancel_alert:\n- #1 price rises to 52000 (breakout)")
+}
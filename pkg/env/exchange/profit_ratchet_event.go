@@ -0,0 +1,155 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EventProfitRatchetAdvanced is emitted when the profit-lock ratchet reaches a new step
+const EventProfitRatchetAdvanced = "profit_ratchet_advanced"
+
+// ProfitRatchetEvent reports the step the ratchet advanced to and whether the stop was tightened
+type ProfitRatchetEvent struct {
+	ttypes.Event
+	Ratchet utils.ProfitRatchet
+	Applied bool // Whether the stop was tightened, false when the current stop is already tighter or the update failed
+}
+
+func NewProfitRatchetEvent(ratchet utils.ProfitRatchet, applied bool) *ProfitRatchetEvent {
+	return &ProfitRatchetEvent{
+		Event:   *ttypes.NewEvent(EventProfitRatchetAdvanced, ratchet),
+		Ratchet: ratchet,
+		Applied: applied,
+	}
+}
+
+func (e *ProfitRatchetEvent) ToPrompts() []string {
+	r := e.Ratchet
+
+	msg := fmt.Sprintf("Profit-lock ratchet advanced to step %d after reaching %.2fR", r.Level, r.PeakR)
+	if e.Applied {
+		msg += fmt.Sprintf(": stop tightened to %.4f, locking %+.2fR.", r.Stop, r.LockedR)
+	} else {
+		msg += fmt.Sprintf(": it locks %+.2fR at %.4f, the current stop is kept.", r.LockedR, r.Stop)
+	}
+
+	return []string{msg}
+}
+
+// ProfitRatchet returns the profit-lock ratchet of the open position, nil without one
+func (ent *ExchangeEntity) ProfitRatchet() *utils.ProfitRatchet {
+	return ent.profitRatchet
+}
+
+// updateProfitRatchet advances the ratchet with the close price and tightens the stop to the reached step
+func (ent *ExchangeEntity) updateProfitRatchet(ctx context.Context, ch chan ttypes.IEvent, closePrice fixedpoint.Value) {
+	cfg := ent.cfg.ProfitRatchet
+	if !cfg.Enabled || ent.position == nil || ent.observeOnly.Load() {
+		return
+	}
+
+	if ent.position.IsClosed() || ent.position.Dust {
+		ent.profitRatchet = nil
+		ent.entryStop = 0
+		return
+	}
+
+	if ent.profitRatchet == nil {
+		initialStop := ent.entryStop
+		if initialStop == 0 {
+			initialStop = ent.position.GetStopLossPrice()
+		}
+
+		// Without an initial stop there is no R to measure the profit in
+		ent.profitRatchet = utils.NewProfitRatchet(ent.position.GetLastSide(), ent.position.AverageCost.Float64(), initialStop)
+		if ent.profitRatchet == nil {
+			return
+		}
+	}
+
+	steps := cfg.Steps
+	if len(steps) == 0 {
+		steps = utils.DefaultProfitRatchetSteps
+	}
+
+	ratchet := ent.profitRatchet
+	advanced := ratchet.Advance(closePrice.Float64(), steps)
+
+	// Also re-applied when the stop was widened since the step was reached
+	applied := false
+	if ratchet.Tightens(ent.position.GetStopLossPrice()) {
+		err := ent.updateStopLoss(ctx, fixedpoint.NewFromFloat(ratchet.Stop))
+		if err != nil {
+			log.WithError(err).WithField("stop", ratchet.Stop).Error("profit ratchet tighten stop loss failed")
+		} else {
+			applied = true
+		}
+	}
+
+	if advanced {
+		log.
+			WithField("level", ratchet.Level).
+			WithField("stop", ratchet.Stop).
+			WithField("applied", applied).
+			Info("profit ratchet advanced")
+		ent.emitEvent(ch, NewProfitRatchetEvent(*ratchet, applied))
+	}
+}
+
+// updateStopLoss moves the stop-loss of the open position, it never closes and reopens the position
+func (ent *ExchangeEntity) updateStopLoss(ctx context.Context, stop fixedpoint.Value) error {
+	service, ok := ent.session.Exchange.(types.ExchangePositionUpdateService)
+	if !ok {
+		return errors.New("exchange does not support updating the position stop loss")
+	}
+
+	pos := ent.position.Position
+	pos.SlTriggerPx = &stop
+
+	return service.UpdatePosition(ctx, pos)
+}
+
+// tightenStop moves the stop of the open position closer to the price, keeping the ratio of its distance,
+// or sets one at fallback_percent from the price when the position has none. It runs as the tighten_stop
+// command, which is not offered to the agent, so it executes like every other command
+func (ent *ExchangeEntity) tightenStop(ctx context.Context, args map[string]string) error {
+	if ent.position == nil || ent.position.IsClosed() || ent.position.Dust {
+		return nil
+	}
+
+	ratio, err := utils.ParseNumberArgFloat(args["ratio"])
+	if err != nil {
+		return errors.Wrapf(err, "invalid ratio: %s", args["ratio"])
+	}
+
+	fallbackPercent, err := utils.ParseNumberArgFloat(args["fallback_percent"])
+	if err != nil {
+		return errors.Wrapf(err, "invalid fallback_percent: %s", args["fallback_percent"])
+	}
+
+	side := ent.position.GetLastSide()
+	price := ent.KLineWindow.GetClose()
+	current := ent.position.GetStopLossPrice()
+
+	stop := utils.TightenStop(side, price.Float64(), current, ratio, fallbackPercent)
+	if stop == current {
+		receiptFrom(ctx).StopLoss = current
+		return nil
+	}
+
+	value := ent.snapTrigger(fixedpoint.NewFromFloat(stop), price, side == PositionSideLong)
+	if err := ent.updateStopLoss(ctx, value); err != nil {
+		return err
+	}
+
+	log.WithField("from", current).WithField("to", value.Float64()).Info("stop loss tightened")
+	receiptFrom(ctx).StopLoss = value.Float64()
+	return nil
+}
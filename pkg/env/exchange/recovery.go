@@ -0,0 +1,52 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// OnExchangeErrors registers a callback run when repeated exchange errors open the circuit breaker
+func (ent *ExchangeEntity) OnExchangeErrors(fn func()) {
+	ent.retry.Breaker.OnOpen(fn)
+}
+
+// RecoveryReport collects the open position, its stop-loss and take-profit and the open orders. The orders
+// are queried once without retries, since the exchange may be the reason for the report.
+func (ent *ExchangeEntity) RecoveryReport(ctx context.Context, reason string) *utils.RecoveryReport {
+	report := &utils.RecoveryReport{
+		Symbol:    ent.symbol,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+
+	pos := ent.position
+	if pos != nil && !pos.IsClosed() && !pos.Dust {
+		report.Side = pos.GetLastSide()
+		report.Quantity = pos.GetBase().Abs().Float64()
+		report.AverageCost = pos.AverageCost.Float64()
+		report.StopLoss = pos.GetStopLossPrice()
+		if pos.TpTriggerPx != nil {
+			report.TakeProfit = pos.TpTriggerPx.Float64()
+		}
+	}
+
+	if ent.venue == nil {
+		return report
+	}
+
+	orders, err := ent.venue.QueryOpenOrders(ctx, ent.symbol)
+	if err != nil {
+		report.OrdersErr = err.Error()
+		return report
+	}
+
+	for _, order := range orders {
+		report.OpenOrders = append(report.OpenOrders, fmt.Sprintf("#%d %s %s %s at %s",
+			order.OrderID, order.Side, order.Type, order.Quantity.String(), order.Price.String()))
+	}
+
+	return report
+}
@@ -0,0 +1,44 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestRecoveryReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	stop := fixedpoint.NewFromFloat(48000)
+	position := &types.Position{
+		Symbol:      "BTCUSDT",
+		Base:        fixedpoint.NewFromFloat(0.5),
+		AverageCost: fixedpoint.NewFromFloat(50000),
+		SlTriggerPx: &stop,
+	}
+
+	ent := &ExchangeEntity{
+		symbol:   "BTCUSDT",
+		position: NewPositionX(position),
+		venue:    NewRestVenue(config.VenueConfig{BaseURL: server.URL, Timeout: types.Duration(time.Second)}, "BTCUSDT", position),
+	}
+
+	report := ent.RecoveryReport(context.Background(), "panic")
+	assert.Equal(t, "long", report.Side)
+	assert.Equal(t, 0.5, report.Quantity)
+	assert.Equal(t, 50000.0, report.AverageCost)
+	assert.Equal(t, 48000.0, report.StopLoss)
+	assert.Equal(t, 0.0, report.TakeProfit)
+	assert.NotEmpty(t, report.OrdersErr)
+}
@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// newRetryPolicy builds the retry policy of exchange calls from the config, with defaults
+func newRetryPolicy(cfg config.RetryConfig) *utils.RetryPolicy {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.BaseDelay == 0 {
+		cfg.BaseDelay = types.Duration(500 * time.Millisecond)
+	}
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = types.Duration(10 * time.Second)
+	}
+	if cfg.Jitter <= 0 || cfg.Jitter > 1 {
+		cfg.Jitter = 0.5
+	}
+	if cfg.AttemptTimeout == 0 {
+		cfg.AttemptTimeout = types.Duration(20 * time.Second)
+	}
+	if cfg.BudgetPerMinute == 0 {
+		cfg.BudgetPerMinute = 20
+	}
+	if cfg.BreakerFailures == 0 {
+		cfg.BreakerFailures = 5
+	}
+	if cfg.BreakerCooldown == 0 {
+		cfg.BreakerCooldown = types.Duration(time.Minute)
+	}
+
+	return &utils.RetryPolicy{
+		MaxAttempts:    cfg.MaxAttempts,
+		BaseDelay:      cfg.BaseDelay.Duration(),
+		MaxDelay:       cfg.MaxDelay.Duration(),
+		Jitter:         cfg.Jitter,
+		AttemptTimeout: cfg.AttemptTimeout.Duration(),
+		Budget:         utils.NewRetryBudget(cfg.BudgetPerMinute, time.Minute),
+		Breaker:        utils.NewCircuitBreaker(cfg.BreakerFailures, cfg.BreakerCooldown.Duration()),
+	}
+}
+
+// submitOrders submits the orders through the execution venue, retrying rate-limit rejections
+func (s *ExchangeEntity) submitOrders(ctx context.Context, orderForms ...types.SubmitOrder) (types.OrderSlice, error) {
+	var createdOrders types.OrderSlice
+
+	err := s.retry.Do(ctx, utils.RetryRateLimited, func(ctx context.Context) error {
+		orders, err := s.venue.SubmitOrders(ctx, orderForms...)
+		createdOrders = orders
+		return err
+	})
+
+	return createdOrders, err
+}
+
+// queryPositionInfo queries the stop-loss and take-profit of the position, retrying transient errors
+func (s *ExchangeEntity) queryPositionInfo(ctx context.Context, service types.ExchangePositionUpdateService, symbol string) (*types.PositionInfo, error) {
+	var posInfo *types.PositionInfo
+
+	err := s.retry.Do(ctx, utils.RetryTransient, func(ctx context.Context) error {
+		info, err := service.QueryPositionInfo(ctx, symbol)
+		posInfo = info
+		return err
+	})
+
+	return posInfo, err
+}
+
+// quoteQuantity queries the leveraged quote balance available for entries, retrying transient errors, and
+// scales it down while entries are throttled
+func (s *ExchangeEntity) quoteQuantity(ctx context.Context, leverage fixedpoint.Value) (fixedpoint.Value, error) {
+	quoteQty := fixedpoint.Zero
+
+	err := s.retry.Do(ctx, utils.RetryTransient, func(ctx context.Context) error {
+		qty, err := bbgo.CalculateQuoteQuantity(ctx, s.session, s.position.Market.QuoteCurrency, leverage)
+		quoteQty = qty
+		return err
+	})
+
+	if scale, ok := s.sizeScale.Load().(float64); ok && scale > 0 && scale < 1 {
+		quoteQty = quoteQty.Mul(fixedpoint.NewFromFloat(scale))
+	}
+
+	return quoteQty, err
+}
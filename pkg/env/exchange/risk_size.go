@@ -0,0 +1,54 @@
+package exchange
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// RiskSizeOpt sizes an entry by the risk per trade or a share of the balance instead of the full leveraged balance
+type RiskSizeOpt struct {
+	Quantity fixedpoint.Value // Base quantity
+	Notional fixedpoint.Value // Quote quantity, market buys are sized in quote
+}
+
+// riskSize translates the risk_percent arg of an entry into a position size from the entry price and the
+// stop-loss distance, clamped to the max risk percent and the leveraged balance
+func (s *ExchangeEntity) riskSize(ctx context.Context, riskArg string, entryPrice fixedpoint.Value, stopLoss float64) (*utils.RiskSize, error) {
+	// The risk is in percent with or without the sign
+	arg, err := utils.ParseNumberArg(riskArg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid risk_percent: %s", riskArg)
+	}
+	riskPercent := arg.Float64()
+
+	if stopLoss <= 0 {
+		return nil, errors.New("risk_percent requires a stop_loss_trigger_price")
+	}
+
+	leverage := s.entryLeverage()
+	quoteQty, err := s.quoteQuantity(ctx, leverage)
+	if err != nil {
+		return nil, errors.Wrap(err, "calculate quote quantity error")
+	}
+
+	maxRiskPercent := s.cfg.RiskSizing.MaxRiskPercent
+	if maxRiskPercent <= 0 {
+		maxRiskPercent = 2
+	}
+
+	return utils.ComputeRiskSize(utils.RiskSizeParams{
+		Equity:         quoteQty.Div(leverage).Float64(),
+		RiskPercent:    riskPercent,
+		MaxRiskPercent: maxRiskPercent,
+		EntryPrice:     entryPrice.Float64(),
+		StopLoss:       stopLoss,
+		// mirrors calculateQuantity, which keeps a 1% buffer on shorts
+		MaxNotional: quoteQty.Float64() * 0.99,
+		MinQuantity: s.position.Market.MinQuantity.Float64(),
+	})
+}
@@ -0,0 +1,159 @@
+package exchange
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// ExecutionVenue is where the exchange entity places its orders, decoupled from the bbgo order executor so
+// exchanges bbgo does not support well can be traded through another adapter
+type ExecutionVenue interface {
+	SubmitOrders(ctx context.Context, orderForms ...types.SubmitOrder) (types.OrderSlice, error)
+	QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error)
+	CancelOrders(ctx context.Context, orders ...types.Order) error
+	QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error)
+
+	// QueryPosition returns the base quantity the venue reports for the symbol, negative for a short
+	QueryPosition(ctx context.Context, symbol string) (fixedpoint.Value, error)
+
+	// QueryServerTime returns the clock of the venue
+	QueryServerTime(ctx context.Context) (time.Time, error)
+
+	// OnPositionUpdate registers a callback called when fills update the position
+	OnPositionUpdate(cb func(position *types.Position))
+}
+
+// NewExecutionVenue creates the venue selected by the config
+func NewExecutionVenue(cfg config.VenueConfig, symbol string, session *bbgo.ExchangeSession, orderExecutor *bbgo.GeneralOrderExecutor, position *types.Position) (ExecutionVenue, error) {
+	switch cfg.Type {
+	case "", config.VenueTypeBbgo:
+		return &bbgoVenue{
+			session:       session,
+			exchange:      session.Exchange,
+			orderExecutor: orderExecutor,
+		}, nil
+	case config.VenueTypeRest:
+		if cfg.BaseURL == "" {
+			return nil, errors.New("the rest venue requires base_url")
+		}
+		if cfg.Symbol == "" {
+			cfg.Symbol = symbol
+		}
+		if cfg.APIKey == "" {
+			cfg.APIKey = os.Getenv("VENUE_API_KEY")
+		}
+		if cfg.Timeout == 0 {
+			cfg.Timeout = types.Duration(10 * time.Second)
+		}
+
+		return NewRestVenue(cfg, symbol, position), nil
+	default:
+		return nil, errors.Errorf("invalid venue type: %s, expected %s or %s", cfg.Type, config.VenueTypeBbgo, config.VenueTypeRest)
+	}
+}
+
+// bbgoVenue executes orders with the bbgo order executor and session exchange
+type bbgoVenue struct {
+	session       *bbgo.ExchangeSession
+	exchange      types.Exchange
+	orderExecutor *bbgo.GeneralOrderExecutor
+}
+
+func (v *bbgoVenue) SubmitOrders(ctx context.Context, orderForms ...types.SubmitOrder) (types.OrderSlice, error) {
+	return v.orderExecutor.SubmitOrders(ctx, orderForms...)
+}
+
+func (v *bbgoVenue) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	return v.exchange.QueryOpenOrders(ctx, symbol)
+}
+
+func (v *bbgoVenue) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	return v.exchange.CancelOrders(ctx, orders...)
+}
+
+func (v *bbgoVenue) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	return v.exchange.QueryTicker(ctx, symbol)
+}
+
+// serverTimeURLs are the public server time endpoints of the exchanges, bbgo does not expose their clock
+var serverTimeURLs = map[string]string{
+	"okex":    "https://www.okx.com/api/v5/public/time",
+	"binance": "https://api.binance.com/api/v3/time",
+	"bybit":   "https://api.bybit.com/v5/market/time",
+	"kucoin":  "https://api.kucoin.com/api/v1/timestamp",
+	"bitget":  "https://api.bitget.com/api/v2/public/time",
+}
+
+func (v *bbgoVenue) QueryServerTime(ctx context.Context) (time.Time, error) {
+	endpoint, ok := serverTimeURLs[string(v.exchange.Name())]
+	if !ok {
+		return time.Time{}, errors.Errorf("server time of %s not supported", v.exchange.Name())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "fetch server time")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "read server time")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, errors.Errorf("fetch server time: %s", resp.Status)
+	}
+
+	return utils.ParseServerTime(body)
+}
+
+// QueryPosition returns the net balance of the base currency, borrowed quantities count as a short
+func (v *bbgoVenue) QueryPosition(ctx context.Context, symbol string) (fixedpoint.Value, error) {
+	market, ok := v.session.Market(symbol)
+	if !ok {
+		return fixedpoint.Zero, errors.Errorf("market %s not found", symbol)
+	}
+
+	account, err := v.session.UpdateAccount(ctx)
+	if err != nil {
+		return fixedpoint.Zero, errors.Wrap(err, "update account")
+	}
+
+	balance, ok := account.Balance(market.BaseCurrency)
+	if !ok {
+		return fixedpoint.Zero, nil
+	}
+
+	return balance.Net(), nil
+}
+
+func (v *bbgoVenue) OnPositionUpdate(cb func(position *types.Position)) {
+	v.orderExecutor.TradeCollector().OnPositionUpdate(cb)
+}
+
+// orderQueryService returns the service querying single orders on the venue, if the venue supports it
+func (ent *ExchangeEntity) orderQueryService() (types.ExchangeOrderQueryService, bool) {
+	if venue, ok := ent.venue.(*bbgoVenue); ok {
+		service, ok := venue.exchange.(types.ExchangeOrderQueryService)
+		return service, ok
+	}
+
+	service, ok := ent.venue.(types.ExchangeOrderQueryService)
+	return service, ok
+}
@@ -0,0 +1,471 @@
+package exchange
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// RestVenue executes orders through a REST service exposing the ccxt unified API, e.g. a ccxt sidecar:
+//
+//	POST   /orders              create an order: {"symbol", "type", "side", "amount", "price", "params"}
+//	GET    /orders/open?symbol= list the open orders
+//	GET    /orders/{id}?symbol= fetch an order
+//	DELETE /orders/{id}?symbol= cancel an order
+//	GET    /ticker?symbol=      fetch the ticker
+//	GET    /positions?symbol=   list the open positions
+//	GET    /time                fetch the exchange time: {"time": <ms>}
+//
+// Orders, tickers and positions are ccxt order, ticker and position structures. Market buys are sized in quote
+// currency by the entity and sent as a base amount at the ask, stop-loss and take-profit prices are attached with
+// the ccxt stopLoss and takeProfit params. The position is updated with the fills reported when orders are
+// created, and the fills of resting orders when they are fetched again: with the open orders, an order, the
+// positions or after a cancel.
+type RestVenue struct {
+	client      *http.Client
+	baseURL     string
+	apiKey      string
+	symbol      string // Symbol of the strategy
+	venueSymbol string // Symbol on the REST service
+
+	position *types.Position
+
+	mu        sync.Mutex
+	callbacks []func(position *types.Position)
+	fills     map[string]restFill // Fills applied of the orders still open, by order id
+}
+
+// restFill is the part of an order already added to the position
+type restFill struct {
+	venueSymbol string
+	filled      float64
+	cost        float64
+}
+
+func NewRestVenue(cfg config.VenueConfig, symbol string, position *types.Position) *RestVenue {
+	return &RestVenue{
+		client:      &http.Client{Timeout: cfg.Timeout.Duration()},
+		baseURL:     strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:      cfg.APIKey,
+		symbol:      symbol,
+		venueSymbol: cfg.Symbol,
+		position:    position,
+		fills:       make(map[string]restFill),
+	}
+}
+
+// ccxtOrder is the ccxt unified order structure
+type ccxtOrder struct {
+	ID            string  `json:"id"`
+	ClientOrderID string  `json:"clientOrderId"`
+	Timestamp     int64   `json:"timestamp"`
+	Symbol        string  `json:"symbol"`
+	Type          string  `json:"type"`
+	Side          string  `json:"side"`
+	Price         float64 `json:"price"`
+	Average       float64 `json:"average"`
+	Amount        float64 `json:"amount"`
+	Filled        float64 `json:"filled"`
+	Status        string  `json:"status"` // open, closed, canceled, expired or rejected
+}
+
+// ccxtTicker is the ccxt unified ticker structure
+type ccxtTicker struct {
+	Timestamp  int64   `json:"timestamp"`
+	High       float64 `json:"high"`
+	Low        float64 `json:"low"`
+	Bid        float64 `json:"bid"`
+	Ask        float64 `json:"ask"`
+	Open       float64 `json:"open"`
+	Last       float64 `json:"last"`
+	BaseVolume float64 `json:"baseVolume"`
+}
+
+// ccxtPosition is the ccxt unified position structure
+type ccxtPosition struct {
+	Symbol    string  `json:"symbol"`
+	Side      string  `json:"side"` // long or short
+	Contracts float64 `json:"contracts"`
+}
+
+func (v *RestVenue) SubmitOrders(ctx context.Context, orderForms ...types.SubmitOrder) (types.OrderSlice, error) {
+	orders := make(types.OrderSlice, 0, len(orderForms))
+
+	for _, form := range orderForms {
+		amount, err := v.baseAmount(ctx, form)
+		if err != nil {
+			return orders, err
+		}
+
+		body := map[string]interface{}{
+			"symbol": v.toVenueSymbol(form.Symbol),
+			"side":   strings.ToLower(string(form.Side)),
+			"amount": amount.Float64(),
+		}
+
+		params := map[string]interface{}{}
+		if form.ClientOrderID != "" {
+			params["clientOrderId"] = form.ClientOrderID
+		}
+		if form.ReduceOnly {
+			params["reduceOnly"] = true
+		}
+		if form.StopPrice.Sign() > 0 {
+			params["stopLoss"] = map[string]interface{}{"triggerPrice": form.StopPrice.Float64()}
+		}
+		if form.TakePrice.Sign() > 0 {
+			params["takeProfit"] = map[string]interface{}{"triggerPrice": form.TakePrice.Float64()}
+		}
+
+		switch form.Type {
+		case types.OrderTypeMarket:
+			body["type"] = "market"
+		case types.OrderTypeLimit:
+			body["type"] = "limit"
+			body["price"] = form.Price.Float64()
+			if form.TimeInForce != "" {
+				params["timeInForce"] = string(form.TimeInForce)
+			}
+		default:
+			return orders, errors.Errorf("order type %s is not supported by the rest venue", form.Type)
+		}
+		body["params"] = params
+
+		var created ccxtOrder
+		if err := v.do(ctx, http.MethodPost, "/orders", nil, body, &created); err != nil {
+			return orders, errors.Wrap(err, "create order")
+		}
+
+		order := v.toOrder(created)
+		orders = append(orders, order)
+		v.applyFill(order, created)
+	}
+
+	return orders, nil
+}
+
+// baseAmount returns the ccxt amount of an order form in base currency, market buys are sized in quote currency
+func (v *RestVenue) baseAmount(ctx context.Context, form types.SubmitOrder) (fixedpoint.Value, error) {
+	if form.Type != types.OrderTypeMarket || form.Side != types.SideTypeBuy {
+		return form.Quantity, nil
+	}
+
+	ticker, err := v.QueryTicker(ctx, form.Symbol)
+	if err != nil {
+		return fixedpoint.Zero, errors.Wrap(err, "price market buy")
+	}
+
+	price := ticker.Sell
+	if price.Sign() <= 0 {
+		price = ticker.Last
+	}
+	if price.Sign() <= 0 {
+		return fixedpoint.Zero, errors.Errorf("no price to size the market buy of %s", form.Symbol)
+	}
+
+	amount := form.Quantity.Div(price)
+	if form.Market.StepSize.Sign() > 0 {
+		amount = form.Market.TruncateQuantity(amount)
+	}
+
+	return amount, nil
+}
+
+func (v *RestVenue) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	v.syncFills(ctx)
+
+	var openOrders []ccxtOrder
+	if err := v.do(ctx, http.MethodGet, "/orders/open", url.Values{"symbol": {v.toVenueSymbol(symbol)}}, nil, &openOrders); err != nil {
+		return nil, errors.Wrap(err, "fetch open orders")
+	}
+
+	orders := make([]types.Order, 0, len(openOrders))
+	for _, order := range openOrders {
+		orders = append(orders, v.toOrder(order))
+	}
+
+	return orders, nil
+}
+
+func (v *RestVenue) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, order := range orders {
+		id := order.UUID
+		if id == "" {
+			id = strconv.FormatUint(order.OrderID, 10)
+		}
+
+		query := url.Values{"symbol": {v.toVenueSymbol(order.Symbol)}}
+		if err := v.do(ctx, http.MethodDelete, "/orders/"+url.PathEscape(id), query, nil, nil); err != nil {
+			return errors.Wrapf(err, "cancel order %s", id)
+		}
+
+		// The order may have filled further before it was canceled
+		if _, err := v.fetchOrder(ctx, id, v.toVenueSymbol(order.Symbol)); err != nil {
+			log.WithError(err).WithField("orderID", id).Warn("fetch canceled order error")
+		}
+	}
+
+	return nil
+}
+
+// QueryOrder fetches an order, adding its fills since it was last seen to the position
+func (v *RestVenue) QueryOrder(ctx context.Context, q types.OrderQuery) (*types.Order, error) {
+	order, err := v.fetchOrder(ctx, q.OrderID, v.toVenueSymbol(q.Symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// fetchOrder fetches an order and applies its new fills
+func (v *RestVenue) fetchOrder(ctx context.Context, id string, venueSymbol string) (types.Order, error) {
+	var fetched ccxtOrder
+	if err := v.do(ctx, http.MethodGet, "/orders/"+url.PathEscape(id), url.Values{"symbol": {venueSymbol}}, nil, &fetched); err != nil {
+		return types.Order{}, errors.Wrapf(err, "fetch order %s", id)
+	}
+
+	order := v.toOrder(fetched)
+	v.applyFill(order, fetched)
+	return order, nil
+}
+
+// syncFills fetches the orders still open when last seen, so their fills reach the position
+func (v *RestVenue) syncFills(ctx context.Context) {
+	v.mu.Lock()
+	pending := make(map[string]string, len(v.fills))
+	for id, fill := range v.fills {
+		pending[id] = fill.venueSymbol
+	}
+	v.mu.Unlock()
+
+	for id, venueSymbol := range pending {
+		if _, err := v.fetchOrder(ctx, id, venueSymbol); err != nil {
+			log.WithError(err).WithField("orderID", id).Warn("sync order fills error")
+		}
+	}
+}
+
+func (v *RestVenue) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	var ticker ccxtTicker
+	if err := v.do(ctx, http.MethodGet, "/ticker", url.Values{"symbol": {v.toVenueSymbol(symbol)}}, nil, &ticker); err != nil {
+		return nil, errors.Wrap(err, "fetch ticker")
+	}
+
+	return &types.Ticker{
+		Time:   time.UnixMilli(ticker.Timestamp),
+		Volume: fixedpoint.NewFromFloat(ticker.BaseVolume),
+		Last:   fixedpoint.NewFromFloat(ticker.Last),
+		Open:   fixedpoint.NewFromFloat(ticker.Open),
+		High:   fixedpoint.NewFromFloat(ticker.High),
+		Low:    fixedpoint.NewFromFloat(ticker.Low),
+		Buy:    fixedpoint.NewFromFloat(ticker.Bid),
+		Sell:   fixedpoint.NewFromFloat(ticker.Ask),
+	}, nil
+}
+
+func (v *RestVenue) QueryPosition(ctx context.Context, symbol string) (fixedpoint.Value, error) {
+	v.syncFills(ctx)
+
+	var positions []ccxtPosition
+	if err := v.do(ctx, http.MethodGet, "/positions", url.Values{"symbol": {v.toVenueSymbol(symbol)}}, nil, &positions); err != nil {
+		return fixedpoint.Zero, errors.Wrap(err, "fetch positions")
+	}
+
+	base := 0.0
+	for _, position := range positions {
+		if position.Side == "short" {
+			base -= position.Contracts
+		} else {
+			base += position.Contracts
+		}
+	}
+
+	return fixedpoint.NewFromFloat(base), nil
+}
+
+func (v *RestVenue) QueryServerTime(ctx context.Context) (time.Time, error) {
+	var serverTime struct {
+		Time int64 `json:"time"`
+	}
+	if err := v.do(ctx, http.MethodGet, "/time", nil, nil, &serverTime); err != nil {
+		return time.Time{}, errors.Wrap(err, "fetch time")
+	}
+
+	return time.UnixMilli(serverTime.Time), nil
+}
+
+func (v *RestVenue) OnPositionUpdate(cb func(position *types.Position)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.callbacks = append(v.callbacks, cb)
+}
+
+// applyFill adds the part of an order filled since it was last seen to the position, and keeps tracking the
+// order while it is open
+func (v *RestVenue) applyFill(order types.Order, fetched ccxtOrder) {
+	price := fetched.Average
+	if price <= 0 {
+		price = fetched.Price
+	}
+
+	venueSymbol := fetched.Symbol
+	if venueSymbol == "" {
+		venueSymbol = v.venueSymbol
+	}
+
+	v.mu.Lock()
+	last := v.fills[fetched.ID]
+	if fetched.Status == "open" {
+		v.fills[fetched.ID] = restFill{
+			venueSymbol: venueSymbol,
+			filled:      fetched.Filled,
+			cost:        price * fetched.Filled,
+		}
+	} else {
+		delete(v.fills, fetched.ID)
+	}
+	callbacks := append([]func(position *types.Position){}, v.callbacks...)
+	v.mu.Unlock()
+
+	filled := fetched.Filled - last.filled
+	if v.position == nil || filled <= 0 {
+		return
+	}
+
+	// The average covers all fills, the new ones are priced by the cost they added
+	cost := price*fetched.Filled - last.cost
+
+	v.position.AddTrade(types.Trade{
+		OrderID:       order.OrderID,
+		Price:         fixedpoint.NewFromFloat(cost / filled),
+		Quantity:      fixedpoint.NewFromFloat(filled),
+		QuoteQuantity: fixedpoint.NewFromFloat(cost),
+		Symbol:        v.symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		Time:          order.UpdateTime,
+	})
+
+	for _, cb := range callbacks {
+		cb(v.position)
+	}
+}
+
+// toOrder converts a ccxt order, reported with the strategy symbol
+func (v *RestVenue) toOrder(order ccxtOrder) types.Order {
+	side := types.SideTypeBuy
+	if strings.EqualFold(order.Side, "sell") {
+		side = types.SideTypeSell
+	}
+
+	orderType := types.OrderTypeLimit
+	if strings.EqualFold(order.Type, "market") {
+		orderType = types.OrderTypeMarket
+	}
+
+	status := types.OrderStatusNew
+	switch order.Status {
+	case "open":
+		if order.Filled > 0 {
+			status = types.OrderStatusPartiallyFilled
+		}
+	case "closed":
+		status = types.OrderStatusFilled
+	case "canceled", "expired":
+		status = types.OrderStatusCanceled
+	case "rejected":
+		status = types.OrderStatusRejected
+	}
+
+	// ccxt ids are strings, numeric ones are kept as the order id too
+	orderID, _ := strconv.ParseUint(order.ID, 10, 64)
+	created := types.Time(time.UnixMilli(order.Timestamp))
+
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        v.symbol,
+			Side:          side,
+			Type:          orderType,
+			Quantity:      fixedpoint.NewFromFloat(order.Amount),
+			Price:         fixedpoint.NewFromFloat(order.Price),
+		},
+		OrderID:          orderID,
+		UUID:             order.ID,
+		Status:           status,
+		ExecutedQuantity: fixedpoint.NewFromFloat(order.Filled),
+		IsWorking:        order.Status == "open",
+		CreationTime:     created,
+		UpdateTime:       created,
+	}
+}
+
+func (v *RestVenue) toVenueSymbol(symbol string) string {
+	if symbol == v.symbol {
+		return v.venueSymbol
+	}
+
+	return symbol
+}
+
+// do sends a JSON request to the REST service and decodes the response into out, if not nil
+func (v *RestVenue) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	endpoint := v.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "encode request")
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+v.apiKey)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "read response")
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("%s %s: %s %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	return errors.Wrap(json.Unmarshal(data, out), "decode response")
+}
@@ -0,0 +1,163 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestRestVenue(t *testing.T) {
+	var created map[string]interface{}
+	var cancelled string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/orders":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.Write([]byte(`{"id": "123", "symbol": "BTC/USDT", "type": "market", "side": "buy", "amount": 0.01, "filled": 0.01, "average": 50000, "status": "closed"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/orders/open":
+			assert.Equal(t, "BTC/USDT", r.URL.Query().Get("symbol"))
+			w.Write([]byte(`[{"id": "abc", "symbol": "BTC/USDT", "type": "limit", "side": "sell", "price": 52000, "amount": 0.01, "filled": 0.004, "status": "open"}]`))
+		case r.Method == http.MethodDelete:
+			cancelled = r.URL.Path
+		case r.URL.Path == "/ticker":
+			w.Write([]byte(`{"last": 50100, "bid": 50099, "ask": 50101}`))
+		case r.URL.Path == "/time":
+			w.Write([]byte(`{"time": 1700000000123}`))
+		case r.URL.Path == "/positions":
+			w.Write([]byte(`[{"symbol": "BTC/USDT", "side": "short", "contracts": 0.02}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	position := &types.Position{Symbol: "BTCUSDT"}
+	venue := NewRestVenue(config.VenueConfig{BaseURL: server.URL, Symbol: "BTC/USDT", APIKey: "secret", Timeout: types.Duration(time.Second)}, "BTCUSDT", position)
+
+	updates := 0
+	venue.OnPositionUpdate(func(position *types.Position) {
+		updates++
+	})
+
+	// Market buys are sized in quote currency and bought at the ask
+	ctx := context.Background()
+	orders, err := venue.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(501.01),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "BTC/USDT", created["symbol"])
+	assert.Equal(t, "market", created["type"])
+	assert.InDelta(t, 0.01, created["amount"], 1e-8)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, uint64(123), orders[0].OrderID)
+	assert.Equal(t, types.OrderStatusFilled, orders[0].Status)
+	assert.Equal(t, 1, updates)
+	assert.Equal(t, 0.01, position.GetBase().Float64())
+
+	_, err = venue.SubmitOrders(ctx, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeStopMarket})
+	assert.Error(t, err)
+
+	open, err := venue.QueryOpenOrders(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, open, 1)
+	assert.Equal(t, "BTCUSDT", open[0].Symbol)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, open[0].Status)
+
+	assert.NoError(t, venue.CancelOrders(ctx, open...))
+	assert.Equal(t, "/orders/abc", cancelled)
+
+	ticker, err := venue.QueryTicker(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 50100.0, ticker.Last.Float64())
+
+	base, err := venue.QueryPosition(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, -0.02, base.Float64())
+
+	serverTime, err := venue.QueryServerTime(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, time.UnixMilli(1700000000123), serverTime)
+
+	_, err = NewExecutionVenue(config.VenueConfig{Type: config.VenueTypeRest}, "BTCUSDT", nil, nil, position)
+	assert.Error(t, err)
+}
+
+func TestRestVenueStopsAndFills(t *testing.T) {
+	var created map[string]interface{}
+	fetches := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/orders":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.Write([]byte(`{"id": "7", "symbol": "BTC/USDT", "type": "limit", "side": "buy", "price": 52000, "amount": 0.01, "filled": 0, "status": "open"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/orders/7":
+			assert.Equal(t, "BTC/USDT", r.URL.Query().Get("symbol"))
+			fetches++
+			if fetches == 1 {
+				w.Write([]byte(`{"id": "7", "symbol": "BTC/USDT", "type": "limit", "side": "buy", "price": 52000, "amount": 0.01, "filled": 0.004, "average": 52000, "status": "open"}`))
+			} else {
+				w.Write([]byte(`{"id": "7", "symbol": "BTC/USDT", "type": "limit", "side": "buy", "price": 52000, "amount": 0.01, "filled": 0.01, "average": 51000, "status": "closed"}`))
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/orders/open":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	position := &types.Position{Symbol: "BTCUSDT"}
+	venue := NewRestVenue(config.VenueConfig{BaseURL: server.URL, Symbol: "BTC/USDT", Timeout: types.Duration(time.Second)}, "BTCUSDT", position)
+
+	ctx := context.Background()
+	orders, err := venue.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:    "BTCUSDT",
+		Side:      types.SideTypeBuy,
+		Type:      types.OrderTypeLimit,
+		Quantity:  fixedpoint.NewFromFloat(0.01),
+		Price:     fixedpoint.NewFromFloat(52000),
+		StopPrice: fixedpoint.NewFromFloat(49000),
+		TakePrice: fixedpoint.NewFromFloat(56000),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	// The stop-loss and take-profit are attached as ccxt params
+	params := created["params"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"triggerPrice": 49000.0}, params["stopLoss"])
+	assert.Equal(t, map[string]interface{}{"triggerPrice": 56000.0}, params["takeProfit"])
+	assert.InDelta(t, 0.01, created["amount"], 1e-8)
+	assert.Equal(t, 0.0, position.GetBase().Float64())
+
+	// Fills of the resting order reach the position when it is fetched again
+	order, err := venue.QueryOrder(ctx, types.OrderQuery{Symbol: "BTCUSDT", OrderID: "7"})
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.InDelta(t, 0.004, position.GetBase().Float64(), 1e-8)
+
+	_, err = venue.QueryOpenOrders(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.01, position.GetBase().Float64(), 1e-8)
+	assert.InDelta(t, 51000, position.AverageCost.Float64(), 1e-4)
+
+	// A closed order is no longer fetched
+	_, err = venue.QueryOpenOrders(ctx, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+}
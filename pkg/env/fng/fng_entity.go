@@ -37,6 +37,11 @@ func (entity *FearAndGreedEntity) HandleCommand(ctx context.Context, cmd string,
 	return nil
 }
 
+// Restartable reports that Run is a self-contained polling loop, restarted after a panic
+func (entity *FearAndGreedEntity) Restartable() bool {
+	return true
+}
+
 func (entity *FearAndGreedEntity) Run(ctx context.Context, ch chan types.IEvent) {
 	timer := time.NewTimer(entity.delay)
 	ticker := time.NewTicker(entity.interval)
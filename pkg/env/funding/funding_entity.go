@@ -0,0 +1,103 @@
+package funding
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yubing744/trading-gpt/pkg/apis/okx"
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+var log = logrus.WithField("entity", "funding")
+
+// FundingRateEntity periodically emits the perpetual funding rate of the traded pair
+type FundingRateEntity struct {
+	config *config.FundingRateConfig
+	client *okx.OKXClient
+	delay  time.Duration
+
+	lastRate *float64
+}
+
+func NewFundingRateEntity(cfg *config.FundingRateConfig) *FundingRateEntity {
+	opts := []okx.Option{
+		okx.WithTimeout(time.Second * 20),
+	}
+	if cfg.BaseURL != "" {
+		opts = append(opts, okx.WithBaseURL(cfg.BaseURL))
+	}
+
+	return &FundingRateEntity{
+		config: cfg,
+		client: okx.NewOKXClient(opts...),
+		delay:  time.Minute,
+	}
+}
+
+func (entity *FundingRateEntity) GetID() string {
+	return "funding"
+}
+
+func (entity *FundingRateEntity) Actions() []*types.ActionDesc {
+	return nil
+}
+
+func (entity *FundingRateEntity) HandleCommand(ctx context.Context, cmd string, args map[string]string) error {
+	return nil
+}
+
+// Restartable reports that Run is a self-contained polling loop, restarted after a panic
+func (entity *FundingRateEntity) Restartable() bool {
+	return true
+}
+
+func (entity *FundingRateEntity) Run(ctx context.Context, ch chan types.IEvent) {
+	timer := time.NewTimer(entity.delay)
+	ticker := time.NewTicker(entity.config.Interval.Duration())
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("funding entity done")
+			return
+		case <-timer.C:
+			entity.update(ctx, ch)
+		case <-ticker.C:
+			entity.update(ctx, ch)
+		}
+	}
+}
+
+func (entity *FundingRateEntity) update(ctx context.Context, ch chan types.IEvent) {
+	funding, err := entity.fetchFunding(ctx)
+	if err != nil {
+		log.WithError(err).Error("update funding rate error")
+		return
+	}
+
+	log.WithField("funding", funding).Debug("update funding rate")
+
+	ch <- NewFundingRateEvent(funding)
+}
+
+func (entity *FundingRateEntity) fetchFunding(ctx context.Context) (*FundingRate, error) {
+	rate, err := entity.client.GetFundingRate(ctx, entity.config.InstID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get funding rate error")
+	}
+
+	funding := &FundingRate{
+		InstID:      rate.InstID,
+		Rate:        rate.Rate,
+		Previous:    entity.lastRate,
+		Interval:    rate.Interval,
+		FundingTime: rate.FundingTime,
+	}
+	entity.lastRate = &rate.Rate
+
+	return funding, nil
+}
@@ -0,0 +1,45 @@
+package funding
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestFundingRateEntity_FetchFunding(t *testing.T) {
+	rate := "0.0003"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "SUI-USDT-SWAP", r.URL.Query().Get("instId"))
+		w.Write([]byte(`{"code":"0","msg":"","data":[{"instId":"SUI-USDT-SWAP","fundingRate":"` + rate + `","fundingTime":"1700000000000","nextFundingTime":"1700028800000"}]}`))
+	}))
+	defer server.Close()
+
+	entity := NewFundingRateEntity(&config.FundingRateConfig{BaseURL: server.URL, InstID: "SUI-USDT-SWAP"})
+	funding, err := entity.fetchFunding(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0.0003, funding.Rate)
+	assert.Nil(t, funding.Previous)
+	assert.InDelta(t, 32.85, funding.Annualized(), 1e-9)
+
+	prompts := NewFundingRateEvent(funding).ToPrompts()
+	assert.Contains(t, prompts[0], "Perpetual funding rate of SUI-USDT-SWAP: +0.0300% per 8h (+32.85% annualized), next settlement at 2023-11-14 22:13 UTC")
+	assert.Contains(t, prompts[0], "Longs pay shorts")
+
+	rate = "-0.0001"
+	funding, err = entity.fetchFunding(context.Background())
+	assert.NoError(t, err)
+
+	if assert.NotNil(t, funding.Previous) {
+		assert.Equal(t, 0.0003, *funding.Previous)
+	}
+
+	prompts = NewFundingRateEvent(funding).ToPrompts()
+	assert.Contains(t, prompts[0], "-0.0100% per 8h (-10.95% annualized), previously +0.0300%")
+	assert.Contains(t, prompts[0], "Shorts pay longs")
+}
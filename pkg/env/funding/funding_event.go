@@ -0,0 +1,73 @@
+package funding
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+const EventFundingRateChanged = "funding_rate_changed"
+
+// FundingRate is a snapshot of the perpetual funding rate
+type FundingRate struct {
+	InstID      string
+	Rate        float64       // Rate paid by longs to shorts per funding period, negative when shorts pay
+	Previous    *float64      // Rate of the previous poll, nil for the first one
+	Interval    time.Duration // Funding period
+	FundingTime time.Time     // Next settlement time, zero when unknown
+}
+
+// Annualized returns the rate per year in percent
+func (f *FundingRate) Annualized() float64 {
+	interval := f.Interval
+	if interval <= 0 {
+		interval = time.Hour * 8
+	}
+
+	return f.Rate * float64(time.Hour*24*365) / float64(interval) * 100
+}
+
+// FundingRateEvent carries the funding rate snapshot to the agent
+type FundingRateEvent struct {
+	types.Event
+	funding *FundingRate
+}
+
+func NewFundingRateEvent(funding *FundingRate) *FundingRateEvent {
+	return &FundingRateEvent{
+		Event:   *types.NewEvent(EventFundingRateChanged, funding),
+		funding: funding,
+	}
+}
+
+func (e *FundingRateEvent) ToPrompts() []string {
+	f := e.funding
+	sb := strings.Builder{}
+
+	period := f.Interval.String()
+	if f.Interval%time.Hour == 0 {
+		period = fmt.Sprintf("%dh", f.Interval/time.Hour)
+	}
+
+	sb.WriteString(fmt.Sprintf("Perpetual funding rate of %s: %+.4f%% per %s (%+.2f%% annualized)", f.InstID, f.Rate*100, period, f.Annualized()))
+	if f.Previous != nil {
+		sb.WriteString(fmt.Sprintf(", previously %+.4f%%", *f.Previous*100))
+	}
+	if !f.FundingTime.IsZero() {
+		sb.WriteString(fmt.Sprintf(", next settlement at %s", f.FundingTime.UTC().Format("2006-01-02 15:04 UTC")))
+	}
+	sb.WriteString("\n")
+
+	switch {
+	case f.Rate > 0:
+		sb.WriteString("Longs pay shorts: longs are crowded, which favors shorts on reversals and makes holding longs costly.")
+	case f.Rate < 0:
+		sb.WriteString("Shorts pay longs: shorts are crowded, which favors longs on squeezes and makes holding shorts costly.")
+	default:
+		sb.WriteString("Funding is neutral.")
+	}
+
+	return []string{sb.String()}
+}
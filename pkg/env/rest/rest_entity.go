@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils/jsonpath"
+	"github.com/yubing744/trading-gpt/pkg/utils/xtemplate"
+)
+
+var log = logrus.WithField("entity", "rest")
+
+// RESTEntity polls user-configured JSON endpoints and emits their formatted data as events
+type RESTEntity struct {
+	config *config.RESTEntityConfig
+	client *http.Client
+}
+
+func NewRESTEntity(cfg *config.RESTEntityConfig) *RESTEntity {
+	return &RESTEntity{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout.Duration(),
+		},
+	}
+}
+
+// GetID returns the entity's id.
+func (e *RESTEntity) GetID() string {
+	return "rest"
+}
+
+// Actions returns a list of action descriptors.
+func (e *RESTEntity) Actions() []*types.ActionDesc {
+	return []*types.ActionDesc{}
+}
+
+// HandleCommand handles a command directed at the entity.
+func (e *RESTEntity) HandleCommand(ctx context.Context, cmd string, args map[string]string) error {
+	return nil
+}
+
+// Run polls every source on its own interval until the context is done.
+func (e *RESTEntity) Run(ctx context.Context, ch chan types.IEvent) {
+	log.Info("rest_run")
+
+	for _, item := range e.config.Sources {
+		log.WithField("item", item.Name).Info("rest_run_item")
+
+		go func(item *config.RESTSourceItem) {
+			ticker := time.NewTicker(item.Interval.Duration())
+			defer ticker.Stop()
+
+			e.poll(ctx, ch, item)
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					e.poll(ctx, ch, item)
+				}
+			}
+		}(item)
+	}
+
+	<-ctx.Done()
+}
+
+func (e *RESTEntity) poll(ctx context.Context, ch chan types.IEvent, item *config.RESTSourceItem) {
+	content, err := e.fetch(ctx, item)
+	if err != nil {
+		log.WithError(err).WithField("item", item.Name).Error("rest_poll_error")
+		return
+	}
+
+	ch <- NewRESTEvent(item.Name, item.Description, content)
+}
+
+// fetch requests the endpoint, extracts the fields and renders them with the template
+func (e *RESTEntity) fetch(ctx context.Context, item *config.RESTSourceItem) (string, error) {
+	method := item.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if item.Body != "" {
+		body = strings.NewReader(os.ExpandEnv(item.Body))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, os.ExpandEnv(item.URL), body)
+	if err != nil {
+		return "", errors.Wrap(err, "new request error")
+	}
+
+	req.Header.Set("Accept", "application/json")
+	for key, val := range item.Headers {
+		req.Header.Set(key, os.ExpandEnv(val))
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "request error")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(resp.Body)
+		return "", errors.Errorf("response error, status code: %d, detail: %s", resp.StatusCode, detail)
+	}
+
+	var doc interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", errors.Wrap(err, "decode response error")
+	}
+
+	fields := make(map[string]interface{}, len(item.Fields))
+	for name, path := range item.Fields {
+		val, err := jsonpath.Get(doc, path)
+		if err != nil {
+			return "", errors.Wrapf(err, "extract field %s error", name)
+		}
+		fields[name] = val
+	}
+
+	if item.Template == "" {
+		return formatFields(fields), nil
+	}
+
+	content, err := xtemplate.Render(item.Template, fields)
+	if err != nil {
+		return "", errors.Wrap(err, "render template error")
+	}
+
+	return content, nil
+}
+
+// formatFields lists the fields as "name: value" lines sorted by name
+func formatFields(fields map[string]interface{}) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s: %v", name, fields[name]))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestRESTEntity_Fetch(t *testing.T) {
+	t.Setenv("REST_TEST_TOKEN", "secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"data":[{"fundingRate":0.0001,"symbol":"BTCUSDT"}]}`))
+	}))
+	defer server.Close()
+
+	entity := NewRESTEntity(&config.RESTEntityConfig{})
+	item := &config.RESTSourceItem{
+		Name:    "funding_changed",
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer ${REST_TEST_TOKEN}"},
+		Fields: map[string]string{
+			"symbol": "$.data[0].symbol",
+			"rate":   "$.data[0].fundingRate",
+		},
+		Template: "Funding rate of {{.symbol}} is {{.rate}}",
+	}
+
+	content, err := entity.fetch(context.Background(), item)
+	assert.NoError(t, err)
+	assert.Equal(t, "Funding rate of BTCUSDT is 0.0001", content)
+
+	item.Template = ""
+	content, err = entity.fetch(context.Background(), item)
+	assert.NoError(t, err)
+	assert.Equal(t, "rate: 0.0001\nsymbol: BTCUSDT", content)
+
+	item.Fields["missing"] = "$.data[1].symbol"
+	_, err = entity.fetch(context.Background(), item)
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// RESTEvent carries the formatted data of a REST source
+type RESTEvent struct {
+	types.Event
+	title   string
+	Content string
+}
+
+func NewRESTEvent(name string, title string, content string) *RESTEvent {
+	return &RESTEvent{
+		Event:   *types.NewEvent(name, content),
+		title:   title,
+		Content: content,
+	}
+}
+
+// ToPrompts includes the title and formatted content in the prompts
+func (e *RESTEvent) ToPrompts() []string {
+	sb := strings.Builder{}
+
+	if e.title != "" {
+		sb.WriteString(fmt.Sprintf("%s\n", e.title))
+	}
+	sb.WriteString(e.Content)
+
+	return []string{sb.String()}
+}
@@ -0,0 +1,496 @@
+package spread
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+var log = logrus.WithField("entity", "spread")
+
+// LegExchange queries prices and submits the orders of a leg, implemented by bbgo exchanges
+type LegExchange interface {
+	QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error)
+	SubmitOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error)
+}
+
+// Leg is an instrument a spread leg can trade
+type Leg struct {
+	Name     string
+	Market   types.Market
+	Exchange LegExchange
+}
+
+// legOrder is the order of one leg, with the price it is expected to fill at
+type legOrder struct {
+	leg   *Leg
+	form  types.SubmitOrder
+	price float64
+}
+
+// SpreadEntity opens and closes two coordinated legs as one spread position and tracks their combined PnL
+type SpreadEntity struct {
+	cfg  *config.SpreadConfig
+	legs map[string]*Leg
+
+	// set while another instance trades the account
+	observeOnly atomic.Bool
+
+	mu       sync.Mutex
+	position *SpreadPosition
+	events   chan ttypes.IEvent
+}
+
+func NewSpreadEntity(cfg *config.SpreadConfig, legs []*Leg) *SpreadEntity {
+	entity := &SpreadEntity{
+		cfg:    cfg,
+		legs:   make(map[string]*Leg, len(legs)),
+		events: make(chan ttypes.IEvent, 1),
+	}
+
+	for _, leg := range legs {
+		entity.legs[leg.Name] = leg
+	}
+
+	return entity
+}
+
+func (entity *SpreadEntity) GetID() string {
+	return "spread"
+}
+
+func (entity *SpreadEntity) Actions() []*ttypes.ActionDesc {
+	legs := entity.legNames()
+
+	return []*ttypes.ActionDesc{
+		{
+			Name:        "open_spread",
+			Description: fmt.Sprintf("Open a spread of two coordinated legs at market, e.g. long one asset and short a correlated one, or long spot and short the perp. Both legs are sent together and a filled leg is unwound if the other fails. Available legs: %s", strings.Join(legs, ", ")),
+			Args: []ttypes.ArgmentDesc{
+				{
+					Name:        "long_leg",
+					Description: "Leg to buy",
+				},
+				{
+					Name:        "short_leg",
+					Description: "Leg to sell",
+				},
+				{
+					Name:        "notional",
+					Description: fmt.Sprintf("Notional of the long leg in quote currency, at most %g", entity.cfg.MaxNotional),
+				},
+				{
+					Name:        "ratio",
+					Description: "Short notional per unit of long notional, defaults to 1 for a dollar-neutral spread",
+				},
+			},
+		},
+		{
+			Name:        "close_spread",
+			Description: "Close both legs of the open spread position at market",
+		},
+	}
+}
+
+func (entity *SpreadEntity) HandleCommand(ctx context.Context, cmd string, args map[string]string) error {
+	log.
+		WithField("cmd", cmd).
+		WithField("args", args).
+		Info("entity spread handle command")
+
+	if entity.observeOnly.Load() {
+		return errors.New("observe-only, another instance is trading this account")
+	}
+
+	switch cmd {
+	case "open_spread":
+		return entity.open(ctx, args)
+	case "close_spread":
+		return entity.close(ctx)
+	}
+
+	return errors.Errorf("unsupported command: %s", cmd)
+}
+
+// Restartable reports that Run is a self-contained polling loop, restarted after a panic
+func (entity *SpreadEntity) Restartable() bool {
+	return true
+}
+
+func (entity *SpreadEntity) Run(ctx context.Context, ch chan ttypes.IEvent) {
+	ticker := time.NewTicker(entity.cfg.Interval.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("spread entity done")
+			return
+		case evt := <-entity.events:
+			ch <- evt
+		case <-ticker.C:
+			evt, err := entity.update(ctx)
+			if err != nil {
+				log.WithError(err).Error("update spread position error")
+				continue
+			}
+
+			if evt != nil {
+				ch <- evt
+			}
+		}
+	}
+}
+
+// SetObserveOnly keeps the entity from placing orders while another instance trades
+func (entity *SpreadEntity) SetObserveOnly(observeOnly bool) {
+	entity.observeOnly.Store(observeOnly)
+}
+
+// Position returns a copy of the open spread position, nil when flat
+func (entity *SpreadEntity) Position() *SpreadPosition {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if entity.position == nil {
+		return nil
+	}
+
+	position := *entity.position
+	return &position
+}
+
+// Restore sets the spread position kept across restarts
+func (entity *SpreadEntity) Restore(position *SpreadPosition) {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if position != nil && !position.IsClosed() {
+		entity.position = position
+	}
+}
+
+func (entity *SpreadEntity) open(ctx context.Context, args map[string]string) error {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if entity.position != nil {
+		return errors.New("a spread position is already open, close it first")
+	}
+
+	longLeg, ok := entity.legs[args["long_leg"]]
+	if !ok {
+		return errors.Errorf("unknown long leg: %s", args["long_leg"])
+	}
+
+	shortLeg, ok := entity.legs[args["short_leg"]]
+	if !ok {
+		return errors.Errorf("unknown short leg: %s", args["short_leg"])
+	}
+
+	if longLeg == shortLeg {
+		return errors.New("long and short leg must differ")
+	}
+
+	notional := entity.cfg.MaxNotional
+	if arg, ok := args["notional"]; ok && arg != "" {
+		value, err := utils.ParseNumberArgFloat(arg)
+		if err != nil || value <= 0 {
+			return errors.Errorf("invalid notional: %s", arg)
+		}
+
+		if entity.cfg.MaxNotional <= 0 || value < entity.cfg.MaxNotional {
+			notional = value
+		}
+	}
+
+	if notional <= 0 {
+		return errors.New("notional required")
+	}
+
+	ratio := 1.0
+	if arg, ok := args["ratio"]; ok && arg != "" {
+		value, err := utils.ParseNumberArgFloat(arg)
+		if err != nil || value <= 0 {
+			return errors.Errorf("invalid ratio: %s", arg)
+		}
+
+		ratio = value
+	}
+
+	longPrice, err := lastPrice(ctx, longLeg)
+	if err != nil {
+		return err
+	}
+
+	shortPrice, err := lastPrice(ctx, shortLeg)
+	if err != nil {
+		return err
+	}
+
+	longQty := longLeg.Market.TruncateQuantity(fixedpoint.NewFromFloat(notional / longPrice))
+	shortQty := shortLeg.Market.TruncateQuantity(fixedpoint.NewFromFloat(notional * ratio / shortPrice))
+	if longQty.IsZero() || longQty.Compare(longLeg.Market.MinQuantity) < 0 {
+		return errors.Errorf("long leg quantity %s below the minimum of %s", longQty.String(), longLeg.Market.MinQuantity.String())
+	}
+	if shortQty.IsZero() || shortQty.Compare(shortLeg.Market.MinQuantity) < 0 {
+		return errors.Errorf("short leg quantity %s below the minimum of %s", shortQty.String(), shortLeg.Market.MinQuantity.String())
+	}
+
+	fills, err := entity.submitLegs(ctx, []*legOrder{
+		{leg: longLeg, form: orderForm(longLeg, types.SideTypeBuy, longQty, types.SideEffectTypeMarginBuy), price: longPrice},
+		{leg: shortLeg, form: orderForm(shortLeg, types.SideTypeSell, shortQty, types.SideEffectTypeMarginBuy), price: shortPrice},
+	})
+	if err != nil {
+		return err
+	}
+
+	entity.position = &SpreadPosition{
+		Long: SpreadLegPosition{
+			Leg:        longLeg.Name,
+			Symbol:     longLeg.Market.Symbol,
+			Side:       SideLong,
+			Quantity:   longQty.Float64(),
+			EntryPrice: fills[0],
+			Price:      fills[0],
+		},
+		Short: SpreadLegPosition{
+			Leg:        shortLeg.Name,
+			Symbol:     shortLeg.Market.Symbol,
+			Side:       SideShort,
+			Quantity:   shortQty.Float64(),
+			EntryPrice: fills[1],
+			Price:      fills[1],
+		},
+		Ratio:    ratio,
+		OpenedAt: time.Now(),
+	}
+	entity.position.Notional = entity.position.Long.Quantity*fills[0] + entity.position.Short.Quantity*fills[1]
+
+	log.WithField("position", entity.position.String()).Info("spread position opened")
+	entity.emit(NewSpreadEvent(EventSpreadChanged, *entity.position))
+
+	return nil
+}
+
+// close sends the closing orders of the open legs together, a leg that fails stays open for the next close
+func (entity *SpreadEntity) close(ctx context.Context) error {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if entity.position == nil {
+		return errors.New("no spread position to close")
+	}
+
+	legs := make([]*SpreadLegPosition, 0, 2)
+	orders := make([]*legOrder, 0, 2)
+	for _, legPosition := range []*SpreadLegPosition{&entity.position.Long, &entity.position.Short} {
+		if legPosition.Quantity <= 0 {
+			continue
+		}
+
+		leg, ok := entity.legs[legPosition.Leg]
+		if !ok {
+			return errors.Errorf("leg %s is no longer configured", legPosition.Leg)
+		}
+
+		side := types.SideTypeSell
+		if legPosition.Side == SideShort {
+			side = types.SideTypeBuy
+		}
+
+		legs = append(legs, legPosition)
+		orders = append(orders, &legOrder{
+			leg:   leg,
+			form:  orderForm(leg, side, fixedpoint.NewFromFloat(legPosition.Quantity), types.SideEffectTypeAutoRepay),
+			price: legPosition.Price,
+		})
+	}
+
+	fills, errs := entity.submitAll(ctx, orders)
+
+	failed := make([]string, 0)
+	for i, legPosition := range legs {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", legPosition.Leg, errs[i].Error()))
+			continue
+		}
+
+		entity.position.CloseLeg(legPosition, fills[i])
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf("close spread legs failed, retry close_spread for the remaining legs: %s", strings.Join(failed, "; "))
+	}
+
+	log.WithField("position", entity.position.String()).Info("spread position closed")
+	entity.emit(NewSpreadEvent(EventSpreadClosed, *entity.position))
+	entity.position = nil
+
+	return nil
+}
+
+// submitLegs sends the orders of all legs at once, unwinding the filled legs when any leg fails,
+// and returns the fill price of each leg
+func (entity *SpreadEntity) submitLegs(ctx context.Context, orders []*legOrder) ([]float64, error) {
+	fills, errs := entity.submitAll(ctx, orders)
+
+	failed := make([]string, 0)
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", orders[i].leg.Name, err.Error()))
+		}
+	}
+
+	if len(failed) == 0 {
+		return fills, nil
+	}
+
+	// Unwind the legs that went through, so no naked leg is left behind
+	unwinds := make([]*legOrder, 0)
+	for i, order := range orders {
+		if errs[i] != nil {
+			continue
+		}
+
+		unwind := *order
+		unwind.form.Side = oppositeSide(order.form.Side)
+		unwind.form.MarginSideEffect = types.SideEffectTypeAutoRepay
+		unwinds = append(unwinds, &unwind)
+	}
+
+	_, unwindErrs := entity.submitAll(ctx, unwinds)
+	for i, err := range unwindErrs {
+		if err != nil {
+			log.WithError(err).WithField("leg", unwinds[i].leg.Name).Error("unwind spread leg failed")
+			failed = append(failed, fmt.Sprintf("unwinding %s failed, close it manually: %s", unwinds[i].leg.Name, err.Error()))
+		}
+	}
+
+	return nil, errors.Errorf("open spread failed: %s", strings.Join(failed, "; "))
+}
+
+// submitAll sends the orders concurrently and returns the fill price or error of each
+func (entity *SpreadEntity) submitAll(ctx context.Context, orders []*legOrder) ([]float64, []error) {
+	fills := make([]float64, len(orders))
+	errs := make([]error, len(orders))
+
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		wg.Add(1)
+		go func(i int, order *legOrder) {
+			defer wg.Done()
+
+			created, err := order.leg.Exchange.SubmitOrder(ctx, order.form)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			fills[i] = order.price
+			if created != nil && created.AveragePrice.Float64() > 0 {
+				fills[i] = created.AveragePrice.Float64()
+			}
+		}(i, order)
+	}
+	wg.Wait()
+
+	return fills, errs
+}
+
+// update refreshes the leg prices of the open spread and reports its combined PnL
+func (entity *SpreadEntity) update(ctx context.Context) (ttypes.IEvent, error) {
+	position := entity.Position()
+	if position == nil {
+		return nil, nil
+	}
+
+	longPrice, err := lastPrice(ctx, entity.legs[position.Long.Leg])
+	if err != nil {
+		return nil, err
+	}
+
+	shortPrice, err := lastPrice(ctx, entity.legs[position.Short.Leg])
+	if err != nil {
+		return nil, err
+	}
+
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	// closed while the prices were queried
+	if entity.position == nil {
+		return nil, nil
+	}
+
+	entity.position.Update(longPrice, shortPrice)
+	return NewSpreadEvent(EventSpreadChanged, *entity.position), nil
+}
+
+// emit queues an event for the run loop, dropping it when one is already pending
+func (entity *SpreadEntity) emit(evt ttypes.IEvent) {
+	select {
+	case entity.events <- evt:
+	default:
+	}
+}
+
+func (entity *SpreadEntity) legNames() []string {
+	names := make([]string, 0, len(entity.legs))
+	for name, leg := range entity.legs {
+		names = append(names, fmt.Sprintf("%s (%s)", name, leg.Market.Symbol))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func lastPrice(ctx context.Context, leg *Leg) (float64, error) {
+	if leg == nil {
+		return 0, errors.New("leg is no longer configured")
+	}
+
+	ticker, err := leg.Exchange.QueryTicker(ctx, leg.Market.Symbol)
+	if err != nil {
+		return 0, errors.Wrapf(err, "query %s ticker error", leg.Name)
+	}
+
+	price := ticker.Last.Float64()
+	if price <= 0 {
+		return 0, errors.Errorf("invalid %s price: %f", leg.Name, price)
+	}
+
+	return price, nil
+}
+
+func orderForm(leg *Leg, side types.SideType, quantity fixedpoint.Value, sideEffect types.MarginOrderSideEffectType) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:           leg.Market.Symbol,
+		Market:           leg.Market,
+		Side:             side,
+		Type:             types.OrderTypeMarket,
+		Quantity:         quantity,
+		MarginSideEffect: sideEffect,
+	}
+}
+
+func oppositeSide(side types.SideType) types.SideType {
+	if side == types.SideTypeBuy {
+		return types.SideTypeSell
+	}
+
+	return types.SideTypeBuy
+}
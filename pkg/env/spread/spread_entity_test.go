@@ -0,0 +1,85 @@
+package spread
+
+import (
+	"context"
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+type fakeExchange struct {
+	price  float64
+	fail   bool
+	orders []types.SubmitOrder
+}
+
+func (f *fakeExchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	return &types.Ticker{Last: fixedpoint.NewFromFloat(f.price)}, nil
+}
+
+func (f *fakeExchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	if f.fail {
+		return nil, errors.New("insufficient balance")
+	}
+
+	f.orders = append(f.orders, order)
+	return &types.Order{SubmitOrder: order}, nil
+}
+
+func newTestEntity(btc, eth *fakeExchange) *SpreadEntity {
+	market := func(symbol string) types.Market {
+		return types.Market{Symbol: symbol, StepSize: fixedpoint.NewFromFloat(0.0001), MinQuantity: fixedpoint.NewFromFloat(0.0001)}
+	}
+
+	return NewSpreadEntity(&config.SpreadConfig{MaxNotional: 1000}, []*Leg{
+		{Name: "btc", Market: market("BTCUSDT"), Exchange: btc},
+		{Name: "eth", Market: market("ETHUSDT"), Exchange: eth},
+	})
+}
+
+func TestSpreadEntity_OpenClose(t *testing.T) {
+	btc := &fakeExchange{price: 50000}
+	eth := &fakeExchange{price: 2500}
+	entity := newTestEntity(btc, eth)
+
+	err := entity.HandleCommand(context.Background(), "open_spread", map[string]string{"long_leg": "btc", "short_leg": "eth", "notional": "500", "ratio": "2"})
+	assert.NoError(t, err)
+
+	position := entity.Position()
+	assert.NotNil(t, position)
+	assert.InDelta(t, 0.01, position.Long.Quantity, 1e-9)
+	assert.InDelta(t, 0.4, position.Short.Quantity, 1e-9)
+	assert.Equal(t, types.SideTypeSell, eth.orders[0].Side)
+
+	btc.price = 51000
+	evt, err := entity.update(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, EventSpreadChanged, evt.GetType())
+	assert.InDelta(t, 10, entity.Position().PnL(), 1e-9)
+
+	err = entity.HandleCommand(context.Background(), "close_spread", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, entity.Position())
+	assert.Equal(t, types.SideTypeBuy, eth.orders[1].Side)
+	assert.Equal(t, types.SideEffectTypeAutoRepay, eth.orders[1].MarginSideEffect)
+}
+
+func TestSpreadEntity_OpenUnwindsFilledLeg(t *testing.T) {
+	btc := &fakeExchange{price: 50000}
+	eth := &fakeExchange{price: 2500, fail: true}
+	entity := newTestEntity(btc, eth)
+
+	err := entity.HandleCommand(context.Background(), "open_spread", map[string]string{"long_leg": "btc", "short_leg": "eth"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient balance")
+	assert.Nil(t, entity.Position())
+
+	// the filled long leg was sold back
+	assert.Len(t, btc.orders, 2)
+	assert.Equal(t, types.SideTypeSell, btc.orders[1].Side)
+}
@@ -0,0 +1,37 @@
+package spread
+
+import (
+	"fmt"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+const (
+	EventSpreadChanged = "spread_changed"
+	EventSpreadClosed  = "spread_closed"
+)
+
+// SpreadEvent reports the combined PnL of the spread position
+type SpreadEvent struct {
+	types.Event
+	position SpreadPosition
+}
+
+func NewSpreadEvent(ty string, position SpreadPosition) *SpreadEvent {
+	return &SpreadEvent{
+		Event:    *types.NewEvent(ty, position),
+		position: position,
+	}
+}
+
+// ToPrompts describes the legs and the combined PnL of the spread
+func (e *SpreadEvent) ToPrompts() []string {
+	p := e.position
+
+	if e.GetType() == EventSpreadClosed {
+		return []string{fmt.Sprintf("The spread trade long %s / short %s was closed with a combined PnL of %.4f (%.2f%%).",
+			p.Long.Symbol, p.Short.Symbol, p.PnL(), p.PnLPercent())}
+	}
+
+	return []string{fmt.Sprintf("Open spread position: %s. Close both legs together with spread.close_spread.", p.String())}
+}
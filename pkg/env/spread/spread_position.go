@@ -0,0 +1,87 @@
+package spread
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	SideLong  = "long"
+	SideShort = "short"
+)
+
+// SpreadLegPosition is one leg of an open spread
+type SpreadLegPosition struct {
+	Leg        string  `json:"leg"`
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"`
+	Quantity   float64 `json:"quantity"` // Base quantity still open, zero once the leg is closed
+	EntryPrice float64 `json:"entry_price"`
+	Price      float64 `json:"price"` // Latest price
+}
+
+// PnL is the unrealized profit of the leg at the latest price
+func (l *SpreadLegPosition) PnL() float64 {
+	pnl := (l.Price - l.EntryPrice) * l.Quantity
+	if l.Side == SideShort {
+		return -pnl
+	}
+
+	return pnl
+}
+
+// SpreadPosition tracks the two legs of a spread trade as one position with a combined PnL
+type SpreadPosition struct {
+	Long     SpreadLegPosition `json:"long"`
+	Short    SpreadLegPosition `json:"short"`
+	Ratio    float64           `json:"ratio"`    // Short notional per unit of long notional at entry
+	Notional float64           `json:"notional"` // Gross notional of both legs at entry
+	Realized float64           `json:"realized"` // Profit of legs already closed
+	OpenedAt time.Time         `json:"opened_at"`
+}
+
+// Update sets the latest prices of the legs
+func (p *SpreadPosition) Update(longPrice, shortPrice float64) {
+	if longPrice > 0 {
+		p.Long.Price = longPrice
+	}
+	if shortPrice > 0 {
+		p.Short.Price = shortPrice
+	}
+}
+
+// PnL is the combined realized and unrealized profit of both legs
+func (p *SpreadPosition) PnL() float64 {
+	return p.Realized + p.Long.PnL() + p.Short.PnL()
+}
+
+// PnLPercent is the combined profit relative to the gross notional at entry
+func (p *SpreadPosition) PnLPercent() float64 {
+	if p.Notional <= 0 {
+		return 0
+	}
+
+	return p.PnL() / p.Notional * 100
+}
+
+// CloseLeg realizes the profit of a leg closed at the given price
+func (p *SpreadPosition) CloseLeg(leg *SpreadLegPosition, price float64) {
+	if price > 0 {
+		leg.Price = price
+	}
+
+	p.Realized += leg.PnL()
+	leg.Quantity = 0
+}
+
+// IsClosed returns whether both legs are closed
+func (p *SpreadPosition) IsClosed() bool {
+	return p.Long.Quantity <= 0 && p.Short.Quantity <= 0
+}
+
+func (p *SpreadPosition) String() string {
+	return fmt.Sprintf("long %g %s at %g (now %g), short %g %s at %g (now %g), ratio %g, combined PnL %.4f (%.2f%%)",
+		p.Long.Quantity, p.Long.Symbol, p.Long.EntryPrice, p.Long.Price,
+		p.Short.Quantity, p.Short.Symbol, p.Short.EntryPrice, p.Short.Price,
+		p.Ratio, p.PnL(), p.PnLPercent())
+}
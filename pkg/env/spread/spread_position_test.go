@@ -0,0 +1,33 @@
+package spread
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpreadPosition_PnL(t *testing.T) {
+	position := &SpreadPosition{
+		Long:     SpreadLegPosition{Side: SideLong, Quantity: 1, EntryPrice: 100, Price: 100},
+		Short:    SpreadLegPosition{Side: SideShort, Quantity: 2, EntryPrice: 50, Price: 50},
+		Notional: 200,
+	}
+
+	// both legs rise together, the spread is flat
+	position.Update(110, 55)
+	assert.InDelta(t, 0, position.PnL(), 1e-9)
+
+	// the long leg outperforms
+	position.Update(110, 52)
+	assert.InDelta(t, 16, position.PnL(), 1e-9)
+	assert.InDelta(t, 8, position.PnLPercent(), 1e-9)
+
+	position.CloseLeg(&position.Long, 110)
+	assert.False(t, position.IsClosed())
+	assert.InDelta(t, 10, position.Realized, 1e-9)
+	assert.InDelta(t, 16, position.PnL(), 1e-9)
+
+	position.CloseLeg(&position.Short, 52)
+	assert.True(t, position.IsClosed())
+	assert.InDelta(t, 16, position.PnL(), 1e-9)
+}
@@ -0,0 +1,140 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/env/eventlog"
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+func (s *Strategy) setupEventLog(ctx context.Context) error {
+	cfg := &s.EventLog
+	if cfg.Path == "" {
+		cfg.Path = fmt.Sprintf("memory-bank/events-%s.jsonl", s.Symbol)
+	}
+	if cfg.Replay.Path == "" {
+		cfg.Replay.Path = cfg.Path
+	}
+
+	if cfg.Replay.Enabled {
+		// The instance lock would switch a replay back to trading
+		if s.InstanceLock.Enabled {
+			return errors.New("event log replay can't be combined with the instance lock")
+		}
+
+		return s.startReplay(ctx)
+	}
+
+	if !cfg.Enabled {
+		return nil
+	}
+
+	writer, err := eventlog.NewWriter(cfg.Path)
+	if err != nil {
+		return errors.Wrap(err, "Error in open event log")
+	}
+	s.eventLog = writer
+
+	s.world.OnEvent(func(evt ttypes.IEvent) {
+		prompts := evt.ToPrompts()
+		if indicator, ok := evt.GetData().(*exchange.ExchangeIndicator); ok {
+			prompts = s.indicatorPrompts(indicator)
+		}
+
+		if err := s.eventLog.Write(evt, prompts); err != nil {
+			log.WithError(err).WithField("type", evt.GetType()).Warn("record env event failed")
+		}
+	})
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if err := s.eventLog.Close(); err != nil {
+			log.WithError(err).Warn("close event log failed")
+		}
+	})
+
+	log.WithField("path", cfg.Path).Info("Event log enabled")
+	return nil
+}
+
+// startReplay feeds the recorded events to the agent in observe-only mode, so its decisions are not executed
+func (s *Strategy) startReplay(ctx context.Context) error {
+	cfg := s.EventLog.Replay
+
+	s.observeOnly.Store(true)
+	s.exchange.SetObserveOnly(true)
+	if s.spread != nil {
+		s.spread.SetObserveOnly(true)
+	}
+
+	go func() {
+		var last time.Time
+		count := 0
+
+		err := eventlog.ReadEach(cfg.Path, func(record *eventlog.Record) error {
+			if cfg.Speed > 0 && !last.IsZero() && record.Time.After(last) {
+				wait := time.Duration(float64(record.Time.Sub(last)) / cfg.Speed)
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+			last = record.Time
+
+			s.world.Emit(replayEvent(record))
+			count++
+			return nil
+		})
+		if err != nil {
+			log.WithError(err).WithField("replayed", count).Error("event log replay failed")
+			return
+		}
+
+		log.WithField("replayed", count).Info("event log replay finished")
+	}()
+
+	log.WithField("config", cfg).Info("Event log replay enabled, trading is observe-only")
+	return nil
+}
+
+// replayEvent restores the data of the recorded events the strategy handles by type, the others are
+// replayed as their recorded prompts
+func replayEvent(record *eventlog.Record) ttypes.IEvent {
+	var data interface{}
+	switch record.Type {
+	case "update_finish":
+		return ttypes.NewEvent(record.Type, nil)
+	case "kline_changed":
+		data = &types.KLineWindow{}
+	case "position_changed":
+		data = &exchange.PositionX{}
+	case "fng_changed":
+		data = new(string)
+	case exchange.EventPositionClosed:
+		positionData := exchange.PositionClosedEventData{}
+		if err := json.Unmarshal(record.Data, &positionData); err == nil {
+			return exchange.NewPositionClosedEvent(positionData)
+		}
+	}
+
+	if data != nil && len(record.Data) > 0 {
+		if err := json.Unmarshal(record.Data, data); err == nil {
+			return ttypes.NewEvent(record.Type, data)
+		}
+	}
+
+	return eventlog.NewReplayedEvent(record)
+}
@@ -0,0 +1,163 @@
+package exposure
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A lock file older than this is left over by a crashed instance
+const staleLockAge = 10 * time.Second
+
+// entry is the margin of an instance in the ledger file
+type entry struct {
+	Margin    float64   `json:"margin"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileLedger is a Ledger kept in a JSON file, updates are serialized by a lock file next to it, it serves
+// instances sharing a host or volume
+type FileLedger struct {
+	path string
+	ttl  time.Duration
+}
+
+func NewFileLedger(path string, ttl time.Duration) *FileLedger {
+	return &FileLedger{
+		path: path,
+		ttl:  ttl,
+	}
+}
+
+func (l *FileLedger) Publish(ctx context.Context, instance string, margin float64) error {
+	return l.update(ctx, func(entries map[string]*entry) bool {
+		entries[instance] = &entry{Margin: margin, ExpiresAt: time.Now().Add(l.ttl)}
+		return true
+	})
+}
+
+func (l *FileLedger) Reserve(ctx context.Context, instance string, margin float64, limit float64) (bool, float64, error) {
+	reserved, others := false, 0.0
+	err := l.update(ctx, func(entries map[string]*entry) bool {
+		others = sumOthers(entries, instance)
+		if others+margin > limit {
+			return false
+		}
+
+		entries[instance] = &entry{Margin: margin, ExpiresAt: time.Now().Add(l.ttl)}
+		reserved = true
+		return true
+	})
+
+	return reserved, others, err
+}
+
+func (l *FileLedger) Others(ctx context.Context, instance string) (float64, error) {
+	others := 0.0
+	err := l.update(ctx, func(entries map[string]*entry) bool {
+		others = sumOthers(entries, instance)
+		return false
+	})
+
+	return others, err
+}
+
+func (l *FileLedger) Remove(ctx context.Context, instance string) error {
+	return l.update(ctx, func(entries map[string]*entry) bool {
+		delete(entries, instance)
+		return true
+	})
+}
+
+// update runs fn on the live entries under the lock file, writing them back when fn returns true
+func (l *FileLedger) update(ctx context.Context, fn func(entries map[string]*entry) bool) error {
+	unlock, err := l.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries := make(map[string]*entry)
+	data, err := os.ReadFile(l.path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "read exposure ledger error")
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return errors.Wrap(err, "parse exposure ledger error")
+		}
+	}
+
+	now := time.Now()
+	for instance, e := range entries {
+		if e == nil || now.After(e.ExpiresAt) {
+			delete(entries, instance)
+		}
+	}
+
+	if !fn(entries) {
+		return nil
+	}
+
+	data, err = json.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "marshal exposure ledger error")
+	}
+
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrap(err, "write exposure ledger error")
+	}
+
+	if err := os.Rename(tmp, l.path); err != nil {
+		return errors.Wrap(err, "replace exposure ledger error")
+	}
+
+	return nil
+}
+
+// lock creates the lock file, waiting while another instance holds it
+func (l *FileLedger) lock(ctx context.Context) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return nil, errors.Wrap(err, "create exposure ledger dir error")
+	}
+
+	lockPath := l.path + ".lock"
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, errors.Wrap(err, "create exposure ledger lock error")
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "wait for exposure ledger lock error")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func sumOthers(entries map[string]*entry, instance string) float64 {
+	total := 0.0
+	for name, e := range entries {
+		if name != instance {
+			total += e.Margin
+		}
+	}
+
+	return total
+}
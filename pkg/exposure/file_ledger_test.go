@@ -0,0 +1,56 @@
+package exposure
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLedgerReserve(t *testing.T) {
+	ctx := context.Background()
+	ledger := NewFileLedger(filepath.Join(t.TempDir(), "exposure.json"), time.Minute)
+
+	assert.NoError(t, ledger.Publish(ctx, "okx:BTCUSDT", 400))
+	assert.NoError(t, ledger.Publish(ctx, "okx:ETHUSDT", 300))
+
+	others, err := ledger.Others(ctx, "okx:SOLUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 700.0, others)
+
+	// 700 in use plus 400 is above the 1000 cap
+	reserved, others, err := ledger.Reserve(ctx, "okx:SOLUSDT", 400, 1000)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, 700.0, others)
+
+	reserved, _, err = ledger.Reserve(ctx, "okx:SOLUSDT", 250, 1000)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	// The instance's own margin is replaced, not added
+	reserved, others, err = ledger.Reserve(ctx, "okx:BTCUSDT", 450, 1000)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, 550.0, others)
+
+	assert.NoError(t, ledger.Remove(ctx, "okx:BTCUSDT"))
+	others, err = ledger.Others(ctx, "okx:SOLUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 300.0, others)
+}
+
+func TestFileLedgerExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "exposure.json")
+
+	stale := NewFileLedger(path, -time.Second)
+	assert.NoError(t, stale.Publish(ctx, "okx:BTCUSDT", 900))
+
+	ledger := NewFileLedger(path, time.Minute)
+	others, err := ledger.Others(ctx, "okx:ETHUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, others)
+}
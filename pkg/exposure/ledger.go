@@ -0,0 +1,35 @@
+package exposure
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// Ledger is the margin in use by each strategy instance trading a shared account
+type Ledger interface {
+	// Publish records the margin the instance currently uses, it expires unless published again
+	Publish(ctx context.Context, instance string, margin float64) error
+	// Reserve records margin for the instance if the margin of the other live instances plus margin stays
+	// within limit, it returns the margin of the other instances
+	Reserve(ctx context.Context, instance string, margin float64, limit float64) (bool, float64, error)
+	// Others returns the margin of the other live instances
+	Others(ctx context.Context, instance string) (float64, error)
+	// Remove drops the instance from the ledger
+	Remove(ctx context.Context, instance string) error
+}
+
+// NewLedger creates the ledger for the configured driver, entries expire after ttl unless published again
+func NewLedger(cfg *config.ExposureConfig, ttl time.Duration) (Ledger, error) {
+	switch cfg.Driver {
+	case "", "file":
+		return NewFileLedger(cfg.Path, ttl), nil
+	case "redis":
+		return NewRedisLedger(cfg.Addr, cfg.Password, cfg.DB, cfg.Key, ttl), nil
+	default:
+		return nil, errors.Errorf("exposure ledger driver not supported: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,118 @@
+package exposure
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// The hash maps each instance to "<margin>|<expiry unix ms>", expired fields are dropped when summing
+
+// publishScript sets the margin of the instance
+var publishScript = redis.NewScript(`
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2] .. "|" .. ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return 1
+`)
+
+// othersScript sums the margin of the other live instances, dropping the expired ones, and reserves the margin
+// for the instance when ARGV[3] is set and the total stays within the limit in ARGV[4].
+// It returns {reserved, others} with others as a string to keep its decimals.
+var othersScript = redis.NewScript(`
+local now = tonumber(ARGV[2])
+local others = 0
+local fields = redis.call("HGETALL", KEYS[1])
+for i = 1, #fields, 2 do
+	local sep = string.find(fields[i + 1], "|", 1, true)
+	local margin = tonumber(string.sub(fields[i + 1], 1, sep - 1))
+	local expiresAt = tonumber(string.sub(fields[i + 1], sep + 1))
+	if expiresAt < now then
+		redis.call("HDEL", KEYS[1], fields[i])
+	elseif fields[i] ~= ARGV[1] then
+		others = others + margin
+	end
+end
+if ARGV[3] == "" then
+	return {0, tostring(others)}
+end
+if others + tonumber(ARGV[3]) > tonumber(ARGV[4]) then
+	return {0, tostring(others)}
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[3] .. "|" .. ARGV[5])
+redis.call("PEXPIRE", KEYS[1], ARGV[6])
+return {1, tostring(others)}
+`)
+
+// RedisLedger is a Ledger kept in a Redis hash, it serves instances across hosts
+type RedisLedger struct {
+	client *redis.Client
+	key    string
+	ttl    time.Duration
+}
+
+func NewRedisLedger(addr string, password string, db int, key string, ttl time.Duration) *RedisLedger {
+	return &RedisLedger{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+		key: key,
+		ttl: ttl,
+	}
+}
+
+func (l *RedisLedger) Publish(ctx context.Context, instance string, margin float64) error {
+	err := publishScript.Run(ctx, l.client, []string{l.key}, instance, margin, l.expiresAt(), l.ttl.Milliseconds()).Err()
+	if err != nil {
+		return errors.Wrap(err, "redis publish exposure error")
+	}
+
+	return nil
+}
+
+func (l *RedisLedger) Reserve(ctx context.Context, instance string, margin float64, limit float64) (bool, float64, error) {
+	return l.run(ctx, instance, strconv.FormatFloat(margin, 'f', -1, 64), limit)
+}
+
+func (l *RedisLedger) Others(ctx context.Context, instance string) (float64, error) {
+	_, others, err := l.run(ctx, instance, "", 0)
+	return others, err
+}
+
+func (l *RedisLedger) Remove(ctx context.Context, instance string) error {
+	if err := l.client.HDel(ctx, l.key, instance).Err(); err != nil {
+		return errors.Wrap(err, "redis remove exposure error")
+	}
+
+	return nil
+}
+
+// run sums the other instances, reserving the margin unless it is empty
+func (l *RedisLedger) run(ctx context.Context, instance string, margin string, limit float64) (bool, float64, error) {
+	res, err := othersScript.Run(ctx, l.client, []string{l.key}, instance, time.Now().UnixMilli(), margin, limit,
+		l.expiresAt(), l.ttl.Milliseconds()).Slice()
+	if err != nil {
+		return false, 0, errors.Wrap(err, "redis reserve exposure error")
+	}
+
+	if len(res) != 2 {
+		return false, 0, errors.Errorf("unexpected redis exposure reply: %v", res)
+	}
+
+	reserved, _ := res[0].(int64)
+	othersText, _ := res[1].(string)
+	others, err := strconv.ParseFloat(othersText, 64)
+	if err != nil {
+		return false, 0, errors.Wrapf(err, "unexpected redis exposure reply: %v", res)
+	}
+
+	return reserved == 1, others, nil
+}
+
+func (l *RedisLedger) expiresAt() int64 {
+	return time.Now().Add(l.ttl).UnixMilli()
+}
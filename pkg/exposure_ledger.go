@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/exposure"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// setupExposure publishes the margin of the instance to the ledger shared by the instances trading the account,
+// entries are rejected when all of them together would use more than the cap
+func (s *Strategy) setupExposure(ctx context.Context) error {
+	cfg := &s.Exposure
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.MaxMarginUsage == 0 {
+		cfg.MaxMarginUsage = 80
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = types.Duration(time.Minute)
+	}
+	if cfg.Path == "" {
+		cfg.Path = fmt.Sprintf("memory-bank/exposure-%s.json", s.session.Name)
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = "localhost:6379"
+	}
+	if cfg.Password == "" {
+		cfg.Password = os.Getenv("EXPOSURE_PASSWORD")
+	}
+	if cfg.Key == "" {
+		cfg.Key = fmt.Sprintf("trading-gpt:exposure:%s", s.session.Name)
+	}
+
+	ledger, err := exposure.NewLedger(cfg, cfg.TTL.Duration())
+	if err != nil {
+		return errors.Wrap(err, "create exposure ledger error")
+	}
+	s.exposureLedger = ledger
+	s.exposureInstance = fmt.Sprintf("%s:%s", s.session.Name, s.Symbol)
+
+	s.publishExposure(ctx)
+
+	go func() {
+		ticker := time.NewTicker(cfg.TTL.Duration() / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.publishExposure(ctx)
+			}
+		}
+	}()
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if err := s.exposureLedger.Remove(ctx, s.exposureInstance); err != nil {
+			log.WithError(err).Warn("remove exposure failed")
+		}
+	})
+
+	log.WithField("driver", cfg.Driver).WithField("max_margin_usage", cfg.MaxMarginUsage).Info("Shared exposure cap enabled")
+	return nil
+}
+
+// positionMargin estimates the margin of the open position from its entry notional and the configured leverage
+func (s *Strategy) positionMargin() float64 {
+	if s.Position == nil {
+		return 0
+	}
+
+	leverage := s.Leverage.Float64()
+	if leverage <= 0 {
+		leverage = 1
+	}
+
+	return s.Position.GetBase().Abs().Mul(s.Position.AverageCost).Float64() / leverage
+}
+
+// publishExposure records the margin of the open position in the ledger
+func (s *Strategy) publishExposure(ctx context.Context) {
+	if err := s.exposureLedger.Publish(ctx, s.exposureInstance, s.positionMargin()); err != nil {
+		log.WithError(err).Warn("publish exposure failed")
+	}
+}
+
+// checkExposure reserves the margin of an entry in the ledger, returning why it is rejected when the instances
+// sharing the account would exceed the cap together, empty when it may execute
+func (s *Strategy) checkExposure(ctx context.Context, action *ttypes.Action, actionName string) string {
+	if s.exposureLedger == nil || !isEntryAction(actionName) {
+		return ""
+	}
+
+	cmd := strings.TrimPrefix(actionName, "exchange.")
+	sim, err := s.exchange.SimulateCommand(ctx, cmd, action.Args, 0)
+	if err != nil {
+		return fmt.Sprintf("the margin of the entry can't be estimated for the shared exposure cap: %s", err.Error())
+	}
+
+	others, err := s.exposureLedger.Others(ctx, s.exposureInstance)
+	if err != nil {
+		log.WithError(err).Warn("read exposure failed")
+		return "the shared exposure ledger is unavailable, entries are held until it is back"
+	}
+
+	// The available balance excludes the margin in use, which belongs to the account equity
+	own := s.positionMargin()
+	equity := sim.Equity + own + others
+	limit := equity * s.Exposure.MaxMarginUsage / 100
+
+	reserved, others, err := s.exposureLedger.Reserve(ctx, s.exposureInstance, own+sim.Margin, limit)
+	if err != nil {
+		log.WithError(err).Warn("reserve exposure failed")
+		return "the shared exposure ledger is unavailable, entries are held until it is back"
+	}
+
+	if !reserved {
+		return fmt.Sprintf("the entry needs %.2f margin while the instances sharing the account use %.2f, together above the %.0f%% cap of the %.2f equity",
+			sim.Margin, own+others, s.Exposure.MaxMarginUsage, equity)
+	}
+
+	return ""
+}
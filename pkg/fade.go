@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/pkg/errors"
+)
+
+// fadedCommands maps the commands taking a side to their inverse
+var fadedCommands = map[string]string{
+	"exchange.open_long_position":  "exchange.open_short_position",
+	"exchange.open_short_position": "exchange.open_long_position",
+}
+
+func (s *Strategy) setupFade(ctx context.Context) error {
+	cfg := &s.Fade
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if !bbgo.IsBackTesting && !cfg.AllowLive {
+		return errors.New("fade mode inverts the agent's entries and is only allowed in backtests, set fade.allow_live to run it live")
+	}
+
+	log.WithField("config", cfg).Warn("Fade mode enabled, the agent's entries are executed inverted")
+	return nil
+}
+
+// fadeCommand returns the inverse of the command when fade mode is enabled: longs become shorts with the
+// stop loss and take profit swapped, and the legs of spreads are swapped. Limit entries become market
+// entries, since the limit price is on the wrong side of the market for the inverse. Only entries are faded:
+// the agent is shown the position actually held, so its position updates already target the right side.
+func (s *Strategy) fadeCommand(actionName string, args map[string]string) (string, map[string]string) {
+	if !s.Fade.Enabled {
+		return actionName, args
+	}
+
+	faded := make(map[string]string, len(args))
+	for key, value := range args {
+		faded[key] = value
+	}
+
+	switch actionName {
+	case "exchange.open_long_position", "exchange.open_short_position":
+		faded["stop_loss_trigger_price"] = args["take_profit_trigger_price"]
+		faded["take_profit_trigger_price"] = args["stop_loss_trigger_price"]
+		delete(faded, "order_type")
+		delete(faded, "limit_price")
+		delete(faded, "post_only")
+		delete(faded, "time_in_force")
+	case "spread.open_spread":
+		faded["long_leg"] = args["short_leg"]
+		faded["short_leg"] = args["long_leg"]
+	default:
+		return actionName, args
+	}
+
+	if inverse, ok := fadedCommands[actionName]; ok {
+		actionName = inverse
+	}
+
+	log.WithField("action", actionName).WithField("args", faded).Info("command faded")
+	return actionName, faded
+}
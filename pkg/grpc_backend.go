@@ -0,0 +1,87 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/api/jarvispb"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// grpcBackend exposes the strategy to the gRPC API
+type grpcBackend struct {
+	s *Strategy
+}
+
+func (b *grpcBackend) GetState(ctx context.Context) (*jarvispb.GetStateResponse, error) {
+	s := b.s
+
+	position := &jarvispb.Position{}
+	if s.Position != nil {
+		if s.Position.IsLong() {
+			position.Side = "long"
+		} else if s.Position.IsShort() {
+			position.Side = "short"
+		}
+
+		position.Quantity = s.Position.GetBase().Abs().Float64()
+		position.AverageCost = s.Position.AverageCost.Float64()
+	}
+
+	return &jarvispb.GetStateResponse{
+		Symbol:       s.Symbol,
+		Interval:     string(s.Interval),
+		Position:     position,
+		LastDecision: s.lastDecision.Load(),
+		TrackRecord:  s.currentTrackRecord(),
+	}, nil
+}
+
+func (b *grpcBackend) SubmitCommand(ctx context.Context, operator string, command string, args map[string]string) error {
+	s := b.s
+
+	if isResumeCommand(command) {
+		if !s.resumeTrading(ctx, operator) {
+			return errors.New("LLM-driven trading is not paused")
+		}
+		return nil
+	}
+
+	if isAckCommand(command) {
+		if s.deadMan == nil {
+			return errors.New("the dead-man's switch is not enabled")
+		}
+		s.ackHeartbeat(ctx, operator)
+		return nil
+	}
+
+	if isReconcileCommand(command) {
+		_, err := s.reconcilePosition(ctx, operator)
+		return err
+	}
+
+	if !strings.Contains(command, ".") {
+		command = "exchange." + command
+	}
+
+	err := s.executeExternalCommand(ctx, command, args)
+
+	result := fmt.Sprintf("Operator %s executed command %s via gRPC.", operator, command)
+	if err != nil {
+		result = fmt.Sprintf("Operator %s failed to execute command %s via gRPC, reason: %s", operator, command, err.Error())
+	}
+
+	s.adminMu.Lock()
+	sessions := append([]ttypes.ISession{}, s.adminSessions...)
+	s.adminMu.Unlock()
+
+	for _, session := range sessions {
+		s.replyMsg(ctx, session, result)
+		s.stashMsg(ctx, session, result)
+	}
+
+	return err
+}
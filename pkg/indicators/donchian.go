@@ -0,0 +1,130 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// Donchian is the channel between the highest high and the lowest low of the last window klines
+type Donchian struct {
+	Window int                 // Lookback in klines
+	Format config.NumberFormat // Number format of the prompt summary
+
+	Upper  Series // Highest high
+	Middle Series // Mean of the upper and lower band
+	Lower  Series // Lowest low
+
+	highs    []float64
+	lows     []float64
+	close    float64
+	breakout int
+}
+
+// NewDonchian creates a Donchian channel over window klines
+func NewDonchian(window int, format config.NumberFormat) *Donchian {
+	return &Donchian{
+		Window: window,
+		Format: format,
+	}
+}
+
+// Update adds a closed kline
+func (d *Donchian) Update(kline types.KLine) {
+	close := kline.Close.Float64()
+	d.close = close
+
+	// A close beyond the band of the previous window klines is a breakout
+	d.breakout = 0
+	if d.Middle.Length() > 0 {
+		if close > d.Upper.Last(0) {
+			d.breakout = 1
+		} else if close < d.Lower.Last(0) {
+			d.breakout = -1
+		}
+	}
+
+	d.highs = append(d.highs, kline.High.Float64())
+	d.lows = append(d.lows, kline.Low.Float64())
+	if len(d.highs) > d.Window {
+		d.highs = d.highs[len(d.highs)-d.Window:]
+		d.lows = d.lows[len(d.lows)-d.Window:]
+	}
+
+	if len(d.highs) < d.Window {
+		return
+	}
+
+	upper, lower := math.Inf(-1), math.Inf(1)
+	for i := range d.highs {
+		upper = math.Max(upper, d.highs[i])
+		lower = math.Min(lower, d.lows[i])
+	}
+
+	d.Upper.push(upper)
+	d.Middle.push((upper + lower) / 2)
+	d.Lower.push(lower)
+}
+
+// Breakout returns 1 when the latest close broke above the upper band of the previous klines, -1 when it broke
+// below the lower band and 0 otherwise
+func (d *Donchian) Breakout() int {
+	return d.breakout
+}
+
+// FlipEvent describes the breakout of the latest close, empty when it stayed inside the channel
+func (d *Donchian) FlipEvent() string {
+	switch d.breakout {
+	case 1:
+		return fmt.Sprintf("the close %s broke above the Donchian(%d) upper band to a new %d-kline high",
+			utils.FormatNumber(d.close, d.Format), d.Window, d.Window)
+	case -1:
+		return fmt.Sprintf("the close %s broke below the Donchian(%d) lower band to a new %d-kline low",
+			utils.FormatNumber(d.close, d.Format), d.Window, d.Window)
+	}
+
+	return ""
+}
+
+// Length returns the number of middle band values
+func (d *Donchian) Length() int {
+	return d.Middle.Length()
+}
+
+// Index returns the i-th middle band value counting back from the latest
+func (d *Donchian) Index(i int) float64 {
+	return d.Middle.Index(i)
+}
+
+// Last returns the i-th middle band value counting back from the latest
+func (d *Donchian) Last(i int) float64 {
+	return d.Middle.Last(i)
+}
+
+// PromptSummary describes the bands of the channel and where the close is in it
+func (d *Donchian) PromptSummary() string {
+	if d.Middle.Length() == 0 {
+		return ""
+	}
+
+	upper, lower := d.Upper.Last(0), d.Lower.Last(0)
+	bands := fmt.Sprintf("Donchian(%d) upper band %s, middle band %s, lower band %s",
+		d.Window,
+		utils.FormatNumber(upper, d.Format),
+		utils.FormatNumber(d.Middle.Last(0), d.Format),
+		utils.FormatNumber(lower, d.Format))
+
+	if event := d.FlipEvent(); event != "" {
+		return fmt.Sprintf("%s, %s", bands, event)
+	}
+
+	if upper == lower {
+		return bands
+	}
+
+	return fmt.Sprintf("%s, the close is at %.0f%% of the channel", bands, (d.close-lower)/(upper-lower)*100)
+}
@@ -0,0 +1,91 @@
+// Package indicators contains indicators computed in Go from closed klines, registered with the exchange
+// environment alongside the bbgo standard indicator set
+package indicators
+
+import (
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// maxSeriesLength bounds the number of values an indicator keeps
+const maxSeriesLength = 1000
+
+// Indicator is fed with the closed klines of its interval and describes its state for the decision prompt
+type Indicator interface {
+	// Update adds a closed kline
+	Update(kline types.KLine)
+
+	// PromptSummary describes the current state of the indicator, empty until it has seen enough klines
+	PromptSummary() string
+}
+
+// TrendFlipper is implemented by indicators that detect trend changes, such as SuperTrend flips and Donchian
+// channel breakouts
+type TrendFlipper interface {
+	// FlipEvent describes the trend change on the latest kline, empty when there was none
+	FlipEvent() string
+}
+
+// Series is a bounded series of indicator values, indexed from the latest like the bbgo indicators
+type Series []float64
+
+// Length returns the number of values
+func (s Series) Length() int {
+	return len(s)
+}
+
+// Last returns the i-th value counting back from the latest, 0 when out of range
+func (s Series) Last(i int) float64 {
+	if i < 0 || i >= len(s) {
+		return 0
+	}
+
+	return s[len(s)-1-i]
+}
+
+// Index is the same as Last
+func (s Series) Index(i int) float64 {
+	return s.Last(i)
+}
+
+func (s *Series) push(val float64) {
+	*s = append(*s, val)
+	if len(*s) > maxSeriesLength {
+		*s = (*s)[len(*s)-maxSeriesLength:]
+	}
+}
+
+// atr is the Wilder average true range, seeded with the mean of the first window true ranges
+type atr struct {
+	window    int
+	count     int
+	sum       float64
+	value     float64
+	prevClose float64
+}
+
+// update adds a kline and returns the average true range, ok is false until window true ranges were seen
+func (a *atr) update(high, low, close float64) (float64, bool) {
+	defer func() { a.prevClose = close }()
+
+	a.count++
+	if a.count == 1 {
+		// The first kline has no previous close to measure the true range from
+		return 0, false
+	}
+
+	tr := math.Max(high-low, math.Max(math.Abs(high-a.prevClose), math.Abs(low-a.prevClose)))
+
+	switch {
+	case a.count <= a.window:
+		a.sum += tr
+		return 0, false
+	case a.count == a.window+1:
+		a.value = (a.sum + tr) / float64(a.window)
+	default:
+		a.value = (a.value*float64(a.window-1) + tr) / float64(a.window)
+	}
+
+	return a.value, true
+}
@@ -0,0 +1,126 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func newKLine(high, low, close float64) types.KLine {
+	return types.KLine{
+		High:  fixedpoint.NewFromFloat(high),
+		Low:   fixedpoint.NewFromFloat(low),
+		Close: fixedpoint.NewFromFloat(close),
+	}
+}
+
+// rising klines one wide around 100, 101, 102, ...
+func risingKLines(n int) []types.KLine {
+	klines := make([]types.KLine, 0, n)
+	for i := 0; i < n; i++ {
+		mid := 100 + float64(i)
+		klines = append(klines, newKLine(mid+0.5, mid-0.5, mid))
+	}
+	return klines
+}
+
+func TestSeries(t *testing.T) {
+	var s Series
+	s.push(1)
+	s.push(2)
+	s.push(3)
+
+	assert.Equal(t, 3, s.Length())
+	assert.Equal(t, 3.0, s.Last(0))
+	assert.Equal(t, 1.0, s.Index(2))
+	assert.Equal(t, 0.0, s.Last(3))
+}
+
+func TestSuperTrend(t *testing.T) {
+	st := NewSuperTrend(3, 2, config.NumberFormat{})
+	assert.Equal(t, "", st.PromptSummary())
+
+	for _, k := range risingKLines(10) {
+		st.Update(k)
+	}
+
+	assert.True(t, st.Uptrend())
+	assert.Equal(t, 7, st.Length())
+	assert.Less(t, st.Last(0), 109.0)
+	assert.GreaterOrEqual(t, st.Last(0), st.Last(1))
+	assert.False(t, st.Flipped())
+	assert.Equal(t, "", st.FlipEvent())
+	assert.Contains(t, st.PromptSummary(), "SuperTrend(3, 2) has been in an uptrend for 7 klines, the support line is at 106.000, the close is 2.83% above it")
+
+	// A close far below the support line flips the trend
+	st.Update(newKLine(109, 90, 90))
+	assert.False(t, st.Uptrend())
+	assert.True(t, st.Flipped())
+	assert.Equal(t, 1, st.TrendLength())
+	assert.Greater(t, st.Last(0), 90.0)
+	assert.Equal(t, "SuperTrend(3, 2) flipped to a downtrend, the close 90.000 broke below the support line", st.FlipEvent())
+	assert.Contains(t, st.PromptSummary(), "SuperTrend(3, 2) flipped to a downtrend on the latest kline, the resistance line is at")
+
+	st.Update(newKLine(91, 89, 90))
+	assert.False(t, st.Flipped())
+	assert.Contains(t, st.PromptSummary(), "has been in a downtrend for 2 klines")
+}
+
+func TestDonchian(t *testing.T) {
+	d := NewDonchian(3, config.NumberFormat{})
+	d.Update(newKLine(10, 5, 8))
+	d.Update(newKLine(12, 6, 9))
+	assert.Equal(t, "", d.PromptSummary())
+
+	d.Update(newKLine(11, 4, 7))
+	assert.Equal(t, 12.0, d.Upper.Last(0))
+	assert.Equal(t, 4.0, d.Lower.Last(0))
+	assert.Equal(t, 8.0, d.Last(0))
+
+	assert.Equal(t, 0, d.Breakout())
+
+	// The first kline leaves the window
+	d.Update(newKLine(9, 7, 8))
+	assert.Equal(t, 12.0, d.Upper.Last(0))
+	assert.Equal(t, 4.0, d.Lower.Last(0))
+
+	d.Update(newKLine(9, 7, 8))
+	d.Update(newKLine(9, 7, 8))
+	assert.Equal(t, 9.0, d.Upper.Last(0))
+	assert.Equal(t, 7.0, d.Lower.Last(0))
+	assert.Equal(t, "Donchian(3) upper band 9.000, middle band 8.000, lower band 7.000, the close is at 50% of the channel", d.PromptSummary())
+
+	d.Update(newKLine(10, 8, 9.5))
+	assert.Equal(t, 1, d.Breakout())
+	assert.Equal(t, "the close 9.500 broke above the Donchian(3) upper band to a new 3-kline high", d.FlipEvent())
+	assert.Contains(t, d.PromptSummary(), "lower band 7.000, the close 9.500 broke above")
+
+	d.Update(newKLine(7, 6, 6.5))
+	assert.Equal(t, -1, d.Breakout())
+	assert.Contains(t, d.FlipEvent(), "broke below the Donchian(3) lower band to a new 3-kline low")
+}
+
+func TestKeltner(t *testing.T) {
+	k := NewKeltner(3, 2, config.NumberFormat{})
+	for _, kline := range risingKLines(5) {
+		k.Update(kline)
+	}
+
+	// EMA seeded with the mean of 100, 101 and 102, the true ranges are 1.5 wide
+	assert.Equal(t, 2, k.Length())
+	assert.InDelta(t, 103, k.Middle.Last(0), 1e-9)
+	assert.InDelta(t, 3, k.Upper.Last(0)-k.Middle.Last(0), 1e-9)
+	assert.InDelta(t, 3, k.Middle.Last(0)-k.Lower.Last(0), 1e-9)
+	assert.Contains(t, k.PromptSummary(), "the close is inside the channel")
+
+	// The close of 104 runs ahead of the narrower band
+	narrow := NewKeltner(3, 0.5, config.NumberFormat{})
+	for _, kline := range risingKLines(5) {
+		narrow.Update(kline)
+	}
+	assert.Contains(t, narrow.PromptSummary(), "the close is above the upper band")
+}
@@ -0,0 +1,97 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// Keltner is the channel a multiple of the ATR around the EMA of the close
+type Keltner struct {
+	Window     int                 // EMA and ATR window
+	Multiplier float64             // ATR multiple of the bands
+	Format     config.NumberFormat // Number format of the prompt summary
+
+	Upper  Series // EMA plus the ATR multiple
+	Middle Series // EMA of the close
+	Lower  Series // EMA minus the ATR multiple
+
+	atr   atr
+	ema   float64
+	count int
+	close float64
+}
+
+// NewKeltner creates a Keltner channel over window klines, the bands multiplier ATRs from the EMA
+func NewKeltner(window int, multiplier float64, format config.NumberFormat) *Keltner {
+	return &Keltner{
+		Window:     window,
+		Multiplier: multiplier,
+		Format:     format,
+		atr:        atr{window: window},
+	}
+}
+
+// Update adds a closed kline
+func (k *Keltner) Update(kline types.KLine) {
+	close := kline.Close.Float64()
+	k.close = close
+
+	// The EMA is seeded with the mean of the first window closes
+	k.count++
+	if k.count <= k.Window {
+		k.ema += (close - k.ema) / float64(k.count)
+	} else {
+		k.ema += (close - k.ema) * 2 / float64(k.Window+1)
+	}
+
+	avgRange, ok := k.atr.update(kline.High.Float64(), kline.Low.Float64(), close)
+	if !ok {
+		return
+	}
+
+	k.Upper.push(k.ema + k.Multiplier*avgRange)
+	k.Middle.push(k.ema)
+	k.Lower.push(k.ema - k.Multiplier*avgRange)
+}
+
+// Length returns the number of middle band values
+func (k *Keltner) Length() int {
+	return k.Middle.Length()
+}
+
+// Index returns the i-th middle band value counting back from the latest
+func (k *Keltner) Index(i int) float64 {
+	return k.Middle.Index(i)
+}
+
+// Last returns the i-th middle band value counting back from the latest
+func (k *Keltner) Last(i int) float64 {
+	return k.Middle.Last(i)
+}
+
+// PromptSummary describes the bands and where the close is relative to the channel
+func (k *Keltner) PromptSummary() string {
+	if k.Middle.Length() == 0 {
+		return ""
+	}
+
+	upper, lower := k.Upper.Last(0), k.Lower.Last(0)
+
+	position := "inside the channel"
+	if k.close > upper {
+		position = "above the upper band"
+	} else if k.close < lower {
+		position = "below the lower band"
+	}
+
+	return fmt.Sprintf("Keltner(%d, %g) upper band %s, middle band %s, lower band %s, the close is %s",
+		k.Window, k.Multiplier,
+		utils.FormatNumber(upper, k.Format),
+		utils.FormatNumber(k.Middle.Last(0), k.Format),
+		utils.FormatNumber(lower, k.Format),
+		position)
+}
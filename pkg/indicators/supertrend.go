@@ -0,0 +1,156 @@
+package indicators
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// SuperTrend trails the price by a multiple of the ATR: below the price in an uptrend, above it in a downtrend,
+// the trend flipping when the close crosses the line
+type SuperTrend struct {
+	Window     int                 // ATR window
+	Multiplier float64             // ATR multiple of the bands
+	Format     config.NumberFormat // Number format of the prompt summary
+
+	Line Series // The lower band in an uptrend, the upper band in a downtrend
+
+	atr     atr
+	upper   float64
+	lower   float64
+	up      bool
+	close   float64
+	flipped bool
+	klines  int
+}
+
+// NewSuperTrend creates a SuperTrend over window klines, the bands multiplier ATRs from the median price
+func NewSuperTrend(window int, multiplier float64, format config.NumberFormat) *SuperTrend {
+	return &SuperTrend{
+		Window:     window,
+		Multiplier: multiplier,
+		Format:     format,
+		atr:        atr{window: window},
+	}
+}
+
+// Update adds a closed kline
+func (st *SuperTrend) Update(kline types.KLine) {
+	high, low, close := kline.High.Float64(), kline.Low.Float64(), kline.Close.Float64()
+	prevClose := st.close
+	st.close = close
+
+	avgRange, ok := st.atr.update(high, low, close)
+	if !ok {
+		return
+	}
+
+	median := (high + low) / 2
+	upper := median + st.Multiplier*avgRange
+	lower := median - st.Multiplier*avgRange
+
+	st.flipped = false
+	st.klines++
+
+	if st.Line.Length() == 0 {
+		st.up = close >= median
+	} else {
+		// The bands only move with the trend, unless the previous close broke through them
+		if upper > st.upper && prevClose <= st.upper {
+			upper = st.upper
+		}
+		if lower < st.lower && prevClose >= st.lower {
+			lower = st.lower
+		}
+
+		if (st.up && close < st.lower) || (!st.up && close > st.upper) {
+			st.up = !st.up
+			st.flipped = true
+			st.klines = 1
+		}
+	}
+
+	st.upper, st.lower = upper, lower
+	if st.up {
+		st.Line.push(lower)
+	} else {
+		st.Line.push(upper)
+	}
+}
+
+// Uptrend reports whether the SuperTrend is in an uptrend
+func (st *SuperTrend) Uptrend() bool {
+	return st.up
+}
+
+// Flipped reports whether the trend flipped on the latest kline
+func (st *SuperTrend) Flipped() bool {
+	return st.flipped
+}
+
+// TrendLength returns the number of klines in the current trend, counting the kline it started on
+func (st *SuperTrend) TrendLength() int {
+	return st.klines
+}
+
+// FlipEvent describes the flip on the latest kline, empty when the trend held
+func (st *SuperTrend) FlipEvent() string {
+	if !st.flipped {
+		return ""
+	}
+
+	if st.up {
+		return fmt.Sprintf("SuperTrend(%d, %g) flipped to an uptrend, the close %s broke above the resistance line",
+			st.Window, st.Multiplier, utils.FormatNumber(st.close, st.Format))
+	}
+
+	return fmt.Sprintf("SuperTrend(%d, %g) flipped to a downtrend, the close %s broke below the support line",
+		st.Window, st.Multiplier, utils.FormatNumber(st.close, st.Format))
+}
+
+// Length returns the number of SuperTrend line values
+func (st *SuperTrend) Length() int {
+	return st.Line.Length()
+}
+
+// Index returns the i-th SuperTrend line value counting back from the latest
+func (st *SuperTrend) Index(i int) float64 {
+	return st.Line.Index(i)
+}
+
+// Last returns the i-th SuperTrend line value counting back from the latest
+func (st *SuperTrend) Last(i int) float64 {
+	return st.Line.Last(i)
+}
+
+// PromptSummary describes the trend, how long it has held and the distance of the close to the line
+func (st *SuperTrend) PromptSummary() string {
+	if st.Line.Length() == 0 {
+		return ""
+	}
+
+	trend, line := "an uptrend", "support"
+	if !st.up {
+		trend, line = "a downtrend", "resistance"
+	}
+
+	held := fmt.Sprintf("has been in %s for %d klines", trend, st.klines)
+	if st.flipped {
+		held = fmt.Sprintf("flipped to %s on the latest kline", trend)
+	}
+
+	value := st.Line.Last(0)
+	distance, side := 0.0, "above"
+	if value != 0 {
+		distance = (st.close - value) / value * 100
+	}
+	if distance < 0 {
+		distance, side = -distance, "below"
+	}
+
+	return fmt.Sprintf("SuperTrend(%d, %g) %s, the %s line is at %s, the close is %.2f%% %s it",
+		st.Window, st.Multiplier, held, line, utils.FormatNumber(value, st.Format), distance, side)
+}
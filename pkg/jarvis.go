@@ -3,12 +3,16 @@ package pkg
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -17,20 +21,36 @@ import (
 	"github.com/yubing744/trading-gpt/pkg/chat/feishu"
 	"github.com/yubing744/trading-gpt/pkg/llms"
 	"github.com/yubing744/trading-gpt/pkg/prompt"
+	"github.com/yubing744/trading-gpt/pkg/risk"
 	"github.com/yubing744/trading-gpt/pkg/utils/xtemplate"
 
 	"github.com/yubing744/trading-gpt/pkg/agents"
 	"github.com/yubing744/trading-gpt/pkg/agents/keeper"
 	"github.com/yubing744/trading-gpt/pkg/agents/trading"
+	"github.com/yubing744/trading-gpt/pkg/api"
+	"github.com/yubing744/trading-gpt/pkg/api/jarvispb"
+	"github.com/yubing744/trading-gpt/pkg/apis/okx"
 	"github.com/yubing744/trading-gpt/pkg/config"
 	"github.com/yubing744/trading-gpt/pkg/env"
+	"github.com/yubing744/trading-gpt/pkg/env/breadth"
+	"github.com/yubing744/trading-gpt/pkg/env/bridge"
 	"github.com/yubing744/trading-gpt/pkg/env/coze"
+	"github.com/yubing744/trading-gpt/pkg/env/divergence"
+	"github.com/yubing744/trading-gpt/pkg/env/eventlog"
 	"github.com/yubing744/trading-gpt/pkg/env/exchange"
 	"github.com/yubing744/trading-gpt/pkg/env/fng"
+	"github.com/yubing744/trading-gpt/pkg/env/funding"
+	"github.com/yubing744/trading-gpt/pkg/env/rest"
+	"github.com/yubing744/trading-gpt/pkg/env/spread"
 	"github.com/yubing744/trading-gpt/pkg/env/twitterapi"
+	"github.com/yubing744/trading-gpt/pkg/exposure"
+	"github.com/yubing744/trading-gpt/pkg/journal"
+	"github.com/yubing744/trading-gpt/pkg/lock"
 	"github.com/yubing744/trading-gpt/pkg/memory"
+	"github.com/yubing744/trading-gpt/pkg/metrics"
 	"github.com/yubing744/trading-gpt/pkg/utils"
 
+	"github.com/yubing744/trading-gpt/pkg/notify"
 	nfeishu "github.com/yubing744/trading-gpt/pkg/notify/feishu"
 	feishu_hook "github.com/yubing744/trading-gpt/pkg/notify/feishu-hook"
 	ttypes "github.com/yubing744/trading-gpt/pkg/types"
@@ -38,6 +58,23 @@ import (
 
 const MaxRetryTime = 1
 
+// MaxBatchActions limits the number of actions executed from a single response
+const MaxBatchActions = 5
+
+// emergencyCloseTimeout bounds the emergency close, which runs outside the cycle context that may have expired
+const emergencyCloseTimeout = 15 * time.Second
+
+// cycleOutcomeKey carries the *cycleOutcome of the decision cycle
+type cycleOutcomeKey struct{}
+
+// cycleOutcome records whether any response of a decision cycle was unparseable or invalid
+type cycleOutcome struct {
+	failed bool
+}
+
+// tradeConfirmedKey marks an agentAction context whose entry was already confirmed after the pre-trade simulation
+type tradeConfirmedKey struct{}
+
 // ID is the unique strategy ID, it needs to be in all lower case
 // For example, grid strategy uses "grid"
 const ID = "jarvis"
@@ -74,13 +111,123 @@ type Strategy struct {
 	// jarvis model
 	llm          *llms.LLMManager
 	world        *env.Environment
+	exchange     *exchange.ExchangeEntity
 	agent        agents.IAgent
 	chatSessions *chat.ChatSessions
 
+	// pub-sub bridge to external quant infrastructure
+	bridge *bridge.BridgeEntity
+
+	// guards entries against abnormal local prices
+	priceDivergence *divergence.PriceDivergenceEntity
+
+	// two-leg spread and hedge trades
+	spread *spread.SpreadEntity
+
 	// memory system
-	memoryManager *memory.MemoryManager
-	memoryEnabled bool
-	currentMemory string
+	memoryManager   *memory.MemoryManager
+	memoryEnabled   bool
+	currentMemory   string
+	memoryRetriever *memory.MemoryRetriever
+
+	// decision journal
+	journal         *journal.Journal
+	noActionStreak  int
+	openDecisionID  string                 // decision that opened the current position
+	entryAssessment *ttypes.Assessment     // market assessment of the decision that opened the current position
+	lastAssessment  *ttypes.Assessment     // latest market assessment, reused as the situation of memory retrieval
+	trackRecord     atomic.Pointer[string] // one-line per-symbol stats computed from the journal, also read by the gRPC API
+
+	formatters *prompt.FormatterRegistry
+
+	// trade reflections waiting for post-close klines
+	pendingReflections   []*pendingReflection
+	tradeCompleteTrigger *memory.TradeCompleteTrigger
+
+	reflectionsSinceConsolidation int
+
+	// perpetual funding rate, refreshed lazily for the position prompt
+	okxClient        *okx.OKXClient
+	fundingRate      *okx.FundingRate
+	fundingFetchedAt time.Time
+
+	// pauses LLM-driven trading after repeated invalid responses until an operator resumes it
+	cycleFailures *utils.FailureWindow
+	blackout      atomic.Bool
+
+	// gRPC control and decision API
+	grpcServer   *api.Server
+	lastDecision atomic.Pointer[jarvispb.Decision]
+
+	// holds back reversals shortly after an entry
+	flipGuard *utils.FlipGuard
+
+	// end of the latest closed kline seen, to tell new klines from cycles fired mid-candle
+	lastKlineTime time.Time
+
+	// blocks entries when the operator stops acknowledging heartbeats
+	deadMan *utils.DeadManSwitch
+
+	// compresses prompt sections unchanged since the last cycle
+	promptDeduper *utils.PromptDeduper
+
+	// unix millis of the latest risk event, for the sampling schedule
+	riskEventAt atomic.Int64
+
+	// records the env events fed to the agent
+	eventLog *eventlog.Writer
+
+	// precisions and order size limits of the market, nil unless market info is enabled
+	marketInfo *utils.MarketInfo
+
+	// few-shot samples shown with the commands, nil unless the sample bank is enabled
+	sampleBank *prompt.SampleBank
+
+	// mined samples waiting for the operator's approval, and the decision IDs the operator rejected
+	sampleMu         sync.Mutex
+	sampleCandidates []*prompt.Sample
+	sampleRejected   map[string]bool
+
+	// rejects entries stating a confidence below the minimum, nil unless calibration is enabled
+	confidenceGate *utils.ConfidenceGate
+
+	// consecutive losing trades, throttling entries once they reach the limit, nil unless enabled
+	lossStreak *utils.LossStreak
+
+	// risk per trade shrunk by the drawdown from the equity peak, nil unless enabled
+	riskBudget *utils.RiskBudget
+
+	// halts entries on the daily loss and max drawdown limits, nil unless enabled
+	riskManager *risk.Manager
+
+	// lease that lets only one instance trade the account and symbol
+	instanceLock lock.Lock
+	observeOnly  atomic.Bool
+
+	// margin used by the instances sharing the account, nil unless the exposure cap is enabled
+	exposureLedger   exposure.Ledger
+	exposureInstance string
+
+	// snapshot loaded at startup, applied once every component is set up
+	restored *StrategySnapshot
+
+	// state captured after the latest decision cycle, written to disk by the snapshot loop
+	capturedSnapshot atomic.Pointer[StrategySnapshot]
+
+	// local clock corrected by the skew measured against the exchange, stamps the decisions
+	clock utils.SyncedClock
+
+	// admin sessions receiving scheduled reports
+	adminSessions []ttypes.ISession
+	adminMu       sync.Mutex
+}
+
+// pendingReflection is a closed trade whose reflection waits for the counterfactual horizon
+type pendingReflection struct {
+	session      ttypes.ISession
+	posData      exchange.PositionClosedEventData
+	tradeContext *memory.TradeContext
+	remaining    int
 }
 
 // ID should return the identity of this strategy
@@ -124,6 +271,12 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	// calculate group id for orders
 	instanceID := s.InstanceID()
 
+	// Restore the strategy state saved before a restart
+	err := s.Restore(ctx)
+	if err != nil {
+		return err
+	}
+
 	// If position is nil, we need to allocate a new position for calculation
 	if s.Position == nil {
 		s.Position = types.NewPositionFromMarket(s.Market)
@@ -152,7 +305,39 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	})
 
 	// Setup LLM
-	err := s.setupLLM(ctx)
+	err = s.setupLLM(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Setup Formatters
+	err = s.setupFormatters(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupCycleDeadline(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupMarketInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The self-test runs before the environment starts, so it never trades
+	if SelfTest {
+		return s.runSelfTest(ctx)
+	}
+
+	err = s.setupTestnet(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The role is settled before the world starts and the admin sessions run decision cycles
+	err = s.setupInstanceLock(ctx)
 	if err != nil {
 		return err
 	}
@@ -163,6 +348,16 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		return err
 	}
 
+	err = s.setupRecovery(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupClockSkew(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Setup Agent
 	err = s.setupAgent(ctx)
 	if err != nil {
@@ -175,6 +370,43 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		return err
 	}
 
+	// Setup Journal
+	err = s.setupJournal(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupSampleBank(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupCalibration(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupLossStreak(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupRiskBudget(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupRisk(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Setup Reflection Trigger
+	err = s.setupReflectionTrigger(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Setup Notify
 	err = s.setupNotify(ctx)
 	if err != nil {
@@ -187,6 +419,88 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		return err
 	}
 
+	err = s.setupBacktest(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Setup Review
+	err = s.setupReview(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupFunding(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupBlackout(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupKlinePrompt(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupFlipGuard(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupPromptDedup(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupSampling(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupStaleGuard(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupFade(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.renewInstanceLock(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupExposure(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupDeadMan(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.setupEventLog(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Setup gRPC API
+	err = s.setupGRPC(ctx)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -201,17 +515,50 @@ func (s *Strategy) setupLLM(ctx context.Context) error {
 	return nil
 }
 
+func (s *Strategy) setupFormatters(ctx context.Context) error {
+	formatters, err := prompt.NewFormatterRegistry(s.Formatters)
+	if err != nil {
+		return errors.Wrap(err, "Init formatters fail")
+	}
+
+	s.formatters = formatters
+	return nil
+}
+
 func (s *Strategy) setupWorld(ctx context.Context) error {
+	switch s.Env.ExchangeConfig.Direction {
+	case "", config.DirectionBoth, config.DirectionLongOnly, config.DirectionShortOnly:
+	default:
+		return errors.Errorf("invalid exchange direction: %s, expected long_only, short_only or both", s.Env.ExchangeConfig.Direction)
+	}
+
+	if err := exchange.ValidateIndicators(s.Env.ExchangeConfig.Indicators); err != nil {
+		return errors.Wrap(err, "invalid exchange indicators")
+	}
+
+	venue, err := exchange.NewExecutionVenue(s.Env.ExchangeConfig.Venue, s.Symbol, s.session, s.orderExecutor, s.Position)
+	if err != nil {
+		return errors.Wrap(err, "create execution venue")
+	}
+
 	world := env.NewEnvironment(&s.Env)
-	world.RegisterEntity(exchange.NewExchangeEntity(
+	s.exchange = exchange.NewExchangeEntity(
 		s.Symbol,
 		s.Interval,
 		s.Leverage,
 		s.Env.ExchangeConfig,
 		s.session,
-		s.orderExecutor,
+		venue,
 		s.Position,
-	))
+	)
+	if s.restored != nil && s.restored.Exchange != nil {
+		s.exchange.Restore(s.restored.Exchange)
+	}
+	s.exchange.SetCycleTimeout(s.cycleTimeout())
+	s.exchange.SetMarketInfo(s.marketInfo)
+	s.exchange.SetEntryCheck(s.checkFallbackEntry)
+	s.exchange.SetObserveOnly(s.observeOnly.Load())
+	world.RegisterEntity(s.exchange)
 
 	if s.Env.FNG != nil && s.Env.FNG.Enabled {
 		log.Info("fng_enabled")
@@ -243,7 +590,164 @@ func (s *Strategy) setupWorld(ctx context.Context) error {
 		world.RegisterEntity(twitterapi.NewTwitterAPIEntity(s.Env.TwitterAPI))
 	}
 
-	err := world.Start(ctx)
+	if s.Env.REST != nil && s.Env.REST.Enabled {
+		log.Info("rest_enabled")
+
+		if s.Env.REST.Timeout == "" {
+			s.Env.REST.Timeout = types.Interval("20s")
+		}
+
+		world.RegisterEntity(rest.NewRESTEntity(s.Env.REST))
+	}
+
+	if s.Env.Breadth != nil && s.Env.Breadth.Enabled {
+		log.Info("breadth_enabled")
+
+		cfg := s.Env.Breadth
+		if cfg.APIKey == "" {
+			cfg.APIKey = os.Getenv("COINGECKO_API_KEY")
+		}
+		if cfg.Interval == "" {
+			cfg.Interval = types.Interval4h
+		}
+		if cfg.TopN <= 0 {
+			cfg.TopN = 50
+		}
+		if cfg.MADays <= 0 {
+			cfg.MADays = 20
+		}
+		if cfg.RequestDelay == "" {
+			cfg.RequestDelay = types.Interval("2s")
+		}
+
+		world.RegisterEntity(breadth.NewMarketBreadthEntity(cfg))
+	}
+
+	if s.Env.Funding != nil && s.Env.Funding.Enabled {
+		log.Info("funding_rate_enabled")
+
+		cfg := s.Env.Funding
+		if cfg.InstID == "" {
+			cfg.InstID = okx.SwapInstID(s.Market.BaseCurrency, s.Market.QuoteCurrency)
+		}
+		if cfg.Interval == "" {
+			cfg.Interval = types.Interval1h
+		}
+
+		world.RegisterEntity(funding.NewFundingRateEntity(cfg))
+	}
+
+	if s.Env.Divergence != nil && s.Env.Divergence.Enabled {
+		log.Info("divergence_enabled")
+
+		cfg := s.Env.Divergence
+		if cfg.Interval == "" {
+			cfg.Interval = types.Interval1m
+		}
+		if cfg.Threshold <= 0 {
+			cfg.Threshold = 1.0
+		}
+
+		referenceSession, ok := s.Environment.Session(cfg.ReferenceSession)
+		if !ok {
+			return errors.Errorf("divergence reference session not found: %s", cfg.ReferenceSession)
+		}
+
+		s.priceDivergence = divergence.NewPriceDivergenceEntity(s.Symbol, cfg, s.session.Exchange, referenceSession.Exchange)
+		world.RegisterEntity(s.priceDivergence)
+	}
+
+	if s.Env.Spread != nil && s.Env.Spread.Enabled {
+		log.Info("spread_enabled")
+
+		cfg := s.Env.Spread
+		if cfg.Interval == "" {
+			cfg.Interval = s.Interval
+		}
+
+		legs := make([]*spread.Leg, 0, len(cfg.Legs))
+		for name, legCfg := range cfg.Legs {
+			session := s.session
+			if legCfg.Session != "" {
+				legSession, ok := s.Environment.Session(legCfg.Session)
+				if !ok {
+					return errors.Errorf("spread leg %s session not found: %s", name, legCfg.Session)
+				}
+				session = legSession
+			}
+
+			market, ok := session.Market(legCfg.Symbol)
+			if !ok {
+				return errors.Errorf("spread leg %s market not found: %s", name, legCfg.Symbol)
+			}
+
+			legs = append(legs, &spread.Leg{Name: name, Market: market, Exchange: session.Exchange})
+		}
+
+		s.spread = spread.NewSpreadEntity(cfg, legs)
+		if s.restored != nil && s.restored.Spread != nil {
+			s.spread.Restore(s.restored.Spread)
+		}
+		s.spread.SetObserveOnly(s.observeOnly.Load())
+		world.RegisterEntity(s.spread)
+	}
+
+	if s.Env.Bridge != nil && s.Env.Bridge.Enabled {
+		log.Info("bridge_enabled")
+
+		cfg := s.Env.Bridge
+		if cfg.Addr == "" {
+			cfg.Addr = "localhost:6379"
+		}
+		if cfg.Password == "" {
+			cfg.Password = os.Getenv("BRIDGE_PASSWORD")
+		}
+
+		pubsub, err := bridge.NewPubSub(cfg)
+		if err != nil {
+			return errors.Wrap(err, "Error in create bridge")
+		}
+
+		s.bridge = bridge.NewBridgeEntity(cfg, pubsub)
+		world.RegisterEntity(s.bridge)
+
+		// Registered once rather than per chat session, so events are published and commands executed once
+		world.OnEvent(func(evt ttypes.IEvent) {
+			s.handleBridgeEvent(ctx, evt)
+		})
+	}
+
+	for _, plugin := range s.Env.Plugins {
+		if plugin == nil || !plugin.Enabled {
+			continue
+		}
+
+		entity, err := env.NewEntity(ctx, plugin.Type, plugin.Config)
+		if err != nil {
+			return errors.Wrap(err, "Error in create plugin entity")
+		}
+
+		if _, exists := world.Entity(entity.GetID()); exists {
+			return errors.Errorf("plugin entity %s conflicts with the registered entity id %s", plugin.Type, entity.GetID())
+		}
+
+		log.WithField("type", plugin.Type).WithField("id", entity.GetID()).Info("plugin_entity_enabled")
+		world.RegisterEntity(entity)
+	}
+
+	world.OnEvent(func(evt ttypes.IEvent) {
+		if evt.GetType() == exchange.EventActionResult {
+			s.recordExecution(evt)
+		}
+	})
+
+	// Replayed events take the place of the live ones
+	if s.EventLog.Replay.Enabled {
+		s.world = world
+		return nil
+	}
+
+	err = world.Start(ctx)
 	if err != nil {
 		return errors.Wrap(err, "Error in start env")
 	}
@@ -256,6 +760,11 @@ func (s *Strategy) setupAgent(ctx context.Context) error {
 	var tradingAgent *trading.TradingAgent
 	tradingCfg := &s.Agent.Trading
 	if tradingCfg != nil && tradingCfg.Enabled {
+		// The core holding is a hard constraint of every decision, so it is part of the background
+		if coreHolding := s.exchange.CoreHoldingPrompt(); coreHolding != "" {
+			tradingCfg.Backgroup = strings.TrimSpace(tradingCfg.Backgroup + "\n\n" + coreHolding)
+		}
+
 		tradingAgent := trading.NewTradingAgent(tradingCfg, s.llm)
 		s.agent = tradingAgent
 	}
@@ -298,6 +807,14 @@ func (s *Strategy) setupMemory(ctx context.Context) error {
 		s.memoryManager = memory.NewMemoryManager(s.Memory.MemoryPath, s.Memory.MaxWords)
 		s.memoryEnabled = true
 
+		// Upgrade memory file written by older versions
+		migrated, err := s.memoryManager.Migrate()
+		if err != nil {
+			log.WithError(err).Warn("Failed to migrate memory file")
+		} else if migrated {
+			log.WithField("path", s.Memory.MemoryPath).Info("Memory file migrated to current schema version")
+		}
+
 		// Load existing memory
 		memoryContent, err := s.memoryManager.LoadMemory()
 		if err != nil {
@@ -312,19 +829,157 @@ func (s *Strategy) setupMemory(ctx context.Context) error {
 		log.Info("Memory system disabled")
 	}
 
-	return nil
-}
+	// Initialize memory retriever over trade reflections (defaults to true if not specified)
+	readMemoryEnabled := true
+	if s.ReadMemoryEnabled != nil {
+		readMemoryEnabled = *s.ReadMemoryEnabled
+	}
 
-func (s *Strategy) setupNotify(ctx context.Context) error {
-	feishuNotifyCfg := s.Notify.Feishu
-	if feishuNotifyCfg != nil && feishuNotifyCfg.Enabled {
+	if readMemoryEnabled {
+		if s.Memory.RetrievalTopK == 0 {
+			s.Memory.RetrievalTopK = 3
+		}
+
+		cacheTTL := time.Minute * 30
+		if s.Memory.RelevanceCacheTTL != "" {
+			cacheTTL = s.Memory.RelevanceCacheTTL.Duration()
+		}
+
+		var complete memory.CompletionFunc
+		if s.Memory.RelevanceLLM {
+			complete = func(ctx context.Context, prompt string) (string, error) {
+				return s.llm.Call(ctx, prompt)
+			}
+		}
+
+		// Upgrade reflections written by older versions before loading them
+		migrated, err := memory.MigrateDir(s.getReflectionPath())
+		if err != nil {
+			log.WithError(err).Warn("Failed to migrate trade reflections")
+		} else if migrated > 0 {
+			log.WithField("migrated", migrated).Info("Trade reflections migrated to current schema version")
+		}
+
+		s.memoryRetriever = memory.NewMemoryRetriever(s.getReflectionPath(), complete, cacheTTL)
+
+		// Specific trades age out, while only the latest distilled rule sets are kept
+		if s.Memory.EpisodicRetentionDays == 0 {
+			s.Memory.EpisodicRetentionDays = 90
+		}
+		if s.Memory.SemanticMaxCount == 0 {
+			s.Memory.SemanticMaxCount = 3
+		}
+		if s.Memory.PromoteAt == 0 {
+			s.Memory.PromoteAt = 2
+		}
+		if s.Memory.DemoteAt == 0 {
+			s.Memory.DemoteAt = -2
+		}
+		s.memoryRetriever.SetRetention(memory.MemoryKindEpisodic, memory.RetentionPolicy{
+			MaxAge: time.Duration(s.Memory.EpisodicRetentionDays) * 24 * time.Hour,
+		})
+		s.memoryRetriever.SetRetention(memory.MemoryKindSemantic, memory.RetentionPolicy{
+			MaxCount: s.Memory.SemanticMaxCount,
+		})
+		err = s.memoryRetriever.Load()
+		if err != nil {
+			log.WithError(err).Warn("Failed to load trade reflections")
+		}
+
+		if s.Memory.KnowledgeBasePath != "" {
+			if s.Memory.KnowledgeTopK == 0 {
+				s.Memory.KnowledgeTopK = 2
+			}
+
+			playbooks, err := memory.LoadKnowledgeBase(s.Memory.KnowledgeBasePath)
+			if err != nil {
+				log.WithError(err).Warn("Failed to load knowledge base")
+			}
+
+			for _, playbook := range playbooks {
+				s.memoryRetriever.Add(playbook)
+			}
+
+			log.WithField("playbooks", len(playbooks)).Info("Knowledge base loaded")
+		}
+
+		if s.Memory.Embeddings {
+			if s.Memory.EmbeddingCachePath == "" {
+				s.Memory.EmbeddingCachePath = "memory-bank/embeddings.json"
+			}
+
+			cache := memory.NewEmbeddingCache(s.Memory.EmbeddingCachePath)
+			err = cache.Load()
+			if err != nil {
+				log.WithError(err).Warn("Failed to load embedding cache, re-embedding all reflections")
+			}
+
+			if s.Memory.RerankCandidates == 0 {
+				s.Memory.RerankCandidates = 3 * s.Memory.RetrievalTopK
+			}
+
+			s.memoryRetriever.SetEmbedder(s.llm.CreateEmbedding, cache, s.Memory.RerankCandidates)
+
+			// Only new or changed reflections are embedded at startup
+			embedded, err := s.memoryRetriever.IndexEmbeddings(ctx)
+			if err != nil {
+				log.WithError(err).Warn("Failed to index reflection embeddings")
+			} else {
+				log.WithField("embedded", embedded).WithField("cached", cache.Len()).Info("Reflection embeddings indexed")
+			}
+		}
+
+		log.WithField("reflections", len(s.memoryRetriever.GetMemories())).Info("Memory retrieval enabled")
+	}
+
+	return nil
+}
+
+func (s *Strategy) setupJournal(ctx context.Context) error {
+	if s.Journal.Enabled {
+		if s.Journal.Path == "" {
+			s.Journal.Path = "memory-bank/journal.jsonl"
+		}
+
+		if s.Journal.StatsWindow == 0 {
+			s.Journal.StatsWindow = 50
+		}
+
+		s.journal = journal.NewJournal(s.Journal.Path)
+		s.journal.SetTestnet(s.Testnet.Enabled)
+		s.updateTrackRecord()
+		log.WithField("path", s.Journal.Path).Info("Decision journal enabled")
+	} else {
+		log.Info("Decision journal disabled")
+	}
+
+	return nil
+}
+
+func (s *Strategy) setupReflectionTrigger(ctx context.Context) error {
+	cfg := s.ReflectionTrigger
+
+	cooldown := time.Duration(0)
+	if cfg.Cooldown != "" {
+		cooldown = cfg.Cooldown.Duration()
+	}
+
+	s.tradeCompleteTrigger = memory.NewTradeCompleteTrigger(cfg.OnlyLosses, cfg.MinAbsPnL, cfg.MaxPerDay, cooldown)
+	log.WithField("config", cfg).Info("Reflection trigger setup")
+
+	return nil
+}
+
+func (s *Strategy) setupNotify(ctx context.Context) error {
+	feishuNotifyCfg := s.Notify.Feishu
+	if feishuNotifyCfg != nil && feishuNotifyCfg.Enabled {
 		if os.Getenv("NOTIFY_FEISHU_APP_ID") != "" {
 			feishuNotifyCfg.AppId = os.Getenv("NOTIFY_FEISHU_APP_ID")
 			feishuNotifyCfg.AppSecret = os.Getenv("NOTIFY_FEISHU_APP_SECRET")
 		}
 
 		feishuNotifyChannel := nfeishu.NewFeishuNotifyChannel(feishuNotifyCfg)
-		chatSession := chat.NewChatSession(feishuNotifyChannel)
+		chatSession := chat.NewChatSession(notify.NewRoutedChannel(feishuNotifyChannel, &feishuNotifyCfg.NotifyRouteConfig))
 		s.setupAdminSession(ctx, chatSession)
 		s.agentAction(ctx, chatSession, []*ttypes.Message{{
 			Text: "Please wait a moment while I prepare the market data. ",
@@ -336,7 +991,7 @@ func (s *Strategy) setupNotify(ctx context.Context) error {
 	hookNotifyCfg := s.Notify.FeishuHook
 	if hookNotifyCfg != nil && hookNotifyCfg.Enabled {
 		feishuHookNotifyChannel := feishu_hook.NewFeishuHookNotifyChannel(hookNotifyCfg)
-		chatSession := chat.NewChatSession(feishuHookNotifyChannel)
+		chatSession := chat.NewChatSession(notify.NewRoutedChannel(feishuHookNotifyChannel, &hookNotifyCfg.NotifyRouteConfig))
 		s.setupAdminSession(ctx, chatSession)
 		s.agentAction(ctx, chatSession, []*ttypes.Message{{
 			Text: "Please wait a moment while I prepare the market data. ",
@@ -387,18 +1042,516 @@ func (s *Strategy) setupChat(ctx context.Context) error {
 	return nil
 }
 
+func (s *Strategy) setupReview(ctx context.Context) error {
+	if !s.Review.Enabled {
+		return nil
+	}
+
+	if s.journal == nil {
+		log.Warn("Strategy review requires the decision journal, review disabled")
+		return nil
+	}
+
+	if s.Review.Interval == "" {
+		s.Review.Interval = types.Interval1w
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.Review.Interval.Duration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.generateReview(ctx)
+			}
+		}
+	}()
+
+	log.WithField("interval", s.Review.Interval).Info("Strategy review scheduled")
+	return nil
+}
+
+func (s *Strategy) setupFunding(ctx context.Context) error {
+	if !s.Funding.Enabled {
+		return nil
+	}
+
+	if s.Funding.InstID == "" {
+		s.Funding.InstID = okx.SwapInstID(s.Market.BaseCurrency, s.Market.QuoteCurrency)
+	}
+	if s.Funding.Threshold <= 0 {
+		s.Funding.Threshold = 0.0005
+	}
+	if s.Funding.Interval == 0 {
+		s.Funding.Interval = types.Duration(time.Minute * 10)
+	}
+
+	log.WithField("inst_id", s.Funding.InstID).Info("funding_enabled")
+	s.okxClient = okx.NewOKXClient()
+
+	return nil
+}
+
+// fundingCarryPrompt describes strong funding of the open position, the rate is cached for the refresh interval
+func (s *Strategy) fundingCarryPrompt(ctx context.Context, side string, notional float64, quoteCurrency string) string {
+	if s.okxClient == nil {
+		return ""
+	}
+
+	if s.fundingRate == nil || time.Since(s.fundingFetchedAt) > s.Funding.Interval.Duration() {
+		rate, err := s.okxClient.GetFundingRate(ctx, s.Funding.InstID)
+		if err != nil {
+			log.WithError(err).Warn("get funding rate error")
+		} else {
+			s.fundingRate = rate
+			s.fundingFetchedAt = time.Now()
+		}
+	}
+
+	if s.fundingRate == nil {
+		return ""
+	}
+
+	carry := utils.ComputeFundingCarry(side, s.fundingRate.Rate, s.fundingRate.Interval, notional)
+	return carry.Prompt(s.Funding.Threshold, s.Funding.HoldHint, quoteCurrency, s.Precision.Percent)
+}
+
+func (s *Strategy) setupBlackout(ctx context.Context) error {
+	if !s.Blackout.Enabled {
+		return nil
+	}
+
+	if s.Blackout.MaxFailures <= 0 {
+		s.Blackout.MaxFailures = 3
+	}
+	if s.Blackout.Window < s.Blackout.MaxFailures {
+		s.Blackout.Window = 10
+	}
+
+	s.cycleFailures = utils.NewFailureWindow(s.Blackout.Window)
+
+	return nil
+}
+
+func (s *Strategy) setupInstanceLock(ctx context.Context) error {
+	cfg := &s.InstanceLock
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.TTL == 0 {
+		cfg.TTL = types.Duration(time.Second * 30)
+	}
+	if cfg.Path == "" {
+		cfg.Path = fmt.Sprintf("memory-bank/lock-%s-%s.json", s.session.Name, s.Symbol)
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = "localhost:6379"
+	}
+	if cfg.Password == "" {
+		cfg.Password = os.Getenv("LOCK_PASSWORD")
+	}
+
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+	key := fmt.Sprintf("trading-gpt:lock:%s:%s", s.session.Name, s.Symbol)
+
+	instanceLock, err := lock.NewLock(cfg, key, owner, cfg.TTL.Duration())
+	if err != nil {
+		return errors.Wrap(err, "create instance lock error")
+	}
+	s.instanceLock = instanceLock
+
+	// Settle the role before the entities are created, they start in it
+	s.refreshInstanceLock(ctx)
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if err := s.instanceLock.Release(ctx); err != nil {
+			log.WithError(err).Warn("release instance lock failed")
+		}
+	})
+
+	log.WithField("owner", owner).WithField("driver", cfg.Driver).Info("Instance lock enabled")
+	return nil
+}
+
+// renewInstanceLock keeps renewing the lease once the entities exist, taking over the lock when it expires
+func (s *Strategy) renewInstanceLock(ctx context.Context) error {
+	if s.instanceLock == nil {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.InstanceLock.TTL.Duration() / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.refreshInstanceLock(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// refreshInstanceLock acquires or renews the lock, switching to observe-only while another instance holds it
+func (s *Strategy) refreshInstanceLock(ctx context.Context) {
+	acquired, err := s.instanceLock.TryAcquire(ctx)
+	if err != nil {
+		// The lease can't be renewed, so another instance may take it over
+		log.WithError(err).Warn("acquire instance lock failed")
+	}
+
+	observeOnly := !acquired
+	if s.observeOnly.Swap(observeOnly) == observeOnly {
+		return
+	}
+
+	// Before setupWorld the entities pick the role up when they are created
+	if s.exchange != nil {
+		s.exchange.SetObserveOnly(observeOnly)
+	}
+	if s.spread != nil {
+		s.spread.SetObserveOnly(observeOnly)
+	}
+
+	var msg string
+	if observeOnly {
+		holder, _ := s.instanceLock.Holder(ctx)
+		if holder == "" {
+			holder = "unknown"
+		}
+
+		msg = fmt.Sprintf("👀 Another instance (%s) holds the trading lock of %s, this instance is observe-only and places no orders.", holder, s.Symbol)
+		log.WithField("holder", holder).Error("instance lock held by another instance, observe-only")
+	} else {
+		msg = fmt.Sprintf("🔓 Trading lock of %s acquired, this instance trades again.", s.Symbol)
+		log.Info("instance lock acquired")
+	}
+
+	bbgo.Notify(msg)
+}
+
+func (s *Strategy) setupFlipGuard(ctx context.Context) error {
+	if !s.FlipGuard.Enabled {
+		return nil
+	}
+
+	if s.FlipGuard.Window <= 0 {
+		s.FlipGuard.Window = 6
+	}
+	if s.FlipGuard.Confirmations <= 0 {
+		s.FlipGuard.Confirmations = 2
+	}
+
+	s.flipGuard = utils.NewFlipGuard(s.FlipGuard.Window, s.FlipGuard.Confirmations, s.FlipGuard.MinConfidence)
+	log.WithField("config", s.FlipGuard).Info("Flip guard enabled")
+
+	return nil
+}
+
+func (s *Strategy) setupPromptDedup(ctx context.Context) error {
+	if !s.PromptDedup.Enabled {
+		return nil
+	}
+
+	if s.PromptDedup.Threshold <= 0 || s.PromptDedup.Threshold > 1 {
+		s.PromptDedup.Threshold = 0.98
+	}
+	if s.PromptDedup.MaxNumericChange <= 0 {
+		s.PromptDedup.MaxNumericChange = 0.001
+	}
+	if s.PromptDedup.MaxSkips <= 0 {
+		s.PromptDedup.MaxSkips = 3
+	}
+
+	s.promptDeduper = utils.NewPromptDeduper(s.PromptDedup.Threshold, s.PromptDedup.MaxNumericChange, s.PromptDedup.MaxSkips)
+	log.WithField("config", s.PromptDedup).Info("Prompt dedup enabled")
+
+	return nil
+}
+
+// dedupeMsgs replaces the messages near-identical to the ones of the last cycles with an unchanged marker
+func (s *Strategy) dedupeMsgs(ctx context.Context, msgs []*ttypes.Message) []*ttypes.Message {
+	if s.promptDeduper == nil || len(msgs) == 0 {
+		return msgs
+	}
+
+	texts := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		texts = append(texts, msg.Text)
+	}
+
+	// Without embeddings the deduper falls back to comparing words
+	vectors, err := s.llm.CreateEmbedding(ctx, texts)
+	if err != nil {
+		log.WithError(err).Debug("create prompt embeddings failed, compare words instead")
+		vectors = nil
+	}
+
+	deduped, replaced := s.promptDeduper.Dedupe(texts, vectors)
+	if replaced == 0 {
+		return msgs
+	}
+
+	rets := make([]*ttypes.Message, 0, len(msgs))
+	saved := 0
+	for i, msg := range msgs {
+		if deduped[i] == msg.Text {
+			rets = append(rets, msg)
+			continue
+		}
+
+		saved += len(msg.Text) - len(deduped[i])
+		compressed := *msg
+		compressed.Text = deduped[i]
+		rets = append(rets, &compressed)
+	}
+
+	log.
+		WithField("replaced", replaced).
+		WithField("total", len(msgs)).
+		WithField("saved_chars", saved).
+		Info("prompt sections unchanged since last cycle compressed")
+
+	return rets
+}
+
+func (s *Strategy) setupKlinePrompt(ctx context.Context) error {
+	if s.KlinePrompt.Recent <= 0 || s.KlinePrompt.Recent >= s.MaxNum {
+		s.KlinePrompt.Recent = s.MaxNum / 2
+	}
+
+	return nil
+}
+
+// markCycleFailed marks the decision cycle of the context as failed by an unparseable or invalid response
+func (s *Strategy) markCycleFailed(ctx context.Context) {
+	if outcome, ok := ctx.Value(cycleOutcomeKey{}).(*cycleOutcome); ok {
+		outcome.failed = true
+	}
+}
+
+// recordCycleOutcome enters the blackout when too many of the latest decision cycles failed
+func (s *Strategy) recordCycleOutcome(ctx context.Context, outcome *cycleOutcome) {
+	failures := s.cycleFailures.Record(outcome.failed)
+	if failures < s.Blackout.MaxFailures || s.blackout.Swap(true) {
+		return
+	}
+
+	reason := fmt.Sprintf("%d of the latest %d decision cycles produced unparseable or invalid actions", failures, s.Blackout.Window)
+	log.WithField("reason", reason).Error("LLM-driven trading paused")
+
+	msg := fmt.Sprintf("⛔ LLM-driven trading paused: %s. Open positions keep their stop-loss and take-profit orders. Send the resume command (chat \"/resume\", or the \"resume\" command via gRPC or the bridge) after checking the model deployment.", reason)
+	bbgo.Notify(msg)
+	s.publishBridge(ctx, &bridge.OutboundMessage{Type: "blackout", Text: reason})
+
+	s.adminMu.Lock()
+	sessions := append([]ttypes.ISession{}, s.adminSessions...)
+	s.adminMu.Unlock()
+
+	for _, session := range sessions {
+		s.notifyMsg(ctx, session, ttypes.SeverityCritical, msg)
+	}
+}
+
+// resumeTrading ends the blackout on an operator's request, it returns false when trading was not paused
+func (s *Strategy) resumeTrading(ctx context.Context, operator string) bool {
+	riskResumed := s.riskManager != nil && s.riskManager.Resume()
+	if !s.blackout.Swap(false) {
+		if !riskResumed {
+			return false
+		}
+
+		msg := fmt.Sprintf("▶️ New positions resumed by %s after the risk limit pause, the limits are measured from the current equity.", operator)
+		log.Info(msg)
+		bbgo.Notify(msg)
+		s.publishBridge(ctx, &bridge.OutboundMessage{Type: "resume", Text: msg})
+		return true
+	}
+
+	s.cycleFailures.Reset()
+
+	msg := fmt.Sprintf("▶️ LLM-driven trading resumed by %s.", operator)
+	log.Info(msg)
+	bbgo.Notify(msg)
+	s.publishBridge(ctx, &bridge.OutboundMessage{Type: "resume", Text: msg})
+
+	return true
+}
+
+// isResumeCommand returns whether an operator command asks to end the blackout
+func isResumeCommand(command string) bool {
+	return command == "resume" || command == "jarvis.resume"
+}
+
+// isLoopbackAddr returns whether a listen address only accepts connections from the local host
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Strategy) setupGRPC(ctx context.Context) error {
+	if !s.GRPC.Enabled {
+		return nil
+	}
+
+	if s.GRPC.Token == "" {
+		s.GRPC.Token = os.Getenv("GRPC_TOKEN")
+	}
+	if s.GRPC.Addr == "" {
+		s.GRPC.Addr = ":50051"
+		if s.GRPC.Token == "" {
+			s.GRPC.Addr = "127.0.0.1:50051"
+		}
+	}
+
+	// Without a token any client can submit commands, so only local ones may connect
+	if s.GRPC.Token == "" && !isLoopbackAddr(s.GRPC.Addr) {
+		return errors.Errorf("gRPC API on %s needs a token, set grpc.token or GRPC_TOKEN, or listen on localhost", s.GRPC.Addr)
+	}
+
+	s.grpcServer = api.NewServer(&grpcBackend{s: s}, s.GRPC.Token)
+	err := s.grpcServer.Serve(ctx, s.GRPC.Addr)
+	if err != nil {
+		return errors.Wrap(err, "Error in start gRPC server")
+	}
+
+	return nil
+}
+
+// generateReview feeds the period's journal and performance stats to the LLM to produce a strategy review memo,
+// stores it as a high-importance memory and posts it to the admin sessions
+func (s *Strategy) generateReview(ctx context.Context) {
+	period := s.Review.Interval.Duration()
+	entries, err := s.journal.Since(time.Now().Add(-period))
+	if err != nil {
+		log.WithError(err).Error("Failed to load journal for strategy review")
+		return
+	}
+
+	if len(entries) == 0 {
+		log.Info("No journal entries in review period, skip strategy review")
+		return
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, formatJournalEntry(entry))
+	}
+
+	repairs := metrics.TakeParseRepairSummary()
+	promptText, err := xtemplate.Render(prompt.StrategyReviewTpl, map[string]interface{}{
+		"Symbol":  s.Symbol,
+		"Period":  period.String(),
+		"Stats":   journal.ComputeStats(entries).String(),
+		"Entries": lines,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to render strategy review prompt")
+		return
+	}
+
+	memo, err := s.llm.Call(ctx, promptText)
+	if err != nil {
+		log.WithError(err).Error("Failed to generate strategy review")
+		return
+	}
+
+	mem, err := memory.SaveReviewMemo(s.getReflectionPath(), s.Symbol, strings.TrimSpace(memo), time.Now())
+	if err != nil {
+		log.WithError(err).Error("Failed to save strategy review")
+	} else if s.memoryRetriever != nil {
+		s.memoryRetriever.Add(mem)
+	}
+
+	s.adminMu.Lock()
+	sessions := append([]ttypes.ISession{}, s.adminSessions...)
+	s.adminMu.Unlock()
+
+	report := fmt.Sprintf("📋 Strategy review for %s:\n%s", s.Symbol, memo)
+	if repairs != "" {
+		report = fmt.Sprintf("%s\n\n🔧 LLM JSON repairs over the period:\n%s", report, repairs)
+	}
+
+	for _, session := range sessions {
+		s.replyMsg(ctx, session, report)
+	}
+}
+
+// formatJournalEntry renders a journal entry as a single line for LLM prompts
+func formatJournalEntry(entry *journal.Entry) string {
+	line := fmt.Sprintf("%s %s %s", entry.Time.Format(time.RFC3339), entry.Kind, entry.Action)
+	if entry.Trade != nil {
+		return fmt.Sprintf("%s %s %s closed: PnL %.2f (%.2f%%), R %.2f, reason %s", entry.Time.Format(time.RFC3339),
+			entry.Kind, entry.Trade.Side, entry.Trade.PnL, entry.Trade.PnLPercent, entry.Trade.RMultiple, entry.Trade.CloseReason)
+	}
+
+	if entry.Note != "" {
+		return fmt.Sprintf("%s operator note on trade %s: %s", entry.Time.Format(time.RFC3339), entry.TradeID, entry.Note)
+	}
+
+	if entry.Reasoning != "" {
+		line = fmt.Sprintf("%s: %s", line, entry.Reasoning)
+	}
+
+	return line
+}
+
 func (s *Strategy) setupAdminSession(ctx context.Context, chatSession ttypes.ISession) {
 	chatSession.SetRoles([]string{ttypes.RoleAdmin})
 
+	s.adminMu.Lock()
+	s.adminSessions = append(s.adminSessions, chatSession)
+	s.adminMu.Unlock()
+
 	s.world.OnEvent(func(evt ttypes.IEvent) {
 		s.handleEnvEvent(context.Background(), chatSession, evt)
 	})
 }
 
 func (s *Strategy) replyMsg(ctx context.Context, chatSession ttypes.ISession, msg string) {
+	s.notifyMsg(ctx, chatSession, ttypes.SeverityInfo, msg)
+}
+
+// actionSeverity returns the notification severity of a decided action, waiting is not worth a trade alert
+func actionSeverity(action *ttypes.Action) ttypes.Severity {
+	if action.Name == "exchange.no_action" {
+		return ttypes.SeverityInfo
+	}
+
+	return ttypes.SeverityAction
+}
+
+// notifyMsg replies with a severity, so notify channels can route and throttle it
+func (s *Strategy) notifyMsg(ctx context.Context, chatSession ttypes.ISession, severity ttypes.Severity, msg string) {
+	if s.Testnet.Enabled {
+		msg = s.Testnet.Label + " " + msg
+	}
+
 	err := chatSession.Reply(ctx, &ttypes.Message{
-		ID:   uuid.NewString(),
-		Text: msg,
+		ID:       uuid.NewString(),
+		Text:     msg,
+		CycleID:  ttypes.CycleIDFromContext(ctx),
+		Severity: severity,
 	})
 	if err != nil {
 		log.WithError(err).Error("reply message error")
@@ -412,18 +1565,45 @@ func (s *Strategy) feedbackCmdExecuteResult(ctx context.Context, chatSession tty
 func (s *Strategy) emergencyClosePosition(ctx context.Context, chatSession ttypes.ISession, reason string) {
 	log.Warn("emergency close position")
 
+	// The LLM call may have failed on the cycle deadline, which must not cancel the protective close too
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), emergencyCloseTimeout)
+	defer cancel()
+
 	err := s.world.SendCommand(ctx, "exchange.close_position", map[string]string{})
 	if err != nil {
 		log.WithError(err).Error("env send cmd error")
 		return
 	}
 
-	log.Warn("emergency close position ok")
-	s.replyMsg(ctx, chatSession, fmt.Sprintf("emergency close position, for %s", reason))
-}
+	log.Warn("emergency close position ok")
+	s.notifyMsg(ctx, chatSession, ttypes.SeverityCritical, fmt.Sprintf("emergency close position, for %s", reason))
+}
+
+func (s *Strategy) agentAction(ctx context.Context, chatSession ttypes.ISession, msgs []*ttypes.Message, retryTime int) {
+	// Retries and confirmations stay in the decision cycle they were started in
+	if ttypes.CycleIDFromContext(ctx) == "" {
+		ctx = ttypes.WithCycleID(ctx, uuid.NewString())
+
+		if s.observeOnly.Load() {
+			log.Warn("skip agent action while observe-only")
+			s.notifyMsg(ctx, chatSession, ttypes.SeverityWarning, "Observe-only: another instance holds the trading lock of this account and symbol.")
+			return
+		}
+
+		if s.cycleFailures != nil && chatSession.HasRole(ttypes.RoleAdmin) {
+			if s.blackout.Load() {
+				log.Warn("skip agent action during blackout")
+				s.notifyMsg(ctx, chatSession, ttypes.SeverityWarning, "LLM-driven trading is paused after repeated invalid responses, waiting for an operator to resume it.")
+				return
+			}
 
-func (s *Strategy) agentAction(ctx context.Context, chatSession ttypes.ISession, msgs []*ttypes.Message, retryTime int) {
-	s.replyMsg(ctx, chatSession, fmt.Sprintf("The agent start action at %s, and the msgs:", time.Now().Format(time.RFC3339)))
+			outcome := &cycleOutcome{}
+			ctx = context.WithValue(ctx, cycleOutcomeKey{}, outcome)
+			defer s.recordCycleOutcome(ctx, outcome)
+		}
+	}
+
+	s.replyMsg(ctx, chatSession, fmt.Sprintf("The agent start action at %s in decision cycle %s, and the msgs:", time.Now().Format(time.RFC3339), ttypes.CycleIDFromContext(ctx)))
 	for _, msg := range msgs {
 		s.replyMsg(ctx, chatSession, msg.Text)
 	}
@@ -431,7 +1611,7 @@ func (s *Strategy) agentAction(ctx context.Context, chatSession ttypes.ISession,
 	resp, err := s.agent.GenActions(ctx, chatSession, msgs)
 	if err != nil {
 		log.WithError(err).Error("gen action error")
-		s.replyMsg(ctx, chatSession, fmt.Sprintf("gen action error: %s", err.Error()))
+		s.notifyMsg(ctx, chatSession, ttypes.SeverityWarning, fmt.Sprintf("gen action error: %s", err.Error()))
 
 		if chatSession.HasRole(ttypes.RoleAdmin) {
 			s.emergencyClosePosition(ctx, chatSession, "agent error")
@@ -450,16 +1630,35 @@ func (s *Strategy) agentAction(ctx context.Context, chatSession ttypes.ISession,
 			s.replyMsg(ctx, chatSession, fmt.Sprintf("Thinking: %s", thinkingText))
 		}
 
-		if strings.HasPrefix(resultText, "{") || strings.Contains(resultText, "```json") {
-			result, err := utils.ParseResult(resultText)
+		if strings.HasPrefix(resultText, "{") || strings.HasPrefix(resultText, "｛") || strings.Contains(resultText, "```json") {
+			var result *ttypes.Result
+			var repair *utils.ParseRepair
+			if resp.ResponseFormat == llms.ResponseFormatTool || s.Agent.Trading.StrictParsing {
+				// Tool call arguments are structured by the provider, the repair pipeline is only for text answers
+				result, err = utils.ParseResultStrict(resultText)
+				repair = &utils.ParseRepair{Level: utils.RepairLevelNone}
+			} else {
+				result, repair, err = utils.ParseResultWithRepair(resultText)
+			}
+
+			s.recordResponseParse(resp, err == nil)
+			if err == nil {
+				s.recordParseRepair(resp, repair)
+			}
 			if err != nil {
-				log.WithError(err).WithField("resultText", resultText).Error("parse resp error")
+				log.WithError(err).WithField("cycle_id", resp.CycleID).WithField("resultText", resultText).Error("parse resp error")
 
 				errMsg := fmt.Sprintf("parse resp error, resultText: %s", resultText)
+				if resp.ResponseFormat == llms.ResponseFormatTool || s.Agent.Trading.StrictParsing {
+					errMsg = fmt.Sprintf("parse resp error: %s, the response must be valid JSON, resultText: %s", err.Error(), resultText)
+				}
 				s.feedbackCmdExecuteResult(ctx, chatSession, errMsg)
+				s.markCycleFailed(ctx)
 
 				if retryTime > 0 {
-					time.Sleep(time.Second * 5)
+					if err := utils.SleepContext(ctx, time.Second*5); err != nil {
+						return
+					}
 
 					newMsgs := append(msgs, []*ttypes.Message{
 						{
@@ -475,23 +1674,45 @@ func (s *Strategy) agentAction(ctx context.Context, chatSession ttypes.ISession,
 				return
 			}
 
+			result.CycleID = resp.CycleID
+
 			if result.Thoughts != nil {
 				s.replyMsg(ctx, chatSession, result.Thoughts.ToHumanText())
 			}
 
 			if result.Action != nil {
-				s.replyMsg(ctx, chatSession, fmt.Sprintf("Action: %s", result.Action.JSON()))
+				s.notifyMsg(ctx, chatSession, actionSeverity(result.Action), fmt.Sprintf("Action: %s", result.Action.JSON()))
+			}
 
-				if result.Action.Name != "" {
-					actions = append(actions, result.Action)
+			for i, action := range result.Actions {
+				if action != nil {
+					s.notifyMsg(ctx, chatSession, actionSeverity(action), fmt.Sprintf("Action %d: %s", i+1, action.JSON()))
 				}
 			}
 
+			actions = result.AllActions()
+
 			// Process memory output if memory is enabled
 			if s.memoryEnabled && s.memoryManager != nil && result.Memory != nil {
 				s.processMemoryOutput(ctx, chatSession, result.Memory)
 			}
+
+			if chatSession.HasRole(ttypes.RoleAdmin) {
+				if result.Assessment != nil {
+					s.lastAssessment = result.Assessment
+				}
+
+				s.recordDecision(ctx, chatSession, msgs, result, resultText, resp.Model)
+
+				for _, action := range actions {
+					s.publishBridge(ctx, &bridge.OutboundMessage{Type: "decision", Text: action.JSON()})
+				}
+			}
 		} else {
+			if chatSession.HasRole(ttypes.RoleAdmin) {
+				s.recordResponseParse(resp, false)
+			}
+
 			s.replyMsg(ctx, chatSession, resultText)
 		}
 	}
@@ -503,7 +1724,7 @@ func (s *Strategy) agentAction(ctx context.Context, chatSession ttypes.ISession,
 	if len(actions) > 0 {
 		if chatSession.HasRole(ttypes.RoleAdmin) {
 			if len(actions) > 1 {
-				log.Info("skip handle actions for too many actions")
+				s.executeBatch(ctx, chatSession, msgs, actions, retryTime)
 				return
 			}
 
@@ -513,27 +1734,47 @@ func (s *Strategy) agentAction(ctx context.Context, chatSession ttypes.ISession,
 					actionName = "exchange." + actionName
 				}
 
-				err := s.world.SendCommand(ctx, actionName, action.Args)
+				if err := s.world.ValidateCommand(actionName, action.Args); err != nil {
+					log.WithError(err).WithField("action", actionName).Warn("invalid action")
+					errMsg := fmt.Sprintf("Command: %s is invalid, reason: %s", action.JSON(), err.Error())
+					s.feedbackCmdExecuteResult(ctx, chatSession, errMsg)
+					s.markCycleFailed(ctx)
+					s.retryAction(ctx, chatSession, msgs, errMsg, retryTime)
+					continue
+				}
+
+				// The guards check the command actually executed, which fade mode inverts
+				cmdName, cmdArgs := s.fadeCommand(actionName, action.Args)
+				sim, err := s.validateAction(ctx, &ttypes.Action{Name: cmdName, Args: cmdArgs}, cmdName)
+				if err != nil {
+					log.WithError(err).WithField("action", cmdName).Warn("action validation failed")
+					errMsg := fmt.Sprintf("Command: %s rejected, reason: %s", action.JSON(), err.Error())
+					if sim != nil {
+						errMsg = fmt.Sprintf("%s\n%s", errMsg, sim.String())
+					}
+
+					s.feedbackCmdExecuteResult(ctx, chatSession, errMsg)
+					s.retryAction(ctx, chatSession, msgs, errMsg, retryTime)
+					continue
+				}
+
+				if !s.staleGuard(ctx, chatSession, msgs, []*ttypes.Action{action}, retryTime) {
+					continue
+				}
+
+				if sim != nil && !s.confirmEntry(ctx, chatSession, msgs, action, sim, retryTime) {
+					continue
+				}
+
+				err = s.world.SendCommand(ctx, cmdName, cmdArgs)
 
 				if err != nil {
 					log.WithError(err).Error("env send cmd error")
 					errMsg := fmt.Sprintf("Command: %s failed to execute by entity, reason: %s", action.JSON(), err.Error())
 					s.feedbackCmdExecuteResult(ctx, chatSession, errMsg)
-
-					if retryTime > 0 {
-						time.Sleep(time.Second * 5)
-
-						newMsgs := append(msgs, []*ttypes.Message{
-							{
-								Text: errMsg,
-							},
-							{
-								Text: "Please try to fix the above error by responding with JSON again.",
-							},
-						}...)
-						s.agentAction(ctx, chatSession, newMsgs, retryTime-1)
-					}
+					s.retryAction(ctx, chatSession, msgs, errMsg, retryTime)
 				} else {
+					s.recordEntry(cmdName)
 					s.feedbackCmdExecuteResult(ctx, chatSession, fmt.Sprintf("Command: %s executed successfully by entity.", action.JSON()))
 				}
 			}
@@ -543,13 +1784,360 @@ func (s *Strategy) agentAction(ctx context.Context, chatSession ttypes.ISession,
 	}
 }
 
+// retryAction asks the agent to fix the error by responding again, if retries are left
+func (s *Strategy) retryAction(ctx context.Context, chatSession ttypes.ISession, msgs []*ttypes.Message, errMsg string, retryTime int) {
+	if retryTime <= 0 {
+		return
+	}
+
+	if err := utils.SleepContext(ctx, time.Second*5); err != nil {
+		return
+	}
+
+	newMsgs := append(msgs, []*ttypes.Message{
+		{
+			Text: errMsg,
+		},
+		{
+			Text: "Please try to fix the above error by responding with JSON again.",
+		},
+	}...)
+	s.agentAction(ctx, chatSession, newMsgs, retryTime-1)
+}
+
+// simulateEntry simulates an open position action and returns the violated pre-trade risk limits
+func (s *Strategy) simulateEntry(ctx context.Context, action *ttypes.Action, actionName string) (*utils.TradeSimulation, []string, error) {
+	cfg := s.PreTrade
+	if cfg.MaintenanceMarginRate <= 0 {
+		cfg.MaintenanceMarginRate = 0.005
+	}
+
+	cmd := strings.TrimPrefix(actionName, "exchange.")
+	sim, err := s.exchange.SimulateCommand(ctx, cmd, action.Args, cfg.MaintenanceMarginRate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	violations := sim.Check(utils.TradeRiskLimits{
+		RequireStopLoss: cfg.RequireStopLoss,
+		MaxLossPercent:  cfg.MaxLossPercent,
+		MaxMarginUsage:  cfg.MaxMarginUsage,
+	})
+
+	return sim, violations, nil
+}
+
+// validateAction runs the pre-execution checks of an action: the entry guards and, for entries, the pre-trade risk limits.
+// Every path executing a command of the agent goes through it
+func (s *Strategy) validateAction(ctx context.Context, action *ttypes.Action, actionName string) (*utils.TradeSimulation, error) {
+	if s.priceDivergence != nil && isEntryAction(actionName) {
+		if blocked, reason := s.priceDivergence.IsEntryBlocked(); blocked {
+			return nil, errors.New(reason)
+		}
+	}
+
+	if reason := s.deadManReason(actionName); reason != "" {
+		return nil, errors.New(reason)
+	}
+
+	if reason := s.checkRisk(actionName); reason != "" {
+		return nil, errors.New(reason)
+	}
+
+	if reason := s.checkFlipGuard(actionName, action.Args); reason != "" {
+		return nil, errors.New(reason)
+	}
+
+	if reason := s.checkConfidenceGate(actionName, action.Args); reason != "" {
+		return nil, errors.New(reason)
+	}
+
+	if reason := s.checkLossStreak(actionName, action.Args); reason != "" {
+		return nil, errors.New(reason)
+	}
+
+	if reason := s.checkExposure(ctx, action, actionName); reason != "" {
+		return nil, errors.New(reason)
+	}
+
+	if !s.PreTrade.Enabled || !isEntryAction(actionName) {
+		return nil, nil
+	}
+
+	// Spreads and grids are not simulated, their SimulateCommand error keeps them out while the limits are enforced
+
+	sim, violations, err := s.simulateEntry(ctx, action, actionName)
+	if err != nil {
+		return nil, errors.Wrap(err, "pre-trade simulation error")
+	}
+
+	if len(violations) > 0 {
+		return sim, errors.Errorf("rejected by pre-trade risk limits: %s", strings.Join(violations, "; "))
+	}
+
+	return sim, nil
+}
+
+// checkFallbackEntry runs the guards that pause entries before the exchange enters the rest of an expired limit
+// entry at market. The agent already decided the entry, so its flip, confidence and loss streak gates are not rerun.
+func (s *Strategy) checkFallbackEntry(ctx context.Context, side string) error {
+	if s.blackout.Load() {
+		return errors.New("trading is paused by the blackout")
+	}
+
+	actionName := "exchange.open_long_position"
+	if side == exchange.PositionSideShort {
+		actionName = "exchange.open_short_position"
+	}
+
+	if s.priceDivergence != nil {
+		if blocked, reason := s.priceDivergence.IsEntryBlocked(); blocked {
+			return errors.New(reason)
+		}
+	}
+
+	if reason := s.deadManReason(actionName); reason != "" {
+		return errors.New(reason)
+	}
+
+	if reason := s.checkRisk(actionName); reason != "" {
+		return errors.New(reason)
+	}
+
+	// Without args the margin is estimated for a full entry, which keeps the cap on the safe side
+	if reason := s.checkExposure(ctx, &ttypes.Action{Name: actionName}, actionName); reason != "" {
+		return errors.New(reason)
+	}
+
+	return nil
+}
+
+// entrySide returns the side an open position action enters, empty for other actions
+func entrySide(actionName string) string {
+	switch actionName {
+	case "exchange.open_long_position":
+		return exchange.PositionSideLong
+	case "exchange.open_short_position":
+		return exchange.PositionSideShort
+	default:
+		return ""
+	}
+}
+
+// isEntryAction returns whether an action adds exposure: an open position, a spread or a grid. Every entry guard
+// checks the actions it matches
+func isEntryAction(actionName string) bool {
+	return entrySide(actionName) != "" || actionName == "spread.open_spread" || actionName == "exchange.start_grid"
+}
+
+// checkFlipGuard returns why an entry reversing a recent one is held back, empty when it may execute
+func (s *Strategy) checkFlipGuard(actionName string, args map[string]string) string {
+	side := entrySide(actionName)
+	if s.flipGuard == nil || side == "" {
+		return ""
+	}
+
+	// Every validation pass of a cycle records the request, they count once per kline
+	s.flipGuard.Request(side)
+
+	confidence, _ := journal.ParseConfidence(args["confidence"])
+	return s.flipGuard.Check(side, confidence)
+}
+
+// recordEntry tells the flip guard about an executed entry
+func (s *Strategy) recordEntry(actionName string) {
+	if side := entrySide(actionName); s.flipGuard != nil && side != "" {
+		s.flipGuard.OnEntry(side)
+	}
+}
+
+// executeBatch validates all actions before executing them in order, aborting the remainder on the first failure
+func (s *Strategy) executeBatch(ctx context.Context, chatSession ttypes.ISession, msgs []*ttypes.Message, actions []*ttypes.Action, retryTime int) {
+	if len(actions) > MaxBatchActions {
+		errMsg := fmt.Sprintf("Batch of %d commands rejected, at most %d commands can be executed in one response. No command was executed.", len(actions), MaxBatchActions)
+		s.feedbackCmdExecuteResult(ctx, chatSession, errMsg)
+		s.retryAction(ctx, chatSession, msgs, errMsg, retryTime)
+		return
+	}
+
+	commands := make([]*ttypes.Action, len(actions))
+	sims := make([]string, 0)
+
+	for i, action := range actions {
+		actionName := action.Name
+		if !strings.Contains(actionName, ".") {
+			actionName = "exchange." + actionName
+		}
+
+		if err := s.world.ValidateCommand(actionName, action.Args); err != nil {
+			log.WithError(err).WithField("action", actionName).Warn("invalid batch action")
+			errMsg := fmt.Sprintf("Batch rejected, step %d command %s is invalid, reason: %s. No command was executed.", i+1, action.JSON(), err.Error())
+			s.feedbackCmdExecuteResult(ctx, chatSession, errMsg)
+			s.markCycleFailed(ctx)
+			s.retryAction(ctx, chatSession, msgs, errMsg, retryTime)
+			return
+		}
+
+		cmdName, cmdArgs := s.fadeCommand(actionName, action.Args)
+		commands[i] = &ttypes.Action{Name: cmdName, Args: cmdArgs}
+
+		sim, err := s.validateAction(ctx, commands[i], cmdName)
+		if err != nil {
+			log.WithError(err).WithField("action", cmdName).Warn("batch action validation failed")
+			errMsg := fmt.Sprintf("Batch rejected, step %d command %s is invalid, reason: %s. No command was executed.", i+1, action.JSON(), err.Error())
+			if sim != nil {
+				errMsg = fmt.Sprintf("%s\n%s", errMsg, sim.String())
+			}
+
+			s.feedbackCmdExecuteResult(ctx, chatSession, errMsg)
+			s.retryAction(ctx, chatSession, msgs, errMsg, retryTime)
+			return
+		}
+
+		if sim != nil {
+			sims = append(sims, fmt.Sprintf("Step %d %s:\n%s", i+1, action.JSON(), sim.String()))
+		}
+	}
+
+	if !s.staleGuard(ctx, chatSession, msgs, actions, retryTime) {
+		return
+	}
+
+	if len(sims) > 0 && s.PreTrade.Confirm && ctx.Value(tradeConfirmedKey{}) == nil {
+		simText := strings.Join(sims, "\n")
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("Pre-trade simulation for the batch:\n%s", simText))
+		newMsgs := append(msgs, []*ttypes.Message{
+			{
+				Text: fmt.Sprintf("Your proposed commands were simulated before execution:\n%s", simText),
+			},
+			{
+				Text: "Confirm them by responding with the same actions JSON, or respond with revised actions (e.g. a smaller risk or no_action).",
+			},
+		}...)
+		s.agentAction(context.WithValue(ctx, tradeConfirmedKey{}, true), chatSession, newMsgs, retryTime)
+		return
+	}
+
+	for _, simText := range sims {
+		s.replyMsg(ctx, chatSession, simText)
+	}
+
+	steps := make([]string, 0, len(actions))
+	for i, action := range actions {
+		err := s.world.SendCommand(ctx, commands[i].Name, commands[i].Args)
+		if err != nil {
+			log.WithError(err).WithField("action", commands[i].Name).Error("env send batch cmd error")
+			steps = append(steps, fmt.Sprintf("%d. %s failed, reason: %s", i+1, action.JSON(), err.Error()))
+			for j := i + 1; j < len(actions); j++ {
+				steps = append(steps, fmt.Sprintf("%d. %s aborted", j+1, actions[j].JSON()))
+			}
+
+			errMsg := fmt.Sprintf("Batch aborted at step %d:\n%s", i+1, strings.Join(steps, "\n"))
+			s.feedbackCmdExecuteResult(ctx, chatSession, errMsg)
+			s.retryAction(ctx, chatSession, msgs, errMsg, retryTime)
+			return
+		}
+
+		s.recordEntry(commands[i].Name)
+		steps = append(steps, fmt.Sprintf("%d. %s executed successfully", i+1, action.JSON()))
+	}
+
+	s.feedbackCmdExecuteResult(ctx, chatSession, fmt.Sprintf("Batch executed successfully by entity:\n%s", strings.Join(steps, "\n")))
+}
+
+// confirmEntry echoes the pre-trade simulation of a validated entry and, when confirmation is enabled, asks the agent
+// once to confirm it and executes the confirmed action instead. It returns whether to execute now
+func (s *Strategy) confirmEntry(ctx context.Context, chatSession ttypes.ISession, msgs []*ttypes.Message, action *ttypes.Action, sim *utils.TradeSimulation, retryTime int) bool {
+	if !s.PreTrade.Confirm || ctx.Value(tradeConfirmedKey{}) != nil {
+		s.replyMsg(ctx, chatSession, sim.String())
+		return true
+	}
+
+	// The confirmed response is executed without another simulation round trip
+	s.replyMsg(ctx, chatSession, fmt.Sprintf("Pre-trade simulation for %s:\n%s", action.JSON(), sim.String()))
+	newMsgs := append(msgs, []*ttypes.Message{
+		{
+			Text: fmt.Sprintf("Your proposed command %s was simulated before execution:\n%s", action.JSON(), sim.String()),
+		},
+		{
+			Text: "Confirm it by responding with the same action JSON, or respond with a revised action (e.g. a smaller risk or no_action).",
+		},
+	}...)
+	s.agentAction(context.WithValue(ctx, tradeConfirmedKey{}, true), chatSession, newMsgs, retryTime)
+	return false
+}
+
 func (s *Strategy) handleChatMessage(ctx context.Context, chatSession *chat.ChatSession, msg *ttypes.Message) {
 	log.WithField("msg", msg).Info("new message")
+	defer s.recoverPanic(ctx, "chat")
+
+	if strings.TrimSpace(msg.Text) == "/resume" && chatSession.HasRole(ttypes.RoleAdmin) {
+		if !s.resumeTrading(ctx, "chat") {
+			s.replyMsg(ctx, chatSession, "LLM-driven trading is not paused.")
+		}
+		return
+	}
+
+	if question, ok := parseChatCommand(msg.Text, "/ask"); ok && chatSession.HasRole(ttypes.RoleAdmin) {
+		s.handleAsk(ctx, chatSession, question)
+		return
+	}
+
+	if strings.TrimSpace(msg.Text) == "/status" && chatSession.HasRole(ttypes.RoleAdmin) {
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("Status of %s:\n%s", s.Symbol, s.askState()))
+		return
+	}
+
+	if strings.TrimSpace(msg.Text) == "/ack" && chatSession.HasRole(ttypes.RoleAdmin) {
+		s.replyMsg(ctx, chatSession, s.ackHeartbeat(ctx, "chat"))
+		return
+	}
+
+	if strings.TrimSpace(msg.Text) == "/reconcile" && chatSession.HasRole(ttypes.RoleAdmin) {
+		s.replyMsg(ctx, chatSession, s.reconcileReply(ctx, "chat"))
+		return
+	}
+
+	if note, ok := parseChatCommand(msg.Text, "/note"); ok && chatSession.HasRole(ttypes.RoleAdmin) {
+		s.handleNote(ctx, chatSession, note)
+		return
+	}
+
+	if args, ok := parseChatCommand(msg.Text, "/samples"); ok && chatSession.HasRole(ttypes.RoleAdmin) {
+		s.handleSamples(ctx, chatSession, args)
+		return
+	}
+
+	if args, ok := parseChatCommand(msg.Text, "/throttle"); ok && chatSession.HasRole(ttypes.RoleAdmin) {
+		s.handleThrottle(ctx, chatSession, args)
+		return
+	}
+
 	s.agentAction(ctx, chatSession, []*ttypes.Message{msg}, MaxRetryTime)
 }
 
+// parseChatCommand returns the argument text of a "<command> <text>" chat command
+func parseChatCommand(text string, command string) (string, bool) {
+	text = strings.TrimSpace(text)
+	if text != command && !strings.HasPrefix(text, command+" ") {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(text, command)), true
+}
+
 func (s *Strategy) handleEnvEvent(ctx context.Context, session ttypes.ISession, evt ttypes.IEvent) {
 	log.WithField("event", evt).Info("handle env event")
+	defer s.recoverPanic(ctx, "agent")
+
+	// Recorded events whose data could not be restored are replayed as their prompts
+	if _, ok := evt.(*eventlog.ReplayedEvent); ok {
+		s.handleDefaultEvent(ctx, session, evt)
+		return
+	}
+
+	s.observeCycle(session, evt)
+	ctx = withPromptSection(ctx, eventSection(evt.GetType()))
 
 	switch evt.GetType() {
 	case "position_changed":
@@ -589,32 +2177,215 @@ func (s *Strategy) handleEnvEvent(ctx context.Context, session ttypes.ISession,
 		} else {
 			log.WithField("eventType", evt.GetType()).Warn("event data Type not match")
 		}
+	case exchange.EventPositionDiverged:
+		s.handlePositionDiverged(ctx, session, evt)
+	case bridge.EventExternalCommand:
+		// executed once by handleBridgeEvent, the result is stashed for the admin sessions
 	case "update_finish":
-		s.handleUpdateFinish(ctx, session)
+		cycleCtx, cancel := s.withCycleDeadline(ctx)
+		defer cancel()
+
+		s.handleUpdateFinish(cycleCtx, session)
+		s.checkCycleDeadline(cycleCtx, session)
+
+		if s.StateSnapshot.Enabled {
+			if err := s.captureSnapshot(ctx); err != nil {
+				log.WithError(err).Warn("capture snapshot failed")
+			}
+		}
 	default:
 		s.handleDefaultEvent(ctx, session, evt)
 	}
 }
 
-func (s *Strategy) handleKlineChanged(ctx context.Context, session ttypes.ISession, klineWindow *types.KLineWindow) {
-	log.WithField("kline", klineWindow).Info("handle klineWindow values changed")
+// handleBridgeEvent publishes env events to the bridge and executes external commands
+func (s *Strategy) handleBridgeEvent(ctx context.Context, evt ttypes.IEvent) {
+	s.publishBridge(ctx, &bridge.OutboundMessage{
+		Type:    evt.GetType(),
+		Prompts: evt.ToPrompts(),
+	})
+
+	if evt.GetType() != bridge.EventExternalCommand {
+		return
+	}
+
+	msg, ok := evt.GetData().(*bridge.InboundMessage)
+	if !ok {
+		log.WithField("eventType", evt.GetType()).Warn("event data Type not match")
+		return
+	}
+
+	cmd := msg.Command
+	if isResumeCommand(cmd) {
+		if !s.resumeTrading(ctx, msg.Source) {
+			s.publishBridge(ctx, &bridge.OutboundMessage{Type: "command_result", Text: "LLM-driven trading is not paused."})
+		}
+		return
+	}
+
+	if isAckCommand(cmd) {
+		s.publishBridge(ctx, &bridge.OutboundMessage{Type: "command_result", Text: s.ackHeartbeat(ctx, msg.Source)})
+		return
+	}
+
+	if isReconcileCommand(cmd) {
+		s.publishBridge(ctx, &bridge.OutboundMessage{Type: "command_result", Text: s.reconcileReply(ctx, msg.Source)})
+		return
+	}
+
+	if !strings.Contains(cmd, ".") {
+		cmd = "exchange." + cmd
+	}
+
+	result := fmt.Sprintf("External command %s from %s executed successfully.", cmd, msg.Source)
+	if err := s.executeExternalCommand(ctx, cmd, msg.Args); err != nil {
+		log.WithError(err).WithField("cmd", cmd).Error("external command error")
+		result = fmt.Sprintf("External command %s from %s failed, reason: %s", cmd, msg.Source, err.Error())
+	}
+
+	s.publishBridge(ctx, &bridge.OutboundMessage{Type: "command_result", Text: result})
+
+	s.adminMu.Lock()
+	sessions := append([]ttypes.ISession{}, s.adminSessions...)
+	s.adminMu.Unlock()
+
+	for _, session := range sessions {
+		s.notifyMsg(ctx, session, ttypes.SeverityAction, result)
+		s.stashMsg(ctx, session, result)
+	}
+}
+
+// executeExternalCommand runs a command sent from outside the decision loop through the checks of the agent's commands
+func (s *Strategy) executeExternalCommand(ctx context.Context, cmd string, args map[string]string) error {
+	if err := s.world.ValidateCommand(cmd, args); err != nil {
+		return err
+	}
+
+	if _, err := s.validateAction(ctx, &ttypes.Action{Name: cmd, Args: args}, cmd); err != nil {
+		return err
+	}
+
+	if err := s.world.SendCommand(ctx, cmd, args); err != nil {
+		return err
+	}
+
+	s.recordEntry(cmd)
+	return nil
+}
+
+// publishBridge publishes a message to the bridge output channel, if enabled
+func (s *Strategy) publishBridge(ctx context.Context, msg *bridge.OutboundMessage) {
+	if s.bridge == nil {
+		return
+	}
+
+	msg.Time = s.clock.Now()
+	msg.Symbol = s.Symbol
+	msg.CycleID = ttypes.CycleIDFromContext(ctx)
+
+	if err := s.bridge.Publish(ctx, msg); err != nil {
+		log.WithError(err).WithField("type", msg.Type).Warn("bridge publish error")
+	}
+}
+
+func (s *Strategy) handleKlineChanged(ctx context.Context, session ttypes.ISession, klineWindow *types.KLineWindow) {
+	log.WithField("kline", klineWindow).Info("handle klineWindow values changed")
+
+	window := s.promptKlineWindow(*klineWindow)
+	msg := fmt.Sprintf("KLine data changed:\n%s", utils.FormatKLineWindowWithPrecision(window, s.MaxNum, s.Precision))
+	if len(window) < len(*klineWindow) {
+		msg += fmt.Sprintf("\nNote: except the latest %d, each row merges several candlesticks to cover %d klines\n", s.KlinePrompt.Recent, len(*klineWindow))
+	}
+	if text, ok := s.formatPrompt(prompt.FormatterKline, utils.KLineTemplateData(window, s.MaxNum)); ok {
+		msg = text
+	}
+
+	session.SetAttribute("kline", klineWindow)
+	s.stashMsg(ctx, session, msg)
+
+	for _, transformed := range s.klineTransformPrompts(*klineWindow) {
+		s.stashMsg(ctx, session, transformed)
+	}
+
+	s.processPendingReflections(ctx)
+
+	// Cycles fired by price alerts mid-candle resend the same closed klines
+	if klineWindow.Len() > 0 {
+		endTime := (*klineWindow)[klineWindow.Len()-1].EndTime.Time()
+		if endTime.Equal(s.lastKlineTime) {
+			return
+		}
+		s.lastKlineTime = endTime
+	}
+
+	if s.flipGuard != nil {
+		s.flipGuard.OnKline()
+	}
+}
+
+// promptKlineWindow returns the kline window shown in prompts, downsampled to max_num rows when configured
+func (s *Strategy) promptKlineWindow(window types.KLineWindow) types.KLineWindow {
+	if !s.KlinePrompt.Downsample {
+		return window
+	}
 
-	msg := fmt.Sprintf("KLine data changed:\n%s", utils.FormatKLineWindow(*klineWindow, s.MaxNum))
+	return utils.DownsampleKLineWindow(window, s.MaxNum, s.KlinePrompt.Recent)
+}
 
-	session.SetAttribute("kline", klineWindow)
-	s.stashMsg(ctx, session, msg)
+// klineTransformPrompts returns the Heikin-Ashi and renko views of the kline window, when configured
+func (s *Strategy) klineTransformPrompts(window types.KLineWindow) []string {
+	msgs := make([]string, 0)
+
+	if s.KlinePrompt.HeikinAshi && len(window) > 0 {
+		heikinAshi := s.promptKlineWindow(utils.HeikinAshi(window))
+		msgs = append(msgs, fmt.Sprintf("Heikin-Ashi candlesticks of the klines, smoothing out the noise:\n%s",
+			utils.FormatKLineWindowWithPrecision(heikinAshi, s.MaxNum, s.Precision)))
+	}
+
+	if s.KlinePrompt.Renko {
+		brickSize := s.KlinePrompt.RenkoBrickSize
+		if brickSize <= 0 {
+			brickSize = utils.AverageTrueRange(window, 14)
+		}
+
+		if brickSize > 0 {
+			bricks := utils.RenkoBricks(window, brickSize)
+			msgs = append(msgs, fmt.Sprintf("Renko view of the klines, ignoring time and noise:\n%s",
+				utils.FormatRenkoBricks(bricks, brickSize, s.MaxNum, s.Precision.Price)))
+		}
+	}
+
+	return msgs
 }
 
 func (s *Strategy) handleExchangeIndicatorChanged(ctx context.Context, session ttypes.ISession, indicator *exchange.ExchangeIndicator) {
 	log.WithField("indicator", indicator).Info("handle indicator changed")
 
-	messages := indicator.ToPrompts(s.MaxNum)
-
-	for _, msg := range messages {
+	for _, msg := range s.indicatorPrompts(indicator) {
 		s.stashMsg(ctx, session, msg)
 	}
 }
 
+// indicatorPrompts renders the indicator with its formatter template, if any
+func (s *Strategy) indicatorPrompts(indicator *exchange.ExchangeIndicator) []string {
+	if text, ok := s.formatPrompt(string(indicator.Type), indicator.TemplateData(s.MaxNum)); ok {
+		return []string{text}
+	}
+
+	return indicator.ToPrompts(s.MaxNum)
+}
+
+// formatPrompt renders event data with the user-defined formatter template of the event type, if any
+func (s *Strategy) formatPrompt(eventType string, data map[string]interface{}) (string, bool) {
+	text, ok, err := s.formatters.Format(eventType, data)
+	if err != nil {
+		log.WithError(err).WithField("eventType", eventType).Warn("Failed to format prompt, using built-in format")
+		return "", false
+	}
+
+	return text, ok
+}
+
 func (s *Strategy) handleDefaultEvent(ctx context.Context, session ttypes.ISession, evt ttypes.IEvent) {
 	messages := evt.ToPrompts()
 	log.WithField("event", evt.GetType()).WithField("messages", messages).Info("handle_default_event")
@@ -634,11 +2405,25 @@ func (s *Strategy) handleFngChanged(ctx context.Context, session ttypes.ISession
 			"Use this as a macro risk filter, not as a signal for the specific asset.",
 		*fng,
 	)
+	if text, ok := s.formatPrompt(prompt.FormatterFng, map[string]interface{}{"Value": *fng}); ok {
+		msg = text
+	}
 	session.SetAttribute("fng_msg", &ttypes.Message{
-		Text: msg,
+		Text:     msg,
+		DataTime: time.Now(),
+		Section:  sectionEvents,
 	})
 }
 
+// formatPrice formats a price with the configured precision, falling back to the market's price precision
+func (s *Strategy) formatPrice(position *exchange.PositionX, price fixedpoint.Value) string {
+	if s.Precision.Price.IsSet() {
+		return utils.FormatNumber(price.Float64(), s.Precision.Price)
+	}
+
+	return position.Market.FormatPrice(price)
+}
+
 func (s *Strategy) handlePositionChanged(_ctx context.Context, session ttypes.ISession, position *exchange.PositionX) {
 	log.WithField("position", position).Info("handle position changed")
 
@@ -652,20 +2437,20 @@ func (s *Strategy) handlePositionChanged(_ctx context.Context, session ttypes.IS
 				side = "long"
 			}
 
-			msg = fmt.Sprintf("The current position is %s with %dx leverage, average cost: %.3f, and accumulated profit: %.3f%% (%.3f %s).",
+			msg = fmt.Sprintf("The current position is %s with %dx leverage, average cost: %s, and accumulated profit: %s (%s %s).",
 				side,
 				s.Leverage.Int(),
-				position.AverageCost.Float64(),
-				position.AccumulatedProfit.Float64(),
-				position.AccumulatedProfitValue.Float64(),
+				utils.FormatNumber(position.AverageCost.Float64(), s.Precision.Price),
+				utils.FormatPercent(position.AccumulatedProfit.Float64(), s.Precision.Percent),
+				utils.FormatNumber(position.AccumulatedProfitValue.Float64(), s.Precision.Price),
 				position.Market.QuoteCurrency)
 
 			if position.TpTriggerPx != nil {
-				msg += fmt.Sprintf("\nThe current position's take-profit trigger price is %s.", position.Market.FormatPrice(*position.TpTriggerPx))
+				msg += fmt.Sprintf("\nThe current position's take-profit trigger price is %s.", s.formatPrice(position, *position.TpTriggerPx))
 			}
 
 			if position.SlTriggerPx != nil {
-				msg += fmt.Sprintf("\nThe current position's stop-loss trigger price is %s.", position.Market.FormatPrice(*position.SlTriggerPx))
+				msg += fmt.Sprintf("\nThe current position's stop-loss trigger price is %s.", s.formatPrice(position, *position.SlTriggerPx))
 			}
 
 			profits := position.GetProfitValues()
@@ -675,12 +2460,76 @@ func (s *Strategy) handlePositionChanged(_ctx context.Context, session ttypes.IS
 
 			msg = msg + fmt.Sprintf("\nThe profits of the recent %d periods: [%s], and the holding period: %d.",
 				s.MaxNum,
-				utils.JoinFloatSlicePercentage([]float64(profits), " "),
+				utils.JoinPercents([]float64(profits), " ", s.Precision.Percent),
 				position.GetHoldingPeriod())
+
+			takeProfit := 0.0
+			if position.TpTriggerPx != nil {
+				takeProfit = position.TpTriggerPx.Float64()
+			}
+
+			excursion := utils.ComputeTradeExcursion(*kline, side, position.AverageCost.Float64(), position.GetStopLossPrice(), takeProfit, position.GetHoldingPeriod())
+			if excursion != nil && position.Excursion.Active {
+				// The tracked excursions also cover the klines that left the window
+				excursion.MAEPercent = math.Min(excursion.MAEPercent, position.Excursion.MAEPercent)
+				excursion.MFEPercent = math.Max(excursion.MFEPercent, position.Excursion.MFEPercent)
+			}
+			if excursion != nil {
+				msg += "\n" + excursion.String()
+			}
+
+			ratchet := s.exchange.ProfitRatchet()
+			if ratchet != nil {
+				msg += "\n" + ratchet.Prompt(kline.GetClose().Float64())
+			}
+
+			notional := position.GetBase().Abs().Mul(kline.GetClose()).Float64()
+			funding := s.fundingCarryPrompt(_ctx, side, notional, position.Market.QuoteCurrency)
+			if funding != "" {
+				msg += "\n" + funding
+			}
+
+			data := map[string]interface{}{
+				"Side":          side,
+				"Leverage":      s.Leverage.Int(),
+				"AverageCost":   position.AverageCost.Float64(),
+				"ProfitPercent": position.AccumulatedProfit.Float64(),
+				"ProfitValue":   position.AccumulatedProfitValue.Float64(),
+				"QuoteCurrency": position.Market.QuoteCurrency,
+				"Profits":       []float64(profits),
+				"HoldingPeriod": position.GetHoldingPeriod(),
+			}
+			if position.TpTriggerPx != nil {
+				data["TakeProfit"] = position.TpTriggerPx.Float64()
+			}
+			if position.SlTriggerPx != nil {
+				data["StopLoss"] = position.SlTriggerPx.Float64()
+			}
+			if funding != "" {
+				data["Funding"] = funding
+			}
+			if ratchet != nil {
+				data["Ratchet"] = ratchet.Prompt(kline.GetClose().Float64())
+			}
+			if excursion != nil {
+				data["TimeInTrade"] = excursion.TimeInTrade.String()
+				data["MAEPercent"] = excursion.MAEPercent
+				data["MFEPercent"] = excursion.MFEPercent
+				data["ATR"] = excursion.ATR
+			}
+			if text, ok := s.formatPrompt(prompt.FormatterPosition, data); ok {
+				msg = text
+			}
+		}
+
+		if permitted := s.exchange.VolatilityLeverage(); permitted != nil {
+			msg += "\n" + permitted.Prompt()
 		}
 
 		session.SetAttribute("position_msg", &ttypes.Message{
-			Text: msg,
+			Text:     msg,
+			DataTime: time.Now(),
+			Section:  sectionPosition,
 		})
 	}
 }
@@ -702,8 +2551,41 @@ func (s *Strategy) handleUpdateFinish(ctx context.Context, session ttypes.ISessi
 			tempMsgs = append(tempMsgs, posMsg)
 		}
 
+		// track record
+		if trackRecord := s.currentTrackRecord(); trackRecord != "" {
+			tempMsgs = append(tempMsgs, &ttypes.Message{
+				Text:    trackRecord,
+				Section: sectionRisk,
+			})
+		}
+
+		// loss streak throttle
+		if lossStreakMsg := s.lossStreakMsg(); lossStreakMsg != "" {
+			tempMsgs = append(tempMsgs, &ttypes.Message{
+				Text:    lossStreakMsg,
+				Section: sectionRisk,
+			})
+		}
+
+		// drawdown risk budget
+		if riskBudgetMsg := s.riskBudgetMsg(); riskBudgetMsg != "" {
+			tempMsgs = append(tempMsgs, &ttypes.Message{
+				Text:    riskBudgetMsg,
+				Section: sectionRisk,
+			})
+		}
+
+		// risk limit pause
+		if riskMsg := s.riskMsg(); riskMsg != "" {
+			tempMsgs = append(tempMsgs, &ttypes.Message{
+				Text:    riskMsg,
+				Section: sectionRisk,
+			})
+		}
+
+		actions := s.world.Actions()
 		actionTips := make([]string, 0)
-		for _, ac := range s.world.Actions() {
+		for _, ac := range actions {
 			actionTips = append(actionTips, ac.String())
 		}
 
@@ -741,16 +2623,56 @@ func (s *Strategy) handleUpdateFinish(ctx context.Context, session ttypes.ISessi
 			templateData["MemoryEnabled"] = false
 		}
 
+		texts := make([]string, 0, len(tempMsgs))
+		for _, msg := range tempMsgs {
+			texts = append(texts, msg.Text)
+		}
+		situation := strings.Join(texts, "\n")
+		if s.lastAssessment != nil {
+			situation = fmt.Sprintf("Market assessment: %s\n%s", s.lastAssessment.String(), situation)
+		}
+
+		// Add the general rules and similar past trades relevant to the current situation
+		injected := make([]*memory.Memory, 0)
+		if s.memoryRetriever != nil {
+			rules := s.retrieveRelevantMemories(ctx, situation, memory.MemoryKindSemantic, 2)
+			templateData["RelevantRules"] = memorySummaries(rules, 0)
+			if s.Memory.KnowledgeBasePath != "" {
+				templateData["RelevantPlaybooks"] = memorySummaries(s.retrieveRelevantMemories(ctx, situation, memory.MemoryKindKnowledge, s.Memory.KnowledgeTopK), 300)
+			}
+			episodes := s.retrieveRelevantMemories(ctx, situation, memory.MemoryKindEpisodic, s.Memory.RetrievalTopK)
+			templateData["RelevantMemories"] = memorySummaries(episodes, 150)
+
+			injected = append(append(injected, rules...), episodes...)
+		}
+
+		templateData["Examples"] = s.selectSamples(situation, actions)
+
 		prompt, err := xtemplate.Render(prompt.ThoughtTpl, templateData)
 		if err != nil {
 			s.replyMsg(ctx, session, fmt.Sprintf("Render prompt error: %s", err.Error()))
 			return
 		}
 
+		// Memories are retrieved with the full sections, only the agent sees the compressed ones
+		tempMsgs = s.dedupeMsgs(ctx, tempMsgs)
+
+		// The changes since the last cycle come first, so the agent focuses on the new information
+		if diffMsg := s.cycleDiffMsg(session); diffMsg != "" {
+			tempMsgs = append([]*ttypes.Message{{Text: diffMsg, Section: sectionHistory}}, tempMsgs...)
+		}
+
+		if s.StaleGuard.Enabled {
+			tempMsgs = stampMsgs(tempMsgs)
+		}
+
 		tempMsgs = append(tempMsgs, &ttypes.Message{
-			Text: prompt,
+			Text:    prompt,
+			Section: sectionInstructions,
 		})
+		s.logContextBudget(ctx, tempMsgs, templateData)
 
+		ctx = s.withPromptSnapshot(s.withSampling(withInjectedMemories(ctx, injected)), session, tempMsgs)
 		s.agentAction(ctx, session, tempMsgs, MaxRetryTime)
 	}
 
@@ -761,7 +2683,9 @@ func (s *Strategy) stashMsg(ctx context.Context, session ttypes.ISession, msg st
 	tempMsgsRef, _ := session.GetAttribute("tempMsgs")
 	tempMsgs, _ := tempMsgsRef.([]*ttypes.Message)
 	tempMsgs = append(tempMsgs, &ttypes.Message{
-		Text: msg,
+		Text:     msg,
+		DataTime: time.Now(),
+		Section:  promptSection(ctx),
 	})
 
 	log.WithField("tempMsgs", tempMsgs).Info("session tmp msgs")
@@ -828,11 +2752,17 @@ func (s *Strategy) handlePositionClosed(ctx context.Context, session ttypes.ISes
 		posData.Timestamp.Format(time.RFC3339))
 
 	// Use Strategy's own reply mechanism for notification
-	s.replyMsg(ctx, session, message)
+	s.notifyMsg(ctx, session, ttypes.SeverityAction, message)
 
 	// Store this in session for later use
 	session.SetAttribute("last_closed_position", posData)
 
+	// Capture the trade context at close time, so deferred reflections keep the regime and decision of this trade
+	tradeContext := s.newTradeContext(session, posData)
+	s.recordTradeClosed(posData, tradeContext)
+	s.recordLossStreak(ctx, posData.ProfitAndLoss)
+	s.updateRiskBudget(ctx)
+
 	// Add a message to the chat
 	s.stashMsg(ctx, session, fmt.Sprintf("📊 Position closed for %s with %s: %.2f (%.2f%%)",
 		posData.Symbol, pnlStr, posData.ProfitAndLoss, posData.ProfitAndLossPercent))
@@ -848,6 +2778,13 @@ func (s *Strategy) handlePositionClosed(ctx context.Context, session ttypes.ISes
 		return
 	}
 
+	if s.tradeCompleteTrigger != nil {
+		if ok, reason := s.tradeCompleteTrigger.ShouldFire(posData.ProfitAndLoss); !ok {
+			log.WithField("reason", reason).Info("Trade reflection skipped by trigger filters")
+			return
+		}
+	}
+
 	// Get reflection path from config (with default if not set)
 	reflectionPath := "memory-bank/reflections/"
 	if s.ReflectionPath != "" {
@@ -856,15 +2793,172 @@ func (s *Strategy) handlePositionClosed(ctx context.Context, session ttypes.ISes
 
 	// Generate reflection if agent is available
 	if s.agent != nil {
+		if s.CounterfactualHorizon > 0 {
+			// Wait for post-close klines so the reflection can include hold-longer counterfactuals
+			s.pendingReflections = append(s.pendingReflections, &pendingReflection{
+				session:      session,
+				posData:      posData,
+				tradeContext: tradeContext,
+				remaining:    s.CounterfactualHorizon,
+			})
+			s.stashMsg(ctx, session, fmt.Sprintf("Trade reflection deferred for %d klines, it will be saved to %s", s.CounterfactualHorizon, reflectionPath))
+			return
+		}
+
 		s.stashMsg(ctx, session, fmt.Sprintf("Generating trade reflection... This will be saved to %s", reflectionPath))
-		s.generateAndSaveReflection(ctx, session, posData)
+		s.generateAndSaveReflection(ctx, session, posData, tradeContext, 0)
 	} else {
 		log.Warn("No agent available to generate trade reflection")
 	}
 }
 
-// generateAndSaveReflection generates a reflection on the closed trade and saves it to a file
-func (s *Strategy) generateAndSaveReflection(ctx context.Context, session ttypes.ISession, posData exchange.PositionClosedEventData) {
+// processPendingReflections generates the deferred reflections whose counterfactual horizon has elapsed
+func (s *Strategy) processPendingReflections(ctx context.Context) {
+	pending := make([]*pendingReflection, 0, len(s.pendingReflections))
+	for _, p := range s.pendingReflections {
+		p.remaining--
+		if p.remaining > 0 {
+			pending = append(pending, p)
+			continue
+		}
+
+		s.generateAndSaveReflection(ctx, p.session, p.posData, p.tradeContext, s.CounterfactualHorizon)
+	}
+
+	s.pendingReflections = pending
+}
+
+// newTradeContext builds the structured context of a closed trade, so reflections and the journal
+// can be filtered by symbol, side, outcome and regime
+func (s *Strategy) newTradeContext(session ttypes.ISession, posData exchange.PositionClosedEventData) *memory.TradeContext {
+	tradeContext := &memory.TradeContext{
+		Symbol:     posData.Symbol,
+		Side:       posData.Side,
+		EntryPrice: posData.EntryPrice,
+		ExitPrice:  posData.ExitPrice,
+		PnL:        posData.ProfitAndLoss,
+		RMultiple:  memory.RMultiple(posData.Side, posData.EntryPrice, posData.ExitPrice, posData.StopLossPrice),
+		DecisionID: s.openDecisionID,
+	}
+	// The regime the agent assessed at entry is preferred over the one detected at close
+	if s.entryAssessment != nil {
+		tradeContext.Regime = utils.AssessmentRegime(s.entryAssessment.Trend)
+	}
+	if kline, ok := s.getKline(session); ok && tradeContext.Regime == "" {
+		tradeContext.Regime = utils.DetectRegime(*kline)
+	}
+	s.openDecisionID = ""
+	s.entryAssessment = nil
+
+	return tradeContext
+}
+
+// recordExecution appends the outcome, latency and the stop-loss and take-profit actually placed by a command to the journal
+func (s *Strategy) recordExecution(evt ttypes.IEvent) {
+	if s.journal == nil {
+		return
+	}
+
+	result, ok := evt.GetData().(exchange.ActionResult)
+	if !ok {
+		log.WithField("eventType", evt.GetType()).Warn("event data Type not match")
+		return
+	}
+
+	execution := &journal.Execution{
+		MarketPrice:   result.MarketPrice,
+		StopLoss:      result.StopLoss,
+		TakeProfit:    result.TakeProfit,
+		Adjustments:   result.Adjustments,
+		LatencyMs:     result.Latency.Milliseconds(),
+		Error:         result.Reason,
+		ErrorCategory: result.ErrorCategory,
+	}
+	if result.RiskSize != nil {
+		execution.RiskPercent = result.RiskSize.RiskPercent
+		execution.Quantity = result.RiskSize.Quantity
+	}
+
+	err := s.journal.Append(&journal.Entry{
+		ID:        uuid.NewString(),
+		Time:      result.Timestamp,
+		Kind:      journal.KindExecution,
+		Symbol:    s.Symbol,
+		Action:    result.Command,
+		Args:      result.Args,
+		CycleID:   result.CycleID,
+		Execution: execution,
+	})
+	if err != nil {
+		log.WithError(err).Warn("Failed to append execution to journal")
+	}
+}
+
+// recordTradeClosed appends the outcome of a closed trade to the journal
+func (s *Strategy) recordTradeClosed(posData exchange.PositionClosedEventData, tradeContext *memory.TradeContext) {
+	if s.journal == nil {
+		return
+	}
+
+	err := s.journal.Append(&journal.Entry{
+		ID:     uuid.NewString(),
+		Time:   posData.Timestamp,
+		Kind:   journal.KindTradeClosed,
+		Symbol: posData.Symbol,
+		Trade: &journal.TradeResult{
+			Side:          posData.Side,
+			EntryPrice:    posData.EntryPrice,
+			ExitPrice:     posData.ExitPrice,
+			PnL:           posData.ProfitAndLoss,
+			PnLPercent:    posData.ProfitAndLossPercent,
+			RMultiple:     tradeContext.RMultiple,
+			HoldingPeriod: posData.HoldingPeriod,
+			MAEPercent:    posData.MAEPercent,
+			MFEPercent:    posData.MFEPercent,
+			Regime:        tradeContext.Regime,
+			CloseReason:   posData.CloseReason,
+			DecisionID:    tradeContext.DecisionID,
+			Faded:         s.Fade.Enabled,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Warn("Failed to append closed trade to journal")
+		return
+	}
+
+	s.updateTrackRecord()
+	s.refreshJournalSamples()
+	s.adjustConfidenceGate()
+	s.reinforceMemories(tradeContext.DecisionID, posData.ProfitAndLoss)
+}
+
+// updateTrackRecord recomputes the rolling per-symbol stats injected into decision prompts
+func (s *Strategy) updateTrackRecord() {
+	entries, err := s.journal.LoadEntries()
+	if err != nil {
+		log.WithError(err).Warn("Failed to load journal for track record")
+		return
+	}
+
+	stats := journal.ComputeSymbolStats(entries, s.Symbol, s.Journal.StatsWindow).String()
+	s.trackRecord.Store(&stats)
+}
+
+// currentTrackRecord returns the rolling per-symbol stats, empty until they are computed
+func (s *Strategy) currentTrackRecord() string {
+	if stats := s.trackRecord.Load(); stats != nil {
+		return *stats
+	}
+
+	return ""
+}
+
+// generateAndSaveReflection generates a reflection on the closed trade and saves it to a file,
+// afterClose is the number of klines observed since the trade was closed
+func (s *Strategy) generateAndSaveReflection(ctx context.Context, session ttypes.ISession, posData exchange.PositionClosedEventData, tradeContext *memory.TradeContext, afterClose int) {
+	// A failed reflection costs a lesson, not the decision cycle that triggered it
+	defer s.isolatePanic("reflection")
+
 	// If no agent is initialized, we can't generate a reflection
 	if s.agent == nil {
 		log.Warn("No agent available to generate trade reflection")
@@ -880,10 +2974,24 @@ func (s *Strategy) generateAndSaveReflection(ctx context.Context, session ttypes
 		"Quantity":      posData.Quantity,
 		"ProfitAndLoss": posData.ProfitAndLoss,
 		"ProfitPercent": posData.ProfitAndLossPercent,
+		"MAEPercent":    posData.MAEPercent,
+		"MFEPercent":    posData.MFEPercent,
 		"CloseReason":   posData.CloseReason,
 		"Timestamp":     posData.Timestamp.Format(time.RFC3339),
 	}
 
+	// Ground the reflection with counterfactual exits replayed on the trade klines
+	if kline, ok := s.getKline(session); ok {
+		counterfactuals := make([]string, 0)
+		for _, cf := range utils.ComputeCounterfactuals(*kline, posData.Side, posData.EntryPrice, posData.ExitPrice, posData.HoldingPeriod, afterClose) {
+			counterfactuals = append(counterfactuals, cf.String())
+		}
+		data["Counterfactuals"] = counterfactuals
+	}
+
+	// Operator commentary attached to the trade with /note
+	data["Notes"] = s.tradeNotes(tradeContext.DecisionID)
+
 	// Use the trade reflection template from prompt.go
 	promptText, err := xtemplate.Render(prompt.TradeReflectionTpl, data)
 	if err != nil {
@@ -892,24 +3000,11 @@ func (s *Strategy) generateAndSaveReflection(ctx context.Context, session ttypes
 	}
 
 	// Use the agent to generate reflection
-	msg := &ttypes.Message{
-		Text: promptText,
-	}
-
-	result, err := s.agent.GenActions(ctx, session, []*ttypes.Message{msg})
-	if err != nil {
-		log.WithError(err).Error("Failed to generate trade reflection")
-		return
-	}
-
-	if result == nil || len(result.Texts) == 0 {
-		log.Error("No reflection text generated")
+	reflectionText, ok := s.genReflection(ctx, session, promptText, posData)
+	if !ok {
 		return
 	}
 
-	// Extract the reflection text from the result
-	reflectionText := result.Texts[0]
-
 	// Save the reflection to a file
 	timestamp := posData.Timestamp.Unix()
 	strategyID := strings.ReplaceAll(posData.StrategyID, " ", "_")
@@ -933,6 +3028,7 @@ func (s *Strategy) generateAndSaveReflection(ctx context.Context, session ttypes
 
 	// Create reflection file content with front matter
 	headerContent := fmt.Sprintf(`---
+schemaVersion: %d
 symbol: %s
 strategyId: %s
 entryPrice: %.4f
@@ -941,11 +3037,13 @@ quantity: %.6f
 profitAndLoss: %.2f
 closeReason: %s
 timestamp: %s
+tradeContext: %s
 ---
 
 # Trade Reflection: %s (%s)
 
 `,
+		memory.CurrentSchemaVersion,
 		posData.Symbol,
 		posData.StrategyID,
 		posData.EntryPrice,
@@ -954,6 +3052,7 @@ timestamp: %s
 		posData.ProfitAndLoss,
 		posData.CloseReason,
 		posData.Timestamp.Format(time.RFC3339),
+		tradeContext.JSON(),
 		posData.Symbol,
 		posData.StrategyID)
 
@@ -968,6 +3067,17 @@ timestamp: %s
 
 	log.WithField("filepath", filepath).Info("Trade reflection saved")
 
+	// Make the new reflection retrievable in the following decision cycles
+	if s.memoryRetriever != nil {
+		mem, err := memory.LoadMemoryFile(filepath)
+		if err != nil {
+			log.WithError(err).Warn("Failed to load saved reflection into retriever")
+		} else {
+			s.memoryRetriever.Add(mem)
+			s.consolidateMemories(ctx, session)
+		}
+	}
+
 	// Store the reflection in session attributes for future reference
 	session.SetAttribute(fmt.Sprintf("reflection_%s", strategyID), reflectionText)
 
@@ -976,6 +3086,122 @@ timestamp: %s
 	s.stashMsg(ctx, session, summaryMsg)
 }
 
+// genReflection generates a reflection and validates its quality, retrying with targeted feedback
+// so that generic or unactionable reflections do not pollute the memory bank
+func (s *Strategy) genReflection(ctx context.Context, session ttypes.ISession, promptText string, posData exchange.PositionClosedEventData) (string, bool) {
+	maxRetries := 2
+	if s.ReflectionMaxRetries != nil {
+		maxRetries = *s.ReflectionMaxRetries
+	}
+
+	msgs := []*ttypes.Message{
+		{
+			Text: promptText,
+		},
+	}
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		result, err := s.agent.GenActions(ctx, session, msgs)
+		if err != nil {
+			log.WithError(err).Error("Failed to generate trade reflection")
+			return "", false
+		}
+
+		if result == nil || len(result.Texts) == 0 {
+			log.Error("No reflection text generated")
+			return "", false
+		}
+
+		// Extract the reflection text from the result
+		reflectionText := result.Texts[0]
+
+		quality := memory.ValidateReflection(reflectionText, posData.EntryPrice, posData.ExitPrice, posData.ProfitAndLoss)
+		if quality.OK() {
+			return reflectionText, true
+		}
+
+		log.WithField("attempt", attempt).
+			WithField("issues", quality.Issues).
+			Warn("Trade reflection rejected by quality check")
+
+		msgs = append(msgs, &ttypes.Message{
+			Text: reflectionText,
+		}, &ttypes.Message{
+			Text: quality.Feedback(),
+		})
+	}
+
+	log.Warn("Discarding trade reflection after exhausting quality retries")
+	return "", false
+}
+
+// getReflectionPath returns the reflection directory from config, with default if not set
+func (s *Strategy) getReflectionPath() string {
+	if s.ReflectionPath != "" {
+		return s.ReflectionPath
+	}
+
+	return "memory-bank/reflections/"
+}
+
+// retrieveRelevantMemories returns the memories of a kind relevant to the current situation
+func (s *Strategy) retrieveRelevantMemories(ctx context.Context, situation string, kind string, topK int) []*memory.Memory {
+	filter := &memory.MemoryFilter{Kind: kind, Symbol: s.Symbol}
+	memories, err := s.memoryRetriever.RetrieveFilteredMemories(ctx, situation, filter, topK)
+	if err != nil {
+		log.WithError(err).WithField("kind", kind).Warn("Failed to retrieve relevant memories")
+		return []*memory.Memory{}
+	}
+
+	return memories
+}
+
+// memorySummaries returns the memories truncated to maxWords for prompts
+func memorySummaries(memories []*memory.Memory, maxWords int) []string {
+	rets := make([]string, 0, len(memories))
+	for _, mem := range memories {
+		rets = append(rets, mem.Summary(maxWords))
+	}
+
+	return rets
+}
+
+// consolidateMemories distills general rules from the latest trade reflections once enough new ones were saved
+func (s *Strategy) consolidateMemories(ctx context.Context, session ttypes.ISession) {
+	s.reflectionsSinceConsolidation++
+	if s.Memory.ConsolidateEvery <= 0 || s.reflectionsSinceConsolidation < s.Memory.ConsolidateEvery {
+		return
+	}
+	s.reflectionsSinceConsolidation = 0
+
+	episodes := make([]*memory.Memory, 0)
+	for _, mem := range s.memoryRetriever.GetMemories() {
+		if mem.Kind == memory.MemoryKindEpisodic && mem.Symbol == s.Symbol {
+			episodes = append(episodes, mem)
+		}
+	}
+	episodes = memory.RetentionPolicy{MaxCount: s.Memory.ConsolidateEvery * 2}.Apply(episodes, time.Now())
+
+	complete := func(ctx context.Context, prompt string) (string, error) {
+		return s.llm.Call(ctx, prompt)
+	}
+
+	rules, err := memory.ConsolidateMemories(ctx, complete, s.Symbol, episodes)
+	if err != nil {
+		log.WithError(err).Warn("Failed to consolidate memories")
+		return
+	}
+
+	mem, err := memory.SaveSemanticMemory(s.getReflectionPath(), s.Symbol, rules, len(episodes), time.Now())
+	if err != nil {
+		log.WithError(err).Warn("Failed to save consolidated rules")
+		return
+	}
+
+	s.memoryRetriever.Add(mem)
+	s.stashMsg(ctx, session, fmt.Sprintf("📚 Distilled general trading rules from %d past trades into %s", len(episodes), mem.ID))
+}
+
 // processMemoryOutput processes memory output from AI and saves it
 func (s *Strategy) processMemoryOutput(ctx context.Context, chatSession ttypes.ISession, memory *ttypes.Memory) {
 	if memory == nil || memory.Content == "" {
@@ -1017,3 +3243,164 @@ func (s *Strategy) processMemoryOutput(ctx context.Context, chatSession ttypes.I
 		s.replyMsg(ctx, chatSession, fmt.Sprintf("💾 Memory saved: %s", memory.Content))
 	}
 }
+
+// recordDecision appends the agent decision to the journal, including no_action decisions with their reasoning
+func (s *Strategy) recordDecision(ctx context.Context, chatSession ttypes.ISession, msgs []*ttypes.Message, result *ttypes.Result, resultText string, model string) {
+	for i, action := range result.AllActions() {
+		// The prompt and completion are recorded once per response, with its first action
+		prompt, completion := "", ""
+		if i == 0 && s.Journal.RecordPrompts {
+			texts := make([]string, 0, len(msgs))
+			for _, msg := range msgs {
+				texts = append(texts, msg.Text)
+			}
+			prompt, completion = strings.Join(texts, "\n"), resultText
+		}
+
+		s.recordAction(ctx, chatSession, action, result.Assessment, result.Thoughts.Summary(), model, prompt, completion)
+	}
+}
+
+// recordResponseParse records in metrics whether the JSON response of the provider parsed, by the response format used
+func (s *Strategy) recordResponseParse(resp *agents.GenResult, parsed bool) {
+	if resp.ResponseFormat == "" {
+		return
+	}
+
+	provider := resp.Model
+	if provider == "" {
+		provider = "unknown"
+	}
+
+	ratio := metrics.RecordResponseParse(provider, resp.ResponseFormat, parsed)
+	log.
+		WithField("provider", provider).
+		WithField("response_format", resp.ResponseFormat).
+		WithField("parsed", parsed).
+		WithField("failure_ratio", ratio).
+		Info("llm response parse recorded")
+}
+
+// recordParseRepair records which JSON repair level made the decision response parse and what it changed
+func (s *Strategy) recordParseRepair(resp *agents.GenResult, repair *utils.ParseRepair) {
+	provider := resp.Model
+	if provider == "" {
+		provider = "unknown"
+	}
+
+	metrics.RecordParseRepair(provider, repair.Level)
+	if repair.Level != utils.RepairLevelNone {
+		log.
+			WithField("provider", provider).
+			WithField("level", repair.Level).
+			WithField("diff", repair.Diff).
+			Info("llm response repaired to parse")
+	}
+}
+
+// journalAssessment converts the market assessment of a decision for the journal
+func journalAssessment(assessment *ttypes.Assessment) *journal.Assessment {
+	if assessment == nil {
+		return nil
+	}
+
+	return &journal.Assessment{
+		Trend:      assessment.Trend,
+		Volatility: assessment.Volatility,
+		KeyLevels:  assessment.KeyLevels,
+		Bias:       assessment.Bias,
+	}
+}
+
+// recordAction records one decided action in the decision stream and the journal
+func (s *Strategy) recordAction(ctx context.Context, chatSession ttypes.ISession, action *ttypes.Action, assessment *ttypes.Assessment, reasoning string, model string, prompt string, completion string) {
+	actionName := action.Name
+	if !strings.Contains(actionName, ".") {
+		actionName = "exchange." + actionName
+	}
+
+	decisionID := uuid.NewString()
+	if actionName == "exchange.open_long_position" || actionName == "exchange.open_short_position" {
+		s.openDecisionID = decisionID
+		s.entryAssessment = assessment
+	}
+
+	decision := &jarvispb.Decision{
+		Id:        decisionID,
+		Timestamp: s.clock.Now().UnixMilli(),
+		Symbol:    s.Symbol,
+		Action:    actionName,
+		Args:      action.Args,
+		Reasoning: reasoning,
+		Model:     model,
+		CycleId:   ttypes.CycleIDFromContext(ctx),
+	}
+	s.lastDecision.Store(decision)
+	if s.grpcServer != nil {
+		s.grpcServer.PublishDecision(decision)
+	}
+
+	if s.journal == nil {
+		return
+	}
+
+	kind := journal.KindDecision
+	if actionName == "exchange.no_action" {
+		kind = journal.KindNoAction
+	}
+
+	err := s.journal.Append(&journal.Entry{
+		ID:         decisionID,
+		Time:       s.clock.Now(),
+		Kind:       kind,
+		Symbol:     s.Symbol,
+		Action:     actionName,
+		Args:       action.Args,
+		Reasoning:  reasoning,
+		Model:      model,
+		CycleID:    ttypes.CycleIDFromContext(ctx),
+		Sampling:   journalSampling(ctx),
+		Memories:   injectedMemories(ctx),
+		Assessment: journalAssessment(assessment),
+		Prompt:     prompt,
+		Completion: completion,
+	})
+	if err != nil {
+		log.WithError(err).Warn("Failed to append decision to journal")
+	}
+
+	if kind != journal.KindNoAction {
+		s.noActionStreak = 0
+		return
+	}
+
+	s.noActionStreak++
+
+	every := s.Journal.NoActionSummaryEvery
+	if every > 0 && s.noActionStreak%every == 0 {
+		s.replyMsg(ctx, chatSession, s.noActionSummary(every))
+	}
+}
+
+// noActionSummary builds a compact "why I'm staying flat" summary from the latest no_action entries
+func (s *Strategy) noActionSummary(num int) string {
+	entries, err := s.journal.Recent(journal.KindNoAction, num)
+	if err != nil {
+		log.WithError(err).Warn("Failed to read no_action entries from journal")
+		return fmt.Sprintf("💤 No action taken for %d consecutive cycles.", s.noActionStreak)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("💤 No action taken for %d consecutive cycles. Why I'm staying flat:\n", s.noActionStreak))
+
+	for _, entry := range entries {
+		reasoning := entry.Reasoning
+		if reasoning == "" {
+			reasoning = "no reasoning provided"
+		}
+
+		builder.WriteString(fmt.Sprintf("- %s: %s\n", entry.Time.Format("01-02 15:04"), reasoning))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,163 @@
+package journal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CalibrationBin counts the trades opened with a stated confidence in [Low, High)
+type CalibrationBin struct {
+	Low           float64
+	High          float64
+	Trades        int
+	Wins          int
+	sumConfidence float64
+}
+
+// WinRate returns the share of winning trades of the bin in [0, 1]
+func (b *CalibrationBin) WinRate() float64 {
+	if b.Trades == 0 {
+		return 0
+	}
+
+	return float64(b.Wins) / float64(b.Trades)
+}
+
+// Calibration compares the confidence stated with entries against the outcome of their trades
+type Calibration struct {
+	Bins   []*CalibrationBin
+	Trades int
+	Brier  float64 // Mean squared error between confidence and outcome, 0.25 is a coin flip stated at 0.5
+}
+
+// ParseConfidence parses a confidence arg, accepting 0-1 and percentages like "70%" or "70"
+func ParseConfidence(val string) (float64, bool) {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return 0, false
+	}
+
+	parsed, err := strconv.ParseFloat(strings.TrimSuffix(val, "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	if strings.HasSuffix(val, "%") || parsed > 1 {
+		parsed = parsed / 100
+	}
+
+	return parsed, true
+}
+
+// ComputeCalibration buckets the closed trades whose entry stated a confidence into equal-width bins.
+// Faded trades are skipped, their outcome belongs to the inverse of the entry.
+func ComputeCalibration(entries []*Entry, bins int) *Calibration {
+	if bins <= 0 {
+		bins = 5
+	}
+
+	calibration := &Calibration{Bins: make([]*CalibrationBin, bins)}
+	for i := range calibration.Bins {
+		calibration.Bins[i] = &CalibrationBin{
+			Low:  float64(i) / float64(bins),
+			High: float64(i+1) / float64(bins),
+		}
+	}
+
+	confidences := make(map[string]float64)
+	for _, entry := range entries {
+		if entry.Kind != KindDecision || entry.ID == "" {
+			continue
+		}
+
+		if confidence, ok := ParseConfidence(entry.Args["confidence"]); ok {
+			confidences[entry.ID] = confidence
+		}
+	}
+
+	sumSquares := 0.0
+	for _, entry := range entries {
+		if entry.Kind != KindTradeClosed || entry.Trade == nil || entry.Trade.Faded {
+			continue
+		}
+
+		confidence, ok := confidences[entry.Trade.DecisionID]
+		if !ok {
+			continue
+		}
+
+		index := int(confidence * float64(bins))
+		if index >= bins {
+			index = bins - 1
+		}
+		if index < 0 {
+			index = 0
+		}
+
+		outcome := 0.0
+		bin := calibration.Bins[index]
+		bin.Trades++
+		bin.sumConfidence += confidence
+		if entry.Trade.PnL > 0 {
+			bin.Wins++
+			outcome = 1
+		}
+
+		calibration.Trades++
+		sumSquares += (confidence - outcome) * (confidence - outcome)
+	}
+
+	if calibration.Trades > 0 {
+		calibration.Brier = sumSquares / float64(calibration.Trades)
+	}
+
+	return calibration
+}
+
+// SuggestMinConfidence returns the lowest bin bound from which the trades stating at least that confidence reach
+// the target win rate. ok is false with fewer than minTrades trades, or when no bound reaches the target with
+// at least a quarter of minTrades trades above it.
+func (c *Calibration) SuggestMinConfidence(targetWinRate float64, minTrades int) (float64, bool) {
+	if c.Trades == 0 || c.Trades < minTrades {
+		return 0, false
+	}
+
+	required := minTrades / 4
+	if required < 1 {
+		required = 1
+	}
+
+	for i, bin := range c.Bins {
+		trades, wins := 0, 0
+		for _, above := range c.Bins[i:] {
+			trades += above.Trades
+			wins += above.Wins
+		}
+
+		if trades >= required && float64(wins)/float64(trades) >= targetWinRate {
+			return bin.Low, true
+		}
+	}
+
+	return 0, false
+}
+
+// String describes the calibration curve as the realized win rate against the mean stated confidence of each bin
+func (c *Calibration) String() string {
+	if c.Trades == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(c.Bins))
+	for _, bin := range c.Bins {
+		if bin.Trades == 0 {
+			continue
+		}
+
+		parts = append(parts, fmt.Sprintf("%.1f-%.1f stated %.0f%% won %.0f%% (%d)",
+			bin.Low, bin.High, bin.sumConfidence/float64(bin.Trades)*100, bin.WinRate()*100, bin.Trades))
+	}
+
+	return fmt.Sprintf("confidence calibration over %d trades: %s, Brier %.3f", c.Trades, strings.Join(parts, "; "), c.Brier)
+}
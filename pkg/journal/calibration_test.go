@@ -0,0 +1,71 @@
+package journal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfidence(t *testing.T) {
+	cases := map[string]float64{"0.7": 0.7, "70%": 0.7, "70": 0.7, " 1 ": 1}
+	for val, expected := range cases {
+		parsed, ok := ParseConfidence(val)
+		if !ok || parsed < expected-1e-9 || parsed > expected+1e-9 {
+			t.Errorf("ParseConfidence(%q) = %v, %v, expected %v", val, parsed, ok, expected)
+		}
+	}
+
+	if _, ok := ParseConfidence("high"); ok {
+		t.Error("Expected a non-numeric confidence not to parse")
+	}
+}
+
+func TestComputeCalibration(t *testing.T) {
+	entries := make([]*Entry, 0)
+	add := func(id string, confidence string, pnl float64, faded bool) {
+		entries = append(entries,
+			&Entry{ID: id, Kind: KindDecision, Action: "exchange.open_long_position", Args: map[string]string{"confidence": confidence}},
+			&Entry{Kind: KindTradeClosed, Trade: &TradeResult{PnL: pnl, DecisionID: id, Faded: faded}},
+		)
+	}
+
+	add("d1", "0.3", -1, false)
+	add("d2", "0.35", -1, false)
+	add("d3", "0.5", 1, false)
+	add("d4", "0.55", -1, false)
+	add("d5", "0.9", 1, false)
+	add("d6", "95%", 1, false)
+	add("d7", "0.9", -1, true)
+	entries = append(entries, &Entry{ID: "d8", Kind: KindDecision, Action: "exchange.open_long_position"},
+		&Entry{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 1, DecisionID: "d8"}})
+
+	calibration := ComputeCalibration(entries, 5)
+	if calibration.Trades != 6 {
+		t.Fatalf("Expected 6 calibrated trades, got %d", calibration.Trades)
+	}
+
+	if bin := calibration.Bins[1]; bin.Trades != 2 || bin.Wins != 0 {
+		t.Errorf("Unexpected 0.2-0.4 bin: %+v", bin)
+	}
+	if bin := calibration.Bins[2]; bin.Trades != 2 || bin.WinRate() != 0.5 {
+		t.Errorf("Unexpected 0.4-0.6 bin: %+v", bin)
+	}
+	if bin := calibration.Bins[4]; bin.Trades != 2 || bin.Wins != 2 {
+		t.Errorf("Unexpected 0.8-1.0 bin: %+v", bin)
+	}
+
+	if !strings.Contains(calibration.String(), "0.8-1.0 stated 92% won 100% (2)") {
+		t.Errorf("Unexpected calibration summary: %s", calibration.String())
+	}
+
+	if min, ok := calibration.SuggestMinConfidence(0.6, 6); !ok || min != 0.4 {
+		t.Errorf("Expected a suggested minimum of 0.4, got %v, %v", min, ok)
+	}
+
+	if _, ok := calibration.SuggestMinConfidence(0.6, 10); ok {
+		t.Error("Expected no suggestion with fewer trades than required")
+	}
+
+	if stats := ComputeStats(entries); !strings.Contains(stats.String(), "confidence calibration over 6 trades") {
+		t.Errorf("Expected the calibration in the stats: %s", stats.String())
+	}
+}
@@ -0,0 +1,113 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Outcome filters of the dataset export
+const (
+	OutcomeAll        = ""
+	OutcomeProfitable = "profitable"
+	OutcomeLosing     = "losing"
+)
+
+// DatasetFilter selects the decisions exported to a dataset
+type DatasetFilter struct {
+	Symbol          string // Only decisions of the symbol, all when empty
+	Outcome         string // Only entries whose trade closed with the outcome, see the Outcome constants
+	IncludeNoAction bool   // Also export no_action decisions, only with the OutcomeAll filter
+}
+
+// DatasetMetadata describes the decision behind a dataset example
+type DatasetMetadata struct {
+	DecisionID string    `json:"decision_id"`
+	Symbol     string    `json:"symbol"`
+	Action     string    `json:"action"`
+	Model      string    `json:"model,omitempty"`
+	Outcome    string    `json:"outcome,omitempty"`
+	PnLPercent float64   `json:"pnl_percent,omitempty"`
+	RMultiple  float64   `json:"r_multiple,omitempty"`
+	Sampling   *Sampling `json:"sampling,omitempty"`
+}
+
+// DatasetExample is a prompt/completion pair of a recorded decision
+type DatasetExample struct {
+	Prompt     string           `json:"prompt"`
+	Completion string           `json:"completion"`
+	Metadata   *DatasetMetadata `json:"metadata,omitempty"`
+}
+
+// BuildDataset converts the decisions recorded with their prompt into dataset examples, labelled with the
+// outcome of the trade they opened
+func BuildDataset(entries []*Entry, filter DatasetFilter) []*DatasetExample {
+	trades := make(map[string]*TradeResult)
+	for _, entry := range entries {
+		if entry.Kind == KindTradeClosed && entry.Trade != nil && entry.Trade.DecisionID != "" {
+			trades[entry.Trade.DecisionID] = entry.Trade
+		}
+	}
+
+	examples := make([]*DatasetExample, 0)
+	for _, entry := range entries {
+		if entry.Prompt == "" || entry.Completion == "" {
+			continue
+		}
+
+		if entry.Kind != KindDecision && (entry.Kind != KindNoAction || !filter.IncludeNoAction) {
+			continue
+		}
+
+		if filter.Symbol != "" && entry.Symbol != filter.Symbol {
+			continue
+		}
+
+		meta := &DatasetMetadata{
+			DecisionID: entry.ID,
+			Symbol:     entry.Symbol,
+			Action:     entry.Action,
+			Model:      entry.Model,
+			Sampling:   entry.Sampling,
+		}
+
+		if trade, ok := trades[entry.ID]; ok {
+			meta.Outcome = OutcomeLosing
+			if trade.PnL > 0 {
+				meta.Outcome = OutcomeProfitable
+			}
+			meta.PnLPercent = trade.PnLPercent
+			meta.RMultiple = trade.RMultiple
+		}
+
+		if filter.Outcome != OutcomeAll && meta.Outcome != filter.Outcome {
+			continue
+		}
+
+		examples = append(examples, &DatasetExample{
+			Prompt:     entry.Prompt,
+			Completion: entry.Completion,
+			Metadata:   meta,
+		})
+	}
+
+	return examples
+}
+
+// WriteDataset writes the examples as JSONL, without metadata unless withMetadata is set
+func WriteDataset(w io.Writer, examples []*DatasetExample, withMetadata bool) error {
+	encoder := json.NewEncoder(w)
+
+	for _, example := range examples {
+		line := *example
+		if !withMetadata {
+			line.Metadata = nil
+		}
+
+		if err := encoder.Encode(&line); err != nil {
+			return fmt.Errorf("failed to write dataset example: %w", err)
+		}
+	}
+
+	return nil
+}
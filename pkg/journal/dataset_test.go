@@ -0,0 +1,66 @@
+package journal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuildDataset(t *testing.T) {
+	entries := []*Entry{
+		{ID: "d1", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_long_position", Prompt: "p1", Completion: "c1",
+			Sampling: &Sampling{State: "flat", Temperature: 0.5}},
+		{ID: "d2", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_short_position", Prompt: "p2", Completion: "c2"},
+		{ID: "d3", Kind: KindNoAction, Symbol: "BTCUSDT", Action: "exchange.no_action", Prompt: "p3", Completion: "c3"},
+		{ID: "d4", Kind: KindDecision, Symbol: "ETHUSDT", Action: "exchange.open_long_position", Prompt: "p4", Completion: "c4"},
+		{ID: "d5", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.close_position"},
+		{Kind: KindTradeClosed, Symbol: "BTCUSDT", Trade: &TradeResult{PnL: 12, PnLPercent: 1.2, DecisionID: "d1"}},
+		{Kind: KindTradeClosed, Symbol: "BTCUSDT", Trade: &TradeResult{PnL: -5, PnLPercent: -0.5, DecisionID: "d2"}},
+	}
+
+	all := BuildDataset(entries, DatasetFilter{})
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 examples, got %d", len(all))
+	}
+
+	withNoAction := BuildDataset(entries, DatasetFilter{Symbol: "BTCUSDT", IncludeNoAction: true})
+	if len(withNoAction) != 3 || withNoAction[2].Prompt != "p3" {
+		t.Errorf("Unexpected examples: %+v", withNoAction)
+	}
+
+	profitable := BuildDataset(entries, DatasetFilter{Outcome: OutcomeProfitable, IncludeNoAction: true})
+	if len(profitable) != 1 || profitable[0].Completion != "c1" || profitable[0].Metadata.PnLPercent != 1.2 ||
+		profitable[0].Metadata.Sampling == nil || profitable[0].Metadata.Sampling.Temperature != 0.5 {
+		t.Errorf("Unexpected profitable examples: %+v", profitable)
+	}
+
+	losing := BuildDataset(entries, DatasetFilter{Outcome: OutcomeLosing})
+	if len(losing) != 1 || losing[0].Metadata.DecisionID != "d2" {
+		t.Errorf("Unexpected losing examples: %+v", losing)
+	}
+}
+
+func TestWriteDataset(t *testing.T) {
+	examples := []*DatasetExample{
+		{Prompt: "p1", Completion: "c1", Metadata: &DatasetMetadata{DecisionID: "d1", Outcome: OutcomeProfitable}},
+		{Prompt: "p2", Completion: "c2", Metadata: &DatasetMetadata{DecisionID: "d2"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDataset(&buf, examples, false); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != `{"prompt":"p1","completion":"c1"}` {
+		t.Errorf("Unexpected dataset: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteDataset(&buf, examples, true); err != nil {
+		t.Fatalf("Failed to write dataset: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"outcome":"profitable"`) {
+		t.Errorf("Expected metadata in dataset: %s", buf.String())
+	}
+}
@@ -0,0 +1,246 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry kinds recorded in the journal
+const (
+	KindDecision    = "decision"
+	KindNoAction    = "no_action"
+	KindTradeClosed = "trade_closed"
+	KindExecution   = "execution"
+	KindNote        = "note"
+)
+
+// Entry is a single decision record in the journal
+type Entry struct {
+	ID        string            `json:"id,omitempty"`
+	Time      time.Time         `json:"time"`
+	Kind      string            `json:"kind"`
+	Symbol    string            `json:"symbol"`
+	Action    string            `json:"action,omitempty"`
+	Args      map[string]string `json:"args,omitempty"`
+	Reasoning string            `json:"reasoning,omitempty"`
+	Model     string            `json:"model,omitempty"`
+	CycleID   string            `json:"cycle_id,omitempty"`
+	Trade     *TradeResult      `json:"trade,omitempty"`
+	Execution *Execution        `json:"execution,omitempty"`
+	Sampling  *Sampling         `json:"sampling,omitempty"`
+	Note      string            `json:"note,omitempty"`     // Operator commentary of note entries
+	TradeID   string            `json:"trade_id,omitempty"` // Decision ID of the trade a note is attached to
+	Memories  []string          `json:"memories,omitempty"` // IDs of the retrieved memories injected into the decision prompt
+	Testnet   bool              `json:"testnet,omitempty"`  // Recorded while trading sandbox accounts
+
+	Assessment *Assessment `json:"assessment,omitempty"` // Market assessment the decision was made on
+
+	// Prompt and raw completion of the decision, recorded when record_prompts is enabled
+	Prompt     string `json:"prompt,omitempty"`
+	Completion string `json:"completion,omitempty"`
+}
+
+// TradeResult is the outcome of a closed trade recorded in trade_closed entries
+type TradeResult struct {
+	Side          string  `json:"side,omitempty"`
+	EntryPrice    float64 `json:"entry_price"`
+	ExitPrice     float64 `json:"exit_price"`
+	PnL           float64 `json:"pnl"`
+	PnLPercent    float64 `json:"pnl_percent"`
+	RMultiple     float64 `json:"r_multiple,omitempty"`
+	HoldingPeriod int     `json:"holding_period,omitempty"`
+	MAEPercent    float64 `json:"mae_percent,omitempty"` // Max adverse excursion, as a non-positive percent of the entry price
+	MFEPercent    float64 `json:"mfe_percent,omitempty"` // Max favorable excursion, as a non-negative percent of the entry price
+	Regime        string  `json:"regime,omitempty"`
+	CloseReason   string  `json:"close_reason,omitempty"`
+	DecisionID    string  `json:"decision_id,omitempty"`
+	Faded         bool    `json:"faded,omitempty"` // Executed as the inverse of the agent's entry in fade mode
+}
+
+// Sampling is the market state and sampling parameters a decision was generated with
+type Sampling struct {
+	State       string  `json:"state"`
+	Temperature float64 `json:"temperature"`
+	TopP        float64 `json:"top_p,omitempty"`
+}
+
+// Assessment is the structured market assessment returned with a decision
+type Assessment struct {
+	Trend      string   `json:"trend,omitempty"`
+	Volatility string   `json:"volatility,omitempty"`
+	KeyLevels  []string `json:"key_levels,omitempty"`
+	Bias       string   `json:"bias,omitempty"`
+}
+
+// Execution is what was actually placed for a command, recorded in execution entries
+type Execution struct {
+	MarketPrice   float64  `json:"market_price"`
+	StopLoss      float64  `json:"stop_loss,omitempty"`
+	TakeProfit    float64  `json:"take_profit,omitempty"`
+	Adjustments   []string `json:"adjustments,omitempty"`
+	RiskPercent   float64  `json:"risk_percent,omitempty"` // Risk per trade of entries sized by risk
+	Quantity      float64  `json:"quantity,omitempty"`     // Base quantity of entries sized by risk
+	LatencyMs     int64    `json:"latency_ms,omitempty"`   // Time taken to execute the command
+	Error         string   `json:"error,omitempty"`        // Failure reason of a failed command
+	ErrorCategory string   `json:"error_category,omitempty"`
+}
+
+// Journal is an append-only JSONL log of agent decisions
+type Journal struct {
+	path string
+	mu   sync.Mutex
+
+	testnet bool
+}
+
+// NewJournal creates a journal backed by the given file path
+func NewJournal(path string) *Journal {
+	return &Journal{
+		path: path,
+	}
+}
+
+// GetPath returns the journal file path
+func (j *Journal) GetPath() string {
+	return j.path
+}
+
+// SetTestnet labels the entries appended from now on as recorded on sandbox accounts
+func (j *Journal) SetTestnet(testnet bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.testnet = testnet
+}
+
+// Append writes an entry to the end of the journal file
+func (j *Journal) Append(entry *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if j.testnet {
+		entry.Testnet = true
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+
+	// Ensure the directory exists before writing the file
+	dir := filepath.Dir(j.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+
+	return nil
+}
+
+// LoadEntries reads all entries from the journal file
+func (j *Journal) LoadEntries() ([]*Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]*Entry, 0)
+
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return entries, nil // File doesn't exist, return empty journal
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue // Skip corrupted lines instead of failing the whole journal
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Recent returns the latest n entries of the given kind, or of any kind if kind is empty
+func (j *Journal) Recent(kind string, n int) ([]*Entry, error) {
+	entries, err := j.LoadEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	rets := make([]*Entry, 0)
+	for i := len(entries) - 1; i >= 0 && len(rets) < n; i-- {
+		if kind == "" || entries[i].Kind == kind {
+			rets = append([]*Entry{entries[i]}, rets...)
+		}
+	}
+
+	return rets, nil
+}
+
+// Since returns the entries recorded at or after the given time
+func (j *Journal) Since(t time.Time) ([]*Entry, error) {
+	entries, err := j.LoadEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	rets := make([]*Entry, 0)
+	for _, entry := range entries {
+		if !entry.Time.Before(t) {
+			rets = append(rets, entry)
+		}
+	}
+
+	return rets, nil
+}
+
+// Notes returns the operator notes attached to the trade opened by the given decision
+func (j *Journal) Notes(tradeID string) ([]*Entry, error) {
+	entries, err := j.LoadEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	rets := make([]*Entry, 0)
+	for _, entry := range entries {
+		if entry.Kind == KindNote && tradeID != "" && entry.TradeID == tradeID {
+			rets = append(rets, entry)
+		}
+	}
+
+	return rets, nil
+}
@@ -0,0 +1,145 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalAppendAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	j := NewJournal(filepath.Join(tmpDir, "sub", "journal.jsonl"))
+
+	// Test loading non-existent file
+	entries, err := j.LoadEntries()
+	if err != nil {
+		t.Fatalf("Failed to load non-existent journal: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected empty journal, got %d entries", len(entries))
+	}
+
+	err = j.Append(&Entry{Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_long_position"})
+	if err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+
+	err = j.Append(&Entry{Kind: KindNoAction, Symbol: "BTCUSDT", Action: "exchange.no_action", Reasoning: "waiting for breakout"})
+	if err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+
+	entries, err = j.LoadEntries()
+	if err != nil {
+		t.Fatalf("Failed to load journal: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[1].Reasoning != "waiting for breakout" {
+		t.Errorf("Unexpected reasoning: %s", entries[1].Reasoning)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("Expected entry time to be set on append")
+	}
+}
+
+func TestJournalTestnetLabel(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	if err := j.Append(&Entry{Kind: KindDecision, Symbol: "BTCUSDT"}); err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+
+	j.SetTestnet(true)
+	if err := j.Append(&Entry{Kind: KindDecision, Symbol: "BTCUSDT"}); err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+
+	entries, err := j.LoadEntries()
+	if err != nil {
+		t.Fatalf("Failed to load journal: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Testnet || !entries[1].Testnet {
+		t.Errorf("Expected only the entry appended after SetTestnet to be labeled, got %v and %v", entries[0].Testnet, entries[1].Testnet)
+	}
+}
+
+func TestJournalRecent(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	for _, reason := range []string{"a", "b", "c"} {
+		if err := j.Append(&Entry{Kind: KindNoAction, Reasoning: reason}); err != nil {
+			t.Fatalf("Failed to append entry: %v", err)
+		}
+	}
+	if err := j.Append(&Entry{Kind: KindDecision, Action: "exchange.close_position"}); err != nil {
+		t.Fatalf("Failed to append entry: %v", err)
+	}
+
+	recent, err := j.Recent(KindNoAction, 2)
+	if err != nil {
+		t.Fatalf("Failed to read recent entries: %v", err)
+	}
+	if len(recent) != 2 || recent[0].Reasoning != "b" || recent[1].Reasoning != "c" {
+		t.Errorf("Unexpected recent entries: %+v", recent)
+	}
+
+	all, err := j.Recent("", 10)
+	if err != nil {
+		t.Fatalf("Failed to read recent entries: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("Expected 4 entries, got %d", len(all))
+	}
+}
+
+func TestJournalSkipsCorruptedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	if err := os.WriteFile(path, []byte("{not json}\n{\"kind\":\"decision\"}\n"), 0644); err != nil {
+		t.Fatalf("Failed to prepare journal: %v", err)
+	}
+
+	entries, err := NewJournal(path).LoadEntries()
+	if err != nil {
+		t.Fatalf("Failed to load journal: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 valid entry, got %d", len(entries))
+	}
+}
+
+func TestJournalNotes(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	entries := []*Entry{
+		{Kind: KindDecision, ID: "d1", Action: "exchange.open_long_position"},
+		{Kind: KindNote, TradeID: "d1", Note: "entered too early"},
+		{Kind: KindNote, TradeID: "d2", Note: "good exit"},
+		{Kind: KindNote, TradeID: "d1", Note: "ignored the funding rate"},
+	}
+	for _, entry := range entries {
+		if err := j.Append(entry); err != nil {
+			t.Fatalf("Failed to append entry: %v", err)
+		}
+	}
+
+	notes, err := j.Notes("d1")
+	if err != nil {
+		t.Fatalf("Failed to read notes: %v", err)
+	}
+	if len(notes) != 2 || notes[0].Note != "entered too early" || notes[1].Note != "ignored the funding rate" {
+		t.Errorf("Unexpected notes: %+v", notes)
+	}
+
+	notes, err = j.Notes("")
+	if err != nil {
+		t.Fatalf("Failed to read notes: %v", err)
+	}
+	if len(notes) != 0 {
+		t.Errorf("Expected no notes without a trade, got %d", len(notes))
+	}
+}
@@ -0,0 +1,77 @@
+package journal
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// MiningFilter selects the decisions good enough to become few-shot examples
+type MiningFilter struct {
+	Symbol            string  // Only decisions of the symbol, all when empty
+	MinPnLPercent     float64 // Minimum profit percent of the trade the decision opened
+	MinRMultiple      float64 // Minimum R multiple of the trade, not checked when 0
+	MinReasoningWords int     // Minimum words of the reasoning, so the setup is explained
+}
+
+// MinedDecision is a decision that passed the mining filter, with the outcome of its trade
+type MinedDecision struct {
+	Decision *Entry
+	Trade    *TradeResult
+}
+
+// MineDecisions returns the decisions with an explained setup, a profitable trade and, when the completion was
+// recorded, a response that is valid JSON without repairs. Faded trades are skipped, their outcome belongs to
+// the inverse of the decision.
+func MineDecisions(entries []*Entry, filter MiningFilter) []*MinedDecision {
+	trades := make(map[string]*TradeResult)
+	for _, entry := range entries {
+		if entry.Kind == KindTradeClosed && entry.Trade != nil && entry.Trade.DecisionID != "" {
+			trades[entry.Trade.DecisionID] = entry.Trade
+		}
+	}
+
+	mined := make([]*MinedDecision, 0)
+	for _, entry := range entries {
+		if entry.Kind != KindDecision || entry.Action == "" {
+			continue
+		}
+
+		if filter.Symbol != "" && entry.Symbol != filter.Symbol {
+			continue
+		}
+
+		trade, ok := trades[entry.ID]
+		if !ok || trade.Faded || trade.PnL <= 0 || trade.PnLPercent < filter.MinPnLPercent {
+			continue
+		}
+
+		if filter.MinRMultiple > 0 && trade.RMultiple < filter.MinRMultiple {
+			continue
+		}
+
+		if len(strings.Fields(entry.Reasoning)) < filter.MinReasoningWords {
+			continue
+		}
+
+		if entry.Completion != "" && !validCompletion(entry.Completion) {
+			continue
+		}
+
+		mined = append(mined, &MinedDecision{Decision: entry, Trade: trade})
+	}
+
+	return mined
+}
+
+// validCompletion reports whether the completion is a JSON object, allowing only a markdown code fence around it
+func validCompletion(completion string) bool {
+	text := strings.TrimSpace(completion)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+		text = strings.TrimSpace(text)
+	}
+
+	return strings.HasPrefix(text, "{") && json.Valid([]byte(text))
+}
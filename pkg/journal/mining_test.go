@@ -0,0 +1,40 @@
+package journal
+
+import "testing"
+
+func TestMineDecisions(t *testing.T) {
+	reasoning := "price broke above the range high with volume"
+	entries := []*Entry{
+		{ID: "d1", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_long_position", Reasoning: reasoning,
+			Completion: "```json\n{\"action\": {\"name\": \"exchange.open_long_position\"}}\n```"},
+		{ID: "d2", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_short_position", Reasoning: reasoning},
+		{ID: "d3", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_long_position", Reasoning: "breakout"},
+		{ID: "d4", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_long_position", Reasoning: reasoning,
+			Completion: "{\"action\": {\"name\": \"exchange.open_long_position\",}"},
+		{ID: "d5", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_long_position", Reasoning: reasoning},
+		{ID: "d6", Kind: KindDecision, Symbol: "ETHUSDT", Action: "exchange.open_long_position", Reasoning: reasoning},
+		{ID: "d7", Kind: KindDecision, Symbol: "BTCUSDT", Action: "exchange.open_long_position", Reasoning: reasoning},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 12, PnLPercent: 1.2, RMultiple: 2, DecisionID: "d1"}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: -5, PnLPercent: -0.5, DecisionID: "d2"}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 12, PnLPercent: 1.2, RMultiple: 2, DecisionID: "d3"}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 12, PnLPercent: 1.2, RMultiple: 2, DecisionID: "d4"}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 2, PnLPercent: 0.2, RMultiple: 0.4, DecisionID: "d5"}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 12, PnLPercent: 1.2, RMultiple: 2, DecisionID: "d6"}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 12, PnLPercent: 1.2, RMultiple: 2, DecisionID: "d7", Faded: true}},
+	}
+
+	mined := MineDecisions(entries, MiningFilter{Symbol: "BTCUSDT", MinPnLPercent: 0.5, MinReasoningWords: 5})
+	if len(mined) != 1 || mined[0].Decision.ID != "d1" || mined[0].Trade.PnLPercent != 1.2 {
+		t.Fatalf("Unexpected mined decisions: %+v", mined)
+	}
+
+	loose := MineDecisions(entries, MiningFilter{MinReasoningWords: 5})
+	if len(loose) != 3 {
+		t.Errorf("Expected 3 mined decisions without the symbol and profit filters, got %d", len(loose))
+	}
+
+	strict := MineDecisions(entries, MiningFilter{MinReasoningWords: 5, MinRMultiple: 3})
+	if len(strict) != 0 {
+		t.Errorf("Expected no decisions above 3R, got %d", len(strict))
+	}
+}
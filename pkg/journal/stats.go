@@ -0,0 +1,272 @@
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stats summarizes the decisions and closed trades of a set of journal entries
+type Stats struct {
+	Decisions int
+	NoActions int
+	Trades    int
+	Wins      int
+	Losses    int
+	TotalPnL  float64
+	BestPnL   float64
+	WorstPnL  float64
+
+	// Trades executed inverted in fade mode, their PnL is the inverse of the agent's signal
+	FadedTrades int
+	FadedPnL    float64
+
+	// Executed commands and their failures by error category
+	Executions        int
+	ExecutionFailures map[string]int
+
+	// Max adverse and favorable excursions of the trades that recorded them, split by outcome
+	WinnerMAE []float64
+	LoserMAE  []float64
+	WinnerMFE []float64
+	LoserMFE  []float64
+
+	// Realized win rate against the confidence stated with the entries
+	Calibration *Calibration
+}
+
+// Confidence bins of the calibration curve in the stats
+const calibrationBins = 5
+
+// ComputeStats aggregates the journal entries into stats
+func ComputeStats(entries []*Entry) *Stats {
+	stats := &Stats{ExecutionFailures: make(map[string]int)}
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case KindExecution:
+			stats.Executions++
+			if entry.Execution != nil && entry.Execution.Error != "" {
+				category := entry.Execution.ErrorCategory
+				if category == "" {
+					category = "other"
+				}
+				stats.ExecutionFailures[category]++
+			}
+		case KindDecision:
+			stats.Decisions++
+		case KindNoAction:
+			stats.NoActions++
+		case KindTradeClosed:
+			if entry.Trade == nil {
+				continue
+			}
+
+			pnl := entry.Trade.PnL
+			if stats.Trades == 0 || pnl > stats.BestPnL {
+				stats.BestPnL = pnl
+			}
+			if stats.Trades == 0 || pnl < stats.WorstPnL {
+				stats.WorstPnL = pnl
+			}
+
+			stats.Trades++
+			stats.TotalPnL += pnl
+			if entry.Trade.Faded {
+				stats.FadedTrades++
+				stats.FadedPnL += pnl
+			}
+			if pnl >= 0 {
+				stats.Wins++
+			} else {
+				stats.Losses++
+			}
+
+			trade := entry.Trade
+			if trade.MAEPercent == 0 && trade.MFEPercent == 0 {
+				continue
+			}
+			if pnl >= 0 {
+				stats.WinnerMAE = append(stats.WinnerMAE, trade.MAEPercent)
+				stats.WinnerMFE = append(stats.WinnerMFE, trade.MFEPercent)
+			} else {
+				stats.LoserMAE = append(stats.LoserMAE, trade.MAEPercent)
+				stats.LoserMFE = append(stats.LoserMFE, trade.MFEPercent)
+			}
+		}
+	}
+
+	stats.Calibration = ComputeCalibration(entries, calibrationBins)
+	return stats
+}
+
+// WinRate returns the share of winning trades in [0, 1]
+func (s *Stats) WinRate() float64 {
+	if s.Trades == 0 {
+		return 0
+	}
+
+	return float64(s.Wins) / float64(s.Trades)
+}
+
+func (s *Stats) String() string {
+	text := fmt.Sprintf("%d decisions, %d no_action, %d trades (%d wins, %d losses, win rate %.0f%%), total PnL %.2f, best %.2f, worst %.2f",
+		s.Decisions, s.NoActions, s.Trades, s.Wins, s.Losses, s.WinRate()*100, s.TotalPnL, s.BestPnL, s.WorstPnL)
+	if s.FadedTrades > 0 {
+		text += fmt.Sprintf(", %d faded trades with PnL %.2f", s.FadedTrades, s.FadedPnL)
+	}
+
+	if len(s.ExecutionFailures) > 0 {
+		categories := make([]string, 0, len(s.ExecutionFailures))
+		failed := 0
+		for category, count := range s.ExecutionFailures {
+			categories = append(categories, fmt.Sprintf("%s %d", category, count))
+			failed += count
+		}
+		sort.Strings(categories)
+
+		text += fmt.Sprintf(", %d of %d executions failed (%s)", failed, s.Executions, strings.Join(categories, ", "))
+	}
+
+	if excursions := s.ExcursionSummary(); excursions != "" {
+		text += ", " + excursions
+	}
+
+	if s.Calibration != nil {
+		if calibration := s.Calibration.String(); calibration != "" {
+			text += ", " + calibration
+		}
+	}
+
+	return text
+}
+
+// ExcursionSummary describes the MAE/MFE distributions, for tuning stops and targets: winners rarely drawing
+// down past their MAE p90 suggests a stop beyond it, losers' MFE shows the profit they gave back
+func (s *Stats) ExcursionSummary() string {
+	if len(s.WinnerMAE) == 0 && len(s.LoserMAE) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, 2)
+	if len(s.WinnerMAE) > 0 {
+		parts = append(parts, fmt.Sprintf("winners MAE median %.2f%% p90 %.2f%%, MFE median +%.2f%%",
+			percentile(s.WinnerMAE, 0.5), percentile(s.WinnerMAE, 0.1), percentile(s.WinnerMFE, 0.5)))
+	}
+	if len(s.LoserMAE) > 0 {
+		parts = append(parts, fmt.Sprintf("losers MAE median %.2f%%, MFE median +%.2f%% p90 +%.2f%%",
+			percentile(s.LoserMAE, 0.5), percentile(s.LoserMFE, 0.5), percentile(s.LoserMFE, 0.9)))
+	}
+
+	return "excursions: " + strings.Join(parts, "; ")
+}
+
+// percentile returns the nearest-rank value at the fraction p of the sorted values
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	index := int(p * float64(len(sorted)-1))
+	return sorted[index]
+}
+
+// SymbolStats is the rolling track record of a symbol computed from its latest closed trades
+type SymbolStats struct {
+	Symbol         string
+	Trades         int
+	WinRate        float64
+	AvgR           float64 // Average R-multiple over trades with a stop loss
+	TypicalHold    int     // Median holding period in klines
+	BestRegime     string  // Regime with the highest average PnL percent
+	BestRegimePnL  float64 // Average PnL percent of the best regime
+	tradesWithRisk int
+}
+
+// ComputeSymbolStats computes the track record of the symbol over its latest window closed trades, 0 means all
+func ComputeSymbolStats(entries []*Entry, symbol string, window int) *SymbolStats {
+	trades := make([]*TradeResult, 0)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Kind != KindTradeClosed || entry.Trade == nil || entry.Symbol != symbol {
+			continue
+		}
+
+		trades = append(trades, entry.Trade)
+		if window > 0 && len(trades) >= window {
+			break
+		}
+	}
+
+	stats := &SymbolStats{Symbol: symbol, Trades: len(trades)}
+	if len(trades) == 0 {
+		return stats
+	}
+
+	wins := 0
+	sumR := 0.0
+	holds := make([]int, 0, len(trades))
+	regimePnL := make(map[string]float64)
+	regimeCount := make(map[string]int)
+
+	for _, trade := range trades {
+		if trade.PnL >= 0 {
+			wins++
+		}
+
+		if trade.RMultiple != 0 {
+			sumR += trade.RMultiple
+			stats.tradesWithRisk++
+		}
+
+		holds = append(holds, trade.HoldingPeriod)
+
+		if trade.Regime != "" {
+			regimePnL[trade.Regime] += trade.PnLPercent
+			regimeCount[trade.Regime]++
+		}
+	}
+
+	stats.WinRate = float64(wins) / float64(len(trades))
+	if stats.tradesWithRisk > 0 {
+		stats.AvgR = sumR / float64(stats.tradesWithRisk)
+	}
+
+	sort.Ints(holds)
+	stats.TypicalHold = holds[len(holds)/2]
+
+	regimes := make([]string, 0, len(regimeCount))
+	for regime := range regimeCount {
+		regimes = append(regimes, regime)
+	}
+	sort.Strings(regimes)
+
+	for _, regime := range regimes {
+		avg := regimePnL[regime] / float64(regimeCount[regime])
+		if stats.BestRegime == "" || avg > stats.BestRegimePnL {
+			stats.BestRegime = regime
+			stats.BestRegimePnL = avg
+		}
+	}
+
+	return stats
+}
+
+// String returns a one-line summary of the track record for decision prompts
+func (s *SymbolStats) String() string {
+	if s.Trades == 0 {
+		return fmt.Sprintf("Track record on %s: no closed trades yet.", s.Symbol)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Track record on %s (last %d trades): win rate %.0f%%", s.Symbol, s.Trades, s.WinRate*100))
+	if s.tradesWithRisk > 0 {
+		sb.WriteString(fmt.Sprintf(", avg R %.2f", s.AvgR))
+	}
+	sb.WriteString(fmt.Sprintf(", typical hold %d klines", s.TypicalHold))
+	if s.BestRegime != "" {
+		sb.WriteString(fmt.Sprintf(", best regime %s (avg %+.2f%%)", s.BestRegime, s.BestRegimePnL))
+	}
+	sb.WriteString(".")
+
+	return sb.String()
+}
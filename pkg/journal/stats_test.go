@@ -0,0 +1,125 @@
+package journal
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeStatsSince(t *testing.T) {
+	j := NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	now := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	entries := []*Entry{
+		{Time: now.Add(-10 * 24 * time.Hour), Kind: KindTradeClosed, Trade: &TradeResult{PnL: 100}},
+		{Time: now.Add(-3 * 24 * time.Hour), Kind: KindDecision, Action: "exchange.open_long_position"},
+		{Time: now.Add(-3 * 24 * time.Hour), Kind: KindNoAction, Action: "exchange.no_action"},
+		{Time: now.Add(-2 * 24 * time.Hour), Kind: KindTradeClosed, Trade: &TradeResult{PnL: 30}},
+		{Time: now.Add(-1 * 24 * time.Hour), Kind: KindTradeClosed, Trade: &TradeResult{PnL: -10}},
+	}
+	for _, entry := range entries {
+		if err := j.Append(entry); err != nil {
+			t.Fatalf("Failed to append entry: %v", err)
+		}
+	}
+
+	week, err := j.Since(now.Add(-7 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to load entries: %v", err)
+	}
+
+	stats := ComputeStats(week)
+	if stats.Decisions != 1 || stats.NoActions != 1 || stats.Trades != 2 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if stats.TotalPnL != 20 || stats.BestPnL != 30 || stats.WorstPnL != -10 || stats.WinRate() != 0.5 {
+		t.Errorf("Unexpected PnL stats: %+v", stats)
+	}
+}
+
+func TestComputeStatsFaded(t *testing.T) {
+	entries := []*Entry{
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 20}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: -15, Faded: true}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 5, Faded: true}},
+	}
+
+	stats := ComputeStats(entries)
+	if stats.Trades != 3 || stats.FadedTrades != 2 || stats.FadedPnL != -10 {
+		t.Errorf("Unexpected faded stats: %+v", stats)
+	}
+	if !strings.Contains(stats.String(), "2 faded trades with PnL -10.00") {
+		t.Errorf("Unexpected stats text: %s", stats.String())
+	}
+}
+
+func TestComputeStatsExecutionFailures(t *testing.T) {
+	entries := []*Entry{
+		{Kind: KindExecution, Execution: &Execution{LatencyMs: 120}},
+		{Kind: KindExecution, Execution: &Execution{Error: "order quantity is too small", ErrorCategory: "min_notional"}},
+		{Kind: KindExecution, Execution: &Execution{Error: "order quantity is too small", ErrorCategory: "min_notional"}},
+		{Kind: KindExecution, Execution: &Execution{Error: "429 Too Many Requests", ErrorCategory: "rate_limit"}},
+	}
+
+	stats := ComputeStats(entries)
+	if stats.Executions != 4 || stats.ExecutionFailures["min_notional"] != 2 || stats.ExecutionFailures["rate_limit"] != 1 {
+		t.Errorf("Unexpected execution stats: %+v", stats)
+	}
+	if !strings.Contains(stats.String(), "3 of 4 executions failed (min_notional 2, rate_limit 1)") {
+		t.Errorf("Unexpected stats text: %s", stats.String())
+	}
+}
+
+func TestComputeStatsExcursions(t *testing.T) {
+	entries := []*Entry{
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 20, MAEPercent: -0.5, MFEPercent: 3}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 10, MAEPercent: -1.5, MFEPercent: 2}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: 5, MAEPercent: -1, MFEPercent: 1}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: -15, MAEPercent: -2, MFEPercent: 0.8}},
+		{Kind: KindTradeClosed, Trade: &TradeResult{PnL: -5}},
+	}
+
+	stats := ComputeStats(entries)
+	if len(stats.WinnerMAE) != 3 || len(stats.LoserMAE) != 1 {
+		t.Fatalf("Unexpected excursions: %+v", stats)
+	}
+
+	summary := stats.ExcursionSummary()
+	if !strings.Contains(summary, "winners MAE median -1.00% p90 -1.50%, MFE median +2.00%") {
+		t.Errorf("Unexpected winner excursions: %s", summary)
+	}
+	if !strings.Contains(summary, "losers MAE median -2.00%, MFE median +0.80%") {
+		t.Errorf("Unexpected loser excursions: %s", summary)
+	}
+	if ComputeStats(entries[4:]).ExcursionSummary() != "" {
+		t.Errorf("Trades without excursions should not be summarized")
+	}
+}
+
+func TestComputeSymbolStats(t *testing.T) {
+	entries := []*Entry{
+		{Kind: KindTradeClosed, Symbol: "ETHUSDT", Trade: &TradeResult{PnL: 50, PnLPercent: 5}},
+		{Kind: KindTradeClosed, Symbol: "BTCUSDT", Trade: &TradeResult{PnL: -10, PnLPercent: -1, RMultiple: -1, HoldingPeriod: 4, Regime: "ranging"}},
+		{Kind: KindTradeClosed, Symbol: "BTCUSDT", Trade: &TradeResult{PnL: 30, PnLPercent: 3, RMultiple: 2, HoldingPeriod: 12, Regime: "trending_up"}},
+		{Kind: KindDecision, Symbol: "BTCUSDT"},
+		{Kind: KindTradeClosed, Symbol: "BTCUSDT", Trade: &TradeResult{PnL: 10, PnLPercent: 1, HoldingPeriod: 20, Regime: "trending_up"}},
+	}
+
+	stats := ComputeSymbolStats(entries, "BTCUSDT", 0)
+	if stats.Trades != 3 || stats.TypicalHold != 12 || stats.AvgR != 0.5 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.BestRegime != "trending_up" || stats.BestRegimePnL != 2 {
+		t.Errorf("Unexpected best regime: %+v", stats)
+	}
+
+	expected := "Track record on BTCUSDT (last 3 trades): win rate 67%, avg R 0.50, typical hold 12 klines, best regime trending_up (avg +2.00%)."
+	if stats.String() != expected {
+		t.Errorf("Unexpected summary: %s", stats.String())
+	}
+
+	if latest := ComputeSymbolStats(entries, "BTCUSDT", 1); latest.Trades != 1 || latest.TypicalHold != 20 {
+		t.Errorf("Expected only the latest trade, got %+v", latest)
+	}
+}
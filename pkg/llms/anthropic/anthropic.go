@@ -135,10 +135,35 @@ func (o *LLM) GenerateContent(ctx context.Context, messages []llms.MessageConten
 	}
 
 	// Add extended thinking configuration
-	if o.enableThinking && o.thinkingBudget > 0 {
+	thinking := o.enableThinking && o.thinkingBudget > 0
+	if thinking {
 		req.Thinking = anthropic.ThinkingConfigParamOfEnabled(o.thinkingBudget)
 	}
 
+	// Add function tools
+	for _, tool := range opts.Tools {
+		if tool.Function == nil {
+			continue
+		}
+
+		schema := anthropic.ToolInputSchemaParam{}
+		if params, ok := tool.Function.Parameters.(map[string]any); ok {
+			schema.Properties = params["properties"]
+			if required, ok := params["required"].([]string); ok {
+				schema.Required = required
+			}
+		}
+
+		toolParam := anthropic.ToolUnionParamOfTool(schema, tool.Function.Name)
+		toolParam.OfTool.Description = anthropic.String(tool.Function.Description)
+		req.Tools = append(req.Tools, toolParam)
+	}
+
+	// Extended thinking only lets the model decide whether to call a tool
+	if choice, ok := opts.ToolChoice.(llms.ToolChoice); ok && choice.Function != nil && !thinking {
+		req.ToolChoice = anthropic.ToolChoiceParamOfTool(choice.Function.Name)
+	}
+
 	// Call the API
 	response, err := o.client.Messages.New(ctx, req)
 	if err != nil {
@@ -150,22 +175,36 @@ func (o *LLM) GenerateContent(ctx context.Context, messages []llms.MessageConten
 
 	// Extract content from response
 	content := ""
+	toolCalls := make([]llms.ToolCall, 0)
 	if len(response.Content) > 0 {
 		for _, c := range response.Content {
 			if c.Type == "thinking" {
 				content += "<thinking>" + c.Thinking + "</thinking>"
 			} else if c.Type == "text" {
 				content += c.Text
+			} else if c.Type == "tool_use" {
+				toolCalls = append(toolCalls, llms.ToolCall{
+					ID:   c.ID,
+					Type: "function",
+					FunctionCall: &llms.FunctionCall{
+						Name:      c.Name,
+						Arguments: string(c.Input),
+					},
+				})
 			}
 		}
 	}
 
+	choice := &llms.ContentChoice{
+		Content: content,
+	}
+	if len(toolCalls) > 0 {
+		choice.ToolCalls = toolCalls
+		choice.FuncCall = toolCalls[0].FunctionCall
+	}
+
 	resp := &llms.ContentResponse{
-		Choices: []*llms.ContentChoice{
-			{
-				Content: content,
-			},
-		},
+		Choices: []*llms.ContentChoice{choice},
 	}
 
 	return resp, nil
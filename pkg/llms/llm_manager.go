@@ -13,17 +13,47 @@ import (
 	"github.com/yubing744/trading-gpt/pkg/config"
 	"github.com/yubing744/trading-gpt/pkg/llms/anthropic"
 	"github.com/yubing744/trading-gpt/pkg/llms/googleai"
+	"github.com/yubing744/trading-gpt/pkg/llms/local"
 
 	openaix "github.com/yubing744/trading-gpt/pkg/llms/openai"
 )
 
 var log = logrus.WithField("module", "llm_manager")
 
+// Response format paths of JSON responses, recorded as "response_format" in the generation info
+const (
+	ResponseFormatNative = "native" // constrained to JSON by the provider
+	ResponseFormatTool   = "tool"   // arguments of a tool call, structured by the provider
+	ResponseFormatRepair = "repair" // free text, left to the repair pipeline of the parser
+)
+
+// nativeJSONProviders are the providers supporting a native JSON response format
+var nativeJSONProviders = map[string]bool{
+	"openai":   true,
+	"ollama":   true,
+	"googleai": true,
+	"local":    true,
+}
+
+// toolCallingProviders are the providers supporting native tool calls
+var toolCallingProviders = map[string]bool{
+	"openai":    true,
+	"anthropic": true,
+}
+
+// embedder is implemented by models that can create embeddings
+type embedder interface {
+	CreateEmbedding(ctx context.Context, inputTexts []string) ([][]float32, error)
+}
+
 type LLMManager struct {
 	cfg      *config.LLMConfig
 	llms     map[string]llms.Model
 	primary  string
 	secondly string
+
+	// responses recorded or replayed by prompt, nil without a cache
+	cache *ResponseCache
 }
 
 func NewLLMManager(cfg *config.LLMConfig) *LLMManager {
@@ -131,6 +161,54 @@ func (mgr *LLMManager) Init() error {
 		mgr.llms["ollama"] = llm
 	}
 
+	// init local model served by an OpenAI-compatible endpoint
+	if mgr.cfg.Local != nil {
+		localCfg := mgr.cfg.Local
+
+		opts := make([]local.Option, 0)
+		opts = append(opts, local.WithModel(localCfg.Model))
+		opts = append(opts, local.WithNoSystemRole(localCfg.NoSystemRole))
+
+		if localCfg.BaseURL != "" {
+			opts = append(opts, local.WithBaseURL(localCfg.BaseURL))
+		}
+		if token := os.Getenv("LLM_LOCAL_TOKEN"); token != "" {
+			opts = append(opts, local.WithToken(token))
+		}
+		if localCfg.Timeout > 0 {
+			opts = append(opts, local.WithTimeout(localCfg.Timeout.Duration()))
+		}
+		if localCfg.ContextLength > 0 {
+			opts = append(opts, local.WithContextLength(localCfg.ContextLength))
+		}
+		if localCfg.MaxTokens > 0 {
+			opts = append(opts, local.WithMaxTokens(localCfg.MaxTokens))
+		}
+
+		llm, err := local.New(opts...)
+		if err != nil {
+			return errors.Wrap(err, "New local model fail")
+		}
+
+		mgr.llms["local"] = llm
+	}
+
+	// init the response cache
+	if mgr.cfg.Cache != nil && mgr.cfg.Cache.Mode != "" {
+		cacheCfg := mgr.cfg.Cache
+		if cacheCfg.Path == "" {
+			cacheCfg.Path = "memory-bank/llm-cache.jsonl"
+		}
+
+		cache, err := NewResponseCache(cacheCfg.Mode, cacheCfg.Path)
+		if err != nil {
+			return errors.Wrap(err, "New llm cache fail")
+		}
+
+		mgr.cache = cache
+		log.WithField("mode", cacheCfg.Mode).WithField("responses", cache.Len()).Info("llm response cache enabled")
+	}
+
 	return nil
 }
 
@@ -156,12 +234,50 @@ func (mgr *LLMManager) GetSecondlyLLM() (llms.Model, error) {
 // messages. It's the most general interface for multi-modal LLMs that support
 // chat-like interactions.
 func (mgr *LLMManager) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	if mgr.cache == nil {
+		return mgr.generateContent(ctx, messages, options...)
+	}
+
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	key, err := cacheKey(mgr.primary, messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp, ok := mgr.cache.Get(key); ok {
+		_, format := mgr.negotiateFormat(mgr.primary, options)
+		setModel(resp, mgr.primary, format)
+		return resp, nil
+	}
+
+	if mgr.cache.Replay() {
+		return nil, errors.Errorf("no cached llm response for prompt %s in replay mode", key[:12])
+	}
+
+	resp, err := mgr.generateContent(ctx, messages, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mgr.cache.Put(key, resp); err != nil {
+		log.WithError(err).Warn("save llm response to cache failed")
+	}
+
+	return resp, nil
+}
+
+func (mgr *LLMManager) generateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
 	llm, err := mgr.GetLLM()
 	if err != nil {
 		return nil, errors.Wrap(err, "get llm fail")
 	}
 
-	resp, err := llm.GenerateContent(ctx, messages, options...)
+	opts, format := mgr.negotiateFormat(mgr.primary, options)
+	resp, err := llm.GenerateContent(ctx, messages, opts...)
 	if err != nil {
 		log.WithError(err).WithField("model", mgr.primary).Error("GenerateContent_fail_by_primary_model")
 
@@ -170,16 +286,65 @@ func (mgr *LLMManager) GenerateContent(ctx context.Context, messages []llms.Mess
 			return nil, errors.Wrap(err2, "get secondly fail")
 		}
 
-		resp2, err := llm2.GenerateContent(ctx, messages, options...)
-		setModel(resp2, mgr.secondly)
+		opts2, format2 := mgr.negotiateFormat(mgr.secondly, options)
+		resp2, err := llm2.GenerateContent(ctx, messages, opts2...)
+		setModel(resp2, mgr.secondly, format2)
 		return resp2, err
 	}
 
-	setModel(resp, mgr.primary)
+	setModel(resp, mgr.primary, format)
 	return resp, nil
 }
 
-func setModel(resp *llms.ContentResponse, model string) {
+// negotiateFormat keeps the tools asked for by the options when the provider supports tool calls natively, then
+// the JSON mode when the provider supports it natively, otherwise turns them off so the free text response is
+// parsed by the repair pipeline. The format is empty when neither is asked for.
+func (mgr *LLMManager) negotiateFormat(name string, options []llms.CallOption) ([]llms.CallOption, string) {
+	opts := llms.CallOptions{}
+	for _, opt := range options {
+		opt(&opts)
+	}
+
+	if len(opts.Tools) > 0 {
+		tools := toolCallingProviders[name]
+		if enabled, ok := mgr.cfg.ToolCalling[name]; ok {
+			tools = enabled
+		}
+
+		// The response comes as the tool call arguments, the JSON mode would ask for it in the text too
+		if tools {
+			return append(append([]llms.CallOption{}, options...), withoutJSONMode), ResponseFormatTool
+		}
+
+		options = append(append([]llms.CallOption{}, options...), withoutTools)
+	}
+
+	if !opts.JSONMode {
+		return options, ""
+	}
+
+	native := nativeJSONProviders[name]
+	if enabled, ok := mgr.cfg.NativeJSON[name]; ok {
+		native = enabled
+	}
+
+	if native {
+		return options, ResponseFormatNative
+	}
+
+	return append(append([]llms.CallOption{}, options...), withoutJSONMode), ResponseFormatRepair
+}
+
+func withoutJSONMode(opts *llms.CallOptions) {
+	opts.JSONMode = false
+}
+
+func withoutTools(opts *llms.CallOptions) {
+	opts.Tools = nil
+	opts.ToolChoice = nil
+}
+
+func setModel(resp *llms.ContentResponse, model string, format string) {
 	if resp != nil {
 		for _, choice := range resp.Choices {
 			if choice.GenerationInfo == nil {
@@ -187,6 +352,9 @@ func setModel(resp *llms.ContentResponse, model string) {
 			}
 
 			choice.GenerationInfo["model"] = model
+			if format != "" {
+				choice.GenerationInfo["response_format"] = format
+			}
 		}
 	}
 }
@@ -218,3 +386,20 @@ func (mgr *LLMManager) Call(ctx context.Context, prompt string, options ...llms.
 
 	return result, nil
 }
+
+// CreateEmbedding creates embeddings for the texts with the first configured model supporting embeddings,
+// trying the primary model before the secondly one.
+func (mgr *LLMManager) CreateEmbedding(ctx context.Context, inputTexts []string) ([][]float32, error) {
+	for _, name := range []string{mgr.primary, mgr.secondly} {
+		llm, ok := mgr.llms[name]
+		if !ok {
+			continue
+		}
+
+		if e, ok := llm.(embedder); ok {
+			return e.CreateEmbedding(ctx, inputTexts)
+		}
+	}
+
+	return nil, errors.New("no llm supports embeddings")
+}
@@ -0,0 +1,83 @@
+package local
+
+import (
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// EstimateTokens roughly estimates the tokens of a text, erring on the high side for non-English text
+func EstimateTokens(text string) int {
+	return utils.EstimateTokens(text)
+}
+
+func messageTokens(msg llms.MessageContent) int {
+	tokens := 4 // role and message framing
+	for _, part := range msg.Parts {
+		if text, ok := part.(llms.TextContent); ok {
+			tokens += EstimateTokens(text.Text)
+		}
+	}
+	return tokens
+}
+
+// FitContext drops the oldest chat history until the messages fit the token budget. System messages and the
+// latest message are always kept, the latest message is cut to its tail when it alone exceeds the budget.
+func FitContext(messages []llms.MessageContent, budget int) []llms.MessageContent {
+	if len(messages) == 0 || budget <= 0 {
+		return messages
+	}
+
+	total := 0
+	for _, msg := range messages {
+		total += messageTokens(msg)
+	}
+
+	last := len(messages) - 1
+	dropped := make(map[int]bool)
+	for i := 0; i < last && total > budget; i++ {
+		if messages[i].Role == llms.ChatMessageTypeSystem {
+			continue
+		}
+
+		dropped[i] = true
+		total -= messageTokens(messages[i])
+	}
+
+	rets := make([]llms.MessageContent, 0, len(messages)-len(dropped))
+	for i, msg := range messages {
+		if !dropped[i] {
+			rets = append(rets, msg)
+		}
+	}
+
+	if total > budget {
+		rets[len(rets)-1] = truncateMessage(rets[len(rets)-1], messageTokens(rets[len(rets)-1])-(total-budget))
+	}
+
+	return rets
+}
+
+// truncateMessage keeps the tail of the message text within the tokens, where the latest market data is
+func truncateMessage(msg llms.MessageContent, tokens int) llms.MessageContent {
+	maxLen := tokens * 3
+	if maxLen < 0 {
+		maxLen = 0
+	}
+
+	parts := make([]llms.ContentPart, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		if text, ok := part.(llms.TextContent); ok && len(text.Text) > maxLen {
+			start := len(text.Text) - maxLen
+			for start < len(text.Text) && !utf8.RuneStart(text.Text[start]) {
+				start++
+			}
+			part = llms.TextPart(text.Text[start:])
+		}
+		parts = append(parts, part)
+	}
+
+	return llms.MessageContent{Role: msg.Role, Parts: parts}
+}
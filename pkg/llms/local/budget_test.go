@@ -0,0 +1,34 @@
+package local
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestFitContext(t *testing.T) {
+	messages := []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, strings.Repeat("s", 30)),
+		llms.TextParts(llms.ChatMessageTypeHuman, strings.Repeat("a", 300)),
+		llms.TextParts(llms.ChatMessageTypeAI, strings.Repeat("b", 300)),
+		llms.TextParts(llms.ChatMessageTypeHuman, strings.Repeat("c", 60)),
+	}
+
+	// Everything fits
+	assert.Len(t, FitContext(messages, 1000), 4)
+
+	// The oldest chat history is dropped first
+	fitted := FitContext(messages, 150)
+	assert.Len(t, fitted, 3)
+	assert.Equal(t, llms.ChatMessageTypeSystem, fitted[0].Role)
+	assert.Equal(t, llms.ChatMessageTypeAI, fitted[1].Role)
+
+	// The latest message is cut to its tail
+	fitted = FitContext(messages, 20)
+	assert.Len(t, fitted, 2)
+	text := fitted[1].Parts[0].(llms.TextContent).Text
+	assert.True(t, len(text) < 60)
+	assert.True(t, strings.HasSuffix(strings.Repeat("c", 60), text))
+}
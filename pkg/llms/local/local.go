@@ -0,0 +1,89 @@
+package local
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+
+	openaix "github.com/yubing744/trading-gpt/pkg/llms/openai"
+)
+
+var log = logrus.WithField("module", "local_llm")
+
+// LLM is a local open-weight model served by an OpenAI-compatible endpoint, such as Ollama or a llama.cpp
+// server. Prompts are fitted into the model context and completions are capped.
+type LLM struct {
+	inner         *openaix.LLM
+	contextLength int
+	maxTokens     int
+}
+
+// New creates a local model
+func New(opts ...Option) (*LLM, error) {
+	o := &options{
+		baseURL:       defaultBaseURL,
+		token:         "local",
+		timeout:       defaultTimeout,
+		contextLength: defaultContextLength,
+		maxTokens:     defaultMaxTokens,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.model == "" {
+		return nil, errors.New("local model name required")
+	}
+
+	if o.maxTokens >= o.contextLength {
+		return nil, errors.Errorf("max tokens %d must be less than the context length %d", o.maxTokens, o.contextLength)
+	}
+
+	inner, err := openaix.New(o.noSystemRole,
+		openai.WithBaseURL(o.baseURL),
+		openai.WithModel(o.model),
+		openai.WithToken(o.token),
+		openai.WithHTTPClient(&http.Client{Timeout: o.timeout}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LLM{
+		inner:         inner,
+		contextLength: o.contextLength,
+		maxTokens:     o.maxTokens,
+	}, nil
+}
+
+// budget is the prompt tokens left after reserving the completion
+func (llm *LLM) budget() int {
+	return llm.contextLength - llm.maxTokens
+}
+
+func (llm *LLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	fitted := FitContext(messages, llm.budget())
+	if len(fitted) < len(messages) {
+		log.
+			WithField("dropped", len(messages)-len(fitted)).
+			WithField("budget", llm.budget()).
+			Info("chat history dropped to fit the local model context")
+	}
+
+	// Applied last, so the completion never exceeds what the context leaves
+	options = append(options, llms.WithMaxTokens(llm.maxTokens))
+
+	return llm.inner.GenerateContent(ctx, fitted, options...)
+}
+
+func (llm *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, llm, prompt, options...)
+}
+
+func (llm *LLM) CreateEmbedding(ctx context.Context, inputTexts []string) ([][]float32, error) {
+	return llm.inner.CreateEmbedding(ctx, inputTexts)
+}
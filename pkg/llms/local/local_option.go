@@ -0,0 +1,71 @@
+package local
+
+import "time"
+
+const (
+	defaultBaseURL       = "http://localhost:11434/v1" // Ollama, a llama.cpp server listens on http://localhost:8080/v1
+	defaultTimeout       = 5 * time.Minute
+	defaultContextLength = 8192
+	defaultMaxTokens     = 1024
+)
+
+type options struct {
+	baseURL       string
+	model         string
+	token         string
+	timeout       time.Duration
+	contextLength int
+	maxTokens     int
+	noSystemRole  bool
+}
+
+type Option func(*options)
+
+// WithBaseURL sets the OpenAI-compatible endpoint of the local server
+func WithBaseURL(baseURL string) Option {
+	return func(opts *options) {
+		opts.baseURL = baseURL
+	}
+}
+
+// WithModel sets the model name served by the local server
+func WithModel(model string) Option {
+	return func(opts *options) {
+		opts.model = model
+	}
+}
+
+// WithToken sets the API key, local servers usually accept any
+func WithToken(token string) Option {
+	return func(opts *options) {
+		opts.token = token
+	}
+}
+
+// WithTimeout sets the request timeout, local inference on CPU can take minutes
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *options) {
+		opts.timeout = timeout
+	}
+}
+
+// WithContextLength sets the context window of the model in tokens
+func WithContextLength(contextLength int) Option {
+	return func(opts *options) {
+		opts.contextLength = contextLength
+	}
+}
+
+// WithMaxTokens sets the completion tokens reserved in the context
+func WithMaxTokens(maxTokens int) Option {
+	return func(opts *options) {
+		opts.maxTokens = maxTokens
+	}
+}
+
+// WithNoSystemRole sends system messages as user messages, for chat templates without a system role
+func WithNoSystemRole(noSystemRole bool) Option {
+	return func(opts *options) {
+		opts.noSystemRole = noSystemRole
+	}
+}
@@ -48,3 +48,7 @@ func (llm *LLM) GenerateContent(ctx context.Context, messages []llms.MessageCont
 func (llm *LLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
 	return llm.innerLLM.Call(ctx, prompt, options...)
 }
+
+func (llm *LLM) CreateEmbedding(ctx context.Context, inputTexts []string) ([][]float32, error) {
+	return llm.innerLLM.CreateEmbedding(ctx, inputTexts)
+}
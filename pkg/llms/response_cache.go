@@ -0,0 +1,216 @@
+package llms
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Modes of the response cache
+const (
+	CacheModeRecord      = "record"       // always call the model, saving the responses
+	CacheModeReplay      = "replay"       // only serve saved responses, a miss is an error
+	CacheModeReadThrough = "read_through" // serve saved responses, calling the model and saving on misses
+)
+
+// cachedChoice is the part of a response choice kept by the cache
+type cachedChoice struct {
+	Content    string           `json:"content"`
+	StopReason string           `json:"stop_reason,omitempty"`
+	ToolCalls  []cachedToolCall `json:"tool_calls,omitempty"`
+}
+
+// cachedToolCall is a function tool call, the JSON encoding of llms.ToolCall does not decode back
+type cachedToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type cacheRecord struct {
+	Key     string         `json:"key"`
+	Choices []cachedChoice `json:"choices"`
+}
+
+// ResponseCache keeps the responses of the model by a hash of the prompt in an append-only JSON lines file
+type ResponseCache struct {
+	mode string
+
+	mu      sync.Mutex
+	file    *os.File
+	entries map[string][]cachedChoice
+}
+
+// NewResponseCache loads the saved responses and opens the cache for appending
+func NewResponseCache(mode string, path string) (*ResponseCache, error) {
+	if mode != CacheModeRecord && mode != CacheModeReplay && mode != CacheModeReadThrough {
+		return nil, errors.Errorf("invalid llm cache mode %q, expected record, replay or read_through", mode)
+	}
+
+	cache := &ResponseCache{
+		mode:    mode,
+		entries: make(map[string][]cachedChoice),
+	}
+
+	if err := cache.load(path); err != nil {
+		return nil, err
+	}
+
+	if mode == CacheModeReplay {
+		return cache, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Wrap(err, "create llm cache dir error")
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "open llm cache error")
+	}
+	cache.file = file
+
+	return cache, nil
+}
+
+func (c *ResponseCache) load(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "open llm cache error")
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	for {
+		record := &cacheRecord{}
+		err := decoder.Decode(record)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "decode llm cache error")
+		}
+
+		c.entries[record.Key] = record.Choices
+	}
+}
+
+// Len returns the number of saved responses
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// Get returns the saved response of the prompt, never in record mode
+func (c *ResponseCache) Get(key string) (*llms.ContentResponse, bool) {
+	if c.mode == CacheModeRecord {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	choices, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	resp := &llms.ContentResponse{Choices: make([]*llms.ContentChoice, 0, len(choices))}
+	for _, choice := range choices {
+		toolCalls := make([]llms.ToolCall, 0, len(choice.ToolCalls))
+		for _, call := range choice.ToolCalls {
+			toolCalls = append(toolCalls, llms.ToolCall{
+				ID:           call.ID,
+				Type:         "function",
+				FunctionCall: &llms.FunctionCall{Name: call.Name, Arguments: call.Arguments},
+			})
+		}
+
+		resp.Choices = append(resp.Choices, &llms.ContentChoice{
+			Content:        choice.Content,
+			StopReason:     choice.StopReason,
+			ToolCalls:      toolCalls,
+			GenerationInfo: map[string]any{"cached": true},
+		})
+	}
+
+	return resp, true
+}
+
+// Put saves the response of the prompt
+func (c *ResponseCache) Put(key string, resp *llms.ContentResponse) error {
+	if c.file == nil || resp == nil {
+		return nil
+	}
+
+	record := &cacheRecord{Key: key, Choices: make([]cachedChoice, 0, len(resp.Choices))}
+	for _, choice := range resp.Choices {
+		cached := cachedChoice{Content: choice.Content, StopReason: choice.StopReason}
+		for _, call := range choice.ToolCalls {
+			if call.FunctionCall != nil {
+				cached.ToolCalls = append(cached.ToolCalls, cachedToolCall{ID: call.ID, Name: call.FunctionCall.Name, Arguments: call.FunctionCall.Arguments})
+			}
+		}
+
+		record.Choices = append(record.Choices, cached)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "encode llm cache record error")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = record.Choices
+	_, err = c.file.Write(append(line, '\n'))
+	return errors.Wrap(err, "write llm cache record error")
+}
+
+// Replay returns whether a miss must fail rather than call the model
+func (c *ResponseCache) Replay() bool {
+	return c.mode == CacheModeReplay
+}
+
+func (c *ResponseCache) Close() error {
+	if c.file == nil {
+		return nil
+	}
+
+	return c.file.Close()
+}
+
+// cacheKey hashes the prompt and the options shaping the response
+func cacheKey(model string, messages []llms.MessageContent, opts llms.CallOptions) (string, error) {
+	tools := make([]string, 0, len(opts.Tools))
+	for _, tool := range opts.Tools {
+		if tool.Function != nil {
+			tools = append(tools, tool.Function.Name)
+		}
+	}
+
+	data, err := json.Marshal(struct {
+		Model    string                `json:"model"`
+		Messages []llms.MessageContent `json:"messages"`
+		JSONMode bool                  `json:"json_mode"`
+		Tools    []string              `json:"tools"`
+	}{model, messages, opts.JSONMode, tools})
+	if err != nil {
+		return "", errors.Wrap(err, "encode llm cache key error")
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,50 @@
+package llms
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestResponseCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llm-cache.jsonl")
+
+	messages := []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "decide")}
+	key, err := cacheKey("openai", messages, llms.CallOptions{JSONMode: true})
+	assert.NoError(t, err)
+
+	other, err := cacheKey("openai", messages, llms.CallOptions{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other, "the JSON mode is part of the key")
+
+	recorder, err := NewResponseCache(CacheModeRecord, path)
+	assert.NoError(t, err)
+
+	resp := &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		Content:   `{"action":{"name":"exchange.no_action"}}`,
+		ToolCalls: []llms.ToolCall{{ID: "1", Type: "function", FunctionCall: &llms.FunctionCall{Name: "submit_decision", Arguments: "{}"}}},
+	}}}
+	assert.NoError(t, recorder.Put(key, resp))
+
+	_, ok := recorder.Get(key)
+	assert.False(t, ok, "record mode always calls the model")
+	assert.NoError(t, recorder.Close())
+
+	replayer, err := NewResponseCache(CacheModeReplay, path)
+	assert.NoError(t, err)
+	assert.True(t, replayer.Replay())
+	assert.Equal(t, 1, replayer.Len())
+
+	cached, ok := replayer.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, resp.Choices[0].Content, cached.Choices[0].Content)
+	assert.Equal(t, "submit_decision", cached.Choices[0].ToolCalls[0].FunctionCall.Name)
+
+	_, ok = replayer.Get(other)
+	assert.False(t, ok)
+
+	_, err = NewResponseCache("bogus", path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// lease is the content of a lock file
+type lease struct {
+	Owner     string    `json:"owner"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileLock is a Lock backed by a lease file, it guards instances sharing a host or volume
+type FileLock struct {
+	path  string
+	owner string
+	ttl   time.Duration
+	mu    sync.Mutex
+}
+
+func NewFileLock(path string, owner string, ttl time.Duration) *FileLock {
+	return &FileLock{
+		path:  path,
+		owner: owner,
+		ttl:   ttl,
+	}
+}
+
+func (l *FileLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, err := l.read()
+	if err != nil {
+		return false, err
+	}
+
+	if current != nil && current.Owner != l.owner && time.Now().Before(current.ExpiresAt) {
+		return false, nil
+	}
+
+	err = l.write(&lease{
+		Owner:     l.owner,
+		ExpiresAt: time.Now().Add(l.ttl),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// Read back, an instance taking over at the same time may have replaced the lease
+	current, err = l.read()
+	if err != nil {
+		return false, err
+	}
+
+	return current != nil && current.Owner == l.owner, nil
+}
+
+func (l *FileLock) Holder(ctx context.Context) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, err := l.read()
+	if err != nil || current == nil || time.Now().After(current.ExpiresAt) {
+		return "", err
+	}
+
+	return current.Owner, nil
+}
+
+func (l *FileLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, err := l.read()
+	if err != nil || current == nil || current.Owner != l.owner {
+		return err
+	}
+
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove lock file error")
+	}
+
+	return nil
+}
+
+// read returns the lease in the lock file, nil if there is none
+func (l *FileLock) read() (*lease, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "read lock file error")
+	}
+
+	current := &lease{}
+	if err := json.Unmarshal(data, current); err != nil {
+		// A partially written lease is treated as expired
+		return nil, nil
+	}
+
+	return current, nil
+}
+
+func (l *FileLock) write(current *lease) error {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return errors.Wrap(err, "marshal lease error")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return errors.Wrap(err, "create lock dir error")
+	}
+
+	tmp := l.path + "." + l.owner + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrap(err, "write lock file error")
+	}
+
+	if err := os.Rename(tmp, l.path); err != nil {
+		return errors.Wrap(err, "replace lock file error")
+	}
+
+	return nil
+}
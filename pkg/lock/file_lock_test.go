@@ -0,0 +1,60 @@
+package lock
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "lock.json")
+
+	first := NewFileLock(path, "first", time.Minute)
+	second := NewFileLock(path, "second", time.Minute)
+
+	acquired, err := first.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	// The holder renews, the other instance is kept out
+	acquired, err = second.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	acquired, err = first.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	holder, err := second.Holder(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", holder)
+
+	// Only the holder can release
+	assert.NoError(t, second.Release(ctx))
+	holder, _ = first.Holder(ctx)
+	assert.Equal(t, "first", holder)
+
+	assert.NoError(t, first.Release(ctx))
+	acquired, err = second.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestFileLockExpired(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "lock.json")
+
+	crashed := NewFileLock(path, "crashed", -time.Second)
+	acquired, err := crashed.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	// An instance that stopped renewing loses the lease
+	acquired, err = NewFileLock(path, "standby", time.Minute).TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+}
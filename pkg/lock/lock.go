@@ -0,0 +1,32 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// Lock is a lease that lets a single instance trade an account and symbol
+type Lock interface {
+	// TryAcquire acquires or renews the lease, it returns false while another instance holds it
+	TryAcquire(ctx context.Context) (bool, error)
+	// Holder returns the owner of the current lease, empty when nobody holds it
+	Holder(ctx context.Context) (string, error)
+	// Release gives up the lease if it is held by this instance
+	Release(ctx context.Context) error
+}
+
+// NewLock creates the lock for the configured driver, leases expire after ttl unless renewed
+func NewLock(cfg *config.InstanceLockConfig, key string, owner string, ttl time.Duration) (Lock, error) {
+	switch cfg.Driver {
+	case "", "file":
+		return NewFileLock(cfg.Path, owner, ttl), nil
+	case "redis":
+		return NewRedisLock(cfg.Addr, cfg.Password, cfg.DB, key, owner, ttl), nil
+	default:
+		return nil, errors.Errorf("lock driver not supported: %s", cfg.Driver)
+	}
+}
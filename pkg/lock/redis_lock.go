@@ -0,0 +1,86 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// renewScript extends the lease only while it is still held by the owner
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lease only while it is still held by the owner
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLock is a Lock backed by a Redis key, it guards instances across hosts
+type RedisLock struct {
+	client *redis.Client
+	key    string
+	owner  string
+	ttl    time.Duration
+}
+
+func NewRedisLock(addr string, password string, db int, key string, owner string, ttl time.Duration) *RedisLock {
+	return &RedisLock{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+		key:   key,
+		owner: owner,
+		ttl:   ttl,
+	}
+}
+
+func (l *RedisLock) TryAcquire(ctx context.Context) (bool, error) {
+	acquired, err := l.client.SetNX(ctx, l.key, l.owner, l.ttl).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "redis acquire lock error")
+	}
+
+	if acquired {
+		return true, nil
+	}
+
+	renewed, err := renewScript.Run(ctx, l.client, []string{l.key}, l.owner, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, errors.Wrap(err, "redis renew lock error")
+	}
+
+	return renewed == 1, nil
+}
+
+func (l *RedisLock) Holder(ctx context.Context) (string, error) {
+	owner, err := l.client.Get(ctx, l.key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "redis get lock error")
+	}
+
+	return owner, nil
+}
+
+func (l *RedisLock) Release(ctx context.Context) error {
+	defer l.client.Close()
+
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, l.owner).Err(); err != nil {
+		return errors.Wrap(err, "redis release lock error")
+	}
+
+	return nil
+}
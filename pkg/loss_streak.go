@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yubing744/trading-gpt/pkg/journal"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// setupLossStreak throttles entries after consecutive losing trades, picking up the streak from the journal
+func (s *Strategy) setupLossStreak(ctx context.Context) error {
+	cfg := &s.LossStreak
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.Losses == 0 {
+		cfg.Losses = 3
+	}
+	if cfg.SizeScale == 0 {
+		cfg.SizeScale = 0.5
+	}
+	if cfg.MinConfidence == 0 {
+		cfg.MinConfidence = 0.8
+	}
+
+	s.lossStreak = utils.NewLossStreak(cfg.Losses)
+
+	if s.journal != nil {
+		entries, err := s.journal.LoadEntries()
+		if err != nil {
+			log.WithError(err).Warn("Failed to load journal for the loss streak")
+		}
+
+		for _, entry := range entries {
+			if entry.Kind == journal.KindTradeClosed && entry.Trade != nil && entry.Symbol == s.Symbol {
+				s.lossStreak.Record(entry.Trade.PnL)
+			}
+		}
+	}
+
+	s.applySizeScale()
+
+	log.WithField("losses", cfg.Losses).
+		WithField("streak", s.lossStreak.Losses()).
+		WithField("throttled", s.lossStreak.Throttled()).
+		Info("Loss streak throttle enabled")
+	return nil
+}
+
+// recordLossStreak counts the closed trade and tells the admins when it engaged or lifted the throttle
+func (s *Strategy) recordLossStreak(ctx context.Context, pnl float64) {
+	if s.lossStreak == nil {
+		return
+	}
+
+	engaged, lifted := s.lossStreak.Record(pnl)
+	if !engaged && !lifted {
+		return
+	}
+
+	s.applySizeScale()
+
+	if engaged {
+		s.notifyAdmins(ctx, ttypes.SeverityWarning, fmt.Sprintf("🐢 %d consecutive losing trades on %s, entries throttled to %.0f%% size and a confidence of at least %.2f until a winning trade. Send /throttle reset to lift it.",
+			s.lossStreak.Losses(), s.Symbol, s.LossStreak.SizeScale*100, s.LossStreak.MinConfidence))
+	} else {
+		s.notifyAdmins(ctx, ttypes.SeverityInfo, fmt.Sprintf("🐇 Winning trade on %s, loss streak throttle lifted.", s.Symbol))
+	}
+}
+
+// checkLossStreak returns why an entry is rejected while the throttle is engaged, empty when it may execute
+func (s *Strategy) checkLossStreak(actionName string, args map[string]string) string {
+	if s.lossStreak == nil || !s.lossStreak.Throttled() || !isEntryAction(actionName) {
+		return ""
+	}
+
+	confidence, stated := journal.ParseConfidence(args["confidence"])
+	if stated && confidence >= s.LossStreak.MinConfidence {
+		return ""
+	}
+
+	return fmt.Sprintf("after %d consecutive losing trades entries need a confidence arg of at least %.2f until a winning trade",
+		s.lossStreak.Losses(), s.LossStreak.MinConfidence)
+}
+
+// lossStreakMsg describes the loss streak for the decision prompt, empty while there is none
+func (s *Strategy) lossStreakMsg() string {
+	if s.lossStreak == nil {
+		return ""
+	}
+
+	streak := s.lossStreak.String()
+	if streak == "" {
+		return ""
+	}
+
+	if s.lossStreak.Throttled() {
+		return fmt.Sprintf("Loss streak: %s, entries are sized at %.0f%% and need a confidence of at least %.2f until a winning trade.",
+			streak, s.LossStreak.SizeScale*100, s.LossStreak.MinConfidence)
+	}
+
+	return fmt.Sprintf("Loss streak: %s.", streak)
+}
+
+// handleThrottle reports the loss streak throttle or lifts it: /throttle, /throttle reset
+func (s *Strategy) handleThrottle(ctx context.Context, chatSession ttypes.ISession, args string) {
+	if s.lossStreak == nil {
+		s.replyMsg(ctx, chatSession, "The loss streak throttle is not enabled.")
+		return
+	}
+
+	switch strings.TrimSpace(args) {
+	case "":
+		msg := s.lossStreakMsg()
+		if msg == "" {
+			msg = "No losing streak, entries are not throttled."
+		}
+		s.replyMsg(ctx, chatSession, msg)
+	case "reset":
+		if !s.lossStreak.Reset() {
+			s.replyMsg(ctx, chatSession, "Entries are not throttled, loss streak cleared.")
+			return
+		}
+
+		s.applySizeScale()
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("✅ Loss streak throttle on %s lifted by the operator.", s.Symbol))
+	default:
+		s.replyMsg(ctx, chatSession, "Usage: /throttle or /throttle reset")
+	}
+}
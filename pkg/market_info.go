@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yubing744/trading-gpt/pkg/apis/okx"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// setupMarketInfo loads the market metadata of the symbol and makes prompts and orders use it instead of defaults,
+// falling back to the cached info when the exchange can't be reached
+func (s *Strategy) setupMarketInfo(ctx context.Context) error {
+	cfg := &s.MarketInfo
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.InstType == "" {
+		cfg.InstType = "SWAP"
+	}
+	if cfg.CachePath == "" {
+		cfg.CachePath = fmt.Sprintf("memory-bank/market-%s.json", s.Symbol)
+	}
+
+	info := utils.NewMarketInfo(s.Market)
+	if cfg.InstID != "" {
+		instrument, err := okx.NewOKXClient().GetInstrument(ctx, cfg.InstType, cfg.InstID)
+		if err != nil {
+			log.WithError(err).WithField("inst_id", cfg.InstID).Warn("get instrument error, using the cached market info")
+
+			cached, err := utils.LoadMarketInfo(cfg.CachePath)
+			if err != nil {
+				log.WithError(err).Warn("no cached market info, the contract multiplier defaults to 1")
+			} else {
+				info = cached
+			}
+		} else {
+			info.SetInstrument(instrument.TickSize, instrument.LotSize, instrument.MinSize, instrument.ContractValue)
+		}
+	}
+
+	if err := utils.SaveMarketInfo(cfg.CachePath, info); err != nil {
+		log.WithError(err).Warn("cache market info error")
+	}
+
+	info.Apply(&s.Market)
+	s.Position.Market = s.Market
+
+	// Configured formats take precedence over the market precisions
+	if !s.Precision.Price.IsSet() {
+		decimals := info.PricePrecision
+		s.Precision.Price.Decimals = &decimals
+	}
+	if !s.Precision.Volume.IsSet() {
+		decimals := info.QuantityPrecision
+		s.Precision.Volume.Decimals = &decimals
+	}
+
+	s.marketInfo = info
+	log.WithField("market", info.String()).Info("Market info loaded")
+	return nil
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 ranking parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// stopWords are common words ignored by the keyword index
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true,
+	"are": true, "was": true, "were": true, "from": true, "has": true, "have": true,
+	"not": true, "but": true, "its": true, "into": true, "than": true, "then": true,
+	"is": true, "of": true, "to": true, "in": true, "on": true, "at": true, "a": true,
+	"an": true, "be": true, "by": true, "or": true, "as": true, "it": true,
+}
+
+// Tokenize splits text into lower-cased word tokens, dropping stop words and single characters
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_'
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if len([]rune(field)) < 2 || stopWords[field] {
+			continue
+		}
+
+		tokens = append(tokens, field)
+	}
+
+	return tokens
+}
+
+type bm25Doc struct {
+	id     string
+	length int
+	terms  map[string]int
+}
+
+// BM25Index is a small in-memory BM25 keyword index over memories
+type BM25Index struct {
+	docs         []*bm25Doc
+	docFreq      map[string]int
+	totalLength  int
+	documentByID map[string]*bm25Doc
+}
+
+// NewBM25Index creates an empty index
+func NewBM25Index() *BM25Index {
+	return &BM25Index{
+		docs:         make([]*bm25Doc, 0),
+		docFreq:      make(map[string]int),
+		documentByID: make(map[string]*bm25Doc),
+	}
+}
+
+// Add indexes the text under the given document ID, replacing any previous text for that ID
+func (idx *BM25Index) Add(id string, text string) {
+	if _, ok := idx.documentByID[id]; ok {
+		idx.Remove(id)
+	}
+
+	tokens := Tokenize(text)
+	doc := &bm25Doc{
+		id:     id,
+		length: len(tokens),
+		terms:  make(map[string]int),
+	}
+
+	for _, token := range tokens {
+		doc.terms[token]++
+	}
+
+	for term := range doc.terms {
+		idx.docFreq[term]++
+	}
+
+	idx.docs = append(idx.docs, doc)
+	idx.documentByID[id] = doc
+	idx.totalLength += doc.length
+}
+
+// Remove drops a document from the index
+func (idx *BM25Index) Remove(id string) {
+	doc, ok := idx.documentByID[id]
+	if !ok {
+		return
+	}
+
+	for term := range doc.terms {
+		idx.docFreq[term]--
+		if idx.docFreq[term] <= 0 {
+			delete(idx.docFreq, term)
+		}
+	}
+
+	for i, d := range idx.docs {
+		if d == doc {
+			idx.docs = append(idx.docs[:i], idx.docs[i+1:]...)
+			break
+		}
+	}
+
+	delete(idx.documentByID, id)
+	idx.totalLength -= doc.length
+}
+
+// Len returns the number of indexed documents
+func (idx *BM25Index) Len() int {
+	return len(idx.docs)
+}
+
+// BM25Result is a scored search hit
+type BM25Result struct {
+	ID    string
+	Score float64
+}
+
+// Search returns up to topK documents with a positive BM25 score for the query, best first
+func (idx *BM25Index) Search(query string, topK int) []BM25Result {
+	results := make([]BM25Result, 0)
+	if len(idx.docs) == 0 || topK <= 0 {
+		return results
+	}
+
+	queryTerms := make(map[string]bool)
+	for _, token := range Tokenize(query) {
+		queryTerms[token] = true
+	}
+
+	n := float64(len(idx.docs))
+	avgLength := float64(idx.totalLength) / n
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	for _, doc := range idx.docs {
+		score := 0.0
+
+		for term := range queryTerms {
+			tf := float64(doc.terms[term])
+			if tf == 0 {
+				continue
+			}
+
+			df := float64(idx.docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.length)/avgLength))
+		}
+
+		if score > 0 {
+			results = append(results, BM25Result{ID: doc.id, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > topK {
+		results = results[:topK]
+	}
+
+	return results
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("The BOLL breakout failed, RSI=72.5 on 5m!")
+	expected := []string{"boll", "breakout", "failed", "rsi", "72", "5m"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Expected tokens %v, got %v", expected, tokens)
+	}
+}
+
+func TestBM25IndexSearch(t *testing.T) {
+	idx := NewBM25Index()
+	idx.Add("a", "Breakout above resistance failed on low volume, stop loss hit.")
+	idx.Add("b", "Ranging market chop, stop loss too tight, many small losses.")
+	idx.Add("c", "Trend following short worked well after breakdown below support.")
+
+	results := idx.Search("volume breakout resistance", 3)
+	if len(results) == 0 || results[0].ID != "a" {
+		t.Fatalf("Expected breakout memory ranked first, got %+v", results)
+	}
+
+	results = idx.Search("chop stop loss", 1)
+	if len(results) != 1 || results[0].ID != "b" {
+		t.Errorf("Expected chop memory ranked first, got %+v", results)
+	}
+
+	if results := idx.Search("unrelated words", 3); len(results) != 0 {
+		t.Errorf("Expected no results, got %+v", results)
+	}
+}
+
+func TestBM25IndexReplaceAndRemove(t *testing.T) {
+	idx := NewBM25Index()
+	idx.Add("a", "breakout volume")
+	idx.Add("a", "chop range")
+
+	if idx.Len() != 1 {
+		t.Errorf("Expected re-adding the same id to replace the document, got %d docs", idx.Len())
+	}
+	if results := idx.Search("breakout", 3); len(results) != 0 {
+		t.Errorf("Expected replaced text to be gone, got %+v", results)
+	}
+
+	idx.Remove("a")
+	if idx.Len() != 0 {
+		t.Errorf("Expected empty index after removal, got %d docs", idx.Len())
+	}
+}
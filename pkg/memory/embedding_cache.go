@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type embeddingEntry struct {
+	Hash   string    `json:"hash"`
+	Vector []float32 `json:"vector"`
+}
+
+// EmbeddingCache persists memory embedding vectors on disk keyed by memory ID and content hash,
+// so that only new or changed memories need to be embedded at startup
+type EmbeddingCache struct {
+	path    string
+	entries map[string]*embeddingEntry
+	mu      sync.RWMutex
+}
+
+// NewEmbeddingCache creates an embedding cache backed by the given JSON file
+func NewEmbeddingCache(path string) *EmbeddingCache {
+	return &EmbeddingCache{
+		path:    path,
+		entries: make(map[string]*embeddingEntry),
+	}
+}
+
+// ContentHash returns the hash identifying a memory content version
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the cached vectors from disk, a missing file yields an empty cache
+func (c *EmbeddingCache) Load() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read embedding cache: %w", err)
+	}
+
+	entries := make(map[string]*embeddingEntry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse embedding cache: %w", err)
+	}
+
+	c.mu.Lock()
+	c.entries = entries
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Save writes the cached vectors to disk
+func (c *EmbeddingCache) Save() error {
+	c.mu.RLock()
+	data, err := json.Marshal(c.entries)
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode embedding cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create embedding cache directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write embedding cache: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the cached vector of a memory if its content has not changed
+func (c *EmbeddingCache) Get(id string, content string) ([]float32, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[id]
+	if !ok || entry.Hash != ContentHash(content) {
+		return nil, false
+	}
+
+	return entry.Vector, true
+}
+
+// Set stores the vector of a memory content
+func (c *EmbeddingCache) Set(id string, content string, vector []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[id] = &embeddingEntry{
+		Hash:   ContentHash(content),
+		Vector: vector,
+	}
+}
+
+// Prune removes the vectors of memories that no longer exist
+func (c *EmbeddingCache) Prune(ids map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id := range c.entries {
+		if !ids[id] {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// Len returns the number of cached vectors
+func (c *EmbeddingCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.entries)
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors, 0 if they are incompatible
+func cosineSimilarity(a []float32, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeEmbed embeds texts as keyword presence vectors and counts embedded texts
+func fakeEmbed(calls *int) EmbedFunc {
+	keywords := []string{"breakout", "reversal", "funding"}
+	return func(ctx context.Context, texts []string) ([][]float32, error) {
+		vectors := make([][]float32, 0, len(texts))
+		for _, text := range texts {
+			*calls++
+			vector := make([]float32, len(keywords))
+			for i, kw := range keywords {
+				if strings.Contains(text, kw) {
+					vector[i] = 1
+				}
+			}
+			vectors = append(vectors, vector)
+		}
+		return vectors, nil
+	}
+}
+
+func TestIndexEmbeddingsIncremental(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "embeddings.json")
+	writeReflection(t, dir, "a.md", "BTCUSDT", "2024-01-01T00:00:00Z", "breakout long worked")
+	writeReflection(t, dir, "b.md", "BTCUSDT", "2024-01-02T00:00:00Z", "funding reversal short")
+
+	calls := 0
+	retriever := NewMemoryRetriever(dir, nil, 0)
+	retriever.SetEmbedder(fakeEmbed(&calls), NewEmbeddingCache(cachePath), 0)
+	if err := retriever.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	count, err := retriever.IndexEmbeddings(context.Background())
+	if err != nil || count != 2 {
+		t.Fatalf("Expected 2 embedded memories, got %d (%v)", count, err)
+	}
+
+	// Restart with the persisted cache and one changed memory
+	writeReflection(t, dir, "b.md", "BTCUSDT", "2024-01-02T00:00:00Z", "funding reversal short, updated")
+	cache := NewEmbeddingCache(cachePath)
+	if err := cache.Load(); err != nil || cache.Len() != 2 {
+		t.Fatalf("Expected 2 cached vectors, got %d (%v)", cache.Len(), err)
+	}
+
+	calls = 0
+	retriever = NewMemoryRetriever(dir, nil, 0)
+	retriever.SetEmbedder(fakeEmbed(&calls), cache, 0)
+	if err := retriever.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	count, err = retriever.IndexEmbeddings(context.Background())
+	if err != nil || count != 1 || calls != 1 {
+		t.Fatalf("Expected only the changed memory to be embedded, got %d embedded, %d calls (%v)", count, calls, err)
+	}
+
+	memories, err := retriever.RetrieveMemories(context.Background(), "clean breakout", 1)
+	if err != nil {
+		t.Fatalf("Failed to retrieve memories: %v", err)
+	}
+	if len(memories) != 1 || memories[0].ID != "a.md" {
+		t.Errorf("Expected breakout memory, got %+v", memories)
+	}
+}
+
+func TestRetrieveRerankEmbeddingShortlist(t *testing.T) {
+	dir := t.TempDir()
+	writeReflection(t, dir, "a.md", "BTCUSDT", "2024-01-01T00:00:00Z", "breakout long worked")
+	writeReflection(t, dir, "b.md", "BTCUSDT", "2024-01-02T00:00:00Z", "breakout short failed")
+	writeReflection(t, dir, "c.md", "BTCUSDT", "2024-01-03T00:00:00Z", "funding reversal short")
+
+	scored := make([]string, 0)
+	complete := func(ctx context.Context, prompt string) (string, error) {
+		scored = append(scored, prompt)
+		if strings.Contains(prompt, "failed") {
+			return "9", nil
+		}
+		return "2", nil
+	}
+
+	calls := 0
+	retriever := NewMemoryRetriever(dir, complete, 0)
+	retriever.SetEmbedder(fakeEmbed(&calls), NewEmbeddingCache(filepath.Join(t.TempDir(), "embeddings.json")), 2)
+	if err := retriever.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	memories, err := retriever.RetrieveMemories(context.Background(), "clean breakout", 1)
+	if err != nil {
+		t.Fatalf("Failed to retrieve memories: %v", err)
+	}
+
+	// Only the two breakout memories are shortlisted and scored, the LLM picks among them
+	if len(scored) != 2 {
+		t.Errorf("Expected 2 LLM scoring calls, got %d", len(scored))
+	}
+	if len(memories) != 1 || memories[0].ID != "b.md" {
+		t.Errorf("Expected the re-ranked breakout memory, got %+v", memories)
+	}
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MemoryKindKnowledge is the kind of user-authored playbooks loaded from the knowledge base
+const MemoryKindKnowledge = "knowledge"
+
+// LoadKnowledgeBase reads the user-authored markdown playbooks under dir, including subdirectories.
+// A playbook may set a symbol in its front matter to apply only to that trading pair.
+func LoadKnowledgeBase(dir string) ([]*Memory, error) {
+	files := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".md") {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge base: %w", err)
+	}
+
+	sort.Strings(files)
+
+	playbooks := make([]*Memory, 0, len(files))
+	for _, file := range files {
+		mem, err := LoadMemoryFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			rel = filepath.Base(file)
+		}
+
+		mem.ID = "kb/" + filepath.ToSlash(rel)
+		mem.Kind = MemoryKindKnowledge
+		playbooks = append(playbooks, mem)
+	}
+
+	return playbooks, nil
+}
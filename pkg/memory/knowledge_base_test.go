@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadKnowledgeBase(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "events"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	files := map[string]string{
+		"breakout.md":        "# Breakout checklist\nWait for a retest with rising volume.",
+		"events/news.md":     "# News policy\nFlatten positions before CPI and FOMC releases.",
+		"eth_only.md":        "---\nsymbol: ETHUSDT\n---\n# ETH notes\nWatch gas fees and staking flows.",
+		"events/ignored.txt": "not a playbook",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write playbook: %v", err)
+		}
+	}
+
+	playbooks, err := LoadKnowledgeBase(dir)
+	if err != nil {
+		t.Fatalf("Failed to load knowledge base: %v", err)
+	}
+	if len(playbooks) != 3 {
+		t.Fatalf("Expected 3 playbooks, got %d", len(playbooks))
+	}
+	if playbooks[2].ID != "kb/events/news.md" || playbooks[2].Kind != MemoryKindKnowledge {
+		t.Errorf("Unexpected playbook: %+v", playbooks[2])
+	}
+
+	retriever := NewMemoryRetriever(t.TempDir(), nil, 0)
+	for _, playbook := range playbooks {
+		retriever.Add(playbook)
+	}
+
+	filter := &MemoryFilter{Kind: MemoryKindKnowledge, Symbol: "BTCUSDT"}
+	found, err := retriever.RetrieveFilteredMemories(context.Background(), "CPI release tomorrow", filter, 5)
+	if err != nil {
+		t.Fatalf("Failed to retrieve playbooks: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != "kb/events/news.md" {
+		t.Errorf("Expected news playbook only, got %+v", found)
+	}
+}
@@ -32,7 +32,7 @@ func (m *MemoryManager) LoadMemory() (string, error) {
 		return "", err
 	}
 
-	return string(content), nil
+	return ParsePlainDocument(string(content)).Body, nil
 }
 
 // SaveMemory saves memory content to file with word limit enforcement
@@ -48,7 +48,13 @@ func (m *MemoryManager) SaveMemory(content string) (string, bool, error) {
 		return "", false, fmt.Errorf("failed to create memory directory: %w", err)
 	}
 
-	err := os.WriteFile(m.memoryPath, []byte(truncated), 0644)
+	doc := &Document{
+		Version: CurrentSchemaVersion,
+		Body:    truncated,
+		plain:   true,
+	}
+
+	err := os.WriteFile(m.memoryPath, []byte(doc.String()), 0644)
 	if err != nil {
 		return "", false, fmt.Errorf("failed to write memory file: %w", err)
 	}
@@ -56,6 +62,11 @@ func (m *MemoryManager) SaveMemory(content string) (string, bool, error) {
 	return truncated, wasTruncated, nil
 }
 
+// Migrate upgrades the memory file to the current schema version, keeping a backup of the original
+func (m *MemoryManager) Migrate() (bool, error) {
+	return MigrateFile(m.memoryPath, true)
+}
+
 // truncateToMaxWords truncates content to maximum word limit
 func (m *MemoryManager) truncateToMaxWords(content string) string {
 	words := strings.Fields(content)
@@ -0,0 +1,477 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yubing744/trading-gpt/pkg/prompt"
+	"github.com/yubing744/trading-gpt/pkg/utils/xtemplate"
+)
+
+// Memory is a retrievable memory item, such as a trade reflection saved in the memory bank
+type Memory struct {
+	ID         string            // File name of the memory
+	Path       string            // Full path of the memory file
+	Symbol     string            // Trading pair symbol from the front matter
+	Timestamp  time.Time         // Time of the trade the memory was generated for
+	Meta       map[string]string // Raw front matter fields
+	Content    string            // Markdown body without front matter
+	Version    int               // Schema version of the memory file
+	Context    *TradeContext     // Structured trade context, nil if not attached
+	Kind       string            // Episodic for specific trades, semantic for distilled rules
+	Importance string            // High-importance memories are prioritized in retrieval, low-importance ones are skipped
+
+	Reinforcement int // Profitable minus losing trades whose decision the memory was injected into
+}
+
+// Summary returns the memory content truncated to the given number of words
+func (m *Memory) Summary(maxWords int) string {
+	words := strings.Fields(m.Content)
+	if maxWords <= 0 || len(words) <= maxWords {
+		return strings.Join(words, " ")
+	}
+
+	return strings.Join(words[:maxWords], " ") + " ..."
+}
+
+// CompletionFunc sends a single prompt to the LLM and returns the reply text
+type CompletionFunc func(ctx context.Context, prompt string) (string, error)
+
+// EmbedFunc converts texts into embedding vectors
+type EmbedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// MemoryRetriever loads memories from the memory bank and retrieves the ones relevant to a situation
+type MemoryRetriever struct {
+	dir      string
+	complete CompletionFunc
+	cache    *RelevanceCache
+	index    *BM25Index
+	memories []*Memory
+	mu       sync.RWMutex
+
+	embed            EmbedFunc
+	embeddings       *EmbeddingCache
+	rerankCandidates int
+	retention        map[string]RetentionPolicy
+	now              func() time.Time
+}
+
+// NewMemoryRetriever creates a retriever over the memory files in dir. If complete is nil,
+// memories are retrieved from a BM25 keyword index instead of LLM relevance scoring.
+func NewMemoryRetriever(dir string, complete CompletionFunc, cacheTTL time.Duration) *MemoryRetriever {
+	return &MemoryRetriever{
+		dir:       dir,
+		complete:  complete,
+		cache:     NewRelevanceCache(cacheTTL),
+		index:     NewBM25Index(),
+		memories:  make([]*Memory, 0),
+		retention: make(map[string]RetentionPolicy),
+		now:       time.Now,
+	}
+}
+
+// SetRetention sets the retention policy of a memory kind
+func (r *MemoryRetriever) SetRetention(kind string, policy RetentionPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.retention[kind] = policy
+}
+
+// applyRetention drops the memories that are no longer retained by the policy of their kind
+func (r *MemoryRetriever) applyRetention(memories []*Memory) []*Memory {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.retention) == 0 {
+		return memories
+	}
+
+	byKind := make(map[string][]*Memory)
+	for _, mem := range memories {
+		byKind[mem.Kind] = append(byKind[mem.Kind], mem)
+	}
+
+	retained := make(map[*Memory]bool, len(memories))
+	for kind, mems := range byKind {
+		policy, ok := r.retention[kind]
+		if !ok {
+			for _, mem := range mems {
+				retained[mem] = true
+			}
+			continue
+		}
+
+		for _, mem := range policy.Apply(mems, r.now()) {
+			retained[mem] = true
+		}
+	}
+
+	rets := make([]*Memory, 0, len(retained))
+	for _, mem := range memories {
+		if retained[mem] {
+			rets = append(rets, mem)
+		}
+	}
+
+	return rets
+}
+
+// SetEmbedder enables embedding similarity retrieval. With LLM relevance scoring, only the candidates closest
+// to the situation are scored by the LLM, at least topK of them.
+func (r *MemoryRetriever) SetEmbedder(embed EmbedFunc, cache *EmbeddingCache, candidates int) {
+	r.embed = embed
+	r.embeddings = cache
+	r.rerankCandidates = candidates
+}
+
+// IndexEmbeddings embeds the memories missing from the embedding cache and prunes removed ones,
+// returning the number of newly embedded memories
+func (r *MemoryRetriever) IndexEmbeddings(ctx context.Context) (int, error) {
+	if r.embed == nil {
+		return 0, nil
+	}
+
+	memories := r.GetMemories()
+
+	ids := make(map[string]bool, len(memories))
+	for _, mem := range memories {
+		ids[mem.ID] = true
+	}
+	r.embeddings.Prune(ids)
+
+	return r.ensureEmbeddings(ctx, memories)
+}
+
+// ensureEmbeddings embeds the memories whose content is not in the cache yet and persists the cache
+func (r *MemoryRetriever) ensureEmbeddings(ctx context.Context, memories []*Memory) (int, error) {
+	missing := make([]*Memory, 0)
+	texts := make([]string, 0)
+	for _, mem := range memories {
+		if _, ok := r.embeddings.Get(mem.ID, mem.Content); !ok {
+			missing = append(missing, mem)
+			texts = append(texts, mem.Content)
+		}
+	}
+
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	vectors, err := r.embed(ctx, texts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to embed memories: %w", err)
+	}
+
+	if len(vectors) != len(missing) {
+		return 0, fmt.Errorf("expected %d embeddings, got %d", len(missing), len(vectors))
+	}
+
+	for i, mem := range missing {
+		r.embeddings.Set(mem.ID, mem.Content, vectors[i])
+	}
+
+	if err := r.embeddings.Save(); err != nil {
+		return 0, err
+	}
+
+	return len(missing), nil
+}
+
+// Load reads all markdown memories from the memory bank directory
+func (r *MemoryRetriever) Load() error {
+	memories := make([]*Memory, 0)
+
+	files, err := filepath.Glob(filepath.Join(r.dir, "*.md"))
+	if err != nil {
+		return fmt.Errorf("failed to list memory files: %w", err)
+	}
+
+	for _, file := range files {
+		mem, err := LoadMemoryFile(file)
+		if err != nil {
+			return err
+		}
+
+		memories = append(memories, mem)
+	}
+
+	sort.Slice(memories, func(i, j int) bool {
+		return memories[i].Timestamp.Before(memories[j].Timestamp)
+	})
+
+	// Build the keyword index at load time
+	index := NewBM25Index()
+	for _, mem := range memories {
+		index.Add(mem.ID, mem.Content)
+	}
+
+	r.mu.Lock()
+	r.memories = memories
+	r.index = index
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Add appends a newly created memory so it can be retrieved without reloading
+func (r *MemoryRetriever) Add(mem *Memory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.memories = append(r.memories, mem)
+	r.index.Add(mem.ID, mem.Content)
+}
+
+// GetMemories returns all loaded memories
+func (r *MemoryRetriever) GetMemories() []*Memory {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]*Memory{}, r.memories...)
+}
+
+// RetrieveMemories returns up to topK memories most relevant to the situation
+func (r *MemoryRetriever) RetrieveMemories(ctx context.Context, situation string, topK int) ([]*Memory, error) {
+	return r.RetrieveFilteredMemories(ctx, situation, nil, topK)
+}
+
+// RetrieveFilteredMemories returns up to topK memories matching the filter that are most relevant to the situation,
+// e.g. previous losing shorts on this symbol in a ranging regime
+func (r *MemoryRetriever) RetrieveFilteredMemories(ctx context.Context, situation string, filter *MemoryFilter, topK int) ([]*Memory, error) {
+	memories := make([]*Memory, 0)
+	for _, mem := range r.GetMemories() {
+		if filter.Match(mem) && mem.Importance != ImportanceLow {
+			memories = append(memories, mem)
+		}
+	}
+	memories = r.applyRetention(memories)
+
+	if len(memories) == 0 || topK <= 0 {
+		return []*Memory{}, nil
+	}
+
+	// High-importance memories, such as strategy review memos, take the first slots
+	important := make([]*Memory, 0)
+	normal := make([]*Memory, 0, len(memories))
+	for _, mem := range memories {
+		if mem.Importance == ImportanceHigh {
+			important = append(important, mem)
+		} else {
+			normal = append(normal, mem)
+		}
+	}
+
+	important = RetentionPolicy{MaxCount: topK}.Apply(important, r.now())
+	if len(important) >= topK || len(normal) == 0 {
+		return important, nil
+	}
+
+	ranked, err := r.rankMemories(ctx, normal, situation, topK-len(important))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(important, ranked...), nil
+}
+
+// rankMemories returns the topK memories most relevant to the situation, scored by the LLM,
+// by embedding similarity, by the LLM over an embedding shortlist or by keywords depending on the configuration
+func (r *MemoryRetriever) rankMemories(ctx context.Context, memories []*Memory, situation string, topK int) ([]*Memory, error) {
+	if r.complete == nil {
+		if r.embed != nil {
+			return r.retrieveByEmbeddings(ctx, memories, situation, topK)
+		}
+
+		return r.retrieveByKeywords(memories, situation, topK), nil
+	}
+
+	// Embeddings shortlist the candidates locally, so the LLM calls no longer grow with the memories
+	if r.embed != nil {
+		candidates := r.rerankCandidates
+		if candidates < topK {
+			candidates = topK
+		}
+
+		shortlist, err := r.retrieveByEmbeddings(ctx, memories, situation, candidates)
+		if err != nil {
+			return nil, err
+		}
+		memories = shortlist
+	}
+
+	situationKey := SituationKey(situation)
+	scores := make(map[string]float64, len(memories))
+
+	for _, mem := range memories {
+		score, ok := r.cache.Get(situationKey, mem.ID)
+		if !ok {
+			var err error
+			score, err = r.scoreRelevance(ctx, situation, mem)
+			if err != nil {
+				return nil, err
+			}
+
+			r.cache.Set(situationKey, mem.ID, score)
+		}
+
+		scores[mem.ID] = score
+	}
+
+	sort.SliceStable(memories, func(i, j int) bool {
+		return scores[memories[i].ID] > scores[memories[j].ID]
+	})
+
+	if len(memories) > topK {
+		memories = memories[:topK]
+	}
+
+	return memories, nil
+}
+
+// retrieveByEmbeddings ranks the memories by cosine similarity between their embeddings and the situation
+func (r *MemoryRetriever) retrieveByEmbeddings(ctx context.Context, memories []*Memory, situation string, topK int) ([]*Memory, error) {
+	if _, err := r.ensureEmbeddings(ctx, memories); err != nil {
+		return nil, err
+	}
+
+	vectors, err := r.embed(ctx, []string{situation})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed situation: %w", err)
+	}
+
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("no embedding returned for situation")
+	}
+
+	scores := make(map[string]float64, len(memories))
+	for _, mem := range memories {
+		if vector, ok := r.embeddings.Get(mem.ID, mem.Content); ok {
+			scores[mem.ID] = cosineSimilarity(vectors[0], vector)
+		}
+	}
+
+	sort.SliceStable(memories, func(i, j int) bool {
+		return scores[memories[i].ID] > scores[memories[j].ID]
+	})
+
+	if len(memories) > topK {
+		memories = memories[:topK]
+	}
+
+	return memories, nil
+}
+
+// retrieveByKeywords ranks the memories against the situation with the BM25 keyword index
+func (r *MemoryRetriever) retrieveByKeywords(memories []*Memory, situation string, topK int) []*Memory {
+	byID := make(map[string]*Memory, len(memories))
+	for _, mem := range memories {
+		byID[mem.ID] = mem
+	}
+
+	r.mu.RLock()
+	results := r.index.Search(situation, r.index.Len())
+	r.mu.RUnlock()
+
+	rets := make([]*Memory, 0, topK)
+	for _, result := range results {
+		if len(rets) >= topK {
+			break
+		}
+
+		if mem, ok := byID[result.ID]; ok {
+			rets = append(rets, mem)
+		}
+	}
+
+	return rets
+}
+
+var scorePattern = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// scoreRelevance asks the LLM to rate the memory relevance in [0, 1]
+func (r *MemoryRetriever) scoreRelevance(ctx context.Context, situation string, mem *Memory) (float64, error) {
+	promptText, err := xtemplate.Render(prompt.MemoryRelevanceTpl, map[string]interface{}{
+		"Situation": situation,
+		"Memory":    mem.Content,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to render memory relevance prompt: %w", err)
+	}
+
+	reply, err := r.complete(ctx, promptText)
+	if err != nil {
+		return 0, fmt.Errorf("failed to score memory %s: %w", mem.ID, err)
+	}
+
+	match := scorePattern.FindString(reply)
+	if match == "" {
+		return 0, nil
+	}
+
+	score, err := strconv.ParseFloat(match, 64)
+	if err != nil {
+		return 0, nil
+	}
+
+	if score > 10 {
+		score = 10
+	}
+
+	return score / 10, nil
+}
+
+// LoadMemoryFile reads a memory file and parses its front matter
+func LoadMemoryFile(path string) (*Memory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory file: %w", err)
+	}
+
+	doc := ParseDocument(string(data))
+
+	mem := &Memory{
+		ID:      filepath.Base(path),
+		Path:    path,
+		Symbol:  doc.Meta["symbol"],
+		Meta:    doc.Meta,
+		Content: strings.TrimSpace(doc.Body),
+		Version: doc.Version,
+		Kind:    MemoryKindEpisodic,
+	}
+
+	if kind, ok := doc.Meta[MemoryKindKey]; ok {
+		mem.Kind = kind
+	}
+
+	mem.Importance = doc.Meta[ImportanceKey]
+	mem.Reinforcement, _ = strconv.Atoi(doc.Meta[ReinforcementKey])
+
+	if text, ok := doc.Meta[TradeContextKey]; ok {
+		if c, err := ParseTradeContext(text); err == nil {
+			mem.Context = c
+		}
+	}
+
+	if ts, ok := doc.Meta["timestamp"]; ok {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			mem.Timestamp = t
+		}
+	}
+
+	if mem.Timestamp.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			mem.Timestamp = info.ModTime()
+		}
+	}
+
+	return mem, nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeReflection(t *testing.T, dir string, name string, symbol string, ts string, body string) {
+	content := "---\nsymbol: " + symbol + "\ntimestamp: " + ts + "\n---\n\n" + body
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write reflection: %v", err)
+	}
+}
+
+func TestMemoryRetrieverLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeReflection(t, dir, "b.md", "BTCUSDT", "2024-01-02T00:00:00Z", "# Reflection\nBreakout failed on low volume.")
+	writeReflection(t, dir, "a.md", "ETHUSDT", "2024-01-01T00:00:00Z", "# Reflection\nStop loss too tight in chop.")
+
+	r := NewMemoryRetriever(dir, nil, 0)
+	if err := r.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	memories := r.GetMemories()
+	if len(memories) != 2 {
+		t.Fatalf("Expected 2 memories, got %d", len(memories))
+	}
+	if memories[0].ID != "a.md" || memories[0].Symbol != "ETHUSDT" {
+		t.Errorf("Expected memories sorted by timestamp, got %s", memories[0].ID)
+	}
+	if strings.Contains(memories[0].Content, "timestamp:") {
+		t.Errorf("Expected front matter to be stripped, got: %s", memories[0].Content)
+	}
+}
+
+func TestMemoryRetrieverKeywordFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeReflection(t, dir, "a.md", "BTCUSDT", "2024-01-01T00:00:00Z", "Breakout failed on low volume.")
+	writeReflection(t, dir, "b.md", "BTCUSDT", "2024-01-02T00:00:00Z", "Stop loss too tight in chop.")
+
+	r := NewMemoryRetriever(dir, nil, 0)
+	if err := r.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	memories, err := r.RetrieveMemories(context.Background(), "possible breakout above resistance", 3)
+	if err != nil {
+		t.Fatalf("Failed to retrieve memories: %v", err)
+	}
+	if len(memories) != 1 || memories[0].ID != "a.md" {
+		t.Errorf("Expected only the breakout memory, got %+v", memories)
+	}
+}
+
+func TestMemoryRetrieverCachesLLMScores(t *testing.T) {
+	dir := t.TempDir()
+	writeReflection(t, dir, "a.md", "BTCUSDT", "2024-01-01T00:00:00Z", "Breakout failed on low volume.")
+	writeReflection(t, dir, "b.md", "BTCUSDT", "2024-01-02T00:00:00Z", "Stop loss too tight in chop.")
+
+	calls := 0
+	complete := func(ctx context.Context, prompt string) (string, error) {
+		calls++
+		if strings.Contains(prompt, "chop") {
+			return "8", nil
+		}
+		return "Relevance: 3", nil
+	}
+
+	r := NewMemoryRetriever(dir, complete, time.Minute)
+	if err := r.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	memories, err := r.RetrieveMemories(context.Background(), "price 1.2345 ranging", 1)
+	if err != nil {
+		t.Fatalf("Failed to retrieve memories: %v", err)
+	}
+	if len(memories) != 1 || memories[0].ID != "b.md" {
+		t.Errorf("Expected the highest scored memory, got %+v", memories)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 LLM calls, got %d", calls)
+	}
+
+	// A near-identical situation in the next cycle should hit the cache
+	_, err = r.RetrieveMemories(context.Background(), "price 1.2346 ranging", 1)
+	if err != nil {
+		t.Fatalf("Failed to retrieve memories: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected cached scores to be reused, got %d LLM calls", calls)
+	}
+}
+
+func TestRetrieveFilteredMemories(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.md": "---\nschemaVersion: 2\nsymbol: BTCUSDT\ntradeContext: {\"symbol\":\"BTCUSDT\",\"side\":\"short\",\"pnl\":-12.5,\"regime\":\"ranging\"}\n---\n\nshort stopped out in chop",
+		"b.md": "---\nschemaVersion: 2\nsymbol: BTCUSDT\ntradeContext: {\"symbol\":\"BTCUSDT\",\"side\":\"long\",\"pnl\":30,\"regime\":\"trending_up\"}\n---\n\nlong rode the trend",
+		"c.md": "---\nschemaVersion: 2\nsymbol: ETHUSDT\ntradeContext: {\"symbol\":\"ETHUSDT\",\"side\":\"short\",\"pnl\":-3,\"regime\":\"ranging\"}\n---\n\nshort stopped out in chop",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write memory file: %v", err)
+		}
+	}
+
+	retriever := NewMemoryRetriever(dir, nil, 0)
+	if err := retriever.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	filter := &MemoryFilter{Symbol: "BTCUSDT", Side: "short", Outcome: OutcomeLoss, Regime: "ranging"}
+	memories, err := retriever.RetrieveFilteredMemories(context.Background(), "short chop", filter, 5)
+	if err != nil {
+		t.Fatalf("Failed to retrieve memories: %v", err)
+	}
+	if len(memories) != 1 || memories[0].ID != "a.md" {
+		t.Fatalf("Expected only memory a, got %+v", memories)
+	}
+	if memories[0].Context.PnL != -12.5 {
+		t.Errorf("Expected parsed trade context, got %+v", memories[0].Context)
+	}
+}
+
+func TestRMultiple(t *testing.T) {
+	if r := RMultiple("long", 100, 110, 95); r != 2 {
+		t.Errorf("Expected 2R for long, got %v", r)
+	}
+	if r := RMultiple("short", 100, 105, 105); r != -1 {
+		t.Errorf("Expected -1R for short, got %v", r)
+	}
+	if r := RMultiple("long", 100, 110, 0); r != 0 {
+		t.Errorf("Expected 0 without stop loss, got %v", r)
+	}
+}
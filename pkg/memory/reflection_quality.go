@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// minReflectionWords is the minimum length of a reflection worth storing
+const minReflectionWords = 60
+
+var reflectionNumberPattern = regexp.MustCompile(`-?\d[\d,]*(?:\.\d+)?`)
+
+// genericPhrases are boilerplate statements that carry no trade-specific insight
+var genericPhrases = []string{
+	"the market is unpredictable",
+	"markets are unpredictable",
+	"past performance is not indicative",
+	"trading involves risk",
+	"always do your own research",
+	"be more careful",
+	"not financial advice",
+}
+
+// actionableMarkers indicate the reflection states a concrete lesson or rule for future trades
+var actionableMarkers = []string{
+	"next time",
+	"in the future",
+	"going forward",
+	"should have",
+	"should ",
+	"avoid",
+	"rule:",
+	"lesson",
+	"wait for",
+	"only enter",
+	"will ",
+}
+
+// ReflectionQuality is the result of validating a generated reflection
+type ReflectionQuality struct {
+	Issues []string
+}
+
+// OK reports whether the reflection passed validation
+func (q *ReflectionQuality) OK() bool {
+	return len(q.Issues) == 0
+}
+
+// Feedback returns targeted feedback for regenerating a rejected reflection
+func (q *ReflectionQuality) Feedback() string {
+	var sb strings.Builder
+	sb.WriteString("Your previous reflection was rejected for the following reasons:\n")
+	for _, issue := range q.Issues {
+		sb.WriteString("- ")
+		sb.WriteString(issue)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Please rewrite the reflection addressing every point above.")
+	return sb.String()
+}
+
+// ValidateReflection runs a lightweight quality pass over a reflection: it must be non-generic,
+// reference concrete numbers from the trade and state an actionable lesson
+func ValidateReflection(text string, tradeNumbers ...float64) *ReflectionQuality {
+	q := &ReflectionQuality{Issues: make([]string, 0)}
+	lower := strings.ToLower(text)
+
+	if words := len(strings.Fields(text)); words < minReflectionWords {
+		q.Issues = append(q.Issues, "it is too short ("+strconv.Itoa(words)+" words), analyze the trade in more depth")
+	}
+
+	for _, phrase := range genericPhrases {
+		if strings.Contains(lower, phrase) {
+			q.Issues = append(q.Issues, "it contains the generic statement \""+phrase+"\", replace it with insight specific to this trade")
+			break
+		}
+	}
+
+	if len(tradeNumbers) > 0 && !referencesNumbers(text, tradeNumbers) {
+		q.Issues = append(q.Issues, "it does not reference concrete numbers from the trade such as the entry price, exit price or profit/loss")
+	}
+
+	actionable := false
+	for _, marker := range actionableMarkers {
+		if strings.Contains(lower, marker) {
+			actionable = true
+			break
+		}
+	}
+	if !actionable {
+		q.Issues = append(q.Issues, "it does not state an actionable lesson for future similar trades")
+	}
+
+	return q
+}
+
+// referencesNumbers reports whether the text mentions any of the numbers within a 0.5% tolerance
+func referencesNumbers(text string, numbers []float64) bool {
+	for _, match := range reflectionNumberPattern.FindAllString(text, -1) {
+		val, err := strconv.ParseFloat(strings.ReplaceAll(match, ",", ""), 64)
+		if err != nil {
+			continue
+		}
+
+		for _, num := range numbers {
+			if num == 0 {
+				continue
+			}
+
+			if math.Abs(math.Abs(val)-math.Abs(num)) <= math.Abs(num)*0.005 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
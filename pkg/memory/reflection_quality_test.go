@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReflection(t *testing.T) {
+	good := "## Entry and Exit\nThe long entry at 43,120.50 came after a breakout retest, but the exit at 42,800 was forced by the stop. " +
+		"The loss of -32.05 was within plan, yet the entry ignored the falling volume during the retest and the 4h resistance overhead. " +
+		"## Lessons\nNext time wait for the retest candle to close above the breakout level with rising volume before entering, " +
+		"and avoid longs directly below higher timeframe resistance. Position size was appropriate for the 1% risk budget and the stop " +
+		"placement under the swing low was sound."
+
+	q := ValidateReflection(good, 43120.5, 42800, -32.05)
+	if !q.OK() {
+		t.Errorf("Expected good reflection to pass, got %v", q.Issues)
+	}
+
+	generic := "The market is unpredictable and trading involves risk. This trade did not work out as expected."
+	q = ValidateReflection(generic, 43120.5, 42800, -32.05)
+	if q.OK() {
+		t.Fatal("Expected generic reflection to be rejected")
+	}
+	if len(q.Issues) != 4 {
+		t.Errorf("Expected 4 issues, got %v", q.Issues)
+	}
+	if !strings.Contains(q.Feedback(), "concrete numbers") {
+		t.Errorf("Expected feedback to mention missing numbers, got %s", q.Feedback())
+	}
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ReinforcementKey is the front matter key holding the profitable minus losing trades a memory was injected into
+const ReinforcementKey = "reinforcement"
+
+// BaseImportanceKey keeps the importance a memory was written with, restored while its reinforcement is between
+// the thresholds
+const BaseImportanceKey = "baseImportance"
+
+// ImportanceLow marks memories that kept preceding losing trades, they are no longer retrieved
+const ImportanceLow = "low"
+
+// ReinforcementPolicy derives the importance of a memory from its reinforcement
+type ReinforcementPolicy struct {
+	PromoteAt int // Reinforcement from which the memory is high importance
+	DemoteAt  int // Reinforcement from which the memory is low importance
+}
+
+// Importance returns the importance of a memory with the reinforcement, base is the importance it was written with
+func (p ReinforcementPolicy) Importance(reinforcement int, base string) string {
+	if p.PromoteAt > 0 && reinforcement >= p.PromoteAt {
+		return ImportanceHigh
+	}
+
+	if p.DemoteAt < 0 && reinforcement <= p.DemoteAt {
+		return ImportanceLow
+	}
+
+	return base
+}
+
+// ReinforceMemoryFile adds delta to the reinforcement of the memory file, updates its importance by the policy
+// and loads it back
+func ReinforceMemoryFile(path string, delta int, policy ReinforcementPolicy) (*Memory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory file: %w", err)
+	}
+
+	doc := ParseDocument(string(data))
+
+	reinforcement := 0
+	if text, ok := doc.Meta[ReinforcementKey]; ok {
+		reinforcement, _ = strconv.Atoi(text)
+	} else if importance := doc.Meta[ImportanceKey]; importance != "" {
+		doc.Set(BaseImportanceKey, importance)
+	}
+
+	reinforcement += delta
+	doc.Set(ReinforcementKey, strconv.Itoa(reinforcement))
+
+	if importance := policy.Importance(reinforcement, doc.Meta[BaseImportanceKey]); importance != "" {
+		doc.Set(ImportanceKey, importance)
+	} else {
+		doc.Delete(ImportanceKey)
+	}
+
+	if err := os.WriteFile(path, []byte(doc.String()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write memory file: %w", err)
+	}
+
+	return LoadMemoryFile(path)
+}
+
+// Reinforce adds delta to the reinforcement of the loaded memory with the ID and updates its importance.
+// User-authored playbooks are never rewritten.
+func (r *MemoryRetriever) Reinforce(id string, delta int, policy ReinforcementPolicy) (*Memory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, mem := range r.memories {
+		if mem.ID != id {
+			continue
+		}
+
+		if mem.Kind == MemoryKindKnowledge {
+			return nil, fmt.Errorf("memory %s is a playbook, playbooks are not reinforced", id)
+		}
+
+		updated, err := ReinforceMemoryFile(mem.Path, delta, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		r.memories[i] = updated
+		return updated, nil
+	}
+
+	return nil, fmt.Errorf("memory %s not found", id)
+}
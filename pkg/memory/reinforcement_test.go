@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReinforcementPolicyImportance(t *testing.T) {
+	policy := ReinforcementPolicy{PromoteAt: 2, DemoteAt: -2}
+
+	cases := []struct {
+		reinforcement int
+		base          string
+		expected      string
+	}{
+		{0, "", ""},
+		{2, "", ImportanceHigh},
+		{-2, "", ImportanceLow},
+		{-2, ImportanceHigh, ImportanceLow},
+		{1, ImportanceHigh, ImportanceHigh},
+	}
+
+	for _, c := range cases {
+		if got := policy.Importance(c.reinforcement, c.base); got != c.expected {
+			t.Errorf("Importance(%d, %q) = %q, expected %q", c.reinforcement, c.base, got, c.expected)
+		}
+	}
+}
+
+func TestMemoryRetrieverReinforce(t *testing.T) {
+	dir := t.TempDir()
+	writeReflection(t, dir, "a.md", "BTCUSDT", "2024-01-01T00:00:00Z", "breakout long stopped out below resistance")
+	writeReflection(t, dir, "b.md", "BTCUSDT", "2024-01-02T00:00:00Z", "breakout long worked after retest")
+
+	retriever := NewMemoryRetriever(dir, nil, 0)
+	if err := retriever.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	policy := ReinforcementPolicy{PromoteAt: 2, DemoteAt: -1}
+	if _, err := retriever.Reinforce("b.md", 1, policy); err != nil {
+		t.Fatalf("Failed to reinforce memory: %v", err)
+	}
+	mem, err := retriever.Reinforce("b.md", 1, policy)
+	if err != nil {
+		t.Fatalf("Failed to reinforce memory: %v", err)
+	}
+	if mem.Reinforcement != 2 || mem.Importance != ImportanceHigh || !strings.Contains(mem.Content, "worked after retest") {
+		t.Errorf("Unexpected reinforced memory: %+v", mem)
+	}
+
+	if _, err := retriever.Reinforce("a.md", -1, policy); err != nil {
+		t.Fatalf("Failed to weaken memory: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "a.md"))
+	if err != nil {
+		t.Fatalf("Failed to read memory file: %v", err)
+	}
+	if !strings.Contains(string(data), "reinforcement: -1") || !strings.Contains(string(data), "importance: low") {
+		t.Errorf("Expected the reinforcement in the front matter, got %s", data)
+	}
+
+	memories, err := retriever.RetrieveMemories(context.Background(), "breakout long", 5)
+	if err != nil {
+		t.Fatalf("Failed to retrieve memories: %v", err)
+	}
+	if len(memories) != 1 || memories[0].ID != "b.md" {
+		t.Errorf("Expected only the reinforced memory to be retrieved, got %+v", memories)
+	}
+
+	if _, err := retriever.Reinforce("missing.md", 1, policy); err == nil {
+		t.Error("Expected an error for an unknown memory")
+	}
+}
+
+func TestReinforceKeepsBaseImportance(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "review.md")
+	if err := os.WriteFile(path, []byte("---\nsymbol: BTCUSDT\nimportance: high\n---\n\nreview memo"), 0644); err != nil {
+		t.Fatalf("Failed to write memory: %v", err)
+	}
+
+	policy := ReinforcementPolicy{PromoteAt: 2, DemoteAt: -2}
+	mem, err := ReinforceMemoryFile(path, -2, policy)
+	if err != nil {
+		t.Fatalf("Failed to reinforce memory: %v", err)
+	}
+	if mem.Importance != ImportanceLow || mem.Meta[BaseImportanceKey] != ImportanceHigh {
+		t.Errorf("Expected a demoted memory keeping its base importance, got %+v", mem)
+	}
+
+	mem, err = ReinforceMemoryFile(path, 1, policy)
+	if err != nil {
+		t.Fatalf("Failed to reinforce memory: %v", err)
+	}
+	if mem.Importance != ImportanceHigh || mem.Reinforcement != -1 {
+		t.Errorf("Expected the base importance restored, got %+v", mem)
+	}
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// numberPattern matches integer and decimal numbers in a situation string
+var numberPattern = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+// SituationKey hashes a situation string after normalizing it, so that identical or
+// near-identical situations (e.g. prices that only moved in insignificant digits) share a key
+func SituationKey(situation string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(situation), " "))
+
+	// Round numbers to 3 significant digits so tiny ticks in a quiet market don't bust the cache
+	normalized = numberPattern.ReplaceAllStringFunc(normalized, func(num string) string {
+		val, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return num
+		}
+
+		return strconv.FormatFloat(val, 'g', 3, 64)
+	})
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+type relevanceCacheEntry struct {
+	score     float64
+	expiresAt time.Time
+}
+
+// RelevanceCache caches memory relevance scores per situation within a TTL
+type RelevanceCache struct {
+	ttl     time.Duration
+	entries map[string]relevanceCacheEntry
+	mu      sync.Mutex
+	now     func() time.Time
+}
+
+// NewRelevanceCache creates a relevance cache, a non-positive ttl disables caching
+func NewRelevanceCache(ttl time.Duration) *RelevanceCache {
+	return &RelevanceCache{
+		ttl:     ttl,
+		entries: make(map[string]relevanceCacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (c *RelevanceCache) key(situationKey string, memoryID string) string {
+	return situationKey + ":" + memoryID
+}
+
+// Get returns the cached score for the memory under the situation key if it is still fresh
+func (c *RelevanceCache) Get(situationKey string, memoryID string) (float64, bool) {
+	if c.ttl <= 0 {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[c.key(situationKey, memoryID)]
+	if !ok {
+		return 0, false
+	}
+
+	if c.now().After(entry.expiresAt) {
+		delete(c.entries, c.key(situationKey, memoryID))
+		return 0, false
+	}
+
+	return entry.score, true
+}
+
+// Set stores the score for the memory under the situation key
+func (c *RelevanceCache) Set(situationKey string, memoryID string, score float64) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+
+	// Drop expired entries so the cache doesn't grow without bound
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[c.key(situationKey, memoryID)] = relevanceCacheEntry{
+		score:     score,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// Len returns the number of cached scores
+func (c *RelevanceCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
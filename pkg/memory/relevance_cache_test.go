@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSituationKeyNormalization(t *testing.T) {
+	a := SituationKey("Current close price: 1.23456\nRSI  is 70.01")
+	b := SituationKey("current close price: 1.23499 rsi is 70.04")
+	if a != b {
+		t.Errorf("Expected similar situations to share a key")
+	}
+
+	c := SituationKey("current close price: 1.31 rsi is 70.04")
+	if a == c {
+		t.Errorf("Expected different situations to have different keys")
+	}
+}
+
+func TestRelevanceCacheTTL(t *testing.T) {
+	now := time.Now()
+	cache := NewRelevanceCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("situation", "mem1", 0.8)
+
+	score, ok := cache.Get("situation", "mem1")
+	if !ok || score != 0.8 {
+		t.Errorf("Expected cached score 0.8, got %v (ok=%v)", score, ok)
+	}
+
+	if _, ok := cache.Get("other", "mem1"); ok {
+		t.Error("Expected cache miss for other situation")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get("situation", "mem1"); ok {
+		t.Error("Expected cache miss after TTL expired")
+	}
+}
+
+func TestRelevanceCacheDisabled(t *testing.T) {
+	cache := NewRelevanceCache(0)
+	cache.Set("situation", "mem1", 0.8)
+
+	if _, ok := cache.Get("situation", "mem1"); ok {
+		t.Error("Expected cache to be disabled with zero TTL")
+	}
+}
@@ -0,0 +1,254 @@
+package memory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CurrentSchemaVersion is the schema version written to new memory files
+const CurrentSchemaVersion = 2
+
+// SchemaVersionKey is the front matter key holding the schema version of a memory file
+const SchemaVersionKey = "schemaVersion"
+
+// headerPattern matches the version header of plain memory files, e.g. "<!-- schemaVersion: 1 -->"
+var headerPattern = regexp.MustCompile(`^<!--\s*schemaVersion:\s*(\d+)\s*-->\s*\n?`)
+
+// Document is a versioned memory file: either markdown with "---" front matter,
+// or a plain markdown file with a version header comment
+type Document struct {
+	Version int
+	Keys    []string // Front matter keys in file order
+	Meta    map[string]string
+	Body    string
+	plain   bool
+}
+
+// ParseDocument parses a markdown memory file with front matter, such as a trade reflection
+func ParseDocument(text string) *Document {
+	doc := &Document{
+		Keys: make([]string, 0),
+		Meta: make(map[string]string),
+		Body: text,
+	}
+
+	if !strings.HasPrefix(text, "---") {
+		return doc
+	}
+
+	rest := strings.TrimPrefix(text, "---")
+	end := strings.Index(rest, "\n---")
+	if end == -1 {
+		return doc
+	}
+
+	for _, line := range strings.Split(rest[:end], "\n") {
+		idx := strings.Index(line, ":")
+		if idx == -1 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:idx])
+		if key == "" {
+			continue
+		}
+
+		if key == SchemaVersionKey {
+			doc.Version, _ = strconv.Atoi(strings.TrimSpace(line[idx+1:]))
+			continue
+		}
+
+		doc.Set(key, strings.TrimSpace(line[idx+1:]))
+	}
+
+	doc.Body = rest[end+len("\n---"):]
+	return doc
+}
+
+// ParsePlainDocument parses a free-form memory file with an optional version header comment
+func ParsePlainDocument(text string) *Document {
+	doc := &Document{
+		Keys:  make([]string, 0),
+		Meta:  make(map[string]string),
+		Body:  text,
+		plain: true,
+	}
+
+	match := headerPattern.FindStringSubmatch(text)
+	if match != nil {
+		doc.Version, _ = strconv.Atoi(match[1])
+		doc.Body = text[len(match[0]):]
+	}
+
+	return doc
+}
+
+// Set sets a front matter field, keeping the original key order
+func (d *Document) Set(key string, value string) {
+	if _, ok := d.Meta[key]; !ok {
+		d.Keys = append(d.Keys, key)
+	}
+
+	d.Meta[key] = value
+}
+
+// Delete removes a front matter field
+func (d *Document) Delete(key string) {
+	if _, ok := d.Meta[key]; !ok {
+		return
+	}
+
+	delete(d.Meta, key)
+
+	for i, k := range d.Keys {
+		if k == key {
+			d.Keys = append(d.Keys[:i], d.Keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// String renders the document with its schema version
+func (d *Document) String() string {
+	var builder strings.Builder
+
+	if d.plain {
+		builder.WriteString(fmt.Sprintf("<!-- %s: %d -->\n", SchemaVersionKey, d.Version))
+		builder.WriteString(d.Body)
+		return builder.String()
+	}
+
+	builder.WriteString("---\n")
+	builder.WriteString(fmt.Sprintf("%s: %d\n", SchemaVersionKey, d.Version))
+	for _, key := range d.Keys {
+		builder.WriteString(fmt.Sprintf("%s: %s\n", key, d.Meta[key]))
+	}
+	builder.WriteString("---")
+	builder.WriteString(d.Body)
+
+	return builder.String()
+}
+
+// Migration upgrades a memory document from schema version From to From+1
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc *Document) error
+}
+
+// migrations are applied in order to bring documents up to CurrentSchemaVersion
+var migrations = []Migration{
+	{
+		From:        0,
+		Description: "add schema version header",
+		Apply: func(doc *Document) error {
+			return nil
+		},
+	},
+	{
+		From:        1,
+		Description: "attach structured trade context",
+		Apply: func(doc *Document) error {
+			if _, ok := doc.Meta[TradeContextKey]; !ok && doc.Meta["symbol"] != "" {
+				doc.Set(TradeContextKey, legacyTradeContext(doc).JSON())
+			}
+			return nil
+		},
+	},
+}
+
+// RegisterMigration adds a migration to the framework, used when the memory schema evolves
+func RegisterMigration(m Migration) {
+	migrations = append(migrations, m)
+}
+
+// Migrate upgrades the document to the target version by applying migrations in order
+func Migrate(doc *Document, target int) error {
+	for doc.Version < target {
+		applied := false
+
+		for _, m := range migrations {
+			if m.From != doc.Version {
+				continue
+			}
+
+			if err := m.Apply(doc); err != nil {
+				return fmt.Errorf("migration from v%d (%s) failed: %w", m.From, m.Description, err)
+			}
+
+			doc.Version = m.From + 1
+			applied = true
+			break
+		}
+
+		if !applied {
+			return fmt.Errorf("no migration registered from schema v%d", doc.Version)
+		}
+	}
+
+	return nil
+}
+
+// MigrateFile upgrades a memory file in place to CurrentSchemaVersion, keeping a backup of
+// the original next to it as "<name>.v<version>.bak". Returns whether the file was migrated.
+func MigrateFile(path string, plain bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read memory file: %w", err)
+	}
+
+	var doc *Document
+	if plain {
+		doc = ParsePlainDocument(string(data))
+	} else {
+		doc = ParseDocument(string(data))
+	}
+
+	if doc.Version >= CurrentSchemaVersion {
+		return false, nil
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, doc.Version)
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return false, fmt.Errorf("failed to back up memory file: %w", err)
+	}
+
+	if err := Migrate(doc, CurrentSchemaVersion); err != nil {
+		return false, fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, []byte(doc.String()), 0644); err != nil {
+		return false, fmt.Errorf("failed to write migrated memory file: %w", err)
+	}
+
+	return true, nil
+}
+
+// MigrateDir upgrades all front matter memory files in dir. Returns the number of migrated files.
+func MigrateDir(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list memory files: %w", err)
+	}
+
+	count := 0
+	for _, file := range files {
+		migrated, err := MigrateFile(file, false)
+		if err != nil {
+			return count, err
+		}
+
+		if migrated {
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	text := "---\nsymbol: BTCUSDT\ntimestamp: 2024-01-01T00:00:00Z\n---\n\n# Trade Reflection"
+	doc := ParseDocument(text)
+
+	if doc.Version != 0 {
+		t.Errorf("Expected version 0 for legacy document, got %d", doc.Version)
+	}
+	if doc.Meta["symbol"] != "BTCUSDT" {
+		t.Errorf("Expected symbol BTCUSDT, got %s", doc.Meta["symbol"])
+	}
+
+	doc.Version = 1
+	rendered := doc.String()
+	expected := "---\nschemaVersion: 1\nsymbol: BTCUSDT\ntimestamp: 2024-01-01T00:00:00Z\n---\n\n# Trade Reflection"
+	if rendered != expected {
+		t.Errorf("Unexpected rendered document:\n%s", rendered)
+	}
+
+	reparsed := ParseDocument(rendered)
+	if reparsed.Version != 1 || len(reparsed.Keys) != 2 {
+		t.Errorf("Expected version and keys to survive round trip, got %+v", reparsed)
+	}
+}
+
+func TestMigrateFileWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "trade.md")
+	original := "---\nsymbol: BTCUSDT\n---\n\nbody"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write memory file: %v", err)
+	}
+
+	count, err := MigrateDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 migrated file, got %d", count)
+	}
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	if err != nil || string(backup) != original {
+		t.Errorf("Expected backup with original content, got %q (%v)", backup, err)
+	}
+
+	mem, err := LoadMemoryFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load migrated file: %v", err)
+	}
+	if mem.Version != CurrentSchemaVersion || mem.Symbol != "BTCUSDT" || mem.Content != "body" {
+		t.Errorf("Unexpected migrated memory: %+v", mem)
+	}
+
+	// Migrating again is a no-op
+	count, err = MigrateDir(dir)
+	if err != nil || count != 0 {
+		t.Errorf("Expected no migration for up-to-date files, got %d (%v)", count, err)
+	}
+}
+
+func TestMigrateRunsRegisteredMigrations(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+
+	RegisterMigration(Migration{
+		From:        CurrentSchemaVersion,
+		Description: "rename strategy id",
+		Apply: func(doc *Document) error {
+			doc.Set("strategy", doc.Meta["strategyId"])
+			doc.Delete("strategyId")
+			return nil
+		},
+	})
+
+	doc := ParseDocument("---\nstrategyId: jarvis\n---\nbody")
+	if err := Migrate(doc, CurrentSchemaVersion+1); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if doc.Version != CurrentSchemaVersion+1 || doc.Meta["strategy"] != "jarvis" {
+		t.Errorf("Unexpected migrated document: %+v", doc)
+	}
+	if strings.Contains(doc.String(), "strategyId") {
+		t.Errorf("Expected strategyId to be removed, got %s", doc.String())
+	}
+
+	if err := Migrate(doc, CurrentSchemaVersion+2); err == nil {
+		t.Error("Expected error when no migration is registered")
+	}
+}
+
+func TestMemoryManagerVersionHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.md")
+	if err := os.WriteFile(path, []byte("legacy memory"), 0644); err != nil {
+		t.Fatalf("Failed to write memory file: %v", err)
+	}
+
+	mm := NewMemoryManager(path, 100)
+	migrated, err := mm.Migrate()
+	if err != nil || !migrated {
+		t.Fatalf("Expected legacy memory file to be migrated, got %v (%v)", migrated, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "<!-- schemaVersion: 2 -->\n") {
+		t.Errorf("Expected version header, got %q", data)
+	}
+
+	content, err := mm.LoadMemory()
+	if err != nil || content != "legacy memory" {
+		t.Errorf("Expected header to be stripped on load, got %q (%v)", content, err)
+	}
+}
+
+func TestMigrateAttachesTradeContext(t *testing.T) {
+	doc := ParseDocument("---\nschemaVersion: 1\nsymbol: BTCUSDT\nentryPrice: 100\nexitPrice: 90\nprofitAndLoss: -10\n---\nbody")
+	if err := Migrate(doc, CurrentSchemaVersion); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	c, err := ParseTradeContext(doc.Meta[TradeContextKey])
+	if err != nil {
+		t.Fatalf("Expected trade context, got %q (%v)", doc.Meta[TradeContextKey], err)
+	}
+	if c.Symbol != "BTCUSDT" || c.EntryPrice != 100 || c.ExitPrice != 90 || c.Outcome() != OutcomeLoss {
+		t.Errorf("Unexpected trade context: %+v", c)
+	}
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yubing744/trading-gpt/pkg/prompt"
+	"github.com/yubing744/trading-gpt/pkg/utils/xtemplate"
+)
+
+// Memory kinds
+const (
+	MemoryKindEpisodic = "episodic" // A specific past trade, such as a trade reflection
+	MemoryKindSemantic = "semantic" // A general rule distilled from episodic memories by consolidation
+)
+
+// MemoryKindKey is the front matter key holding the memory kind, memories without it are episodic
+const MemoryKindKey = "kind"
+
+// ImportanceKey is the front matter key marking memories that are prioritized in retrieval
+const ImportanceKey = "importance"
+
+// ImportanceHigh marks memories that take the first retrieval slots of their kind
+const ImportanceHigh = "high"
+
+// RetentionPolicy limits which memories of a kind remain retrievable, zero values disable a limit
+type RetentionPolicy struct {
+	MaxAge   time.Duration // Memories older than this are no longer retrieved
+	MaxCount int           // Only the newest memories up to this count are retrieved
+}
+
+// Apply returns the memories retained by the policy, newest first
+func (p RetentionPolicy) Apply(memories []*Memory, now time.Time) []*Memory {
+	rets := make([]*Memory, 0, len(memories))
+	for _, mem := range memories {
+		if p.MaxAge > 0 && now.Sub(mem.Timestamp) > p.MaxAge {
+			continue
+		}
+		rets = append(rets, mem)
+	}
+
+	sort.SliceStable(rets, func(i, j int) bool {
+		return rets[i].Timestamp.After(rets[j].Timestamp)
+	})
+
+	if p.MaxCount > 0 && len(rets) > p.MaxCount {
+		rets = rets[:p.MaxCount]
+	}
+
+	return rets
+}
+
+// ConsolidateMemories asks the LLM to distill general trading rules from episodic memories
+func ConsolidateMemories(ctx context.Context, complete CompletionFunc, symbol string, episodes []*Memory) (string, error) {
+	summaries := make([]string, 0, len(episodes))
+	for _, mem := range episodes {
+		summaries = append(summaries, mem.Summary(200))
+	}
+
+	promptText, err := xtemplate.Render(prompt.MemoryConsolidationTpl, map[string]interface{}{
+		"Symbol":   symbol,
+		"Episodes": summaries,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render memory consolidation prompt: %w", err)
+	}
+
+	rules, err := complete(ctx, promptText)
+	if err != nil {
+		return "", fmt.Errorf("failed to consolidate memories: %w", err)
+	}
+
+	return strings.TrimSpace(rules), nil
+}
+
+// SaveSemanticMemory writes distilled rules as a semantic memory file in dir
+func SaveSemanticMemory(dir string, symbol string, rules string, sources int, now time.Time) (*Memory, error) {
+	doc := ParseDocument("\n\n# Trading Rules: " + symbol + "\n\n" + rules + "\n")
+	doc.Set(MemoryKindKey, MemoryKindSemantic)
+	doc.Set("symbol", symbol)
+	doc.Set("sources", fmt.Sprintf("%d", sources))
+	doc.Set("timestamp", now.Format(time.RFC3339))
+
+	return saveDocument(doc, filepath.Join(dir, fmt.Sprintf("rules_%s_%d.md", symbol, now.Unix())))
+}
+
+// SaveReviewMemo writes a strategy review memo as a high-importance semantic memory file in dir
+func SaveReviewMemo(dir string, symbol string, memo string, now time.Time) (*Memory, error) {
+	doc := ParseDocument("\n\n# Strategy Review: " + symbol + " (" + now.Format("2006-01-02") + ")\n\n" + memo + "\n")
+	doc.Set(MemoryKindKey, MemoryKindSemantic)
+	doc.Set(ImportanceKey, ImportanceHigh)
+	doc.Set("symbol", symbol)
+	doc.Set("timestamp", now.Format(time.RFC3339))
+
+	return saveDocument(doc, filepath.Join(dir, fmt.Sprintf("review_%s_%d.md", symbol, now.Unix())))
+}
+
+// saveDocument writes a new memory document at the current schema version and loads it back
+func saveDocument(doc *Document, path string) (*Memory, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create memory directory: %w", err)
+	}
+
+	doc.Version = CurrentSchemaVersion
+	if err := os.WriteFile(path, []byte(doc.String()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write memory file: %w", err)
+	}
+
+	return LoadMemoryFile(path)
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsolidateAndRetrieveByKind(t *testing.T) {
+	dir := t.TempDir()
+	writeReflection(t, dir, "a.md", "BTCUSDT", "2024-01-01T00:00:00Z", "breakout long stopped out below resistance")
+	writeReflection(t, dir, "b.md", "BTCUSDT", "2024-01-02T00:00:00Z", "breakout long worked after retest")
+
+	retriever := NewMemoryRetriever(dir, nil, 0)
+	if err := retriever.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	var gotPrompt string
+	complete := func(ctx context.Context, prompt string) (string, error) {
+		gotPrompt = prompt
+		return "- Wait for a breakout retest before entering", nil
+	}
+
+	rules, err := ConsolidateMemories(context.Background(), complete, "BTCUSDT", retriever.GetMemories())
+	if err != nil {
+		t.Fatalf("Failed to consolidate: %v", err)
+	}
+	if !strings.Contains(gotPrompt, "breakout long worked after retest") {
+		t.Errorf("Expected episodes in consolidation prompt, got %s", gotPrompt)
+	}
+
+	mem, err := SaveSemanticMemory(dir, "BTCUSDT", rules, 2, time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to save semantic memory: %v", err)
+	}
+	if mem.Kind != MemoryKindSemantic || mem.Version != CurrentSchemaVersion || !strings.Contains(mem.Content, "breakout retest") {
+		t.Errorf("Unexpected semantic memory: %+v", mem)
+	}
+	retriever.Add(mem)
+
+	episodic, _ := retriever.RetrieveFilteredMemories(context.Background(), "breakout", &MemoryFilter{Kind: MemoryKindEpisodic, Symbol: "BTCUSDT"}, 5)
+	semantic, _ := retriever.RetrieveFilteredMemories(context.Background(), "breakout", &MemoryFilter{Kind: MemoryKindSemantic, Symbol: "BTCUSDT"}, 5)
+	if len(episodic) != 2 || len(semantic) != 1 {
+		t.Errorf("Expected 2 episodic and 1 semantic memories, got %d and %d", len(episodic), len(semantic))
+	}
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	memories := []*Memory{
+		{ID: "old", Timestamp: now.Add(-100 * 24 * time.Hour)},
+		{ID: "mid", Timestamp: now.Add(-10 * 24 * time.Hour)},
+		{ID: "new", Timestamp: now.Add(-24 * time.Hour)},
+	}
+
+	kept := RetentionPolicy{MaxAge: 90 * 24 * time.Hour}.Apply(memories, now)
+	if len(kept) != 2 || kept[0].ID != "new" {
+		t.Errorf("Expected old memory to be dropped, got %d memories", len(kept))
+	}
+
+	kept = RetentionPolicy{MaxCount: 1}.Apply(memories, now)
+	if len(kept) != 1 || kept[0].ID != "new" {
+		t.Errorf("Expected only the newest memory, got %d memories", len(kept))
+	}
+}
+
+func TestReviewMemoTakesFirstSlot(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	if _, err := SaveSemanticMemory(dir, "BTCUSDT", "- Wait for a breakout retest", 3, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed to save rules: %v", err)
+	}
+	if _, err := SaveReviewMemo(dir, "BTCUSDT", "Cut position size during low volume weekends", now); err != nil {
+		t.Fatalf("Failed to save review memo: %v", err)
+	}
+
+	retriever := NewMemoryRetriever(dir, nil, 0)
+	if err := retriever.Load(); err != nil {
+		t.Fatalf("Failed to load memories: %v", err)
+	}
+
+	filter := &MemoryFilter{Kind: MemoryKindSemantic, Symbol: "BTCUSDT"}
+	found, err := retriever.RetrieveFilteredMemories(context.Background(), "breakout retest", filter, 2)
+	if err != nil {
+		t.Fatalf("Failed to retrieve memories: %v", err)
+	}
+	if len(found) != 2 || found[0].Importance != ImportanceHigh || !strings.HasPrefix(found[1].ID, "rules_") {
+		t.Errorf("Expected review memo before rules, got %+v", found)
+	}
+}
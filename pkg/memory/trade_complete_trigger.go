@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TradeCompleteTrigger decides whether a closed trade is significant enough to reflect on,
+// keeping LLM cost bounded and memories focused on significant trades
+type TradeCompleteTrigger struct {
+	onlyLosses bool
+	minAbsPnL  float64
+	maxPerDay  int
+	cooldown   time.Duration
+
+	fired []time.Time
+	mu    sync.Mutex
+	now   func() time.Time
+}
+
+// NewTradeCompleteTrigger creates a trigger, zero values disable the corresponding filter
+func NewTradeCompleteTrigger(onlyLosses bool, minAbsPnL float64, maxPerDay int, cooldown time.Duration) *TradeCompleteTrigger {
+	return &TradeCompleteTrigger{
+		onlyLosses: onlyLosses,
+		minAbsPnL:  minAbsPnL,
+		maxPerDay:  maxPerDay,
+		cooldown:   cooldown,
+		fired:      make([]time.Time, 0),
+		now:        time.Now,
+	}
+}
+
+// ShouldFire reports whether a reflection should be generated for a trade with the given PnL,
+// recording the firing when it does. The reason explains why the trade was skipped.
+func (t *TradeCompleteTrigger) ShouldFire(pnl float64) (bool, string) {
+	if t.onlyLosses && pnl >= 0 {
+		return false, "only losing trades are reflected on"
+	}
+
+	if t.minAbsPnL > 0 && pnl < t.minAbsPnL && pnl > -t.minAbsPnL {
+		return false, fmt.Sprintf("|PnL| %.2f is below the %.2f threshold", pnl, t.minAbsPnL)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+
+	// Keep only the firings within the last day
+	recent := make([]time.Time, 0, len(t.fired))
+	for _, ts := range t.fired {
+		if now.Sub(ts) < 24*time.Hour {
+			recent = append(recent, ts)
+		}
+	}
+	t.fired = recent
+
+	if t.cooldown > 0 && len(t.fired) > 0 && now.Sub(t.fired[len(t.fired)-1]) < t.cooldown {
+		return false, fmt.Sprintf("cooldown of %s since the last reflection has not elapsed", t.cooldown)
+	}
+
+	if t.maxPerDay > 0 && len(t.fired) >= t.maxPerDay {
+		return false, fmt.Sprintf("the limit of %d reflections per day has been reached", t.maxPerDay)
+	}
+
+	t.fired = append(t.fired, now)
+	return true, ""
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTradeCompleteTriggerFilters(t *testing.T) {
+	trigger := NewTradeCompleteTrigger(true, 10, 0, 0)
+
+	if ok, _ := trigger.ShouldFire(25); ok {
+		t.Error("Expected winning trade to be skipped")
+	}
+	if ok, _ := trigger.ShouldFire(-5); ok {
+		t.Error("Expected small loss to be skipped")
+	}
+	if ok, reason := trigger.ShouldFire(-15); !ok {
+		t.Errorf("Expected significant loss to fire, got %s", reason)
+	}
+}
+
+func TestTradeCompleteTriggerRateLimit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	trigger := NewTradeCompleteTrigger(false, 0, 2, time.Hour)
+	trigger.now = func() time.Time { return now }
+
+	if ok, _ := trigger.ShouldFire(1); !ok {
+		t.Fatal("Expected first trade to fire")
+	}
+
+	now = now.Add(30 * time.Minute)
+	if ok, _ := trigger.ShouldFire(1); ok {
+		t.Error("Expected trade within cooldown to be skipped")
+	}
+
+	now = now.Add(time.Hour)
+	if ok, _ := trigger.ShouldFire(1); !ok {
+		t.Error("Expected trade after cooldown to fire")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if ok, _ := trigger.ShouldFire(1); ok {
+		t.Error("Expected daily limit to skip trade")
+	}
+
+	now = now.Add(24 * time.Hour)
+	if ok, _ := trigger.ShouldFire(1); !ok {
+		t.Error("Expected trade on the next day to fire")
+	}
+}
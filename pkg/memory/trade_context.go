@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// TradeContextKey is the front matter key holding the structured trade context of a memory
+const TradeContextKey = "tradeContext"
+
+// Trade outcomes
+const (
+	OutcomeWin  = "win"
+	OutcomeLoss = "loss"
+)
+
+// TradeContext is structured trade information attached to a memory by the reflection pipeline
+type TradeContext struct {
+	Symbol     string  `json:"symbol,omitempty"`
+	Side       string  `json:"side,omitempty"`
+	EntryPrice float64 `json:"entry_price,omitempty"`
+	ExitPrice  float64 `json:"exit_price,omitempty"`
+	PnL        float64 `json:"pnl"`
+	RMultiple  float64 `json:"r_multiple,omitempty"`
+	Regime     string  `json:"regime,omitempty"`
+	DecisionID string  `json:"decision_id,omitempty"`
+}
+
+// Outcome returns whether the trade was a win or a loss
+func (c *TradeContext) Outcome() string {
+	if c.PnL >= 0 {
+		return OutcomeWin
+	}
+
+	return OutcomeLoss
+}
+
+// JSON returns the trade context as a single-line JSON string for front matter
+func (c *TradeContext) JSON() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(data)
+}
+
+// ParseTradeContext parses a trade context from its front matter JSON
+func ParseTradeContext(text string) (*TradeContext, error) {
+	var c TradeContext
+	if err := json.Unmarshal([]byte(text), &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// RMultiple returns the trade result in units of initial risk, or 0 if no stop loss was set
+func RMultiple(side string, entryPrice float64, exitPrice float64, stopLossPrice float64) float64 {
+	if stopLossPrice <= 0 || entryPrice <= 0 {
+		return 0
+	}
+
+	risk := entryPrice - stopLossPrice
+	reward := exitPrice - entryPrice
+	if side == "short" {
+		risk = stopLossPrice - entryPrice
+		reward = entryPrice - exitPrice
+	}
+
+	if risk <= 0 {
+		return 0
+	}
+
+	return reward / risk
+}
+
+// MemoryFilter restricts retrieval to memories whose trade context matches all non-empty fields
+type MemoryFilter struct {
+	Kind    string
+	Symbol  string
+	Side    string
+	Outcome string
+	Regime  string
+}
+
+// Match reports whether the memory satisfies the filter
+func (f *MemoryFilter) Match(mem *Memory) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.Kind != "" && f.Kind != mem.Kind {
+		return false
+	}
+
+	c := mem.Context
+	if c == nil {
+		// Memories without trade context only match an empty filter, except on symbol
+		if f.Side != "" || f.Outcome != "" || f.Regime != "" {
+			return false
+		}
+
+		// Memories without a symbol, such as generic playbooks, apply to every symbol
+		return f.Symbol == "" || mem.Symbol == "" || f.Symbol == mem.Symbol
+	}
+
+	if f.Symbol != "" && f.Symbol != c.Symbol {
+		return false
+	}
+
+	if f.Side != "" && f.Side != c.Side {
+		return false
+	}
+
+	if f.Outcome != "" && f.Outcome != c.Outcome() {
+		return false
+	}
+
+	if f.Regime != "" && f.Regime != c.Regime {
+		return false
+	}
+
+	return true
+}
+
+// legacyTradeContext builds a trade context from the flat front matter fields of v1 reflections
+func legacyTradeContext(doc *Document) *TradeContext {
+	parseFloat := func(key string) float64 {
+		val, _ := strconv.ParseFloat(doc.Meta[key], 64)
+		return val
+	}
+
+	return &TradeContext{
+		Symbol:     doc.Meta["symbol"],
+		EntryPrice: parseFloat("entryPrice"),
+		ExitPrice:  parseFloat("exitPrice"),
+		PnL:        parseFloat("profitAndLoss"),
+	}
+}
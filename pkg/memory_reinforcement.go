@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/yubing744/trading-gpt/pkg/journal"
+	"github.com/yubing744/trading-gpt/pkg/memory"
+)
+
+// injectedMemoriesKey carries the IDs of the memories injected into the prompt of a decision cycle
+type injectedMemoriesKey struct{}
+
+func withInjectedMemories(ctx context.Context, memories []*memory.Memory) context.Context {
+	if len(memories) == 0 {
+		return ctx
+	}
+
+	ids := make([]string, 0, len(memories))
+	for _, mem := range memories {
+		ids = append(ids, mem.ID)
+	}
+
+	return context.WithValue(ctx, injectedMemoriesKey{}, ids)
+}
+
+// injectedMemories returns the IDs of the memories injected into the prompt of the decision cycle
+func injectedMemories(ctx context.Context) []string {
+	ids, _ := ctx.Value(injectedMemoriesKey{}).([]string)
+	return ids
+}
+
+// reinforceMemories bumps the memories injected into the decision that opened a trade when the trade won, and
+// decrements them when it lost, so their importance follows the outcomes
+func (s *Strategy) reinforceMemories(decisionID string, pnl float64) {
+	if !s.Memory.Reinforcement || s.memoryRetriever == nil || s.journal == nil || decisionID == "" {
+		return
+	}
+
+	entries, err := s.journal.LoadEntries()
+	if err != nil {
+		log.WithError(err).Warn("Failed to load journal for memory reinforcement")
+		return
+	}
+
+	var decision *journal.Entry
+	for _, entry := range entries {
+		if entry.ID == decisionID && entry.Kind == journal.KindDecision {
+			decision = entry
+		}
+	}
+
+	if decision == nil || len(decision.Memories) == 0 {
+		return
+	}
+
+	delta := 1
+	if pnl < 0 {
+		delta = -1
+	}
+
+	policy := memory.ReinforcementPolicy{PromoteAt: s.Memory.PromoteAt, DemoteAt: s.Memory.DemoteAt}
+	for _, id := range decision.Memories {
+		mem, err := s.memoryRetriever.Reinforce(id, delta, policy)
+		if err != nil {
+			log.WithError(err).WithField("memory", id).Warn("Failed to reinforce memory")
+			continue
+		}
+
+		log.WithField("memory", id).WithField("reinforcement", mem.Reinforcement).WithField("importance", mem.Importance).
+			Info("Memory reinforced by trade outcome")
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+var (
+	actionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "trading_gpt_actions_total",
+			Help: "Executed env commands by action, result and error category",
+		},
+		[]string{"action", "result", "error_category"},
+	)
+
+	actionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "trading_gpt_action_duration_seconds",
+			Help:    "Execution latency of env commands by action and result",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"action", "result"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(actionsTotal, actionDuration)
+}
+
+// RecordAction records the latency and outcome of an executed command, and returns the category of its error
+func RecordAction(action string, duration time.Duration, err error) string {
+	result := "ok"
+	category := utils.CategorizeExecError(err)
+	if err != nil {
+		result = "failed"
+	}
+
+	actionsTotal.WithLabelValues(action, result, category).Inc()
+	actionDuration.WithLabelValues(action, result).Observe(duration.Seconds())
+
+	return category
+}
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+var (
+	llmResponsesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "trading_gpt_llm_responses_total",
+			Help: "LLM decision responses by provider, response format path (native, tool or repair) and parse result",
+		},
+		[]string{"provider", "format", "result"},
+	)
+
+	llmParseFailureRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "trading_gpt_llm_parse_failure_ratio",
+			Help: "Share of LLM decision responses that failed to parse, by provider and response format path",
+		},
+		[]string{"provider", "format"},
+	)
+
+	llmParseRepairsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "trading_gpt_llm_parse_repairs_total",
+			Help: "Parsed LLM decision responses by provider and the JSON repair level that made them parse",
+		},
+		[]string{"provider", "level"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(llmResponsesTotal, llmParseFailureRatio, llmParseRepairsTotal)
+}
+
+type parseCount struct {
+	total  int
+	failed int
+}
+
+var (
+	parseCounts   = make(map[[2]string]*parseCount)
+	parseCountsMu sync.Mutex
+)
+
+// RecordResponseParse records whether a decision response of the provider parsed, by the response format path used,
+// and returns the parse failure ratio of the provider and path so far
+func RecordResponseParse(provider string, format string, parsed bool) float64 {
+	result := "ok"
+	if !parsed {
+		result = "failed"
+	}
+	llmResponsesTotal.WithLabelValues(provider, format, result).Inc()
+
+	parseCountsMu.Lock()
+	defer parseCountsMu.Unlock()
+
+	key := [2]string{provider, format}
+	count, ok := parseCounts[key]
+	if !ok {
+		count = &parseCount{}
+		parseCounts[key] = count
+	}
+
+	count.total++
+	if !parsed {
+		count.failed++
+	}
+
+	ratio := float64(count.failed) / float64(count.total)
+	llmParseFailureRatio.WithLabelValues(provider, format).Set(ratio)
+
+	return ratio
+}
+
+var (
+	repairCounts   = make(map[string]map[string]int)
+	repairCountsMu sync.Mutex
+)
+
+// RecordParseRepair records the JSON repair level that made a decision response of the provider parse
+func RecordParseRepair(provider string, level string) {
+	llmParseRepairsTotal.WithLabelValues(provider, level).Inc()
+
+	repairCountsMu.Lock()
+	defer repairCountsMu.Unlock()
+
+	levels, ok := repairCounts[provider]
+	if !ok {
+		levels = make(map[string]int)
+		repairCounts[provider] = levels
+	}
+	levels[level]++
+}
+
+// TakeParseRepairSummary describes the repair levels recorded per provider since the last call and resets them,
+// e.g. "gpt-4o: 40 parsed, 38 none, 2 syntax (5% repaired)", empty when nothing was recorded
+func TakeParseRepairSummary() string {
+	repairCountsMu.Lock()
+	counts := repairCounts
+	repairCounts = make(map[string]map[string]int)
+	repairCountsMu.Unlock()
+
+	providers := make([]string, 0, len(counts))
+	for provider := range counts {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	lines := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		levels := make([]string, 0, len(counts[provider]))
+		for level := range counts[provider] {
+			levels = append(levels, level)
+		}
+		sort.Strings(levels)
+
+		total, repaired := 0, 0
+		parts := make([]string, 0, len(levels))
+		for _, level := range levels {
+			count := counts[provider][level]
+			total += count
+			if level != utils.RepairLevelNone {
+				repaired += count
+			}
+			parts = append(parts, fmt.Sprintf("%d %s", count, level))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s: %d parsed, %s (%.0f%% repaired)",
+			provider, total, strings.Join(parts, ", "), float64(repaired)/float64(total)*100))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	panicsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "trading_gpt_panics_total",
+			Help: "Recovered panics by subsystem",
+		},
+		[]string{"subsystem"},
+	)
+
+	restartsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "trading_gpt_subsystem_restarts_total",
+			Help: "Restarts of subsystems after a panic, by subsystem",
+		},
+		[]string{"subsystem"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(panicsTotal, restartsTotal)
+}
+
+// RecordPanic counts a recovered panic of the subsystem
+func RecordPanic(subsystem string) {
+	panicsTotal.WithLabelValues(subsystem).Inc()
+}
+
+// RecordRestart counts a restart of the subsystem after a panic
+func RecordRestart(subsystem string) {
+	restartsTotal.WithLabelValues(subsystem).Inc()
+}
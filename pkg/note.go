@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/journal"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// handleNote attaches operator commentary to the open trade, or to the last closed trade without one
+func (s *Strategy) handleNote(ctx context.Context, chatSession ttypes.ISession, note string) {
+	if note == "" {
+		s.replyMsg(ctx, chatSession, "Usage: /note <text>, e.g. /note entered against the 4h trend")
+		return
+	}
+
+	trade, err := s.addTradeNote(note)
+	if err != nil {
+		log.WithError(err).Warn("add trade note error")
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("note error: %s", err.Error()))
+		return
+	}
+
+	s.replyMsg(ctx, chatSession, fmt.Sprintf("📝 Note attached to the %s.", trade))
+}
+
+// addTradeNote appends a note entry for the trade to the journal, returning which trade it was attached to
+func (s *Strategy) addTradeNote(note string) (string, error) {
+	if s.journal == nil {
+		return "", errors.New("the journal is not enabled")
+	}
+
+	trade := "open trade"
+	tradeID := ""
+	if s.Position != nil && !s.Position.GetBase().IsZero() {
+		tradeID = s.openDecisionID
+	}
+
+	if tradeID == "" {
+		closed, err := s.journal.Recent(journal.KindTradeClosed, 1)
+		if err != nil {
+			return "", errors.Wrap(err, "load journal error")
+		}
+
+		if len(closed) == 0 {
+			return "", errors.New("no open or closed trade to attach the note to")
+		}
+
+		trade = fmt.Sprintf("trade closed at %s", closed[0].Time.Format(time.RFC3339))
+		tradeID = closed[0].ID
+		if closed[0].Trade != nil && closed[0].Trade.DecisionID != "" {
+			tradeID = closed[0].Trade.DecisionID
+		}
+	}
+
+	err := s.journal.Append(&journal.Entry{
+		ID:      uuid.NewString(),
+		Time:    time.Now(),
+		Kind:    journal.KindNote,
+		Symbol:  s.Symbol,
+		Note:    note,
+		TradeID: tradeID,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "append note error")
+	}
+
+	return trade, nil
+}
+
+// tradeNotes returns the operator notes attached to the trade opened by the given decision
+func (s *Strategy) tradeNotes(decisionID string) []string {
+	if s.journal == nil || decisionID == "" {
+		return []string{}
+	}
+
+	entries, err := s.journal.Notes(decisionID)
+	if err != nil {
+		log.WithError(err).Warn("Failed to load trade notes")
+		return []string{}
+	}
+
+	notes := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		notes = append(notes, entry.Note)
+	}
+
+	return notes
+}
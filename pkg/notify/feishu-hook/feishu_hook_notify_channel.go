@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -47,6 +48,13 @@ func (ch *FeishuHookNotifyChannel) GetID() string {
 }
 
 func (ch *FeishuHookNotifyChannel) Reply(ctx context.Context, msg *types.Message) error {
+	if msg.CycleID != "" {
+		msg = &types.Message{
+			ID:   msg.ID,
+			Text: fmt.Sprintf("%s\n[cycle: %s]", msg.Text, msg.CycleID),
+		}
+	}
+
 	hook := &FeishuHook{
 		MsgType: "text",
 		Content: msg,
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+var log = logrus.WithField("notify", "routed")
+
+// rateLimitWindow is the window the per-severity rate limits count messages in
+const rateLimitWindow = time.Minute
+
+// RoutedChannel wraps a notify channel, only forwarding the configured severities within their rate limits.
+// Filtered and throttled messages are still logged.
+type RoutedChannel struct {
+	channel   types.INotifyChannel
+	levels    map[types.Severity]bool
+	rateLimit map[types.Severity]int
+
+	mu         sync.Mutex
+	sent       map[types.Severity][]time.Time
+	suppressed map[types.Severity]int
+	now        func() time.Time
+}
+
+// NewRoutedChannel creates a routed channel, a nil config forwards everything
+func NewRoutedChannel(channel types.INotifyChannel, cfg *config.NotifyRouteConfig) *RoutedChannel {
+	ch := &RoutedChannel{
+		channel:    channel,
+		rateLimit:  make(map[types.Severity]int),
+		sent:       make(map[types.Severity][]time.Time),
+		suppressed: make(map[types.Severity]int),
+		now:        time.Now,
+	}
+
+	if cfg == nil {
+		return ch
+	}
+
+	for _, name := range cfg.Levels {
+		severity, ok := types.ParseSeverity(name)
+		if !ok {
+			log.WithField("level", name).Warn("unknown notify level, ignored")
+			continue
+		}
+
+		if ch.levels == nil {
+			ch.levels = make(map[types.Severity]bool)
+		}
+		ch.levels[severity] = true
+	}
+
+	for name, limit := range cfg.RateLimit {
+		severity, ok := types.ParseSeverity(name)
+		if !ok {
+			log.WithField("level", name).Warn("unknown notify level in rate limit, ignored")
+			continue
+		}
+
+		ch.rateLimit[severity] = limit
+	}
+
+	return ch
+}
+
+func (ch *RoutedChannel) GetID() string {
+	return ch.channel.GetID()
+}
+
+func (ch *RoutedChannel) Reply(ctx context.Context, msg *types.Message) error {
+	severity := msg.Severity
+	if severity == "" {
+		severity = types.SeverityInfo
+	}
+
+	if ch.levels != nil && !ch.levels[severity] {
+		log.WithField("channel", ch.GetID()).
+			WithField("severity", severity).
+			WithField("text", msg.Text).
+			Debug("notification not routed to channel")
+		return nil
+	}
+
+	suppressed, ok := ch.allow(severity)
+	if !ok {
+		log.WithField("channel", ch.GetID()).
+			WithField("severity", severity).
+			WithField("text", msg.Text).
+			Info("notification throttled")
+		return nil
+	}
+
+	if suppressed > 0 {
+		copied := *msg
+		copied.Text = fmt.Sprintf("%s\n(%d earlier %s notifications throttled)", msg.Text, suppressed, severity)
+		msg = &copied
+	}
+
+	return ch.channel.Reply(ctx, msg)
+}
+
+// allow records a message of the severity if it is within the rate limit, returning how many were throttled before it
+func (ch *RoutedChannel) allow(severity types.Severity) (int, bool) {
+	limit := ch.rateLimit[severity]
+	if limit <= 0 {
+		return 0, true
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	now := ch.now()
+	sent := ch.sent[severity]
+	for len(sent) > 0 && now.Sub(sent[0]) >= rateLimitWindow {
+		sent = sent[1:]
+	}
+
+	if len(sent) >= limit {
+		ch.sent[severity] = sent
+		ch.suppressed[severity]++
+		return 0, false
+	}
+
+	ch.sent[severity] = append(sent, now)
+
+	suppressed := ch.suppressed[severity]
+	ch.suppressed[severity] = 0
+	return suppressed, true
+}
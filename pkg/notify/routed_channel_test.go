@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+type recordChannel struct {
+	msgs []*types.Message
+}
+
+func (ch *recordChannel) GetID() string {
+	return "record"
+}
+
+func (ch *recordChannel) Reply(ctx context.Context, msg *types.Message) error {
+	ch.msgs = append(ch.msgs, msg)
+	return nil
+}
+
+func TestRoutedChannelLevels(t *testing.T) {
+	record := &recordChannel{}
+	ch := NewRoutedChannel(record, &config.NotifyRouteConfig{
+		Levels: []string{"action", "critical"},
+	})
+
+	ctx := context.Background()
+	assert.NoError(t, ch.Reply(ctx, &types.Message{Text: "thoughts"}))
+	assert.NoError(t, ch.Reply(ctx, &types.Message{Text: "opened long", Severity: types.SeverityAction}))
+	assert.NoError(t, ch.Reply(ctx, &types.Message{Text: "high drawdown", Severity: types.SeverityWarning}))
+	assert.NoError(t, ch.Reply(ctx, &types.Message{Text: "paused", Severity: types.SeverityCritical}))
+
+	assert.Len(t, record.msgs, 2)
+	assert.Equal(t, "opened long", record.msgs[0].Text)
+	assert.Equal(t, "paused", record.msgs[1].Text)
+
+	// Everything is forwarded without a config
+	record = &recordChannel{}
+	ch = NewRoutedChannel(record, nil)
+	assert.NoError(t, ch.Reply(ctx, &types.Message{Text: "thoughts"}))
+	assert.Len(t, record.msgs, 1)
+}
+
+func TestRoutedChannelRateLimit(t *testing.T) {
+	record := &recordChannel{}
+	ch := NewRoutedChannel(record, &config.NotifyRouteConfig{
+		RateLimit: map[string]int{"info": 2},
+	})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ch.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, ch.Reply(ctx, &types.Message{Text: "info"}))
+	}
+	assert.NoError(t, ch.Reply(ctx, &types.Message{Text: "action", Severity: types.SeverityAction}))
+	assert.Len(t, record.msgs, 3)
+
+	// The window slides, and the next message reports the throttled ones
+	now = now.Add(time.Minute)
+	assert.NoError(t, ch.Reply(ctx, &types.Message{Text: "info"}))
+	assert.Len(t, record.msgs, 4)
+	assert.Equal(t, "info\n(2 earlier info notifications throttled)", record.msgs[3].Text)
+}
@@ -0,0 +1,463 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+
+	"github.com/yubing744/trading-gpt/pkg/agents/trading"
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/env"
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	"github.com/yubing744/trading-gpt/pkg/risk"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// noAction is the response of the scripted LLM in the cycles it has no decision for
+const noAction = `{"thoughts": {"plan": "wait", "speak": "No setup, waiting"}, "action": null}`
+
+// scriptedLLM answers the decision cycles with canned responses by cycle index
+type scriptedLLM struct {
+	responses map[int]string
+	prompts   []string
+
+	// stalls are the cycles whose call hangs until the context is done, like a provider slower than the deadline
+	stalls map[int]bool
+}
+
+func (m *scriptedLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	var prompt strings.Builder
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if text, ok := part.(llms.TextContent); ok {
+				prompt.WriteString(text.Text)
+				prompt.WriteString("\n")
+			}
+		}
+	}
+
+	cycle := len(m.prompts)
+	m.prompts = append(m.prompts, prompt.String())
+
+	if m.stalls[cycle] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	text, ok := m.responses[cycle]
+	if !ok {
+		text = noAction
+	}
+
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{Content: text}},
+	}, nil
+}
+
+func (m *scriptedLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// recordingSession is the admin chat session of a scenario, keeping the replies of the strategy
+type recordingSession struct {
+	*ttypes.MockSession
+	replies []string
+}
+
+func (s *recordingSession) Reply(ctx context.Context, msg *ttypes.Message) error {
+	s.replies = append(s.replies, msg.Text)
+	return nil
+}
+
+// bar is a kline of a scenario
+type bar struct {
+	Open, High, Low, Close float64
+}
+
+// paperClose is a position closed on the paper venue
+type paperClose struct {
+	Side      string
+	ExitPrice float64
+	PnL       float64
+	Reason    string
+}
+
+// paperVenue fills the orders of the exchange entity at the price of the last kline, and triggers the stop-loss
+// and take-profit attached to the entry on the following klines
+type paperVenue struct {
+	symbol   string
+	position *types.Position
+	price    float64
+	balance  float64 // Quote balance with the realized PnL
+
+	// side, stops and realized PnL of the open position
+	side       string
+	stopLoss   float64
+	takeProfit float64
+	realized   float64
+
+	orders []types.SubmitOrder
+	closes []paperClose
+
+	// orders resting on the venue and the number of times they were queried
+	openOrders       []types.Order
+	openOrderQueries int
+
+	callbacks []func(position *types.Position)
+	onClose   func(paperClose)
+}
+
+func (v *paperVenue) SubmitOrders(ctx context.Context, orderForms ...types.SubmitOrder) (types.OrderSlice, error) {
+	orders := make(types.OrderSlice, 0, len(orderForms))
+
+	for _, form := range orderForms {
+		if form.Type != types.OrderTypeMarket {
+			return orders, fmt.Errorf("paper venue fills market orders only, got %s", form.Type)
+		}
+
+		v.orders = append(v.orders, form)
+
+		// Market buys are sized in quote, a full close takes the whole position like the exchanges do
+		quantity := form.Quantity
+		switch {
+		case form.ClosePosition:
+			quantity = v.position.GetBase().Abs()
+		case form.Side == types.SideTypeBuy:
+			quantity = quantity.Div(fixedpoint.NewFromFloat(v.price))
+		}
+
+		entry := v.position.GetBase().IsZero()
+		v.fill(form.Side, v.price, quantity)
+
+		if entry {
+			v.side = exchange.PositionSideLong
+			if form.Side == types.SideTypeSell {
+				v.side = exchange.PositionSideShort
+			}
+			v.stopLoss = form.StopPrice.Float64()
+			v.takeProfit = form.TakePrice.Float64()
+		} else if v.position.GetBase().IsZero() {
+			v.closePosition(v.price, "agent")
+		}
+
+		orders = append(orders, types.Order{
+			SubmitOrder:      form,
+			OrderID:          uint64(len(v.orders)),
+			Status:           types.OrderStatusFilled,
+			ExecutedQuantity: quantity,
+		})
+	}
+
+	return orders, nil
+}
+
+func (v *paperVenue) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	v.openOrderQueries++
+	return v.openOrders, nil
+}
+
+func (v *paperVenue) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	return nil
+}
+
+func (v *paperVenue) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	price := fixedpoint.NewFromFloat(v.price)
+	return &types.Ticker{Buy: price, Sell: price, Last: price}, nil
+}
+
+func (v *paperVenue) QueryPosition(ctx context.Context, symbol string) (fixedpoint.Value, error) {
+	return v.position.GetBase(), nil
+}
+
+func (v *paperVenue) QueryServerTime(ctx context.Context) (time.Time, error) {
+	return time.Now(), nil
+}
+
+func (v *paperVenue) OnPositionUpdate(cb func(position *types.Position)) {
+	v.callbacks = append(v.callbacks, cb)
+}
+
+// fill applies a trade to the position, realizing the PnL of the part it closes
+func (v *paperVenue) fill(side types.SideType, price float64, quantity fixedpoint.Value) {
+	base := v.position.GetBase().Float64()
+	if (side == types.SideTypeSell && base > 0) || (side == types.SideTypeBuy && base < 0) {
+		closed := math.Min(quantity.Float64(), math.Abs(base))
+		pnl := closed * (price - v.position.AverageCost.Float64()) * math.Copysign(1, base)
+		v.balance += pnl
+		v.realized += pnl
+	}
+
+	v.position.AddTrade(types.Trade{
+		OrderID:       uint64(len(v.orders)),
+		Price:         fixedpoint.NewFromFloat(price),
+		Quantity:      quantity,
+		QuoteQuantity: quantity.Mul(fixedpoint.NewFromFloat(price)),
+		Symbol:        v.symbol,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+	})
+
+	for _, cb := range v.callbacks {
+		cb(v.position)
+	}
+}
+
+// trigger fills the stop-loss or take-profit of the position hit by the kline, at the open when it gapped through
+func (v *paperVenue) trigger(b bar) {
+	base := v.position.GetBase().Float64()
+	if base == 0 {
+		return
+	}
+
+	long := base > 0
+	stop, take := v.stopLoss, v.takeProfit
+
+	switch {
+	case stop > 0 && long && b.Open <= stop:
+		v.exit(b.Open, "stop_loss_gap")
+	case stop > 0 && !long && b.Open >= stop:
+		v.exit(b.Open, "stop_loss_gap")
+	case stop > 0 && long && b.Low <= stop:
+		v.exit(stop, "stop_loss")
+	case stop > 0 && !long && b.High >= stop:
+		v.exit(stop, "stop_loss")
+	case take > 0 && long && b.High >= take:
+		v.exit(math.Max(b.Open, take), "take_profit")
+	case take > 0 && !long && b.Low <= take:
+		v.exit(math.Min(b.Open, take), "take_profit")
+	}
+}
+
+// exit closes the whole position at the price
+func (v *paperVenue) exit(price float64, reason string) {
+	side := types.SideTypeSell
+	if v.position.IsShort() {
+		side = types.SideTypeBuy
+	}
+
+	v.fill(side, price, v.position.GetBase().Abs())
+	v.closePosition(price, reason)
+}
+
+func (v *paperVenue) closePosition(price float64, reason string) {
+	closed := paperClose{Side: v.side, ExitPrice: price, PnL: v.realized, Reason: reason}
+	v.closes = append(v.closes, closed)
+	v.side, v.stopLoss, v.takeProfit, v.realized = "", 0, 0, 0
+
+	if v.onClose != nil {
+		v.onClose(closed)
+	}
+}
+
+// paperExchange reports the balance of the paper venue as the account of the exchange session
+type paperExchange struct {
+	types.Exchange
+	venue *paperVenue
+}
+
+func (e *paperExchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	account := types.NewAccount()
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": types.Balance{Currency: "USDT", Available: fixedpoint.NewFromFloat(e.venue.balance)},
+	})
+
+	return account, nil
+}
+
+// pipelineConfig are the mechanical limits a scenario runs with
+type pipelineConfig struct {
+	Equity         float64
+	MaxRiskPercent float64
+	Risk           config.RiskConfig
+	FlipGuard      config.FlipGuardConfig
+	PreTrade       config.PreTradeConfig
+}
+
+// pipeline runs the decision loop of the strategy over scripted klines. Each closed kline goes through the env
+// events the exchange entity emits, then the agent decides with a scripted LLM and its actions go through
+// agentAction, the guards of the strategy and the exchange entity, which submits the orders to a paper venue.
+type pipeline struct {
+	strategy *Strategy
+	llm      *scriptedLLM
+	session  *recordingSession
+	venue    *paperVenue
+
+	start time.Time
+	cycle int
+
+	// rejections are the reasons the guards gave for the rejected commands, by decision cycle
+	rejections map[int][]string
+}
+
+func newPipeline(t *testing.T, cfg pipelineConfig, responses map[int]string) *pipeline {
+	ctx := context.Background()
+
+	market := types.Market{
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+		TickSize:      fixedpoint.NewFromFloat(0.01),
+		StepSize:      fixedpoint.NewFromFloat(0.0001),
+		MinQuantity:   fixedpoint.NewFromFloat(0.0001),
+	}
+	position := types.NewPositionFromMarket(market)
+
+	venue := &paperVenue{symbol: market.Symbol, position: position, balance: cfg.Equity}
+	session := &bbgo.ExchangeSession{Exchange: &paperExchange{venue: venue}}
+	_, err := session.UpdateAccount(ctx)
+	assert.NoError(t, err)
+
+	s := &Strategy{
+		Config: config.Config{
+			Symbol:    market.Symbol,
+			Interval:  types.Interval1h,
+			Leverage:  fixedpoint.One,
+			Risk:      cfg.Risk,
+			FlipGuard: cfg.FlipGuard,
+			PreTrade:  cfg.PreTrade,
+			Env: config.EnvConfig{
+				IncludeEvents: []string{"kline_changed", exchange.EventPositionClosed, risk.EventRiskLimitHit},
+				ExchangeConfig: &config.EnvExchangeConfig{
+					RiskSizing: config.RiskSizingConfig{MaxRiskPercent: cfg.MaxRiskPercent},
+				},
+			},
+		},
+		Market:   market,
+		Position: position,
+		session:  session,
+	}
+
+	s.world = env.NewEnvironment(&s.Env)
+	s.exchange = exchange.NewExchangeEntity(s.Symbol, s.Interval, s.Leverage, s.Env.ExchangeConfig, session, venue, position)
+	s.exchange.KLineWindow = &types.KLineWindow{}
+	s.world.RegisterEntity(s.exchange)
+
+	llm := &scriptedLLM{responses: responses}
+	s.agent = trading.NewTradingAgent(&config.TradingAgentConfig{
+		Name:             "jarvis",
+		Backgroup:        "You are a crypto futures trader.",
+		MaxContextLength: 4096,
+	}, llm)
+
+	for _, setup := range []func(ctx context.Context) error{s.setupFormatters, s.setupRisk, s.setupFlipGuard} {
+		assert.NoError(t, setup(ctx))
+	}
+
+	// The user data stream reports the closed positions
+	venue.onClose = func(closed paperClose) {
+		s.world.Emit(exchange.NewPositionClosedEvent(exchange.PositionClosedEventData{
+			Symbol:        market.Symbol,
+			Side:          closed.Side,
+			ExitPrice:     closed.ExitPrice,
+			ProfitAndLoss: closed.PnL,
+			CloseReason:   closed.Reason,
+			Timestamp:     time.Now(),
+		}))
+	}
+
+	chatSession := &recordingSession{MockSession: ttypes.NewMockSession("scenario")}
+	chatSession.SetRoles([]string{ttypes.RoleAdmin})
+
+	return &pipeline{
+		strategy:   s,
+		llm:        llm,
+		session:    chatSession,
+		venue:      venue,
+		start:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		rejections: make(map[int][]string),
+	}
+}
+
+// run feeds the klines one by one, a decision cycle runs on every close
+func (p *pipeline) run(bars []bar) {
+	for _, b := range bars {
+		p.close(b)
+		p.decide(context.Background())
+		p.cycle++
+	}
+}
+
+// close plays the kline close of the exchange entity: the stops trigger, the kline joins the window and the
+// kline_changed event updates the risk manager and the prompt
+func (p *pipeline) close(b bar) {
+	ctx := context.Background()
+	s := p.strategy
+
+	p.venue.trigger(b)
+	p.venue.price = b.Close
+
+	startTime := p.start.Add(time.Duration(p.cycle) * time.Hour)
+	s.exchange.KLineWindow.Add(types.KLine{
+		Symbol:    s.Symbol,
+		Interval:  s.Interval,
+		StartTime: types.Time(startTime),
+		EndTime:   types.Time(startTime.Add(time.Hour - time.Millisecond)),
+		Open:      fixedpoint.NewFromFloat(b.Open),
+		High:      fixedpoint.NewFromFloat(b.High),
+		Low:       fixedpoint.NewFromFloat(b.Low),
+		Close:     fixedpoint.NewFromFloat(b.Close),
+		Closed:    true,
+	})
+
+	evt := ttypes.NewEvent("kline_changed", s.exchange.KLineWindow)
+	s.world.Emit(evt)
+	s.handleEnvEvent(ctx, p.session, evt)
+}
+
+// decide runs the decision cycle on the prompt of the kline, without retries so a rejection ends the cycle
+func (p *pipeline) decide(ctx context.Context) {
+	msgs, ok := p.strategy.popMsgs(ctx, p.session)
+	if !ok {
+		msgs = []*ttypes.Message{{Text: "No kline closed"}}
+	}
+	p.session.RemoveAttribute("tempMsgs")
+
+	replied := len(p.session.replies)
+	p.strategy.agentAction(ctx, p.session, msgs, 0)
+
+	for _, reply := range p.session.replies[replied:] {
+		if _, reason, ok := strings.Cut(reply, " rejected, reason: "); ok {
+			p.rejections[p.cycle] = append(p.rejections[p.cycle], reason)
+		}
+	}
+}
+
+// entries returns the submitted orders opening a position
+func (p *pipeline) entries() []types.SubmitOrder {
+	entries := make([]types.SubmitOrder, 0)
+	for _, order := range p.venue.orders {
+		if !order.ClosePosition {
+			entries = append(entries, order)
+		}
+	}
+
+	return entries
+}
+
+// trend returns n klines moving by step from the price, with wicks of a quarter step
+func trend(price float64, step float64, n int) []bar {
+	bars := make([]bar, 0, n)
+	wick := math.Abs(step) / 4
+
+	for i := 0; i < n; i++ {
+		open := price
+		price += step
+		bars = append(bars, bar{
+			Open:  open,
+			High:  math.Max(open, price) + wick,
+			Low:   math.Min(open, price) - wick,
+			Close: price,
+		})
+	}
+
+	return bars
+}
@@ -0,0 +1,57 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/env/bridge"
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// handlePositionDiverged alerts the operator of a mismatch with the exchange position before the agent sees it
+func (s *Strategy) handlePositionDiverged(ctx context.Context, session ttypes.ISession, evt ttypes.IEvent) {
+	if diverged, ok := evt.(*exchange.PositionDivergedEvent); ok {
+		msg := fmt.Sprintf("⚠️ Position mismatch on %s: %s. New entries are paused, send /reconcile to adopt the exchange position.",
+			diverged.Symbol, diverged.Divergence.String())
+		s.notifyMsg(ctx, session, ttypes.SeverityCritical, msg)
+	}
+
+	s.handleDefaultEvent(ctx, session, evt)
+}
+
+// reconcilePosition adopts the exchange position on an operator's request
+func (s *Strategy) reconcilePosition(ctx context.Context, operator string) (string, error) {
+	if s.exchange == nil {
+		return "", errors.New("no exchange entity to reconcile")
+	}
+
+	result, err := s.exchange.ReconcilePosition(ctx)
+	if err != nil {
+		log.WithError(err).Error("reconcile position error")
+		return "", err
+	}
+
+	msg := fmt.Sprintf("🔄 %s, requested by %s.", result, operator)
+	log.Info(msg)
+	s.publishBridge(ctx, &bridge.OutboundMessage{Type: "reconcile", Text: msg})
+
+	return msg, nil
+}
+
+// reconcileReply runs an operator's reconcile and returns the reply to send back
+func (s *Strategy) reconcileReply(ctx context.Context, operator string) string {
+	msg, err := s.reconcilePosition(ctx, operator)
+	if err != nil {
+		return fmt.Sprintf("Reconcile failed, reason: %s", err.Error())
+	}
+
+	return msg
+}
+
+// isReconcileCommand returns whether an operator command asks to adopt the exchange position
+func isReconcileCommand(command string) bool {
+	return command == "reconcile" || command == "jarvis.reconcile"
+}
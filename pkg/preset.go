@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// UnmarshalJSON loads the strategy config on top of its preset, so every field set in the config overrides
+// the preset's value while nested sections and indicators are merged
+func (s *Strategy) UnmarshalJSON(data []byte) error {
+	// plain has the fields of Strategy without this method
+	type plain Strategy
+
+	var head struct {
+		Preset string `json:"preset"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return err
+	}
+
+	if head.Preset != "" {
+		preset, err := config.Preset(head.Preset)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(preset, (*plain)(s)); err != nil {
+			return errors.Wrapf(err, "apply preset %s error", head.Preset)
+		}
+
+		log.WithField("preset", head.Preset).Info("Strategy preset applied")
+	}
+
+	return json.Unmarshal(data, (*plain)(s))
+}
@@ -0,0 +1,89 @@
+package prompt
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/template"
+
+	"github.com/yubing744/trading-gpt/pkg/utils/xtemplate"
+)
+
+// Event types with overridable prompt formatters. Indicators are keyed by their type, e.g. "boll" or "rsi".
+const (
+	FormatterKline    = "kline"
+	FormatterPosition = "position"
+	FormatterFng      = "fng"
+)
+
+// FormatterRegistry maps event types to user-overridable prompt templates.
+// Event types without a registered template keep their built-in phrasing.
+type FormatterRegistry struct {
+	templates map[string]*template.Template
+	mu        sync.RWMutex
+}
+
+// NewFormatterRegistry creates a registry from event type to template string overrides
+func NewFormatterRegistry(overrides map[string]string) (*FormatterRegistry, error) {
+	registry := &FormatterRegistry{
+		templates: make(map[string]*template.Template),
+	}
+
+	for eventType, tmplStr := range overrides {
+		if err := registry.Register(eventType, tmplStr); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// Register sets the template used to render an event type
+func (r *FormatterRegistry) Register(eventType string, tmplStr string) error {
+	tmpl, err := xtemplate.Parse(tmplStr)
+	if err != nil {
+		return fmt.Errorf("invalid formatter template for %s: %w", eventType, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.templates[eventType] = tmpl
+	return nil
+}
+
+// Has reports whether an event type has a registered template
+func (r *FormatterRegistry) Has(eventType string) bool {
+	if r == nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.templates[eventType]
+	return ok
+}
+
+// Format renders the event data with the registered template. ok is false if the event type
+// has no template, in which case the caller falls back to the built-in phrasing.
+func (r *FormatterRegistry) Format(eventType string, data map[string]interface{}) (string, bool, error) {
+	if r == nil {
+		return "", false, nil
+	}
+
+	r.mu.RLock()
+	tmpl, ok := r.templates[eventType]
+	r.mu.RUnlock()
+
+	if !ok {
+		return "", false, nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", true, fmt.Errorf("failed to render %s formatter: %w", eventType, err)
+	}
+
+	return buf.String(), true, nil
+}
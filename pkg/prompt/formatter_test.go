@@ -0,0 +1,26 @@
+package prompt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatterRegistry(t *testing.T) {
+	registry, err := NewFormatterRegistry(map[string]string{
+		"rsi": "{{.Name}}={{round .Last 1}}",
+	})
+	assert.NoError(t, err)
+
+	text, ok, err := registry.Format("rsi", map[string]interface{}{"Name": "RSI14", "Last": 71.234})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "RSI14=71.2", text)
+
+	_, ok, err = registry.Format(FormatterKline, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = NewFormatterRegistry(map[string]string{"boll": "{{.Broken"})
+	assert.Error(t, err)
+}
@@ -19,6 +19,31 @@ Current memory usage: {{.CurrentWords}} words ({{.MemoryUsagePercent}}% of limit
 
 IMPORTANT: The strategy runs in cycles, and your memory resets at the beginning of each cycle. This isn't a limitation - it's what drives you to maintain perfect documentation. After each reset, you rely ENTIRELY on your Memory Part to understand the project and continue work effectively. Each cycle, you must output complete memory within the word limit to maintain continuity.
 
+{{end}}
+{{- if .RelevantPlaybooks}}
+=== Strategy Playbooks ===
+{{- range $index, $item := .RelevantPlaybooks}}
+{{add $index 1}}. {{$item}}
+{{- end}}
+
+{{end}}{{- if .RelevantRules}}
+=== General Trading Rules (distilled from past trades) ===
+{{- range $index, $item := .RelevantRules}}
+{{add $index 1}}. {{$item}}
+{{- end}}
+
+{{end}}{{- if .RelevantMemories}}
+=== Similar Past Trades ===
+{{- range $index, $item := .RelevantMemories}}
+{{add $index 1}}. {{$item}}
+{{- end}}
+
+{{end}}{{- if .Examples}}
+=== Example Decisions ===
+{{- range $index, $item := .Examples}}
+{{add $index 1}}. {{$item}}
+{{- end}}
+
 {{end}}
 Analyze the data provided above, and step-by-step consider the only executable trade command based on the trading strategy provided below to maximize user profit.
 
@@ -45,6 +70,7 @@ Constraints:
 5、The analyze statement can be very long to ensure that the reasoning process of the analysis is rigorous.
 6、When comparing two numbers, if a digit in the decimal part is already greater, there's no need to compare the subsequent digits.
 7、The returned JSON format does not support comments
+8、To run several commands in order in one response (e.g. exchange.close_position then exchange.open_short_position), replace "action" with "actions": [{"name": "command name", "args": {}}, ...]. All commands are validated before the first one runs, and the remaining ones are aborted if one fails
 
 {{if .MemoryEnabled}}
 You should only respond in JSON format as described below, no other explanation is required
@@ -57,6 +83,7 @@ Response Format:
         "reflection": "comprehensive self-criticism including: 1) trade execution analysis, 2) strategy effectiveness evaluation, 3) market condition adaptation, 4) risk management review, 5) strategy improvement suggestions",
         "speak": "thoughts summary to say to user"
     },
+    "assessment": {"trend": "up, down or sideways", "volatility": "low, normal or high", "key_levels": ["support and resistance prices"], "bias": "long, short or neutral"},
     "action": {"name": "command name", "args": {"arg name": "value"}},
     "memory": {"content": "memory content to save, keep concise and within reasonable word limit"}
 }
@@ -71,6 +98,7 @@ Response Format:
         "reflection": "comprehensive self-criticism including: 1) trade execution analysis, 2) strategy effectiveness evaluation, 3) market condition adaptation, 4) risk management review, 5) strategy improvement suggestions",
         "speak": "thoughts summary to say to user"
     },
+    "assessment": {"trend": "up, down or sideways", "volatility": "low, normal or high", "key_levels": ["support and resistance prices"], "bias": "long, short or neutral"},
     "action": {"name": "command name", "args": {"arg name": "value"}}
 }
 {{end}}
@@ -93,8 +121,91 @@ Trade details:
 - Exit Price: {{.ExitPrice}}
 - Quantity: {{.Quantity}}
 - Profit/Loss: {{.ProfitAndLoss}} ({{.ProfitPercent}}%)
+- Max Adverse Excursion: {{printf "%.2f" .MAEPercent}}% (deepest drawdown from entry during the trade)
+- Max Favorable Excursion: +{{printf "%.2f" .MFEPercent}}% (largest open profit during the trade)
 - Close Reason: {{.CloseReason}}
 - Close Time: {{.Timestamp}}
+{{- if .Counterfactuals}}
+
+Counterfactual outcomes replayed on the market data:
+{{- range .Counterfactuals}}
+- {{.}}
+{{- end}}
+{{- end}}
+{{- if .Notes}}
+
+Operator notes on this trade:
+{{- range .Notes}}
+- {{.}}
+{{- end}}
+{{- end}}
 
+Reference the concrete prices and profit/loss figures of this trade, avoid generic statements, and finish with specific, actionable lessons for future similar trades.
 Please format your response as a structured markdown document with clear headings and bullet points. This reflection will be saved to the memory bank for future reference in trading decisions.
 `
+
+// StrategyReviewTpl is a template for the periodic strategy review memo
+var StrategyReviewTpl = `You are an expert trading advisor reviewing the performance of an automated trading strategy on {{.Symbol}} over the last {{.Period}}.
+
+Performance stats:
+{{.Stats}}
+
+Decision journal:
+{{- range .Entries}}
+- {{.}}
+{{- end}}
+
+Write a concise strategy review memo in markdown with the sections "What Worked", "What Didn't Work" and "What To Change".
+Ground every point in the journal and stats above, and make "What To Change" a list of specific, actionable adjustments.
+`
+
+// AskTpl is a template for answering an operator's question about the bot, grounded in its state and history
+var AskTpl = `You are the automated trading assistant trading {{.Symbol}}. An operator asks you a question about your state or past decisions.
+
+Current state:
+{{.State}}
+
+Recent decision journal (oldest first):
+{{- range .Entries}}
+- {{.}}
+{{- else}}
+- (empty)
+{{- end}}
+{{- if .Memories}}
+
+Relevant memories:
+{{- range $index, $item := .Memories}}
+{{add $index 1}}. {{$item}}
+{{- end}}
+{{- end}}
+
+Operator question: {{.Question}}
+
+Answer concisely using only the evidence above, citing the time of the journal entries you rely on.
+If the evidence does not answer the question, say so instead of guessing.
+`
+
+// MemoryConsolidationTpl is a template for distilling general trading rules from past trade reflections
+var MemoryConsolidationTpl = `You are an expert trading advisor reviewing past trade reflections for {{.Symbol}}.
+
+Past trade reflections:
+{{- range $index, $item := .Episodes}}
+{{add $index 1}}. {{$item}}
+{{- end}}
+
+Distill the recurring lessons into at most 5 general trading rules that apply beyond any single trade.
+Each rule must be one concise, actionable sentence. Respond with a markdown bullet list of the rules only.
+`
+
+// MemoryRelevanceTpl is a template for scoring how relevant a stored memory is to the current market situation
+var MemoryRelevanceTpl = `You are helping a trading assistant decide which past trade reflections are useful right now.
+
+Current market situation:
+{{.Situation}}
+
+Past trade reflection:
+{{.Memory}}
+
+Rate how relevant this reflection is to the current situation on a scale from 0 (irrelevant) to 10 (directly applicable).
+Respond with the number only.
+`
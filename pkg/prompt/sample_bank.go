@@ -0,0 +1,220 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Sample sources
+const (
+	SampleSourceBuiltin = "builtin"
+	SampleSourceFile    = "file"
+	SampleSourceJournal = "journal"
+)
+
+// Sample is a curated few-shot example of the command decided in a situation
+type Sample struct {
+	ID        string `json:"id,omitempty"` // Decision ID of journal samples
+	Action    string `json:"action"`       // Full command name, e.g. "exchange.open_long_position"
+	Situation string `json:"situation"`    // Market and position summary when the command was decided
+	Output    string `json:"output"`       // The decided command, e.g. {"name":"exchange.close_position","args":{}}
+	Source    string `json:"source,omitempty"`
+}
+
+// String renders the sample as a prompt line
+func (s *Sample) String() string {
+	return fmt.Sprintf("Situation: %s => %s", s.Situation, s.Output)
+}
+
+// LoadSamples reads a JSON array of samples from a file
+func LoadSamples(path string) ([]*Sample, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read sample file error: %w", err)
+	}
+
+	samples := make([]*Sample, 0)
+	if err := json.Unmarshal(data, &samples); err != nil {
+		return nil, fmt.Errorf("parse sample file %s error: %w", path, err)
+	}
+
+	for _, sample := range samples {
+		if sample.Source == "" {
+			sample.Source = SampleSourceFile
+		}
+	}
+
+	return samples, nil
+}
+
+// SaveSamples writes the samples to a file as a JSON array
+func SaveSamples(path string, samples []*Sample) error {
+	data, err := json.MarshalIndent(samples, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal samples error: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create sample dir error: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write sample file error: %w", err)
+	}
+
+	return nil
+}
+
+// SampleBank picks the few-shot samples most relevant to the current situation for each available command.
+// Every time a sample is shown its relevance is discounted, so equally relevant samples take turns.
+type SampleBank struct {
+	mu      sync.Mutex
+	samples []*Sample
+	shown   map[*Sample]int
+}
+
+// NewSampleBank creates an empty sample bank
+func NewSampleBank() *SampleBank {
+	return &SampleBank{
+		samples: make([]*Sample, 0),
+		shown:   make(map[*Sample]int),
+	}
+}
+
+// Add adds the samples, skipping incomplete ones and duplicates of the same action and situation,
+// and returns how many were added
+func (b *SampleBank) Add(samples ...*Sample) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	added := 0
+	for _, sample := range samples {
+		if sample == nil || sample.Action == "" || strings.TrimSpace(sample.Situation) == "" || sample.Output == "" {
+			continue
+		}
+
+		if b.contains(sample) {
+			continue
+		}
+
+		b.samples = append(b.samples, sample)
+		added++
+	}
+
+	return added
+}
+
+func (b *SampleBank) contains(sample *Sample) bool {
+	for _, existing := range b.samples {
+		if existing.Action == sample.Action && existing.Situation == sample.Situation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Has reports whether a sample with the ID is in the bank
+func (b *SampleBank) Has(id string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sample := range b.samples {
+		if id != "" && sample.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the number of samples in the bank
+func (b *SampleBank) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.samples)
+}
+
+// Select returns up to perAction samples for each of the actions, in the order of the actions,
+// ranked by keyword overlap with the situation and discounted by how often they were shown
+func (b *SampleBank) Select(situation string, actions []string, perAction int) []*Sample {
+	if perAction <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	words := keywords(situation)
+	selected := make([]*Sample, 0)
+
+	for _, action := range actions {
+		type candidate struct {
+			sample *Sample
+			score  float64
+		}
+
+		candidates := make([]candidate, 0)
+		for _, sample := range b.samples {
+			if sample.Action != action {
+				continue
+			}
+
+			score := relevance(words, keywords(sample.Situation)) / float64(1+b.shown[sample])
+			candidates = append(candidates, candidate{sample: sample, score: score})
+		}
+
+		// Stable so that ties keep the bank order, builtin samples first
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].score > candidates[j].score
+		})
+
+		for i := 0; i < len(candidates) && i < perAction; i++ {
+			b.shown[candidates[i].sample]++
+			selected = append(selected, candidates[i].sample)
+		}
+	}
+
+	return selected
+}
+
+// relevance is the share of the sample keywords found in the situation, with a floor so that
+// samples without overlap still rotate in
+func relevance(situation map[string]bool, sample map[string]bool) float64 {
+	if len(sample) == 0 {
+		return 0.01
+	}
+
+	overlap := 0
+	for word := range sample {
+		if situation[word] {
+			overlap++
+		}
+	}
+
+	return 0.01 + float64(overlap)/float64(len(sample))
+}
+
+// keywords returns the lowercase words of the text, without numbers and short words
+func keywords(text string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		if len(word) < 3 || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		set[word] = true
+	}
+
+	return set
+}
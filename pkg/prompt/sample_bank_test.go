@@ -0,0 +1,75 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleBankSelectsRelevantSamplesPerAction(t *testing.T) {
+	bank := NewSampleBank()
+	added := bank.Add(
+		&Sample{Action: "exchange.open_long_position", Situation: "price breaks above resistance with rising volume", Output: "long breakout"},
+		&Sample{Action: "exchange.open_long_position", Situation: "price bounces on the lower BOLL band, RSI oversold", Output: "long bounce"},
+		&Sample{Action: "exchange.close_position", Situation: "long position in profit, RSI overbought", Output: "close"},
+		&Sample{Action: "exchange.close_position", Situation: "long position in profit, RSI overbought", Output: "duplicate"},
+		&Sample{Action: "exchange.no_action", Situation: "", Output: "incomplete"},
+	)
+	assert.Equal(t, 3, added)
+	assert.Equal(t, 3, bank.Len())
+
+	selected := bank.Select("RSI 28 is oversold and price touches the lower band", []string{"exchange.open_long_position", "exchange.close_position", "exchange.no_action"}, 1)
+	assert.Len(t, selected, 2)
+	assert.Equal(t, "long bounce", selected[0].Output)
+	assert.Equal(t, "close", selected[1].Output)
+}
+
+func TestSampleBankRotatesEquallyRelevantSamples(t *testing.T) {
+	bank := NewSampleBank()
+	bank.Add(
+		&Sample{Action: "exchange.no_action", Situation: "sideways market", Output: "a"},
+		&Sample{Action: "exchange.no_action", Situation: "choppy market", Output: "b"},
+	)
+
+	first := bank.Select("market", []string{"exchange.no_action"}, 1)
+	second := bank.Select("market", []string{"exchange.no_action"}, 1)
+	third := bank.Select("market", []string{"exchange.no_action"}, 1)
+
+	assert.Equal(t, "a", first[0].Output)
+	assert.Equal(t, "b", second[0].Output)
+	assert.Equal(t, "a", third[0].Output)
+	assert.Nil(t, bank.Select("market", []string{"exchange.no_action"}, 0))
+}
+
+func TestSaveSamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory-bank", "samples.json")
+	err := SaveSamples(path, []*Sample{{ID: "d1", Action: "exchange.close_position", Situation: "profit at resistance", Output: "{}", Source: SampleSourceJournal}})
+	assert.NoError(t, err)
+
+	samples, err := LoadSamples(path)
+	assert.NoError(t, err)
+	assert.Len(t, samples, 1)
+	assert.Equal(t, SampleSourceJournal, samples[0].Source)
+
+	bank := NewSampleBank()
+	bank.Add(samples...)
+	assert.True(t, bank.Has("d1"))
+	assert.False(t, bank.Has("d2"))
+	assert.False(t, bank.Has(""))
+}
+
+func TestLoadSamples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "samples.json")
+	err := os.WriteFile(path, []byte(`[{"action":"exchange.no_action","situation":"flat market","output":"{\"name\":\"exchange.no_action\"}"}]`), 0644)
+	assert.NoError(t, err)
+
+	samples, err := LoadSamples(path)
+	assert.NoError(t, err)
+	assert.Len(t, samples, 1)
+	assert.Equal(t, SampleSourceFile, samples[0].Source)
+
+	_, err = LoadSamples(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/yubing744/trading-gpt/pkg/env/bridge"
+	"github.com/yubing744/trading-gpt/pkg/metrics"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// recoveryTimeout bounds the exchange queries of a recovery dump, the exchange may be what failed
+const recoveryTimeout = 10 * time.Second
+
+// setupRecovery dumps the open risk when repeated exchange errors open the retry circuit breaker
+func (s *Strategy) setupRecovery(ctx context.Context) error {
+	cfg := &s.Recovery
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.Dir == "" {
+		cfg.Dir = "memory-bank/recovery"
+	}
+
+	if s.exchange != nil {
+		s.exchange.OnExchangeErrors(func() {
+			// Apart from the failing exchange call, which holds up its caller
+			go s.dumpRecovery(ctx, "repeated exchange errors opened the circuit breaker, exchange calls are paused")
+		})
+	}
+
+	log.WithField("config", cfg).Info("Recovery dumps enabled")
+	return nil
+}
+
+// dumpRecovery writes the open position, its stop-loss and take-profit, the open orders and the manual close
+// steps to a recovery file, and sends them to the admins so a human can take over
+func (s *Strategy) dumpRecovery(ctx context.Context, reason string) {
+	if !s.Recovery.Enabled || s.exchange == nil {
+		return
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, recoveryTimeout)
+	report := s.exchange.RecoveryReport(queryCtx, reason)
+	cancel()
+
+	msg := report.String()
+	path := filepath.Join(s.Recovery.Dir, fmt.Sprintf("recovery-%s-%s.txt", s.Symbol, report.CreatedAt.UTC().Format("20060102T150405Z")))
+
+	err := os.MkdirAll(s.Recovery.Dir, 0o755)
+	if err == nil {
+		err = os.WriteFile(path, []byte(msg), 0o600)
+	}
+	if err != nil {
+		log.WithError(err).WithField("path", path).Error("write recovery file failed")
+		msg += fmt.Sprintf("Writing the recovery file failed: %s", err.Error())
+	} else {
+		msg += fmt.Sprintf("Saved to %s", path)
+	}
+
+	log.Warn(msg)
+	s.notifyAdmins(ctx, ttypes.SeverityCritical, msg)
+	s.publishBridge(ctx, &bridge.OutboundMessage{Type: "recovery", Text: msg})
+}
+
+// recoverPanic isolates a panic of the deferring handler from the event loop, and dumps the open risk since
+// the decision cycle it was in may have left a position unattended
+func (s *Strategy) recoverPanic(ctx context.Context, subsystem string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	log.WithField("subsystem", subsystem).WithField("panic", r).Errorf("subsystem panicked:\n%s", debug.Stack())
+	metrics.RecordPanic(subsystem)
+
+	s.dumpRecovery(ctx, fmt.Sprintf("panic in the %s subsystem: %v", subsystem, r))
+}
+
+// isolatePanic isolates a panic of a subsystem that holds no open risk, such as the reflection pipeline
+func (s *Strategy) isolatePanic(subsystem string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	log.WithField("subsystem", subsystem).WithField("panic", r).Errorf("subsystem panicked:\n%s", debug.Stack())
+	metrics.RecordPanic(subsystem)
+}
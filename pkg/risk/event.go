@@ -0,0 +1,27 @@
+package risk
+
+import (
+	"fmt"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// EventRiskLimitHit is emitted when a loss limit pauses new entries
+const EventRiskLimitHit = "risk_limit_hit"
+
+// RiskLimitHitEvent reports the loss limit that paused new entries
+type RiskLimitHitEvent struct {
+	ttypes.Event
+	Hit LimitHit
+}
+
+func NewRiskLimitHitEvent(hit LimitHit) *RiskLimitHitEvent {
+	return &RiskLimitHitEvent{
+		Event: *ttypes.NewEvent(EventRiskLimitHit, hit),
+		Hit:   hit,
+	}
+}
+
+func (e *RiskLimitHitEvent) ToPrompts() []string {
+	return []string{fmt.Sprintf("Risk limit hit: %s. New positions are paused, manage or close the open position only.", e.Hit.String())}
+}
@@ -0,0 +1,181 @@
+package risk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status tells whether new entries are allowed by the risk manager
+type Status string
+
+const (
+	StatusActive Status = "active"
+	StatusPaused Status = "paused" // New entries halted until the limit clears or an operator resumes
+)
+
+// Limits hit by the account
+const (
+	LimitDailyLoss   = "daily_loss"
+	LimitMaxDrawdown = "max_drawdown"
+)
+
+// Limits are the losses halting new entries, in percent of the equity, 0 disables a limit
+type Limits struct {
+	DailyLossPercent   float64 // Loss since the start of the UTC day
+	MaxDrawdownPercent float64 // Fall from the equity peak
+}
+
+// LimitHit is a loss limit reached by the account
+type LimitHit struct {
+	Limit            string
+	LossPercent      float64
+	ThresholdPercent float64
+	Equity           float64
+	At               time.Time
+}
+
+func (h *LimitHit) String() string {
+	switch h.Limit {
+	case LimitDailyLoss:
+		return fmt.Sprintf("daily loss of %.2f%% reached the %.2f%% limit, equity %.2f", h.LossPercent, h.ThresholdPercent, h.Equity)
+	default:
+		return fmt.Sprintf("drawdown of %.2f%% from the equity peak reached the %.2f%% limit, equity %.2f", h.LossPercent, h.ThresholdPercent, h.Equity)
+	}
+}
+
+// Manager tracks the equity of the account, its balance including the realized PnL plus the unrealized PnL of the
+// open positions, and pauses new entries when a loss limit is hit. A daily loss pause clears at the next UTC day,
+// a drawdown pause only when an operator resumes.
+type Manager struct {
+	limits Limits
+
+	mu         sync.Mutex
+	status     Status
+	hit        *LimitHit
+	day        time.Time // UTC day of dayStart
+	dayStart   float64   // Equity at the start of the day
+	peak       float64
+	equity     float64
+	unrealized float64
+	realized   float64 // Realized PnL of the day
+}
+
+func NewManager(limits Limits) *Manager {
+	return &Manager{
+		limits: limits,
+		status: StatusActive,
+	}
+}
+
+// Update records the balance and the unrealized PnL, it returns the limit hit when it pauses the entries
+func (m *Manager) Update(now time.Time, balance float64, unrealized float64) *LimitHit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	equity := balance + unrealized
+	m.equity = equity
+	m.unrealized = unrealized
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(m.day) {
+		m.day = day
+		m.dayStart = equity
+		m.realized = 0
+
+		if m.hit != nil && m.hit.Limit == LimitDailyLoss {
+			m.status = StatusActive
+			m.hit = nil
+		}
+	}
+
+	if equity > m.peak {
+		m.peak = equity
+	}
+
+	if m.status == StatusPaused {
+		return nil
+	}
+
+	if hit := m.check(now); hit != nil {
+		m.status = StatusPaused
+		m.hit = hit
+		return hit
+	}
+
+	return nil
+}
+
+func (m *Manager) check(now time.Time) *LimitHit {
+	if m.limits.DailyLossPercent > 0 && m.dayStart > 0 {
+		loss := (m.dayStart - m.equity) / m.dayStart * 100
+		if loss >= m.limits.DailyLossPercent {
+			return &LimitHit{Limit: LimitDailyLoss, LossPercent: loss, ThresholdPercent: m.limits.DailyLossPercent, Equity: m.equity, At: now}
+		}
+	}
+
+	if m.limits.MaxDrawdownPercent > 0 && m.peak > 0 {
+		drawdown := (m.peak - m.equity) / m.peak * 100
+		if drawdown >= m.limits.MaxDrawdownPercent {
+			return &LimitHit{Limit: LimitMaxDrawdown, LossPercent: drawdown, ThresholdPercent: m.limits.MaxDrawdownPercent, Equity: m.equity, At: now}
+		}
+	}
+
+	return nil
+}
+
+// RecordRealized adds the PnL of a closed trade to the realized PnL of the day
+func (m *Manager) RecordRealized(pnl float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.realized += pnl
+}
+
+// Status returns whether new entries are allowed
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.status
+}
+
+// Hit returns the limit pausing the entries, nil while active
+func (m *Manager) Hit() *LimitHit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.hit
+}
+
+// Resume allows entries again on an operator's request, measuring the limits from the current equity. It returns
+// false when the entries were not paused.
+func (m *Manager) Resume() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.status != StatusPaused {
+		return false
+	}
+
+	m.status = StatusActive
+	m.hit = nil
+	m.peak = m.equity
+	m.dayStart = m.equity
+
+	return true
+}
+
+func (m *Manager) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dayPnL := m.equity - m.dayStart
+	drawdown := 0.0
+	if m.peak > 0 {
+		drawdown = (m.peak - m.equity) / m.peak * 100
+	}
+
+	return fmt.Sprintf("%s, equity %.2f (unrealized %+.2f), day PnL %+.2f (realized %+.2f), drawdown %.2f%% from the %.2f peak",
+		m.status, m.equity, m.unrealized, dayPnL, m.realized, drawdown, m.peak)
+}
@@ -0,0 +1,59 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerDailyLoss(t *testing.T) {
+	manager := NewManager(Limits{DailyLossPercent: 5, MaxDrawdownPercent: 20})
+	day := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, manager.Update(day, 1000, 0))
+	assert.Nil(t, manager.Update(day.Add(time.Hour), 1000, -40))
+
+	// The unrealized loss counts toward the limit
+	hit := manager.Update(day.Add(2*time.Hour), 980, -30)
+	assert.NotNil(t, hit)
+	assert.Equal(t, LimitDailyLoss, hit.Limit)
+	assert.InDelta(t, 5, hit.LossPercent, 1e-9)
+	assert.Equal(t, StatusPaused, manager.Status())
+	assert.Contains(t, hit.String(), "daily loss of 5.00% reached the 5.00% limit")
+
+	// Reported once while paused
+	assert.Nil(t, manager.Update(day.Add(3*time.Hour), 900, 0))
+	assert.Equal(t, StatusPaused, manager.Status())
+
+	// Cleared by the next UTC day
+	assert.Nil(t, manager.Update(day.Add(20*time.Hour), 950, 0))
+	assert.Equal(t, StatusActive, manager.Status())
+	assert.Nil(t, manager.Hit())
+}
+
+func TestManagerMaxDrawdown(t *testing.T) {
+	manager := NewManager(Limits{MaxDrawdownPercent: 10})
+	day := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	assert.Nil(t, manager.Update(day, 1000, 0))
+	assert.Nil(t, manager.Update(day.Add(24*time.Hour), 1200, 0))
+
+	hit := manager.Update(day.Add(48*time.Hour), 1080, 0)
+	assert.NotNil(t, hit)
+	assert.Equal(t, LimitMaxDrawdown, hit.Limit)
+	assert.InDelta(t, 10, hit.LossPercent, 1e-9)
+
+	// A new day does not clear a drawdown pause
+	assert.Nil(t, manager.Update(day.Add(72*time.Hour), 1080, 0))
+	assert.Equal(t, StatusPaused, manager.Status())
+
+	manager.RecordRealized(-20)
+	assert.Equal(t, "paused, equity 1080.00 (unrealized +0.00), day PnL +0.00 (realized -20.00), drawdown 10.00% from the 1200.00 peak", manager.String())
+
+	// Resuming measures the drawdown from the current equity
+	assert.True(t, manager.Resume())
+	assert.False(t, manager.Resume())
+	assert.Nil(t, manager.Update(day.Add(73*time.Hour), 1000, 0))
+	assert.Equal(t, StatusActive, manager.Status())
+}
@@ -0,0 +1,104 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// setupRiskBudget shrinks the size of entries as the account draws down from its equity peak
+func (s *Strategy) setupRiskBudget(ctx context.Context) error {
+	cfg := &s.RiskBudget
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if len(cfg.Steps) == 0 {
+		cfg.Steps = []config.RiskBudgetStepConfig{
+			{DrawdownPercent: 5, Scale: 0.5},
+			{DrawdownPercent: 10, Scale: 0.25},
+		}
+	}
+
+	steps := make([]utils.RiskBudgetStep, 0, len(cfg.Steps))
+	for _, step := range cfg.Steps {
+		if step.DrawdownPercent <= 0 || step.Scale <= 0 || step.Scale > 1 {
+			return errors.Errorf("invalid risk budget step, drawdown_percent must be positive and scale in (0, 1]: %+v", step)
+		}
+
+		steps = append(steps, utils.RiskBudgetStep{DrawdownPercent: step.DrawdownPercent, Scale: step.Scale})
+	}
+
+	s.riskBudget = utils.NewRiskBudget(steps)
+	s.updateRiskBudget(ctx)
+
+	log.WithField("steps", cfg.Steps).WithField("budget", s.riskBudget.String()).Info("Drawdown risk budget enabled")
+	return nil
+}
+
+// accountEquity returns the total quote balance of the account
+func (s *Strategy) accountEquity(ctx context.Context) (float64, error) {
+	account, err := s.session.UpdateAccount(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "update account error")
+	}
+
+	balance, ok := account.Balance(s.Market.QuoteCurrency)
+	if !ok {
+		return 0, errors.Errorf("no %s balance", s.Market.QuoteCurrency)
+	}
+
+	return balance.Total().Float64(), nil
+}
+
+// updateRiskBudget records the account equity and tells the admins when the drawdown changed the risk budget
+func (s *Strategy) updateRiskBudget(ctx context.Context) {
+	if s.riskBudget == nil {
+		return
+	}
+
+	equity, err := s.accountEquity(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to query the equity for the risk budget")
+		return
+	}
+
+	if !s.riskBudget.Update(equity) {
+		return
+	}
+
+	s.applySizeScale()
+	s.notifyAdmins(ctx, ttypes.SeverityWarning, fmt.Sprintf("📉 Risk budget of %s changed: %s.", s.Symbol, s.riskBudget.String()))
+}
+
+// applySizeScale sizes the entries of the exchange by the loss streak throttle and the drawdown risk budget
+func (s *Strategy) applySizeScale() {
+	if s.exchange == nil {
+		return
+	}
+
+	scale := 1.0
+	if s.lossStreak != nil && s.lossStreak.Throttled() {
+		scale = s.LossStreak.SizeScale
+	}
+
+	if s.riskBudget != nil {
+		scale = scale * s.riskBudget.Scale()
+	}
+
+	s.exchange.SetSizeScale(scale)
+}
+
+// riskBudgetMsg describes the risk budget for the decision prompt, empty while the risk is not reduced
+func (s *Strategy) riskBudgetMsg() string {
+	if s.riskBudget == nil || s.riskBudget.Scale() >= 1 {
+		return ""
+	}
+
+	return fmt.Sprintf("Risk budget: %s, entries are sized down accordingly until the equity recovers.", s.riskBudget.String())
+}
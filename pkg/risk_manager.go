@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	"github.com/yubing744/trading-gpt/pkg/risk"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// setupRisk halts new entries when the daily loss or the drawdown of the equity hits its limit, checked on every
+// kline close with the unrealized PnL of the position
+func (s *Strategy) setupRisk(ctx context.Context) error {
+	cfg := &s.Risk
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.DailyLossPercent == 0 {
+		cfg.DailyLossPercent = 5
+	}
+	if cfg.MaxDrawdownPercent == 0 {
+		cfg.MaxDrawdownPercent = 15
+	}
+
+	s.riskManager = risk.NewManager(risk.Limits{
+		DailyLossPercent:   cfg.DailyLossPercent,
+		MaxDrawdownPercent: cfg.MaxDrawdownPercent,
+	})
+
+	s.world.OnEvent(func(evt ttypes.IEvent) {
+		switch evt.GetType() {
+		case "kline_changed":
+			if klineWindow, ok := evt.GetData().(*types.KLineWindow); ok && klineWindow.Len() > 0 {
+				s.updateRisk(ctx, klineWindow.GetClose().Float64())
+			}
+		case exchange.EventPositionClosed:
+			if posData, ok := evt.GetData().(exchange.PositionClosedEventData); ok {
+				s.riskManager.RecordRealized(posData.ProfitAndLoss)
+			}
+		}
+	})
+
+	log.WithField("config", cfg).Info("Risk manager enabled")
+	return nil
+}
+
+// updateRisk records the equity, pausing entries and emitting a risk_limit_hit event when a limit is hit
+func (s *Strategy) updateRisk(ctx context.Context, price float64) {
+	balance, err := s.accountEquity(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to query the equity for the risk manager")
+		return
+	}
+
+	unrealized := 0.0
+	if s.Position != nil && !s.Position.GetBase().IsZero() {
+		unrealized = s.Position.GetBase().Float64() * (price - s.Position.AverageCost.Float64())
+	}
+
+	hit := s.riskManager.Update(time.Now(), balance, unrealized)
+	if hit == nil {
+		return
+	}
+
+	msg := fmt.Sprintf("🛑 Risk limit hit on %s: %s. New positions are paused", s.Symbol, hit.String())
+	if hit.Limit == risk.LimitDailyLoss {
+		msg += " until the next UTC day or /resume."
+	} else {
+		msg += " until an operator sends /resume."
+	}
+
+	log.Warn(msg)
+	s.notifyAdmins(ctx, ttypes.SeverityCritical, msg)
+	s.world.Emit(risk.NewRiskLimitHitEvent(*hit))
+}
+
+// checkRisk returns why an entry is blocked by the risk manager, empty when it may execute
+func (s *Strategy) checkRisk(actionName string) string {
+	if s.riskManager == nil || !isEntryAction(actionName) {
+		return ""
+	}
+
+	hit := s.riskManager.Hit()
+	if hit == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("new positions are paused by the risk manager, %s", hit.String())
+}
+
+// riskMsg describes the risk pause for the decision prompt, empty while entries are allowed
+func (s *Strategy) riskMsg() string {
+	if s.riskManager == nil || s.riskManager.Status() != risk.StatusPaused {
+		return ""
+	}
+
+	return fmt.Sprintf("Risk manager: %s. New positions are paused, only manage or close the open position.", s.riskManager.String())
+}
@@ -0,0 +1,156 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/journal"
+	"github.com/yubing744/trading-gpt/pkg/prompt"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// Words of a journal decision's reasoning kept as the sample situation
+const journalSampleWords = 60
+
+// setupSampleBank builds the few-shot sample bank from the sample file and the decisions of winning trades
+// in the journal, the builtin samples of the commands are added as the commands are offered
+func (s *Strategy) setupSampleBank(ctx context.Context) error {
+	cfg := &s.SampleBank
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.MaxJournal == 0 {
+		cfg.MaxJournal = 20
+	}
+	if cfg.PerAction == 0 {
+		cfg.PerAction = 1
+	}
+	if cfg.Mining.Enabled && cfg.Path == "" {
+		cfg.Path = fmt.Sprintf("memory-bank/samples-%s.json", s.Symbol)
+	}
+
+	s.sampleBank = prompt.NewSampleBank()
+
+	if cfg.Path != "" {
+		samples, err := prompt.LoadSamples(cfg.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Wrap(err, "load sample bank error")
+		}
+		s.sampleBank.Add(samples...)
+	}
+
+	if cfg.FromJournal {
+		if s.journal == nil {
+			log.Warn("Journal samples require the decision journal, using the configured samples only")
+		} else {
+			s.refreshJournalSamples()
+		}
+	}
+
+	log.WithField("samples", s.sampleBank.Len()).Info("Sample bank enabled")
+	return s.setupSampleMining(ctx)
+}
+
+// refreshJournalSamples adds the decisions of recent winning trades to the sample bank
+func (s *Strategy) refreshJournalSamples() {
+	if s.sampleBank == nil || !s.SampleBank.FromJournal || s.journal == nil {
+		return
+	}
+
+	entries, err := s.journal.LoadEntries()
+	if err != nil {
+		log.WithError(err).Warn("Failed to load journal for the sample bank")
+		return
+	}
+
+	s.sampleBank.Add(journalSamples(entries, s.Symbol, s.SampleBank.MaxJournal)...)
+}
+
+// selectSamples renders the samples most relevant to the situation for the available commands
+func (s *Strategy) selectSamples(situation string, actions []*ttypes.ActionDesc) []string {
+	if s.sampleBank == nil {
+		return nil
+	}
+
+	s.sampleBank.Add(builtinSamples(actions)...)
+
+	names := make([]string, 0, len(actions))
+	for _, ac := range actions {
+		names = append(names, ac.Name)
+	}
+
+	examples := make([]string, 0)
+	for _, sample := range s.sampleBank.Select(situation, names, s.SampleBank.PerAction) {
+		examples = append(examples, sample.String())
+	}
+
+	return examples
+}
+
+// builtinSamples converts the samples declared with the commands
+func builtinSamples(actions []*ttypes.ActionDesc) []*prompt.Sample {
+	samples := make([]*prompt.Sample, 0)
+	for _, ac := range actions {
+		for _, sample := range ac.Samples {
+			samples = append(samples, &prompt.Sample{
+				Action:    ac.Name,
+				Situation: strings.Join(sample.Input, "; "),
+				Output:    fmt.Sprintf(`{"name": "%s", "args": %s}`, ac.Name, strings.Join(sample.Output, "")),
+				Source:    prompt.SampleSourceBuiltin,
+			})
+		}
+	}
+
+	return samples
+}
+
+// journalSamples returns the decisions of the symbol's most recent winning trades as samples. Faded trades
+// are skipped, their outcome belongs to the inverse of the decision.
+func journalSamples(entries []*journal.Entry, symbol string, limit int) []*prompt.Sample {
+	winners := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Kind == journal.KindTradeClosed && entry.Trade != nil && entry.Trade.DecisionID != "" &&
+			entry.Trade.PnL > 0 && !entry.Trade.Faded {
+			winners[entry.Trade.DecisionID] = true
+		}
+	}
+
+	samples := make([]*prompt.Sample, 0)
+	for i := len(entries) - 1; i >= 0 && len(samples) < limit; i-- {
+		entry := entries[i]
+		if entry.Kind != journal.KindDecision || entry.Symbol != symbol || !winners[entry.ID] || entry.Reasoning == "" {
+			continue
+		}
+
+		samples = append(samples, journalSample(entry))
+	}
+
+	return samples
+}
+
+// journalSample converts a journal decision to a sample, situated by the start of its reasoning
+func journalSample(entry *journal.Entry) *prompt.Sample {
+	action := &ttypes.Action{Name: entry.Action, Args: entry.Args}
+	return &prompt.Sample{
+		ID:        entry.ID,
+		Action:    entry.Action,
+		Situation: firstWords(entry.Reasoning, journalSampleWords),
+		Output:    action.JSON(),
+		Source:    prompt.SampleSourceJournal,
+	}
+}
+
+// firstWords returns the first n words of the text
+func firstWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+
+	return strings.Join(words[:n], " ") + " ..."
+}
@@ -0,0 +1,212 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/journal"
+	"github.com/yubing744/trading-gpt/pkg/prompt"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// Characters of the decision ID used to refer to a sample candidate in chat
+const sampleRefLength = 8
+
+// setupSampleMining schedules mining the journal for sample candidates, which the operator approves via chat
+func (s *Strategy) setupSampleMining(ctx context.Context) error {
+	cfg := &s.SampleBank.Mining
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if s.journal == nil {
+		log.Warn("Sample mining requires the decision journal, mining disabled")
+		return nil
+	}
+
+	if cfg.Interval == "" {
+		cfg.Interval = types.Interval1d
+	}
+	if cfg.MinPnLPercent == 0 {
+		cfg.MinPnLPercent = 0.5
+	}
+	if cfg.MinReasoningWords == 0 {
+		cfg.MinReasoningWords = 30
+	}
+
+	s.sampleRejected = make(map[string]bool)
+
+	go func() {
+		ticker := time.NewTicker(cfg.Interval.Duration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.proposeSamples(ctx)
+			}
+		}
+	}()
+
+	log.WithField("interval", cfg.Interval).Info("Sample mining scheduled")
+	return nil
+}
+
+// proposeSamples mines new sample candidates and asks the admins to approve them
+func (s *Strategy) proposeSamples(ctx context.Context) {
+	added, err := s.mineSamples()
+	if err != nil {
+		log.WithError(err).Warn("Failed to mine samples")
+		return
+	}
+
+	if len(added) == 0 {
+		return
+	}
+
+	s.notifyAdmins(ctx, ttypes.SeverityInfo, fmt.Sprintf("🧪 %d new few-shot sample candidates for %s:\n%s\nReply /samples approve <id> or /samples reject <id>.",
+		len(added), s.Symbol, formatSampleCandidates(added)))
+}
+
+// mineSamples adds the mined decisions that are not in the bank, pending or rejected to the candidates,
+// returning the new ones
+func (s *Strategy) mineSamples() ([]*prompt.Sample, error) {
+	entries, err := s.journal.LoadEntries()
+	if err != nil {
+		return nil, errors.Wrap(err, "load journal error")
+	}
+
+	cfg := s.SampleBank.Mining
+	mined := journal.MineDecisions(entries, journal.MiningFilter{
+		Symbol:            s.Symbol,
+		MinPnLPercent:     cfg.MinPnLPercent,
+		MinRMultiple:      cfg.MinRMultiple,
+		MinReasoningWords: cfg.MinReasoningWords,
+	})
+
+	s.sampleMu.Lock()
+	defer s.sampleMu.Unlock()
+
+	added := make([]*prompt.Sample, 0)
+	for _, decision := range mined {
+		id := decision.Decision.ID
+		if s.sampleRejected[id] || s.sampleBank.Has(id) || s.findSampleCandidate(id) >= 0 {
+			continue
+		}
+
+		sample := journalSample(decision.Decision)
+		s.sampleCandidates = append(s.sampleCandidates, sample)
+		added = append(added, sample)
+	}
+
+	return added, nil
+}
+
+// findSampleCandidate returns the index of the candidate whose decision ID starts with ref, -1 if none,
+// the caller must hold sampleMu
+func (s *Strategy) findSampleCandidate(ref string) int {
+	for i, candidate := range s.sampleCandidates {
+		if ref != "" && strings.HasPrefix(candidate.ID, ref) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// handleSamples lists the pending sample candidates, mining new ones first, or approves or rejects one:
+// /samples, /samples approve <id>, /samples reject <id>
+func (s *Strategy) handleSamples(ctx context.Context, chatSession ttypes.ISession, args string) {
+	if s.sampleBank == nil || !s.SampleBank.Mining.Enabled || s.journal == nil {
+		s.replyMsg(ctx, chatSession, "Sample mining is not enabled, it requires the sample bank and the journal.")
+		return
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		if _, err := s.mineSamples(); err != nil {
+			s.replyMsg(ctx, chatSession, fmt.Sprintf("mine samples error: %s", err.Error()))
+			return
+		}
+
+		s.sampleMu.Lock()
+		pending := append([]*prompt.Sample{}, s.sampleCandidates...)
+		s.sampleMu.Unlock()
+
+		if len(pending) == 0 {
+			s.replyMsg(ctx, chatSession, "No few-shot sample candidates pending.")
+			return
+		}
+
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("🧪 Pending few-shot sample candidates:\n%s\nReply /samples approve <id> or /samples reject <id>.",
+			formatSampleCandidates(pending)))
+		return
+	}
+
+	if len(fields) != 2 || (fields[0] != "approve" && fields[0] != "reject") {
+		s.replyMsg(ctx, chatSession, "Usage: /samples, /samples approve <id> or /samples reject <id>")
+		return
+	}
+
+	s.sampleMu.Lock()
+	defer s.sampleMu.Unlock()
+
+	i := s.findSampleCandidate(fields[1])
+	if i < 0 {
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("No pending sample candidate %s.", fields[1]))
+		return
+	}
+	candidate := s.sampleCandidates[i]
+
+	if fields[0] == "reject" {
+		s.sampleRejected[candidate.ID] = true
+		s.sampleCandidates = append(s.sampleCandidates[:i], s.sampleCandidates[i+1:]...)
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("Sample %s rejected.", sampleRef(candidate)))
+		return
+	}
+
+	if err := appendSampleFile(s.SampleBank.Path, candidate); err != nil {
+		log.WithError(err).Warn("save approved sample error")
+		s.replyMsg(ctx, chatSession, fmt.Sprintf("approve sample error: %s", err.Error()))
+		return
+	}
+
+	s.sampleBank.Add(candidate)
+	s.sampleCandidates = append(s.sampleCandidates[:i], s.sampleCandidates[i+1:]...)
+	s.replyMsg(ctx, chatSession, fmt.Sprintf("✅ Sample %s added to the few-shot sample bank.", sampleRef(candidate)))
+}
+
+// appendSampleFile adds the sample to the sample file, creating it if it doesn't exist
+func appendSampleFile(path string, sample *prompt.Sample) error {
+	samples, err := prompt.LoadSamples(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return prompt.SaveSamples(path, append(samples, sample))
+}
+
+func sampleRef(sample *prompt.Sample) string {
+	if len(sample.ID) > sampleRefLength {
+		return sample.ID[:sampleRefLength]
+	}
+
+	return sample.ID
+}
+
+func formatSampleCandidates(samples []*prompt.Sample) string {
+	lines := make([]string, 0, len(samples))
+	for _, sample := range samples {
+		lines = append(lines, fmt.Sprintf("[%s] %s", sampleRef(sample), sample.String()))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/env/divergence"
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	"github.com/yubing744/trading-gpt/pkg/journal"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// Market states the sampling parameters of decisions are chosen by
+const (
+	SamplingStateRisk     = "risk"
+	SamplingStatePosition = "position"
+	SamplingStateFlat     = "flat"
+)
+
+func (s *Strategy) setupSampling(ctx context.Context) error {
+	cfg := &s.Sampling
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if len(cfg.RiskEvents) == 0 {
+		cfg.RiskEvents = []string{exchange.EventIndicatorAlert, exchange.EventFlatByWarning, divergence.EventPriceDivergence}
+	}
+	if cfg.RiskHold == 0 {
+		cfg.RiskHold = types.Duration(time.Minute * 30)
+	}
+	if cfg.Risk.Temperature <= 0 {
+		cfg.Risk.Temperature = 0.1
+	}
+	if cfg.Position.Temperature <= 0 {
+		cfg.Position.Temperature = 0.2
+	}
+	if cfg.Flat.Temperature <= 0 {
+		cfg.Flat.Temperature = 0.5
+	}
+
+	riskEvents := make(map[string]bool, len(cfg.RiskEvents))
+	for _, eventType := range cfg.RiskEvents {
+		riskEvents[eventType] = true
+	}
+
+	s.world.OnEvent(func(evt ttypes.IEvent) {
+		if riskEvents[evt.GetType()] {
+			s.riskEventAt.Store(time.Now().UnixMilli())
+		}
+	})
+
+	log.WithField("config", cfg).Info("Sampling schedule enabled")
+	return nil
+}
+
+// withSampling returns a context carrying the sampling parameters for the current market state,
+// the context unchanged when the schedule is disabled
+func (s *Strategy) withSampling(ctx context.Context) context.Context {
+	if !s.Sampling.Enabled {
+		return ctx
+	}
+
+	state, params := SamplingStateFlat, s.Sampling.Flat
+
+	riskEventAt := time.UnixMilli(s.riskEventAt.Load())
+	if time.Since(riskEventAt) < s.Sampling.RiskHold.Duration() || (s.deadMan != nil && s.deadMan.Tripped()) {
+		state, params = SamplingStateRisk, s.Sampling.Risk
+	} else if s.Position != nil && !s.Position.GetBase().IsZero() {
+		state, params = SamplingStatePosition, s.Sampling.Position
+	}
+
+	log.
+		WithField("state", state).
+		WithField("temperature", params.Temperature).
+		WithField("top_p", params.TopP).
+		Info("sampling parameters chosen by market state")
+
+	return ttypes.WithSampling(ctx, &ttypes.Sampling{
+		State:       state,
+		Temperature: params.Temperature,
+		TopP:        params.TopP,
+	})
+}
+
+// journalSampling returns the sampling parameters of the decision cycle to record in the journal
+func journalSampling(ctx context.Context) *journal.Sampling {
+	sampling := ttypes.SamplingFromContext(ctx)
+	if sampling == nil {
+		return nil
+	}
+
+	return &journal.Sampling{
+		State:       sampling.State,
+		Temperature: sampling.Temperature,
+		TopP:        sampling.TopP,
+	}
+}
@@ -0,0 +1,180 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	"github.com/yubing744/trading-gpt/pkg/risk"
+)
+
+func scenarioConfig() pipelineConfig {
+	return pipelineConfig{
+		Equity:         10000,
+		MaxRiskPercent: 10,
+		Risk:           config.RiskConfig{Enabled: true, DailyLossPercent: 5, MaxDrawdownPercent: 15},
+		FlipGuard:      config.FlipGuardConfig{Enabled: true, Window: 5, Confirmations: 2, MinConfidence: 0.8},
+		PreTrade:       config.PreTradeConfig{Enabled: true, RequireStopLoss: true, MaxLossPercent: 8},
+	}
+}
+
+// chop returns n klines closing alternately above and below the price
+func chop(price float64, n int) []bar {
+	bars := make([]bar, 0, n)
+
+	for i := 0; i < n; i++ {
+		open, close := price-0.5, price+0.5
+		if i%2 == 1 {
+			open, close = close, open
+		}
+		bars = append(bars, bar{Open: open, High: price + 1, Low: price - 1, Close: close})
+	}
+
+	return bars
+}
+
+func TestScenarioStrongUptrend(t *testing.T) {
+	p := newPipeline(t, scenarioConfig(), map[int]string{
+		0:  `{"thoughts": {"plan": "buy the breakout"}, "action": {"name": "exchange.open_long_position", "args": {}}}`,
+		1:  `{"thoughts": {"plan": "buy the breakout with a stop"}, "action": {"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "101.004", "risk_percent": "1%"}}}`,
+		10: `{"thoughts": {"plan": "take the profit"}, "action": {"name": "exchange.close_position", "args": {}}}`,
+	})
+
+	p.run(trend(100, 2, 12))
+
+	assert.Len(t, p.llm.prompts, 12)
+
+	// An entry without a stop loss is rejected
+	if assert.Len(t, p.rejections[0], 1) {
+		assert.Contains(t, p.rejections[0][0], "rejected by pre-trade risk limits: a stop loss is required")
+	}
+
+	// The stop loss is snapped to the tick size and attached to the entry
+	if entries := p.entries(); assert.Len(t, entries, 1) {
+		assert.Equal(t, types.SideTypeBuy, entries[0].Side)
+		assert.InDelta(t, 101.0, entries[0].StopPrice.Float64(), 1e-9)
+	}
+
+	assert.True(t, p.strategy.Position.GetBase().IsZero())
+	if assert.Len(t, p.venue.closes, 1) {
+		assert.Equal(t, "agent", p.venue.closes[0].Reason)
+		assert.Greater(t, p.venue.closes[0].PnL, 0.0)
+	}
+}
+
+func TestScenarioCrash(t *testing.T) {
+	bars := trend(100, 0.5, 3)
+	bars = append(bars, bar{Open: 101.5, High: 101.6, Low: 90, Close: 91})
+	bars = append(bars, trend(91, -1, 4)...)
+
+	p := newPipeline(t, scenarioConfig(), map[int]string{
+		2: `{"thoughts": {"plan": "buy the trend"}, "action": {"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "95.5", "risk_percent": "6"}}}`,
+		3: `{"thoughts": {"plan": "short the crash"}, "action": {"name": "exchange.open_short_position", "args": {"stop_loss_trigger_price": "95", "risk_percent": "1"}}}`,
+		5: `{"thoughts": {"plan": "buy the dip"}, "action": {"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "85", "risk_percent": "1"}}}`,
+		8: `{"thoughts": {"plan": "short the trend"}, "action": {"name": "exchange.open_short_position", "args": {"stop_loss_trigger_price": "90", "risk_percent": "1"}}}`,
+	})
+
+	p.run(bars)
+
+	// The stop loss filled inside the crash kline
+	if assert.Len(t, p.venue.closes, 1) {
+		assert.Equal(t, "stop_loss", p.venue.closes[0].Reason)
+		assert.Equal(t, 95.5, p.venue.closes[0].ExitPrice)
+	}
+
+	// The daily loss limit pauses every later entry
+	manager := p.strategy.riskManager
+	assert.Equal(t, risk.StatusPaused, manager.Status())
+	if hit := manager.Hit(); assert.NotNil(t, hit) {
+		assert.Equal(t, risk.LimitDailyLoss, hit.Limit)
+	}
+
+	assert.Len(t, p.entries(), 1)
+	for _, cycle := range []int{3, 5} {
+		if assert.Len(t, p.rejections[cycle], 1) {
+			assert.Contains(t, p.rejections[cycle][0], "new positions are paused by the risk manager, daily loss of")
+		}
+	}
+
+	// Entries are allowed again once an operator resumes
+	assert.True(t, p.strategy.resumeTrading(context.Background(), "operator"))
+	p.decide(context.Background())
+
+	assert.Empty(t, p.rejections[p.cycle])
+	if entries := p.entries(); assert.Len(t, entries, 2) {
+		assert.Equal(t, types.SideTypeSell, entries[1].Side)
+	}
+}
+
+func TestScenarioChop(t *testing.T) {
+	p := newPipeline(t, scenarioConfig(), map[int]string{
+		0: `{"thoughts": {"plan": "range low"}, "action": {"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "97", "confidence": "0.6"}}}`,
+		1: `{"thoughts": {"plan": "range high"}, "action": {"name": "exchange.open_short_position", "args": {"stop_loss_trigger_price": "103", "confidence": "0.5"}}}`,
+		3: `{"thoughts": {"plan": "range high"}, "action": {"name": "exchange.open_short_position", "args": {"stop_loss_trigger_price": "103", "confidence": "0.5"}}}`,
+		4: `{"thoughts": {"plan": "range high again"}, "action": {"name": "exchange.open_short_position", "args": {"stop_loss_trigger_price": "103", "confidence": "0.5"}}}`,
+		5: `{"thoughts": {"plan": "strong reversal"}, "actions": [{"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "97", "confidence": "0.9"}}]}`,
+	})
+
+	p.run(chop(100, 8))
+
+	// Reversals within the cooldown wait for consecutive confirmations
+	if assert.Len(t, p.rejections[1], 1) {
+		assert.Contains(t, p.rejections[1][0], "needs 2 consecutive decision cycles asking for it (1 so far)")
+	}
+	if assert.Len(t, p.rejections[3], 1) {
+		assert.Contains(t, p.rejections[3][0], "(1 so far)", "a cycle without the request starts the count over")
+	}
+	assert.Empty(t, p.rejections[4])
+
+	// A confident signal reverses at once
+	assert.Empty(t, p.rejections[5])
+
+	sides := make([]types.SideType, 0)
+	for _, entry := range p.entries() {
+		sides = append(sides, entry.Side)
+		assert.Greater(t, entry.StopPrice.Float64(), 0.0)
+	}
+	assert.Equal(t, []types.SideType{types.SideTypeBuy, types.SideTypeSell, types.SideTypeBuy}, sides)
+
+	if assert.Len(t, p.venue.closes, 2) {
+		assert.Equal(t, exchange.PositionSideLong, p.venue.closes[0].Side)
+		assert.Equal(t, exchange.PositionSideShort, p.venue.closes[1].Side)
+	}
+	assert.Equal(t, risk.StatusActive, p.strategy.riskManager.Status())
+}
+
+func TestScenarioGap(t *testing.T) {
+	cfg := scenarioConfig()
+	cfg.Risk = config.RiskConfig{}
+
+	bars := trend(100, 1, 3)
+	bars = append(bars, bar{Open: 94, High: 95, Low: 92, Close: 93})
+	bars = append(bars, bar{Open: 98, High: 99, Low: 97, Close: 98})
+
+	p := newPipeline(t, cfg, map[int]string{
+		2: `{"thoughts": {"plan": "buy"}, "action": {"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "100", "risk_percent": "2"}}}`,
+		3: `{"thoughts": {"plan": "sell the gap"}, "action": {"name": "exchange.open_short_position", "args": {"stop_loss_trigger_price": "96", "take_profit_trigger_price": "85", "risk_percent": "1", "confidence": "0.9"}}}`,
+	})
+
+	p.run(bars)
+
+	// Stops gapped through fill at the open, beyond the planned risk
+	if assert.Len(t, p.venue.closes, 2) && assert.Len(t, p.entries(), 2) {
+		long, short := p.venue.closes[0], p.venue.closes[1]
+
+		assert.Equal(t, "stop_loss_gap", long.Reason)
+		assert.Equal(t, 94.0, long.ExitPrice)
+		assert.Greater(t, -long.PnL, 10000*0.02)
+
+		assert.Equal(t, "stop_loss_gap", short.Reason)
+		assert.Equal(t, 98.0, short.ExitPrice)
+		assert.Greater(t, -short.PnL, (10000+long.PnL)*0.01)
+	}
+
+	assert.True(t, p.strategy.Position.GetBase().IsZero())
+	assert.Less(t, p.venue.balance, 10000.0)
+}
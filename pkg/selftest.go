@@ -0,0 +1,184 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/notify"
+	nfeishu "github.com/yubing744/trading-gpt/pkg/notify/feishu"
+	feishu_hook "github.com/yubing744/trading-gpt/pkg/notify/feishu-hook"
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+	"github.com/yubing744/trading-gpt/pkg/utils"
+)
+
+// SelfTest is set by the --selftest flag: instead of trading, the strategy checks the exchange, LLM, memory
+// and notifications, reports a pass/fail checklist and stops
+var SelfTest bool
+
+// ErrSelfTestDone is returned by Run when every self-test check passed, the command exits successfully on it
+var ErrSelfTestDone = errors.New("self-test passed")
+
+// selfTestTimeout bounds each self-test check
+const selfTestTimeout = 30 * time.Second
+
+// runSelfTest runs the startup checks, returning ErrSelfTestDone when they all pass and an error listing them otherwise
+func (s *Strategy) runSelfTest(ctx context.Context) error {
+	checks := []struct {
+		name string
+		run  func(ctx context.Context) (string, error)
+	}{
+		{"exchange connectivity", s.selfTestExchange},
+		{"balance read", s.selfTestBalance},
+		{"market metadata", s.selfTestMarket},
+		{"llm round trip", s.selfTestLLM},
+		{"memory read/write", s.selfTestMemory},
+		{"notification delivery", s.selfTestNotify},
+	}
+
+	results := make([]utils.SelfTestResult, 0, len(checks))
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+		detail, err := check.run(checkCtx)
+		cancel()
+
+		results = append(results, utils.SelfTestResult{
+			Name:    check.name,
+			Detail:  detail,
+			Err:     err,
+			Skipped: err == nil && strings.HasPrefix(detail, "skipped"),
+		})
+	}
+
+	report, ok := utils.FormatSelfTest(results)
+	if !ok {
+		log.Error(report)
+		return errors.New(strings.SplitN(report, "\n", 2)[0])
+	}
+
+	log.Info(report)
+	return ErrSelfTestDone
+}
+
+func (s *Strategy) selfTestExchange(ctx context.Context) (string, error) {
+	ticker, err := s.session.Exchange.QueryTicker(ctx, s.Symbol)
+	if err != nil {
+		return "", errors.Wrap(err, "query ticker")
+	}
+
+	return fmt.Sprintf("%s last price %s", s.Symbol, ticker.Last.String()), nil
+}
+
+func (s *Strategy) selfTestBalance(ctx context.Context) (string, error) {
+	account, err := s.session.UpdateAccount(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "update account")
+	}
+
+	balance, ok := account.Balance(s.Market.QuoteCurrency)
+	if !ok {
+		return fmt.Sprintf("no %s balance", s.Market.QuoteCurrency), nil
+	}
+
+	return fmt.Sprintf("%s available %s", s.Market.QuoteCurrency, balance.Available.String()), nil
+}
+
+func (s *Strategy) selfTestMarket(ctx context.Context) (string, error) {
+	market, ok := s.session.Market(s.Symbol)
+	if !ok {
+		return "", errors.Errorf("market %s not found in the session", s.Symbol)
+	}
+
+	if market.TickSize.IsZero() {
+		return "", errors.Errorf("market %s has no tick size", s.Symbol)
+	}
+
+	return fmt.Sprintf("tick size %s, min quantity %s, min notional %s", market.TickSize.String(), market.MinQuantity.String(), market.MinNotional.String()), nil
+}
+
+func (s *Strategy) selfTestLLM(ctx context.Context) (string, error) {
+	started := time.Now()
+
+	reply, err := s.llm.Call(ctx, "This is a connectivity check. Reply with the single word OK.")
+	if err != nil {
+		return "", errors.Wrap(err, "call llm")
+	}
+
+	if strings.TrimSpace(reply) == "" {
+		return "", errors.New("empty llm reply")
+	}
+
+	return fmt.Sprintf("replied in %s", time.Since(started).Round(time.Millisecond)), nil
+}
+
+// selfTestMemory writes, reads back and removes a probe file in the memory and reflection directories
+func (s *Strategy) selfTestMemory(ctx context.Context) (string, error) {
+	memoryPath := s.Memory.MemoryPath
+	if memoryPath == "" {
+		memoryPath = "memory-bank/trading-memory.md"
+	}
+
+	dirs := []string{filepath.Dir(memoryPath), s.getReflectionPath()}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", errors.Wrapf(err, "create %s", dir)
+		}
+
+		probe := filepath.Join(dir, ".selftest")
+		content := []byte(uuid.NewString())
+		if err := os.WriteFile(probe, content, 0644); err != nil {
+			return "", errors.Wrapf(err, "write %s", probe)
+		}
+
+		read, err := os.ReadFile(probe)
+		os.Remove(probe)
+		if err != nil {
+			return "", errors.Wrapf(err, "read %s", probe)
+		}
+		if string(read) != string(content) {
+			return "", errors.Errorf("%s read back different content", probe)
+		}
+	}
+
+	return strings.Join(dirs, ", "), nil
+}
+
+// selfTestNotify sends a message through every enabled notify channel
+func (s *Strategy) selfTestNotify(ctx context.Context) (string, error) {
+	channels := make([]ttypes.INotifyChannel, 0)
+
+	if cfg := s.Notify.Feishu; cfg != nil && cfg.Enabled {
+		if os.Getenv("NOTIFY_FEISHU_APP_ID") != "" {
+			cfg.AppId = os.Getenv("NOTIFY_FEISHU_APP_ID")
+			cfg.AppSecret = os.Getenv("NOTIFY_FEISHU_APP_SECRET")
+		}
+		channels = append(channels, notify.NewRoutedChannel(nfeishu.NewFeishuNotifyChannel(cfg), &cfg.NotifyRouteConfig))
+	}
+
+	if cfg := s.Notify.FeishuHook; cfg != nil && cfg.Enabled {
+		channels = append(channels, notify.NewRoutedChannel(feishu_hook.NewFeishuHookNotifyChannel(cfg), &cfg.NotifyRouteConfig))
+	}
+
+	if len(channels) == 0 {
+		return "skipped, no notify channel enabled", nil
+	}
+
+	for _, channel := range channels {
+		err := channel.Reply(ctx, &ttypes.Message{
+			ID:       uuid.NewString(),
+			Text:     fmt.Sprintf("Self-test notification from %s %s", ID, s.Symbol),
+			Severity: ttypes.SeverityAction,
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "notify %s", channel.GetID())
+		}
+	}
+
+	return fmt.Sprintf("%d channels delivered", len(channels)), nil
+}
@@ -0,0 +1,297 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/env/exchange"
+	"github.com/yubing744/trading-gpt/pkg/env/spread"
+)
+
+const snapshotVersion = 1
+
+// StrategySnapshot is the full strategy state saved to disk, restored at startup to survive restarts and deploys
+type StrategySnapshot struct {
+	Version  int             `json:"version"`
+	Symbol   string          `json:"symbol"`
+	SavedAt  time.Time       `json:"saved_at"`
+	Position *types.Position `json:"position"`
+
+	// klines, position metrics and pending orders of the exchange entity
+	Exchange *exchange.EntitySnapshot `json:"exchange,omitempty"`
+
+	// open two-leg spread position
+	Spread *spread.SpreadPosition `json:"spread,omitempty"`
+
+	// short-term memory
+	Memory         string `json:"memory"`
+	OpenDecisionID string `json:"open_decision_id"`
+
+	// risk counters
+	NoActionStreak int    `json:"no_action_streak"`
+	Blackout       bool   `json:"blackout"`
+	CycleOutcomes  []bool `json:"cycle_outcomes"`
+
+	// highest equity seen by the drawdown risk budget
+	PeakEquity float64 `json:"peak_equity,omitempty"`
+}
+
+// captureSnapshot copies the in-memory strategy state. It runs on the decision goroutine after each cycle, as the
+// memory, risk counters and entity state are only consistent between cycles, and queries nothing from the exchange.
+func (s *Strategy) captureSnapshot(ctx context.Context) error {
+	position, err := copyPosition(s.Position)
+	if err != nil {
+		return err
+	}
+
+	snapshot := &StrategySnapshot{
+		Version:        snapshotVersion,
+		Symbol:         s.Symbol,
+		SavedAt:        time.Now(),
+		Position:       position,
+		Memory:         s.currentMemory,
+		OpenDecisionID: s.openDecisionID,
+		NoActionStreak: s.noActionStreak,
+		Blackout:       s.blackout.Load(),
+	}
+
+	if s.exchange != nil {
+		snapshot.Exchange = s.exchange.Snapshot()
+	}
+
+	if s.spread != nil {
+		snapshot.Spread = s.spread.Position()
+	}
+
+	if s.cycleFailures != nil {
+		snapshot.CycleOutcomes = s.cycleFailures.Outcomes()
+	}
+
+	if s.riskBudget != nil {
+		snapshot.PeakEquity = s.riskBudget.Peak()
+	}
+
+	s.capturedSnapshot.Store(snapshot)
+	return nil
+}
+
+// copyPosition copies the position under its lock, the trade collector updates it from the exchange goroutine
+func copyPosition(position *types.Position) (*types.Position, error) {
+	if position == nil {
+		return nil, nil
+	}
+
+	position.Lock()
+	data, err := json.Marshal(position)
+	position.Unlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal position error")
+	}
+
+	copied := &types.Position{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, errors.Wrap(err, "unmarshal position error")
+	}
+
+	return copied, nil
+}
+
+// Snapshot writes the strategy state captured after the latest decision cycle to the snapshot file, with the orders
+// pending on the exchange. It returns nil before the first cycle, leaving the snapshot restored at startup in place.
+func (s *Strategy) Snapshot(ctx context.Context) (*StrategySnapshot, error) {
+	captured := s.capturedSnapshot.Load()
+	if captured == nil {
+		return nil, nil
+	}
+
+	// The captured state is shared with the next writer, so the pending orders go into a copy
+	snapshot := *captured
+	if captured.Exchange != nil {
+		exchangeSnapshot := *captured.Exchange
+
+		orders, err := s.exchange.PendingOrders(ctx)
+		if err != nil {
+			log.WithError(err).Warn("query open orders for snapshot failed")
+		} else {
+			exchangeSnapshot.PendingOrders = orders
+		}
+
+		snapshot.Exchange = &exchangeSnapshot
+	}
+
+	data, err := json.Marshal(&snapshot)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal snapshot error")
+	}
+
+	// Write to a temporary file first, so a crash never leaves a truncated snapshot behind
+	dir := filepath.Dir(s.StateSnapshot.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "create snapshot dir error")
+	}
+
+	tmp := s.StateSnapshot.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return nil, errors.Wrap(err, "write snapshot error")
+	}
+
+	if err := os.Rename(tmp, s.StateSnapshot.Path); err != nil {
+		return nil, errors.Wrap(err, "replace snapshot error")
+	}
+
+	return &snapshot, nil
+}
+
+// loadSnapshot reads the snapshot to restore, nil if there is none or it does not fit this instance
+func (s *Strategy) loadSnapshot() (*StrategySnapshot, error) {
+	data, err := os.ReadFile(s.StateSnapshot.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "read snapshot error")
+	}
+
+	snapshot := &StrategySnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, errors.Wrap(err, "unmarshal snapshot error")
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return nil, errors.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	if snapshot.Symbol != s.Symbol {
+		return nil, errors.Errorf("snapshot of symbol %s does not match %s", snapshot.Symbol, s.Symbol)
+	}
+
+	if s.StateSnapshot.MaxAge > 0 && time.Since(snapshot.SavedAt) > s.StateSnapshot.MaxAge.Duration() {
+		log.WithField("saved_at", snapshot.SavedAt).Warn("snapshot too old, skip restore")
+		return nil, nil
+	}
+
+	return snapshot, nil
+}
+
+// Restore loads the latest snapshot at startup, before the position and entities are created from it
+func (s *Strategy) Restore(ctx context.Context) error {
+	if !s.StateSnapshot.Enabled {
+		return nil
+	}
+
+	if s.StateSnapshot.Path == "" {
+		s.StateSnapshot.Path = fmt.Sprintf("memory-bank/snapshot-%s.json", s.Symbol)
+	}
+	if s.StateSnapshot.Interval == 0 {
+		s.StateSnapshot.Interval = types.Duration(time.Minute)
+	}
+
+	snapshot, err := s.loadSnapshot()
+	if err != nil {
+		log.WithError(err).Warn("load snapshot failed, starting from a fresh state")
+		return nil
+	}
+
+	if snapshot == nil {
+		log.WithField("path", s.StateSnapshot.Path).Info("no snapshot to restore")
+		return nil
+	}
+
+	// A position restored by the bbgo persistence takes precedence
+	if s.Position == nil && snapshot.Position != nil {
+		s.Position = snapshot.Position
+	}
+
+	s.restored = snapshot
+	return nil
+}
+
+// restoreState applies the strategy state of the loaded snapshot, once every component is set up
+func (s *Strategy) restoreState(ctx context.Context) {
+	snapshot := s.restored
+	if snapshot == nil {
+		return
+	}
+	s.restored = nil
+
+	if s.memoryEnabled && s.currentMemory == "" {
+		s.currentMemory = snapshot.Memory
+	}
+
+	s.openDecisionID = snapshot.OpenDecisionID
+	s.noActionStreak = snapshot.NoActionStreak
+
+	if s.cycleFailures != nil {
+		for _, failed := range snapshot.CycleOutcomes {
+			s.cycleFailures.Record(failed)
+		}
+
+		// The pause outlives restarts until an operator resumes trading
+		s.blackout.Store(snapshot.Blackout)
+	}
+
+	// The drawdown is measured from the peak before the restart
+	if s.riskBudget != nil && s.riskBudget.Restore(snapshot.PeakEquity) {
+		s.applySizeScale()
+	}
+
+	log.
+		WithField("saved_at", snapshot.SavedAt).
+		WithField("position", s.Position).
+		Info("strategy state restored from snapshot")
+}
+
+// setupSnapshot restores the loaded snapshot, then saves snapshots periodically and on shutdown
+func (s *Strategy) setupSnapshot(ctx context.Context) error {
+	if !s.StateSnapshot.Enabled {
+		return nil
+	}
+
+	s.restoreState(ctx)
+
+	go func() {
+		ticker := time.NewTicker(s.StateSnapshot.Interval.Duration())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Snapshot(ctx); err != nil {
+					log.WithError(err).Warn("save snapshot failed")
+				}
+			}
+		}
+	}()
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		snapshot, err := s.Snapshot(ctx)
+		if err != nil {
+			log.WithError(err).Error("save snapshot on shutdown failed")
+			return
+		}
+
+		if snapshot == nil {
+			log.Info("no decision cycle ran, snapshot left unchanged on shutdown")
+			return
+		}
+
+		log.WithField("path", s.StateSnapshot.Path).Info("snapshot saved on shutdown")
+	})
+
+	log.WithField("path", s.StateSnapshot.Path).Info("Strategy snapshots enabled")
+	return nil
+}
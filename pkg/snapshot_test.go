@@ -0,0 +1,54 @@
+package pkg
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotQueriesPendingOrdersOnlyWhenWritten(t *testing.T) {
+	ctx := context.Background()
+
+	p := newPipeline(t, scenarioConfig(), map[int]string{
+		0: `{"thoughts": {"plan": "buy"}, "action": {"name": "exchange.open_long_position", "args": {"stop_loss_trigger_price": "97", "risk_percent": "1"}}}`,
+	})
+	p.strategy.StateSnapshot.Path = filepath.Join(t.TempDir(), "snapshot.json")
+	p.run(trend(100, 1, 1))
+	p.venue.openOrders = []types.Order{{OrderID: 7}}
+
+	// Nothing is written before the first capture
+	snapshot, err := p.strategy.Snapshot(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, snapshot)
+
+	// The decision goroutine only copies the in-memory state
+	assert.NoError(t, p.strategy.captureSnapshot(ctx))
+	assert.Equal(t, 0, p.venue.openOrderQueries)
+
+	captured := p.strategy.capturedSnapshot.Load()
+	if assert.NotNil(t, captured) && assert.NotNil(t, captured.Position) {
+		assert.NotSame(t, p.strategy.Position, captured.Position)
+		assert.Equal(t, p.strategy.Position.GetBase(), captured.Position.GetBase())
+	}
+
+	// The writer adds the pending orders, leaving the captured state as it was
+	snapshot, err = p.strategy.Snapshot(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p.venue.openOrderQueries)
+	if assert.NotNil(t, snapshot) && assert.NotNil(t, snapshot.Exchange) {
+		assert.Len(t, snapshot.Exchange.PendingOrders, 1)
+	}
+	assert.Empty(t, captured.Exchange.PendingOrders)
+
+	loaded, err := p.strategy.loadSnapshot()
+	assert.NoError(t, err)
+	if assert.NotNil(t, loaded) && assert.NotNil(t, loaded.Exchange) {
+		if assert.Len(t, loaded.Exchange.PendingOrders, 1) {
+			assert.Equal(t, uint64(7), loaded.Exchange.PendingOrders[0].OrderID)
+		}
+		assert.Len(t, loaded.Exchange.KLines, 1)
+	}
+}
@@ -0,0 +1,198 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+
+	ttypes "github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// Ways to handle an entry whose price drifted while the decision was slow
+const (
+	StaleOnDriftAbort   = "abort"
+	StaleOnDriftConfirm = "confirm"
+)
+
+// promptSnapshotKey carries the promptSnapshot of a decision cycle in its context
+type promptSnapshotKey struct{}
+
+// staleConfirmedKey marks an agentAction context whose entries were already re-confirmed against the current price
+type staleConfirmedKey struct{}
+
+// promptSnapshot is when the data of a decision's prompt was taken, and the price it showed
+type promptSnapshot struct {
+	At    time.Time
+	Price float64
+}
+
+// staleDrift is how far the price moved since the prompt data of a slow decision was taken
+type staleDrift struct {
+	Age         time.Duration
+	PromptPrice float64
+	Price       float64
+	Percent     float64
+}
+
+func (d *staleDrift) String() string {
+	return fmt.Sprintf("the prompt data is %s old and the price moved %.2f%% from %g to %g",
+		d.Age.Round(time.Second), d.Percent, d.PromptPrice, d.Price)
+}
+
+func (s *Strategy) setupStaleGuard(ctx context.Context) error {
+	cfg := &s.StaleGuard
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = types.Duration(time.Minute * 2)
+	}
+	if cfg.MaxDriftPercent <= 0 {
+		cfg.MaxDriftPercent = 0.3
+	}
+	if cfg.OnDrift != StaleOnDriftAbort {
+		cfg.OnDrift = StaleOnDriftConfirm
+	}
+
+	log.WithField("config", cfg).Info("Stale prompt data guard enabled")
+	return nil
+}
+
+// stampMsgs prefixes the prompt sections with the time their data was taken
+func stampMsgs(msgs []*ttypes.Message) []*ttypes.Message {
+	rets := make([]*ttypes.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.DataTime.IsZero() {
+			rets = append(rets, msg)
+			continue
+		}
+
+		stamped := *msg
+		stamped.Text = fmt.Sprintf("[data as of %s]\n%s", msg.DataTime.UTC().Format("2006-01-02 15:04:05 UTC"), msg.Text)
+		rets = append(rets, &stamped)
+	}
+
+	return rets
+}
+
+// withPromptSnapshot returns a context carrying when the oldest prompt section was taken and the kline close it showed
+func (s *Strategy) withPromptSnapshot(ctx context.Context, session ttypes.ISession, msgs []*ttypes.Message) context.Context {
+	if !s.StaleGuard.Enabled {
+		return ctx
+	}
+
+	kline, ok := s.getKline(session)
+	if !ok || kline.Len() == 0 {
+		return ctx
+	}
+
+	snapshot := &promptSnapshot{At: time.Now(), Price: kline.GetClose().Float64()}
+	for _, msg := range msgs {
+		if !msg.DataTime.IsZero() && msg.DataTime.Before(snapshot.At) {
+			snapshot.At = msg.DataTime
+		}
+	}
+
+	return context.WithValue(ctx, promptSnapshotKey{}, snapshot)
+}
+
+// checkStaleData re-validates the price when an entry was decided on prompt data older than max_age,
+// returning the drift when it exceeds max_drift_percent, nil when the entry may execute
+func (s *Strategy) checkStaleData(ctx context.Context) (*staleDrift, error) {
+	snapshot, ok := ctx.Value(promptSnapshotKey{}).(*promptSnapshot)
+	if !s.StaleGuard.Enabled || !ok || snapshot.Price <= 0 {
+		return nil, nil
+	}
+
+	age := time.Since(snapshot.At)
+	if age < s.StaleGuard.MaxAge.Duration() {
+		return nil, nil
+	}
+
+	price, err := s.exchange.MarketPrice(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "re-validate the price of stale prompt data error")
+	}
+
+	drift := &staleDrift{
+		Age:         age,
+		PromptPrice: snapshot.Price,
+		Price:       price.Float64(),
+		Percent:     (price.Float64() - snapshot.Price) / snapshot.Price * 100,
+	}
+
+	log.
+		WithField("age", age).
+		WithField("prompt_price", drift.PromptPrice).
+		WithField("price", drift.Price).
+		WithField("drift_percent", drift.Percent).
+		Info("price of stale prompt data re-validated")
+
+	if math.Abs(drift.Percent) <= s.StaleGuard.MaxDriftPercent {
+		return nil, nil
+	}
+
+	return drift, nil
+}
+
+// staleGuard returns whether the actions may execute on their prompt data. When entries were decided on stale data
+// and the price drifted, it aborts them, or asks the agent once to re-confirm them against the current price.
+func (s *Strategy) staleGuard(ctx context.Context, chatSession ttypes.ISession, msgs []*ttypes.Message, actions []*ttypes.Action, retryTime int) bool {
+	hasEntry := false
+	texts := make([]string, 0, len(actions))
+	for _, action := range actions {
+		actionName := action.Name
+		if !strings.Contains(actionName, ".") {
+			actionName = "exchange." + actionName
+		}
+
+		hasEntry = hasEntry || isEntryAction(actionName)
+		texts = append(texts, action.JSON())
+	}
+
+	if !hasEntry {
+		return true
+	}
+
+	commands := strings.Join(texts, ", ")
+
+	drift, err := s.checkStaleData(ctx)
+	if err != nil {
+		log.WithError(err).Warn("stale prompt data check failed")
+		s.feedbackCmdExecuteResult(ctx, chatSession, fmt.Sprintf("Command: %s aborted, reason: %s", commands, err.Error()))
+		return false
+	}
+
+	if drift == nil {
+		return true
+	}
+
+	if s.StaleGuard.OnDrift == StaleOnDriftAbort || ctx.Value(staleConfirmedKey{}) != nil {
+		log.WithField("drift", drift.String()).Warn("entry aborted on stale prompt data")
+		s.feedbackCmdExecuteResult(ctx, chatSession, fmt.Sprintf("Command: %s aborted, reason: %s.", commands, drift))
+		return false
+	}
+
+	// The re-confirmation is checked against the price it is asked with
+	s.replyMsg(ctx, chatSession, fmt.Sprintf("Re-confirming %s: %s.", commands, drift))
+	newMsgs := append(msgs, []*ttypes.Message{
+		{
+			Text: fmt.Sprintf("Your proposed command %s was decided on stale data: %s.", commands, drift),
+		},
+		{
+			Text: "Re-confirm it against the current price by responding with the same action JSON, or respond with a revised action (e.g. new stop loss or no_action).",
+		},
+	}...)
+
+	ctx = context.WithValue(ctx, staleConfirmedKey{}, true)
+	ctx = context.WithValue(ctx, promptSnapshotKey{}, &promptSnapshot{At: time.Now(), Price: drift.Price})
+	s.agentAction(ctx, chatSession, newMsgs, retryTime)
+
+	return false
+}
@@ -0,0 +1,37 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// setupTestnet routes the orders to the sandbox endpoints and labels the notifications and the journal
+func (s *Strategy) setupTestnet(ctx context.Context) error {
+	cfg := &s.Testnet
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.Label == "" {
+		cfg.Label = "[TESTNET]"
+	}
+
+	venue := &s.Env.ExchangeConfig.Venue
+	switch venue.Type {
+	case config.VenueTypeRest:
+		if venue.TestnetBaseURL == "" {
+			return errors.New("testnet requires venue.testnet_base_url with the rest venue")
+		}
+		venue.BaseURL = venue.TestnetBaseURL
+	default:
+		// bbgo picks the endpoints of a session when it creates it, whether they are a sandbox can't be verified,
+		// so nothing labeled testnet may trade through it
+		return errors.Errorf("testnet requires the rest venue with venue.testnet_base_url, the %s session of the bbgo venue can't be verified as a sandbox", s.session.ExchangeName)
+	}
+
+	log.WithField("label", cfg.Label).Info("Testnet enabled")
+	return nil
+}
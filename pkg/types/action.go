@@ -12,8 +12,8 @@ type ArgmentDesc struct {
 }
 
 type Sample struct {
-	Input  []string
-	Output []string
+	Input  []string // Lines describing the situation
+	Output []string // Args of the command decided, as JSON
 }
 
 type ActionDesc struct {
@@ -0,0 +1,16 @@
+package types
+
+import "context"
+
+type cycleIDKey struct{}
+
+// WithCycleID returns a context carrying the ID of the decision cycle it belongs to
+func WithCycleID(ctx context.Context, cycleID string) context.Context {
+	return context.WithValue(ctx, cycleIDKey{}, cycleID)
+}
+
+// CycleIDFromContext returns the decision cycle ID of the context, or "" outside a decision cycle
+func CycleIDFromContext(ctx context.Context) string {
+	cycleID, _ := ctx.Value(cycleIDKey{}).(string)
+	return cycleID
+}
@@ -0,0 +1,16 @@
+package types
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycleIDFromContext(t *testing.T) {
+	assert.Equal(t, "", CycleIDFromContext(context.Background()))
+
+	ctx := WithCycleID(context.Background(), "c1")
+	assert.Equal(t, "c1", CycleIDFromContext(ctx))
+	assert.Equal(t, "c1", CycleIDFromContext(context.WithValue(ctx, struct{}{}, 1)))
+}
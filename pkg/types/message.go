@@ -1,6 +1,12 @@
 package types
 
+import "time"
+
 type Message struct {
-	ID   string `json:"id"`
-	Text string `json:"text"`
+	ID       string    `json:"id"`
+	Text     string    `json:"text"`
+	CycleID  string    `json:"cycle_id,omitempty"`  // Decision cycle the message was sent in
+	Severity Severity  `json:"severity,omitempty"`  // Notification severity, info when empty
+	DataTime time.Time `json:"data_time,omitempty"` // When the data of a prompt section was taken
+	Section  string    `json:"section,omitempty"`   // Prompt section of the message, e.g. klines, for the context budget
 }
@@ -25,6 +25,23 @@ func (t *Thoughts) ToHumanText() string {
 		t.Speak)
 }
 
+// Summary returns a compact reasoning summary, preferring what the agent says to the user.
+func (t *Thoughts) Summary() string {
+	if t == nil {
+		return ""
+	}
+
+	if strings.TrimSpace(t.Speak) != "" {
+		return t.Speak
+	}
+
+	if t.Analyze != nil {
+		return interfaceToString(t.Analyze)
+	}
+
+	return ""
+}
+
 // interfaceToString converts an interface{} to a string in a human-readable format.
 func interfaceToString(i interface{}) string {
 	if i == nil {
@@ -48,9 +65,64 @@ func interfaceToString(i interface{}) string {
 }
 
 type Result struct {
-	Thoughts *Thoughts `json:"thoughts"`
-	Action   *Action   `json:"action"`
-	Memory   *Memory   `json:"memory,omitempty"` // New memory field
+	Thoughts   *Thoughts   `json:"thoughts"`
+	Assessment *Assessment `json:"assessment,omitempty"` // Structured market assessment the action was decided on
+	Action     *Action     `json:"action"`
+	Actions    []*Action   `json:"actions,omitempty"`  // Ordered actions executed as a batch
+	Memory     *Memory     `json:"memory,omitempty"`   // New memory field
+	CycleID    string      `json:"cycle_id,omitempty"` // Decision cycle the result was generated in
+}
+
+// Assessment is the market assessment returned alongside the action
+type Assessment struct {
+	Trend      string   `json:"trend"`      // up, down or sideways
+	Volatility string   `json:"volatility"` // low, normal or high
+	KeyLevels  []string `json:"key_levels"` // Support and resistance prices
+	Bias       string   `json:"bias"`       // long, short or neutral
+}
+
+// String renders the assessment on one line, e.g. "trend: up, volatility: high, bias: long, key levels: 51500, 52000"
+func (a *Assessment) String() string {
+	if a == nil {
+		return ""
+	}
+
+	parts := make([]string, 0, 4)
+	if a.Trend != "" {
+		parts = append(parts, "trend: "+a.Trend)
+	}
+	if a.Volatility != "" {
+		parts = append(parts, "volatility: "+a.Volatility)
+	}
+	if a.Bias != "" {
+		parts = append(parts, "bias: "+a.Bias)
+	}
+	if len(a.KeyLevels) > 0 {
+		parts = append(parts, "key levels: "+strings.Join(a.KeyLevels, ", "))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// AllActions returns the named actions of the result in execution order, the batch taking precedence over the single action
+func (r *Result) AllActions() []*Action {
+	actions := make([]*Action, 0)
+
+	if len(r.Actions) > 0 {
+		for _, action := range r.Actions {
+			if action != nil && action.Name != "" {
+				actions = append(actions, action)
+			}
+		}
+
+		return actions
+	}
+
+	if r.Action != nil && r.Action.Name != "" {
+		actions = append(actions, r.Action)
+	}
+
+	return actions
 }
 
 // Memory represents memory content for AI learning
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -81,3 +82,34 @@ Speak: A statement.
 		})
 	}
 }
+
+func TestResultAllActions(t *testing.T) {
+	single := &Result{Action: &Action{Name: "exchange.close_position"}}
+	assert.Equal(t, []*Action{single.Action}, single.AllActions())
+
+	batch := &Result{
+		Action: &Action{Name: "exchange.no_action"},
+		Actions: []*Action{
+			{Name: "exchange.close_position"},
+			nil,
+			{Name: ""},
+			{Name: "exchange.open_short_position", Args: map[string]string{"stop_loss_trigger_price": "1.2"}},
+		},
+	}
+	actions := batch.AllActions()
+	assert.Len(t, actions, 2)
+	assert.Equal(t, "exchange.close_position", actions[0].Name)
+	assert.Equal(t, "exchange.open_short_position", actions[1].Name)
+
+	assert.Empty(t, (&Result{Action: &Action{}}).AllActions())
+}
+
+func TestResultAssessment(t *testing.T) {
+	var result Result
+	err := json.Unmarshal([]byte(`{"assessment": {"trend": "up", "volatility": "high", "key_levels": ["51500", "52000"], "bias": "long"}, "action": {"name": "exchange.no_action"}}`), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, "trend: up, volatility: high, bias: long, key levels: 51500, 52000", result.Assessment.String())
+
+	assert.Equal(t, "trend: sideways", (&Assessment{Trend: "sideways"}).String())
+	assert.Equal(t, "", (*Assessment)(nil).String())
+}
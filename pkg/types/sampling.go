@@ -0,0 +1,23 @@
+package types
+
+import "context"
+
+// Sampling is the sampling parameters a decision is generated with, chosen by the market state
+type Sampling struct {
+	State       string  `json:"state"`
+	Temperature float64 `json:"temperature"`
+	TopP        float64 `json:"top_p,omitempty"`
+}
+
+type samplingKey struct{}
+
+// WithSampling returns a context carrying the sampling parameters of the decision cycle
+func WithSampling(ctx context.Context, sampling *Sampling) context.Context {
+	return context.WithValue(ctx, samplingKey{}, sampling)
+}
+
+// SamplingFromContext returns the sampling parameters of the context, or nil when the agent's defaults apply
+func SamplingFromContext(ctx context.Context) *Sampling {
+	sampling, _ := ctx.Value(samplingKey{}).(*Sampling)
+	return sampling
+}
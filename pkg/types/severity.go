@@ -0,0 +1,23 @@
+package types
+
+import "strings"
+
+// Severity is how urgently a notification needs an operator's attention
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"     // Verbose events: prompts, thoughts, market updates
+	SeverityAction   Severity = "action"   // Trade actions and their results
+	SeverityWarning  Severity = "warning"  // Risk alerts that need a look
+	SeverityCritical Severity = "critical" // Trading paused or positions force closed
+)
+
+// ParseSeverity parses a severity name, ok is false for unknown names
+func ParseSeverity(name string) (Severity, bool) {
+	switch severity := Severity(strings.ToLower(strings.TrimSpace(name))); severity {
+	case SeverityInfo, SeverityAction, SeverityWarning, SeverityCritical:
+		return severity, true
+	default:
+		return "", false
+	}
+}
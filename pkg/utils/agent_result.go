@@ -2,6 +2,7 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -13,51 +14,134 @@ func extractThinking(text string) string {
 	return ""
 }
 
+// Repair levels of ParseResult, from the plain JSON to the most aggressive fixes
+const (
+	RepairLevelNone       = "none"       // Parsed as written, apart from the text around the JSON
+	RepairLevelUnderscore = "underscore" // Escaped underscores like open\_long\_position
+	RepairLevelSyntax     = "syntax"     // Trailing or missing commas, single quotes, raw newlines in strings
+	RepairLevelFullWidth  = "full_width" // Full-width punctuation of LLMs answering in Chinese
+)
+
+// Characters of each side of the repair diff kept for telemetry
+const repairDiffMaxLength = 120
+
+// ParseRepair describes how a result was repaired to parse
+type ParseRepair struct {
+	Level string // Repair level that parsed
+	Diff  string // What the repair changed in the JSON, empty when nothing was changed
+}
+
+type repairStep struct {
+	level  string
+	repair func(string) string
+}
+
+// repairSteps try parsing with increasing levels of repair
+var repairSteps = []repairStep{
+	// Level 1: Basic cleanup
+	{RepairLevelNone, func(s string) string {
+		s = trimMarkdownJSON(s)
+		s = trimJSON(s)
+		return s
+	}},
+	// Level 2: Fix escaped underscores and backslashes
+	{RepairLevelUnderscore, func(s string) string {
+		s = trimMarkdownJSON(s)
+		s = fixEscapedUnderscores(s)
+		s = trimJSON(s)
+		return s
+	}},
+	// Level 3: More aggressive cleanup
+	{RepairLevelSyntax, func(s string) string {
+		s = trimMarkdownJSON(s)
+		s = fixEscapedUnderscores(s)
+		s = fixCommonJSONIssues(s)
+		s = trimJSON(s)
+		return s
+	}},
+	// Level 4: Full-width punctuation from LLMs answering in Chinese, e.g. ｛“action”：…｝
+	{RepairLevelFullWidth, func(s string) string {
+		s = NormalizeWidth(s)
+		s = trimMarkdownJSON(s)
+		s = fixEscapedUnderscores(s)
+		s = fixCommonJSONIssues(s)
+		s = trimJSON(s)
+		return s
+	}},
+}
+
 func ParseResult(text string) (*types.Result, error) {
-	// Try parsing with increasing levels of repair
-	repairs := []func(string) string{
-		// Level 1: Basic cleanup
-		func(s string) string {
-			s = trimMarkdownJSON(s)
-			s = trimJSON(s)
-			return s
-		},
-		// Level 2: Fix escaped underscores and backslashes
-		func(s string) string {
-			s = trimMarkdownJSON(s)
-			s = fixEscapedUnderscores(s)
-			s = trimJSON(s)
-			return s
-		},
-		// Level 3: More aggressive cleanup
-		func(s string) string {
-			s = trimMarkdownJSON(s)
-			s = fixEscapedUnderscores(s)
-			s = fixCommonJSONIssues(s)
-			s = trimJSON(s)
-			return s
-		},
-	}
+	result, _, err := ParseResultWithRepair(text)
+	return result, err
+}
+
+// ParseResultWithRepair parses the result like ParseResult and reports the repair that made it parse
+func ParseResultWithRepair(text string) (*types.Result, *ParseRepair, error) {
+	original := trimJSON(trimMarkdownJSON(text))
 
 	var lastErr error
-	for i, repair := range repairs {
-		cleanedText := repair(text)
-		jsonBytes := removeJSONComments([]byte(cleanedText))
+	for _, step := range repairSteps {
+		jsonBytes := removeJSONComments([]byte(step.repair(text)))
 
 		var result types.Result
 		err := json.Unmarshal(jsonBytes, &result)
 		if err == nil {
-			return &result, nil
+			normalizeResultArgs(&result)
+			return &result, &ParseRepair{Level: step.level, Diff: repairDiff(original, string(jsonBytes))}, nil
 		}
 
 		lastErr = err
-		// Log attempt if not the last one
-		if i < len(repairs)-1 {
-			continue
-		}
 	}
 
-	return nil, errors.Wrapf(lastErr, "json.Unmarshal_error after all repair attempts")
+	return nil, nil, errors.Wrapf(lastErr, "json.Unmarshal_error after all repair attempts")
+}
+
+// repairDiff describes the change between the JSON as written and as repaired by its differing middle part,
+// e.g. `-,}` `+}` for a trailing comma, multiple fixes are reported as one span
+func repairDiff(before string, after string) string {
+	if before == after {
+		return ""
+	}
+
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	removed := strings.TrimSpace(before[prefix : len(before)-suffix])
+	added := strings.TrimSpace(after[prefix : len(after)-suffix])
+
+	return fmt.Sprintf("-%s +%s", truncateDiffSpan(removed), truncateDiffSpan(added))
+}
+
+func truncateDiffSpan(span string) string {
+	runes := []rune(span)
+	if len(runes) <= repairDiffMaxLength {
+		return span
+	}
+
+	return string(runes[:repairDiffMaxLength]) + "…"
+}
+
+// ParseResultStrict parses the JSON of the result without the repair heuristics of ParseResult, which can
+// silently change the meaning of a response, e.g. when fixing quotes. Only the surrounding text and a markdown
+// fence are removed.
+func ParseResultStrict(text string) (*types.Result, error) {
+	cleanedText := trimJSON(trimMarkdownJSON(text))
+
+	var result types.Result
+	if err := json.Unmarshal([]byte(cleanedText), &result); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal_error in strict mode")
+	}
+
+	normalizeResultArgs(&result)
+	return &result, nil
 }
 
 func trimMarkdownJSON(text string) string {
@@ -89,6 +173,10 @@ func trimJSON(text string) string {
 }
 
 func removeJSONComments(jsonData []byte) []byte {
+	// Whole-line comments anywhere; other comments only at the end, to keep "//" inside strings such as URLs
+	lineCommentPattern := regexp.MustCompile(`(?m)^\s*//.*$`)
+	jsonData = lineCommentPattern.ReplaceAll(jsonData, []byte{})
+
 	singleLineCommentPattern := regexp.MustCompile(`//.*$`)
 	jsonData = singleLineCommentPattern.ReplaceAll(jsonData, []byte{})
 
@@ -302,3 +302,34 @@ func TestParseResultWithoutThoughts(t *testing.T) {
 	assert.Nil(t, ret.Thoughts)
 	assert.Equal(t, "exchange.close_position", ret.Action.Name)
 }
+
+func TestParseResultStrict(t *testing.T) {
+	ret, err := ParseResultStrict("```json\n{\"action\": {\"name\": \"exchange.close_position\", \"args\": {\"percentage\": \"50%\"}}}\n```")
+	assert.NoError(t, err)
+	assert.Equal(t, "exchange.close_position", ret.Action.Name)
+	assert.Equal(t, "50%", ret.Action.Args["percentage"])
+
+	// Trailing commas and single quotes are repaired by ParseResult only
+	text := `{'action': {"name": "exchange.no_action", "args": {},},}`
+	_, err = ParseResult(text)
+	assert.NoError(t, err)
+
+	_, err = ParseResultStrict(text)
+	assert.Error(t, err)
+}
+
+func TestParseResultWithRepair(t *testing.T) {
+	_, repair, err := ParseResultWithRepair("Here you go:\n```json\n{\"action\": {\"name\": \"exchange.no_action\"}}\n```")
+	assert.NoError(t, err)
+	assert.Equal(t, RepairLevelNone, repair.Level)
+	assert.Empty(t, repair.Diff)
+
+	_, repair, err = ParseResultWithRepair(`{"action": {"name": "exchange.open\_long\_position"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, RepairLevelUnderscore, repair.Level)
+
+	_, repair, err = ParseResultWithRepair(`{"action": {"name": "exchange.no_action", "args": {},}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, RepairLevelSyntax, repair.Level)
+	assert.Equal(t, "-, +", repair.Diff)
+}
@@ -31,6 +31,12 @@ func ExtractArgs(text string, cmd string) []string {
 }
 
 func ArgToFixedpoint(vm *goja.Runtime, arg string) (*fixedpoint.Value, error) {
+	// Plain numbers with separators or suffixes like "51,250" and "51.25k" are not valid expressions,
+	// "51,250" would even evaluate to 250
+	if num, err := ParseNumberArg(arg); err == nil && !num.Percent {
+		return &num.Value, nil
+	}
+
 	v, err := vm.RunString(arg)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// BacktestTrade is a trade closed during a backtest
+type BacktestTrade struct {
+	Time       time.Time
+	Side       string
+	EntryPrice float64
+	ExitPrice  float64
+	PnL        float64 // Quote currency
+	PnLPercent float64
+	Reason     string
+}
+
+// BacktestReport accumulates the closed trades of a backtest into its PnL and the drawdown of the equity
+// curve, equity being the initial equity plus the realized PnL
+type BacktestReport struct {
+	Symbol        string
+	InitialEquity float64
+	Trades        []BacktestTrade
+	Decisions     int // Decision cycles the agent ran
+
+	equity      float64
+	peak        float64
+	maxDrawdown float64 // Quote currency
+	maxDDPct    float64
+}
+
+func NewBacktestReport(symbol string, initialEquity float64) *BacktestReport {
+	return &BacktestReport{
+		Symbol:        symbol,
+		InitialEquity: initialEquity,
+		Trades:        make([]BacktestTrade, 0),
+		equity:        initialEquity,
+		peak:          initialEquity,
+	}
+}
+
+// AddTrade records a closed trade and updates the equity curve
+func (r *BacktestReport) AddTrade(trade BacktestTrade) {
+	r.Trades = append(r.Trades, trade)
+
+	r.equity += trade.PnL
+	if r.equity > r.peak {
+		r.peak = r.equity
+	}
+
+	drawdown := r.peak - r.equity
+	if drawdown > r.maxDrawdown {
+		r.maxDrawdown = drawdown
+	}
+	if r.peak > 0 && drawdown/r.peak*100 > r.maxDDPct {
+		r.maxDDPct = drawdown / r.peak * 100
+	}
+}
+
+// NetPnL returns the realized PnL of the trades
+func (r *BacktestReport) NetPnL() float64 {
+	return r.equity - r.InitialEquity
+}
+
+// MaxDrawdown returns the largest fall of the equity from its peak, in quote currency and in percent of the peak
+func (r *BacktestReport) MaxDrawdown() (float64, float64) {
+	return r.maxDrawdown, r.maxDDPct
+}
+
+// WinRate returns the percent of the trades closed with a profit
+func (r *BacktestReport) WinRate() float64 {
+	if len(r.Trades) == 0 {
+		return 0
+	}
+
+	wins := 0
+	for _, trade := range r.Trades {
+		if trade.PnL > 0 {
+			wins++
+		}
+	}
+
+	return float64(wins) / float64(len(r.Trades)) * 100
+}
+
+// ProfitFactor returns the gross profit over the gross loss, +Inf without losses and 0 without trades
+func (r *BacktestReport) ProfitFactor() float64 {
+	profit, loss := 0.0, 0.0
+	for _, trade := range r.Trades {
+		if trade.PnL > 0 {
+			profit += trade.PnL
+		} else {
+			loss -= trade.PnL
+		}
+	}
+
+	if loss == 0 {
+		if profit == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+
+	return profit / loss
+}
+
+func (r *BacktestReport) String() string {
+	var builder strings.Builder
+
+	ddQuote, ddPct := r.MaxDrawdown()
+
+	builder.WriteString(fmt.Sprintf("Backtest report for %s\n", r.Symbol))
+	builder.WriteString(fmt.Sprintf("Decision cycles: %d\n", r.Decisions))
+	builder.WriteString(fmt.Sprintf("Trades: %d, win rate %.1f%%, profit factor %.2f\n", len(r.Trades), r.WinRate(), r.ProfitFactor()))
+	builder.WriteString(fmt.Sprintf("Net PnL: %.2f", r.NetPnL()))
+	if r.InitialEquity > 0 {
+		builder.WriteString(fmt.Sprintf(" (%+.2f%% of %.2f)", r.NetPnL()/r.InitialEquity*100, r.InitialEquity))
+	}
+	builder.WriteString("\n")
+	builder.WriteString(fmt.Sprintf("Max drawdown: %.2f (%.2f%%)\n", ddQuote, ddPct))
+
+	if len(r.Trades) > 0 {
+		builder.WriteString("Trades:\n")
+		for _, trade := range r.Trades {
+			builder.WriteString(fmt.Sprintf("- %s %s %g -> %g, PnL %.2f (%.2f%%), %s\n",
+				trade.Time.UTC().Format(time.RFC3339), trade.Side, trade.EntryPrice, trade.ExitPrice, trade.PnL, trade.PnLPercent, trade.Reason))
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBacktestReport(t *testing.T) {
+	report := NewBacktestReport("BTCUSDT", 1000)
+	assert.Equal(t, 0.0, report.ProfitFactor())
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report.AddTrade(BacktestTrade{Time: now, Side: "long", EntryPrice: 100, ExitPrice: 110, PnL: 100, PnLPercent: 10, Reason: "TakeProfit"})
+	report.AddTrade(BacktestTrade{Time: now, Side: "short", EntryPrice: 110, ExitPrice: 121, PnL: -220, PnLPercent: -10, Reason: "StopLoss"})
+	report.AddTrade(BacktestTrade{Time: now, Side: "long", EntryPrice: 100, ExitPrice: 105, PnL: 50, PnLPercent: 5, Reason: "Manual"})
+	report.Decisions = 12
+
+	assert.InDelta(t, -70, report.NetPnL(), 1e-9)
+	assert.InDelta(t, 100.0*2/3, report.WinRate(), 1e-9)
+	assert.InDelta(t, 150.0/220, report.ProfitFactor(), 1e-9)
+
+	ddQuote, ddPct := report.MaxDrawdown()
+	assert.InDelta(t, 220, ddQuote, 1e-9)
+	assert.InDelta(t, 20, ddPct, 1e-9)
+
+	text := report.String()
+	assert.Contains(t, text, "Decision cycles: 12")
+	assert.Contains(t, text, "Trades: 3, win rate 66.7%, profit factor 0.68")
+	assert.Contains(t, text, "Net PnL: -70.00 (-7.00% of 1000.00)")
+	assert.Contains(t, text, "Max drawdown: 220.00 (20.00%)")
+	assert.Contains(t, text, "2024-01-01T00:00:00Z short 110 -> 121, PnL -220.00 (-10.00%), StopLoss")
+}
+
+func TestBacktestReportWithoutLosses(t *testing.T) {
+	report := NewBacktestReport("BTCUSDT", 0)
+	report.AddTrade(BacktestTrade{PnL: 10})
+
+	assert.True(t, math.IsInf(report.ProfitFactor(), 1))
+	assert.NotContains(t, report.String(), "% of")
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SyncedClock is the local clock corrected by the offset measured against the exchange server time, the zero
+// value is the local clock
+type SyncedClock struct {
+	offset atomic.Int64
+}
+
+// Now returns the local time corrected by the offset
+func (c *SyncedClock) Now() time.Time {
+	return time.Now().Add(c.Offset())
+}
+
+// Offset returns how far the exchange clock is ahead of the local one
+func (c *SyncedClock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+func (c *SyncedClock) SetOffset(offset time.Duration) {
+	c.offset.Store(int64(offset))
+}
+
+// MeasureClockSkew returns how far the server clock is ahead of the local one, assuming the server read its clock
+// halfway through the round trip, and the round trip
+func MeasureClockSkew(sent time.Time, received time.Time, server time.Time) (time.Duration, time.Duration) {
+	rtt := received.Sub(sent)
+	return server.Sub(sent.Add(rtt / 2)), rtt
+}
+
+// serverTimeKeys are the fields exchanges return their server time in, e.g. okx {"data":[{"ts":"..."}]} or
+// binance {"serverTime":...}
+var serverTimeKeys = []string{"serverTime", "ts", "time", "timestamp", "data"}
+
+// ParseServerTime extracts the server time from the JSON response of an exchange time endpoint, in milliseconds
+// or seconds since the epoch
+func ParseServerTime(body []byte) (time.Time, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return time.Time{}, errors.Wrap(err, "decode server time")
+	}
+
+	if t, ok := findServerTime(value); ok {
+		return t, nil
+	}
+
+	return time.Time{}, errors.Errorf("no server time in %s", string(body))
+}
+
+func findServerTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case float64:
+		return epochTime(v)
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return epochTime(f)
+	case []interface{}:
+		for _, item := range v {
+			if t, ok := findServerTime(item); ok {
+				return t, true
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range serverTimeKeys {
+			if item, ok := v[key]; ok {
+				if t, ok := findServerTime(item); ok {
+					return t, true
+				}
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// epochTime reads a timestamp in milliseconds, or in seconds when it is too small to be milliseconds
+func epochTime(v float64) (time.Time, bool) {
+	switch {
+	case v >= 1e12:
+		return time.UnixMilli(int64(v)), true
+	case v >= 1e9:
+		return time.UnixMilli(int64(v * 1000)), true
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasureClockSkew(t *testing.T) {
+	sent := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	received := sent.Add(200 * time.Millisecond)
+
+	skew, rtt := MeasureClockSkew(sent, received, sent.Add(1600*time.Millisecond))
+	assert.Equal(t, 1500*time.Millisecond, skew)
+	assert.Equal(t, 200*time.Millisecond, rtt)
+
+	skew, _ = MeasureClockSkew(sent, received, sent.Add(-time.Second))
+	assert.Equal(t, -1100*time.Millisecond, skew)
+}
+
+func TestParseServerTime(t *testing.T) {
+	want := time.UnixMilli(1700000000123)
+
+	for _, body := range []string{
+		`{"code":"0","data":[{"ts":"1700000000123"}],"msg":""}`,
+		`{"serverTime":1700000000123}`,
+		`{"retCode":0,"result":{"timeSecond":"1700000000"},"time":1700000000123}`,
+		`{"code":"200000","data":1700000000123}`,
+		`{"time":1700000000123}`,
+	} {
+		got, err := ParseServerTime([]byte(body))
+		assert.NoError(t, err, body)
+		assert.Equal(t, want, got, body)
+	}
+
+	got, err := ParseServerTime([]byte(`{"timestamp":1700000000}`))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0), got)
+
+	_, err = ParseServerTime([]byte(`{"status":"ok"}`))
+	assert.Error(t, err)
+}
+
+func TestSyncedClock(t *testing.T) {
+	var clock SyncedClock
+	assert.Equal(t, time.Duration(0), clock.Offset())
+
+	clock.SetOffset(time.Hour)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), clock.Now(), time.Second)
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ConfidenceGate rejects entries whose stated confidence is below a minimum that can be adjusted while running
+type ConfidenceGate struct {
+	min float64
+	mu  sync.Mutex
+}
+
+func NewConfidenceGate(min float64) *ConfidenceGate {
+	return &ConfidenceGate{min: min}
+}
+
+// Min returns the current minimum confidence
+func (g *ConfidenceGate) Min() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.min
+}
+
+// SetMin changes the minimum confidence, 0 lets every entry pass
+func (g *ConfidenceGate) SetMin(min float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.min = min
+}
+
+// Check returns why an entry with the confidence is rejected, empty when it may execute.
+// stated is false when the entry didn't state a confidence.
+func (g *ConfidenceGate) Check(confidence float64, stated bool) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.min <= 0 {
+		return ""
+	}
+
+	if !stated {
+		return fmt.Sprintf("entries need a confidence arg of at least %.2f", g.min)
+	}
+
+	if confidence < g.min {
+		return fmt.Sprintf("confidence %.2f is below the minimum %.2f", confidence, g.min)
+	}
+
+	return ""
+}
@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfidenceGate(t *testing.T) {
+	gate := NewConfidenceGate(0)
+	assert.Empty(t, gate.Check(0, false))
+
+	gate.SetMin(0.6)
+	assert.Equal(t, 0.6, gate.Min())
+	assert.Contains(t, gate.Check(0, false), "need a confidence arg")
+	assert.Contains(t, gate.Check(0.5, true), "below the minimum 0.60")
+	assert.Empty(t, gate.Check(0.6, true))
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EstimateTokens roughly estimates the tokens of a text, erring on the high side for non-English text
+func EstimateTokens(text string) int {
+	return (len(text) + 2) / 3
+}
+
+// ContextBudget is the estimated token count contributed by each section of a prompt
+type ContextBudget struct {
+	tokens map[string]int
+}
+
+func NewContextBudget() *ContextBudget {
+	return &ContextBudget{tokens: make(map[string]int)}
+}
+
+// Add counts the text into the section
+func (b *ContextBudget) Add(section string, text string) {
+	b.AddTokens(section, EstimateTokens(text))
+}
+
+// AddTokens counts tokens into the section
+func (b *ContextBudget) AddTokens(section string, tokens int) {
+	if tokens <= 0 {
+		return
+	}
+
+	b.tokens[section] += tokens
+}
+
+// Tokens returns the tokens of the section
+func (b *ContextBudget) Tokens(section string) int {
+	return b.tokens[section]
+}
+
+// Total returns the tokens of all the sections
+func (b *ContextBudget) Total() int {
+	total := 0
+	for _, tokens := range b.tokens {
+		total += tokens
+	}
+
+	return total
+}
+
+// String renders the sections from the largest, with their share of the total
+func (b *ContextBudget) String() string {
+	sections := make([]string, 0, len(b.tokens))
+	for section := range b.tokens {
+		sections = append(sections, section)
+	}
+
+	sort.Slice(sections, func(i, j int) bool {
+		if b.tokens[sections[i]] != b.tokens[sections[j]] {
+			return b.tokens[sections[i]] > b.tokens[sections[j]]
+		}
+		return sections[i] < sections[j]
+	})
+
+	total := b.Total()
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		parts = append(parts, fmt.Sprintf("%s %d (%.0f%%)", section, b.tokens[section], float64(b.tokens[section])*100/float64(total)))
+	}
+
+	return fmt.Sprintf("~%d tokens: %s", total, strings.Join(parts, ", "))
+}
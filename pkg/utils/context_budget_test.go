@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextBudget(t *testing.T) {
+	budget := NewContextBudget()
+	budget.Add("klines", strings.Repeat("k", 598))
+	budget.Add("indicators", strings.Repeat("i", 148))
+	budget.Add("indicators", strings.Repeat("i", 148))
+	budget.Add("memories", "")
+	budget.AddTokens("history", -5)
+	budget.Add("instructions", strings.Repeat("s", 298))
+
+	assert.Equal(t, 200, budget.Tokens("klines"))
+	assert.Equal(t, 100, budget.Tokens("indicators"))
+	assert.Equal(t, 0, budget.Tokens("memories"))
+	assert.Equal(t, 400, budget.Total())
+	assert.Equal(t, "~400 tokens: klines 200 (50%), indicators 100 (25%), instructions 100 (25%)", budget.String())
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// atrPeriod is the number of klines used to compute the ATR for counterfactual stops
+const atrPeriod = 14
+
+// Counterfactual is an alternative outcome of a closed trade under a different exit rule
+type Counterfactual struct {
+	Name       string
+	ExitPrice  float64
+	PnLPercent float64
+}
+
+func (c Counterfactual) String() string {
+	return fmt.Sprintf("%s: exit %.4f, PnL %.2f%%", c.Name, c.ExitPrice, c.PnLPercent)
+}
+
+// ComputeCounterfactuals replays the klines of a closed trade under alternative exit rules.
+// The window ends with afterClose klines observed after the close, preceded by the holdingPeriod klines of the trade.
+func ComputeCounterfactuals(window types.KLineWindow, side string, entryPrice float64, exitPrice float64, holdingPeriod int, afterClose int) []Counterfactual {
+	rets := make([]Counterfactual, 0)
+	if len(window) == 0 || entryPrice <= 0 {
+		return rets
+	}
+
+	afterClose = min(afterClose, len(window))
+	holdingPeriod = min(holdingPeriod, len(window)-afterClose)
+	start := len(window) - afterClose - holdingPeriod
+	short := side == "short"
+
+	pnlPercent := func(exit float64) float64 {
+		if short {
+			return (entryPrice - exit) / entryPrice * 100
+		}
+		return (exit - entryPrice) / entryPrice * 100
+	}
+
+	rets = append(rets, Counterfactual{Name: "Actual exit", ExitPrice: exitPrice, PnLPercent: pnlPercent(exitPrice)})
+
+	// ATR from the klines before entry, falling back to the trade klines
+	atrKlines := window[max(0, start-atrPeriod):start]
+	if len(atrKlines) < 2 {
+		atrKlines = window[start : len(window)-afterClose]
+	}
+
+	atr := averageTrueRange(atrKlines)
+	if atr > 0 {
+		stop := entryPrice - 2*atr
+		if short {
+			stop = entryPrice + 2*atr
+		}
+
+		exit := window[len(window)-1].Close.Float64()
+		for _, k := range window[start:] {
+			if (!short && k.Low.Float64() <= stop) || (short && k.High.Float64() >= stop) {
+				exit = stop
+				break
+			}
+		}
+
+		rets = append(rets, Counterfactual{Name: fmt.Sprintf("Stop loss at 2xATR (%.4f)", stop), ExitPrice: exit, PnLPercent: pnlPercent(exit)})
+	}
+
+	if afterClose > 0 {
+		exit := window[len(window)-1].Close.Float64()
+		rets = append(rets, Counterfactual{Name: fmt.Sprintf("Held %d more klines", afterClose), ExitPrice: exit, PnLPercent: pnlPercent(exit)})
+	}
+
+	return rets
+}
+
+// averageTrueRange returns the mean true range of the klines
+func averageTrueRange(klines types.KLineWindow) float64 {
+	if len(klines) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for i, k := range klines {
+		tr := k.High.Float64() - k.Low.Float64()
+		if i > 0 {
+			prevClose := klines[i-1].Close.Float64()
+			tr = math.Max(tr, math.Max(math.Abs(k.High.Float64()-prevClose), math.Abs(k.Low.Float64()-prevClose)))
+		}
+		sum += tr
+	}
+
+	return sum / float64(len(klines))
+}
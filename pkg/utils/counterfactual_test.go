@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newKLine(high, low, close float64) types.KLine {
+	return types.KLine{
+		High:  fixedpoint.NewFromFloat(high),
+		Low:   fixedpoint.NewFromFloat(low),
+		Close: fixedpoint.NewFromFloat(close),
+	}
+}
+
+func TestComputeCounterfactuals_Long(t *testing.T) {
+	window := types.KLineWindow{
+		// before entry, true range 2
+		newKLine(101, 99, 100),
+		newKLine(101, 99, 100),
+		// trade klines, stopped out at 98.5 by a tight stop
+		newKLine(101, 98, 98.5),
+		// after close, price recovers without touching 2xATR stop at 96
+		newKLine(103, 97, 102),
+		newKLine(106, 101, 105),
+	}
+
+	cfs := ComputeCounterfactuals(window, "long", 100, 98.5, 1, 2)
+	assert.Len(t, cfs, 3)
+
+	assert.Equal(t, "Actual exit", cfs[0].Name)
+	assert.InDelta(t, -1.5, cfs[0].PnLPercent, 0.0001)
+
+	assert.InDelta(t, 105, cfs[1].ExitPrice, 0.0001)
+	assert.InDelta(t, 5, cfs[1].PnLPercent, 0.0001)
+
+	assert.Equal(t, "Held 2 more klines", cfs[2].Name)
+	assert.InDelta(t, 5, cfs[2].PnLPercent, 0.0001)
+}
+
+func TestComputeCounterfactuals_ShortStopHit(t *testing.T) {
+	window := types.KLineWindow{
+		newKLine(101, 99, 100),
+		newKLine(101, 99, 100),
+		newKLine(105, 99, 104),
+	}
+
+	cfs := ComputeCounterfactuals(window, "short", 100, 99, 1, 0)
+	assert.Len(t, cfs, 2)
+	assert.InDelta(t, 1, cfs[0].PnLPercent, 0.0001)
+	assert.InDelta(t, 104, cfs[1].ExitPrice, 0.0001)
+	assert.InDelta(t, -4, cfs[1].PnLPercent, 0.0001)
+}
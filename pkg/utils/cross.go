@@ -0,0 +1,15 @@
+package utils
+
+// CrossOver returns 1 when series a crosses above series b between the previous and current values,
+// -1 when it crosses below, and 0 otherwise
+func CrossOver(prevA, currA, prevB, currB float64) int {
+	if prevA <= prevB && currA > currB {
+		return 1
+	}
+
+	if prevA >= prevB && currA < currB {
+		return -1
+	}
+
+	return 0
+}
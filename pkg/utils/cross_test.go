@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossOver(t *testing.T) {
+	// RSI crossing the 70 threshold
+	assert.Equal(t, 1, CrossOver(68, 71, 70, 70))
+	assert.Equal(t, -1, CrossOver(71, 69, 70, 70))
+	assert.Equal(t, 0, CrossOver(71, 72, 70, 70))
+	assert.Equal(t, 0, CrossOver(70, 70, 70, 70))
+
+	// Fast line crossing a moving slow line
+	assert.Equal(t, 1, CrossOver(1.0, 1.2, 1.1, 1.15))
+	assert.Equal(t, -1, CrossOver(1.2, 1.0, 1.1, 1.05))
+}
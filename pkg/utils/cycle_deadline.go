@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"context"
+	"time"
+)
+
+// CycleTimeout returns how long a decision cycle may take: the given share of the kline interval,
+// but at least min, so a slow call can't run into the next kline
+func CycleTimeout(interval time.Duration, ratio float64, min time.Duration) time.Duration {
+	timeout := time.Duration(float64(interval) * ratio)
+	if timeout < min {
+		return min
+	}
+
+	return timeout
+}
+
+// SleepContext sleeps for the duration, returning early with the context error when it is done
+func SleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycleTimeout(t *testing.T) {
+	assert.Equal(t, 48*time.Minute, CycleTimeout(time.Hour, 0.8, 30*time.Second))
+	assert.Equal(t, 30*time.Second, CycleTimeout(30*time.Second, 0.8, 30*time.Second))
+}
+
+func TestSleepContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, SleepContext(ctx, time.Hour), context.Canceled)
+	assert.NoError(t, SleepContext(context.Background(), time.Millisecond))
+}
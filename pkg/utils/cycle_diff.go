@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// CycleState is what a decision cycle was shown, compared with the next cycle to tell what changed
+type CycleState struct {
+	Close         float64
+	Side          string             // long or short, empty when flat
+	ProfitPercent float64            // Accumulated profit of the position in percent
+	Indicators    map[string]float64 // Latest value by indicator name
+	Events        []string           // Types of the events besides the ones every cycle has
+}
+
+// NewCycleState creates an empty cycle state
+func NewCycleState() *CycleState {
+	return &CycleState{
+		Indicators: make(map[string]float64),
+	}
+}
+
+// AddEvent records the event type once
+func (c *CycleState) AddEvent(eventType string) {
+	if !contains(c.Events, eventType) {
+		c.Events = append(c.Events, eventType)
+	}
+}
+
+// DiffCycles describes the changes from the previous cycle, empty without a previous cycle
+func DiffCycles(prev, curr *CycleState, precision config.PrecisionConfig) string {
+	if prev == nil || curr == nil {
+		return ""
+	}
+
+	lines := make([]string, 0)
+
+	if prev.Close > 0 && curr.Close > 0 && curr.Close != prev.Close {
+		lines = append(lines, fmt.Sprintf("- Close: %s -> %s (%s)",
+			FormatNumber(prev.Close, precision.Price),
+			FormatNumber(curr.Close, precision.Price),
+			signed(FormatPercent((curr.Close-prev.Close)/prev.Close*100, precision.Percent))))
+	}
+
+	switch {
+	case prev.Side != curr.Side:
+		lines = append(lines, fmt.Sprintf("- Position: %s -> %s", sideOrFlat(prev.Side), sideOrFlat(curr.Side)))
+	case curr.Side != "" && curr.ProfitPercent != prev.ProfitPercent:
+		lines = append(lines, fmt.Sprintf("- Position profit: %s -> %s (%s pts)",
+			FormatPercent(prev.ProfitPercent, precision.Percent),
+			FormatPercent(curr.ProfitPercent, precision.Percent),
+			signed(FormatNumber(curr.ProfitPercent-prev.ProfitPercent, precision.Percent))))
+	}
+
+	names := make([]string, 0, len(curr.Indicators))
+	for name := range curr.Indicators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := curr.Indicators[name]
+		prevValue, ok := prev.Indicators[name]
+		if !ok || FormatNumber(prevValue, precision.Price) == FormatNumber(value, precision.Price) {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("- %s: %s -> %s", name, FormatNumber(prevValue, precision.Price), FormatNumber(value, precision.Price)))
+	}
+
+	newEvents := make([]string, 0)
+	for _, eventType := range curr.Events {
+		if !contains(prev.Events, eventType) {
+			newEvents = append(newEvents, eventType)
+		}
+	}
+	if len(newEvents) > 0 {
+		lines = append(lines, fmt.Sprintf("- New events: %s", strings.Join(newEvents, ", ")))
+	}
+
+	if len(lines) == 0 {
+		return "Changes since the last decision cycle: none, focus on whether the plan still holds."
+	}
+
+	return fmt.Sprintf("Changes since the last decision cycle:\n%s", strings.Join(lines, "\n"))
+}
+
+func sideOrFlat(side string) string {
+	if side == "" {
+		return "flat"
+	}
+
+	return side
+}
+
+func signed(text string) string {
+	if strings.HasPrefix(text, "-") {
+		return text
+	}
+
+	return "+" + text
+}
+
+func contains(items []string, item string) bool {
+	for _, existing := range items {
+		if existing == item {
+			return true
+		}
+	}
+
+	return false
+}
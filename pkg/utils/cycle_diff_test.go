@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestDiffCycles(t *testing.T) {
+	two := 2
+	precision := config.PrecisionConfig{
+		Price:   config.NumberFormat{Decimals: &two},
+		Percent: config.NumberFormat{Decimals: &two},
+	}
+
+	prev := NewCycleState()
+	prev.Close = 50000
+	prev.Indicators["RSI"] = 48.2
+	prev.Indicators["MA"] = 49800
+	prev.AddEvent("price_alert")
+
+	curr := NewCycleState()
+	curr.Close = 50500
+	curr.Side = "long"
+	curr.Indicators["RSI"] = 71.3
+	curr.Indicators["MA"] = 49800
+	curr.AddEvent("indicator_alert")
+	curr.AddEvent("price_alert")
+	curr.AddEvent("indicator_alert")
+
+	assert.Equal(t, "", DiffCycles(nil, curr, precision))
+	assert.Equal(t, "Changes since the last decision cycle:\n"+
+		"- Close: 50000.00 -> 50500.00 (+1.00%)\n"+
+		"- Position: flat -> long\n"+
+		"- RSI: 48.20 -> 71.30\n"+
+		"- New events: indicator_alert", DiffCycles(prev, curr, precision))
+
+	next := NewCycleState()
+	next.Close = 50400
+	next.Side = "long"
+	next.ProfitPercent = -0.8
+	next.Indicators["RSI"] = 71.3
+	next.Indicators["MA"] = 49800
+	assert.Equal(t, "Changes since the last decision cycle:\n"+
+		"- Close: 50500.00 -> 50400.00 (-0.20%)\n"+
+		"- Position profit: 0.00% -> -0.80% (-0.80 pts)", DiffCycles(curr, next, precision))
+
+	assert.Equal(t, "Changes since the last decision cycle: none, focus on whether the plan still holds.", DiffCycles(next, next, precision))
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// DeadManSwitch asks the operator for periodic heartbeats and trips when a ping is not acknowledged in time.
+// It resets once the operator acknowledges again.
+type DeadManSwitch struct {
+	interval time.Duration
+	window   time.Duration
+
+	lastAck time.Time
+	pingAt  time.Time // time of the unacknowledged ping, zero when none
+	tripped bool
+	mu      sync.Mutex
+}
+
+func NewDeadManSwitch(interval time.Duration, window time.Duration, now time.Time) *DeadManSwitch {
+	return &DeadManSwitch{
+		interval: interval,
+		window:   window,
+		lastAck:  now,
+	}
+}
+
+// Tick returns whether a ping is due now and whether the switch just tripped
+func (d *DeadManSwitch) Tick(now time.Time) (ping bool, tripped bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.pingAt.IsZero() {
+		if now.Sub(d.lastAck) >= d.interval {
+			d.pingAt = now
+			return true, false
+		}
+		return false, false
+	}
+
+	if !d.tripped && now.Sub(d.pingAt) >= d.window {
+		d.tripped = true
+		return false, true
+	}
+
+	// Keep reminding the operator while tripped
+	if d.tripped && now.Sub(d.pingAt) >= d.interval {
+		d.pingAt = now
+		return true, false
+	}
+
+	return false, false
+}
+
+// Ack records an operator heartbeat and returns whether it reset a tripped switch
+func (d *DeadManSwitch) Ack(now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	restored := d.tripped
+	d.lastAck = now
+	d.pingAt = time.Time{}
+	d.tripped = false
+
+	return restored
+}
+
+// Tripped returns whether the operator missed a heartbeat and has not acknowledged since
+func (d *DeadManSwitch) Tripped() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.tripped
+}
+
+// TightenStop returns the stop of a "long" or "short" position keeping the ratio of the distance between the
+// price and the current stop, or at fallbackPercent from the price without a stop. It never loosens the stop.
+func TightenStop(side string, price float64, stop float64, ratio float64, fallbackPercent float64) float64 {
+	long := side == "long"
+
+	distance := price * fallbackPercent / 100
+	if stop > 0 {
+		if long {
+			distance = (price - stop) * ratio
+		} else {
+			distance = (stop - price) * ratio
+		}
+	}
+
+	// The price already crossed the stop, nothing to tighten
+	if distance <= 0 {
+		return stop
+	}
+
+	if long {
+		return price - distance
+	}
+	return price + distance
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadManSwitch(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := NewDeadManSwitch(4*time.Hour, 30*time.Minute, start)
+
+	ping, tripped := d.Tick(start.Add(time.Hour))
+	assert.False(t, ping)
+	assert.False(t, tripped)
+
+	ping, _ = d.Tick(start.Add(4 * time.Hour))
+	assert.True(t, ping)
+
+	// Acknowledged in time
+	assert.False(t, d.Ack(start.Add(4*time.Hour+10*time.Minute)))
+	assert.False(t, d.Tripped())
+
+	ping, _ = d.Tick(start.Add(8*time.Hour + 10*time.Minute))
+	assert.True(t, ping)
+
+	_, tripped = d.Tick(start.Add(8*time.Hour + 30*time.Minute))
+	assert.False(t, tripped)
+
+	_, tripped = d.Tick(start.Add(8*time.Hour + 40*time.Minute))
+	assert.True(t, tripped)
+	assert.True(t, d.Tripped())
+
+	// Trips only once, then keeps reminding
+	_, tripped = d.Tick(start.Add(9 * time.Hour))
+	assert.False(t, tripped)
+	ping, _ = d.Tick(start.Add(12*time.Hour + 10*time.Minute))
+	assert.True(t, ping)
+
+	assert.True(t, d.Ack(start.Add(13*time.Hour)))
+	assert.False(t, d.Tripped())
+}
+
+func TestTightenStop(t *testing.T) {
+	assert.InDelta(t, 95, TightenStop("long", 100, 90, 0.5, 2), 1e-9)
+	assert.InDelta(t, 105, TightenStop("short", 100, 110, 0.5, 2), 1e-9)
+
+	// Without a stop
+	assert.InDelta(t, 98, TightenStop("long", 100, 0, 0.5, 2), 1e-9)
+	assert.InDelta(t, 102, TightenStop("short", 100, 0, 0.5, 2), 1e-9)
+
+	// The price already crossed the stop
+	assert.InDelta(t, 101, TightenStop("long", 100, 101, 0.5, 2), 1e-9)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Categories of command execution errors, to surface systematic execution problems
+const (
+	ExecErrorMinNotional         = "min_notional"
+	ExecErrorInsufficientBalance = "insufficient_balance"
+	ExecErrorRateLimit           = "rate_limit"
+	ExecErrorTimeout             = "timeout"
+	ExecErrorNetwork             = "network"
+	ExecErrorObserveOnly         = "observe_only"
+	ExecErrorInvalidArgs         = "invalid_args"
+	ExecErrorRejected            = "rejected"
+	ExecErrorOther               = "other"
+)
+
+// execErrorPatterns are matched in order against the lower-cased error message
+var execErrorPatterns = []struct {
+	category string
+	patterns []string
+}{
+	{ExecErrorObserveOnly, []string{"observe-only"}},
+	{ExecErrorMinNotional, []string{"min notional", "minnotional", "min_notional", "too small", "minimum amount", "minimum order"}},
+	{ExecErrorInsufficientBalance, []string{"insufficient", "not enough balance", "margin is insufficient"}},
+	{ExecErrorRateLimit, []string{"rate limit", "too many requests", "429"}},
+	{ExecErrorTimeout, []string{"timeout", "timed out", "deadline exceeded"}},
+	{ExecErrorNetwork, []string{"connection", "eof", "no such host", "broken pipe"}},
+	{ExecErrorInvalidArgs, []string{"invalid", "required", "must be", "unsupported", "unknown"}},
+	{ExecErrorRejected, []string{"rejected", "blocked", "not allowed", "already", "no existing", "no grid", "no spread"}},
+}
+
+// CategorizeExecError returns the category of a command execution error, empty for nil
+func CategorizeExecError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ExecErrorTimeout
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, p := range execErrorPatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(msg, pattern) {
+				return p.category
+			}
+		}
+	}
+
+	return ExecErrorOther
+}
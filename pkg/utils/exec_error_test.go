@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorizeExecError(t *testing.T) {
+	assert.Equal(t, "", CategorizeExecError(nil))
+	assert.Equal(t, ExecErrorMinNotional, CategorizeExecError(errors.New("open position error: BTCUSDT order quantity 0.0001 is too small, less than 0.001")))
+	assert.Equal(t, ExecErrorInsufficientBalance, CategorizeExecError(errors.New("Insufficient USDT balance")))
+	assert.Equal(t, ExecErrorRateLimit, CategorizeExecError(errors.New("429 Too Many Requests")))
+	assert.Equal(t, ExecErrorTimeout, CategorizeExecError(errors.Wrap(context.DeadlineExceeded, "submit order")))
+	assert.Equal(t, ExecErrorObserveOnly, CategorizeExecError(errors.New("observe-only, another instance is trading this account and symbol")))
+	assert.Equal(t, ExecErrorInvalidArgs, CategorizeExecError(errors.New("limit_price is required when order_type=limit")))
+	assert.Equal(t, ExecErrorRejected, CategorizeExecError(errors.New("no existing open position")))
+	assert.Equal(t, ExecErrorOther, CategorizeExecError(errors.New("something odd")))
+}
@@ -0,0 +1,57 @@
+package utils
+
+import "sync"
+
+// FailureWindow counts the failures among the latest outcomes
+type FailureWindow struct {
+	size     int
+	outcomes []bool
+	mu       sync.Mutex
+}
+
+func NewFailureWindow(size int) *FailureWindow {
+	if size < 1 {
+		size = 1
+	}
+
+	return &FailureWindow{
+		size:     size,
+		outcomes: make([]bool, 0, size),
+	}
+}
+
+// Record adds an outcome, dropping the oldest one when the window is full, and returns the failures in the window
+func (w *FailureWindow) Record(failed bool) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.outcomes) == w.size {
+		w.outcomes = w.outcomes[1:]
+	}
+	w.outcomes = append(w.outcomes, failed)
+
+	failures := 0
+	for _, outcome := range w.outcomes {
+		if outcome {
+			failures++
+		}
+	}
+
+	return failures
+}
+
+// Reset forgets all outcomes
+func (w *FailureWindow) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.outcomes = w.outcomes[:0]
+}
+
+// Outcomes returns the outcomes in the window, oldest first
+func (w *FailureWindow) Outcomes() []bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]bool{}, w.outcomes...)
+}
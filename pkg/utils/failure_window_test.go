@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureWindow(t *testing.T) {
+	w := NewFailureWindow(3)
+
+	assert.Equal(t, 1, w.Record(true))
+	assert.Equal(t, 1, w.Record(false))
+	assert.Equal(t, 2, w.Record(true))
+	// The first failure drops out of the window
+	assert.Equal(t, 2, w.Record(true))
+	assert.Equal(t, 2, w.Record(false))
+	assert.Equal(t, []bool{true, true, false}, w.Outcomes())
+
+	w.Reset()
+	assert.Equal(t, 0, w.Record(false))
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FlatSchedule is a recurring daily or weekly deadline by which positions must be flat
+type FlatSchedule struct {
+	hour    int
+	minute  int
+	weekday *time.Weekday // nil for a daily deadline
+	loc     *time.Location
+}
+
+// ParseFlatSchedule parses a "HH:MM" clock time, an optional weekday name and an optional IANA timezone
+func ParseFlatSchedule(clock string, weekday string, timezone string) (*FlatSchedule, error) {
+	at, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return nil, errors.Errorf("invalid flat-by time %q, expected HH:MM", clock)
+	}
+
+	schedule := &FlatSchedule{
+		hour:   at.Hour(),
+		minute: at.Minute(),
+		loc:    time.UTC,
+	}
+
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid flat-by timezone %q", timezone)
+		}
+		schedule.loc = loc
+	}
+
+	if weekday != "" {
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(day.String(), strings.TrimSpace(weekday)) {
+				schedule.weekday = &day
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, errors.Errorf("invalid flat-by weekday %q", weekday)
+		}
+	}
+
+	return schedule, nil
+}
+
+// Next returns the first deadline after t
+func (s *FlatSchedule) Next(t time.Time) time.Time {
+	local := t.In(s.loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.hour, s.minute, 0, 0, s.loc)
+
+	for !next.After(local) || (s.weekday != nil && next.Weekday() != *s.weekday) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+1, s.hour, s.minute, 0, 0, s.loc)
+	}
+
+	return next
+}
+
+// Crossed returns whether a deadline passed after from and at or before to
+func (s *FlatSchedule) Crossed(from time.Time, to time.Time) bool {
+	return !s.Next(from).After(to)
+}
+
+// String describes the schedule, e.g. "Friday 21:00 UTC"
+func (s *FlatSchedule) String() string {
+	clock := fmt.Sprintf("%02d:%02d %s", s.hour, s.minute, s.loc)
+	if s.weekday == nil {
+		return "daily " + clock
+	}
+
+	return fmt.Sprintf("%s %s", s.weekday, clock)
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlatScheduleDaily(t *testing.T) {
+	schedule, err := ParseFlatSchedule("21:00", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "daily 21:00 UTC", schedule.String())
+
+	now := time.Date(2024, 1, 5, 20, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC), schedule.Next(now))
+
+	// At the deadline the next one is a day later
+	now = time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 6, 21, 0, 0, 0, time.UTC), schedule.Next(now))
+
+	assert.True(t, schedule.Crossed(time.Date(2024, 1, 5, 20, 55, 0, 0, time.UTC), time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC)))
+	assert.False(t, schedule.Crossed(time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC), time.Date(2024, 1, 5, 21, 5, 0, 0, time.UTC)))
+}
+
+func TestFlatScheduleWeekly(t *testing.T) {
+	schedule, err := ParseFlatSchedule("20:00", "friday", "America/Chicago")
+	assert.NoError(t, err)
+	assert.Equal(t, "Friday 20:00 America/Chicago", schedule.String())
+
+	// Monday 2024-01-01 12:00 UTC
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	next := schedule.Next(now)
+	assert.Equal(t, time.Friday, next.Weekday())
+	assert.Equal(t, time.Date(2024, 1, 6, 2, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestParseFlatScheduleInvalid(t *testing.T) {
+	_, err := ParseFlatSchedule("25:00", "", "")
+	assert.Error(t, err)
+
+	_, err = ParseFlatSchedule("21:00", "someday", "")
+	assert.Error(t, err)
+
+	_, err = ParseFlatSchedule("21:00", "", "Mars/Base")
+	assert.Error(t, err)
+}
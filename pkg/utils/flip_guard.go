@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FlipGuard holds back reversing the direction shortly after an entry until consecutive decision cycles
+// confirm it or the signal is confident enough. Exits are never held back.
+type FlipGuard struct {
+	window        int
+	confirmations int
+	minConfidence float64
+
+	entrySide        string
+	klinesSinceEntry int
+	pendingSide      string
+	pendingCount     int
+	pendingKline     int
+	mu               sync.Mutex
+}
+
+func NewFlipGuard(window int, confirmations int, minConfidence float64) *FlipGuard {
+	return &FlipGuard{
+		window:        window,
+		confirmations: confirmations,
+		minConfidence: minConfidence,
+	}
+}
+
+// OnKline advances the klines counted since the latest entry
+func (g *FlipGuard) OnKline() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.klinesSinceEntry++
+}
+
+// OnEntry records an executed entry of the side, "long" or "short"
+func (g *FlipGuard) OnEntry(side string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.entrySide = side
+	g.klinesSinceEntry = 0
+	g.pendingSide = ""
+	g.pendingCount = 0
+}
+
+// Request counts an entry request of the side. Requests in the same kline count as one confirmation, so the
+// passes of a decision cycle may each record it, and a kline without a request starts the count over.
+func (g *FlipGuard) Request(side string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.entrySide == "" || side == g.entrySide || g.klinesSinceEntry >= g.window {
+		return
+	}
+
+	switch {
+	case g.pendingSide != side || g.klinesSinceEntry > g.pendingKline+1:
+		g.pendingSide = side
+		g.pendingCount = 1
+	case g.klinesSinceEntry == g.pendingKline+1:
+		g.pendingCount++
+	}
+	g.pendingKline = g.klinesSinceEntry
+}
+
+// Check returns why an entry of the side is held back, empty when it may execute. It only reads the requests
+// counted by Request, so checking an entry again does not change the outcome.
+func (g *FlipGuard) Check(side string, confidence float64) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.entrySide == "" || side == g.entrySide || g.klinesSinceEntry >= g.window {
+		return ""
+	}
+
+	if g.minConfidence > 0 && confidence >= g.minConfidence {
+		return ""
+	}
+
+	count := 0
+	if g.pendingSide == side && g.pendingKline == g.klinesSinceEntry {
+		count = g.pendingCount
+	}
+
+	if count >= g.confirmations {
+		return ""
+	}
+
+	reason := fmt.Sprintf("reversing the %s entry of %d klines ago to %s needs %d consecutive decision cycles asking for it (%d so far)",
+		g.entrySide, g.klinesSinceEntry, side, g.confirmations, count)
+	if g.minConfidence > 0 {
+		reason += fmt.Sprintf(" or a confidence of at least %.2f", g.minConfidence)
+	}
+
+	return reason + ". Closing the position is always allowed"
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// request records an entry request and checks it, as a decision cycle does
+func request(g *FlipGuard, side string, confidence float64) string {
+	g.Request(side)
+	return g.Check(side, confidence)
+}
+
+func TestFlipGuard(t *testing.T) {
+	g := NewFlipGuard(3, 2, 0.8)
+
+	// Nothing to reverse yet
+	assert.Empty(t, request(g, "short", 0))
+
+	g.OnEntry("long")
+	assert.Empty(t, request(g, "long", 0))
+
+	// A retry in the same kline is no confirmation
+	assert.NotEmpty(t, request(g, "short", 0.5))
+	assert.NotEmpty(t, request(g, "short", 0.5))
+
+	g.OnKline()
+	assert.Empty(t, request(g, "short", 0.5))
+
+	// Checking the confirmed reversal again, as the confirmation pass does, keeps it allowed
+	assert.Empty(t, g.Check("short", 0.5))
+	assert.Empty(t, request(g, "short", 0.5))
+
+	// A kline without the request starts the count over
+	g.OnEntry("short")
+	assert.NotEmpty(t, request(g, "long", 0))
+	g.OnKline()
+	g.OnKline()
+	assert.Contains(t, request(g, "long", 0), "(1 so far)")
+
+	// A confident signal reverses at once
+	assert.Empty(t, request(g, "long", 0.9))
+
+	// Outside the window reversals are free
+	g.OnKline()
+	assert.Empty(t, request(g, "long", 0))
+}
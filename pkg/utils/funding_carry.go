@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// FundingCarry is the funding income or cost of holding a perpetual position
+type FundingCarry struct {
+	Side         string
+	Rate         float64       // Funding rate per period, positive when longs pay shorts
+	Interval     time.Duration // Funding period
+	DailyPercent float64       // Funding received per day in percent of the notional, negative when paid
+	DailyValue   float64       // Funding received per day in quote currency, negative when paid
+}
+
+// ComputeFundingCarry computes the daily funding carry of a long or short position of the notional
+func ComputeFundingCarry(side string, rate float64, interval time.Duration, notional float64) *FundingCarry {
+	if interval <= 0 {
+		interval = time.Hour * 8
+	}
+
+	received := -rate
+	if side == "short" {
+		received = rate
+	}
+
+	periodsPerDay := float64(time.Hour*24) / float64(interval)
+
+	return &FundingCarry{
+		Side:         side,
+		Rate:         rate,
+		Interval:     interval,
+		DailyPercent: received * periodsPerDay * 100,
+		DailyValue:   received * periodsPerDay * math.Abs(notional),
+	}
+}
+
+// Favorable returns whether the position receives funding
+func (c *FundingCarry) Favorable() bool {
+	return c.DailyValue > 0
+}
+
+// Prompt describes strong funding for the position prompt. It is empty below the threshold rate per period,
+// and suggests holding for favorable funding only with holdHint.
+func (c *FundingCarry) Prompt(threshold float64, holdHint bool, quoteCurrency string, format config.NumberFormat) string {
+	if math.Abs(c.Rate) < threshold || c.Rate == 0 {
+		return ""
+	}
+
+	period := c.Interval.String()
+	if c.Interval%time.Hour == 0 {
+		period = fmt.Sprintf("%dh", c.Interval/time.Hour)
+	}
+	rate := fmt.Sprintf("%s per %s", FormatPercent(c.Rate*100, format), period)
+
+	if c.Favorable() {
+		if !holdHint {
+			return ""
+		}
+
+		return fmt.Sprintf("Funding strongly favors your %s position: the funding rate is %s, earning about %s (%s %s) per day. Consider holding for funding while the trade setup stays valid.",
+			c.Side, rate, FormatPercent(c.DailyPercent, format), FormatNumber(c.DailyValue, format), quoteCurrency)
+	}
+
+	return fmt.Sprintf("Funding strongly opposes your %s position: the funding rate is %s, so holding costs about %s (%s %s) per day. Weigh this carrying cost against the expected move.",
+		c.Side, rate, FormatPercent(-c.DailyPercent, format), FormatNumber(-c.DailyValue, format), quoteCurrency)
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestComputeFundingCarry(t *testing.T) {
+	// Longs pay 0.05% every 8h on 10000 notional: 0.15% and 15 per day
+	long := ComputeFundingCarry("long", 0.0005, time.Hour*8, 10000)
+	assert.False(t, long.Favorable())
+	assert.InDelta(t, -0.15, long.DailyPercent, 1e-9)
+	assert.InDelta(t, -15, long.DailyValue, 1e-9)
+
+	short := ComputeFundingCarry("short", 0.0005, time.Hour*4, -10000)
+	assert.True(t, short.Favorable())
+	assert.InDelta(t, 30, short.DailyValue, 1e-9)
+}
+
+func TestFundingCarryPrompt(t *testing.T) {
+	decimals := 2
+	format := config.NumberFormat{Decimals: &decimals}
+
+	long := ComputeFundingCarry("long", 0.0005, time.Hour*8, 10000)
+	assert.Equal(t, "Funding strongly opposes your long position: the funding rate is 0.05% per 8h, so holding costs about 0.15% (15.00 USDT) per day. Weigh this carrying cost against the expected move.",
+		long.Prompt(0.0003, false, "USDT", format))
+	assert.Equal(t, "", long.Prompt(0.001, false, "USDT", format))
+
+	short := ComputeFundingCarry("short", 0.0005, time.Hour*8, 10000)
+	assert.Equal(t, "", short.Prompt(0.0003, false, "USDT", format))
+	assert.Contains(t, short.Prompt(0.0003, true, "USDT", format), "Consider holding for funding")
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	GridSideBuy  = "buy"
+	GridSideSell = "sell"
+)
+
+// GridLevel is a price level of the grid, with the order resting at it
+type GridLevel struct {
+	Price   float64 `json:"price"`
+	Side    string  `json:"side"` // Side of the resting order, empty when the level has none
+	OrderID uint64  `json:"order_id"`
+}
+
+// Grid buys at the levels below the price and sells at the levels above, replacing each filled order with
+// the opposite order one level away
+type Grid struct {
+	Lower    float64     `json:"lower"`
+	Upper    float64     `json:"upper"`
+	Quantity float64     `json:"quantity"` // Base quantity per level
+	Levels   []GridLevel `json:"levels"`
+	Fills    int         `json:"fills"`
+	Profit   float64     `json:"profit"` // Profit of the completed buy-sell round trips, before fees
+}
+
+// NewGrid spaces the levels evenly over the range and places buys below the price and sells above it,
+// leaving the level closest to the price empty
+func NewGrid(lower, upper float64, levels int, quantity float64, price float64) (*Grid, error) {
+	if lower <= 0 || upper <= lower {
+		return nil, errors.Errorf("invalid grid range %g - %g", lower, upper)
+	}
+	if levels < 2 {
+		return nil, errors.New("a grid needs at least 2 levels")
+	}
+	if quantity <= 0 {
+		return nil, errors.New("grid quantity must be greater than zero")
+	}
+	if price <= lower || price >= upper {
+		return nil, errors.Errorf("price %g is outside the grid range %g - %g", price, lower, upper)
+	}
+
+	grid := &Grid{
+		Lower:    lower,
+		Upper:    upper,
+		Quantity: quantity,
+		Levels:   make([]GridLevel, levels),
+	}
+
+	step := (upper - lower) / float64(levels-1)
+	closest := 0
+	for i := range grid.Levels {
+		grid.Levels[i].Price = lower + step*float64(i)
+		if math.Abs(grid.Levels[i].Price-price) < math.Abs(grid.Levels[closest].Price-price) {
+			closest = i
+		}
+	}
+
+	for i := range grid.Levels {
+		if i < closest {
+			grid.Levels[i].Side = GridSideBuy
+		} else if i > closest {
+			grid.Levels[i].Side = GridSideSell
+		}
+	}
+
+	return grid, nil
+}
+
+// Level returns the index of the level the order rests at, -1 when it is not a grid order
+func (g *Grid) Level(orderID uint64) int {
+	for i, level := range g.Levels {
+		if level.Side != "" && level.OrderID == orderID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Fill records the fill of the order at the level and returns the index of the level to place the
+// opposite order at, -1 when the fill was at the edge of the grid
+func (g *Grid) Fill(index int) int {
+	filled := g.Levels[index]
+	g.Levels[index].Side = ""
+	g.Levels[index].OrderID = 0
+	g.Fills++
+
+	next := index + 1
+	side := GridSideSell
+	if filled.Side == GridSideSell {
+		next = index - 1
+		side = GridSideBuy
+
+		// A sell one level above a buy completes a round trip
+		if next >= 0 {
+			g.Profit += (filled.Price - g.Levels[next].Price) * g.Quantity
+		}
+	}
+
+	if next < 0 || next >= len(g.Levels) {
+		return -1
+	}
+
+	g.Levels[next].Side = side
+	g.Levels[next].OrderID = 0
+	return next
+}
+
+// Breached returns whether the price left the range by more than breakPercent
+func (g *Grid) Breached(price float64, breakPercent float64) bool {
+	return price < g.Lower*(1-breakPercent/100) || price > g.Upper*(1+breakPercent/100)
+}
+
+// Notional returns the quote value of the orders the grid places
+func (g *Grid) Notional() float64 {
+	notional := 0.0
+	for _, level := range g.Levels {
+		if level.Side != "" {
+			notional += level.Price * g.Quantity
+		}
+	}
+
+	return notional
+}
+
+func (g *Grid) String() string {
+	buys, sells := 0, 0
+	for _, level := range g.Levels {
+		switch level.Side {
+		case GridSideBuy:
+			buys++
+		case GridSideSell:
+			sells++
+		}
+	}
+
+	parts := []string{
+		fmt.Sprintf("range %g - %g", g.Lower, g.Upper),
+		fmt.Sprintf("%d levels of %g", len(g.Levels), g.Quantity),
+		fmt.Sprintf("%d buys and %d sells resting", buys, sells),
+		fmt.Sprintf("%d fills", g.Fills),
+		fmt.Sprintf("round-trip profit %.4f", g.Profit),
+	}
+
+	return strings.Join(parts, ", ")
+}
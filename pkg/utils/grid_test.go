@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGrid(t *testing.T) {
+	grid, err := NewGrid(90, 110, 5, 1, 101)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{90, 95, 100, 105, 110}, []float64{grid.Levels[0].Price, grid.Levels[1].Price, grid.Levels[2].Price, grid.Levels[3].Price, grid.Levels[4].Price})
+	assert.Equal(t, GridSideBuy, grid.Levels[1].Side)
+	assert.Equal(t, "", grid.Levels[2].Side)
+	assert.Equal(t, GridSideSell, grid.Levels[3].Side)
+	assert.Equal(t, 90.0+95+105+110, grid.Notional())
+
+	_, err = NewGrid(90, 110, 5, 1, 120)
+	assert.Error(t, err)
+
+	_, err = NewGrid(110, 90, 5, 1, 100)
+	assert.Error(t, err)
+}
+
+func TestGrid_Fill(t *testing.T) {
+	grid, _ := NewGrid(90, 110, 5, 2, 101)
+	grid.Levels[1].OrderID = 7
+	assert.Equal(t, 1, grid.Level(7))
+	assert.Equal(t, -1, grid.Level(8))
+
+	// the buy at 95 is replaced by a sell at 100
+	next := grid.Fill(1)
+	assert.Equal(t, 2, next)
+	assert.Equal(t, GridSideSell, grid.Levels[2].Side)
+	assert.Equal(t, "", grid.Levels[1].Side)
+
+	// the sell at 100 completes a round trip and is replaced by a buy at 95
+	next = grid.Fill(2)
+	assert.Equal(t, 1, next)
+	assert.Equal(t, GridSideBuy, grid.Levels[1].Side)
+	assert.Equal(t, 10.0, grid.Profit)
+	assert.Equal(t, 2, grid.Fills)
+
+	// the sell at the top is replaced by a buy one level below
+	assert.Equal(t, 3, grid.Fill(4))
+
+	// a buy at the top has no level above to sell at
+	grid.Levels[4].Side = GridSideBuy
+	assert.Equal(t, -1, grid.Fill(4))
+}
+
+func TestGrid_Breached(t *testing.T) {
+	grid, _ := NewGrid(90, 110, 5, 1, 100)
+	assert.False(t, grid.Breached(89.5, 1))
+	assert.True(t, grid.Breached(89, 1))
+	assert.True(t, grid.Breached(111.5, 1))
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Conditions of the intents scheduled by the agent
+const (
+	IntentCloseAbove = "close_above"
+	IntentCloseBelow = "close_below"
+)
+
+// Intent is a plan the agent deferred until a kline closes beyond a price within a number of klines
+type Intent struct {
+	ID        int
+	Condition string  // close_above or close_below
+	Price     float64 // Level the close has to reach
+	Bars      int     // Klines the condition is given to be met
+	Elapsed   int     // Klines closed since the intent was scheduled
+	Plan      string  // What the agent intends to do once the condition is met
+	CreatedAt time.Time
+}
+
+// String describes the intent, e.g. "#1 if a kline closes above 52000 within 4 klines: consider a long"
+func (i Intent) String() string {
+	direction := "below"
+	if i.Condition == IntentCloseAbove {
+		direction = "above"
+	}
+
+	return fmt.Sprintf("#%d if a kline closes %s %g within %d klines: %s", i.ID, direction, i.Price, i.Bars, i.Plan)
+}
+
+// Intents holds the intents scheduled by the agent until they are met or expire
+type Intents struct {
+	mu      sync.Mutex
+	max     int
+	seq     int
+	intents []Intent
+}
+
+// NewIntents creates the intents holding at most max pending intents
+func NewIntents(max int) *Intents {
+	return &Intents{max: max}
+}
+
+// Add schedules an intent
+func (s *Intents) Add(condition string, price float64, bars int, plan string) (Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if condition != IntentCloseAbove && condition != IntentCloseBelow {
+		return Intent{}, errors.Errorf("invalid intent condition %q, use %s or %s", condition, IntentCloseAbove, IntentCloseBelow)
+	}
+
+	if price <= 0 {
+		return Intent{}, errors.Errorf("invalid intent price %g", price)
+	}
+
+	if bars < 1 {
+		return Intent{}, errors.Errorf("invalid intent bars %d, at least 1", bars)
+	}
+
+	if len(s.intents) >= s.max {
+		return Intent{}, errors.Errorf("at most %d intents can be scheduled", s.max)
+	}
+
+	s.seq++
+	intent := Intent{
+		ID:        s.seq,
+		Condition: condition,
+		Price:     price,
+		Bars:      bars,
+		Plan:      plan,
+		CreatedAt: time.Now(),
+	}
+	s.intents = append(s.intents, intent)
+
+	return intent, nil
+}
+
+// OnClose counts a closed kline and removes the intents its close met, and those that ran out of klines
+func (s *Intents) OnClose(close float64) (met []Intent, expired []Intent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.intents[:0]
+	for _, intent := range s.intents {
+		intent.Elapsed++
+
+		switch {
+		case (intent.Condition == IntentCloseAbove && close > intent.Price) || (intent.Condition == IntentCloseBelow && close < intent.Price):
+			met = append(met, intent)
+		case intent.Elapsed >= intent.Bars:
+			expired = append(expired, intent)
+		default:
+			pending = append(pending, intent)
+		}
+	}
+	s.intents = pending
+
+	return met, expired
+}
+
+// List returns the pending intents in the order they were scheduled
+func (s *Intents) List() []Intent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Intent{}, s.intents...)
+}
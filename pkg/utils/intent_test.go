@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntents(t *testing.T) {
+	intents := NewIntents(2)
+
+	long, err := intents.Add(IntentCloseAbove, 52000, 4, "consider a long")
+	assert.NoError(t, err)
+	assert.Equal(t, "#1 if a kline closes above 52000 within 4 klines: consider a long", long.String())
+
+	_, err = intents.Add(IntentCloseBelow, 50000, 2, "close the long")
+	assert.NoError(t, err)
+
+	_, err = intents.Add(IntentCloseBelow, 49000, 2, "")
+	assert.Error(t, err)
+
+	met, expired := intents.OnClose(51000)
+	assert.Empty(t, met)
+	assert.Empty(t, expired)
+
+	// The second intent runs out of klines
+	met, expired = intents.OnClose(51500)
+	assert.Empty(t, met)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, 2, expired[0].ID)
+	assert.Equal(t, 2, expired[0].Elapsed)
+
+	met, expired = intents.OnClose(52100)
+	assert.Len(t, met, 1)
+	assert.Equal(t, 1, met[0].ID)
+	assert.Equal(t, 3, met[0].Elapsed)
+	assert.Empty(t, expired)
+	assert.Empty(t, intents.List())
+}
+
+func TestIntentsInvalid(t *testing.T) {
+	intents := NewIntents(5)
+
+	_, err := intents.Add("touch", 52000, 4, "")
+	assert.Error(t, err)
+
+	_, err = intents.Add(IntentCloseAbove, 0, 4, "")
+	assert.Error(t, err)
+
+	_, err = intents.Add(IntentCloseAbove, 52000, 0, "")
+	assert.Error(t, err)
+}
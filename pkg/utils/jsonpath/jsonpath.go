@@ -0,0 +1,138 @@
+// Package jsonpath evaluates a subset of JSONPath against decoded JSON documents.
+// Supported: the root $, child keys (.key and ['key']), array indexes ([0], [-1]) and wildcards (.* and [*]).
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Get returns the value at the path, or a slice of values when the path contains a wildcard
+func Get(doc interface{}, path string) (interface{}, error) {
+	segments, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{doc}
+	multi := false
+
+	for _, seg := range segments {
+		next := make([]interface{}, 0, len(values))
+
+		for _, val := range values {
+			switch {
+			case seg.wildcard:
+				multi = true
+				switch v := val.(type) {
+				case []interface{}:
+					next = append(next, v...)
+				case map[string]interface{}:
+					for _, item := range v {
+						next = append(next, item)
+					}
+				}
+			case seg.isIndex:
+				arr, ok := val.([]interface{})
+				if !ok {
+					continue
+				}
+
+				idx := seg.index
+				if idx < 0 {
+					idx += len(arr)
+				}
+				if idx >= 0 && idx < len(arr) {
+					next = append(next, arr[idx])
+				}
+			default:
+				obj, ok := val.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				if item, ok := obj[seg.key]; ok {
+					next = append(next, item)
+				}
+			}
+		}
+
+		values = next
+	}
+
+	if multi {
+		return values, nil
+	}
+
+	if len(values) == 0 {
+		return nil, fmt.Errorf("path not found: %s", path)
+	}
+
+	return values[0], nil
+}
+
+func parse(path string) ([]segment, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path must start with $: %s", path)
+	}
+
+	segments := make([]segment, 0)
+	rest := path[1:]
+
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path: %s", path)
+			}
+
+			if key == "*" {
+				segments = append(segments, segment{wildcard: true})
+			} else {
+				segments = append(segments, segment{key: key})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in path: %s", path)
+			}
+
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				segments = append(segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, segment{key: inner[1 : len(inner)-1]})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q in path: %s", inner, path)
+				}
+				segments = append(segments, segment{index: idx, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("unexpected %q in path: %s", rest[0], path)
+		}
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,35 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	var doc interface{}
+	err := json.Unmarshal([]byte(`{"data":{"rates":[{"symbol":"BTC","funding rate":0.01},{"symbol":"ETH","funding rate":-0.02}]}}`), &doc)
+	assert.NoError(t, err)
+
+	val, err := Get(doc, "$.data.rates[0].symbol")
+	assert.NoError(t, err)
+	assert.Equal(t, "BTC", val)
+
+	val, err = Get(doc, "$.data.rates[-1]['funding rate']")
+	assert.NoError(t, err)
+	assert.Equal(t, -0.02, val)
+
+	val, err = Get(doc, "$.data.rates[*].symbol")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"BTC", "ETH"}, val)
+
+	_, err = Get(doc, "$.data.missing")
+	assert.Error(t, err)
+
+	_, err = Get(doc, "data.rates")
+	assert.Error(t, err)
+
+	_, err = Get(doc, "$.data.rates[x]")
+	assert.Error(t, err)
+}
@@ -6,9 +6,16 @@ import (
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
 )
 
 func FormatKLineWindow(window types.KLineWindow, maxNum int) string {
+	return FormatKLineWindowWithPrecision(window, maxNum, config.PrecisionConfig{})
+}
+
+// FormatKLineWindowWithPrecision formats the kline window with the configured price, volume and percent precision
+func FormatKLineWindowWithPrecision(window types.KLineWindow, maxNum int, precision config.PrecisionConfig) string {
 	var sb strings.Builder
 
 	// Add descriptive information
@@ -29,11 +36,94 @@ func FormatKLineWindow(window types.KLineWindow, maxNum int) string {
 		change := kline.GetChange()
 		changePercent := change.Div(kline.GetOpen()).Mul(fixedpoint.NewFromFloat(100))
 		amplitude := kline.GetMaxChange().Div(kline.GetLow()).Mul(fixedpoint.NewFromFloat(100))
-		sb.WriteString(fmt.Sprintf("%d      %.3f  %.3f    %.3f   %.3f   %.3f    %.3f    %.3f%%    %.3f%%\n", i, kline.Open.Float64(), kline.Close.Float64(), kline.High.Float64(), kline.Low.Float64(), kline.Volume.Float64(), change.Float64(), changePercent.Float64(), amplitude.Float64()))
+		sb.WriteString(fmt.Sprintf("%d      %s  %s    %s   %s   %s    %s    %s    %s\n", i,
+			FormatNumber(kline.Open.Float64(), precision.Price),
+			FormatNumber(kline.Close.Float64(), precision.Price),
+			FormatNumber(kline.High.Float64(), precision.Price),
+			FormatNumber(kline.Low.Float64(), precision.Price),
+			FormatNumber(kline.Volume.Float64(), precision.Volume),
+			FormatNumber(change.Float64(), precision.Price),
+			FormatPercent(changePercent.Float64(), precision.Percent),
+			FormatPercent(amplitude.Float64(), precision.Percent)))
 	}
 
 	// Add latest closing price
-	sb.WriteString(fmt.Sprintf("\nCurrent close price: %.3f", window.Close().Last(0)))
+	sb.WriteString(fmt.Sprintf("\nCurrent close price: %s", FormatNumber(window.Close().Last(0), precision.Price)))
 
 	return sb.String()
 }
+
+// KLineTemplateData returns the kline values exposed to user-defined prompt formatter templates
+func KLineTemplateData(window types.KLineWindow, maxNum int) map[string]interface{} {
+	klines := make([]map[string]interface{}, 0)
+	for _, kline := range window.Tail(maxNum) {
+		klines = append(klines, map[string]interface{}{
+			"Open":      kline.Open.Float64(),
+			"Close":     kline.Close.Float64(),
+			"High":      kline.High.Float64(),
+			"Low":       kline.Low.Float64(),
+			"Volume":    kline.Volume.Float64(),
+			"StartTime": kline.StartTime.Time(),
+		})
+	}
+
+	data := map[string]interface{}{
+		"Interval": string(window.GetInterval()),
+		"KLines":   klines,
+	}
+
+	if len(window) > 0 {
+		data["Close"] = window.Close().Last(0)
+	}
+
+	return data
+}
+
+// MergeKLines merges consecutive klines into one spanning them all
+func MergeKLines(klines []types.KLine) types.KLine {
+	merged := klines[0]
+
+	for _, kline := range klines[1:] {
+		merged.High = fixedpoint.Max(merged.High, kline.High)
+		merged.Low = fixedpoint.Min(merged.Low, kline.Low)
+		merged.Volume = merged.Volume.Add(kline.Volume)
+		merged.QuoteVolume = merged.QuoteVolume.Add(kline.QuoteVolume)
+		merged.Close = kline.Close
+		merged.EndTime = kline.EndTime
+		merged.Closed = kline.Closed
+	}
+
+	return merged
+}
+
+// DownsampleKLineWindow shrinks the window to at most maxNum klines, keeping the latest recent klines
+// at full resolution and merging the older ones into equally sized buckets
+func DownsampleKLineWindow(window types.KLineWindow, maxNum int, recent int) types.KLineWindow {
+	if maxNum <= 0 || len(window) <= maxNum {
+		return window
+	}
+
+	if recent >= maxNum {
+		return window.Tail(maxNum)
+	}
+
+	if recent < 0 {
+		recent = 0
+	}
+
+	older := window[:len(window)-recent]
+	buckets := maxNum - recent
+	bucketSize := (len(older) + buckets - 1) / buckets
+
+	// Align the buckets to the latest klines, so only the oldest bucket may be partial
+	result := make(types.KLineWindow, 0, maxNum)
+	start := len(older) % bucketSize
+	if start > 0 {
+		result = append(result, MergeKLines(older[:start]))
+	}
+	for i := start; i < len(older); i += bucketSize {
+		result = append(result, MergeKLines(older[i:i+bucketSize]))
+	}
+
+	return append(result, window[len(window)-recent:]...)
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testKLineWindow(num int) types.KLineWindow {
+	window := make(types.KLineWindow, 0, num)
+	for i := 0; i < num; i++ {
+		price := float64(100 + i)
+		window = append(window, types.KLine{
+			Open:   fixedpoint.NewFromFloat(price),
+			Close:  fixedpoint.NewFromFloat(price + 0.5),
+			High:   fixedpoint.NewFromFloat(price + 1),
+			Low:    fixedpoint.NewFromFloat(price - 1),
+			Volume: fixedpoint.NewFromFloat(10),
+		})
+	}
+	return window
+}
+
+func TestMergeKLines(t *testing.T) {
+	merged := MergeKLines(testKLineWindow(3))
+	assert.Equal(t, 100.0, merged.Open.Float64())
+	assert.Equal(t, 102.5, merged.Close.Float64())
+	assert.Equal(t, 103.0, merged.High.Float64())
+	assert.Equal(t, 99.0, merged.Low.Float64())
+	assert.Equal(t, 30.0, merged.Volume.Float64())
+}
+
+func TestDownsampleKLineWindow(t *testing.T) {
+	window := testKLineWindow(200)
+
+	downsampled := DownsampleKLineWindow(window, 30, 20)
+	assert.Len(t, downsampled, 30)
+	// The latest klines are kept at full resolution
+	assert.Equal(t, window[180:], downsampled[10:])
+	// The older 180 klines are merged into 10 buckets of 18
+	assert.Equal(t, 100.0, downsampled[0].Open.Float64())
+	assert.Equal(t, 180.0, downsampled[9].Volume.Float64())
+
+	// Only the oldest bucket is partial
+	downsampled = DownsampleKLineWindow(testKLineWindow(47), 10, 5)
+	assert.Len(t, downsampled, 10)
+	assert.Equal(t, 60.0, downsampled[0].Volume.Float64())
+	assert.Equal(t, 90.0, downsampled[1].Volume.Float64())
+
+	assert.Len(t, DownsampleKLineWindow(testKLineWindow(20), 30, 10), 20)
+	assert.Len(t, DownsampleKLineWindow(testKLineWindow(50), 30, 40), 30)
+}
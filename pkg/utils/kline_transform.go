@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// HeikinAshiKLine transforms the kline into a Heikin-Ashi candlestick given the previous Heikin-Ashi candlestick,
+// nil for the first kline. The other fields of the kline such as the volume and times are kept.
+func HeikinAshiKLine(prev *types.KLine, kline types.KLine) types.KLine {
+	four := fixedpoint.NewFromInt(4)
+	two := fixedpoint.NewFromInt(2)
+
+	ha := kline
+	ha.Close = kline.Open.Add(kline.High).Add(kline.Low).Add(kline.Close).Div(four)
+	if prev == nil {
+		ha.Open = kline.Open.Add(kline.Close).Div(two)
+	} else {
+		ha.Open = prev.Open.Add(prev.Close).Div(two)
+	}
+	ha.High = fixedpoint.Max(kline.High, fixedpoint.Max(ha.Open, ha.Close))
+	ha.Low = fixedpoint.Min(kline.Low, fixedpoint.Min(ha.Open, ha.Close))
+
+	return ha
+}
+
+// HeikinAshi transforms the kline window into Heikin-Ashi candlesticks, which average out the noise of the
+// raw klines so trends read as runs of same-colored candlesticks
+func HeikinAshi(window types.KLineWindow) types.KLineWindow {
+	result := make(types.KLineWindow, 0, len(window))
+
+	var prev *types.KLine
+	for _, kline := range window {
+		ha := HeikinAshiKLine(prev, kline)
+		result = append(result, ha)
+		prev = &result[len(result)-1]
+	}
+
+	return result
+}
+
+// RenkoBrick is a brick of a renko chart, drawn whenever the close moved a brick size from the last brick
+type RenkoBrick struct {
+	Open  float64
+	Close float64
+	Up    bool
+}
+
+// RenkoBricks builds renko bricks of brickSize from the closes of the window. A new brick in the direction of
+// the last one needs a move of one brick size, a reversal brick a move of two.
+func RenkoBricks(window types.KLineWindow, brickSize float64) []RenkoBrick {
+	bricks := make([]RenkoBrick, 0)
+	if len(window) == 0 || brickSize <= 0 {
+		return bricks
+	}
+
+	// The top and bottom of the last brick, starting at the first close
+	top := window[0].Close.Float64()
+	bottom := top
+
+	for _, kline := range window[1:] {
+		price := kline.Close.Float64()
+
+		// Reversals start from the far side of the last brick, so they take a move of two bricks
+		for price >= top+brickSize {
+			bricks = append(bricks, RenkoBrick{Open: top, Close: top + brickSize, Up: true})
+			bottom, top = top, top+brickSize
+		}
+
+		for price <= bottom-brickSize {
+			bricks = append(bricks, RenkoBrick{Open: bottom, Close: bottom - brickSize, Up: false})
+			top, bottom = bottom, bottom-brickSize
+		}
+	}
+
+	return bricks
+}
+
+// AverageTrueRange returns the mean true range of the last period klines of the window, 0 when it is too short
+func AverageTrueRange(window types.KLineWindow, period int) float64 {
+	if period < 1 || len(window) < period+1 {
+		return 0
+	}
+
+	sum := 0.0
+	for i := len(window) - period; i < len(window); i++ {
+		k := window[i]
+		prevClose := window[i-1].Close.Float64()
+		sum += math.Max(k.High.Float64()-k.Low.Float64(), math.Max(math.Abs(k.High.Float64()-prevClose), math.Abs(k.Low.Float64()-prevClose)))
+	}
+
+	return sum / float64(period)
+}
+
+// FormatRenkoBricks formats the latest maxNum renko bricks oldest first, with the run of the last direction
+func FormatRenkoBricks(bricks []RenkoBrick, brickSize float64, maxNum int, format config.NumberFormat) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Renko bricks of %s built from the closes, a reversal needs a move of two bricks\n", FormatNumber(brickSize, format)))
+	sb.WriteString("# Brick: Brick Number, Starting from 0\n")
+	sb.WriteString("\n")
+
+	if len(bricks) == 0 {
+		sb.WriteString("No brick yet, the close has not moved a brick size.")
+		return sb.String()
+	}
+
+	sb.WriteString("Brick   Direction   Open   Close\n")
+
+	tail := bricks
+	if maxNum > 0 && len(tail) > maxNum {
+		tail = tail[len(tail)-maxNum:]
+	}
+
+	for i, brick := range tail {
+		direction := "down"
+		if brick.Up {
+			direction = "up"
+		}
+
+		sb.WriteString(fmt.Sprintf("%d       %s        %s   %s\n", i, direction, FormatNumber(brick.Open, format), FormatNumber(brick.Close, format)))
+	}
+
+	last := bricks[len(bricks)-1]
+	run := 0
+	for i := len(bricks) - 1; i >= 0 && bricks[i].Up == last.Up; i-- {
+		run++
+	}
+
+	direction := "down"
+	if last.Up {
+		direction = "up"
+	}
+
+	sb.WriteString(fmt.Sprintf("\nLatest direction: %s, %d bricks in a row", direction, run))
+
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func newOHLC(open, high, low, close float64) types.KLine {
+	return types.KLine{
+		Open:  fixedpoint.NewFromFloat(open),
+		High:  fixedpoint.NewFromFloat(high),
+		Low:   fixedpoint.NewFromFloat(low),
+		Close: fixedpoint.NewFromFloat(close),
+	}
+}
+
+func TestHeikinAshi(t *testing.T) {
+	window := types.KLineWindow{
+		newOHLC(10, 12, 9, 11),
+		newOHLC(11, 14, 10, 13),
+	}
+
+	ha := HeikinAshi(window)
+	assert.Len(t, ha, 2)
+
+	// The first open is the midpoint of the open and close, the closes average the four prices
+	assert.InDelta(t, 10.5, ha[0].Open.Float64(), 1e-9)
+	assert.InDelta(t, 10.5, ha[0].Close.Float64(), 1e-9)
+	assert.InDelta(t, 12, ha[0].High.Float64(), 1e-9)
+	assert.InDelta(t, 9, ha[0].Low.Float64(), 1e-9)
+
+	assert.InDelta(t, 10.5, ha[1].Open.Float64(), 1e-9)
+	assert.InDelta(t, 12, ha[1].Close.Float64(), 1e-9)
+	assert.InDelta(t, 14, ha[1].High.Float64(), 1e-9)
+	assert.InDelta(t, 10, ha[1].Low.Float64(), 1e-9)
+}
+
+func TestRenkoBricks(t *testing.T) {
+	window := newCloseWindow(100, 101, 103.5, 102, 101.5, 100.5, 99)
+
+	bricks := RenkoBricks(window, 1)
+	assert.Equal(t, []RenkoBrick{
+		{Open: 100, Close: 101, Up: true},
+		{Open: 101, Close: 102, Up: true},
+		{Open: 102, Close: 103, Up: true},
+		// 102 and 101.5 are within two bricks of the top, 100.5 reverses
+		{Open: 102, Close: 101, Up: false},
+		{Open: 101, Close: 100, Up: false},
+		{Open: 100, Close: 99, Up: false},
+	}, bricks)
+
+	assert.Empty(t, RenkoBricks(window, 0))
+	assert.Empty(t, RenkoBricks(newCloseWindow(100, 100.5), 1))
+}
+
+func TestFormatRenkoBricks(t *testing.T) {
+	bricks := RenkoBricks(newCloseWindow(100, 101, 102, 100), 1)
+
+	text := FormatRenkoBricks(bricks, 1, 2, config.NumberFormat{})
+	assert.Contains(t, text, "# Renko bricks of 1.000")
+	assert.Contains(t, text, "0       up        101.000   102.000\n")
+	assert.Contains(t, text, "1       down        101.000   100.000\n")
+	assert.Contains(t, text, "Latest direction: down, 1 bricks in a row")
+
+	assert.Contains(t, FormatRenkoBricks(nil, 1, 2, config.NumberFormat{}), "No brick yet")
+}
+
+func TestAverageTrueRange(t *testing.T) {
+	window := types.KLineWindow{
+		newOHLC(10, 11, 9, 10),
+		newOHLC(10, 12, 10, 11),
+		newOHLC(11, 11.5, 8, 9),
+	}
+
+	assert.InDelta(t, 2.75, AverageTrueRange(window, 2), 1e-9)
+	assert.Equal(t, 0.0, AverageTrueRange(window, 3))
+}
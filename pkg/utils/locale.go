@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yubing744/trading-gpt/pkg/types"
+)
+
+// localizedArgKeys maps arg keys LLMs answer with when prompted in Chinese to the action arg names
+var localizedArgKeys = map[string]string{
+	"止损":    "stop_loss_trigger_price",
+	"止损价":   "stop_loss_trigger_price",
+	"止损价格":  "stop_loss_trigger_price",
+	"止损触发价": "stop_loss_trigger_price",
+	"止盈":    "take_profit_trigger_price",
+	"止盈价":   "take_profit_trigger_price",
+	"止盈价格":  "take_profit_trigger_price",
+	"止盈触发价": "take_profit_trigger_price",
+	"数量":    "quantity",
+	"百分比":   "percentage",
+	"平仓比例":  "percentage",
+	"比例":    "ratio",
+	"置信度":   "confidence",
+	"信心":    "confidence",
+	"限价":    "limit_price",
+	"限价价格":  "limit_price",
+	"订单类型":  "order_type",
+	"风险比例":  "risk_percent",
+	"风险百分比": "risk_percent",
+	"仓位比例":  "position_size_percent",
+	"仓位百分比": "position_size_percent",
+	"上限价":   "upper_price",
+	"上限价格":  "upper_price",
+	"下限价":   "lower_price",
+	"下限价格":  "lower_price",
+	"网格数":   "levels",
+	"目标利润":  "profit_amount",
+	"名义价值":  "notional",
+	"只做挂单":  "post_only",
+	"有效期":   "time_in_force",
+}
+
+// localizedArgValues maps localized boolean answers to the values the actions accept
+var localizedArgValues = map[string]string{
+	"是": "true",
+	"否": "false",
+	"真": "true",
+	"假": "false",
+}
+
+var chineseDigits = map[rune]int64{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+var chineseUnits = map[rune]int64{'十': 10, '百': 100, '千': 1000}
+
+var chineseMagnitudes = map[rune]float64{'万': 1e4, '亿': 1e8}
+
+// NormalizeWidth converts full-width ASCII characters, the ideographic space and CJK quotes and punctuation
+// to their ASCII forms
+func NormalizeWidth(text string) string {
+	var builder strings.Builder
+	builder.Grow(len(text))
+
+	for _, r := range text {
+		switch {
+		case r >= '！' && r <= '～':
+			builder.WriteRune(r - 0xFEE0)
+		case r == '　':
+			builder.WriteRune(' ')
+		case r == '“' || r == '”':
+			builder.WriteRune('"')
+		case r == '‘' || r == '’':
+			builder.WriteRune('\'')
+		case r == '、':
+			builder.WriteRune(',')
+		case r == '【':
+			builder.WriteRune('[')
+		case r == '】':
+			builder.WriteRune(']')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// NormalizeArgs maps localized arg keys to the action arg names and converts full-width characters and Chinese
+// numbers in the values, e.g. {"止损价": "５万"} becomes {"stop_loss_trigger_price": "50000"}. Values that are not
+// plain numbers, such as price expressions, only get their width normalized.
+func NormalizeArgs(args map[string]string) map[string]string {
+	if len(args) == 0 {
+		return args
+	}
+
+	normalized := make(map[string]string, len(args))
+	for key, val := range args {
+		key = strings.TrimSpace(NormalizeWidth(key))
+		if name, ok := localizedArgKeys[key]; ok {
+			// Keep the arg given with the arg name when the LLM repeated it under a localized key
+			if args[name] != "" {
+				continue
+			}
+
+			key = name
+		}
+
+		normalized[key] = NormalizeArgValue(val)
+	}
+
+	return normalized
+}
+
+// NormalizeArgValue converts a localized arg value: full-width digits and punctuation, Chinese numerals with
+// 万 and 亿 magnitudes like "5.1万" or "五万一千", "百分之五" percentages and 是/否 booleans
+func NormalizeArgValue(val string) string {
+	val = strings.TrimSpace(NormalizeWidth(val))
+	if mapped, ok := localizedArgValues[val]; ok {
+		return mapped
+	}
+
+	if rest, ok := strings.CutPrefix(val, "百分之"); ok {
+		if num, ok := parseChineseNumber(rest); ok {
+			return formatLocaleNumber(num) + "%"
+		}
+		return val
+	}
+
+	if num, ok := parseChineseNumber(val); ok {
+		return formatLocaleNumber(num)
+	}
+
+	return val
+}
+
+// parseChineseNumber parses Arabic or Chinese numerals with optional 万 and 亿 magnitudes, ok is false when the
+// text contains anything else or no Chinese number part, which leaves plain Arabic numbers untouched
+func parseChineseNumber(text string) (float64, bool) {
+	if text == "" {
+		return 0, false
+	}
+
+	total := 0.0
+	section := ""
+	chinese := false
+	for _, r := range text {
+		magnitude, ok := chineseMagnitudes[r]
+		if !ok {
+			section += string(r)
+			continue
+		}
+
+		num, ok := parseChineseSection(section)
+		if !ok {
+			return 0, false
+		}
+
+		total += num * magnitude
+		section = ""
+		chinese = true
+	}
+
+	if section != "" {
+		num, ok := parseChineseSection(section)
+		if !ok {
+			return 0, false
+		}
+
+		total += num
+		chinese = chinese || !isArabicNumber(section)
+	}
+
+	return total, chinese
+}
+
+// parseChineseSection parses a number below 万 written in Arabic numerals or with 十, 百 and 千, a 点 marking
+// the decimals
+func parseChineseSection(section string) (float64, bool) {
+	if section == "" {
+		return 1, true
+	}
+
+	if num, err := strconv.ParseFloat(section, 64); err == nil {
+		return num, true
+	}
+
+	integer, decimals, hasDecimals := strings.Cut(section, "点")
+
+	total := int64(0)
+	digit := int64(-1)
+	for _, r := range integer {
+		if d, ok := chineseDigits[r]; ok {
+			digit = d
+			continue
+		}
+
+		unit, ok := chineseUnits[r]
+		if !ok {
+			return 0, false
+		}
+
+		// 十二 starts with the unit, the digit one is implied
+		if digit < 0 {
+			digit = 1
+		}
+
+		total += digit * unit
+		digit = -1
+	}
+
+	if digit > 0 {
+		total += digit
+	}
+
+	num := float64(total)
+	if hasDecimals {
+		scale := 0.1
+		for _, r := range decimals {
+			d, ok := chineseDigits[r]
+			if !ok {
+				return 0, false
+			}
+
+			num += float64(d) * scale
+			scale /= 10
+		}
+	}
+
+	return num, true
+}
+
+func isArabicNumber(text string) bool {
+	_, err := strconv.ParseFloat(text, 64)
+	return err == nil
+}
+
+func formatLocaleNumber(num float64) string {
+	return strconv.FormatFloat(num, 'f', -1, 64)
+}
+
+// normalizeResultArgs normalizes the args of the single and batch actions of the result
+func normalizeResultArgs(result *types.Result) {
+	if result.Action != nil {
+		result.Action.Name = strings.TrimSpace(NormalizeWidth(result.Action.Name))
+		result.Action.Args = NormalizeArgs(result.Action.Args)
+	}
+
+	for _, action := range result.Actions {
+		if action == nil {
+			continue
+		}
+
+		action.Name = strings.TrimSpace(NormalizeWidth(action.Name))
+		action.Args = NormalizeArgs(action.Args)
+	}
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeArgs(t *testing.T) {
+	args := NormalizeArgs(map[string]string{
+		"止损价":   "５０，０００",
+		"止盈":    "5.1万",
+		"数量":    "零点五",
+		"置信度":   "百分之七十",
+		"限价":    "last_close * 0.995",
+		"只做挂单":  "是",
+		"ratio": "1.50",
+	})
+
+	assert.Equal(t, "50,000", args["stop_loss_trigger_price"])
+	assert.Equal(t, "51000", args["take_profit_trigger_price"])
+	assert.Equal(t, "0.5", args["quantity"])
+	assert.Equal(t, "70%", args["confidence"])
+	assert.Equal(t, "last_close * 0.995", args["limit_price"])
+	assert.Equal(t, "true", args["post_only"])
+	assert.Equal(t, "1.50", args["ratio"])
+}
+
+func TestNormalizeArgsKeepsCanonicalKey(t *testing.T) {
+	args := NormalizeArgs(map[string]string{
+		"stop_loss_trigger_price": "49000",
+		"止损价":                     "48000",
+	})
+
+	assert.Len(t, args, 1)
+	assert.Equal(t, "49000", args["stop_loss_trigger_price"])
+}
+
+func TestNormalizeArgValueChineseNumbers(t *testing.T) {
+	assert.Equal(t, "51000", NormalizeArgValue("五万一千"))
+	assert.Equal(t, "150000000", NormalizeArgValue("一亿五千万"))
+	assert.Equal(t, "105", NormalizeArgValue("一百零五"))
+	assert.Equal(t, "12", NormalizeArgValue("十二"))
+	assert.Equal(t, "2.5%", NormalizeArgValue("２.５％"))
+	assert.Equal(t, "market", NormalizeArgValue("market"))
+}
+
+func TestParseResultFullWidthPunctuation(t *testing.T) {
+	ret, err := ParseResult(`｛“thoughts”：｛“text”：“价格突破阻力位”｝，“action”：｛“name”：“exchange.open_long_position”，“args”：｛“止损价”：“４.９万”｝｝｝`)
+	assert.NoError(t, err)
+	assert.Equal(t, "exchange.open_long_position", ret.Action.Name)
+	assert.Equal(t, "49000", ret.Action.Args["stop_loss_trigger_price"])
+}
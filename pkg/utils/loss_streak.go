@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LossStreak counts consecutive losing trades and throttles once they reach a threshold, until a winning trade
+// or a reset
+type LossStreak struct {
+	threshold int
+	losses    int
+	throttled bool
+	mu        sync.Mutex
+}
+
+func NewLossStreak(threshold int) *LossStreak {
+	return &LossStreak{threshold: threshold}
+}
+
+// Record counts a closed trade, returning whether it engaged or lifted the throttle
+func (l *LossStreak) Record(pnl float64) (engaged bool, lifted bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if pnl > 0 {
+		lifted = l.throttled
+		l.losses = 0
+		l.throttled = false
+		return false, lifted
+	}
+
+	// Break-even trades neither extend nor break the streak
+	if pnl == 0 {
+		return false, false
+	}
+
+	l.losses++
+	if !l.throttled && l.threshold > 0 && l.losses >= l.threshold {
+		l.throttled = true
+		return true, false
+	}
+
+	return false, false
+}
+
+// Reset clears the streak, returning whether the throttle was engaged
+func (l *LossStreak) Reset() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	throttled := l.throttled
+	l.losses = 0
+	l.throttled = false
+
+	return throttled
+}
+
+// Throttled returns whether the throttle is engaged
+func (l *LossStreak) Throttled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.throttled
+}
+
+// Losses returns the consecutive losing trades so far
+func (l *LossStreak) Losses() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.losses
+}
+
+// String describes the streak, empty while there is none
+func (l *LossStreak) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.losses == 0 {
+		return ""
+	}
+
+	if l.throttled {
+		return fmt.Sprintf("%d consecutive losing trades, throttle engaged", l.losses)
+	}
+
+	return fmt.Sprintf("%d consecutive losing trades, throttle engages at %d", l.losses, l.threshold)
+}
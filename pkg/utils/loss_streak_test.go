@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLossStreakEngagesAfterConsecutiveLosses(t *testing.T) {
+	streak := NewLossStreak(3)
+
+	engaged, _ := streak.Record(-10)
+	assert.False(t, engaged)
+	engaged, _ = streak.Record(0)
+	assert.False(t, engaged)
+	engaged, _ = streak.Record(-5)
+	assert.False(t, engaged)
+	assert.Equal(t, "2 consecutive losing trades, throttle engages at 3", streak.String())
+
+	engaged, _ = streak.Record(-1)
+	assert.True(t, engaged)
+	assert.True(t, streak.Throttled())
+
+	engaged, _ = streak.Record(-1)
+	assert.False(t, engaged)
+	assert.Equal(t, 4, streak.Losses())
+
+	_, lifted := streak.Record(20)
+	assert.True(t, lifted)
+	assert.False(t, streak.Throttled())
+	assert.Equal(t, "", streak.String())
+}
+
+func TestLossStreakReset(t *testing.T) {
+	streak := NewLossStreak(1)
+	streak.Record(-1)
+	assert.True(t, streak.Throttled())
+
+	assert.True(t, streak.Reset())
+	assert.False(t, streak.Throttled())
+	assert.False(t, streak.Reset())
+
+	_, lifted := streak.Record(1)
+	assert.False(t, lifted)
+}
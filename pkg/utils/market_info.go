@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// MarketInfo is the trading metadata of a symbol, loaded once at startup and cached on disk
+type MarketInfo struct {
+	Symbol             string  `json:"symbol"`
+	PricePrecision     int     `json:"price_precision"`
+	QuantityPrecision  int     `json:"quantity_precision"`
+	TickSize           float64 `json:"tick_size"`
+	StepSize           float64 `json:"step_size"`
+	MinQuantity        float64 `json:"min_quantity"`
+	MinNotional        float64 `json:"min_notional"`
+	ContractMultiplier float64 `json:"contract_multiplier"` // Base currency per unit of order quantity, 1 for spot
+}
+
+// NewMarketInfo reads the metadata of the market, deriving missing precisions from the tick and step sizes
+func NewMarketInfo(market types.Market) *MarketInfo {
+	info := &MarketInfo{
+		Symbol:             market.Symbol,
+		PricePrecision:     market.PricePrecision,
+		QuantityPrecision:  market.VolumePrecision,
+		TickSize:           market.TickSize.Float64(),
+		StepSize:           market.StepSize.Float64(),
+		MinQuantity:        market.MinQuantity.Float64(),
+		MinNotional:        market.MinNotional.Float64(),
+		ContractMultiplier: 1,
+	}
+	info.derivePrecisions()
+
+	return info
+}
+
+func (m *MarketInfo) derivePrecisions() {
+	if m.PricePrecision <= 0 && m.TickSize > 0 {
+		m.PricePrecision = StepDecimals(m.TickSize)
+	}
+	if m.QuantityPrecision <= 0 && m.StepSize > 0 {
+		m.QuantityPrecision = StepDecimals(m.StepSize)
+	}
+}
+
+// SetInstrument fills the sizes the market lacks from the exchange instrument and takes its contract multiplier
+func (m *MarketInfo) SetInstrument(tickSize, stepSize, minQuantity, contractMultiplier float64) {
+	if m.TickSize <= 0 {
+		m.TickSize = tickSize
+	}
+	if m.StepSize <= 0 {
+		m.StepSize = stepSize
+	}
+	if m.MinQuantity <= 0 {
+		m.MinQuantity = minQuantity
+	}
+	if contractMultiplier > 0 {
+		m.ContractMultiplier = contractMultiplier
+	}
+
+	m.derivePrecisions()
+}
+
+// Apply fills the fields the market is missing, so order construction doesn't fall back to zero values
+func (m *MarketInfo) Apply(market *types.Market) {
+	if market.PricePrecision <= 0 {
+		market.PricePrecision = m.PricePrecision
+	}
+	if market.VolumePrecision <= 0 {
+		market.VolumePrecision = m.QuantityPrecision
+	}
+	if market.TickSize.IsZero() {
+		market.TickSize = fixedpoint.NewFromFloat(m.TickSize)
+	}
+	if market.StepSize.IsZero() {
+		market.StepSize = fixedpoint.NewFromFloat(m.StepSize)
+	}
+	if market.MinQuantity.IsZero() {
+		market.MinQuantity = fixedpoint.NewFromFloat(m.MinQuantity)
+	}
+	if market.MinNotional.IsZero() {
+		market.MinNotional = fixedpoint.NewFromFloat(m.MinNotional)
+	}
+}
+
+// Notional returns the quote value of an order quantity at the price
+func (m *MarketInfo) Notional(quantity float64, price float64) float64 {
+	multiplier := m.ContractMultiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+
+	return quantity * multiplier * price
+}
+
+func (m *MarketInfo) String() string {
+	return fmt.Sprintf("%s: price precision %d (tick %g), quantity precision %d (step %g), min quantity %g, min notional %g, contract multiplier %g",
+		m.Symbol, m.PricePrecision, m.TickSize, m.QuantityPrecision, m.StepSize, m.MinQuantity, m.MinNotional, m.ContractMultiplier)
+}
+
+// StepDecimals returns the decimal places of a tick or step size, e.g. 2 for 0.01 and 0 for 5
+func StepDecimals(step float64) int {
+	if step <= 0 || step >= 1 {
+		return 0
+	}
+
+	return int(math.Ceil(-math.Log10(step) - 1e-9))
+}
+
+// SaveMarketInfo caches the market info to the file
+func SaveMarketInfo(path string, info *MarketInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encode market info error")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "create market info dir error")
+	}
+
+	return errors.Wrap(os.WriteFile(path, data, 0644), "write market info error")
+}
+
+// LoadMarketInfo reads the market info cached in the file
+func LoadMarketInfo(path string) (*MarketInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read market info error")
+	}
+
+	info := &MarketInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, errors.Wrap(err, "decode market info error")
+	}
+
+	return info, nil
+}
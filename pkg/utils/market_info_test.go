@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepDecimals(t *testing.T) {
+	assert.Equal(t, 2, StepDecimals(0.01))
+	assert.Equal(t, 4, StepDecimals(0.0005))
+	assert.Equal(t, 8, StepDecimals(0.00000001))
+	assert.Equal(t, 0, StepDecimals(1))
+	assert.Equal(t, 0, StepDecimals(5))
+}
+
+func TestMarketInfo(t *testing.T) {
+	info := NewMarketInfo(types.Market{
+		Symbol:      "BTCUSDT",
+		TickSize:    fixedpoint.NewFromFloat(0.1),
+		MinNotional: fixedpoint.NewFromFloat(5),
+	})
+	assert.Equal(t, 1, info.PricePrecision)
+	assert.Equal(t, 0, info.QuantityPrecision)
+
+	info.SetInstrument(0.5, 0.01, 0.01, 0.01)
+	assert.Equal(t, 0.1, info.TickSize, "the market's own tick size wins")
+	assert.Equal(t, 2, info.QuantityPrecision)
+	assert.InDelta(t, 300, info.Notional(1, 30000), 0.0001)
+
+	market := types.Market{Symbol: "BTCUSDT"}
+	info.Apply(&market)
+	assert.Equal(t, 1, market.PricePrecision)
+	assert.Equal(t, 0.01, market.MinQuantity.Float64())
+
+	path := filepath.Join(t.TempDir(), "market.json")
+	assert.NoError(t, SaveMarketInfo(path, info))
+	loaded, err := LoadMarketInfo(path)
+	assert.NoError(t, err)
+	assert.Equal(t, info, loaded)
+}
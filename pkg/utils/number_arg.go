@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/pkg/errors"
+)
+
+// Digit groups of three separated by commas, e.g. 1,234,567.89
+var thousandsPattern = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d+)?$`)
+
+// numberSuffixes scale numbers written with a magnitude suffix like 51.25k
+var numberSuffixes = map[string]float64{
+	"k": 1e3,
+	"m": 1e6,
+	"b": 1e9,
+}
+
+// NumberArg is a numeric action arg with its unit
+type NumberArg struct {
+	Value   fixedpoint.Value // The number as written, 2.5 for "2.5%"
+	Percent bool             // The number was written with a % sign
+}
+
+// Fraction returns the value as a fraction, a percent divided by 100
+func (n NumberArg) Fraction() fixedpoint.Value {
+	if n.Percent {
+		return n.Value.Div(fixedpoint.NewFromInt(100))
+	}
+
+	return n.Value
+}
+
+// Float64 returns the number as written
+func (n NumberArg) Float64() float64 {
+	return n.Value.Float64()
+}
+
+// ParseNumberArg parses a numeric arg written the ways LLMs tend to: thousands separators "51,250", magnitude
+// suffixes "51.25k", "1.2M" and "3b", scientific notation "5.125e4", a leading "$" or "+", and a trailing "%"
+// which is kept as the unit instead of being applied
+func ParseNumberArg(text string) (NumberArg, error) {
+	arg := NumberArg{}
+
+	val := strings.TrimSpace(text)
+	val = strings.TrimPrefix(val, "$")
+	if trimmed, ok := strings.CutSuffix(val, "%"); ok {
+		arg.Percent = true
+		val = strings.TrimSpace(trimmed)
+	}
+
+	scale := 1.0
+	if len(val) > 1 {
+		if suffixScale, ok := numberSuffixes[strings.ToLower(val[len(val)-1:])]; ok && !arg.Percent {
+			scale = suffixScale
+			val = strings.TrimSpace(val[:len(val)-1])
+		}
+	}
+
+	if strings.Contains(val, ",") {
+		// "51,25" could be a decimal comma or a typo, refuse to guess
+		if !thousandsPattern.MatchString(val) {
+			return arg, errors.Errorf("invalid number %q, use digit groups of three like 51,250", text)
+		}
+
+		val = strings.ReplaceAll(val, ",", "")
+	}
+
+	num, err := strconv.ParseFloat(val, 64)
+	if err != nil || math.IsNaN(num) || math.IsInf(num, 0) {
+		return arg, errors.Errorf("invalid number %q", text)
+	}
+
+	arg.Value = fixedpoint.NewFromFloat(num * scale)
+	return arg, nil
+}
+
+// ParseNumberArgFloat parses a numeric arg that has no unit, rejecting percentages
+func ParseNumberArgFloat(text string) (float64, error) {
+	arg, err := ParseNumberArg(text)
+	if err != nil {
+		return 0, err
+	}
+
+	if arg.Percent {
+		return 0, errors.Errorf("invalid number %q, a percentage is not allowed", text)
+	}
+
+	return arg.Float64(), nil
+}
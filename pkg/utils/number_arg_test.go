@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNumberArg(t *testing.T) {
+	cases := []struct {
+		text    string
+		value   float64
+		percent bool
+	}{
+		{"51250", 51250, false},
+		{"51,250", 51250, false},
+		{"1,234,567.5", 1234567.5, false},
+		{"51.25k", 51250, false},
+		{"1.2M", 1200000, false},
+		{"$51,250", 51250, false},
+		{"5.125e4", 51250, false},
+		{" 2.5% ", 2.5, true},
+		{"-0.5", -0.5, false},
+	}
+
+	for _, c := range cases {
+		arg, err := ParseNumberArg(c.text)
+		assert.NoError(t, err, c.text)
+		assert.InDelta(t, c.value, arg.Float64(), 1e-8, c.text)
+		assert.Equal(t, c.percent, arg.Percent, c.text)
+	}
+}
+
+func TestParseNumberArgInvalid(t *testing.T) {
+	for _, text := range []string{"", "51,25", "1,2345", "abc", "NaN", "k", "5%k"} {
+		_, err := ParseNumberArg(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestNumberArgFraction(t *testing.T) {
+	arg, err := ParseNumberArg("2.5%")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.025, arg.Fraction().Float64(), 1e-8)
+
+	arg, err = ParseNumberArg("0.3")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.3, arg.Fraction().Float64(), 1e-8)
+
+	_, err = ParseNumberArgFloat("2.5%")
+	assert.Error(t, err)
+}
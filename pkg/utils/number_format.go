@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// FormatNumber renders a value according to the number format
+func FormatNumber(val float64, format config.NumberFormat) string {
+	suffix := ""
+	if format.Percent {
+		val = val * 100
+		suffix = "%"
+	}
+
+	decimals := config.DefaultDecimals
+	if format.Decimals != nil {
+		decimals = *format.Decimals
+	}
+
+	if format.SigFigs > 0 {
+		decimals = 0
+		if val != 0 && !math.IsNaN(val) && !math.IsInf(val, 0) {
+			decimals = format.SigFigs - 1 - int(math.Floor(math.Log10(math.Abs(val))))
+		}
+		if decimals < 0 {
+			// Round the integer part to the significant figures
+			scale := math.Pow(10, float64(-decimals))
+			val = math.Round(val/scale) * scale
+			decimals = 0
+		}
+	}
+
+	return strconv.FormatFloat(val, 'f', decimals, 64) + suffix
+}
+
+// FormatPercent renders a value that is already expressed in percent, e.g. 1.23 as 1.23%
+func FormatPercent(val float64, format config.NumberFormat) string {
+	format.Percent = false
+	return FormatNumber(val, format) + "%"
+}
+
+// JoinNumbers renders each value according to the number format, separated by sep
+func JoinNumbers(vals []float64, sep string, format config.NumberFormat) string {
+	items := make([]string, 0, len(vals))
+	for _, val := range vals {
+		items = append(items, FormatNumber(val, format))
+	}
+
+	return strings.Join(items, sep)
+}
+
+// JoinPercents renders each value already expressed in percent, separated by sep
+func JoinPercents(vals []float64, sep string, format config.NumberFormat) string {
+	items := make([]string, 0, len(vals))
+	for _, val := range vals {
+		items = append(items, FormatPercent(val, format))
+	}
+
+	return strings.Join(items, sep)
+}
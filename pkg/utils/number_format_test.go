@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+func TestFormatNumber(t *testing.T) {
+	two := 2
+
+	assert.Equal(t, "43120.123", FormatNumber(43120.1234, config.NumberFormat{}))
+	assert.Equal(t, "43120.12", FormatNumber(43120.1234, config.NumberFormat{Decimals: &two}))
+	assert.Equal(t, "43100", FormatNumber(43120.1234, config.NumberFormat{SigFigs: 3}))
+	assert.Equal(t, "0.000123", FormatNumber(0.00012345, config.NumberFormat{SigFigs: 3}))
+	assert.Equal(t, "1.23%", FormatNumber(0.012345, config.NumberFormat{Decimals: &two, Percent: true}))
+	assert.Equal(t, "0", FormatNumber(0, config.NumberFormat{SigFigs: 3}))
+}
+
+func TestFormatPercent(t *testing.T) {
+	one := 1
+	assert.Equal(t, "1.235%", FormatPercent(1.2346, config.NumberFormat{}))
+	assert.Equal(t, "1.2%", FormatPercent(1.2345, config.NumberFormat{Decimals: &one, Percent: true}))
+}
+
+func TestJoinNumbers(t *testing.T) {
+	two := 2
+	assert.Equal(t, "1.00 2.50", JoinNumbers([]float64{1, 2.5}, " ", config.NumberFormat{Decimals: &two}))
+}
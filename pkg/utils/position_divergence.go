@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+)
+
+// PositionDivergence is a mismatch between the position tracked locally and the one the exchange reports,
+// e.g. after a missed fill or a manual trade on the account
+type PositionDivergence struct {
+	Local    float64 // Base quantity, negative for a short
+	Exchange float64
+}
+
+// CheckPositionDivergence returns the divergence when the local and exchange quantities differ by more than
+// the tolerance, nil otherwise
+func CheckPositionDivergence(local float64, exchange float64, tolerance float64) *PositionDivergence {
+	if math.Abs(exchange-local) <= tolerance {
+		return nil
+	}
+
+	return &PositionDivergence{Local: local, Exchange: exchange}
+}
+
+// Diff returns what the exchange holds beyond the local position
+func (d *PositionDivergence) Diff() float64 {
+	return d.Exchange - d.Local
+}
+
+func (d *PositionDivergence) String() string {
+	return fmt.Sprintf("the local position is %g but the exchange reports %g (%+g)", d.Local, d.Exchange, d.Diff())
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPositionDivergence(t *testing.T) {
+	assert.Nil(t, CheckPositionDivergence(0.5, 0.5, 0))
+	assert.Nil(t, CheckPositionDivergence(0.5, 0.5005, 0.001))
+	assert.Nil(t, CheckPositionDivergence(0, -0.0005, 0.001))
+
+	divergence := CheckPositionDivergence(0.5, 0.25, 0.001)
+	assert.NotNil(t, divergence)
+	assert.Equal(t, -0.25, divergence.Diff())
+	assert.Equal(t, "the local position is 0.5 but the exchange reports 0.25 (-0.25)", divergence.String())
+
+	divergence = CheckPositionDivergence(0, -1, 0.001)
+	assert.NotNil(t, divergence)
+	assert.Equal(t, "the local position is 0 but the exchange reports -1 (-1)", divergence.String())
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PositionSizeParams are the inputs translating a share of the balance into a position size
+type PositionSizeParams struct {
+	Percent     float64 // Percent of the leveraged balance the agent asks for
+	MaxPercent  float64 // Upper bound of the percent, 0 for no bound
+	Balance     float64 // Leveraged quote balance available for entries
+	EntryPrice  float64
+	MinQuantity float64 // Smallest base quantity of the market
+}
+
+// PositionSize is the position size computed from a share of the balance
+type PositionSize struct {
+	Percent  float64  `json:"percent"`  // Share actually used, after clamping
+	Quantity float64  `json:"quantity"` // Base quantity
+	Notional float64  `json:"notional"`
+	Clamps   []string `json:"clamps,omitempty"` // Limits the size was clamped to
+}
+
+// ComputePositionSize sizes a position with the percent of the leveraged balance, clamped to the max percent
+func ComputePositionSize(p PositionSizeParams) (*PositionSize, error) {
+	if p.Percent <= 0 || p.Percent > 100 {
+		return nil, errors.Errorf("position size percent must be greater than 0 and at most 100, got %g", p.Percent)
+	}
+
+	if p.Balance <= 0 {
+		return nil, errors.New("no balance to size the position with")
+	}
+
+	if p.EntryPrice <= 0 {
+		return nil, errors.Errorf("position sizing needs an entry price, got %g", p.EntryPrice)
+	}
+
+	size := &PositionSize{Percent: p.Percent, Clamps: make([]string, 0)}
+
+	if p.MaxPercent > 0 && size.Percent > p.MaxPercent {
+		size.Percent = p.MaxPercent
+		size.Clamps = append(size.Clamps, fmt.Sprintf("max position size %g%%", p.MaxPercent))
+	}
+
+	size.Notional = p.Balance * size.Percent / 100
+	size.Quantity = size.Notional / p.EntryPrice
+
+	if size.Quantity < p.MinQuantity {
+		return nil, errors.Errorf("position sized quantity %g is less than the minimum quantity %g", size.Quantity, p.MinQuantity)
+	}
+
+	return size, nil
+}
+
+func (size *PositionSize) String() string {
+	text := fmt.Sprintf("%.2f%% of the leveraged balance: quantity %g, notional %.2f", size.Percent, size.Quantity, size.Notional)
+
+	if len(size.Clamps) > 0 {
+		text += fmt.Sprintf(", clamped to the %s", strings.Join(size.Clamps, " and "))
+	}
+
+	return text
+}
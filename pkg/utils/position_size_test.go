@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputePositionSize(t *testing.T) {
+	size, err := ComputePositionSize(PositionSizeParams{
+		Percent:    25,
+		Balance:    8000,
+		EntryPrice: 100,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 2000, size.Notional, 1e-9)
+	assert.InDelta(t, 20, size.Quantity, 1e-9)
+	assert.Empty(t, size.Clamps)
+	assert.Equal(t, "25.00% of the leveraged balance: quantity 20, notional 2000.00", size.String())
+
+	// Clamped to the configured cap
+	size, err = ComputePositionSize(PositionSizeParams{
+		Percent:    80,
+		MaxPercent: 50,
+		Balance:    8000,
+		EntryPrice: 100,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 50, size.Percent, 1e-9)
+	assert.InDelta(t, 4000, size.Notional, 1e-9)
+	assert.Contains(t, size.String(), "clamped to the max position size 50%")
+
+	_, err = ComputePositionSize(PositionSizeParams{Percent: 0, Balance: 8000, EntryPrice: 100})
+	assert.Error(t, err)
+
+	_, err = ComputePositionSize(PositionSizeParams{Percent: 150, Balance: 8000, EntryPrice: 100})
+	assert.Error(t, err)
+
+	_, err = ComputePositionSize(PositionSizeParams{Percent: 1, Balance: 100, EntryPrice: 100, MinQuantity: 0.1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PriceAlert is a price level the agent asked to be woken at
+type PriceAlert struct {
+	ID        int
+	Price     float64
+	Above     bool   // Triggers when the price rises to the level, otherwise when it falls to it
+	Note      string // Why the agent wants to be woken, e.g. the plan at the level
+	CreatedAt time.Time
+}
+
+// String describes the alert, e.g. "#1 price rises to 52000 (breakout, consider long)"
+func (a PriceAlert) String() string {
+	direction := "falls"
+	if a.Above {
+		direction = "rises"
+	}
+
+	text := fmt.Sprintf("#%d price %s to %g", a.ID, direction, a.Price)
+	if a.Note != "" {
+		text += fmt.Sprintf(" (%s)", a.Note)
+	}
+
+	return text
+}
+
+// PriceAlerts holds the active price alerts, each one triggering once
+type PriceAlerts struct {
+	mu     sync.Mutex
+	max    int
+	seq    int
+	alerts []PriceAlert
+}
+
+// NewPriceAlerts creates the alerts holding at most max active alerts
+func NewPriceAlerts(max int) *PriceAlerts {
+	return &PriceAlerts{max: max}
+}
+
+// Add sets an alert at the price, triggering when the price rises to it when above and falls to it otherwise
+func (p *PriceAlerts) Add(price float64, above bool, note string) (PriceAlert, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if price <= 0 {
+		return PriceAlert{}, errors.Errorf("invalid alert price %g", price)
+	}
+
+	if len(p.alerts) >= p.max {
+		return PriceAlert{}, errors.Errorf("at most %d price alerts can be active", p.max)
+	}
+
+	p.seq++
+	alert := PriceAlert{
+		ID:        p.seq,
+		Price:     price,
+		Above:     above,
+		Note:      note,
+		CreatedAt: time.Now(),
+	}
+	p.alerts = append(p.alerts, alert)
+
+	return alert, nil
+}
+
+// Check removes and returns the alerts reached by the price range traded since they were set, high for the
+// rising alerts and low for the falling ones
+func (p *PriceAlerts) Check(high, low float64) []PriceAlert {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	triggered := make([]PriceAlert, 0)
+	active := p.alerts[:0]
+	for _, alert := range p.alerts {
+		if (alert.Above && high >= alert.Price) || (!alert.Above && low <= alert.Price) {
+			triggered = append(triggered, alert)
+		} else {
+			active = append(active, alert)
+		}
+	}
+	p.alerts = active
+
+	return triggered
+}
+
+// Remove cancels the alert with the id, false when there is no such active alert
+func (p *PriceAlerts) Remove(id int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, alert := range p.alerts {
+		if alert.ID == id {
+			p.alerts = append(p.alerts[:i], p.alerts[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// List returns the active alerts in the order they were set
+func (p *PriceAlerts) List() []PriceAlert {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]PriceAlert{}, p.alerts...)
+}
+
+// Len returns the number of active alerts
+func (p *PriceAlerts) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.alerts)
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceAlerts(t *testing.T) {
+	alerts := NewPriceAlerts(2)
+
+	up, err := alerts.Add(52000, true, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, up.ID)
+	assert.Equal(t, "#1 price rises to 52000", up.String())
+
+	down, err := alerts.Add(49500.5, false, "stop loss is near, reconsider the long")
+	assert.NoError(t, err)
+	assert.Equal(t, "#2 price falls to 49500.5 (stop loss is near, reconsider the long)", down.String())
+
+	_, err = alerts.Add(53000, true, "")
+	assert.Error(t, err)
+
+	_, err = alerts.Add(0, true, "")
+	assert.Error(t, err)
+
+	assert.Empty(t, alerts.Check(51900, 49600))
+	assert.Equal(t, 2, alerts.Len())
+
+	triggered := alerts.Check(52000, 51000)
+	assert.Len(t, triggered, 1)
+	assert.Equal(t, 1, triggered[0].ID)
+	assert.Equal(t, 1, alerts.Len())
+
+	// An alert triggers once
+	assert.Empty(t, alerts.Check(53000, 51000))
+
+	triggered = alerts.Check(50000, 49000)
+	assert.Len(t, triggered, 1)
+	assert.Equal(t, 2, triggered[0].ID)
+	assert.Equal(t, 0, alerts.Len())
+
+	next, err := alerts.Add(51000, true, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, next.ID)
+}
+
+func TestPriceAlertsRemove(t *testing.T) {
+	alerts := NewPriceAlerts(5)
+	alerts.Add(52000, true, "breakout")
+	alerts.Add(50000, false, "")
+	alerts.Add(53000, true, "")
+
+	assert.True(t, alerts.Remove(2))
+	assert.False(t, alerts.Remove(2))
+	assert.False(t, alerts.Remove(9))
+
+	list := alerts.List()
+	assert.Len(t, list, 2)
+	assert.Equal(t, 1, list[0].ID)
+	assert.Equal(t, "breakout", list[0].Note)
+	assert.Equal(t, 3, list[1].ID)
+
+	// The list is a copy
+	list[0].Price = 1
+	assert.Equal(t, 52000.0, alerts.List()[0].Price)
+}
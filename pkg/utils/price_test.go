@@ -131,3 +131,18 @@ func TestParsePrice_InvalidExpression(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, price)
 }
+
+func TestParsePrice_ThousandsSeparator(t *testing.T) {
+	vm := goja.New()
+	closePrice := fixedpoint.NewFromFloat(51000.0)
+
+	price, err := ParsePrice(vm, nil, closePrice, "51,250")
+	assert.NoError(t, err)
+	assert.NotNil(t, price)
+	assert.Equal(t, 51250.0, price.Float64())
+
+	price, err = ParsePrice(vm, nil, closePrice, "51.25k")
+	assert.NoError(t, err)
+	assert.NotNil(t, price)
+	assert.Equal(t, 51250.0, price.Float64())
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/yubing744/trading-gpt/pkg/config"
+)
+
+// DefaultProfitRatchetSteps lock breakeven at +1R, +1R at +2R and +2R at +3R
+var DefaultProfitRatchetSteps = []config.ProfitRatchetStep{
+	{TriggerR: 1, LockR: 0},
+	{TriggerR: 2, LockR: 1},
+	{TriggerR: 3, LockR: 2},
+}
+
+// ProfitRatchet tightens the stop of a position in steps as its unrealized profit grows, it never loosens it
+type ProfitRatchet struct {
+	Side       string  `json:"side"`
+	EntryPrice float64 `json:"entry_price"`
+	Risk       float64 `json:"risk"`     // Distance from the entry to the initial stop, 1R
+	Level      int     `json:"level"`    // Number of steps reached
+	LockedR    float64 `json:"locked_r"` // Profit locked by the stop, in R
+	Stop       float64 `json:"stop"`     // Stop price of the reached step, 0 before the first step
+	PeakR      float64 `json:"peak_r"`   // Highest unrealized profit seen, in R
+}
+
+// NewProfitRatchet creates the ratchet of a long or short position, nil when the stop does not limit the risk
+func NewProfitRatchet(side string, entryPrice float64, initialStop float64) *ProfitRatchet {
+	risk := entryPrice - initialStop
+	if side == "short" {
+		risk = initialStop - entryPrice
+	}
+
+	if entryPrice <= 0 || initialStop <= 0 || risk <= 0 {
+		return nil
+	}
+
+	return &ProfitRatchet{
+		Side:       side,
+		EntryPrice: entryPrice,
+		Risk:       risk,
+	}
+}
+
+// RMultiple returns the unrealized profit at the price, in R
+func (r *ProfitRatchet) RMultiple(price float64) float64 {
+	if r.Side == "short" {
+		return (r.EntryPrice - price) / r.Risk
+	}
+
+	return (price - r.EntryPrice) / r.Risk
+}
+
+// Advance moves the ratchet to the highest step the price reaches and returns whether it advanced
+func (r *ProfitRatchet) Advance(price float64, steps []config.ProfitRatchetStep) bool {
+	rMultiple := r.RMultiple(price)
+	r.PeakR = math.Max(r.PeakR, rMultiple)
+
+	sorted := append([]config.ProfitRatchetStep{}, steps...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].TriggerR < sorted[j].TriggerR
+	})
+
+	level := r.Level
+	for i := r.Level; i < len(sorted) && rMultiple >= sorted[i].TriggerR; i++ {
+		level = i + 1
+	}
+
+	if level == r.Level {
+		return false
+	}
+
+	r.Level = level
+	r.LockedR = sorted[level-1].LockR
+	r.Stop = r.stopAt(r.LockedR)
+	return true
+}
+
+// Tightens returns whether the ratchet stop is tighter than the current stop, 0 meaning no stop
+func (r *ProfitRatchet) Tightens(currentStop float64) bool {
+	if r.Stop <= 0 {
+		return false
+	}
+	if currentStop <= 0 {
+		return true
+	}
+
+	if r.Side == "short" {
+		return r.Stop < currentStop
+	}
+
+	return r.Stop > currentStop
+}
+
+// Prompt describes the ratchet state for the position prompt
+func (r *ProfitRatchet) Prompt(price float64) string {
+	msg := fmt.Sprintf("Profit-lock ratchet: 1R = %.4f (entry %.4f), unrealized profit %.2fR, peak %.2fR",
+		r.Risk, r.EntryPrice, r.RMultiple(price), r.PeakR)
+
+	if r.Level == 0 {
+		return msg + ", no profit locked yet."
+	}
+
+	return msg + fmt.Sprintf(", step %d reached, stop tightened to %.4f locking %+.2fR. The ratchet only tightens the stop, do not widen it.",
+		r.Level, r.Stop, r.LockedR)
+}
+
+func (r *ProfitRatchet) stopAt(lockR float64) float64 {
+	if r.Side == "short" {
+		return r.EntryPrice - lockR*r.Risk
+	}
+
+	return r.EntryPrice + lockR*r.Risk
+}
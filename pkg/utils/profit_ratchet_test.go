@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfitRatchet_Long(t *testing.T) {
+	r := NewProfitRatchet("long", 100, 98)
+	assert.Equal(t, 2.0, r.Risk)
+
+	assert.False(t, r.Advance(101, DefaultProfitRatchetSteps))
+	assert.Equal(t, 0, r.Level)
+
+	// +2.5R skips breakeven and locks +1R
+	assert.True(t, r.Advance(105, DefaultProfitRatchetSteps))
+	assert.Equal(t, 2, r.Level)
+	assert.Equal(t, 102.0, r.Stop)
+	assert.True(t, r.Tightens(98))
+	assert.False(t, r.Tightens(103))
+
+	// A pullback never loosens the ratchet
+	assert.False(t, r.Advance(100, DefaultProfitRatchetSteps))
+	assert.Equal(t, 102.0, r.Stop)
+	assert.Equal(t, 2.5, r.PeakR)
+
+	assert.True(t, r.Advance(106, DefaultProfitRatchetSteps))
+	assert.Equal(t, 104.0, r.Stop)
+	assert.Contains(t, r.Prompt(106), "step 3 reached")
+}
+
+func TestProfitRatchet_Short(t *testing.T) {
+	r := NewProfitRatchet("short", 100, 104)
+
+	assert.True(t, r.Advance(96, DefaultProfitRatchetSteps))
+	assert.Equal(t, 1, r.Level)
+	assert.Equal(t, 100.0, r.Stop)
+	assert.True(t, r.Tightens(104))
+
+	assert.Nil(t, NewProfitRatchet("short", 100, 99))
+	assert.Nil(t, NewProfitRatchet("long", 100, 0))
+}
@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var numberPattern = regexp.MustCompile(`-?\d+(?:\.\d+)?`)
+
+// PromptDeduper replaces prompt sections that are near-identical to the ones sent in the previous cycles with
+// an "unchanged since last cycle" marker. A section counts as unchanged when it is similar enough to a section
+// sent in full and none of its numbers moved by more than the numeric tolerance, since embeddings barely
+// notice changed numbers.
+type PromptDeduper struct {
+	threshold        float64
+	maxNumericChange float64
+	maxSkips         int
+
+	baselines []*promptSection
+	mu        sync.Mutex
+}
+
+// promptSection is a section last sent in full, and how many cycles it was replaced by the marker since
+type promptSection struct {
+	text    string
+	vector  []float32
+	numbers []float64
+	skips   int
+}
+
+// NewPromptDeduper creates a deduper, threshold is the similarity (0-1) of near-identical sections,
+// maxNumericChange the relative change of numbers tolerated, and maxSkips how many cycles in a row a section
+// may be replaced before it is sent in full again
+func NewPromptDeduper(threshold float64, maxNumericChange float64, maxSkips int) *PromptDeduper {
+	return &PromptDeduper{
+		threshold:        threshold,
+		maxNumericChange: maxNumericChange,
+		maxSkips:         maxSkips,
+	}
+}
+
+// Dedupe returns the sections with the unchanged ones replaced by a marker, and how many were replaced.
+// Vectors are the embeddings of the sections, the word overlap is used instead when they are nil.
+func (d *PromptDeduper) Dedupe(texts []string, vectors [][]float32) ([]string, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(vectors) != len(texts) {
+		vectors = nil
+	}
+
+	rets := make([]string, 0, len(texts))
+	baselines := make([]*promptSection, 0, len(texts))
+	used := make(map[*promptSection]bool)
+	replaced := 0
+
+	for i, text := range texts {
+		section := &promptSection{text: text, numbers: extractNumbers(text)}
+		if vectors != nil {
+			section.vector = vectors[i]
+		}
+
+		baseline := d.match(section, used)
+		if baseline != nil && baseline.skips < d.maxSkips {
+			used[baseline] = true
+			baseline.skips++
+			baselines = append(baselines, baseline)
+			rets = append(rets, unchangedMarker(text))
+			replaced++
+			continue
+		}
+
+		if baseline != nil {
+			used[baseline] = true
+		}
+		baselines = append(baselines, section)
+		rets = append(rets, text)
+	}
+
+	d.baselines = baselines
+	return rets, replaced
+}
+
+// match returns the most similar unused baseline the section is unchanged from, nil if there is none
+func (d *PromptDeduper) match(section *promptSection, used map[*promptSection]bool) *promptSection {
+	var best *promptSection
+	bestScore := 0.0
+
+	for _, baseline := range d.baselines {
+		if used[baseline] {
+			continue
+		}
+
+		score := 0.0
+		if section.vector != nil && baseline.vector != nil {
+			score = cosine(section.vector, baseline.vector)
+		} else {
+			score = wordOverlap(section.text, baseline.text)
+		}
+
+		if score > bestScore {
+			best, bestScore = baseline, score
+		}
+	}
+
+	if best == nil || bestScore < d.threshold || !numbersClose(best.numbers, section.numbers, d.maxNumericChange) {
+		return nil
+	}
+
+	return best
+}
+
+// unchangedMarker keeps the first line of the section, so the agent knows which data it refers to
+func unchangedMarker(text string) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+	if len([]rune(title)) > 80 {
+		title = string([]rune(title)[:80]) + "..."
+	}
+
+	return title + " [unchanged since last cycle]"
+}
+
+func extractNumbers(text string) []float64 {
+	matches := numberPattern.FindAllString(text, -1)
+	numbers := make([]float64, 0, len(matches))
+	for _, match := range matches {
+		if val, err := strconv.ParseFloat(match, 64); err == nil {
+			numbers = append(numbers, val)
+		}
+	}
+	return numbers
+}
+
+// numbersClose returns whether both texts have the same numbers, each within the relative tolerance
+func numbersClose(a []float64, b []float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		diff := math.Abs(a[i] - b[i])
+		scale := math.Max(math.Abs(a[i]), math.Abs(b[i]))
+		if diff > 0 && diff > scale*tolerance {
+			return false
+		}
+	}
+
+	return true
+}
+
+func cosine(a []float32, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// wordOverlap is the Jaccard similarity of the words of the texts, numbers masked as they are compared apart
+func wordOverlap(a string, b string) float64 {
+	a = numberPattern.ReplaceAllString(a, "#")
+	b = numberPattern.ReplaceAllString(b, "#")
+
+	wordsA := make(map[string]bool)
+	for _, word := range strings.Fields(a) {
+		wordsA[word] = true
+	}
+
+	wordsB := make(map[string]bool)
+	for _, word := range strings.Fields(b) {
+		wordsB[word] = true
+	}
+
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	common := 0
+	for word := range wordsB {
+		if wordsA[word] {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(wordsA)+len(wordsB)-common)
+}
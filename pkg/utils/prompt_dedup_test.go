@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromptDeduper(t *testing.T) {
+	d := NewPromptDeduper(0.9, 0.001, 2)
+
+	rsi := "The RSI indicator data:\nRSI(14): 52.31, 52.40, 52.38"
+	trend := "Market trend: ranging between support and resistance"
+
+	texts, replaced := d.Dedupe([]string{rsi, trend}, nil)
+	assert.Equal(t, []string{rsi, trend}, texts)
+	assert.Equal(t, 0, replaced)
+
+	// Numbers barely moved, the trend text is the same
+	rsi2 := "The RSI indicator data:\nRSI(14): 52.31, 52.40, 52.39"
+	texts, replaced = d.Dedupe([]string{rsi2, trend}, nil)
+	assert.Equal(t, 2, replaced)
+	assert.Equal(t, "The RSI indicator data: [unchanged since last cycle]", texts[0])
+	assert.Equal(t, "Market trend: ranging between support and resistance [unchanged since last cycle]", texts[1])
+
+	// A number moved beyond the tolerance of the section last sent in full
+	rsi3 := "The RSI indicator data:\nRSI(14): 52.31, 52.40, 58.10"
+	texts, _ = d.Dedupe([]string{rsi3, trend}, nil)
+	assert.Equal(t, rsi3, texts[0])
+	assert.Equal(t, "Market trend: ranging between support and resistance [unchanged since last cycle]", texts[1])
+
+	// Sent in full again after max skips
+	texts, _ = d.Dedupe([]string{rsi3, trend}, nil)
+	assert.Equal(t, trend, texts[1])
+}
+
+func TestPromptDeduperVectors(t *testing.T) {
+	d := NewPromptDeduper(0.99, 0.001, 3)
+
+	d.Dedupe([]string{"section a", "section b"}, [][]float32{{1, 0}, {0, 1}})
+
+	texts, replaced := d.Dedupe([]string{"section b", "section c"}, [][]float32{{0, 1}, {0.7, 0.7}})
+	assert.Equal(t, 1, replaced)
+	assert.Equal(t, "section b [unchanged since last cycle]", texts[0])
+	assert.Equal(t, "section c", texts[1])
+}
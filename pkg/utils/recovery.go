@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecoveryReport is the open risk of the strategy, dumped on critical failures so an operator can take over
+type RecoveryReport struct {
+	Symbol    string
+	Reason    string
+	CreatedAt time.Time
+
+	// open position, Side is empty when flat
+	Side        string
+	Quantity    float64
+	AverageCost float64
+	StopLoss    float64 // 0 when the position has no stop
+	TakeProfit  float64 // 0 when the position has no take-profit
+
+	OpenOrders []string
+	OrdersErr  string // why the open orders could not be queried
+}
+
+// String renders the report with the manual close instructions
+func (r *RecoveryReport) String() string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("🆘 Recovery report for %s at %s\n", r.Symbol, r.CreatedAt.UTC().Format(time.RFC3339)))
+	builder.WriteString(fmt.Sprintf("Reason: %s\n", r.Reason))
+
+	if r.Side == "" {
+		builder.WriteString("Open position: none\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("Open position: %s %g at an average cost of %g\n", r.Side, r.Quantity, r.AverageCost))
+		builder.WriteString(fmt.Sprintf("Stop loss: %s\n", priceOrNone(r.StopLoss)))
+		builder.WriteString(fmt.Sprintf("Take profit: %s\n", priceOrNone(r.TakeProfit)))
+	}
+
+	switch {
+	case r.OrdersErr != "":
+		builder.WriteString(fmt.Sprintf("Open orders: unknown, %s\n", r.OrdersErr))
+	case len(r.OpenOrders) == 0:
+		builder.WriteString("Open orders: none\n")
+	default:
+		builder.WriteString("Open orders:\n")
+		for _, order := range r.OpenOrders {
+			builder.WriteString(fmt.Sprintf("- %s\n", order))
+		}
+	}
+
+	builder.WriteString("Manual close:\n")
+	step := 1
+	if r.Side != "" {
+		closeSide := "sell"
+		if r.Side == "short" {
+			closeSide = "buy"
+		}
+
+		builder.WriteString(fmt.Sprintf("%d. Close the %s position on the exchange with a reduce-only market %s of %g %s.\n", step, r.Side, closeSide, r.Quantity, r.Symbol))
+		step++
+
+		if r.StopLoss == 0 {
+			builder.WriteString(fmt.Sprintf("%d. Until it is closed the position has no stop, watch it or set one on the exchange.\n", step))
+			step++
+		}
+	}
+
+	if r.OrdersErr != "" || len(r.OpenOrders) > 0 {
+		builder.WriteString(fmt.Sprintf("%d. Cancel the open orders of %s on the exchange.\n", step, r.Symbol))
+		step++
+	}
+
+	builder.WriteString(fmt.Sprintf("%d. Check the logs before restarting the strategy, it picks up the position from the exchange.\n", step))
+
+	return builder.String()
+}
+
+func priceOrNone(price float64) string {
+	if price == 0 {
+		return "none"
+	}
+
+	return fmt.Sprintf("%g", price)
+}
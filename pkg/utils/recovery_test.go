@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryReportOpenPosition(t *testing.T) {
+	report := &RecoveryReport{
+		Symbol:      "BTCUSDT",
+		Reason:      "exchange calls failing: 5 consecutive errors",
+		CreatedAt:   time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		Side:        "short",
+		Quantity:    0.5,
+		AverageCost: 52000,
+		TakeProfit:  48000,
+		OpenOrders:  []string{"#1 BUY LIMIT 0.5 at 48000"},
+	}
+
+	assert.Equal(t, "🆘 Recovery report for BTCUSDT at 2024-05-01T08:00:00Z\n"+
+		"Reason: exchange calls failing: 5 consecutive errors\n"+
+		"Open position: short 0.5 at an average cost of 52000\n"+
+		"Stop loss: none\n"+
+		"Take profit: 48000\n"+
+		"Open orders:\n"+
+		"- #1 BUY LIMIT 0.5 at 48000\n"+
+		"Manual close:\n"+
+		"1. Close the short position on the exchange with a reduce-only market buy of 0.5 BTCUSDT.\n"+
+		"2. Until it is closed the position has no stop, watch it or set one on the exchange.\n"+
+		"3. Cancel the open orders of BTCUSDT on the exchange.\n"+
+		"4. Check the logs before restarting the strategy, it picks up the position from the exchange.\n", report.String())
+}
+
+func TestRecoveryReportFlat(t *testing.T) {
+	report := &RecoveryReport{
+		Symbol:    "BTCUSDT",
+		Reason:    "panic",
+		CreatedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		OrdersErr: "timeout",
+	}
+
+	out := report.String()
+	assert.Contains(t, out, "Open position: none\n")
+	assert.Contains(t, out, "Open orders: unknown, timeout\n")
+	assert.Contains(t, out, "1. Cancel the open orders of BTCUSDT on the exchange.\n")
+	assert.NotContains(t, out, "Stop loss")
+}
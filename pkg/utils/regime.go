@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"math"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Market regimes
+const (
+	RegimeTrendingUp   = "trending_up"
+	RegimeTrendingDown = "trending_down"
+	RegimeRanging      = "ranging"
+)
+
+// trendEfficiencyThreshold is the efficiency ratio above which price movement is considered trending
+const trendEfficiencyThreshold = 0.3
+
+// DetectRegime classifies the market regime of the window using the Kaufman efficiency ratio of closes
+func DetectRegime(window types.KLineWindow) string {
+	if len(window) < 2 {
+		return ""
+	}
+
+	net := window[len(window)-1].Close.Float64() - window[0].Close.Float64()
+	path := 0.0
+	for i := 1; i < len(window); i++ {
+		path += math.Abs(window[i].Close.Float64() - window[i-1].Close.Float64())
+	}
+
+	if path == 0 || math.Abs(net)/path < trendEfficiencyThreshold {
+		return RegimeRanging
+	}
+
+	if net > 0 {
+		return RegimeTrendingUp
+	}
+
+	return RegimeTrendingDown
+}
+
+// AssessmentRegime maps the trend of a market assessment to a regime, empty when it is not recognized
+func AssessmentRegime(trend string) string {
+	switch strings.ToLower(strings.TrimSpace(trend)) {
+	case "up", "uptrend", "bullish", RegimeTrendingUp:
+		return RegimeTrendingUp
+	case "down", "downtrend", "bearish", RegimeTrendingDown:
+		return RegimeTrendingDown
+	case "sideways", "range", "ranging", "flat":
+		return RegimeRanging
+	}
+
+	return ""
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCloseWindow(closes ...float64) types.KLineWindow {
+	window := make(types.KLineWindow, 0, len(closes))
+	for _, c := range closes {
+		window = append(window, types.KLine{Close: fixedpoint.NewFromFloat(c)})
+	}
+	return window
+}
+
+func TestDetectRegime(t *testing.T) {
+	assert.Equal(t, RegimeTrendingUp, DetectRegime(newCloseWindow(100, 101, 102, 101.5, 103)))
+	assert.Equal(t, RegimeTrendingDown, DetectRegime(newCloseWindow(103, 102, 101, 101.5, 100)))
+	assert.Equal(t, RegimeRanging, DetectRegime(newCloseWindow(100, 102, 100, 102, 100)))
+	assert.Equal(t, "", DetectRegime(newCloseWindow(100)))
+}
+
+func TestAssessmentRegime(t *testing.T) {
+	assert.Equal(t, RegimeTrendingUp, AssessmentRegime("Up"))
+	assert.Equal(t, RegimeTrendingDown, AssessmentRegime("bearish"))
+	assert.Equal(t, RegimeRanging, AssessmentRegime(" sideways "))
+	assert.Equal(t, "", AssessmentRegime("unclear"))
+}
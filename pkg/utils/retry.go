@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is returned without calling the exchange while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open, exchange calls are paused after repeated failures")
+
+// RetryTransient retries the errors that do not depend on the request: rate limits, timeouts and network errors.
+// Only safe for idempotent calls such as queries.
+func RetryTransient(err error) bool {
+	switch CategorizeExecError(err) {
+	case ExecErrorRateLimit, ExecErrorTimeout, ExecErrorNetwork:
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryRateLimited retries only rate-limit rejections, for calls like order submission where a timeout or
+// network error leaves it unknown whether the exchange accepted the request
+func RetryRateLimited(err error) bool {
+	return CategorizeExecError(err) == ExecErrorRateLimit
+}
+
+// RetryBudget caps the retries made in a sliding window, so a failing exchange is not hammered by every caller
+type RetryBudget struct {
+	max    int
+	window time.Duration
+
+	mu      sync.Mutex
+	retries []time.Time
+}
+
+// NewRetryBudget allows at most max retries per window, nil when max is not positive
+func NewRetryBudget(max int, window time.Duration) *RetryBudget {
+	if max <= 0 {
+		return nil
+	}
+
+	return &RetryBudget{max: max, window: window}
+}
+
+// Take spends a retry from the budget, returning false when it is exhausted
+func (b *RetryBudget) Take(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := b.retries[:0]
+	for _, t := range b.retries {
+		if now.Sub(t) < b.window {
+			kept = append(kept, t)
+		}
+	}
+	b.retries = kept
+
+	if len(b.retries) >= b.max {
+		return false
+	}
+
+	b.retries = append(b.retries, now)
+	return true
+}
+
+// CircuitBreaker opens after consecutive failures and lets a single trial call through once the cooldown passed
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+
+	onOpen func()
+}
+
+// NewCircuitBreaker opens after threshold consecutive failures, nil when threshold is not positive
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// OnOpen registers a callback run when the breaker opens after a run of successful calls
+func (c *CircuitBreaker) OnOpen(fn func()) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onOpen = fn
+}
+
+// Allow returns whether a call may be made
+func (c *CircuitBreaker) Allow(now time.Time) bool {
+	if c == nil {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.failures < c.threshold {
+		return true
+	}
+
+	if now.Sub(c.openedAt) < c.cooldown || c.trial {
+		return false
+	}
+
+	c.trial = true
+	return true
+}
+
+// Record records the outcome of a call, opening the breaker at the threshold or when the trial call failed
+func (c *CircuitBreaker) Record(now time.Time, success bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+
+	c.trial = false
+	if success {
+		c.failures = 0
+		c.mu.Unlock()
+		return
+	}
+
+	c.failures++
+	if c.failures >= c.threshold {
+		c.openedAt = now
+	}
+
+	// Failed trial calls keep it open without running the callback again
+	onOpen := c.onOpen
+	opened := c.failures == c.threshold
+	c.mu.Unlock()
+
+	if opened && onOpen != nil {
+		onOpen()
+	}
+}
+
+// IsOpen returns whether calls are paused
+func (c *CircuitBreaker) IsOpen(now time.Time) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.failures >= c.threshold && now.Sub(c.openedAt) < c.cooldown
+}
+
+// RetryPolicy retries failed calls with exponential backoff and jitter, within a shared retry budget and
+// behind a circuit breaker
+type RetryPolicy struct {
+	MaxAttempts    int           // Attempts per call including the first, 1 disables retries
+	BaseDelay      time.Duration // Delay before the first retry, doubled on every retry
+	MaxDelay       time.Duration
+	Jitter         float64       // Share of the delay randomized, in [0, 1]
+	AttemptTimeout time.Duration // Deadline of each attempt, 0 keeps the caller's deadline
+
+	Budget  *RetryBudget
+	Breaker *CircuitBreaker
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Backoff returns the delay before the given retry, starting at 1
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(retry-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay = delay * (1 - p.Jitter + 2*p.Jitter*rand.Float64())
+	}
+
+	return time.Duration(delay)
+}
+
+// Do calls fn until it succeeds, returns an error retryable does not accept, or the attempts or budget run out.
+// A nil policy calls fn once.
+func (p *RetryPolicy) Do(ctx context.Context, retryable func(error) bool, fn func(ctx context.Context) error) error {
+	if p == nil {
+		return fn(ctx)
+	}
+
+	attempts := p.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = SleepContext
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if !p.Budget.Take(time.Now()) {
+				return errors.Wrap(err, "retry budget exhausted")
+			}
+
+			if sleepErr := sleep(ctx, p.Backoff(attempt-1)); sleepErr != nil {
+				return errors.Wrap(err, sleepErr.Error())
+			}
+		}
+
+		if !p.Breaker.Allow(time.Now()) {
+			if err != nil {
+				return errors.Wrap(err, ErrCircuitOpen.Error())
+			}
+			return ErrCircuitOpen
+		}
+
+		err = p.attempt(ctx, fn)
+		// Rejections of the request itself say nothing about the health of the exchange
+		p.Breaker.Record(time.Now(), err == nil || !RetryTransient(err))
+
+		if err == nil || retryable == nil || !retryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (p *RetryPolicy) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if p.AttemptTimeout <= 0 {
+		return fn(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, p.AttemptTimeout)
+	defer cancel()
+
+	return fn(attemptCtx)
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func noSleep(ctx context.Context, d time.Duration) error {
+	return nil
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, sleep: noSleep}
+
+	calls := 0
+	err := policy.Do(context.Background(), RetryTransient, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("429 Too Many Requests")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// rejections of the request are not retried
+	calls = 0
+	err = policy.Do(context.Background(), RetryTransient, func(ctx context.Context) error {
+		calls++
+		return errors.New("Insufficient USDT balance")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	// order submission only retries rate limits
+	calls = 0
+	err = policy.Do(context.Background(), RetryRateLimited, func(ctx context.Context) error {
+		calls++
+		return errors.New("read: connection reset by peer")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryPolicy_Budget(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 5, Budget: NewRetryBudget(2, time.Minute), sleep: noSleep}
+
+	calls := 0
+	err := policy.Do(context.Background(), RetryTransient, func(ctx context.Context) error {
+		calls++
+		return errors.New("request timeout")
+	})
+	assert.ErrorContains(t, err, "retry budget exhausted")
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		delay := policy.Backoff(2)
+		assert.GreaterOrEqual(t, delay, time.Second)
+		assert.LessOrEqual(t, delay, 3*time.Second)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(2, time.Minute)
+
+	breaker.Record(now, false)
+	assert.True(t, breaker.Allow(now))
+	breaker.Record(now, false)
+	assert.True(t, breaker.IsOpen(now))
+	assert.False(t, breaker.Allow(now.Add(30*time.Second)))
+
+	// a single trial call after the cooldown
+	later := now.Add(2 * time.Minute)
+	assert.True(t, breaker.Allow(later))
+	assert.False(t, breaker.Allow(later))
+
+	breaker.Record(later, true)
+	assert.False(t, breaker.IsOpen(later))
+	assert.True(t, breaker.Allow(later))
+
+	policy := &RetryPolicy{MaxAttempts: 3, Breaker: NewCircuitBreaker(1, time.Minute), sleep: noSleep}
+	calls := 0
+	err := policy.Do(context.Background(), RetryTransient, func(ctx context.Context) error {
+		calls++
+		return errors.New("no such host")
+	})
+	assert.ErrorContains(t, err, "circuit breaker open")
+	assert.Equal(t, 1, calls)
+}
+
+func TestCircuitBreakerOnOpen(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(2, time.Minute)
+
+	opened := 0
+	breaker.OnOpen(func() { opened++ })
+
+	breaker.Record(now, false)
+	assert.Equal(t, 0, opened)
+	breaker.Record(now, false)
+	assert.Equal(t, 1, opened)
+
+	// a failed trial call keeps it open quietly
+	later := now.Add(2 * time.Minute)
+	breaker.Allow(later)
+	breaker.Record(later, false)
+	assert.Equal(t, 1, opened)
+
+	breaker.Record(later, true)
+	breaker.Record(later, false)
+	breaker.Record(later, false)
+	assert.Equal(t, 2, opened)
+}
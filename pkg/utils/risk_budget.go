@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// RiskBudgetStep scales the risk per trade once the drawdown from the equity peak reaches DrawdownPercent
+type RiskBudgetStep struct {
+	DrawdownPercent float64
+	Scale           float64
+}
+
+// RiskBudget shrinks the risk per trade as the account draws down from its equity peak and restores it as the
+// equity recovers
+type RiskBudget struct {
+	steps    []RiskBudgetStep
+	peak     float64
+	equity   float64
+	drawdown float64
+	scale    float64
+	mu       sync.Mutex
+}
+
+func NewRiskBudget(steps []RiskBudgetStep) *RiskBudget {
+	sorted := append([]RiskBudgetStep{}, steps...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].DrawdownPercent < sorted[j].DrawdownPercent
+	})
+
+	return &RiskBudget{steps: sorted, scale: 1}
+}
+
+// Update records the account equity, returning whether the risk scale changed
+func (b *RiskBudget) Update(equity float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if equity <= 0 {
+		return false
+	}
+
+	b.equity = equity
+	if equity > b.peak {
+		b.peak = equity
+	}
+
+	return b.rescale()
+}
+
+// Restore raises the equity peak to one seen before a restart, returning whether the risk scale changed
+func (b *RiskBudget) Restore(peak float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if peak <= b.peak {
+		return false
+	}
+
+	b.peak = peak
+	return b.rescale()
+}
+
+func (b *RiskBudget) rescale() bool {
+	b.drawdown = 0
+	if b.peak > 0 && b.equity > 0 {
+		b.drawdown = (b.peak - b.equity) / b.peak * 100
+	}
+
+	scale := 1.0
+	for _, step := range b.steps {
+		if b.drawdown >= step.DrawdownPercent {
+			scale = step.Scale
+		}
+	}
+
+	changed := scale != b.scale
+	b.scale = scale
+	return changed
+}
+
+// Scale returns the share of the normal risk per trade allowed at the current drawdown
+func (b *RiskBudget) Scale() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.scale
+}
+
+// Peak returns the highest equity seen
+func (b *RiskBudget) Peak() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.peak
+}
+
+// String describes the drawdown and the risk budget, empty before the equity is known
+func (b *RiskBudget) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.peak <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("equity %.2f, %.2f%% below the %.2f peak, risk per trade at %.0f%% of normal",
+		b.equity, b.drawdown, b.peak, b.scale*100)
+}
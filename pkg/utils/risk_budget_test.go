@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRiskBudgetShrinksWithDrawdown(t *testing.T) {
+	budget := NewRiskBudget([]RiskBudgetStep{
+		{DrawdownPercent: 10, Scale: 0.25},
+		{DrawdownPercent: 5, Scale: 0.5},
+	})
+	assert.Equal(t, "", budget.String())
+
+	assert.False(t, budget.Update(1000))
+	assert.Equal(t, 1.0, budget.Scale())
+
+	assert.False(t, budget.Update(960))
+	assert.True(t, budget.Update(950))
+	assert.Equal(t, 0.5, budget.Scale())
+
+	assert.True(t, budget.Update(880))
+	assert.Equal(t, 0.25, budget.Scale())
+	assert.Equal(t, "equity 880.00, 12.00% below the 1000.00 peak, risk per trade at 25% of normal", budget.String())
+
+	// Restored as the equity recovers
+	assert.True(t, budget.Update(990))
+	assert.Equal(t, 1.0, budget.Scale())
+	assert.Equal(t, 1000.0, budget.Peak())
+
+	assert.False(t, budget.Update(0))
+}
+
+func TestRiskBudgetRestore(t *testing.T) {
+	budget := NewRiskBudget([]RiskBudgetStep{{DrawdownPercent: 5, Scale: 0.5}})
+	budget.Update(900)
+
+	assert.True(t, budget.Restore(1000))
+	assert.Equal(t, 0.5, budget.Scale())
+	assert.False(t, budget.Restore(950))
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RiskSizeParams are the inputs translating a risk per trade into a position size
+type RiskSizeParams struct {
+	Equity         float64 // Account equity in quote currency
+	RiskPercent    float64 // Percent of equity lost if the stop loss is hit
+	MaxRiskPercent float64 // Upper bound of the risk percent, 0 for no bound
+	EntryPrice     float64
+	StopLoss       float64
+	MaxNotional    float64 // Largest notional the balance and leverage allow, 0 for no bound
+	MinQuantity    float64 // Smallest base quantity of the market
+}
+
+// RiskSize is the position size computed from a risk per trade
+type RiskSize struct {
+	RiskPercent float64  `json:"risk_percent"` // Risk actually taken, after clamping
+	RiskAmount  float64  `json:"risk_amount"`  // Quote currency lost at the stop
+	Quantity    float64  `json:"quantity"`     // Base quantity
+	Notional    float64  `json:"notional"`
+	Clamps      []string `json:"clamps,omitempty"` // Limits the size was clamped to
+}
+
+// ComputeRiskSize sizes a position so that hitting the stop loss loses the risk percent of the equity,
+// clamped to the max risk percent and the max notional
+func ComputeRiskSize(p RiskSizeParams) (*RiskSize, error) {
+	if p.RiskPercent <= 0 {
+		return nil, errors.Errorf("risk percent must be greater than zero, got %g", p.RiskPercent)
+	}
+
+	if p.Equity <= 0 {
+		return nil, errors.New("no equity to size the position with")
+	}
+
+	distance := math.Abs(p.EntryPrice - p.StopLoss)
+	if p.EntryPrice <= 0 || p.StopLoss <= 0 || distance == 0 {
+		return nil, errors.Errorf("risk sizing needs a stop loss away from the entry price %g", p.EntryPrice)
+	}
+
+	size := &RiskSize{RiskPercent: p.RiskPercent, Clamps: make([]string, 0)}
+
+	if p.MaxRiskPercent > 0 && size.RiskPercent > p.MaxRiskPercent {
+		size.RiskPercent = p.MaxRiskPercent
+		size.Clamps = append(size.Clamps, fmt.Sprintf("max risk %g%%", p.MaxRiskPercent))
+	}
+
+	size.Quantity = p.Equity * size.RiskPercent / 100 / distance
+
+	if p.MaxNotional > 0 && size.Quantity*p.EntryPrice > p.MaxNotional {
+		size.Quantity = p.MaxNotional / p.EntryPrice
+		size.Clamps = append(size.Clamps, fmt.Sprintf("max notional %.2f", p.MaxNotional))
+	}
+
+	if size.Quantity < p.MinQuantity {
+		return nil, errors.Errorf("risk sized quantity %g is less than the minimum quantity %g", size.Quantity, p.MinQuantity)
+	}
+
+	size.Notional = size.Quantity * p.EntryPrice
+	size.RiskAmount = size.Quantity * distance
+	size.RiskPercent = size.RiskAmount / p.Equity * 100
+
+	return size, nil
+}
+
+func (size *RiskSize) String() string {
+	text := fmt.Sprintf("risk %.2f%% of equity (%.2f at the stop): quantity %g, notional %.2f",
+		size.RiskPercent, size.RiskAmount, size.Quantity, size.Notional)
+
+	if len(size.Clamps) > 0 {
+		text += fmt.Sprintf(", clamped to the %s", strings.Join(size.Clamps, " and "))
+	}
+
+	return text
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeRiskSize(t *testing.T) {
+	// 1% of 10000 is 100, lost over a 2 point stop distance
+	size, err := ComputeRiskSize(RiskSizeParams{
+		Equity:      10000,
+		RiskPercent: 1,
+		EntryPrice:  100,
+		StopLoss:    98,
+		MaxNotional: 50000,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 50, size.Quantity, 1e-9)
+	assert.InDelta(t, 5000, size.Notional, 1e-9)
+	assert.InDelta(t, 100, size.RiskAmount, 1e-9)
+	assert.Empty(t, size.Clamps)
+	assert.Equal(t, "risk 1.00% of equity (100.00 at the stop): quantity 50, notional 5000.00", size.String())
+
+	// Short stop above the entry, clamped to the max risk
+	size, err = ComputeRiskSize(RiskSizeParams{
+		Equity:         10000,
+		RiskPercent:    5,
+		MaxRiskPercent: 2,
+		EntryPrice:     100,
+		StopLoss:       104,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 50, size.Quantity, 1e-9)
+	assert.InDelta(t, 2, size.RiskPercent, 1e-9)
+	assert.Equal(t, []string{"max risk 2%"}, size.Clamps)
+
+	// A tight stop is clamped to the leveraged balance, taking less risk than asked
+	size, err = ComputeRiskSize(RiskSizeParams{
+		Equity:      1000,
+		RiskPercent: 1,
+		EntryPrice:  100,
+		StopLoss:    99.9,
+		MaxNotional: 3000,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 30, size.Quantity, 1e-9)
+	assert.InDelta(t, 0.3, size.RiskPercent, 1e-9)
+	assert.Contains(t, size.String(), "clamped to the max notional 3000.00")
+
+	_, err = ComputeRiskSize(RiskSizeParams{Equity: 1000, RiskPercent: 1, EntryPrice: 100})
+	assert.Error(t, err)
+
+	_, err = ComputeRiskSize(RiskSizeParams{Equity: 100, RiskPercent: 0.05, EntryPrice: 100, StopLoss: 90, MinQuantity: 0.01})
+	assert.Error(t, err)
+}
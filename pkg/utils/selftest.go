@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SelfTestResult is the outcome of one startup self-test check
+type SelfTestResult struct {
+	Name    string
+	Detail  string
+	Err     error
+	Skipped bool // The check does not apply to the config, e.g. no notify channel
+}
+
+// FormatSelfTest renders the self-test checklist, and reports whether no check failed
+func FormatSelfTest(results []SelfTestResult) (string, bool) {
+	lines := make([]string, 0, len(results))
+	failed, skipped := 0, 0
+
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			lines = append(lines, fmt.Sprintf("[FAIL] %s: %s", result.Name, result.Err.Error()))
+		case result.Skipped:
+			skipped++
+			lines = append(lines, fmt.Sprintf("[SKIP] %s: %s", result.Name, result.Detail))
+		default:
+			lines = append(lines, fmt.Sprintf("[PASS] %s: %s", result.Name, result.Detail))
+		}
+	}
+
+	header := fmt.Sprintf("Self-test passed: %d checks, %d skipped", len(results), skipped)
+	if failed > 0 {
+		header = fmt.Sprintf("Self-test failed: %d of %d checks failed", failed, len(results))
+	}
+
+	return header + "\n" + strings.Join(lines, "\n"), failed == 0
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSelfTest(t *testing.T) {
+	results := []SelfTestResult{
+		{Name: "exchange connectivity", Detail: "BTCUSDT last price 50100"},
+		{Name: "notification delivery", Detail: "no notify channel configured", Skipped: true},
+	}
+
+	report, ok := FormatSelfTest(results)
+	assert.True(t, ok)
+	assert.Equal(t, "Self-test passed: 2 checks, 1 skipped\n"+
+		"[PASS] exchange connectivity: BTCUSDT last price 50100\n"+
+		"[SKIP] notification delivery: no notify channel configured", report)
+
+	results = append(results, SelfTestResult{Name: "balance read", Err: errors.New("invalid api key")})
+	report, ok = FormatSelfTest(results)
+	assert.False(t, ok)
+	assert.Contains(t, report, "Self-test failed: 1 of 3 checks failed")
+	assert.Contains(t, report, "[FAIL] balance read: invalid api key")
+}
@@ -14,10 +14,11 @@ func ParseStopLoss(vm *goja.Runtime, side types.SideType, closePrice fixedpoint.
 	}
 
 	if strings.Contains(text, "%") {
-		val, err := fixedpoint.NewFromString(text)
+		arg, err := ParseNumberArg(text)
 		if err != nil {
 			return nil, err
 		}
+		val := arg.Fraction()
 
 		switch side {
 		case types.SideTypeSell:
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// PanicError is a recovered panic with the stack of the goroutine that panicked
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Recover calls fn and returns the panic it raised, nil when it returned normally
+func Recover(fn func()) (err *PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+// Supervisor restarts a subsystem after a panic, with a delay doubling from BaseDelay up to MaxDelay. The delay
+// starts over once the subsystem ran for MaxDelay without panicking.
+type Supervisor struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// OnPanic is called with every recovered panic and the number of restarts so far, including the coming one
+	OnPanic func(err *PanicError, restarts int)
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Run calls fn until it returns normally or the context is done
+func (s *Supervisor) Run(ctx context.Context, fn func(ctx context.Context)) {
+	sleep := s.sleep
+	if sleep == nil {
+		sleep = SleepContext
+	}
+
+	delay := s.BaseDelay
+	for restarts := 1; ; restarts++ {
+		startedAt := time.Now()
+
+		err := Recover(func() {
+			fn(ctx)
+		})
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		if s.OnPanic != nil {
+			s.OnPanic(err, restarts)
+		}
+
+		if time.Since(startedAt) >= s.MaxDelay {
+			delay = s.BaseDelay
+		}
+
+		if sleep(ctx, delay) != nil {
+			return
+		}
+
+		delay *= 2
+		if delay > s.MaxDelay {
+			delay = s.MaxDelay
+		}
+	}
+}
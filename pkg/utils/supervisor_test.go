@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	assert.Nil(t, Recover(func() {}))
+
+	err := Recover(func() {
+		panic("boom")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, "panic: boom", err.Error())
+	assert.Contains(t, string(err.Stack), "TestRecover")
+}
+
+func TestSupervisorRestartsWithBackoff(t *testing.T) {
+	var delays []time.Duration
+	var restarts []int
+
+	supervisor := &Supervisor{
+		BaseDelay: time.Second,
+		MaxDelay:  3 * time.Second,
+		OnPanic: func(err *PanicError, n int) {
+			restarts = append(restarts, n)
+		},
+		sleep: func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	}
+
+	calls := 0
+	supervisor.Run(context.Background(), func(ctx context.Context) {
+		calls++
+		if calls <= 4 {
+			panic("boom")
+		}
+	})
+
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, []int{1, 2, 3, 4}, restarts)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, delays)
+}
+
+func TestSupervisorStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	supervisor := &Supervisor{BaseDelay: time.Second, MaxDelay: time.Second}
+	supervisor.Run(ctx, func(ctx context.Context) {
+		calls++
+		cancel()
+		panic("boom")
+	})
+
+	assert.Equal(t, 1, calls)
+}
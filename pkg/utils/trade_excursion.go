@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// TradeExcursion describes how price moved against and in favor of an open position since entry
+type TradeExcursion struct {
+	HoldingPeriod int
+	TimeInTrade   time.Duration
+	MAEPercent    float64 // Max adverse excursion, as a non-positive percent of the entry price
+	MFEPercent    float64 // Max favorable excursion, as a non-negative percent of the entry price
+	ATR           float64
+
+	// Distances from the current close, in percent of the close and in ATR units, nil without SL/TP
+	StopLossPercent   *float64
+	StopLossATR       *float64
+	TakeProfitPercent *float64
+	TakeProfitATR     *float64
+}
+
+func (e *TradeExcursion) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Time in trade: %s (%d klines), max adverse excursion: %.2f%%, max favorable excursion: +%.2f%%.",
+		e.TimeInTrade, e.HoldingPeriod, e.MAEPercent, e.MFEPercent))
+
+	distance := func(name string, percent *float64, atr *float64) {
+		if percent == nil {
+			return
+		}
+
+		sb.WriteString(fmt.Sprintf("\nDistance to %s: %.2f%%", name, *percent))
+		if atr != nil {
+			sb.WriteString(fmt.Sprintf(" (%.2f ATR)", *atr))
+		}
+		sb.WriteString(".")
+	}
+
+	distance("stop-loss", e.StopLossPercent, e.StopLossATR)
+	distance("take-profit", e.TakeProfitPercent, e.TakeProfitATR)
+
+	return sb.String()
+}
+
+// ComputeTradeExcursion measures the open position over the last holdingPeriod klines of the window.
+// stopLoss and takeProfit are trigger prices, 0 when not set.
+func ComputeTradeExcursion(window types.KLineWindow, side string, entryPrice float64, stopLoss float64, takeProfit float64, holdingPeriod int) *TradeExcursion {
+	if len(window) == 0 || entryPrice <= 0 {
+		return nil
+	}
+
+	holdingPeriod = max(0, min(holdingPeriod, len(window)))
+
+	e := &TradeExcursion{
+		HoldingPeriod: holdingPeriod,
+		TimeInTrade:   time.Duration(holdingPeriod) * window.GetInterval().Duration(),
+		ATR:           averageTrueRange(window[max(0, len(window)-atrPeriod):]),
+	}
+
+	for _, k := range window[len(window)-holdingPeriod:] {
+		adverse, favorable := excursion(side, entryPrice, k.High.Float64(), k.Low.Float64())
+		e.MAEPercent = math.Min(e.MAEPercent, adverse)
+		e.MFEPercent = math.Max(e.MFEPercent, favorable)
+	}
+
+	closePrice := window[len(window)-1].Close.Float64()
+	distance := func(price float64) (*float64, *float64) {
+		if price <= 0 || closePrice <= 0 {
+			return nil, nil
+		}
+
+		diff := math.Abs(closePrice - price)
+		percent := diff / closePrice * 100
+		if e.ATR <= 0 {
+			return &percent, nil
+		}
+
+		atr := diff / e.ATR
+		return &percent, &atr
+	}
+
+	e.StopLossPercent, e.StopLossATR = distance(stopLoss)
+	e.TakeProfitPercent, e.TakeProfitATR = distance(takeProfit)
+
+	return e
+}
+
+// excursion returns how far the kline range went against and in favor of the side, in percent of the entry price
+func excursion(side string, entryPrice float64, high float64, low float64) (float64, float64) {
+	if side == "short" {
+		return (entryPrice - high) / entryPrice * 100, (entryPrice - low) / entryPrice * 100
+	}
+
+	return (low - entryPrice) / entryPrice * 100, (high - entryPrice) / entryPrice * 100
+}
+
+// ExcursionTracker follows the max adverse and favorable excursion of a trade over its whole life,
+// not only over the klines still in the window
+type ExcursionTracker struct {
+	MAEPercent float64 `json:"mae_percent"` // Non-positive percent of the entry price
+	MFEPercent float64 `json:"mfe_percent"` // Non-negative percent of the entry price
+	Active     bool    `json:"active"`      // Whether the trade is still open
+}
+
+// Start resets the tracker for a new trade
+func (t *ExcursionTracker) Start() {
+	*t = ExcursionTracker{Active: true}
+}
+
+// Stop freezes the excursions of the closed trade
+func (t *ExcursionTracker) Stop() {
+	t.Active = false
+}
+
+// Update extends the excursions with the range of a kline of the open trade
+func (t *ExcursionTracker) Update(side string, entryPrice float64, high float64, low float64) {
+	if !t.Active || entryPrice <= 0 {
+		return
+	}
+
+	adverse, favorable := excursion(side, entryPrice, high, low)
+	t.MAEPercent = math.Min(t.MAEPercent, adverse)
+	t.MFEPercent = math.Max(t.MFEPercent, favorable)
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeTradeExcursion_Long(t *testing.T) {
+	window := types.KLineWindow{
+		newKLine(101, 99, 100),
+		// trade klines
+		newKLine(101, 98, 99),
+		newKLine(104, 99, 103),
+	}
+	for i := range window {
+		window[i].Interval = types.Interval5m
+	}
+
+	e := ComputeTradeExcursion(window, "long", 100, 97, 106, 2)
+	assert.NotNil(t, e)
+
+	assert.Equal(t, 10*time.Minute, e.TimeInTrade)
+	assert.InDelta(t, -2, e.MAEPercent, 0.0001)
+	assert.InDelta(t, 4, e.MFEPercent, 0.0001)
+
+	// close 103, SL 97, TP 106
+	assert.InDelta(t, 6/103.0*100, *e.StopLossPercent, 0.0001)
+	assert.InDelta(t, 3/103.0*100, *e.TakeProfitPercent, 0.0001)
+	assert.InDelta(t, 6/e.ATR, *e.StopLossATR, 0.0001)
+
+	assert.Contains(t, e.String(), "Time in trade: 10m0s (2 klines)")
+	assert.Contains(t, e.String(), "Distance to stop-loss")
+}
+
+func TestComputeTradeExcursion_ShortWithoutStops(t *testing.T) {
+	window := types.KLineWindow{
+		newKLine(101, 99, 100),
+		newKLine(102, 97, 98),
+	}
+
+	e := ComputeTradeExcursion(window, "short", 100, 0, 0, 1)
+	assert.NotNil(t, e)
+
+	assert.InDelta(t, -2, e.MAEPercent, 0.0001)
+	assert.InDelta(t, 3, e.MFEPercent, 0.0001)
+	assert.Nil(t, e.StopLossPercent)
+	assert.Nil(t, e.TakeProfitPercent)
+	assert.NotContains(t, e.String(), "Distance")
+}
+
+func TestExcursionTracker(t *testing.T) {
+	var tracker ExcursionTracker
+	tracker.Update("long", 100, 110, 90)
+	assert.Zero(t, tracker.MFEPercent, "not tracking before the trade starts")
+
+	tracker.Start()
+	tracker.Update("short", 100, 101, 97)
+	tracker.Update("short", 100, 103, 99)
+	assert.InDelta(t, -3, tracker.MAEPercent, 0.0001)
+	assert.InDelta(t, 3, tracker.MFEPercent, 0.0001)
+
+	tracker.Stop()
+	tracker.Update("short", 100, 120, 80)
+	assert.InDelta(t, -3, tracker.MAEPercent, 0.0001, "frozen once the trade closed")
+
+	tracker.Start()
+	assert.Zero(t, tracker.MAEPercent)
+	assert.True(t, tracker.Active)
+}
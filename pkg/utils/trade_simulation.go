@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TradeSimulationParams describes a proposed entry
+type TradeSimulationParams struct {
+	Side                  string  // "long" or "short"
+	EntryPrice            float64 // Expected entry price, the limit price or the last close
+	Quantity              float64 // Base quantity
+	Leverage              float64
+	Equity                float64 // Account equity in quote currency
+	StopLoss              float64 // Stop loss trigger price, 0 when not set
+	TakeProfit            float64 // Take profit trigger price, 0 when not set
+	MaintenanceMarginRate float64
+}
+
+// TradeSimulation is the hypothetical position after a proposed entry
+type TradeSimulation struct {
+	TradeSimulationParams
+
+	Notional         float64
+	Margin           float64
+	MarginUsage      float64 // Percent of equity
+	LiquidationPrice float64
+	LossAtStop       float64 // Quote currency, 0 without a stop loss
+	LossAtStopPct    float64 // Percent of equity
+	ProfitAtTarget   float64 // Quote currency, 0 without a take profit
+}
+
+// TradeRiskLimits are the limits a simulated trade is checked against, zero values disable a check
+type TradeRiskLimits struct {
+	RequireStopLoss bool
+	MaxLossPercent  float64
+	MaxMarginUsage  float64
+}
+
+// SimulateTrade computes the position a proposed entry would open.
+// The liquidation price is an isolated margin estimate: entry * (1 -/+ 1/leverage +/- maintenance margin rate).
+func SimulateTrade(p TradeSimulationParams) *TradeSimulation {
+	sim := &TradeSimulation{
+		TradeSimulationParams: p,
+		Notional:              p.Quantity * p.EntryPrice,
+	}
+
+	leverage := math.Max(p.Leverage, 1)
+	sim.Margin = sim.Notional / leverage
+	if p.Equity > 0 {
+		sim.MarginUsage = sim.Margin / p.Equity * 100
+	}
+
+	short := p.Side == "short"
+	if short {
+		sim.LiquidationPrice = p.EntryPrice * (1 + 1/leverage - p.MaintenanceMarginRate)
+	} else {
+		sim.LiquidationPrice = p.EntryPrice * (1 - 1/leverage + p.MaintenanceMarginRate)
+	}
+
+	if p.StopLoss > 0 {
+		sim.LossAtStop = math.Abs(p.EntryPrice-p.StopLoss) * p.Quantity
+		if p.Equity > 0 {
+			sim.LossAtStopPct = sim.LossAtStop / p.Equity * 100
+		}
+	}
+
+	if p.TakeProfit > 0 {
+		sim.ProfitAtTarget = math.Abs(p.TakeProfit-p.EntryPrice) * p.Quantity
+	}
+
+	return sim
+}
+
+// Check returns the risk limit violations of the simulated trade
+func (sim *TradeSimulation) Check(limits TradeRiskLimits) []string {
+	violations := make([]string, 0)
+
+	if sim.StopLoss <= 0 {
+		if limits.RequireStopLoss {
+			violations = append(violations, "a stop loss is required")
+		}
+	} else {
+		short := sim.Side == "short"
+		if (!short && sim.StopLoss <= sim.LiquidationPrice) || (short && sim.StopLoss >= sim.LiquidationPrice) {
+			violations = append(violations, fmt.Sprintf("the stop loss %.4f is beyond the estimated liquidation price %.4f", sim.StopLoss, sim.LiquidationPrice))
+		}
+
+		if limits.MaxLossPercent > 0 && sim.LossAtStopPct > limits.MaxLossPercent {
+			violations = append(violations, fmt.Sprintf("the loss at the stop loss is %.2f%% of equity, above the %.2f%% limit", sim.LossAtStopPct, limits.MaxLossPercent))
+		}
+	}
+
+	if limits.MaxMarginUsage > 0 && sim.MarginUsage > limits.MaxMarginUsage {
+		violations = append(violations, fmt.Sprintf("the margin usage is %.2f%% of equity, above the %.2f%% limit", sim.MarginUsage, limits.MaxMarginUsage))
+	}
+
+	return violations
+}
+
+func (sim *TradeSimulation) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Simulated %s position: size %.6f at %.4f (notional %.2f), %.0fx leverage, margin %.2f (%.2f%% of equity %.2f), estimated liquidation price %.4f.",
+		sim.Side, sim.Quantity, sim.EntryPrice, sim.Notional, sim.Leverage, sim.Margin, sim.MarginUsage, sim.Equity, sim.LiquidationPrice))
+
+	if sim.StopLoss > 0 {
+		sb.WriteString(fmt.Sprintf("\nWorst-case loss at the stop loss %.4f: %.2f (%.2f%% of equity).", sim.StopLoss, sim.LossAtStop, sim.LossAtStopPct))
+	} else {
+		sb.WriteString("\nNo stop loss is set, the loss is only bounded by liquidation.")
+	}
+
+	if sim.TakeProfit > 0 {
+		sb.WriteString(fmt.Sprintf("\nProfit at the take profit %.4f: %.2f.", sim.TakeProfit, sim.ProfitAtTarget))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateTrade_Long(t *testing.T) {
+	sim := SimulateTrade(TradeSimulationParams{
+		Side:                  "long",
+		EntryPrice:            100,
+		Quantity:              30,
+		Leverage:              3,
+		Equity:                1000,
+		StopLoss:              95,
+		TakeProfit:            110,
+		MaintenanceMarginRate: 0.005,
+	})
+
+	assert.InDelta(t, 3000, sim.Notional, 0.0001)
+	assert.InDelta(t, 1000, sim.Margin, 0.0001)
+	assert.InDelta(t, 100, sim.MarginUsage, 0.0001)
+	assert.InDelta(t, 67.1667, sim.LiquidationPrice, 0.001)
+	assert.InDelta(t, 150, sim.LossAtStop, 0.0001)
+	assert.InDelta(t, 15, sim.LossAtStopPct, 0.0001)
+	assert.InDelta(t, 300, sim.ProfitAtTarget, 0.0001)
+
+	assert.Empty(t, sim.Check(TradeRiskLimits{RequireStopLoss: true, MaxLossPercent: 20}))
+
+	violations := sim.Check(TradeRiskLimits{MaxLossPercent: 10, MaxMarginUsage: 50})
+	assert.Len(t, violations, 2)
+	assert.Contains(t, sim.String(), "Worst-case loss at the stop loss")
+}
+
+func TestSimulateTrade_ShortStopBeyondLiquidation(t *testing.T) {
+	sim := SimulateTrade(TradeSimulationParams{
+		Side:       "short",
+		EntryPrice: 100,
+		Quantity:   10,
+		Leverage:   10,
+		Equity:     100,
+		StopLoss:   120,
+	})
+
+	assert.InDelta(t, 110, sim.LiquidationPrice, 0.0001)
+
+	violations := sim.Check(TradeRiskLimits{})
+	assert.Len(t, violations, 1)
+	assert.Contains(t, violations[0], "beyond the estimated liquidation price")
+
+	sim = SimulateTrade(TradeSimulationParams{Side: "short", EntryPrice: 100, Quantity: 1, Leverage: 1, Equity: 100})
+	assert.Equal(t, []string{"a stop loss is required"}, sim.Check(TradeRiskLimits{RequireStopLoss: true}))
+}
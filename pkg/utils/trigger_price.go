@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// AdjustTriggerPrice snaps a stop-loss or take-profit trigger price to the tick size and keeps it at least
+// minDistance away from the current price, on the side given by below. It returns the adjusted price and
+// a description of the changes, empty when the price was placed as requested.
+func AdjustTriggerPrice(trigger float64, price float64, tickSize float64, minDistance float64, below bool) (float64, string) {
+	adjusted := trigger
+	changes := make([]string, 0, 2)
+
+	if tickSize > 0 {
+		adjusted = roundPrice(math.Round(adjusted/tickSize) * tickSize)
+		if adjusted != trigger {
+			changes = append(changes, fmt.Sprintf("snapped to the tick size %s", formatPrice(tickSize)))
+		}
+	}
+
+	if minDistance > 0 {
+		// Moved away from the price, rounding away from it too so the distance is kept after snapping
+		if below && price-adjusted < minDistance {
+			adjusted = price - minDistance
+			if tickSize > 0 {
+				adjusted = math.Floor(adjusted/tickSize+1e-9) * tickSize
+			}
+			adjusted = roundPrice(adjusted)
+			changes = append(changes, fmt.Sprintf("moved to keep the minimum distance %s below the price %s", formatPrice(minDistance), formatPrice(price)))
+		} else if !below && adjusted-price < minDistance {
+			adjusted = price + minDistance
+			if tickSize > 0 {
+				adjusted = math.Ceil(adjusted/tickSize-1e-9) * tickSize
+			}
+			adjusted = roundPrice(adjusted)
+			changes = append(changes, fmt.Sprintf("moved to keep the minimum distance %s above the price %s", formatPrice(minDistance), formatPrice(price)))
+		}
+	}
+
+	if len(changes) == 0 {
+		return trigger, ""
+	}
+
+	return adjusted, fmt.Sprintf("%s -> %s (%s)", formatPrice(trigger), formatPrice(adjusted), strings.Join(changes, ", "))
+}
+
+// roundPrice drops the floating point noise below the 8 decimals prices are stored with
+func roundPrice(price float64) float64 {
+	return math.Round(price*1e8) / 1e8
+}
+
+func formatPrice(price float64) string {
+	return fmt.Sprintf("%g", roundPrice(price))
+}
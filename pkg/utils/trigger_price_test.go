@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjustTriggerPrice(t *testing.T) {
+	// Snapped to the nearest tick
+	adjusted, note := AdjustTriggerPrice(98.237, 100, 0.01, 0, true)
+	assert.Equal(t, 98.24, adjusted)
+	assert.Equal(t, "98.237 -> 98.24 (snapped to the tick size 0.01)", note)
+
+	// Already valid
+	adjusted, note = AdjustTriggerPrice(98.24, 100, 0.01, 0.5, true)
+	assert.Equal(t, 98.24, adjusted)
+	assert.Empty(t, note)
+
+	// A stop-loss too close below the price is moved down
+	adjusted, note = AdjustTriggerPrice(99.9, 100, 0.05, 0.23, true)
+	assert.Equal(t, 99.75, adjusted)
+	assert.Contains(t, note, "minimum distance 0.23 below the price 100")
+
+	// A take-profit too close above the price is moved up
+	adjusted, _ = AdjustTriggerPrice(100.01, 100, 0.05, 0.23, false)
+	assert.Equal(t, 100.25, adjusted)
+
+	// A trigger on the wrong side of the price is moved to the minimum distance
+	adjusted, _ = AdjustTriggerPrice(101, 100, 0.1, 0.1, true)
+	assert.Equal(t, 99.9, adjusted)
+}
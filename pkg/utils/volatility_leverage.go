@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// VolatilityLeverage is the leverage permitted for new entries under the current volatility
+type VolatilityLeverage struct {
+	Leverage    float64 // Permitted leverage
+	MaxLeverage float64 // Leverage permitted at the lowest volatility
+	ATRPercent  float64 // Latest ATR in percent of the close
+	Percentile  float64 // Rank of the latest ATR among the ATRs of the window, 0-100
+}
+
+// ComputeVolatilityLeverage scales leverage inversely with the ATR percentile of the window: the calmest ATR
+// permits maxLeverage, the most volatile minLeverage. It returns nil when the window is too short.
+func ComputeVolatilityLeverage(window types.KLineWindow, period int, minLeverage, maxLeverage float64) *VolatilityLeverage {
+	atrs := atrPercentSeries(window, period)
+	if len(atrs) < 2 {
+		return nil
+	}
+
+	latest := atrs[len(atrs)-1]
+	percentile := percentileRank(atrs, latest)
+
+	minLeverage = math.Max(minLeverage, 1)
+	maxLeverage = math.Max(maxLeverage, minLeverage)
+	leverage := math.Floor(maxLeverage - (maxLeverage-minLeverage)*percentile/100)
+
+	return &VolatilityLeverage{
+		Leverage:    math.Max(leverage, minLeverage),
+		MaxLeverage: maxLeverage,
+		ATRPercent:  latest,
+		Percentile:  percentile,
+	}
+}
+
+// Prompt tells the agent the leverage new entries are sized with
+func (v *VolatilityLeverage) Prompt() string {
+	return fmt.Sprintf("Permitted leverage for new entries: %.0fx of max %.0fx, scaled down with volatility (ATR %.2f%% of price, %.0fth percentile of the recent klines).",
+		v.Leverage, v.MaxLeverage, v.ATRPercent, v.Percentile)
+}
+
+// atrPercentSeries returns the rolling ATRs over period klines, in percent of the close
+func atrPercentSeries(window types.KLineWindow, period int) []float64 {
+	if period < 1 || len(window) < period+1 {
+		return nil
+	}
+
+	trs := make([]float64, len(window))
+	for i := 1; i < len(window); i++ {
+		k := window[i]
+		prevClose := window[i-1].Close.Float64()
+		trs[i] = math.Max(k.High.Float64()-k.Low.Float64(), math.Max(math.Abs(k.High.Float64()-prevClose), math.Abs(k.Low.Float64()-prevClose)))
+	}
+
+	atrs := make([]float64, 0, len(window)-period)
+	for i := period; i < len(window); i++ {
+		sum := 0.0
+		for _, tr := range trs[i-period+1 : i+1] {
+			sum += tr
+		}
+
+		price := window[i].Close.Float64()
+		if price > 0 {
+			atrs = append(atrs, sum/float64(period)/price*100)
+		}
+	}
+
+	return atrs
+}
+
+// percentileRank returns the percentage of the other values that are below the value
+func percentileRank(values []float64, value float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	below := sort.SearchFloat64s(sorted, value)
+	return float64(below) / float64(len(sorted)-1) * 100
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func volatilityWindow(ranges ...float64) types.KLineWindow {
+	window := types.KLineWindow{}
+	for _, r := range ranges {
+		window = append(window, newKLine(100+r/2, 100-r/2, 100))
+	}
+	return window
+}
+
+func TestComputeVolatilityLeverage(t *testing.T) {
+	// The latest ATR is the highest of the window
+	v := ComputeVolatilityLeverage(volatilityWindow(1, 1, 1, 1, 2, 3, 4), 2, 1, 5)
+	assert.InDelta(t, 100, v.Percentile, 0.0001)
+	assert.Equal(t, 1.0, v.Leverage)
+	assert.InDelta(t, 3.5, v.ATRPercent, 0.0001)
+
+	// The latest ATR is the lowest of the window
+	v = ComputeVolatilityLeverage(volatilityWindow(4, 4, 3, 2, 1, 1), 2, 1, 5)
+	assert.InDelta(t, 0, v.Percentile, 0.0001)
+	assert.Equal(t, 5.0, v.Leverage)
+
+	// Halfway between the bounds, rounded down to a whole leverage
+	v = ComputeVolatilityLeverage(volatilityWindow(1, 1, 4, 5, 2, 3), 1, 2, 5)
+	assert.InDelta(t, 50, v.Percentile, 0.0001)
+	assert.Equal(t, 3.0, v.Leverage)
+
+	assert.Nil(t, ComputeVolatilityLeverage(volatilityWindow(1, 1), 2, 1, 5))
+}
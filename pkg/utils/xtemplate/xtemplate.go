@@ -2,6 +2,8 @@ package xtemplate
 
 import (
 	"bytes"
+	"strconv"
+	"strings"
 	"text/template"
 )
 
@@ -10,12 +12,37 @@ func add(x, y int) int {
 	return x + y
 }
 
+// round formats a float with the given number of decimal places.
+func round(val float64, places int) string {
+	return strconv.FormatFloat(val, 'f', places, 64)
+}
+
+// roundAll formats each float with the given number of decimal places, separated by spaces.
+func roundAll(vals []float64, places int) string {
+	items := make([]string, 0, len(vals))
+	for _, val := range vals {
+		items = append(items, round(val, places))
+	}
+
+	return strings.Join(items, " ")
+}
+
+// funcs are the custom functions available in all templates.
+var funcs = template.FuncMap{
+	"add":      add,
+	"round":    round,
+	"roundAll": roundAll,
+}
+
+// Parse parses a template string with the custom template functions.
+func Parse(tmplStr string) (*template.Template, error) {
+	return template.New("tmpl").Funcs(funcs).Parse(tmplStr)
+}
+
 // Render function that takes a template string and a context map, and returns the rendered string or an error.
 func Render(tmplStr string, ctx map[string]interface{}) (string, error) {
 	// Parse the template string.
-	tmpl, err := template.New("tmpl").Funcs(template.FuncMap{
-		"add": add,
-	}).Parse(tmplStr)
+	tmpl, err := Parse(tmplStr)
 	if err != nil {
 		return "", err
 	}
@@ -122,3 +122,13 @@ func TestRender(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderRoundFuncs(t *testing.T) {
+	got, err := Render(`{{round .Val 2}} [{{roundAll .Vals 1}}]`, map[string]interface{}{
+		"Val":  3.14159,
+		"Vals": []float64{1.26, 2, 3.04},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "3.14 [1.3 2.0 3.0]", got)
+}